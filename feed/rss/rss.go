@@ -16,6 +16,9 @@
 // Based on RSS 2.0.11 standard: https://www.rssboard.org/rss-specification
 //
 // Currently, not all channel and item elements are supported.
+//
+// A [Feed] is written as a whole. Large feeds can be written item by item
+// with a [StreamWriter].
 package rss
 
 import (
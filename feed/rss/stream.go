@@ -0,0 +1,181 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package rss
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+)
+
+// ErrClosed is returned by a [StreamWriter] that was already closed.
+var ErrClosed = errors.New("rss: stream writer closed")
+
+// ChannelInfo contains the metadata of a RSS channel, i.e. all data of a
+// [Feed] except its items.
+type ChannelInfo struct {
+	Title          string
+	Link           string
+	Description    string
+	Language       string
+	Copyright      string
+	ManagingEditor string
+	WebMaster      string
+	PubDate        string
+	LastBuildDate  string
+	Generator      string
+	TTL            int
+	Image          *Image
+}
+
+// ChannelInfo returns the metadata of the feed.
+func (rss *Feed) ChannelInfo() ChannelInfo {
+	return ChannelInfo{
+		Title:          rss.Title,
+		Link:           rss.Link,
+		Description:    rss.Description,
+		Language:       rss.Language,
+		Copyright:      rss.Copyright,
+		ManagingEditor: rss.ManagingEditor,
+		WebMaster:      rss.WebMaster,
+		PubDate:        rss.PubDate,
+		LastBuildDate:  rss.LastBuildDate,
+		Generator:      rss.Generator,
+		TTL:            rss.TTL,
+		Image:          rss.Image,
+	}
+}
+
+// StreamWriter writes a RSS feed item by item, e.g. to stream items from a
+// database cursor without materializing all of them.
+//
+// For the same channel metadata and items, the output is byte-identical to
+// [Feed.Write].
+//
+// After an error, all further calls return this error.
+type StreamWriter struct {
+	enc    *xml.Encoder
+	err    error
+	closed bool
+}
+
+// Start elements of the feed.
+var (
+	startRSS     = xml.StartElement{Name: xml.Name{Local: "rss"}, Attr: []xml.Attr{{Name: xml.Name{Local: "version"}, Value: "2.0"}}}
+	startChannel = xml.StartElement{Name: xml.Name{Local: "channel"}}
+)
+
+// NewStreamWriter writes the XML header and the channel metadata, and
+// returns a writer for the items of the channel. [StreamWriter.Close] must
+// be called to complete the feed.
+func NewStreamWriter(w io.Writer, channel ChannelInfo) (*StreamWriter, error) {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return nil, err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	sw := &StreamWriter{enc: enc}
+	if err := sw.writeChannel(&channel); err != nil {
+		return nil, err
+	}
+	return sw, nil
+}
+
+func (sw *StreamWriter) writeChannel(ci *ChannelInfo) error {
+	enc := sw.enc
+	if err := enc.EncodeToken(startRSS); err != nil {
+		return err
+	}
+	if err := enc.EncodeToken(startChannel); err != nil {
+		return err
+	}
+	elems := []struct {
+		name      string
+		value     string
+		omitEmpty bool
+	}{
+		{"title", ci.Title, false},
+		{"link", ci.Link, false},
+		{"description", ci.Description, false},
+		{"language", ci.Language, true},
+		{"copyright", ci.Copyright, true},
+		{"managingEditor", ci.ManagingEditor, true},
+		{"webMaster", ci.WebMaster, true},
+		{"pubDate", ci.PubDate, true},
+		{"lastBuildDate", ci.LastBuildDate, true},
+		{"generator", ci.Generator, true},
+	}
+	for _, elem := range elems {
+		if elem.omitEmpty && elem.value == "" {
+			continue
+		}
+		if err := enc.EncodeElement(elem.value, xml.StartElement{Name: xml.Name{Local: elem.name}}); err != nil {
+			return err
+		}
+	}
+	if ttl := ci.TTL; ttl != 0 {
+		if err := enc.EncodeElement(strconv.Itoa(ttl), xml.StartElement{Name: xml.Name{Local: "ttl"}}); err != nil {
+			return err
+		}
+	}
+	if img := ci.Image; img != nil {
+		if err := enc.Encode(img); err != nil {
+			return err
+		}
+	}
+	return enc.Flush()
+}
+
+// WriteItem writes the given item. A nil item is ignored.
+func (sw *StreamWriter) WriteItem(item *Item) error {
+	if err := sw.check(); err != nil {
+		return err
+	}
+	if item == nil {
+		return nil
+	}
+	if err := sw.enc.Encode(item); err != nil {
+		sw.err = err
+	}
+	return sw.err
+}
+
+// Close writes the closing tags of the channel and the feed. It does not
+// close the underlying writer.
+func (sw *StreamWriter) Close() error {
+	if err := sw.check(); err != nil {
+		return err
+	}
+	sw.closed = true
+	enc := sw.enc
+	if err := enc.EncodeToken(startChannel.End()); err != nil {
+		sw.err = err
+	} else if err = enc.EncodeToken(startRSS.End()); err != nil {
+		sw.err = err
+	} else {
+		sw.err = enc.Flush()
+	}
+	return sw.err
+}
+
+func (sw *StreamWriter) check() error {
+	if sw.err != nil {
+		return sw.err
+	}
+	if sw.closed {
+		return ErrClosed
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package rss_test
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"t73f.de/r/webs/feed/rss"
+)
+
+func makeItems(n int) []*rss.Item {
+	items := make([]*rss.Item, 0, n)
+	for i := range n {
+		item := &rss.Item{
+			Title:       "Item <" + strconv.Itoa(i) + "> & more",
+			Description: rss.CData{Data: "<p>Text " + strconv.Itoa(i) + "</p>"},
+			Link:        "https://example.com/item?id=" + strconv.Itoa(i) + "&x=y",
+			PubDate:     rss.RFC822Date(time.Date(2025, time.July, 15, 12, i%60, 0, 0, time.UTC)),
+		}
+		if i%2 == 0 {
+			item.Category = []string{"even", "test"}
+			item.GUID = &rss.GUID{IsPermaLink: true, Value: item.Link}
+		}
+		if i%3 == 0 {
+			item.Author = "author" + strconv.Itoa(i) + "@example.com"
+			item.Source = &rss.Source{URL: "https://example.org/feed", Title: "Origin"}
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func streamFeed(t *testing.T, feed *rss.Feed) string {
+	t.Helper()
+	var sb strings.Builder
+	sw, err := rss.NewStreamWriter(&sb, feed.ChannelInfo())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range feed.Items {
+		if err = sw.WriteItem(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = sw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return sb.String()
+}
+
+func TestStreamEqualsBatch(t *testing.T) {
+	testcases := []struct {
+		name string
+		feed rss.Feed
+	}{
+		{"empty", rss.Feed{}},
+		{"minimal", rss.Feed{Title: "T", Link: "https://example.com", Description: "D", Items: makeItems(1)}},
+		{"full", rss.Feed{
+			Title:          "Archive <all>",
+			Link:           "https://example.com/?a=1&b=2",
+			Description:    "All items",
+			Language:       "en",
+			Copyright:      "(c) me",
+			ManagingEditor: "editor@example.com",
+			WebMaster:      "master@example.com",
+			PubDate:        rss.RFC822Date(time.Date(2025, time.January, 5, 16, 46, 17, 0, time.UTC)),
+			LastBuildDate:  rss.RFC822Date(time.Date(2025, time.January, 6, 0, 0, 0, 0, time.UTC)),
+			Generator:      "webs",
+			TTL:            60,
+			Image:          &rss.Image{URL: "https://example.com/logo.png", Title: "Logo", Link: "https://example.com"},
+			Items:          makeItems(50),
+		}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := tc.feed.Write(&sb); err != nil {
+				t.Fatal(err)
+			}
+			exp := sb.String()
+			if got := streamFeed(t, &tc.feed); got != exp {
+				t.Errorf("EXP: %s\nGOT: %s", exp, got)
+			}
+		})
+	}
+}
+
+// failWriter fails after a given number of bytes were written.
+type failWriter struct {
+	sb    strings.Builder
+	limit int
+}
+
+var errWrite = errors.New("write failed")
+
+func (fw *failWriter) Write(p []byte) (int, error) {
+	if fw.sb.Len()+len(p) > fw.limit {
+		return 0, errWrite
+	}
+	return fw.sb.Write(p)
+}
+
+func TestStreamWriteError(t *testing.T) {
+	fw := &failWriter{limit: 1000}
+	sw, err := rss.NewStreamWriter(fw, rss.ChannelInfo{Title: "T"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	written := 0
+	for _, item := range makeItems(100) {
+		if err = sw.WriteItem(item); err != nil {
+			break
+		}
+		written++
+	}
+	if !errors.Is(err, errWrite) {
+		t.Fatalf("write error expected, got %v", err)
+	}
+	if written == 0 {
+		t.Error("some items should have been written")
+	}
+	if got := strings.Count(fw.sb.String(), "</item>"); got != written {
+		t.Errorf("%d complete items expected, got %d", written, got)
+	}
+	if err = sw.WriteItem(makeItems(1)[0]); !errors.Is(err, errWrite) {
+		t.Errorf("error must be sticky, got %v", err)
+	}
+	if err = sw.Close(); !errors.Is(err, errWrite) {
+		t.Errorf("close must return write error, got %v", err)
+	}
+
+	if _, err = rss.NewStreamWriter(&failWriter{limit: 10}, rss.ChannelInfo{}); !errors.Is(err, errWrite) {
+		t.Errorf("header write error expected, got %v", err)
+	}
+}
+
+func TestStreamClosed(t *testing.T) {
+	var sb strings.Builder
+	sw, err := rss.NewStreamWriter(&sb, rss.ChannelInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = sw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = sw.WriteItem(&rss.Item{}); !errors.Is(err, rss.ErrClosed) {
+		t.Errorf("ErrClosed expected, got %v", err)
+	}
+	if err = sw.Close(); !errors.Is(err, rss.ErrClosed) {
+		t.Errorf("ErrClosed expected, got %v", err)
+	}
+}
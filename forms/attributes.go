@@ -16,7 +16,12 @@ package forms
 // Attributes are technically validators that do not validate, but return HTML
 // attributes.
 
-import "t73f.de/r/webs/htmls"
+import (
+	"fmt"
+	"strings"
+
+	"t73f.de/r/webs/htmls"
+)
 
 // ----- Autofocus: where the input starts.
 
@@ -46,3 +51,46 @@ func (AttrStep) Check(*Form, Field) error { return nil }
 func (s AttrStep) Attributes() []htmls.Attribute {
 	return []htmls.Attribute{{Key: "step", Value: s.Value}}
 }
+
+// ----- Additional attributes of a field, set by the application.
+
+// reservedAttributes are managed by the fields and must not be set
+// explicitly.
+var reservedAttributes = map[string]bool{"id": true, "name": true, "type": true, "value": true}
+
+// fieldAttributes stores additional attributes of a field.
+type fieldAttributes []htmls.Attribute
+
+// set the attribute with the given key. A previous value is overwritten. It
+// panics, if the key is empty or reserved.
+func (fa *fieldAttributes) set(key, val string) {
+	key = strings.ToLower(key)
+	if key == "" || reservedAttributes[key] {
+		panic(fmt.Sprintf("attribute %q must not be set", key))
+	}
+	for i, attr := range *fa {
+		if attr.Key == key {
+			(*fa)[i].Value = val
+			return
+		}
+	}
+	*fa = append(*fa, htmls.Attribute{Key: key, Value: val})
+}
+
+// merge the additional attributes into the given attributes. An additional
+// attribute replaces an attribute with the same key, e.g. one returned by a
+// validator.
+func (fa fieldAttributes) merge(attrs []htmls.Attribute) []htmls.Attribute {
+	for _, attr := range fa {
+		found := false
+		for i := range attrs {
+			if attrs[i].Key == attr.Key {
+				attrs[i].Value, found = attr.Value, true
+			}
+		}
+		if !found {
+			attrs = append(attrs, attr)
+		}
+	}
+	return attrs
+}
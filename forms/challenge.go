@@ -0,0 +1,336 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package forms
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"t73f.de/r/webs/htmls"
+)
+
+// ChallengeProvider is a provider of a proof-of-humanity challenge, e.g. a
+// CAPTCHA.
+type ChallengeProvider interface {
+	// RenderWidget renders the widget that presents the challenge. It is
+	// placed before the response input, which has the given field
+	// identifier.
+	//
+	// Hidden inputs of the widget, which use the field identifier as their
+	// name, are part of the response. Their values precede the value of
+	// the response input, separated by a newline character.
+	RenderWidget(fieldID string) *htmls.Node
+
+	// Verify the response of the client. The remote address is the one of
+	// the connection, i.e. the RemoteAddr of the request, which is not
+	// taken from a client-supplied header. It may be empty, if it is not
+	// known.
+	Verify(ctx context.Context, responseToken string, remoteAddr string) error
+}
+
+// ChallengeElement represents a challenge that must be solved to submit a
+// form.
+type ChallengeElement struct {
+	name     string
+	provider ChallengeProvider
+	value    string
+	disabled bool
+	fieldRendering
+}
+
+// ChallengeField builds a new challenge field, where the challenge is
+// presented and verified by the given provider.
+func ChallengeField(name string, provider ChallengeProvider) *ChallengeElement {
+	return &ChallengeElement{name: name, provider: provider}
+}
+
+// Name returns the name of this element.
+func (ce *ChallengeElement) Name() string { return ce.name }
+
+// Value returns the response of the client.
+func (ce *ChallengeElement) Value() string { return ce.value }
+
+// Clear the element.
+func (ce *ChallengeElement) Clear() { ce.value = "" }
+
+// SetValue sets the response of the client.
+func (ce *ChallengeElement) SetValue(value string) error { ce.value = value; return nil }
+
+// Validators return the validator that verifies the response.
+func (ce *ChallengeElement) Validators() Validators {
+	return Validators{challengeValidator{ce}}
+}
+
+// challengeValidator verifies the response with the data of the submitting
+// request.
+type challengeValidator struct{ ce *ChallengeElement }
+
+// Check verifies the response without a request.
+func (cv challengeValidator) Check(f *Form, _ Field) error {
+	return cv.checkRequest(f, nil, "")
+}
+
+func (cv challengeValidator) checkRequest(f *Form, ctx context.Context, remoteAddr string) error {
+	ce := cv.ce
+	if ce.disabled {
+		return nil
+	}
+	if ce.value == "" {
+		return CodedStopValidationError(CodeChallengeUnanswered, f.sprintf(ce, MsgChallengeUnanswered), nil)
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := ce.provider.Verify(ctx, ce.value, remoteAddr); err != nil {
+		return CodedStopValidationError(CodeChallengeFailed, err.Error(), nil)
+	}
+	return nil
+}
+
+// Disable the element.
+func (ce *ChallengeElement) Disable() { ce.disabled = true }
+
+func (ce *ChallengeElement) isDisabled() bool { return ce.disabled }
+
+// Render the challenge element without warnings.
+func (ce *ChallengeElement) Render(fieldID string, messages []string) *htmls.Node {
+	return ce.RenderWarnings(fieldID, messages, nil)
+}
+
+// RenderWarnings renders the challenge element, with its error and warning
+// messages. The response is never rendered, because each challenge can only
+// be solved once.
+func (ce *ChallengeElement) RenderWarnings(fieldID string, messages, warnings []string) *htmls.Node {
+	attrs := makeAttributes(6, nil, ce.disabled)
+	attrs = append(attrs,
+		htmls.Attribute{Key: "id", Value: fieldID},
+		htmls.Attribute{Key: "name", Value: ce.name},
+		htmls.Attribute{Key: "type", Value: "text"},
+		htmls.Attribute{Key: "autocomplete", Value: "off"},
+	)
+	attrs = addEnablingAttributes(attrs, ce.disabled, nil)
+	attrs = addDescribedBy(attrs, fieldID, false, len(messages)+len(warnings))
+	attrs = addInvalid(attrs, messages)
+
+	widget := ce.provider.RenderWidget(fieldID)
+	if fieldID != ce.name {
+		// The identifier is prefixed, see Form.SetIDPrefix, but the hidden
+		// inputs must be sent with the name of the field.
+		renameInputs(widget, fieldID, ce.name)
+	}
+	divNode := htmls.Elem("div", nil)
+	divNode.AddChildren(widget)
+	divNode.Children = append(divNode.Children, renderAllMessages(ce.renderer(), fieldID, messages, warnings)...)
+	divNode.Children = append(divNode.Children, htmls.Elem("input", attrs))
+	return divNode
+}
+
+// ----- Built-in challenge provider
+
+// Errors returned by [MathChallenge.Verify].
+var (
+	ErrChallengeFailed   = errors.New("wrong answer to challenge")
+	ErrChallengeExpired  = errors.New("challenge expired")
+	ErrChallengeReplayed = errors.New("challenge already answered")
+	ErrChallengeLimit    = errors.New("too many challenge attempts, please try again later")
+)
+
+// renameInputs changes the name of all input elements of the tree from
+// oldName to newName.
+func renameInputs(node *htmls.Node, oldName, newName string) {
+	if node == nil || node.Type != htmls.ElementNode {
+		return
+	}
+	if node.Data == "input" {
+		for i, attr := range node.Attributes {
+			if attr.Key == "name" && attr.Value == oldName {
+				node.Attributes[i].Value = newName
+			}
+		}
+	}
+	for _, child := range node.Children {
+		renameInputs(child, oldName, newName)
+	}
+}
+
+// MathChallenge is a self-hosted [ChallengeProvider] that asks to add two
+// small numbers.
+//
+// The question is signed and sent to the client as a hidden token, which
+// does not reveal the answer. Each token can only be answered once, and the
+// number of failed attempts per remote host is limited. The remote host is
+// taken from the connection, not from a header like "X-Forwarded-For",
+// which could be changed by the client with every attempt. At most
+// [MaxChallengeHosts] hosts are tracked: if more hosts fail, the hosts whose
+// limit ends first are forgotten.
+type MathChallenge struct {
+	key         []byte
+	ttl         time.Duration
+	maxAttempts int
+
+	mx       sync.Mutex
+	used     map[string]time.Time   // nonce -> expiry
+	attempts map[string]mathAttempt // remote host -> failed attempts
+}
+
+// MaxChallengeHosts is the maximum number of remote hosts, whose failed
+// attempts are tracked by a [MathChallenge].
+const MaxChallengeHosts = 10000
+
+type mathAttempt struct {
+	count int
+	reset time.Time
+}
+
+// NewMathChallenge creates a new arithmetic challenge provider. The key is
+// used to sign the challenges, ttl specifies how long a challenge can be
+// answered, and maxAttempts limits the number of failed attempts per remote
+// host within ttl.
+func NewMathChallenge(key []byte, ttl time.Duration, maxAttempts int) *MathChallenge {
+	return &MathChallenge{
+		key:         key,
+		ttl:         ttl,
+		maxAttempts: maxAttempts,
+		used:        map[string]time.Time{},
+		attempts:    map[string]mathAttempt{},
+	}
+}
+
+// RenderWidget renders the question and the signed token.
+func (mc *MathChallenge) RenderWidget(fieldID string) *htmls.Node {
+	a, b := randomOperand(), randomOperand()
+	nonce := rand.Text()
+	expires := time.Now().Add(mc.ttl).UnixMilli()
+	token := nonce + "." + strconv.FormatInt(expires, 10) + "." + mc.sign(nonce, expires, a+b)
+	return htmls.Elem("div", nil,
+		htmls.Elem("label", htmls.Attrs("for", fieldID), htmls.Text(fmt.Sprintf("What is %d + %d?", a, b))),
+		htmls.Elem("input", htmls.Attrs("name", fieldID, "type", "hidden", "value", token)),
+	)
+}
+
+func randomOperand() int {
+	n, err := rand.Int(rand.Reader, big.NewInt(10))
+	if err != nil {
+		panic(err)
+	}
+	return int(n.Int64()) + 1
+}
+
+func (mc *MathChallenge) sign(nonce string, expires int64, answer int) string {
+	mac := hmac.New(sha256.New, mc.key)
+	mac.Write([]byte(nonce))
+	var buf [16]byte
+	binary.BigEndian.PutUint64(buf[0:8], uint64(expires))
+	binary.BigEndian.PutUint64(buf[8:16], uint64(answer))
+	mac.Write(buf[:])
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Verify the response, which consists of the token and the answer.
+func (mc *MathChallenge) Verify(_ context.Context, responseToken string, remoteAddr string) error {
+	host := remoteAddr
+	if h, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		host = h
+	}
+	now := time.Now()
+
+	mc.mx.Lock()
+	defer mc.mx.Unlock()
+	mc.cleanup(now)
+	if att, found := mc.attempts[host]; found && att.count >= mc.maxAttempts {
+		return ErrChallengeLimit
+	}
+	err := mc.check(responseToken, now)
+	if err != nil && err != ErrChallengeReplayed {
+		att, found := mc.attempts[host]
+		if !found {
+			att.reset = now.Add(mc.ttl)
+			if len(mc.attempts) >= MaxChallengeHosts {
+				mc.forgetFirstHost()
+			}
+		}
+		att.count++
+		mc.attempts[host] = att
+	}
+	return err
+}
+
+func (mc *MathChallenge) check(responseToken string, now time.Time) error {
+	token, answer, found := strings.Cut(responseToken, "\n")
+	if !found {
+		return ErrChallengeFailed
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return ErrChallengeFailed
+	}
+	nonce, sig := parts[0], parts[2]
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return ErrChallengeFailed
+	}
+	result, err := strconv.Atoi(strings.TrimSpace(answer))
+	if err != nil {
+		return ErrChallengeFailed
+	}
+	if !hmac.Equal([]byte(sig), []byte(mc.sign(nonce, expires, result))) {
+		return ErrChallengeFailed
+	}
+	if _, isUsed := mc.used[nonce]; isUsed {
+		return ErrChallengeReplayed
+	}
+	expiry := time.UnixMilli(expires)
+	if !now.Before(expiry) {
+		return ErrChallengeExpired
+	}
+	mc.used[nonce] = expiry
+	return nil
+}
+
+// forgetFirstHost removes the host, whose limit ends first.
+func (mc *MathChallenge) forgetFirstHost() {
+	var first string
+	var reset time.Time
+	for host, att := range mc.attempts {
+		if first == "" || att.reset.Before(reset) {
+			first, reset = host, att.reset
+		}
+	}
+	delete(mc.attempts, first)
+}
+
+func (mc *MathChallenge) cleanup(now time.Time) {
+	for nonce, expiry := range mc.used {
+		if !now.Before(expiry) {
+			delete(mc.used, nonce)
+		}
+	}
+	for host, att := range mc.attempts {
+		if !now.Before(att.reset) {
+			delete(mc.attempts, host)
+		}
+	}
+}
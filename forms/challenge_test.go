@@ -0,0 +1,134 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package forms_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"t73f.de/r/webs/forms"
+)
+
+var challengeRe = regexp.MustCompile(`What is (\d+) \+ (\d+)\?</label><input name="human" type="hidden" value="([^"]+)"`)
+
+// newChallenge renders a new form and returns the token and the correct
+// answer of its challenge.
+func newChallenge(t *testing.T, f *forms.Form) (string, string) {
+	t.Helper()
+	html := renderForm(f)
+	m := challengeRe.FindStringSubmatch(html)
+	if m == nil {
+		t.Fatalf("no challenge found in %q", html)
+	}
+	a, _ := strconv.Atoi(m[1])
+	b, _ := strconv.Atoi(m[2])
+	return m[3], strconv.Itoa(a + b)
+}
+
+func submitChallenge(f *forms.Form, token, answer, remote string) forms.SubmitResult {
+	vals := url.Values{"human": {token, answer}, "save": {"Save"}}
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(vals.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.RemoteAddr = remote
+	sr, _ := f.OnSubmit(r)
+	return sr
+}
+
+func TestMathChallenge(t *testing.T) {
+	mc := forms.NewMathChallenge([]byte("secret"), time.Minute, 3)
+	newForm := func() *forms.Form {
+		return forms.Define(forms.ChallengeField("human", mc), forms.SubmitField("save", "Save"))
+	}
+
+	f := newForm()
+	token, answer := newChallenge(t, f)
+	if sr := submitChallenge(f, token, answer, "192.0.2.1:1234"); sr != forms.SubmitValidData {
+		t.Errorf("correct answer must be valid, but got %v: %v", sr, f.Messages())
+	}
+	f = newForm()
+	if sr := submitChallenge(f, token, answer, "192.0.2.1:1234"); sr != forms.SubmitInvalidData {
+		t.Error("replayed answer must be invalid")
+	} else if got := f.Messages()["human"]; len(got) != 1 || got[0] != forms.ErrChallengeReplayed.Error() {
+		t.Errorf("replay message expected, but got %v", got)
+	}
+
+	f = newForm()
+	token, answer = newChallenge(t, f)
+	if sr := submitChallenge(f, token, answer+"1", "192.0.2.1:1234"); sr != forms.SubmitInvalidData {
+		t.Error("wrong answer must be invalid")
+	} else if got := f.Messages()["human"]; len(got) != 1 || got[0] != forms.ErrChallengeFailed.Error() {
+		t.Errorf("failure message expected, but got %v", got)
+	}
+	if sr := submitChallenge(f, "", "", "192.0.2.1:1234"); sr != forms.SubmitInvalidData {
+		t.Error("missing answer must be invalid")
+	}
+	if sr := submitChallenge(f, "a.b", answer, "192.0.2.1:1234"); sr != forms.SubmitInvalidData {
+		t.Error("malformed token must be invalid")
+	}
+	if sr := submitChallenge(f, token, "x", "192.0.2.1:1234"); sr != forms.SubmitInvalidData {
+		t.Error("non-numeric answer must be invalid")
+	}
+
+	// Three failed attempts: even the correct answer is now rejected.
+	if sr := submitChallenge(f, token, answer, "192.0.2.1:4321"); sr != forms.SubmitInvalidData {
+		t.Error("attempts must be limited")
+	} else if got := f.Messages()["human"]; len(got) != 1 || got[0] != forms.ErrChallengeLimit.Error() {
+		t.Errorf("limit message expected, but got %v", got)
+	}
+	// Other hosts are not affected.
+	if sr := submitChallenge(f, token, answer, "198.51.100.1:1234"); sr != forms.SubmitValidData {
+		t.Errorf("other host must not be limited, but got %v", f.Messages())
+	}
+}
+
+func TestMathChallengeExpired(t *testing.T) {
+	mc := forms.NewMathChallenge([]byte("secret"), 10*time.Millisecond, 3)
+	f := forms.Define(forms.ChallengeField("human", mc), forms.SubmitField("save", "Save"))
+	token, answer := newChallenge(t, f)
+	time.Sleep(20 * time.Millisecond)
+	if sr := submitChallenge(f, token, answer, "192.0.2.1:1234"); sr != forms.SubmitInvalidData {
+		t.Error("expired challenge must be invalid")
+	} else if got := f.Messages()["human"]; len(got) != 1 || got[0] != forms.ErrChallengeExpired.Error() {
+		t.Errorf("expiry message expected, but got %v", got)
+	}
+}
+
+func TestMathChallengeForwardedFor(t *testing.T) {
+	mc := forms.NewMathChallenge([]byte("secret"), time.Minute, 2)
+	f := forms.Define(forms.ChallengeField("human", mc), forms.SubmitField("save", "Save"))
+	submit := func(token, answer, forwarded string) forms.SubmitResult {
+		vals := url.Values{"human": {token, answer}, "save": {"Save"}}
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(vals.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.Header.Set("X-Forwarded-For", forwarded)
+		r.RemoteAddr = "192.0.2.7:1234"
+		sr, _ := f.OnSubmit(r)
+		return sr
+	}
+	token, answer := newChallenge(t, f)
+	for i := range 2 {
+		submit(token, answer+"1", "203.0.113."+strconv.Itoa(i))
+	}
+	if sr := submit(token, answer, "203.0.113.99"); sr != forms.SubmitInvalidData {
+		t.Error("a changed X-Forwarded-For header must not reset the limit")
+	} else if got := f.Messages()["human"]; len(got) != 1 || got[0] != forms.ErrChallengeLimit.Error() {
+		t.Errorf("limit message expected, but got %v", got)
+	}
+}
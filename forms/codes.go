@@ -0,0 +1,191 @@
+// -----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+// -----------------------------------------------------------------------------
+
+package forms
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"slices"
+)
+
+// Codes of validation errors, see [FieldError]. They are stable and do not
+// depend on the language of the messages. The parameters of each error are
+// given in parentheses.
+const (
+	CodeInvalid             = "invalid"              // (), error without a code
+	CodeRequired            = "required"             // ()
+	CodeTooShort            = "too_short"            // (min, length)
+	CodeTooLong             = "too_long"             // (max, length)
+	CodeFormat              = "format"               // (pattern)
+	CodeNotANumber          = "not_a_number"         // (value)
+	CodeNotAnInteger        = "not_an_integer"       // (value)
+	CodeNotAnUnsigned       = "not_an_unsigned"      // (value)
+	CodeInvalidValue        = "invalid_value"        // (value)
+	CodeTooSmall            = "too_small"            // (min, value)
+	CodeTooLarge            = "too_large"            // (max, value)
+	CodeNotInSet            = "not_in_set"           // (value, allowed)
+	CodeInForbiddenSet      = "in_forbidden_set"     // (value)
+	CodeCompare             = "compare"              // (op, other)
+	CodeFileTooLarge        = "file_too_large"       // (max, size)
+	CodeFileType            = "file_type"            // (filename, accepted)
+	CodeOTPDigits           = "otp_digits"           // (digits)
+	CodeChallengeUnanswered = "challenge_unanswered" // ()
+	CodeChallengeFailed     = "challenge_failed"     // ()
+	CodeQRCodeContent       = "qrcode_content"       // (finding)
+	CodeUnexpectedField     = "unexpected_field"     // (name)
+	CodeConflict            = "conflict"             // ()
+)
+
+// FieldError is a validation error of a field in a machine-readable form,
+// e.g. for a JSON API. See [Form.FieldErrors].
+type FieldError struct {
+	Field   string         `json:"field"` // empty for an error of the whole form
+	Code    string         `json:"code"`
+	Params  map[string]any `json:"params,omitempty"`
+	Message string         `json:"message"` // as in [Form.Messages]
+}
+
+// CodedError is a validation error with a machine-readable code and
+// parameters. Like [ValidationError], its message is shown to the user. If
+// Stop is true, further validation of the field stops, like with a
+// [StopValidationError].
+type CodedError struct {
+	Code    string
+	Params  map[string]any
+	Message string
+	Stop    bool
+}
+
+func (ce *CodedError) Error() string { return ce.Message }
+
+// CodedValidationError returns a validation error with a code and optional
+// parameters, which allows further validation of the field.
+func CodedValidationError(code, message string, params map[string]any) error {
+	return &CodedError{Code: code, Params: params, Message: message}
+}
+
+// CodedStopValidationError returns a validation error with a code and
+// optional parameters, which stops further validation of the field.
+func CodedStopValidationError(code, message string, params map[string]any) error {
+	return &CodedError{Code: code, Params: params, Message: message, Stop: true}
+}
+
+// FieldErrors contains the validation errors of all fields, as a map of
+// field names to a list of errors. Errors of the whole form use the empty
+// string as a field name.
+type FieldErrors map[string][]FieldError
+
+// MarshalJSON returns the errors as a JSON array, ordered by field name, e.g.
+// as part of an API response. Errors of the whole form are first.
+func (fe FieldErrors) MarshalJSON() ([]byte, error) {
+	result := make([]FieldError, 0, len(fe))
+	for _, name := range slices.Sorted(maps.Keys(fe)) {
+		result = append(result, fe[name]...)
+	}
+	return json.Marshal(result)
+}
+
+// FieldErrors returns the error messages of [Form.Messages] together with
+// their codes and parameters. A message without a code, e.g. of a custom
+// validator that returns a [ValidationError], gets the code [CodeInvalid].
+func (f *Form) FieldErrors() FieldErrors {
+	if len(f.messages) == 0 {
+		return nil
+	}
+	result := make(FieldErrors, len(f.messages))
+	for name, msgs := range f.messages {
+		details := slices.Clone(f.details[name])
+		errs := make([]FieldError, 0, len(msgs))
+		for _, msg := range msgs {
+			fe := FieldError{Field: name, Code: CodeInvalid, Message: msg}
+			if pos := slices.IndexFunc(details, func(d FieldError) bool { return d.Message == msg }); pos >= 0 {
+				fe = details[pos]
+				details = slices.Delete(details, pos, pos+1)
+			}
+			errs = append(errs, fe)
+		}
+		result[name] = errs
+	}
+	return result
+}
+
+// validation collects the results of validators.
+type validation struct {
+	messages Messages
+	warnings Messages
+	details  map[string][]FieldError // coded errors, see Form.FieldErrors
+
+	ctx        context.Context // context of the submitting request, if any
+	remoteAddr string          // remote address of the submitting request
+}
+
+// newValidation returns a validation of the data of the given request.
+func newValidation(r *http.Request) *validation {
+	return &validation{ctx: r.Context(), remoteAddr: r.RemoteAddr}
+}
+
+// requestValidator is a validator, which needs data of the submitting
+// request, e.g. to verify a challenge. They are passed per validation, so
+// that a form can be shared by concurrent requests.
+type requestValidator interface {
+	Validator
+	checkRequest(f *Form, ctx context.Context, remoteAddr string) error
+}
+
+// add the message of the error to messages or warnings. An error that wraps
+// multiple errors, e.g. one created by errors.Join, adds all their messages.
+// It returns true, if the validation of the field must stop.
+func (v *validation) add(err error, msgName string) bool {
+	if multi, isMulti := err.(interface{ Unwrap() []error }); isMulti {
+		stop := false
+		for _, e := range multi.Unwrap() {
+			stop = v.add(e, msgName) || stop
+		}
+		return stop
+	}
+	switch e := err.(type) {
+	case WarningError:
+		if warnMsg := e.Error(); warnMsg != "" {
+			v.warnings = v.warnings.Add(msgName, warnMsg)
+		}
+		return false
+	case *CodedError:
+		if e.Message != "" {
+			v.messages = v.messages.Add(msgName, e.Message)
+			v.details = addDetail(v.details, msgName, e.Code, e.Message, e.Params)
+		}
+		return e.Stop
+	}
+	if errMsg := err.Error(); errMsg != "" {
+		v.messages = v.messages.Add(msgName, errMsg)
+	}
+	_, isStop := err.(StopValidationError)
+	return isStop
+}
+
+func addDetail(details map[string][]FieldError, name, code, msg string, params map[string]any) map[string][]FieldError {
+	if details == nil {
+		details = map[string][]FieldError{}
+	}
+	details[name] = append(details[name], FieldError{Field: name, Code: code, Params: params, Message: msg})
+	return details
+}
+
+// addCodedMessage adds a message with a code to the messages of the form.
+func (f *Form) addCodedMessage(name, code, msg string, params map[string]any) {
+	f.messages = f.messages.Add(name, msg)
+	f.details = addDetail(f.details, name, code, msg, params)
+}
@@ -0,0 +1,156 @@
+// -----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+// -----------------------------------------------------------------------------
+
+package forms_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"t73f.de/r/webs/forms"
+)
+
+func TestFieldErrorCodes(t *testing.T) {
+	custom := forms.ValidatorFunc(func(*forms.Form, forms.Field) error { return forms.ValidationError("custom") })
+	testcases := []struct {
+		name   string
+		field  forms.Field
+		value  string
+		code   string
+		params map[string]any
+	}{
+		{"required", forms.TextField("f", "F", forms.Required{}), "", forms.CodeRequired, nil},
+		{"too-short", forms.TextField("f", "F", &forms.MinMaxLength{MinLength: 3}), "ab", forms.CodeTooShort,
+			map[string]any{"min": 3, "length": 2}},
+		{"too-long", forms.TextField("f", "F", &forms.MinMaxLength{MaxLength: 1}), "ab", forms.CodeTooLong,
+			map[string]any{"max": 1, "length": 2}},
+		{"format", forms.TextField("f", "F", &forms.Regexp{Regexp: regexp.MustCompile(`\d+`)}), "ab", forms.CodeFormat,
+			map[string]any{"pattern": `\d+`}},
+		{"not-a-number", forms.NumberField("f", "F", &forms.MinValue{Value: "1"}), "x", forms.CodeNotANumber,
+			map[string]any{"value": "x"}},
+		{"too-small", forms.NumberField("f", "F", &forms.MinValue{Value: "10"}), "9", forms.CodeTooSmall,
+			map[string]any{"min": "10", "value": "9"}},
+		{"too-small-date", forms.DateField("f", "F", &forms.MinValue{Value: "2025-01-02"}), "2025-01-01", forms.CodeTooSmall,
+			map[string]any{"min": "2025-01-02", "value": "2025-01-01"}},
+		{"too-large", forms.NumberField("f", "F", &forms.MaxValue{Value: "10"}), "11", forms.CodeTooLarge,
+			map[string]any{"max": "10", "value": "11"}},
+		{"invalid-value", forms.TimeField("f", "F", &forms.MaxValue{Value: "10:00"}), "25:00", forms.CodeInvalidValue,
+			map[string]any{"value": "25:00"}},
+		{"not-an-integer", forms.TextField("f", "F", forms.IntValidator()), "1.5", forms.CodeNotAnInteger,
+			map[string]any{"value": "1.5"}},
+		{"not-an-unsigned", forms.TextField("f", "F", forms.UIntValidator()), "-1", forms.CodeNotAnUnsigned,
+			map[string]any{"value": "-1"}},
+		{"not-in-set", forms.TextField("f", "F", forms.AnyOf("b", "a")), "c", forms.CodeNotInSet,
+			map[string]any{"value": "c", "allowed": []string{"a", "b"}}},
+		{"in-forbidden-set", forms.TextField("f", "F", forms.NoneOf("a")), "a", forms.CodeInForbiddenSet,
+			map[string]any{"value": "a"}},
+		{"compare", forms.TextField("f", "F", forms.StringLess("b", "")), "c", forms.CodeCompare,
+			map[string]any{"op": "≥", "other": "b"}},
+		{"otp", forms.OTPField("f", 6), "123", forms.CodeOTPDigits, map[string]any{"digits": 6}},
+		{"challenge", forms.ChallengeField("f", forms.NewMathChallenge([]byte("s"), time.Minute, 3)), "", forms.CodeChallengeUnanswered, nil},
+		{"challenge-failed", forms.ChallengeField("f", forms.NewMathChallenge([]byte("s"), time.Minute, 3)), "x\n1", forms.CodeChallengeFailed, nil},
+		{"custom", forms.TextField("f", "F", custom), "", forms.CodeInvalid, nil},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := forms.Define(tc.field)
+			_ = tc.field.SetValue(tc.value)
+			if f.IsValid() {
+				t.Fatal("form must be invalid")
+			}
+			errs := f.FieldErrors()["f"]
+			if len(errs) != 1 {
+				t.Fatalf("one error expected, got %v", errs)
+			}
+			fe := errs[0]
+			if fe.Field != "f" || fe.Code != tc.code || !reflect.DeepEqual(fe.Params, tc.params) {
+				t.Errorf("code %q with %v expected, got %+v", tc.code, tc.params, fe)
+			}
+			if msgs := f.Messages()["f"]; len(msgs) != 1 || msgs[0] != fe.Message {
+				t.Errorf("message %q expected, got %q", fe.Message, msgs)
+			}
+		})
+	}
+}
+
+func TestFieldErrorCodesFile(t *testing.T) {
+	testcases := []struct {
+		validator forms.Validator
+		code      string
+		params    map[string]any
+	}{
+		{forms.MaxFileSize{Size: 2}, forms.CodeFileTooLarge, map[string]any{"max": int64(2), "size": int64(3)}},
+		{forms.AcceptFile{Types: []string{".pdf"}}, forms.CodeFileType,
+			map[string]any{"filename": "a.txt", "accepted": []string{".pdf"}}},
+	}
+	for _, tc := range testcases {
+		f := forms.Define(forms.FileField("doc", "Doc", tc.validator), forms.SubmitField("save", "Save"))
+		r := newUploadRequest(t, map[string]string{"save": "Save"}, uploadFile{"doc", "a.txt", "text/plain", "abc"})
+		if sr, _ := f.OnSubmit(r); sr != forms.SubmitInvalidData {
+			t.Fatalf("invalid data expected, got %v", sr)
+		}
+		errs := f.FieldErrors()["doc"]
+		if len(errs) != 1 || errs[0].Code != tc.code || !reflect.DeepEqual(errs[0].Params, tc.params) {
+			t.Errorf("code %q with %v expected, got %+v", tc.code, tc.params, errs)
+		}
+	}
+}
+
+func TestFieldErrorsJSON(t *testing.T) {
+	f := forms.Define(
+		forms.TextField("name", "Name", forms.Required{}, &forms.MinMaxLength{MinLength: 3}),
+		forms.TextField("age", "Age", forms.UIntValidator(), forms.ValidatorFunc(func(_ *forms.Form, field forms.Field) error {
+			if field.Value() == "x" {
+				return forms.ValidationError("too young")
+			}
+			return nil
+		})),
+		forms.TextField("city", "City"),
+	).StrictMode()
+	if f.SetFormValues(map[string][]string{"extra": {"x"}}, nil) {
+		t.Error("unknown field must be rejected")
+	}
+	b, err := json.Marshal(f.FieldErrors())
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `[{"field":"","code":"unexpected_field","params":{"name":"extra"},"message":"unexpected field: extra"}]`
+	if got := string(b); got != exp {
+		t.Errorf("\nexp: %s\ngot: %s", exp, got)
+	}
+
+	f.SetData(forms.Data{"age": "x"})
+	if f.IsValid() {
+		t.Error("form must be invalid")
+	}
+	if b, err = json.Marshal(f.FieldErrors()); err != nil {
+		t.Fatal(err)
+	}
+	exp = `[{"field":"age","code":"not_an_unsigned","params":{"value":"x"},"message":"age does not contain an unsigned integer value: x"},` +
+		`{"field":"age","code":"invalid","message":"too young"},` +
+		`{"field":"name","code":"required","message":"Required"}]`
+	if got := string(b); got != exp {
+		t.Errorf("\nexp: %s\ngot: %s", exp, got)
+	}
+
+	f.SetData(forms.Data{"age": "18", "name": "Alice"})
+	if !f.IsValid() {
+		t.Errorf("form must be valid, got %v", f.Messages())
+	}
+	if b, err = json.Marshal(f.FieldErrors()); err != nil || string(b) != "[]" {
+		t.Errorf("empty array expected, got %s/%v", b, err)
+	}
+}
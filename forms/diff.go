@@ -0,0 +1,258 @@
+// -----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+// -----------------------------------------------------------------------------
+
+package forms
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"t73f.de/r/webs/htmls"
+)
+
+// Text values with at least this number of runes are diffed at word level.
+const diffMinTextLen = 40
+
+// Maximum number of LCS table cells. Larger texts are shown as a whole.
+const diffMaxCells = 1 << 20
+
+// RenderDiff renders the changes of field values as a table, e.g. for a
+// review page. The changes map field names to the old and the new value.
+//
+// Each changed field results in one row with the field label, the old value,
+// and the new value, in the order of the form fields. Values of select
+// fields are shown with the label of their choice, date and number values
+// are formatted according to the locale of the form, see [Form.SetLocale]. Long text values are diffed at word
+// level: deleted words of the old value are wrapped in <del>, inserted words
+// of the new value are wrapped in <ins>.
+//
+// Unchanged values, unknown fields, and fields without a visible value, like
+// password, submit, and version fields, are not shown. If no row remains,
+// nil is returned.
+func (f *Form) RenderDiff(changes map[string][2]string) *htmls.Node {
+	if f == nil || len(changes) == 0 {
+		return nil
+	}
+	var rows []*htmls.Node
+	var walk func([]Field)
+	walk = func(fields []Field) {
+		for _, field := range fields {
+			if fs, isFieldset := field.(*Fieldset); isFieldset {
+				walk(fs.fields)
+				continue
+			}
+			change, found := changes[field.Name()]
+			if !found || change[0] == change[1] {
+				continue
+			}
+			if row := f.renderDiffRow(field, change[0], change[1]); row != nil {
+				rows = append(rows, row)
+			}
+		}
+	}
+	walk(f.fields)
+	if len(rows) == 0 {
+		return nil
+	}
+	return htmls.Elem("table", htmls.Attrs("class", "form-diff"), htmls.Elem("tbody", nil, rows...))
+}
+
+func (f *Form) renderDiffRow(field Field, oldValue, newValue string) *htmls.Node {
+	var label string
+	var oldNodes, newNodes []*htmls.Node
+	switch fd := field.(type) {
+	case *InputElement:
+		if fd.itype == itypePassword {
+			return nil
+		}
+		label = fd.label
+		if fd.itype == itypeText || fd.itype == itypeEmail {
+			oldNodes, newNodes = diffText(oldValue, newValue)
+		} else {
+			locale := f.Locale()
+			oldNodes = textNodes(locale.formatValue(fd.itype, oldValue))
+			newNodes = textNodes(locale.formatValue(fd.itype, newValue))
+		}
+	case *TextAreaElement:
+		label = fd.label
+		oldNodes, newNodes = diffText(oldValue, newValue)
+	case *SelectElement:
+		label = fd.label
+		oldNodes = textNodes(fd.choiceLabel(oldValue))
+		newNodes = textNodes(fd.choiceLabel(newValue))
+	case *MultiSelectElement:
+		label = fd.label
+		oldNodes = textNodes(fd.choiceLabels(oldValue))
+		newNodes = textNodes(fd.choiceLabels(newValue))
+	case *CheckboxGroupElement:
+		label = fd.label
+		oldNodes = textNodes(fd.choiceLabels(oldValue))
+		newNodes = textNodes(fd.choiceLabels(newValue))
+	case *CheckboxElement:
+		label = fd.label
+		oldNodes = textNodes(checkboxMark(oldValue))
+		newNodes = textNodes(checkboxMark(newValue))
+	default:
+		return nil
+	}
+	return htmls.Elem("tr", nil,
+		htmls.Elem("th", htmls.Attrs("scope", "row"), htmls.Text(label)),
+		htmls.Elem("td", htmls.Attrs("class", "form-diff-old"), oldNodes...),
+		htmls.Elem("td", htmls.Attrs("class", "form-diff-new"), newNodes...),
+	)
+}
+
+func textNodes(s string) []*htmls.Node {
+	if s == "" {
+		return nil
+	}
+	return []*htmls.Node{htmls.Text(s)}
+}
+
+// choiceLabel returns the label of the choice with the given value, or the
+// value itself, if there is no such choice.
+func (se *SelectElement) choiceLabel(value string) string {
+	for i := 0; i < len(se.choices); i += 2 {
+		if se.choices[i] == value {
+			return se.choices[i+1]
+		}
+	}
+	return value
+}
+
+// choiceLabels returns the labels of the choices of the given values,
+// separated by a comma. A value without a choice is used as its label.
+func (mc *multiChoice) choiceLabels(value string) string {
+	if value == "" {
+		return ""
+	}
+	values := strings.Split(value, multiValueSeparator)
+	labels := make([]string, 0, len(values))
+	for _, val := range values {
+		label := val
+		for i := 0; i < len(mc.choices); i += 2 {
+			if mc.choices[i] == val {
+				label = mc.choices[i+1]
+				break
+			}
+		}
+		labels = append(labels, label)
+	}
+	return strings.Join(labels, ", ")
+}
+
+func checkboxMark(value string) string {
+	if value == "" {
+		return "☐" // ballot box
+	}
+	return "☑" // ballot box with check
+}
+
+// diffText returns the nodes of the old and the new text. Long texts are
+// diffed at word level.
+func diffText(oldValue, newValue string) (oldNodes, newNodes []*htmls.Node) {
+	if utf8.RuneCountInString(oldValue) < diffMinTextLen && utf8.RuneCountInString(newValue) < diffMinTextLen {
+		return textNodes(oldValue), textNodes(newValue)
+	}
+	oldWords, newWords := strings.Fields(oldValue), strings.Fields(newValue)
+	if len(oldWords)*len(newWords) > diffMaxCells {
+		return textNodes(oldValue), textNodes(newValue)
+	}
+	for _, op := range diffWords(oldWords, newWords) {
+		text := strings.Join(op.words, " ")
+		switch op.kind {
+		case diffEqual:
+			oldNodes = appendWords(oldNodes, htmls.Text(text))
+			newNodes = appendWords(newNodes, htmls.Text(text))
+		case diffDelete:
+			oldNodes = appendWords(oldNodes, htmls.Elem("del", nil, htmls.Text(text)))
+		case diffInsert:
+			newNodes = appendWords(newNodes, htmls.Elem("ins", nil, htmls.Text(text)))
+		}
+	}
+	return oldNodes, newNodes
+}
+
+// appendWords appends a node with words, separated by a space.
+func appendWords(nodes []*htmls.Node, node *htmls.Node) []*htmls.Node {
+	if len(nodes) > 0 {
+		nodes = append(nodes, htmls.Text(" "))
+	}
+	return append(nodes, node)
+}
+
+type diffKind uint8
+
+const (
+	diffEqual diffKind = iota
+	diffDelete
+	diffInsert
+)
+
+// diffOp is a sequence of words that are equal, deleted, or inserted.
+type diffOp struct {
+	kind  diffKind
+	words []string
+}
+
+// diffWords computes the word level difference of two texts, based on their
+// longest common subsequence.
+func diffWords(oldWords, newWords []string) []diffOp {
+	n, m := len(oldWords), len(newWords)
+
+	// lcs[i][j] is the length of the LCS of oldWords[i:] and newWords[j:].
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if oldWords[i] == newWords[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []diffOp
+	add := func(kind diffKind, word string) {
+		if l := len(ops); l > 0 && ops[l-1].kind == kind {
+			ops[l-1].words = append(ops[l-1].words, word)
+			return
+		}
+		ops = append(ops, diffOp{kind, []string{word}})
+	}
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case oldWords[i] == newWords[j]:
+			add(diffEqual, oldWords[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			add(diffDelete, oldWords[i])
+			i++
+		default:
+			add(diffInsert, newWords[j])
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		add(diffDelete, oldWords[i])
+	}
+	for ; j < m; j++ {
+		add(diffInsert, newWords[j])
+	}
+	return ops
+}
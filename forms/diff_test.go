@@ -0,0 +1,115 @@
+// -----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+// -----------------------------------------------------------------------------
+
+package forms_test
+
+import (
+	"strings"
+	"testing"
+
+	"t73f.de/r/webs/forms"
+	"t73f.de/r/webs/htmls/render"
+)
+
+func renderDiff(t *testing.T, f *forms.Form, changes map[string][2]string) string {
+	t.Helper()
+	var sb strings.Builder
+	if err := render.Render(&sb, f.RenderDiff(changes)); err != nil {
+		t.Fatal(err)
+	}
+	return sb.String()
+}
+
+func diffRow(label, oldHTML, newHTML string) string {
+	return `<tr><th scope="row">` + label + `</th><td class="form-diff-old">` + oldHTML +
+		`</td><td class="form-diff-new">` + newHTML + `</td></tr>`
+}
+
+func TestRenderDiffText(t *testing.T) {
+	f := forms.Define(
+		forms.TextField("title", "Title"),
+		forms.TextAreaField("body", "Content"),
+	)
+	got := renderDiff(t, f, map[string][2]string{
+		"title": {"old", "new"},
+		"body": {
+			"The quick brown fox jumps over the lazy dog.",
+			"The quick red fox jumps over the very lazy dog!",
+		},
+	})
+	exp := `<table class="form-diff"><tbody>` +
+		diffRow("Title", "old", "new") +
+		diffRow("Content",
+			"The quick <del>brown</del> fox jumps over the lazy <del>dog.</del>",
+			"The quick <ins>red</ins> fox jumps over the <ins>very</ins> lazy <ins>dog!</ins>") +
+		`</tbody></table>`
+	if got != exp {
+		t.Errorf("expected\n%s\ngot\n%s", exp, got)
+	}
+}
+
+func TestRenderDiffFormatted(t *testing.T) {
+	f := forms.Define(
+		forms.FieldsetField("meta", "Meta",
+			forms.SelectField("state", "State", []string{"d", "Draft", "p", "Published"}),
+			forms.DateField("due", "Due"),
+			forms.DatetimeField("at", "Publish at"),
+		),
+		forms.NumberField("prio", "Priority"),
+		forms.CheckboxField("public", "Public"),
+	)
+	got := renderDiff(t, f, map[string][2]string{
+		"prio":   {"007", "1.50"},
+		"public": {"", "public"},
+		"state":  {"d", "p"},
+		"due":    {"", "2025-03-01"},
+		"at":     {"2025-03-01T10:00", "2025-03-02T11:30"},
+	})
+	exp := `<table class="form-diff"><tbody>` +
+		diffRow("State", "Draft", "Published") +
+		diffRow("Due", "", "2025-03-01") +
+		diffRow("Publish at", "2025-03-01 10:00", "2025-03-02 11:30") +
+		diffRow("Priority", "7", "1.5") +
+		diffRow("Public", "☐", "☑") +
+		`</tbody></table>`
+	if got != exp {
+		t.Errorf("expected\n%s\ngot\n%s", exp, got)
+	}
+}
+
+func TestRenderDiffExcluded(t *testing.T) {
+	f := forms.Define(
+		forms.VersionField("version", "1"),
+		forms.TextField("name", "Name"),
+		forms.PasswordField("password", "Password"),
+		forms.SubmitField("save", "Save"),
+	)
+	got := renderDiff(t, f, map[string][2]string{
+		"version":  {"1", "2"},
+		"name":     {"same", "same"},
+		"password": {"secret", "geheim"},
+		"save":     {"", "Save"},
+		"unknown":  {"a", "b"},
+	})
+	if got != "" {
+		t.Errorf("no diff expected, got %s", got)
+	}
+
+	got = renderDiff(t, f, map[string][2]string{"name": {"a", "b"}, "password": {"secret", "geheim"}})
+	if exp := `<table class="form-diff"><tbody>` + diffRow("Name", "a", "b") + `</tbody></table>`; got != exp {
+		t.Errorf("expected\n%s\ngot\n%s", exp, got)
+	}
+	if strings.Contains(got, "secret") || strings.Contains(got, "Password") {
+		t.Errorf("password must not be shown: %s", got)
+	}
+}
@@ -32,6 +32,21 @@ type Field interface {
 	Render(string, []string) *htmls.Node
 }
 
+// WarningRenderer is a [Field] that renders warning messages too. Forms
+// render such a field with RenderWarnings instead of Render.
+type WarningRenderer interface {
+	RenderWarnings(fieldID string, messages, warnings []string) *htmls.Node
+}
+
+// renderField renders the field with its messages, and its warnings, if it
+// is a [WarningRenderer].
+func renderField(field Field, fieldID string, messages, warnings []string) *htmls.Node {
+	if wr, ok := field.(WarningRenderer); ok {
+		return wr.RenderWarnings(fieldID, messages, warnings)
+	}
+	return field.Render(fieldID, messages)
+}
+
 // ----- Submit input element
 
 // SubmitElement represents an element <input type="submit" ...>
@@ -42,6 +57,7 @@ type SubmitElement struct {
 	prio           uint8
 	disabled       bool
 	noFormValidate bool
+	fieldRendering
 }
 
 // SubmitField builds a new submit field.
@@ -53,19 +69,14 @@ func SubmitField(name, label string) *SubmitElement {
 }
 
 // SetPriority sets the importance of the field. Only the values 0, 1, 2, and 3
-// are allowed, with 0 being the highest priority.
+// are allowed, with 0 being the highest priority. The priority determines
+// the CSS class, see [FieldRenderer], and the order of consecutive submit
+// fields, see [Form.SetSubmitOrder].
 func (se *SubmitElement) SetPriority(prio uint8) *SubmitElement {
-	se.prio = min(prio, uint8(len(submitPrioClass)-1))
+	se.prio = min(prio, submitPrioCancel)
 	return se
 }
 
-var submitPrioClass = map[uint8]string{
-	0: "primary",
-	1: "secondary",
-	2: "tertiary",
-	3: "cancel", // must always be the last, see se.SetCancel()
-}
-
 // NoFormValidate marks the submit field as an action that disables form
 // validation, if this field causes the form to be sent.
 func (se *SubmitElement) NoFormValidate() *SubmitElement {
@@ -75,7 +86,7 @@ func (se *SubmitElement) NoFormValidate() *SubmitElement {
 
 // SetCancel marks the submit field to work as as a cancel button.
 func (se *SubmitElement) SetCancel() *SubmitElement {
-	se.prio = uint8(len(submitPrioClass) - 1)
+	se.prio = submitPrioCancel
 	se.noFormValidate = true
 	return se
 }
@@ -98,6 +109,8 @@ func (se *SubmitElement) Validators() Validators { return nil }
 // Disable the submit element.
 func (se *SubmitElement) Disable() { se.disabled = true }
 
+func (se *SubmitElement) isDisabled() bool { return se.disabled }
+
 // Render the submit element as SxHTML.
 func (se *SubmitElement) Render(fieldID string, _ []string) *htmls.Node {
 	valAttrs := makeValidatorAttributes(se.Validators())
@@ -107,8 +120,10 @@ func (se *SubmitElement) Render(fieldID string, _ []string) *htmls.Node {
 		htmls.Attribute{Key: "name", Value: se.name},
 		htmls.Attribute{Key: "type", Value: "submit"},
 		htmls.Attribute{Key: "value", Value: se.label},
-		htmls.Attribute{Key: "class", Value: submitPrioClass[se.prio]},
 	)
+	if class := se.renderer().SubmitClass(se.prio); class != "" {
+		attrs = append(attrs, htmls.Attribute{Key: "class", Value: class})
+	}
 	attrs = addEnablingAttributes(attrs, se.disabled, valAttrs)
 	attrs = addBoolAttribute(attrs, "formnovalidate", se.noFormValidate)
 	return htmls.Elem("input", attrs)
@@ -122,6 +137,7 @@ type CheckboxElement struct {
 	label    string
 	value    string
 	disabled bool
+	fieldHelp
 }
 
 // CheckboxField provides a checkbox.
@@ -159,6 +175,8 @@ func (cbe *CheckboxElement) Validators() Validators { return nil }
 // Disable the checkbox element.
 func (cbe *CheckboxElement) Disable() { cbe.disabled = true }
 
+func (cbe *CheckboxElement) isDisabled() bool { return cbe.disabled }
+
 // Render the checkbox element.
 func (cbe *CheckboxElement) Render(fieldID string, _ []string) *htmls.Node {
 	valAttrs := makeValidatorAttributes(cbe.Validators())
@@ -171,11 +189,10 @@ func (cbe *CheckboxElement) Render(fieldID string, _ []string) *htmls.Node {
 	)
 	attrs = addBoolAttribute(attrs, "checked", cbe.value != "")
 	attrs = addEnablingAttributes(attrs, cbe.disabled, valAttrs)
+	attrs = cbe.addDescribedBy(attrs, fieldID, 0)
 
-	return htmls.Elem("div", nil,
-		htmls.Elem("input", attrs),
-		renderLabel(cbe, fieldID, cbe.label),
-	)
+	return cbe.renderer().WrapField(cbe,
+		renderLabel(cbe, fieldID, cbe.label), nil, htmls.Elem("input", attrs), cbe.renderHelp(fieldID))
 }
 
 // ----- <textarea ...>...</textarea> field
@@ -189,6 +206,8 @@ type TextAreaElement struct {
 	value      string
 	validators Validators
 	disabled   bool
+	attrs      fieldAttributes
+	fieldHelp
 }
 
 // TextAreaField creates a new text area element.
@@ -213,6 +232,19 @@ func (tae *TextAreaElement) SetCols(cols uint32) *TextAreaElement {
 	return tae
 }
 
+// SetPlaceholder sets the "placeholder" attribute, a hint that is shown while
+// the text area is empty.
+func (tae *TextAreaElement) SetPlaceholder(text string) *TextAreaElement {
+	return tae.SetAttr("placeholder", text)
+}
+
+// SetAttr sets an arbitrary attribute of the rendered <textarea> element, see
+// [InputElement.SetAttr].
+func (tae *TextAreaElement) SetAttr(key, val string) *TextAreaElement {
+	tae.attrs.set(key, val)
+	return tae
+}
+
 // Name returns the name of the text area element.
 func (tae *TextAreaElement) Name() string { return tae.name }
 
@@ -239,8 +271,15 @@ func (tae *TextAreaElement) Validators() Validators {
 // Disable the text area element.
 func (tae *TextAreaElement) Disable() { tae.disabled = true }
 
-// Render the text area.
+func (tae *TextAreaElement) isDisabled() bool { return tae.disabled }
+
+// Render the text area without warnings.
 func (tae *TextAreaElement) Render(fieldID string, messages []string) *htmls.Node {
+	return tae.RenderWarnings(fieldID, messages, nil)
+}
+
+// RenderWarnings renders the text area, with its error and warning messages.
+func (tae *TextAreaElement) RenderWarnings(fieldID string, messages, warnings []string) *htmls.Node {
 	valAttrs := makeValidatorAttributes(tae.Validators())
 	attrs := makeAttributes(5, valAttrs, tae.rows > 0, tae.cols > 0, tae.disabled)
 	attrs = append(attrs,
@@ -254,14 +293,16 @@ func (tae *TextAreaElement) Render(fieldID string, messages []string) *htmls.Nod
 		attrs = append(attrs, htmls.Attribute{Key: "cols", Value: strconv.FormatUint(uint64(cols), 10)})
 	}
 	attrs = addEnablingAttributes(attrs, tae.disabled, valAttrs)
+	attrs = tae.attrs.merge(attrs)
+	attrs = tae.addDescribedBy(attrs, fieldID, len(messages)+len(warnings))
+	attrs = addInvalid(attrs, messages)
 
-	msgs := renderMessages(messages)
-	divNode := htmls.Elem("div", nil)
-	divNode.Children = make([]*htmls.Node, 2+len(msgs))
-	divNode.AddChildren(renderLabel(tae, fieldID, tae.label))
-	divNode.AddChildren(msgs...)
-	divNode.AddChildren(htmls.Elem("textarea", attrs, htmls.Text(tae.value)))
-	return divNode
+	fr := tae.renderer()
+	return fr.WrapField(tae,
+		renderLabel(tae, fieldID, tae.label),
+		renderAllMessages(fr, fieldID, messages, warnings),
+		htmls.Elem("textarea", attrs, htmls.Text(tae.value)),
+		tae.renderHelp(fieldID))
 }
 
 // ----- <select ...>...</select> field
@@ -274,6 +315,7 @@ type SelectElement struct {
 	value      string
 	validators Validators
 	disabled   bool
+	fieldHelp
 }
 
 // SelectField creates a new select element.
@@ -289,14 +331,16 @@ func SelectField(name, label string, choices []string, validators ...Validator)
 
 // SetChoices allows to update the choices after field creation, e.g. for
 // dynamically generated choices.
-func (se *SelectElement) SetChoices(choices []string) {
+func (se *SelectElement) SetChoices(choices []string) { se.choices = checkChoices(choices) }
+
+// checkChoices returns the given choices, as pairs of value and label.
+func checkChoices(choices []string) []string {
 	if len(choices) == 0 || len(choices) == 1 {
-		se.choices = nil
+		return nil
 	} else if len(choices)%2 != 0 {
-		se.choices = choices[0 : len(choices)-2]
-	} else {
-		se.choices = choices
+		return choices[0 : len(choices)-2]
 	}
+	return choices
 }
 
 // Name returns the element name.
@@ -330,8 +374,16 @@ func (se *SelectElement) Validators() Validators {
 // Disable the field.
 func (se *SelectElement) Disable() { se.disabled = true }
 
-// Render the select element.
+func (se *SelectElement) isDisabled() bool { return se.disabled }
+
+// Render the select element without warnings.
 func (se *SelectElement) Render(fieldID string, messages []string) *htmls.Node {
+	return se.RenderWarnings(fieldID, messages, nil)
+}
+
+// RenderWarnings renders the select element, with its error and warning
+// messages.
+func (se *SelectElement) RenderWarnings(fieldID string, messages, warnings []string) *htmls.Node {
 	valAttrs := makeValidatorAttributes(se.Validators())
 	attrs := makeAttributes(5, valAttrs, se.disabled)
 	attrs = append(attrs,
@@ -339,6 +391,8 @@ func (se *SelectElement) Render(fieldID string, messages []string) *htmls.Node {
 		htmls.Attribute{Key: "name", Value: se.name},
 	)
 	attrs = addEnablingAttributes(attrs, se.disabled, valAttrs)
+	attrs = se.addDescribedBy(attrs, fieldID, len(messages)+len(warnings))
+	attrs = addInvalid(attrs, messages)
 
 	choiceNodes := make([]*htmls.Node, 0, len(se.choices)/2)
 	for i := 0; i < len(se.choices); i += 2 {
@@ -350,10 +404,12 @@ func (se *SelectElement) Render(fieldID string, messages []string) *htmls.Node {
 		choiceNodes = append(choiceNodes, htmls.Elem("option", optAttrs, htmls.Text(se.choices[i+1])))
 	}
 
-	divElem := htmls.Elem("div", nil, renderLabel(se, fieldID, se.label))
-	divElem.Children = append(divElem.Children, renderMessages(messages)...)
-	divElem.Children = append(divElem.Children, htmls.Elem("select", attrs, choiceNodes...))
-	return divElem
+	fr := se.renderer()
+	return fr.WrapField(se,
+		renderLabel(se, fieldID, se.label),
+		renderAllMessages(fr, fieldID, messages, warnings),
+		htmls.Elem("select", attrs, choiceNodes...),
+		se.renderHelp(fieldID))
 }
 
 // EnsureEmptyChoice preprends an empty choice, if it is not already part of the given choices.
@@ -415,18 +471,18 @@ func renderLabel(field Field, fieldID, label string) *htmls.Node {
 	}
 	labelText := htmls.Text(label)
 	if field.Validators().HasRequired() {
-		labelText.Data += "*"
+		labelText.Data += requiredMarker(field)
 	}
 	return htmls.Elem("label", []htmls.Attribute{{Key: "for", Value: fieldID}}, labelText)
 }
 
-func renderMessages(messages []string) []*htmls.Node {
-	result := make([]*htmls.Node, 0, len(messages))
-	for _, msg := range messages {
-		result = append(result,
-			htmls.Elem("span", []htmls.Attribute{{Key: "class", Value: "message"}}, htmls.Text(msg)))
+// requiredMarker returns the text that is appended to the label of a
+// required field.
+func requiredMarker(field Field) string {
+	if rm, ok := field.(interface{ requiredMarker() string }); ok {
+		return rm.requiredMarker()
 	}
-	return result
+	return DefaultMessages.Sprintf(MsgRequiredMarker)
 }
 
 func addBoolAttribute(attrs []htmls.Attribute, key string, val bool) []htmls.Attribute {
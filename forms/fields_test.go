@@ -14,7 +14,16 @@
 package forms_test
 
 import (
+	"fmt"
+	"maps"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"t73f.de/r/webs/forms"
 	"t73f.de/r/webs/htmls"
@@ -28,3 +37,303 @@ func TestFlowContent(t *testing.T) {
 		t.Errorf("expected: %q, but got: %q", exp, got)
 	}
 }
+
+func TestInputAttributes(t *testing.T) {
+	fd := forms.NumberField("amount", "Amount", forms.AttrStep{Value: "1"}).
+		SetStep("0.01").
+		SetPlaceholder("0.00").
+		SetAutocomplete("off").
+		SetAutofocus().
+		SetAttr("Inputmode", "decimal").
+		SetAttr("inputmode", "numeric")
+	got := renderForm(forms.Define(fd))
+	exp := `<form action="" method="POST"><div><label for="amount">Amount</label>` +
+		`<input id="amount" name="amount" type="number" value="" step="0.01" placeholder="0.00" autocomplete="off" autofocus="" inputmode="numeric">` +
+		`</div></form>`
+	if got != exp {
+		t.Errorf("\nexpected: %q\nbut got:  %q", exp, got)
+	}
+}
+
+func TestTextAreaAttributes(t *testing.T) {
+	fd := forms.TextAreaField("bio", "Bio").SetRows(3).SetPlaceholder("About you").SetAttr("rows", "5")
+	got := renderForm(forms.Define(fd))
+	exp := `<form action="" method="POST"><div><label for="bio">Bio</label>` +
+		`<textarea id="bio" name="bio" rows="5" placeholder="About you"></textarea></div></form>`
+	if got != exp {
+		t.Errorf("\nexpected: %q\nbut got:  %q", exp, got)
+	}
+}
+
+func TestSetAttrReserved(t *testing.T) {
+	for _, key := range []string{"id", "name", "type", "value", "ID", ""} {
+		t.Run(key, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("setting attribute %q must panic", key)
+				}
+			}()
+			forms.TextField("text", "Text").SetAttr(key, "x")
+		})
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("setting attribute name of text area must panic")
+		}
+	}()
+	forms.TextAreaField("bio", "Bio").SetAttr("name", "other")
+}
+
+func TestInputTypes(t *testing.T) {
+	testcases := []struct {
+		field *forms.InputElement
+		itype string
+	}{
+		{forms.RangeField("f", "F"), "range"},
+		{forms.ColorField("f", "F"), "color"},
+		{forms.TelField("f", "F"), "tel"},
+		{forms.TimeField("f", "F"), "time"},
+		{forms.MonthField("f", "F"), "month"},
+		{forms.SearchField("f", "F"), "search"},
+	}
+	for _, tc := range testcases {
+		got := renderForm(forms.Define(tc.field))
+		exp := `<form action="" method="POST"><div><label for="f">F</label>` +
+			`<input id="f" name="f" type="` + tc.itype + `" value=""></div></form>`
+		if got != exp {
+			t.Errorf("\nexpected: %q\nbut got:  %q", exp, got)
+		}
+	}
+}
+
+func TestTimeMonthSetValue(t *testing.T) {
+	testcases := []struct {
+		field *forms.InputElement
+		value string
+		valid bool
+	}{
+		{forms.TimeField("t", "T"), "", true},
+		{forms.TimeField("t", "T"), "15:04", true},
+		{forms.TimeField("t", "T"), "15:04:05", true},
+		{forms.TimeField("t", "T"), "15:04:05.250", true},
+		{forms.TimeField("t", "T"), "15:04:65", false},
+		{forms.DatetimeField("dt", "DT"), "2024-01-02T15:04:05", true},
+		{forms.TimeField("t", "T"), "25:00", false},
+		{forms.TimeField("t", "T"), "2024-01", false},
+		{forms.MonthField("m", "M"), "", true},
+		{forms.MonthField("m", "M"), "2024-02", true},
+		{forms.MonthField("m", "M"), "2024-13", false},
+		{forms.MonthField("m", "M"), "2024-02-01", false},
+	}
+	for _, tc := range testcases {
+		if err := tc.field.SetValue(tc.value); (err == nil) != tc.valid {
+			t.Errorf("%s %q: valid=%v expected, got error %v", tc.field.Name(), tc.value, tc.valid, err)
+		}
+	}
+}
+
+func TestRangeMinMax(t *testing.T) {
+	fd := forms.RangeField("vol", "Volume", &forms.MinValue{Value: "0"}, &forms.MaxValue{Value: "11"}).SetStep("0.5")
+	f := forms.Define(fd)
+	got := renderForm(f)
+	exp := `<form action="" method="POST"><div><label for="vol">Volume</label>` +
+		`<input id="vol" name="vol" type="range" value="" min="0" max="11" step="0.5"></div></form>`
+	if got != exp {
+		t.Errorf("\nexpected: %q\nbut got:  %q", exp, got)
+	}
+	if f.SetData(forms.Data{"vol": "12"}) && f.IsValid() {
+		t.Error("value above maximum must be invalid")
+	}
+	if !f.SetData(forms.Data{"vol": "10.5"}) || !f.IsValid() {
+		t.Errorf("value must be valid, got %v", f.Messages())
+	}
+
+	tf := forms.TimeField("at", "At", &forms.MinValue{Value: "08:00"})
+	f = forms.Define(tf)
+	if f.SetData(forms.Data{"at": "07:59"}) && f.IsValid() {
+		t.Error("time before minimum must be invalid")
+	}
+	if got, exp := f.Messages()["at"], "minimum value of at is 08:00, but got 07:59"; len(got) != 1 || got[0] != exp {
+		t.Errorf("message %q expected, got %q", exp, got)
+	}
+	if f.SetData(forms.Data{"at": "07:59:59"}) && f.IsValid() {
+		t.Error("time with seconds before minimum must be invalid")
+	}
+	if !f.SetData(forms.Data{"at": "08:00:01"}) || !f.IsValid() {
+		t.Errorf("time with seconds must be valid, got %v", f.Messages())
+	}
+}
+
+func TestInputNumbers(t *testing.T) {
+	num := forms.NumberField("n", "N")
+	for _, val := range []int64{0, -42, 1 << 40} {
+		if err := num.SetInt(val); err != nil {
+			t.Fatal(err)
+		}
+		if got := num.Int(7); got != val {
+			t.Errorf("%d expected, got %d (value %q)", val, got, num.Value())
+		}
+	}
+	for _, val := range []float64{0, -1.5, 3.25e10, 1e-7} {
+		if err := num.SetFloat(val); err != nil {
+			t.Fatal(err)
+		}
+		if got := num.Float(7); got != val {
+			t.Errorf("%v expected, got %v (value %q)", val, got, num.Value())
+		}
+	}
+	if err := num.SetFloat(0.5); err != nil || num.Value() != "0.5" || num.Int(7) != 7 {
+		t.Errorf("fraction must not be an integer: %q %v", num.Value(), err)
+	}
+	if err := num.SetFloat(math.NaN()); err == nil {
+		t.Error("NaN must be rejected")
+	}
+	_ = num.SetValue("abc")
+	if got := num.Float(2.5); got != 2.5 {
+		t.Errorf("default expected, got %v", got)
+	}
+
+	rng := forms.RangeField("r", "R")
+	if err := rng.SetInt(3); err != nil || rng.Int(0) != 3 {
+		t.Errorf("range field must accept integers: %v", err)
+	}
+
+	text := forms.TextField("t", "T")
+	if err := text.SetInt(1); err == nil {
+		t.Error("text field must not accept an integer")
+	}
+	if err := text.SetFloat(1); err == nil {
+		t.Error("text field must not accept a number")
+	}
+	_ = text.SetValue("17")
+	if got := text.Int(-1); got != -1 {
+		t.Errorf("text field must return the default, got %d", got)
+	}
+}
+
+func TestInputTimes(t *testing.T) {
+	tm := time.Date(2025, time.March, 14, 15, 9, 26, 0, time.Local)
+	testcases := []struct {
+		field *forms.InputElement
+		value string
+		exp   time.Time
+	}{
+		{forms.DateField("d", "D"), "2025-03-14", time.Date(2025, time.March, 14, 0, 0, 0, 0, time.UTC)},
+		{forms.DatetimeField("dt", "DT"), "2025-03-14T15:09", time.Date(2025, time.March, 14, 15, 9, 0, 0, time.Local)},
+		{forms.MonthField("m", "M"), "2025-03", time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)},
+		{forms.TimeField("t", "T"), "15:09", time.Date(0, time.January, 1, 15, 9, 0, 0, time.UTC)},
+	}
+	for _, tc := range testcases {
+		if err := tc.field.SetTime(tm); err != nil {
+			t.Fatal(err)
+		}
+		if got := tc.field.Value(); got != tc.value {
+			t.Errorf("%s: value %q expected, got %q", tc.field.Name(), tc.value, got)
+		}
+		if got, ok := tc.field.Time(); !ok || !got.Equal(tc.exp) {
+			t.Errorf("%s: time %v expected, got %v/%v", tc.field.Name(), tc.exp, got, ok)
+		}
+		if err := tc.field.SetTime(time.Time{}); err != nil || tc.field.Value() != "" {
+			t.Errorf("%s: zero time must clear the value: %q/%v", tc.field.Name(), tc.field.Value(), err)
+		}
+		if _, ok := tc.field.Time(); ok {
+			t.Errorf("%s: empty value must not be a time", tc.field.Name())
+		}
+	}
+
+	num := forms.NumberField("n", "N")
+	if err := num.SetTime(tm); err == nil {
+		t.Error("number field must not accept a time")
+	}
+	_ = num.SetValue("2025-03-14")
+	if _, ok := num.Time(); ok {
+		t.Error("number field must not return a time")
+	}
+}
+
+func TestPasswordConfirmFields(t *testing.T) {
+	newForm := func() *forms.Form {
+		pw, confirm := forms.PasswordConfirmFields("pw", "Password", "Repeat password", &forms.MinMaxLength{MinLength: 6})
+		return forms.Define(forms.EmailField("email", "E-Mail"), pw, confirm, forms.SubmitField("signup", "Sign up"))
+	}
+	submit := func(f *forms.Form, pw, confirm string) forms.SubmitResult {
+		vals := url.Values{"email": {"a@example.com"}, "pw": {pw}, "pw" + forms.ConfirmSuffix: {confirm}, "signup": {"Sign up"}}
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(vals.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		sr, _ := f.OnSubmit(r)
+		return sr
+	}
+
+	f := newForm()
+	if sr := submit(f, "s3cr3t!", "geh3im!"); sr != forms.SubmitInvalidData {
+		t.Fatalf("invalid data expected, got %v", sr)
+	}
+	if exp, got := []string{"passwords do not match"}, f.Messages()["pw-confirm"]; !slices.Equal(exp, got) {
+		t.Errorf("%v expected, got %v", exp, got)
+	}
+	if got := fmt.Sprint(f.FieldErrors()); strings.Contains(got, "s3cr3t") || strings.Contains(got, "geh3im") {
+		t.Errorf("passwords must not appear in errors: %s", got)
+	}
+	got := renderForm(f)
+	if strings.Contains(got, "s3cr3t") || strings.Contains(got, "geh3im") {
+		t.Errorf("passwords must not be rendered: %s", got)
+	}
+	for _, exp := range []string{
+		`<input id="pw" name="pw" type="password" minlength="6" autocomplete="new-password">`,
+		`<input id="pw-confirm" name="pw-confirm" type="password" autocomplete="new-password" aria-describedby="pw-confirm-msg-0" aria-invalid="true">`,
+	} {
+		if !strings.Contains(got, exp) {
+			t.Errorf("%q expected in %s", exp, got)
+		}
+	}
+	f.ClearSensitive()
+	if exp, data := (forms.Data{"email": "a@example.com", "signup": "Sign up"}), f.Data(); !maps.Equal(exp, data) {
+		t.Errorf("%v expected, got %v", exp, data)
+	}
+
+	f = newForm()
+	if sr := submit(f, "s3cr3t!", "s3cr3t!"); sr != forms.SubmitValidData {
+		t.Fatalf("valid data expected, got %v: %v", sr, f.Messages())
+	}
+	if got := f.Data()["pw"]; got != "s3cr3t!" {
+		t.Errorf("password expected before clearing, got %q", got)
+	}
+	f.ClearSensitive()
+	if got := f.Data()["pw"]; got != "" {
+		t.Errorf("password must be cleared, got %q", got)
+	}
+}
+
+// plainField is a field of another package, which does not render warnings.
+type plainField struct{ value string }
+
+func (*plainField) Name() string                   { return "plain" }
+func (pf *plainField) Value() string               { return pf.value }
+func (pf *plainField) Clear()                      { pf.value = "" }
+func (pf *plainField) SetValue(value string) error { pf.value = value; return nil }
+func (*plainField) Validators() forms.Validators {
+	return forms.Validators{forms.ValidatorFunc(func(*forms.Form, forms.Field) error {
+		return forms.WarningError("check")
+	})}
+}
+func (*plainField) Disable() {}
+func (pf *plainField) Render(fieldID string, messages []string) *htmls.Node {
+	return htmls.Elem("p", htmls.Attrs("id", fieldID), htmls.Text(fmt.Sprintf("%s %d", pf.value, len(messages))))
+}
+
+func TestFieldWithoutWarnings(t *testing.T) {
+	f := forms.Define(&plainField{}, forms.TextField("name", "Name", forms.ValidatorFunc(func(*forms.Form, forms.Field) error {
+		return forms.WarningError("check")
+	})))
+	f.SetData(forms.Data{"plain": "value"})
+	if !f.IsValid() || len(f.Warnings()) != 2 {
+		t.Fatalf("valid form with warnings expected, got %v / %v", f.Messages(), f.Warnings())
+	}
+	got := renderForm(f)
+	if !strings.Contains(got, `<p id="plain">value 0</p>`) {
+		t.Errorf("plain field expected, got %s", got)
+	}
+	if !strings.Contains(got, `class="message warning"`) {
+		t.Errorf("warning of text field expected, got %s", got)
+	}
+}
@@ -26,6 +26,8 @@ type Fieldset struct {
 	disabled bool
 }
 
+// setForm attaches the fieldset and all its fields to the form. Nested
+// fieldsets are attached too, by [Form.addName].
 func (fs *Fieldset) setForm(f *Form) {
 	for _, fd := range fs.fields {
 		f.addName(fd)
@@ -70,8 +72,15 @@ func (fs *Fieldset) Disable() {
 	}
 }
 
-// Render the Fieldset.
+// Render the Fieldset without warnings.
 func (fs *Fieldset) Render(fieldID string, messages []string) *htmls.Node {
+	return fs.RenderWarnings(fieldID, messages, nil)
+}
+
+// RenderWarnings renders the Fieldset, with its error and warning messages.
+// If the fieldset does not belong to a form, the names of the fields are
+// used as their identifiers, and no messages are rendered.
+func (fs *Fieldset) RenderWarnings(fieldID string, messages, warnings []string) *htmls.Node {
 	valAttrs := makeValidatorAttributes(fs.Validators())
 	attrs := makeAttributes(5, valAttrs, fs.disabled)
 	attrs = append(attrs,
@@ -79,8 +88,9 @@ func (fs *Fieldset) Render(fieldID string, messages []string) *htmls.Node {
 		htmls.Attribute{Key: "name", Value: fs.name},
 	)
 	attrs = addEnablingAttributes(attrs, fs.disabled, valAttrs)
+	attrs = addDescribedBy(attrs, fieldID, false, len(messages)+len(warnings))
 
-	msgs := renderMessages(messages)
+	msgs := renderAllMessages(fs.form.fieldRenderer(), fieldID, messages, warnings)
 	numChildren := len(msgs) + len(fs.fields)
 	if fs.legend != "" {
 		numChildren++
@@ -91,10 +101,14 @@ func (fs *Fieldset) Render(fieldID string, messages []string) *htmls.Node {
 	if legend := fs.legend; legend != "" {
 		fsNode.Children = append(fsNode.Children, htmls.Elem("legend", nil, htmls.Text(legend)))
 	}
-	fsNode.Children = append(fsNode.Children, renderMessages(messages)...)
+	fsNode.Children = append(fsNode.Children, msgs...)
 	form := fs.form
 	for _, field := range fs.fields {
-		fsNode.Children = append(fsNode.Children, field.Render(form.calcFieldID(field), form.messages[field.Name()]))
+		if form == nil {
+			fsNode.Children = append(fsNode.Children, field.Render(field.Name(), nil))
+			continue
+		}
+		fsNode.Children = append(fsNode.Children, renderField(field, form.calcFieldID(field), form.messages[field.Name()], form.warnings[field.Name()]))
 	}
 
 	return fsNode
@@ -14,9 +14,13 @@
 package forms_test
 
 import (
+	"net/url"
+	"slices"
+	"strings"
 	"testing"
 
 	"t73f.de/r/webs/forms"
+	"t73f.de/r/webs/htmls/render"
 )
 
 func TestBasicFieldset(t *testing.T) {
@@ -39,3 +43,67 @@ func TestBasicFieldset(t *testing.T) {
 		t.Errorf("\nexpected: %q\nbut got:  %q", exp, got)
 	}
 }
+
+func TestNestedFieldset(t *testing.T) {
+	street := forms.TextField("street", "Street", forms.Required{"street required"})
+	zip := forms.TextField("zip", "ZIP", forms.Required{"zip required"}, &forms.MinMaxLength{MaxLength: 5})
+	inner := forms.FieldsetField("address", "Address", street, zip)
+	outer := forms.FieldsetField("person", "Person", forms.TextField("name", "Name"), inner)
+	f := forms.Define(outer, forms.SubmitField("save", "Save"))
+
+	for _, name := range []string{"person", "name", "address", "street", "zip"} {
+		if _, err := f.Field(name); err != nil {
+			t.Errorf("field %q not registered: %v", name, err)
+		}
+	}
+
+	f.SetFormValues(url.Values{"name": {"Alice"}, "zip": {"123456"}}, nil)
+	if f.IsValid() {
+		t.Error("form must not be valid")
+	}
+	msgs := f.Messages()
+	if got := msgs["street"]; !slices.Equal(got, []string{"street required"}) {
+		t.Errorf("street message expected, got %v", got)
+	}
+	if got := msgs["zip"]; len(got) != 1 {
+		t.Errorf("one zip message expected, got %v", got)
+	}
+	got := renderForm(f)
+	for _, s := range []string{
+		`<fieldset id="person" name="person"><legend>Person</legend>`,
+		`<fieldset id="address" name="address"><legend>Address</legend>`,
+		`<input id="zip" name="zip" type="text" value="123456"`,
+		`<span class="message error" id="zip-msg-0">`,
+		`street required`,
+	} {
+		if !strings.Contains(got, s) {
+			t.Errorf("%q expected in %s", s, got)
+		}
+	}
+
+	f.SetFormValues(url.Values{"name": {"Alice"}, "street": {"Main St"}, "zip": {"12345"}}, nil)
+	if !f.IsValid() {
+		t.Errorf("form must be valid, got %v", f.Messages())
+	}
+	data := f.Data()
+	if data["street"] != "Main St" || data["zip"] != "12345" || data["name"] != "Alice" {
+		t.Errorf("nested values expected in data, got %v", data)
+	}
+}
+
+func TestFieldsetWithoutForm(t *testing.T) {
+	fs := forms.FieldsetField("outer", "Outer",
+		forms.TextField("name", "Name"),
+		forms.FieldsetField("inner", "", forms.CheckboxField("admin", "Admin")))
+	var sb strings.Builder
+	if err := render.Render(&sb, fs.Render("outer", nil)); err != nil {
+		t.Fatal(err)
+	}
+	exp := `<fieldset id="outer" name="outer"><legend>Outer</legend>` +
+		`<div><label for="name">Name</label><input id="name" name="name" type="text" value=""></div>` +
+		`<fieldset id="inner" name="inner"><div><input id="admin" name="admin" type="checkbox" value="admin"><label for="admin">Admin</label></div></fieldset>` +
+		`</fieldset>`
+	if got := sb.String(); got != exp {
+		t.Errorf("\nexpected: %s\nbut got:  %s", exp, got)
+	}
+}
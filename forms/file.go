@@ -0,0 +1,242 @@
+// -----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+// -----------------------------------------------------------------------------
+
+package forms
+
+// ----- <input type="file"> fields
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"path"
+	"strings"
+
+	"t73f.de/r/webs/htmls"
+)
+
+// ErrNoFile is returned by [FileElement.Open], if no file was uploaded.
+var ErrNoFile = errors.New("no file uploaded")
+
+// FileElement represents a HTML <input type="file"> field.
+//
+// Its value is the name of the uploaded file. A form that contains a file
+// field is rendered with the encoding "multipart/form-data".
+type FileElement struct {
+	name       string
+	label      string
+	header     *multipart.FileHeader
+	validators Validators
+	disabled   bool
+	fieldHelp
+}
+
+// FileField builds a new field to upload a file.
+func FileField(name, label string, validators ...Validator) *FileElement {
+	return &FileElement{
+		name:       name,
+		label:      label,
+		validators: validators,
+	}
+}
+
+// Name returns the name of this element.
+func (fe *FileElement) Name() string { return fe.name }
+
+// Value returns the name of the uploaded file, or the empty string.
+func (fe *FileElement) Value() string { return fe.Filename() }
+
+// Clear the file element, i.e. forget the uploaded file.
+func (fe *FileElement) Clear() { fe.header = nil }
+
+// SetValue sets the value of this file element.
+//
+// A file cannot be set by a value, only be uploaded. Therefore, only the
+// empty string, which clears the element, and the name of the already
+// uploaded file are accepted.
+func (fe *FileElement) SetValue(value string) error {
+	if value == "" {
+		fe.header = nil
+		return nil
+	}
+	if value == fe.Filename() {
+		return nil
+	}
+	return fmt.Errorf("file %q must be uploaded", value)
+}
+
+// setFile stores the uploaded file.
+func (fe *FileElement) setFile(fh *multipart.FileHeader) { fe.header = fh }
+
+// FileHeader returns the header of the uploaded file, or nil.
+func (fe *FileElement) FileHeader() *multipart.FileHeader { return fe.header }
+
+// Filename returns the name of the uploaded file, as sent by the client.
+func (fe *FileElement) Filename() string {
+	if fe.header == nil {
+		return ""
+	}
+	return fe.header.Filename
+}
+
+// Size returns the size of the uploaded file in bytes.
+func (fe *FileElement) Size() int64 {
+	if fe.header == nil {
+		return 0
+	}
+	return fe.header.Size
+}
+
+// ContentType returns the content type of the uploaded file, as sent by the
+// client. Since it is not checked, it should not be trusted.
+func (fe *FileElement) ContentType() string {
+	if fe.header == nil {
+		return ""
+	}
+	return fe.header.Header.Get("Content-Type")
+}
+
+// Open the uploaded file for reading. The caller must close it.
+func (fe *FileElement) Open() (multipart.File, error) {
+	if fe.header == nil {
+		return nil, ErrNoFile
+	}
+	return fe.header.Open()
+}
+
+// Validators returns all currently active Validators.
+func (fe *FileElement) Validators() Validators {
+	if fe.disabled {
+		return nil
+	}
+	return fe.validators
+}
+
+// Disable the file element.
+func (fe *FileElement) Disable() { fe.disabled = true }
+
+func (fe *FileElement) isDisabled() bool { return fe.disabled }
+
+// Render the file element without warnings.
+func (fe *FileElement) Render(fieldID string, messages []string) *htmls.Node {
+	return fe.RenderWarnings(fieldID, messages, nil)
+}
+
+// RenderWarnings renders the file element as SxHTML, with its error and
+// warning messages.
+//
+// Browsers do not allow to preset the file of an input element, therefore no
+// value is rendered.
+func (fe *FileElement) RenderWarnings(fieldID string, messages, warnings []string) *htmls.Node {
+	valAttrs := makeValidatorAttributes(fe.Validators())
+	attrs := makeAttributes(3, valAttrs, fe.disabled)
+	attrs = append(attrs,
+		htmls.Attribute{Key: "id", Value: fieldID},
+		htmls.Attribute{Key: "name", Value: fe.name},
+		htmls.Attribute{Key: "type", Value: "file"},
+	)
+	attrs = addEnablingAttributes(attrs, fe.disabled, valAttrs)
+	attrs = fe.addDescribedBy(attrs, fieldID, len(messages)+len(warnings))
+	attrs = addInvalid(attrs, messages)
+
+	fr := fe.renderer()
+	return fr.WrapField(fe,
+		renderLabel(fe, fieldID, fe.label),
+		renderAllMessages(fr, fieldID, messages, warnings),
+		htmls.Elem("input", attrs),
+		fe.renderHelp(fieldID))
+}
+
+// ----- MaxFileSize: uploaded file must not be too large.
+
+// MaxFileSize is a validator that checks the size of an uploaded file.
+//
+// Please note that the whole form is limited in size too, which is checked
+// before any validator is executed.
+type MaxFileSize struct {
+	Size    int64 // Maximum size in bytes.
+	Message string
+}
+
+// Check the given field w.r.t. to this validator.
+func (mfs MaxFileSize) Check(f *Form, field Field) error {
+	fe, isFile := field.(*FileElement)
+	if !isFile || fe.header == nil || fe.Size() <= mfs.Size {
+		return nil
+	}
+	msg := mfs.Message
+	if msg == "" {
+		msg = f.sprintf(field, MsgMaxFileSize, field.Name(), mfs.Size, fe.Size())
+	}
+	return CodedValidationError(CodeFileTooLarge, msg, map[string]any{"max": mfs.Size, "size": fe.Size()})
+}
+
+// ----- AcceptFile: uploaded file must be of a specific type.
+
+// AcceptFile is a validator that checks the type of an uploaded file.
+//
+// Types contains MIME types, like "image/png", MIME types with a wildcard
+// subtype, like "image/*", and file name extensions, like ".pdf". A file is
+// accepted, if its content type or its extension matches at least one type.
+// The types are emitted as the HTML "accept" attribute, so that a browser
+// will offer only appropriate files.
+type AcceptFile struct {
+	Types   []string
+	Message string
+}
+
+// Check the given field w.r.t. to this validator.
+func (af AcceptFile) Check(f *Form, field Field) error {
+	fe, isFile := field.(*FileElement)
+	if !isFile || fe.header == nil || len(af.Types) == 0 {
+		return nil
+	}
+	ext := strings.ToLower(path.Ext(fe.Filename()))
+	ct, _, err := mime.ParseMediaType(fe.ContentType())
+	if err != nil {
+		ct = ""
+	}
+	for _, typ := range af.Types {
+		typ = strings.ToLower(strings.TrimSpace(typ))
+		if strings.HasPrefix(typ, ".") {
+			if ext == typ {
+				return nil
+			}
+			continue
+		}
+		if ct == "" {
+			continue
+		}
+		if prefix, isWildcard := strings.CutSuffix(typ, "/*"); isWildcard {
+			if strings.HasPrefix(ct, prefix+"/") {
+				return nil
+			}
+		} else if ct == typ {
+			return nil
+		}
+	}
+	msg := af.Message
+	if msg == "" {
+		msg = f.sprintf(field, MsgFileType, fe.Filename(), field.Name(), strings.Join(af.Types, ", "))
+	}
+	return CodedValidationError(CodeFileType, msg, map[string]any{"filename": fe.Filename(), "accepted": af.Types})
+}
+
+// Attributes returns HTML attributes.
+func (af AcceptFile) Attributes() []htmls.Attribute {
+	if len(af.Types) == 0 {
+		return nil
+	}
+	return []htmls.Attribute{{Key: "accept", Value: strings.Join(af.Types, ",")}}
+}
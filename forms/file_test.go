@@ -0,0 +1,186 @@
+// -----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+// -----------------------------------------------------------------------------
+
+package forms_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"t73f.de/r/webs/forms"
+)
+
+type uploadFile struct {
+	field, filename, contentType, content string
+}
+
+func newUploadRequest(t *testing.T, values map[string]string, files ...uploadFile) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for key, val := range values {
+		if err := mw.WriteField(key, val); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, uf := range files {
+		hdr := make(textproto.MIMEHeader)
+		hdr.Set("Content-Disposition", `form-data; name="`+uf.field+`"; filename="`+uf.filename+`"`)
+		hdr.Set("Content-Type", uf.contentType)
+		w, err := mw.CreatePart(hdr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = io.WriteString(w, uf.content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r := httptest.NewRequest(http.MethodPost, "/", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	return r
+}
+
+func TestFileFieldRender(t *testing.T) {
+	f := forms.Define(
+		forms.FileField("doc", "Document", forms.Required{}, forms.AcceptFile{Types: []string{"image/*", ".pdf"}}),
+		forms.SubmitField("save", "Save"),
+	)
+	got := renderForm(f)
+	exp := `<form action="" method="POST" enctype="multipart/form-data">` +
+		`<div><label for="doc">Document*</label>` +
+		`<input id="doc" name="doc" type="file" required="" accept="image/*,.pdf"></div>` +
+		`<div><input id="save" name="save" type="submit" value="Save" class="primary"></div></form>`
+	if got != exp {
+		t.Errorf("\nexpected: %q\nbut got:  %q", exp, got)
+	}
+
+	f = forms.Define(forms.TextField("name", "Name"))
+	if got = renderForm(f); strings.Contains(got, "enctype") {
+		t.Errorf("form without file field must not have an enctype: %q", got)
+	}
+
+	f = forms.Define(forms.FieldsetField("fs", "Upload", forms.FileField("doc", "Document")))
+	if got = renderForm(f); !strings.Contains(got, `enctype="multipart/form-data"`) {
+		t.Errorf("file field within fieldset must set enctype: %q", got)
+	}
+}
+
+func TestFileFieldUpload(t *testing.T) {
+	fe := forms.FileField("doc", "Document", forms.MaxFileSize{Size: 16})
+	f := forms.Define(forms.TextField("title", "Title"), fe, forms.SubmitField("save", "Save"))
+	r := newUploadRequest(t,
+		map[string]string{"title": "Report", "save": "Save"},
+		uploadFile{"doc", "report.txt", "text/plain", "Hello, World"},
+	)
+	if sr, submit := f.OnSubmit(r); sr != forms.SubmitValidData || submit != "save" {
+		t.Fatalf("valid data expected, got %v/%q: %v", sr, submit, f.Messages())
+	}
+	if got := f.Data()["title"]; got != "Report" {
+		t.Errorf("title %q expected, got %q", "Report", got)
+	}
+	if got := fe.Filename(); got != "report.txt" {
+		t.Errorf("filename %q expected, got %q", "report.txt", got)
+	}
+	if got := fe.Value(); got != "report.txt" {
+		t.Errorf("value %q expected, got %q", "report.txt", got)
+	}
+	if got := fe.Size(); got != 12 {
+		t.Errorf("size 12 expected, got %d", got)
+	}
+	if got := fe.ContentType(); got != "text/plain" {
+		t.Errorf("content type %q expected, got %q", "text/plain", got)
+	}
+	if _, found := f.PresentData()["doc"]; !found {
+		t.Errorf("file field must be present: %v", f.PresentData())
+	}
+	file, err := fe.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = file.Close() }()
+	content, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(content); got != "Hello, World" {
+		t.Errorf("content %q expected, got %q", "Hello, World", got)
+	}
+
+	f.Clear()
+	if fe.FileHeader() != nil {
+		t.Error("file must be cleared")
+	}
+	if _, err = fe.Open(); !errors.Is(err, forms.ErrNoFile) {
+		t.Errorf("ErrNoFile expected, got %v", err)
+	}
+}
+
+func TestFileFieldNoUpload(t *testing.T) {
+	fe := forms.FileField("doc", "Document", forms.Required{})
+	f := forms.Define(fe, forms.SubmitField("save", "Save"))
+	r := newUploadRequest(t, map[string]string{"save": "Save"})
+	if sr, _ := f.OnSubmit(r); sr != forms.SubmitInvalidData {
+		t.Errorf("missing file must be invalid, got %v", sr)
+	}
+	if fe.FileHeader() != nil {
+		t.Error("no file expected")
+	}
+
+	// A previously uploaded file must not survive a new submission.
+	r = newUploadRequest(t, map[string]string{"save": "Save"}, uploadFile{"doc", "a.txt", "text/plain", "a"})
+	if sr, _ := f.OnSubmit(r); sr != forms.SubmitValidData {
+		t.Fatalf("valid data expected, got %v: %v", sr, f.Messages())
+	}
+	r = newUploadRequest(t, map[string]string{"save": "Save"})
+	if sr, _ := f.OnSubmit(r); sr != forms.SubmitInvalidData || fe.FileHeader() != nil {
+		t.Errorf("stale upload: %v, %v", sr, fe.FileHeader())
+	}
+}
+
+func TestFileValidators(t *testing.T) {
+	testcases := []struct {
+		name  string
+		val   forms.Validator
+		file  uploadFile
+		valid bool
+	}{
+		{"size-ok", forms.MaxFileSize{Size: 3}, uploadFile{"doc", "a.txt", "text/plain", "abc"}, true},
+		{"size-large", forms.MaxFileSize{Size: 3}, uploadFile{"doc", "a.txt", "text/plain", "abcd"}, false},
+		{"mime", forms.AcceptFile{Types: []string{"image/png"}}, uploadFile{"doc", "a.bin", "image/png", "x"}, true},
+		{"mime-params", forms.AcceptFile{Types: []string{"text/plain"}}, uploadFile{"doc", "a", "text/plain; charset=utf-8", "x"}, true},
+		{"wildcard", forms.AcceptFile{Types: []string{"image/*"}}, uploadFile{"doc", "a.bin", "image/jpeg", "x"}, true},
+		{"wildcard-other", forms.AcceptFile{Types: []string{"image/*"}}, uploadFile{"doc", "a.bin", "text/plain", "x"}, false},
+		{"ext", forms.AcceptFile{Types: []string{".pdf"}}, uploadFile{"doc", "A.PDF", "application/octet-stream", "x"}, true},
+		{"ext-other", forms.AcceptFile{Types: []string{".pdf", "image/*"}}, uploadFile{"doc", "a.txt", "text/plain", "x"}, false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := forms.Define(forms.FileField("doc", "Document", tc.val), forms.SubmitField("save", "Save"))
+			r := newUploadRequest(t, map[string]string{"save": "Save"}, tc.file)
+			sr, _ := f.OnSubmit(r)
+			if got := sr == forms.SubmitValidData; got != tc.valid {
+				t.Errorf("valid=%v expected, got %v (messages: %v)", tc.valid, got, f.Messages())
+			}
+		})
+	}
+}
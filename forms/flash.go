@@ -0,0 +1,86 @@
+// -----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+// -----------------------------------------------------------------------------
+
+package forms
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"t73f.de/r/webs/flash"
+)
+
+// MaxFlashSize is the maximum size of the serialized form data that is stored
+// by [StashInFlash].
+const MaxFlashSize = 16 * 1024
+
+// flashStash is the data stored in a flash message.
+type flashStash struct {
+	Data     Data     `json:"d,omitempty"`
+	Messages Messages `json:"m,omitempty"`
+	Warnings Messages `json:"w,omitempty"`
+}
+
+// StashInFlash stores the data and the messages of the form as a flash
+// message with the given key, so that they survive a redirect. It is used to
+// implement the post-redirect-get pattern. Values of password and challenge
+// fields are not stored.
+//
+// An error is returned if the data could not be stored, e.g. because it is
+// larger than [MaxFlashSize].
+func StashInFlash(ctx context.Context, f *Form, flasher flash.Flasher, formKey string) error {
+	data := f.Data()
+	for name := range data {
+		if isSecretField(f.fieldnames[name]) {
+			delete(data, name)
+		}
+	}
+	stash, err := json.Marshal(flashStash{Data: data, Messages: f.messages, Warnings: f.warnings})
+	if err != nil {
+		return err
+	}
+	if len(stash) > MaxFlashSize {
+		return fmt.Errorf("form data too large for flash: %d bytes", len(stash))
+	}
+	flasher.Add(ctx, formKey, string(stash))
+	return nil
+}
+
+// RestoreFromFlash populates the form with data and messages, stored by
+// [StashInFlash] with the same key. It returns true, if data was found.
+//
+// Other flash messages are retained.
+func RestoreFromFlash(ctx context.Context, f *Form, flasher flash.Flasher, formKey string) bool {
+	messages := flasher.Messages(ctx)
+	stashes, found := messages[formKey]
+	for key, msgs := range messages {
+		if key != formKey {
+			for _, msg := range msgs {
+				flasher.Add(ctx, key, msg)
+			}
+		}
+	}
+	if !found || len(stashes) == 0 {
+		return false
+	}
+
+	var stash flashStash
+	if err := json.Unmarshal([]byte(stashes[len(stashes)-1]), &stash); err != nil {
+		return false
+	}
+	f.SetData(stash.Data)
+	f.messages = stash.Messages
+	f.warnings = stash.Warnings
+	return true
+}
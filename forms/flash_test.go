@@ -0,0 +1,123 @@
+// -----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+// -----------------------------------------------------------------------------
+
+package forms_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"t73f.de/r/webs/flash"
+	"t73f.de/r/webs/forms"
+	"t73f.de/r/webs/htmls/render"
+	"t73f.de/r/webs/login"
+)
+
+func TestFlashPRG(t *testing.T) {
+	lp := login.MakeProvider(slog.New(slog.DiscardHandler), &login.TestAuthenticator{}, &login.RAMSessions{}, &login.SimpleRedirector{})
+	flasher := flash.MakeMemoryFlasher()
+	newForm := func() *forms.Form {
+		return forms.Define(
+			forms.TextField("name", "Name", forms.Required{}, &forms.MinMaxLength{MinLength: 3}),
+			forms.PasswordField("secret", "Secret", forms.Required{}),
+			forms.SubmitField("save", "Save"),
+		)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /edit", func(w http.ResponseWriter, r *http.Request) {
+		f := newForm()
+		if sr, _ := f.OnSubmit(r); sr != forms.SubmitValidData {
+			flasher.Add(r.Context(), "", "please correct")
+			if err := forms.StashInFlash(r.Context(), f, flasher, "edit"); err != nil {
+				t.Error(err)
+			}
+		}
+		http.Redirect(w, r, "/edit", http.StatusSeeOther)
+	})
+	mux.HandleFunc("GET /edit", func(w http.ResponseWriter, r *http.Request) {
+		f := newForm()
+		if forms.RestoreFromFlash(r.Context(), f, flasher, "edit") {
+			w.Header().Set("X-Restored", "yes")
+		}
+		for _, msg := range flasher.Messages(r.Context())[""] {
+			w.Header().Add("X-Flash", msg)
+		}
+		_ = render.Render(w, f.Render())
+	})
+	h := lp.EnrichUserInfo(mux)
+
+	form := url.Values{lp.UsernameKey: {"user"}, lp.PasswordKey: {"user"}}
+	r := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	lp.Login().ServeHTTP(w, r)
+	cookies := w.Result().Cookies()
+
+	do := func(method string, vals url.Values) *httptest.ResponseRecorder {
+		var body *strings.Reader
+		if vals != nil {
+			body = strings.NewReader(vals.Encode())
+		} else {
+			body = strings.NewReader("")
+		}
+		r := httptest.NewRequest(method, "/edit", body)
+		if vals != nil {
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		for _, c := range cookies {
+			r.AddCookie(c)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w = do(http.MethodPost, url.Values{"name": {"ab"}, "secret": {"s3cr3t"}, "save": {"Save"}})
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("expected redirect, but got %d", w.Code)
+	}
+
+	w = do(http.MethodGet, nil)
+	if w.Header().Get("X-Restored") != "yes" {
+		t.Error("form data was not restored")
+	}
+	if got := w.Header().Get("X-Flash"); got != "please correct" {
+		t.Errorf("other flash message must be retained, but got %q", got)
+	}
+	body := w.Body.String()
+	for _, exp := range []string{
+		`<input id="name" name="name" type="text" value="ab"`,
+		`<span class="message error" id="name-msg-0">minimum length of name is 3, but got 2</span>`,
+		`<input id="secret" name="secret" type="password" required=""`,
+	} {
+		if !strings.Contains(body, exp) {
+			t.Errorf("%q not found in %q", exp, body)
+		}
+	}
+	if strings.Contains(body, "s3cr3t") {
+		t.Errorf("password value must not be restored: %q", body)
+	}
+
+	w = do(http.MethodGet, nil)
+	if w.Header().Get("X-Restored") != "" {
+		t.Error("form data must be restored only once")
+	}
+	if body = w.Body.String(); strings.Contains(body, "message") || strings.Contains(body, `value="ab"`) {
+		t.Errorf("no data expected, but got %q", body)
+	}
+}
@@ -15,12 +15,19 @@
 package forms
 
 import (
+	"cmp"
+	"context"
+	"errors"
 	"fmt"
+	"iter"
 	"mime"
 	"mime/multipart"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"t73f.de/r/webs/htmls"
 )
@@ -33,33 +40,142 @@ type Form struct {
 	fields      []Field
 	fieldnames  map[string]Field
 	messages    Messages
+	warnings    Messages
+	details     map[string][]FieldError // coded errors, see [Form.FieldErrors]
+	present     map[string]bool
+	unknown     Data // submitted values without a field
+	strict      bool // unknown values are an error
+	loadVersion func(context.Context) (string, error)
+	locale      Locale
+	printer     MessagePrinter
+	renderer    FieldRenderer
+	parent      *Form      // form of a micro form, see [Form.MicroForm]
+	idPrefix    string     // prefix of all field identifiers
+	submitOrder []string   // names of submit fields, see [Form.SetSubmitOrder]
+	carryKeys   []string   // query parameters of a GET form, see [Form.CarryQuery]
+	carried     url.Values // values of carried query parameters
 }
 
 // Define builds a new form.
+//
+// It panics, if a field name is invalid or if two fields share the same name,
+// see [DefineE].
 func Define(fields ...Field) *Form {
+	f, err := DefineE(fields...)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// DefineE builds a new form, but returns an error, if a field name is
+// invalid or if two fields share the same name. Names of fields within a
+// fieldset are checked too.
+func DefineE(fields ...Field) (*Form, error) {
 	f := &Form{
 		method:      http.MethodPost,
 		maxFormSize: (10 << 20), // 10 MB
-		fields:      fields,
 		fieldnames:  make(map[string]Field, len(fields)),
 	}
 	for _, field := range fields {
-		f.addName(field)
+		if err := f.AppendE(field); err != nil {
+			return nil, err
+		}
 	}
-	return f
+	return f, nil
 }
 
 // Append a field.
+//
+// It panics, if the name of the field is invalid or already used, see
+// [Form.AppendE].
 func (f *Form) Append(field Field) *Form {
+	if err := f.AppendE(field); err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// AppendE appends a field, but returns an error, if the name of the field or
+// of one of its fieldset children is invalid or already used. In this case,
+// the form is not changed.
+func (f *Form) AppendE(field Field) error {
+	if err := f.checkNames(field, map[string]Field{}); err != nil {
+		return err
+	}
 	f.fields = append(f.fields, field)
 	f.addName(field)
-	return f
+	return nil
 }
 
-func (f *Form) addName(field Field) {
-	f.fieldnames[field.Name()] = field
+// NameSharer is implemented by fields that intentionally share their name
+// with other fields, e.g. the buttons of a radio group. Duplicate names are
+// allowed, if all fields with that name share their name.
+type NameSharer interface {
+	SharesName() bool
+}
+
+func sharesName(field Field) bool {
+	ns, ok := field.(NameSharer)
+	return ok && ns.SharesName()
+}
+
+// checkNames checks the name of the field, and of all fieldset children.
+// Names of fields that are about to be added are stored in pending.
+func (f *Form) checkNames(field Field, pending map[string]Field) error {
+	name := field.Name()
+	if err := checkFieldName(name); err != nil {
+		return err
+	}
+	for _, other := range []Field{f.fieldnames[name], pending[name]} {
+		if other != nil && (!sharesName(field) || !sharesName(other)) {
+			return fmt.Errorf("duplicate field name %q", name)
+		}
+	}
+	pending[name] = field
 	if fs, ok := field.(*Fieldset); ok {
-		fs.setForm(f)
+		for _, child := range fs.fields {
+			if err := f.checkNames(child, pending); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkFieldName checks that the given name is a valid field name, i.e. it
+// is not empty, valid UTF-8, and contains no white space or control
+// characters.
+func checkFieldName(name string) error {
+	if name == "" {
+		return errors.New("empty field name")
+	}
+	if !utf8.ValidString(name) {
+		return fmt.Errorf("field name %q is not valid UTF-8", name)
+	}
+	for _, ch := range name {
+		if unicode.IsSpace(ch) || unicode.IsControl(ch) {
+			return fmt.Errorf("field name %q contains invalid character %q", name, ch)
+		}
+	}
+	return nil
+}
+
+func (f *Form) addName(field Field) {
+	if _, found := f.fieldnames[field.Name()]; !found {
+		f.fieldnames[field.Name()] = field
+	}
+	switch fd := field.(type) {
+	case *Fieldset:
+		fd.setForm(f)
+	case *InputElement:
+		fd.locale = &f.locale
+	}
+	if fp, ok := field.(interface{ setFormPrinter(*MessagePrinter) }); ok {
+		fp.setFormPrinter(&f.printer)
+	}
+	if fr, ok := field.(interface{ setFormRenderer(*FieldRenderer) }); ok {
+		fr.setFormRenderer(&f.renderer)
 	}
 }
 
@@ -68,6 +184,9 @@ func (f *Form) Field(name string) (Field, error) {
 	if field, found := f.fieldnames[name]; found {
 		return field, nil
 	}
+	if f.parent != nil {
+		return f.parent.Field(name)
+	}
 	return nil, fmt.Errorf("no such field: %v", name)
 }
 
@@ -77,14 +196,68 @@ func (f *Form) SetAction(action string) *Form { f.action = action; return f }
 // SetMethodGET updates the "method" attribute to the value "GET".
 func (f *Form) SetMethodGET() *Form { f.method = http.MethodGet; return f }
 
+// SetSubmitOrder overrides the order of rendered submit fields. Within each
+// run of consecutive submit fields, the named fields are rendered first, in
+// the given order. All other submit fields follow, ordered by their
+// priority. Without names, the default order is restored.
+//
+// The order of submit fields does not change the order of other fields.
+func (f *Form) SetSubmitOrder(names ...string) *Form {
+	f.submitOrder = names
+	return f
+}
+
+// CarryQuery names query parameters that are not fields of a GET form, but
+// must survive a submission of the form, e.g. the current page of a list
+// that is filtered by the form. Each value of a named parameter is rendered
+// as a hidden input element. The values are taken from the request of the
+// last call to [Form.ValidRequestForm], or from [Form.SetCarriedValues].
+// Names of fields are ignored.
+//
+// Carried parameters are neither reported by [Form.UnknownData], nor
+// rejected in strict mode. Forms with method POST do not carry parameters.
+func (f *Form) CarryQuery(keys ...string) *Form {
+	f.carryKeys = keys
+	return f
+}
+
+// SetCarriedValues sets the values of the query parameters named with
+// [Form.CarryQuery] explicitly, e.g. when the form is rendered for a
+// request it did not parse.
+func (f *Form) SetCarriedValues(vals url.Values) *Form {
+	f.carried = vals
+	return f
+}
+
 // Clear all field data and messages.
 func (f *Form) Clear() {
 	for _, field := range f.fields {
 		field.Clear()
 	}
 	f.messages = nil
+	f.warnings = nil
+	f.details = nil
+	f.present = nil
+	f.unknown = nil
 }
 
+// ClearSensitive clears the values of all password fields, including those
+// within fieldsets, e.g. after [Form.OnSubmit], regardless of the validity
+// of the submitted data. Other fields and all messages are kept, so that the
+// form can be rendered again.
+func (f *Form) ClearSensitive() {
+	for field := range allFields(f.fields) {
+		if isPassword(field) {
+			field.Clear()
+		}
+	}
+}
+
+// StrictMode lets [Form.SetFormValues] reject submitted values without a
+// corresponding field: a form-level message is added for each of them, and
+// the values are not available via [Form.UnknownData].
+func (f *Form) StrictMode() *Form { f.strict = true; return f }
+
 // Disable the form.
 func (f *Form) Disable() *Form {
 	for _, field := range f.fields {
@@ -135,6 +308,26 @@ func (f *Form) Data() Data {
 	return data
 }
 
+// MultiData returns the map of field names to all values. In contrast to
+// [Form.Data], a field with multiple values, see [MultiField], has all its
+// values as separate elements. All other fields have their single value.
+func (f *Form) MultiData() MultiData {
+	if len(f.fieldnames) == 0 {
+		return nil
+	}
+	data := make(MultiData, len(f.fieldnames))
+	for name, field := range f.fieldnames {
+		if mf, isMulti := field.(MultiField); isMulti {
+			if values := mf.Values(); len(values) > 0 {
+				data[name] = values
+			}
+		} else if value := field.Value(); value != "" {
+			data[name] = []string{value}
+		}
+	}
+	return data
+}
+
 // SetData set field values according to the given data.
 func (f *Form) SetData(data Data) bool {
 	ok := true
@@ -153,20 +346,173 @@ func (f *Form) SetData(data Data) bool {
 	return ok
 }
 
-// SetFormValues populates the form with the given URL values.
-func (f *Form) SetFormValues(vals url.Values, _ *multipart.Form) bool {
-	if len(vals) == 0 {
+// SetFormValues populates the form with the given URL values. Uploaded files
+// of a multipart form are stored in the corresponding file fields, see
+// [FileField]. The multipart form may be nil.
+//
+// In addition, it records which fields were present in the values, see
+// [Form.PresentData], and which values have no corresponding field, see
+// [Form.UnknownData] and [Form.StrictMode].
+func (f *Form) SetFormValues(vals url.Values, mf *multipart.Form) bool {
+	f.present = nil
+	f.unknown = nil
+	var files map[string][]*multipart.FileHeader
+	if mf != nil {
+		files = mf.File
+	}
+	f.setFiles(files)
+	if len(vals) == 0 && len(files) == 0 {
 		return true
 	}
+	ok := f.setUnknown(vals)
 	data := make(Data, len(vals))
 	for name, values := range vals {
-		value := ""
-		if len(values) > 0 {
-			value = values[0]
+		if mf, isMulti := f.fieldnames[name].(MultiField); isMulti {
+			// All values are passed, not only the first one.
+			if err := mf.SetValues(values); err != nil {
+				f.messages = f.messages.Add(name, err.Error())
+				ok = false
+			}
+			continue
 		}
-		data[name] = value
+		data[name] = fieldValue(f.fieldnames[name], values)
 	}
-	return f.SetData(data)
+	f.recordPresence(vals, files)
+	return f.SetData(data) && ok
+}
+
+// fieldValue returns the single value of a field, which was sent as the
+// given values.
+func fieldValue(field Field, values []string) string {
+	switch field.(type) {
+	case *ChallengeElement:
+		return strings.Join(values, "\n")
+	case *OTPElement:
+		// The digits of a one-time code are sent as separate values.
+		return strings.Join(values, "")
+	}
+	if len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// setUnknown records the values without a corresponding field. In strict
+// mode, a message is added for each of them, and false is returned.
+func (f *Form) setUnknown(vals url.Values) bool {
+	var names []string
+	for name := range vals {
+		if _, found := f.fieldnames[name]; !found && !f.isCarried(name) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return true
+	}
+	if f.strict {
+		slices.Sort(names)
+		for _, name := range names {
+			f.addCodedMessage("", CodeUnexpectedField, f.sprintf(nil, MsgUnexpectedField, name), map[string]any{"name": name})
+		}
+		return false
+	}
+	f.unknown = make(Data, len(names))
+	for _, name := range names {
+		f.unknown[name] = strings.Join(vals[name], multiValueSeparator)
+	}
+	return true
+}
+
+// isCarried returns true, if the named query parameter is carried by a GET
+// form, see [Form.CarryQuery].
+func (f *Form) isCarried(name string) bool {
+	return f.method == http.MethodGet && slices.Contains(f.carryKeys, name)
+}
+
+// UnknownData returns the values of the last call to [Form.SetFormValues],
+// whose names do not correspond to a field, e.g. values of input elements
+// that were added by client-side scripts. Multiple values of a name are
+// separated by a newline character. Uploaded files are not contained. If
+// there are no such values, nil is returned.
+func (f *Form) UnknownData() Data { return f.unknown }
+
+// setFiles stores the uploaded files in the enabled file fields. File fields
+// without an uploaded file are cleared.
+func (f *Form) setFiles(files map[string][]*multipart.FileHeader) {
+	for name, field := range f.fieldnames {
+		if fe, isFile := field.(*FileElement); isFile && !fe.disabled {
+			var fh *multipart.FileHeader
+			if fhs := files[name]; len(fhs) > 0 {
+				fh = fhs[0]
+			}
+			fe.setFile(fh)
+		}
+	}
+}
+
+// recordPresence determines which fields are present in the given values.
+//
+// Browsers do not send unchecked checkboxes, and fields with multiple values,
+// if no value is selected. If the values were sent by submitting the
+// rendered form, i.e. they contain a value of a submit element, all enabled
+// checkboxes and multi-value fields are present, even if they are empty.
+// Disabled fields are never sent by browsers and are therefore absent.
+func (f *Form) recordPresence(vals url.Values, files map[string][]*multipart.FileHeader) {
+	rendered := false
+	for name := range vals {
+		if _, isSubmit := f.fieldnames[name].(*SubmitElement); isSubmit {
+			rendered = true
+			break
+		}
+	}
+	present := make(map[string]bool, len(vals))
+	for name, field := range f.fieldnames {
+		if isDisabled(field) {
+			continue
+		}
+		if _, found := vals[name]; found {
+			present[name] = true
+		} else if _, found = files[name]; found {
+			present[name] = true
+		} else if isUnsentWhenEmpty(field) && rendered {
+			present[name] = true
+		}
+	}
+	f.present = present
+}
+
+// isUnsentWhenEmpty returns true, if browsers do not send the field without
+// a value.
+func isUnsentWhenEmpty(field Field) bool {
+	switch field.(type) {
+	case *CheckboxElement, MultiField:
+		return true
+	}
+	return false
+}
+
+// PresentData returns the values of all fields that were present in the
+// last call to [Form.SetFormValues], including those with an empty value.
+// Fields that were absent are not contained. If no values were set, nil is
+// returned.
+func (f *Form) PresentData() PresentData {
+	if f.present == nil {
+		return nil
+	}
+	data := make(PresentData, len(f.present))
+	for name := range f.present {
+		if field, found := f.fieldnames[name]; found {
+			data[name] = field.Value()
+		}
+	}
+	return data
+}
+
+func isDisabled(field Field) bool {
+	if df, ok := field.(interface{ isDisabled() bool }); ok {
+		return df.isDisabled()
+	}
+	return false
 }
 
 // ValidRequestForm populates the form with the values of the given HTTP request,
@@ -176,12 +522,17 @@ func (f *Form) ValidRequestForm(r *http.Request) bool {
 		sr, _ := f.OnSubmit(r)
 		return sr == SubmitValidData
 	}
-	return f.SetFormValues(r.URL.Query(), nil) && f.IsValid()
+	query := r.URL.Query()
+	f.carried = query
+	return f.SetFormValues(query, nil) && f.validate(newValidation(r))
 }
 
 // OnSubmit consumes a POST request, parses incoming data into the form and
 // validates that data. It returns a result, depending on the request, plus
 // the name of the submit field, which causes the request.
+//
+// If a version check was set with [Form.CheckVersion], a conflicting edit
+// results in [SubmitConflict].
 func (f *Form) OnSubmit(r *http.Request) (SubmitResult, string) {
 	if r.Method != http.MethodPost {
 		return SubmitNoData, ""
@@ -209,7 +560,17 @@ func (f *Form) OnSubmit(r *http.Request) (SubmitResult, string) {
 		}
 	}
 
-	if f.SetFormValues(r.PostForm, r.MultipartForm) && f.IsValid() {
+	valid := f.SetFormValues(r.PostForm, r.MultipartForm) && f.validate(newValidation(r))
+	conflict, err := f.checkVersion(r.Context())
+	if err != nil {
+		f.messages = f.messages.Add("", err.Error())
+		return SubmitInvalidData, submitName
+	}
+	if conflict {
+		f.addCodedMessage("", CodeConflict, ConflictMessage, nil)
+		return SubmitConflict, submitName
+	}
+	if valid {
 		return SubmitValidData, submitName
 	}
 	return SubmitInvalidData, submitName
@@ -231,14 +592,18 @@ const (
 
 	// Valid data received.
 	SubmitValidData
+
+	// Data received, but the record was changed concurrently, see
+	// [Form.CheckVersion]. The data may be valid or not.
+	SubmitConflict
 )
 
 // parseForm uses the approriate form parser, depending on the request.
 //
-// Until there is no FileElement, an ordinary ParseForm is suffcient.
-// When a FileElement is added, the form must use a different encoding
+// A form with a [FileElement] is rendered with the encoding
 // "multipart/form-data", instead of the default value
-// "application/x-www-form-urlencoded".
+// "application/x-www-form-urlencoded". Its size is limited by the maximum
+// form size.
 func (f *Form) parseForm(r *http.Request) (err error) {
 	ct := r.Header.Get("Content-Type")
 	if ct != "" {
@@ -254,54 +619,157 @@ func (f *Form) parseForm(r *http.Request) (err error) {
 }
 
 // IsValid returns true if the form has been successfully validates.
-func (f *Form) IsValid() bool {
-	var messages Messages
-	for _, field := range f.fields {
-		fieldName := field.Name()
-		for _, validator := range field.Validators() {
-			if err := validator.Check(f, field); err != nil {
-				if errMsg := err.Error(); errMsg != "" {
-					messages = messages.Add(fieldName, errMsg)
+//
+// Warnings, signaled by a [WarningError], are collected separately and do
+// not make the form invalid.
+//
+// Without a request, a [ChallengeField] is verified without a remote
+// address.
+func (f *Form) IsValid() bool { return f.validate(&validation{}) }
+
+// validate checks all fields, and stores the messages.
+func (f *Form) validate(v *validation) bool {
+	for field := range allFields(f.fields) {
+		f.checkValidators(field, field.Validators(), field.Name(), v)
+	}
+	f.messages, f.warnings, f.details = v.messages, v.warnings, v.details
+	return len(f.messages) == 0
+}
+
+// allFields returns an iterator over all given fields, including the fields
+// of (nested) fieldsets, in the order of their definition.
+func allFields(fields []Field) iter.Seq[Field] {
+	return func(yield func(Field) bool) {
+		var walk func([]Field) bool
+		walk = func(fields []Field) bool {
+			for _, field := range fields {
+				if !yield(field) {
+					return false
 				}
-				if _, isStop := err.(StopValidationError); isStop {
-					break
+				if fs, isFieldset := field.(*Fieldset); isFieldset && !walk(fs.fields) {
+					return false
 				}
 			}
+			return true
+		}
+		walk(fields)
+	}
+}
+
+// checkValidators checks the field with the given validators. Errors and
+// warnings are collected under the given message name.
+func (f *Form) checkValidators(field Field, validators Validators, msgName string, v *validation) {
+	for _, validator := range validators {
+		var err error
+		if rv, isRequestValidator := validator.(requestValidator); isRequestValidator {
+			err = rv.checkRequest(f, v.ctx, v.remoteAddr)
+		} else {
+			err = validator.Check(f, field)
+		}
+		if err != nil && v.add(err, msgName) {
+			break
 		}
 	}
-	f.messages = messages
-	return len(messages) == 0
 }
 
 // Messages return the map of error messages, from an earlier validation.
 func (f *Form) Messages() Messages { return f.messages }
 
+// Warnings return the map of warning messages, from an earlier validation.
+func (f *Form) Warnings() Messages { return f.warnings }
+
 // Render the form.
 func (f *Form) Render() *htmls.Node {
 	if f == nil {
 		return nil
 	}
 	formNode := htmls.Elem("form", htmls.Attrs("action", f.action, "method", f.method))
+	if f.hasFileField() {
+		formNode.Attributes = append(formNode.Attributes, htmls.Attribute{Key: "enctype", Value: "multipart/form-data"})
+	}
 	formNode.Children = make([]*htmls.Node, 0, len(f.fields))
+	formNode.Children = f.appendCarried(formNode.Children)
 
-	submitDivNode := htmls.Elem("div", nil)
+	var submits []*SubmitElement
 	for _, field := range f.fields {
-		fieldID := f.calcFieldID(field)
 		if submitField, isSubmit := field.(*SubmitElement); isSubmit {
-			submitDivNode.Children = append(submitDivNode.Children, submitField.Render(fieldID, nil))
+			submits = append(submits, submitField)
 			continue
 		}
-		if len(submitDivNode.Children) > 0 {
-			formNode.Children = append(formNode.Children, submitDivNode)
-			submitDivNode = htmls.Elem("div", nil)
+		if len(submits) > 0 {
+			formNode.Children = append(formNode.Children, f.renderSubmits(submits))
+			submits = submits[:0]
 		}
-		formNode.Children = append(formNode.Children, field.Render(fieldID, f.messages[field.Name()]))
+		fieldID := f.calcFieldID(field)
+		formNode.Children = append(formNode.Children, renderField(field, fieldID, f.messages[field.Name()], f.warnings[field.Name()]))
 	}
-	if len(submitDivNode.Children) > 0 {
-		formNode.Children = append(formNode.Children, submitDivNode)
+	if len(submits) > 0 {
+		formNode.Children = append(formNode.Children, f.renderSubmits(submits))
 	}
 
 	return formNode
 }
 
-func (*Form) calcFieldID(field Field) string { return field.Name() }
+// appendCarried appends hidden input elements for all values of carried
+// query parameters, see [Form.CarryQuery].
+func (f *Form) appendCarried(nodes []*htmls.Node) []*htmls.Node {
+	for _, key := range f.carryKeys {
+		if _, isField := f.fieldnames[key]; isField || !f.isCarried(key) {
+			continue
+		}
+		for _, value := range f.carried[key] {
+			nodes = append(nodes, htmls.Elem("input", htmls.Attrs("type", "hidden", "name", key, "value", value)))
+		}
+	}
+	return nodes
+}
+
+// renderSubmits renders a run of consecutive submit fields within a <div>.
+// The fields are sorted by the order set with [Form.SetSubmitOrder], and
+// then by their priority, with the primary field first and the cancel field
+// last. Fields of equal rank keep their order of definition.
+func (f *Form) renderSubmits(submits []*SubmitElement) *htmls.Node {
+	rank := func(se *SubmitElement) int {
+		if pos := slices.Index(f.submitOrder, se.name); pos >= 0 {
+			return pos
+		}
+		return len(f.submitOrder) + int(se.prio)
+	}
+	slices.SortStableFunc(submits, func(a, b *SubmitElement) int { return cmp.Compare(rank(a), rank(b)) })
+	divNode := htmls.Elem("div", nil)
+	divNode.Children = make([]*htmls.Node, 0, len(submits))
+	for _, se := range submits {
+		divNode.Children = append(divNode.Children, se.Render(f.calcFieldID(se), nil))
+	}
+	return divNode
+}
+
+// hasFileField returns true, if the form contains a file field, possibly
+// within a fieldset.
+func (f *Form) hasFileField() bool {
+	for _, field := range f.fieldnames {
+		if _, isFile := field.(*FileElement); isFile {
+			return true
+		}
+	}
+	return false
+}
+
+// SetIDPrefix sets a prefix of the identifiers of all fields, including
+// those within fieldsets, e.g. to render the same form twice on one page.
+// Prefix and field name are separated by a dash. All references to an
+// identifier, like the "for" attribute of a label or the
+// "aria-describedby" attribute, use the prefixed identifier. Field names,
+// and therefore the submitted values, are not changed. An empty prefix
+// removes a previously set one.
+func (f *Form) SetIDPrefix(prefix string) *Form {
+	if prefix == "" {
+		f.idPrefix = ""
+	} else {
+		f.idPrefix = prefix + "-"
+	}
+	return f
+}
+
+// calcFieldID returns the identifier of the field, see [Form.SetIDPrefix].
+func (f *Form) calcFieldID(field Field) string { return f.idPrefix + field.Name() }
@@ -15,12 +15,16 @@ package forms_test
 
 import (
 	"maps"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"t73f.de/r/webs/forms"
+	"t73f.de/r/webs/htmls"
 	"t73f.de/r/webs/htmls/render"
 )
 
@@ -75,3 +79,256 @@ func TestRenderNilForm(t *testing.T) {
 		t.Errorf("nil snippet expected, but got: %v", got)
 	}
 }
+
+type sharedCheckbox struct{ *forms.CheckboxElement }
+
+func (sharedCheckbox) SharesName() bool { return true }
+
+func TestDefineNames(t *testing.T) {
+	testcases := []struct {
+		name   string
+		fields []forms.Field
+		valid  bool
+	}{
+		{"ok", []forms.Field{forms.TextField("a", "A"), forms.TextField("b", "B")}, true},
+		{"duplicate", []forms.Field{forms.TextField("a", "A"), forms.TextField("a", "B")}, false},
+		{"fieldset-duplicate", []forms.Field{
+			forms.TextField("a", "A"),
+			forms.FieldsetField("fs", "FS", forms.CheckboxField("a", "A")),
+		}, false},
+		{"fieldset-name", []forms.Field{forms.FieldsetField("a", "FS", forms.TextField("a", "A"))}, false},
+		{"fieldset-ok", []forms.Field{forms.FieldsetField("fs", "FS", forms.TextField("a", "A"))}, true},
+		{"empty", []forms.Field{forms.TextField("", "A")}, false},
+		{"space", []forms.Field{forms.TextField("a b", "A")}, false},
+		{"control", []forms.Field{forms.TextField("a\x00", "A")}, false},
+		{"utf8", []forms.Field{forms.TextField("a\xff", "A")}, false},
+		{"unicode", []forms.Field{forms.TextField("größe", "A")}, true},
+		{"shared", []forms.Field{
+			sharedCheckbox{forms.CheckboxField("r", "1")},
+			sharedCheckbox{forms.CheckboxField("r", "2")},
+		}, true},
+		{"half-shared", []forms.Field{
+			sharedCheckbox{forms.CheckboxField("r", "1")},
+			forms.CheckboxField("r", "2"),
+		}, false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := forms.DefineE(tc.fields...)
+			if tc.valid && (err != nil || f == nil) {
+				t.Errorf("valid form expected, but got error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Error("error expected")
+			}
+		})
+	}
+
+	f := forms.Define(forms.TextField("a", "A"))
+	if err := f.AppendE(forms.FieldsetField("fs", "FS", forms.TextField("b", "B"), forms.TextField("a", "A"))); err == nil {
+		t.Error("duplicate name in appended fieldset expected")
+	}
+	if len(f.Fields()) != 1 {
+		t.Errorf("form must not change on error, but got %d fields", len(f.Fields()))
+	}
+	if _, err := f.Field("b"); err == nil {
+		t.Error("field b must not be added on error")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Append must panic on duplicate name")
+		}
+	}()
+	f.Append(forms.TextField("a", "A"))
+}
+
+func newURLEncodedRequest(vals url.Values) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(vals.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
+
+func TestUnknownData(t *testing.T) {
+	newForm := func() *forms.Form {
+		return forms.Define(
+			forms.TextField("title", "Title", forms.Required{}),
+			forms.SubmitField("save", "Save"),
+		)
+	}
+	testcases := []struct {
+		name string
+		req  func() *http.Request
+	}{
+		{"urlencoded", func() *http.Request {
+			return newURLEncodedRequest(url.Values{"title": {"T"}, "save": {"Save"}, "row-1": {"a"}, "row-2": {"b", "c"}})
+		}},
+		{"multipart", func() *http.Request {
+			return newUploadRequest(t, map[string]string{"title": "T", "save": "Save", "row-1": "a", "row-2": "b\nc"},
+				uploadFile{"attachment", "a.txt", "text/plain", "ignored"})
+		}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newForm()
+			if sr, _ := f.OnSubmit(tc.req()); sr != forms.SubmitValidData {
+				t.Fatalf("valid data expected, got %v: %v", sr, f.Messages())
+			}
+			if exp, got := (forms.Data{"row-1": "a", "row-2": "b\nc"}), f.UnknownData(); !maps.Equal(exp, got) {
+				t.Errorf("%v expected, got %v", exp, got)
+			}
+			if exp, got := (forms.Data{"title": "T", "save": "Save"}), f.Data(); !maps.Equal(exp, got) {
+				t.Errorf("%v expected, got %v", exp, got)
+			}
+
+			f = newForm().StrictMode()
+			if sr, _ := f.OnSubmit(tc.req()); sr != forms.SubmitInvalidData {
+				t.Fatalf("invalid data expected in strict mode, got %v", sr)
+			}
+			exp := forms.Messages{"": {"unexpected field: row-1", "unexpected field: row-2"}}
+			if got := f.Messages(); !maps.EqualFunc(exp, got, slices.Equal) {
+				t.Errorf("%v expected, got %v", exp, got)
+			}
+			if got := f.UnknownData(); got != nil {
+				t.Errorf("no unknown data expected in strict mode, got %v", got)
+			}
+		})
+	}
+
+	f := newForm()
+	f.SetFormValues(url.Values{"title": {"T"}}, nil)
+	if got := f.UnknownData(); got != nil {
+		t.Errorf("no unknown data expected, got %v", got)
+	}
+}
+
+func TestIDPrefix(t *testing.T) {
+	makeForm := func(prefix string) *forms.Form {
+		name := forms.TextField("name", "Name", forms.Required{"name required"})
+		name.SetHelp("Your full name")
+		f := forms.Define(
+			name,
+			forms.FieldsetField("details", "Details",
+				forms.SelectField("size", "Size", []string{"s", "Small", "l", "Large"}),
+				forms.CheckboxGroupField("extras", "Extras", []string{"n", "Napkins", "d", "Dip"}),
+				forms.OTPField("code", 4),
+			),
+			forms.ChallengeField("human", forms.NewMathChallenge([]byte("secret"), time.Minute, 3)),
+			forms.SubmitField("save", "Save"),
+		).SetIDPrefix(prefix)
+		f.SetFormValues(url.Values{}, nil)
+		f.IsValid()
+		return f
+	}
+	root := htmls.Elem("body", nil, makeForm("header").Render(), makeForm("main").Render())
+
+	ids := map[string]int{}
+	var refs, names []string
+	var walk func(*htmls.Node)
+	walk = func(node *htmls.Node) {
+		for _, attr := range node.Attributes {
+			switch attr.Key {
+			case "id":
+				ids[attr.Value]++
+			case "for":
+				refs = append(refs, attr.Value)
+			case "aria-describedby":
+				refs = append(refs, strings.Fields(attr.Value)...)
+			case "name":
+				names = append(names, attr.Value)
+			}
+		}
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	walk(root)
+
+	for id, count := range ids {
+		if count > 1 {
+			t.Errorf("duplicate id %q", id)
+		}
+		if !strings.HasPrefix(id, "header-") && !strings.HasPrefix(id, "main-") {
+			t.Errorf("id %q without prefix", id)
+		}
+	}
+	for _, ref := range refs {
+		if ids[ref] == 0 {
+			t.Errorf("reference to unknown id %q", ref)
+		}
+	}
+	for _, s := range []string{"header-name", "main-name", "main-name-help", "main-name-msg-0", "header-size", "header-extras-1", "main-code-3", "header-human"} {
+		if ids[s] == 0 {
+			t.Errorf("id %q expected, got %v", s, ids)
+		}
+	}
+	for _, name := range names {
+		if strings.HasPrefix(name, "header-") || strings.HasPrefix(name, "main-") {
+			t.Errorf("name %q must not be prefixed", name)
+		}
+	}
+
+	f := makeForm("header").SetIDPrefix("")
+	if got := renderForm(f); !strings.Contains(got, `<label for="name">`) {
+		t.Errorf("unprefixed label expected, got %s", got)
+	}
+}
+
+func TestCarryQuery(t *testing.T) {
+	newForm := func() *forms.Form {
+		return forms.Define(
+			forms.TextField("q", "Filter"),
+			forms.SubmitField("search", "Search"),
+		).SetMethodGET().StrictMode().CarryQuery("page", "q", "sort")
+	}
+	f := newForm()
+	r := httptest.NewRequest(http.MethodGet, "/list?q=foo&page=3&sort=a&sort=b&other=x", nil)
+	if f.ValidRequestForm(r) {
+		t.Fatal("unknown parameter must be rejected in strict mode")
+	}
+	exp := forms.Messages{"": {"unexpected field: other"}}
+	if got := f.Messages(); !maps.EqualFunc(exp, got, slices.Equal) {
+		t.Errorf("%v expected, got %v", exp, got)
+	}
+
+	f = newForm()
+	r = httptest.NewRequest(http.MethodGet, "/list?q=foo&page=3&sort=a&sort=b", nil)
+	if !f.ValidRequestForm(r) {
+		t.Fatalf("valid form expected, got %v", f.Messages())
+	}
+	got := renderNode(t, f.Render())
+	for _, s := range []string{
+		`<form action="" method="GET"><input type="hidden" name="page" value="3"><input type="hidden" name="sort" value="a"><input type="hidden" name="sort" value="b">`,
+		`name="q" type="text" value="foo"`,
+	} {
+		if !strings.Contains(got, s) {
+			t.Errorf("%q expected in %s", s, got)
+		}
+	}
+	if strings.Contains(got, `type="hidden" name="q"`) {
+		t.Errorf("field must not be carried: %s", got)
+	}
+
+	// Submitting the rendered form again yields the same values.
+	f = newForm()
+	r = httptest.NewRequest(http.MethodGet, "/list?page=3&sort=a&sort=b&q=foo&search=Search", nil)
+	if !f.ValidRequestForm(r) {
+		t.Fatalf("valid form expected, got %v", f.Messages())
+	}
+	if data := f.Data(); data["q"] != "foo" {
+		t.Errorf("filter value foo expected, got %v", data)
+	}
+	if got2 := renderNode(t, f.Render()); got2 != got {
+		t.Errorf("round trip changed the form:\n%s\n%s", got, got2)
+	}
+
+	f = newForm().SetCarriedValues(url.Values{"page": {"7"}})
+	if got = renderNode(t, f.Render()); !strings.Contains(got, `<input type="hidden" name="page" value="7">`) {
+		t.Errorf("explicit carried value expected, got %s", got)
+	}
+
+	f = forms.Define(forms.TextField("q", "Filter")).CarryQuery("page").SetCarriedValues(url.Values{"page": {"7"}})
+	if got = renderNode(t, f.Render()); strings.Contains(got, "hidden") {
+		t.Errorf("POST form must not carry parameters, got %s", got)
+	}
+}
@@ -0,0 +1,115 @@
+// -----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+// -----------------------------------------------------------------------------
+
+package forms
+
+// ----- Help text of fields
+
+import (
+	"strconv"
+	"strings"
+
+	"t73f.de/r/webs/htmls"
+)
+
+// CSS classes of rendered help.
+const (
+	classHelp        = "help"
+	classHelpDetails = "help-details"
+)
+
+// fieldHelp stores the help of a field. It is embedded into all input-like
+// fields.
+type fieldHelp struct {
+	help    *htmls.Node
+	summary string
+	details *htmls.Node
+	fieldMessages
+	fieldRendering
+}
+
+// SetHelp sets a short help text, which is rendered after the input element.
+// An empty text removes the help.
+func (fh *fieldHelp) SetHelp(text string) {
+	if text == "" {
+		fh.help = nil
+	} else {
+		fh.help = htmls.Text(text)
+	}
+}
+
+// SetHelpNode sets a short help, which is rendered after the input element.
+// A nil node removes the help.
+func (fh *fieldHelp) SetHelpNode(node *htmls.Node) { fh.help = node }
+
+// SetDetails sets a longer explanation, which is rendered as an expandable
+// <details> element with the given summary. A nil body removes the details.
+func (fh *fieldHelp) SetDetails(summary string, body *htmls.Node) {
+	fh.summary, fh.details = summary, body
+}
+
+// helpID returns the identifier of the help element.
+func helpID(fieldID string) string { return fieldID + "-help" }
+
+// messageID returns the identifier of the i-th message of a field.
+func messageID(fieldID string, i int) string { return fieldID + "-msg-" + strconv.Itoa(i) }
+
+// renderHelp renders the help and the details, if there are any.
+func (fh *fieldHelp) renderHelp(fieldID string) []*htmls.Node {
+	var result []*htmls.Node
+	if help := fh.help; help != nil {
+		result = append(result,
+			htmls.Elem("small", htmls.Attrs("class", classHelp, "id", helpID(fieldID)), help))
+	}
+	if body := fh.details; body != nil {
+		result = append(result, htmls.Elem("details", htmls.Attrs("class", classHelpDetails),
+			htmls.Elem("summary", nil, htmls.Text(fh.summary)),
+			body,
+		))
+	}
+	return result
+}
+
+// addDescribedBy adds the "aria-describedby" attribute, if there is a help
+// or a message. It references the help and all messages.
+func (fh *fieldHelp) addDescribedBy(attrs []htmls.Attribute, fieldID string, numMessages int) []htmls.Attribute {
+	return addDescribedBy(attrs, fieldID, fh.help != nil, numMessages)
+}
+
+// addDescribedBy adds the "aria-describedby" attribute, which references
+// the help, if there is one, and the given number of messages.
+func addDescribedBy(attrs []htmls.Attribute, fieldID string, hasHelp bool, numMessages int) []htmls.Attribute {
+	if !hasHelp && numMessages == 0 {
+		return attrs
+	}
+	var sb strings.Builder
+	if hasHelp {
+		sb.WriteString(helpID(fieldID))
+	}
+	for i := range numMessages {
+		if sb.Len() > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(messageID(fieldID, i))
+	}
+	return append(attrs, htmls.Attribute{Key: "aria-describedby", Value: sb.String()})
+}
+
+// addInvalid adds the attribute aria-invalid="true", if there are error
+// messages. Warnings do not make a value invalid.
+func addInvalid(attrs []htmls.Attribute, messages []string) []htmls.Attribute {
+	if len(messages) == 0 {
+		return attrs
+	}
+	return append(attrs, htmls.Attribute{Key: "aria-invalid", Value: "true"})
+}
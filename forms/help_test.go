@@ -0,0 +1,136 @@
+// -----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+// -----------------------------------------------------------------------------
+
+package forms_test
+
+import (
+	"strings"
+	"testing"
+
+	"t73f.de/r/webs/forms"
+	"t73f.de/r/webs/htmls"
+)
+
+func TestHelpText(t *testing.T) {
+	fd := forms.TextField("email", "E-Mail")
+	fd.SetHelp("We'll never share your email")
+	got := renderForm(forms.Define(fd))
+	exp := `<form action="" method="POST"><div><label for="email">E-Mail</label>` +
+		`<input id="email" name="email" type="text" value="" aria-describedby="email-help">` +
+		`<small class="help" id="email-help">We&#39;ll never share your email</small></div></form>`
+	if got != exp {
+		t.Errorf("\nexpected: %q\nbut got:  %q", exp, got)
+	}
+
+	fd.SetHelp("")
+	exp = `<form action="" method="POST"><div><label for="email">E-Mail</label>` +
+		`<input id="email" name="email" type="text" value=""></div></form>`
+	if got = renderForm(forms.Define(fd)); got != exp {
+		t.Errorf("\nexpected: %q\nbut got:  %q", exp, got)
+	}
+}
+
+func TestHelpNode(t *testing.T) {
+	fd := forms.SelectField("color", "Color", []string{"r", "Red"})
+	fd.SetHelpNode(htmls.Elem("a", htmls.Attrs("href", "/colors"), htmls.Text("Colors")))
+	got := renderForm(forms.Define(fd))
+	exp := `<form action="" method="POST"><div><label for="color">Color</label>` +
+		`<select id="color" name="color" aria-describedby="color-help"><option value="r">Red</option></select>` +
+		`<small class="help" id="color-help"><a href="/colors">Colors</a></small></div></form>`
+	if got != exp {
+		t.Errorf("\nexpected: %q\nbut got:  %q", exp, got)
+	}
+}
+
+func TestHelpDetails(t *testing.T) {
+	fd := forms.TextAreaField("bio", "Bio")
+	fd.SetDetails("Why?", htmls.Elem("p", nil, htmls.Text("It is shown to others.")))
+	got := renderForm(forms.Define(fd))
+	exp := `<form action="" method="POST"><div><label for="bio">Bio</label>` +
+		`<textarea id="bio" name="bio"></textarea>` +
+		`<details class="help-details"><summary>Why?</summary><p>It is shown to others.</p></details>` +
+		`</div></form>`
+	if got != exp {
+		t.Errorf("\nexpected: %q\nbut got:  %q", exp, got)
+	}
+}
+
+func TestHelpWithMessages(t *testing.T) {
+	fd := forms.TextField("name", "Name", &forms.MinMaxLength{MinLength: 3},
+		forms.ValidatorFunc(func(*forms.Form, forms.Field) error { return forms.WarningError("lower case only") }))
+	fd.SetHelp("Your full name")
+	f := forms.Define(fd)
+	f.SetData(forms.Data{"name": "de"})
+	f.IsValid()
+	got := renderForm(f)
+	exp := `<form action="" method="POST"><div><label for="name">Name</label>` +
+		`<span class="message error" id="name-msg-0">minimum length of name is 3, but got 2</span>` +
+		`<span class="message warning" id="name-msg-1">lower case only</span>` +
+		`<input id="name" name="name" type="text" value="de" minlength="3" aria-describedby="name-help name-msg-0 name-msg-1" aria-invalid="true">` +
+		`<small class="help" id="name-help">Your full name</small></div></form>`
+	if got != exp {
+		t.Errorf("\nexpected: %q\nbut got:  %q", exp, got)
+	}
+}
+
+func TestHelpDisabled(t *testing.T) {
+	fd := forms.CheckboxField("agree", "Agree")
+	fd.SetHelp("Required to continue")
+	got := renderForm(forms.Define(fd).Disable())
+	exp := `<form action="" method="POST"><div>` +
+		`<input id="agree" name="agree" type="checkbox" value="agree" disabled="" aria-describedby="agree-help">` +
+		`<label for="agree">Agree</label><small class="help" id="agree-help">Required to continue</small></div></form>`
+	if got != exp {
+		t.Errorf("\nexpected: %q\nbut got:  %q", exp, got)
+	}
+}
+
+func TestMessageARIA(t *testing.T) {
+	warn := forms.ValidatorFunc(func(_ *forms.Form, field forms.Field) error {
+		if field.Value() == "warn" {
+			return forms.WarningError("check value")
+		}
+		return nil
+	})
+	f := forms.Define(
+		forms.TextField("name", "Name", forms.Required{"name required"}, warn),
+		forms.TextAreaField("note", "Note", forms.Required{"note required"}),
+		forms.SelectField("size", "Size", []string{"", "-", "s", "Small"}, forms.Required{"size required"}),
+		forms.FieldsetField("extra", "Extra", forms.TextField("city", "City", forms.Required{"city required"})),
+	)
+	f.SetData(forms.Data{})
+	f.IsValid()
+	got := renderForm(f)
+	for _, s := range []string{
+		`<input id="name" name="name" type="text" value="" required="" aria-describedby="name-msg-0" aria-invalid="true">`,
+		`<textarea id="note" name="note" required="" aria-describedby="note-msg-0" aria-invalid="true">`,
+		`<select id="size" name="size" required="" aria-describedby="size-msg-0" aria-invalid="true">`,
+		`<input id="city" name="city" type="text" value="" required="" aria-describedby="city-msg-0" aria-invalid="true">`,
+		`id="city-msg-0">city required</span>`,
+	} {
+		if !strings.Contains(got, s) {
+			t.Errorf("%q expected in %s", s, got)
+		}
+	}
+
+	// Warnings are described, but the value is not invalid.
+	f.SetData(forms.Data{"name": "warn", "note": "x", "size": "s", "city": "y"})
+	f.IsValid()
+	got = renderForm(f)
+	if exp := `<input id="name" name="name" type="text" value="warn" required="" aria-describedby="name-msg-0">`; !strings.Contains(got, exp) {
+		t.Errorf("%q expected in %s", exp, got)
+	}
+	if n := strings.Count(got, "aria-"); n != 1 {
+		t.Errorf("only one aria attribute expected, got %s", got)
+	}
+}
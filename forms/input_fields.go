@@ -16,6 +16,9 @@ package forms
 // ----- <input ...> fields
 
 import (
+	"fmt"
+	"math"
+	"strconv"
 	"time"
 
 	"t73f.de/r/webs/htmls"
@@ -29,6 +32,9 @@ type InputElement struct {
 	validators Validators
 	disabled   bool
 	itype      inputType
+	locale     *Locale // locale of the form, if field is part of a form
+	attrs      fieldAttributes
+	fieldHelp
 }
 
 type inputType uint
@@ -37,12 +43,18 @@ type inputType uint
 const (
 	_ inputType = iota
 	itypeCheckbox
+	itypeColor
 	itypeDate
 	itypeDatetime
 	itypeEmail
+	itypeMonth
 	itypeNumber
 	itypePassword
+	itypeRange
+	itypeSearch
+	itypeTel
 	itypeText
+	itypeTime
 )
 
 // Name returns the name of this element.
@@ -51,23 +63,157 @@ func (fd *InputElement) Name() string { return fd.name }
 // Value returns the value of the input element.
 func (fd *InputElement) Value() string { return fd.value }
 
+// DisplayValue returns the value of the input element, formatted for humans
+// according to the locale of the form, see [Form.SetLocale], e.g. to show a
+// submitted value outside the form. The value of a password field is never
+// shown.
+func (fd *InputElement) DisplayValue() string {
+	if fd.itype == itypePassword {
+		return ""
+	}
+	var l Locale
+	if fd.locale != nil {
+		l = *fd.locale
+	}
+	return l.formatValue(fd.itype, fd.value)
+}
+
 // Clear the input element.
 func (fd *InputElement) Clear() { fd.value = "" }
 
 // SetValue sets the value of this input element.
 func (fd *InputElement) SetValue(value string) (err error) {
 	fd.value = value
+	if layout := fd.timeLayout(); layout != "" && value != "" {
+		_, err = parseHTMLTime(layout, value, time.UTC)
+	}
+	return err
+}
+
+// SetInt sets the value of a number or range field to the given integer. An
+// error is returned for other fields.
+func (fd *InputElement) SetInt(val int64) error {
+	if !fd.isNumeric() {
+		return fmt.Errorf("field %s is not a number field", fd.name)
+	}
+	fd.value = strconv.FormatInt(val, 10)
+	return nil
+}
+
+// SetFloat sets the value of a number or range field to the given number.
+// An error is returned for other fields, or if the number is not finite.
+func (fd *InputElement) SetFloat(val float64) error {
+	if !fd.isNumeric() {
+		return fmt.Errorf("field %s is not a number field", fd.name)
+	}
+	if math.IsNaN(val) || math.IsInf(val, 0) {
+		return fmt.Errorf("field %s: number %v is not finite", fd.name, val)
+	}
+	fd.value = strconv.FormatFloat(val, 'f', -1, 64)
+	return nil
+}
+
+// Int returns the value of a number or range field as an integer. The
+// default value is returned, if the value is not an integer, or if the field
+// is not a number field.
+func (fd *InputElement) Int(defaultValue int64) int64 {
+	if fd.isNumeric() {
+		if result, err := strconv.ParseInt(fd.value, 10, 64); err == nil {
+			return result
+		}
+	}
+	return defaultValue
+}
+
+// Float returns the value of a number or range field as a number. The
+// default value is returned, if the value is not a number, or if the field
+// is not a number field.
+func (fd *InputElement) Float(defaultValue float64) float64 {
+	if fd.isNumeric() {
+		if result, err := strconv.ParseFloat(fd.value, 64); err == nil && !math.IsNaN(result) && !math.IsInf(result, 0) {
+			return result
+		}
+	}
+	return defaultValue
+}
+
+func (fd *InputElement) isNumeric() bool { return fd.itype == itypeNumber || fd.itype == itypeRange }
+
+// SetTime sets the value of a date, date/time, month, or time field. Only
+// the parts of the time that are relevant for the field are stored, e.g.
+// the date of a date field. The zero time clears the value. An error is
+// returned for other fields.
+func (fd *InputElement) SetTime(t time.Time) error {
+	layout := fd.timeLayout()
+	if layout == "" {
+		return fmt.Errorf("field %s is not a date or time field", fd.name)
+	}
+	if t.IsZero() {
+		fd.value = ""
+	} else {
+		fd.value = t.Format(layout)
+	}
+	return nil
+}
+
+// Time returns the value of a date, date/time, month, or time field. A
+// date/time value is interpreted in the local time zone, all other values in
+// UTC. False is returned, if the field has no valid value or if it is not a
+// date or time field.
+func (fd *InputElement) Time() (time.Time, bool) {
+	layout := fd.timeLayout()
+	if layout == "" || fd.value == "" {
+		return time.Time{}, false
+	}
+	loc := time.UTC
+	if fd.itype == itypeDatetime {
+		loc = time.Local
+	}
+	result, err := parseHTMLTime(layout, fd.value, loc)
+	return result, err == nil
+}
+
+// SetStep sets the "step" attribute, e.g. the granularity of a number field.
+func (fd *InputElement) SetStep(step string) *InputElement { return fd.SetAttr("step", step) }
+
+// SetPlaceholder sets the "placeholder" attribute, a hint that is shown while
+// the field is empty.
+func (fd *InputElement) SetPlaceholder(text string) *InputElement {
+	return fd.SetAttr("placeholder", text)
+}
+
+// SetAutocomplete sets the "autocomplete" attribute, e.g. "email" or
+// "current-password".
+func (fd *InputElement) SetAutocomplete(hint string) *InputElement {
+	return fd.SetAttr("autocomplete", hint)
+}
+
+// SetAutofocus sets the "autofocus" attribute.
+func (fd *InputElement) SetAutofocus() *InputElement { return fd.SetAttr("autofocus", "") }
+
+// SetAttr sets an arbitrary attribute of the rendered <input> element. It
+// replaces an attribute with the same key, e.g. one of a validator. Since the
+// attributes "id", "name", "type", and "value" are managed by the field
+// itself, it panics if one of them is set.
+func (fd *InputElement) SetAttr(key, val string) *InputElement {
+	fd.attrs.set(key, val)
+	return fd
+}
+
+// timeLayout returns the HTML time layout of a date, date/time, month, or
+// time input element. For other input elements, the empty string is returned.
+func (fd *InputElement) timeLayout() string {
 	switch fd.itype {
 	case itypeDate:
-		if value != "" {
-			_, err = time.Parse(htmlDateLayout, value)
-		}
+		return htmlDateLayout
 	case itypeDatetime:
-		if value != "" {
-			_, err = time.Parse(htmlDatetimeLayout, value)
-		}
+		return htmlDatetimeLayout
+	case itypeMonth:
+		return htmlMonthLayout
+	case itypeTime:
+		return htmlTimeLayout
 	}
-	return err
+	return ""
 }
 
 // Validators returns all currently active Validators.
@@ -81,32 +227,54 @@ func (fd *InputElement) Validators() Validators {
 // Disable the input element.
 func (fd *InputElement) Disable() { fd.disabled = true }
 
-// Render the form input element as SxHTML.
+func (fd *InputElement) isDisabled() bool { return fd.disabled }
+
+// Render the form input element without warnings.
 func (fd *InputElement) Render(fieldID string, messages []string) *htmls.Node {
+	return fd.RenderWarnings(fieldID, messages, nil)
+}
+
+// RenderWarnings renders the form input element as SxHTML, with its error
+// and warning messages.
+func (fd *InputElement) RenderWarnings(fieldID string, messages, warnings []string) *htmls.Node {
 	valAttrs := makeValidatorAttributes(fd.Validators())
 	attrs := makeAttributes(5, valAttrs, fd.disabled)
 	attrs = append(attrs,
 		htmls.Attribute{Key: "id", Value: fieldID},
 		htmls.Attribute{Key: "name", Value: fd.name},
 		htmls.Attribute{Key: "type", Value: inputTypeString[fd.itype]},
-		htmls.Attribute{Key: "value", Value: fd.value},
 	)
+	if fd.itype != itypePassword {
+		// A password is never sent back to the client.
+		attrs = append(attrs, htmls.Attribute{Key: "value", Value: fd.value})
+	}
 	attrs = addEnablingAttributes(attrs, fd.disabled, valAttrs)
+	attrs = fd.attrs.merge(attrs)
+	attrs = fd.addDescribedBy(attrs, fieldID, len(messages)+len(warnings))
+	attrs = addInvalid(attrs, messages)
 
-	divNode := htmls.Elem("div", nil, renderLabel(fd, fieldID, fd.label))
-	divNode.Children = append(divNode.Children, renderMessages(messages)...)
-	divNode.Children = append(divNode.Children, htmls.Elem("input", attrs))
-	return divNode
+	fr := fd.renderer()
+	return fr.WrapField(fd,
+		renderLabel(fd, fieldID, fd.label),
+		renderAllMessages(fr, fieldID, messages, warnings),
+		htmls.Elem("input", attrs),
+		fd.renderHelp(fieldID))
 }
 
 var inputTypeString = map[inputType]string{
 	itypeCheckbox: "checkbox",
+	itypeColor:    "color",
 	itypeDate:     "date",
 	itypeDatetime: "datetime-local",
 	itypeEmail:    "email",
+	itypeMonth:    "month",
 	itypeNumber:   "number",
 	itypePassword: "password",
+	itypeRange:    "range",
+	itypeSearch:   "search",
+	itypeTel:      "tel",
 	itypeText:     "text",
+	itypeTime:     "time",
 }
 
 // TextField builds a new text field.
@@ -149,6 +317,24 @@ func PasswordField(name, label string, validators ...Validator) *InputElement {
 	}
 }
 
+// ConfirmSuffix is appended to the name of a password field to build the
+// name of its confirmation field, see [PasswordConfirmFields].
+const ConfirmSuffix = "-confirm"
+
+// PasswordConfirmFields builds a pair of password fields, e.g. for a sign-up
+// form: the password field with the given name, label, and validators, and
+// a field to repeat the password, named with [ConfirmSuffix]. The
+// confirmation field must contain the same value as the password field.
+// Both fields hint the client to suggest a new password.
+//
+// The values of password fields are never rendered. Use
+// [Form.ClearSensitive] to remove them from the form after a submission.
+func PasswordConfirmFields(name, label, confirmLabel string, validators ...Validator) (pw, confirm *InputElement) {
+	pw = PasswordField(name, label, validators...).SetAutocomplete("new-password")
+	confirm = PasswordField(name+ConfirmSuffix, confirmLabel, FieldStringEqual(name, "")).SetAutocomplete("new-password")
+	return pw, confirm
+}
+
 // EmailField builds a new e-mail field.
 func EmailField(name, label string, validators ...Validator) *InputElement {
 	return &InputElement{
@@ -168,3 +354,65 @@ func NumberField(name, label string, validators ...Validator) *InputElement {
 		validators: validators,
 	}
 }
+
+// RangeField builds a new field to select a number within a range, e.g. with
+// a slider. The range is specified with [MinValue], [MaxValue], and
+// [InputElement.SetStep].
+func RangeField(name, label string, validators ...Validator) *InputElement {
+	return &InputElement{
+		itype:      itypeRange,
+		name:       name,
+		label:      label,
+		validators: validators,
+	}
+}
+
+// ColorField builds a new field to select a color.
+func ColorField(name, label string, validators ...Validator) *InputElement {
+	return &InputElement{
+		itype:      itypeColor,
+		name:       name,
+		label:      label,
+		validators: validators,
+	}
+}
+
+// TelField builds a new field to enter a telephone number.
+func TelField(name, label string, validators ...Validator) *InputElement {
+	return &InputElement{
+		itype:      itypeTel,
+		name:       name,
+		label:      label,
+		validators: validators,
+	}
+}
+
+// TimeField builds a new field to enter a time of day.
+func TimeField(name, label string, validators ...Validator) *InputElement {
+	return &InputElement{
+		itype:      itypeTime,
+		name:       name,
+		label:      label,
+		validators: validators,
+	}
+}
+
+// MonthField builds a new field to enter a month of a year.
+func MonthField(name, label string, validators ...Validator) *InputElement {
+	return &InputElement{
+		itype:      itypeMonth,
+		name:       name,
+		label:      label,
+		validators: validators,
+	}
+}
+
+// SearchField builds a new field to enter search terms.
+func SearchField(name, label string, validators ...Validator) *InputElement {
+	return &InputElement{
+		itype:      itypeSearch,
+		name:       name,
+		label:      label,
+		validators: validators,
+	}
+}
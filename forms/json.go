@@ -0,0 +1,122 @@
+// -----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+// -----------------------------------------------------------------------------
+
+package forms
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// MarshalJSON returns the data as a JSON object of field names to string
+// values.
+func (d Data) MarshalJSON() ([]byte, error) { return json.Marshal(map[string]string(d)) }
+
+// UnmarshalJSON parses a JSON object of field names to values. A value is
+// either a string or an array of strings, e.g. the values of a [MultiField].
+// The elements of an array are joined by a newline character, so that the
+// resulting value is accepted by the SetValue method of a multi field.
+func (d *Data) UnmarshalJSON(b []byte) error {
+	var raw map[string]jsonValue
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if raw == nil {
+		*d = nil
+		return nil
+	}
+	data := make(Data, len(raw))
+	for name, value := range raw {
+		data[name] = string(value)
+	}
+	*d = data
+	return nil
+}
+
+// jsonValue is a string value that may be encoded as a JSON string or as an
+// array of strings.
+type jsonValue string
+
+func (jv *jsonValue) UnmarshalJSON(b []byte) error {
+	var values []string
+	if err := json.Unmarshal(b, &values); err == nil {
+		*jv = jsonValue(strings.Join(values, multiValueSeparator))
+		return nil
+	}
+	var value string
+	if err := json.Unmarshal(b, &value); err != nil {
+		return err
+	}
+	*jv = jsonValue(value)
+	return nil
+}
+
+// MarshalData returns the values of all fields as a JSON object, e.g. to
+// persist a partially filled form. The values of a [MultiField] are encoded
+// as an array of strings, all other values as a string. Fields without a
+// value are omitted. Like [StashInFlash], values of password and challenge
+// fields are not stored. Only fields that carry data are stored: not file
+// fields, whose files must be uploaded again, not submit buttons, and not
+// fieldsets.
+func (f *Form) MarshalData() ([]byte, error) {
+	data := make(map[string]any, len(f.fieldnames))
+	for name, field := range f.fieldnames {
+		if isSecretField(field) || !isDataField(field) {
+			continue
+		}
+		if mf, isMulti := field.(MultiField); isMulti {
+			if values := mf.Values(); len(values) > 0 {
+				data[name] = values
+			}
+		} else if value := field.Value(); value != "" {
+			data[name] = value
+		}
+	}
+	return json.Marshal(data)
+}
+
+// UnmarshalData populates the form with values produced by
+// [Form.MarshalData]. An error is returned only, if the JSON is malformed.
+// Values are set like with [Form.SetData]: values of unknown fields are
+// ignored, and invalid values, e.g. an unparsable date or an unknown choice
+// of a select field, result in messages of the form, see [Form.Messages].
+func (f *Form) UnmarshalData(b []byte) error {
+	var data Data
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	f.SetData(data)
+	return nil
+}
+
+// isSecretField returns true, if the value of the field must not be stored
+// outside of the current request.
+func isSecretField(field Field) bool {
+	switch fd := field.(type) {
+	case *InputElement:
+		return fd.itype == itypePassword
+	case *ChallengeElement:
+		return true
+	}
+	return false
+}
+
+// isDataField returns true, if the value of the field is data that can be
+// set again with SetValue.
+func isDataField(field Field) bool {
+	switch field.(type) {
+	case *FileElement, *SubmitElement, *Fieldset:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,159 @@
+// -----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+// -----------------------------------------------------------------------------
+
+package forms_test
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"testing"
+
+	"t73f.de/r/webs/forms"
+)
+
+func defineJSONForm() *forms.Form {
+	return forms.Define(
+		forms.TextField("name", "Name"),
+		forms.TextAreaField("notes", "Notes"),
+		forms.DateField("due", "Due"),
+		forms.SelectField("color", "Color", []string{"r", "Red", "g", "Green"}),
+		forms.MultiSelectField("tags", "Tags", []string{"a", "A", "b", "B", "c", "C"}),
+		forms.PasswordField("secret", "Secret"),
+	)
+}
+
+func TestMarshalData(t *testing.T) {
+	t.Parallel()
+	f := defineJSONForm()
+	f.SetData(forms.Data{
+		"name":   "Alice",
+		"notes":  "line 1\nline 2",
+		"due":    "2025-03-01",
+		"color":  "g",
+		"tags":   "a\nc",
+		"secret": "s3cr3t",
+	})
+	b, err := f.MarshalData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `{"color":"g","due":"2025-03-01","name":"Alice","notes":"line 1\nline 2","tags":["a","c"]}`
+	if got := string(b); got != exp {
+		t.Errorf("\nexp: %s\ngot: %s", exp, got)
+	}
+
+	g := defineJSONForm()
+	if err = g.UnmarshalData(b); err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Messages()) != 0 {
+		t.Errorf("no messages expected, got %v", g.Messages())
+	}
+	expData := f.Data()
+	delete(expData, "secret")
+	if got := g.Data(); !maps.Equal(got, expData) {
+		t.Errorf("data %v expected, got %v", expData, got)
+	}
+	if got := g.MultiData().Get("tags"); !slices.Equal(got, []string{"a", "c"}) {
+		t.Errorf("two tags expected, got %v", got)
+	}
+}
+
+func TestUnmarshalDataInvalid(t *testing.T) {
+	t.Parallel()
+	f := defineJSONForm()
+	if err := f.UnmarshalData([]byte(`{"name": 7}`)); err == nil {
+		t.Error("error expected for a malformed value")
+	}
+	if err := f.UnmarshalData([]byte(`{"due": "tomorrow", "color": "x", "tags": ["a", "z"], "unknown": "u"}`)); err != nil {
+		t.Fatal(err)
+	}
+	msgs := f.Messages()
+	for _, name := range []string{"due", "color", "tags"} {
+		if len(msgs[name]) == 0 {
+			t.Errorf("message for field %q expected, got %v", name, msgs)
+		}
+	}
+	if _, found := msgs["unknown"]; found {
+		t.Errorf("unknown field must be ignored, got %v", msgs)
+	}
+}
+
+func TestDataJSON(t *testing.T) {
+	t.Parallel()
+	type record struct {
+		ID   int        `json:"id"`
+		Data forms.Data `json:"data"`
+	}
+	rec := record{ID: 1, Data: forms.Data{"b": "2", "a": "x\ny"}}
+	b, err := json.Marshal(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp, got := `{"id":1,"data":{"a":"x\ny","b":"2"}}`, string(b); got != exp {
+		t.Errorf("%s expected, got %s", exp, got)
+	}
+	var got record
+	if err = json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != rec.ID || !maps.Equal(got.Data, rec.Data) {
+		t.Errorf("%v expected, got %v", rec, got)
+	}
+
+	var d forms.Data
+	if err = json.Unmarshal([]byte(`{"tags":["a","b"],"name":"n"}`), &d); err != nil {
+		t.Fatal(err)
+	}
+	if exp := (forms.Data{"tags": "a\nb", "name": "n"}); !maps.Equal(d, exp) {
+		t.Errorf("%v expected, got %v", exp, d)
+	}
+}
+
+func TestMarshalDataFile(t *testing.T) {
+	t.Parallel()
+	define := func() *forms.Form {
+		return forms.Define(
+			forms.TextField("title", "Title"),
+			forms.FileField("doc", "Document"),
+			forms.SubmitField("save", "Save"),
+		)
+	}
+	f := define()
+	r := newUploadRequest(t,
+		map[string]string{"title": "Report", "save": "Save"},
+		uploadFile{"doc", "report.txt", "text/plain", "Hello"},
+	)
+	if sr, _ := f.OnSubmit(r); sr != forms.SubmitValidData {
+		t.Fatalf("valid data expected, got %v: %v", sr, f.Messages())
+	}
+	b, err := f.MarshalData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := string(b), `{"title":"Report"}`; got != exp {
+		t.Errorf("\nexp: %s\ngot: %s", exp, got)
+	}
+
+	g := define()
+	if err = g.UnmarshalData(b); err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Messages()) != 0 {
+		t.Errorf("no messages expected, got %v", g.Messages())
+	}
+	if got, exp := g.Data(), (forms.Data{"title": "Report"}); !maps.Equal(got, exp) {
+		t.Errorf("data %v expected, got %v", exp, got)
+	}
+}
@@ -0,0 +1,122 @@
+// -----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+// -----------------------------------------------------------------------------
+
+package forms
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Locale specifies how values are formatted for humans, e.g. in validation
+// messages, in [Form.RenderDiff], and by [InputElement.DisplayValue].
+//
+// The value of a HTML input element is not affected: it is always formatted
+// as required by HTML.
+type Locale struct {
+	DateLayout       string // Time layout of a date, e.g. "02.01.2006"
+	DatetimeLayout   string // Time layout of a date/time, e.g. "02.01.2006 15:04"
+	DecimalSeparator string // Separator of the fractional part of a number, e.g. ","
+}
+
+// DefaultLocale formats dates and date/times according to ISO 8601, and
+// numbers with a decimal point. It is used, if no other locale was set.
+var DefaultLocale = Locale{
+	DateLayout:       time.DateOnly,
+	DatetimeLayout:   "2006-01-02 15:04",
+	DecimalSeparator: ".",
+}
+
+// withDefaults returns the locale, where all empty specifications are
+// replaced by those of [DefaultLocale].
+func (l Locale) withDefaults() Locale {
+	if l.DateLayout == "" {
+		l.DateLayout = DefaultLocale.DateLayout
+	}
+	if l.DatetimeLayout == "" {
+		l.DatetimeLayout = DefaultLocale.DatetimeLayout
+	}
+	if l.DecimalSeparator == "" {
+		l.DecimalSeparator = DefaultLocale.DecimalSeparator
+	}
+	return l
+}
+
+// FormatDate formats the date part of the given time.
+func (l Locale) FormatDate(t time.Time) string {
+	return t.Format(l.withDefaults().DateLayout)
+}
+
+// FormatDatetime formats the given time as a local date/time.
+func (l Locale) FormatDatetime(t time.Time) string {
+	return t.Format(l.withDefaults().DatetimeLayout)
+}
+
+// FormatNumber formats the given number with the shortest representation.
+func (l Locale) FormatNumber(n float64) string {
+	s := strconv.FormatFloat(n, 'f', -1, 64)
+	if sep := l.withDefaults().DecimalSeparator; sep != "." {
+		s = strings.Replace(s, ".", sep, 1)
+	}
+	return s
+}
+
+// formatValue formats the value of an input element of the given type. Other
+// values, and values that cannot be parsed, are returned unchanged.
+func (l Locale) formatValue(itype inputType, value string) string {
+	switch itype {
+	case itypeDate:
+		if t, err := time.Parse(htmlDateLayout, value); err == nil {
+			return l.FormatDate(t)
+		}
+	case itypeDatetime:
+		if t, err := parseHTMLTime(htmlDatetimeLayout, value, time.UTC); err == nil {
+			return l.FormatDatetime(t)
+		}
+	case itypeNumber, itypeRange:
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return l.FormatNumber(n)
+		}
+	}
+	return value
+}
+
+// formatTime formats the time value of a date, date/time, month, or time
+// input element. Months and times are formatted like HTML values.
+func (l Locale) formatTime(itype inputType, t time.Time) string {
+	switch itype {
+	case itypeDate:
+		return l.FormatDate(t)
+	case itypeMonth:
+		return t.Format(htmlMonthLayout)
+	case itypeTime:
+		return t.Format(htmlTimeLayout)
+	}
+	return l.FormatDatetime(t)
+}
+
+// SetLocale sets the locale to format values for humans. Empty
+// specifications of the locale are taken from [DefaultLocale].
+func (f *Form) SetLocale(l Locale) *Form {
+	f.locale = l.withDefaults()
+	return f
+}
+
+// Locale returns the locale of the form, see [Form.SetLocale].
+func (f *Form) Locale() Locale {
+	if f == nil {
+		return DefaultLocale
+	}
+	return f.locale.withDefaults()
+}
@@ -0,0 +1,129 @@
+// -----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+// -----------------------------------------------------------------------------
+
+package forms_test
+
+import (
+	"strings"
+	"testing"
+
+	"t73f.de/r/webs/forms"
+)
+
+var germanLocale = forms.Locale{
+	DateLayout:       "02.01.2006",
+	DatetimeLayout:   "02.01.2006 15:04",
+	DecimalSeparator: ",",
+}
+
+func TestDisplayValue(t *testing.T) {
+	testcases := []struct {
+		name  string
+		field *forms.InputElement
+		value string
+		iso   string
+		de    string
+	}{
+		{"date", forms.DateField("date", "Date"), "2024-01-05", "2024-01-05", "05.01.2024"},
+		{"datetime", forms.DatetimeField("datetime", "Datetime"), "2024-01-05T09:30", "2024-01-05 09:30", "05.01.2024 09:30"},
+		{"number", forms.NumberField("number", "Number"), "1234.50", "1234.5", "1234,5"},
+		{"integer", forms.NumberField("integer", "Integer"), "17", "17", "17"},
+		{"text", forms.TextField("text", "Text"), "1.5", "1.5", "1.5"},
+		{"password", forms.PasswordField("password", "Password"), "2024-01-05", "", ""},
+		{"invalid", forms.NumberField("invalid", "Invalid"), "abc", "abc", "abc"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_ = tc.field.SetValue(tc.value)
+			if got := tc.field.DisplayValue(); got != tc.iso {
+				t.Errorf("without form: %q expected, got %q", tc.iso, got)
+			}
+			f := forms.Define(forms.FieldsetField("fs", "Fieldset", tc.field))
+			if got := tc.field.DisplayValue(); got != tc.iso {
+				t.Errorf("default locale: %q expected, got %q", tc.iso, got)
+			}
+			f.SetLocale(germanLocale)
+			if got := tc.field.DisplayValue(); got != tc.de {
+				t.Errorf("german locale: %q expected, got %q", tc.de, got)
+			}
+			if got := tc.field.Value(); got != tc.value {
+				t.Errorf("value must not change, but got %q", got)
+			}
+		})
+	}
+}
+
+func TestLocaleDefaults(t *testing.T) {
+	f := forms.Define(forms.DateField("date", "Date"))
+	if got := f.Locale(); got != forms.DefaultLocale {
+		t.Errorf("default locale expected, got %v", got)
+	}
+	f.SetLocale(forms.Locale{DecimalSeparator: ","})
+	if got := f.Locale().DateLayout; got != forms.DefaultLocale.DateLayout {
+		t.Errorf("default date layout expected, got %q", got)
+	}
+}
+
+func TestLocaleRenderedValue(t *testing.T) {
+	field := forms.DateField("date", "Date")
+	f := forms.Define(field).SetLocale(germanLocale)
+	if err := field.SetValue("2024-01-05"); err != nil {
+		t.Fatal(err)
+	}
+	if got := renderForm(f); !strings.Contains(got, `value="2024-01-05"`) {
+		t.Errorf("input value must stay ISO, got %s", got)
+	}
+}
+
+func TestLocaleMessages(t *testing.T) {
+	field := forms.NumberField("n", "Number", &forms.MinValue{Value: "1.5"}, &forms.MaxValue{Value: "10.25"})
+	f := forms.Define(field)
+	testcases := []struct {
+		value  string
+		locale forms.Locale
+		exp    string
+	}{
+		{"0.5", forms.DefaultLocale, "minimum value of n is 1.5, but got 0.5"},
+		{"0.5", germanLocale, "minimum value of n is 1,5, but got 0,5"},
+		{"12", forms.DefaultLocale, "maximum value of n is 10.25, but got 12"},
+		{"12", germanLocale, "maximum value of n is 10,25, but got 12"},
+	}
+	for _, tc := range testcases {
+		f.SetLocale(tc.locale)
+		if f.SetData(forms.Data{"n": tc.value}) && f.IsValid() {
+			t.Errorf("%q: must not be valid", tc.value)
+			continue
+		}
+		if got := f.Messages()["n"]; len(got) != 1 || got[0] != tc.exp {
+			t.Errorf("%q: message %q expected, got %q", tc.value, tc.exp, got)
+		}
+	}
+}
+
+func TestLocaleDiff(t *testing.T) {
+	f := forms.Define(
+		forms.DateField("due", "Due"),
+		forms.NumberField("prio", "Priority"),
+	).SetLocale(germanLocale)
+	got := renderDiff(t, f, map[string][2]string{
+		"due":  {"2024-01-05", "2024-02-29"},
+		"prio": {"1.50", "2"},
+	})
+	exp := `<table class="form-diff"><tbody>` +
+		diffRow("Due", "05.01.2024", "29.02.2024") +
+		diffRow("Priority", "1,5", "2") +
+		`</tbody></table>`
+	if got != exp {
+		t.Errorf("expected\n%s\ngot\n%s", exp, got)
+	}
+}
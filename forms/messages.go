@@ -0,0 +1,167 @@
+// -----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+// -----------------------------------------------------------------------------
+
+package forms
+
+import "fmt"
+
+// ----- Translatable messages of built-in validators
+
+// MessagePrinter formats the messages of all built-in validators, and the
+// marker of required fields. Each message is identified by a stable key,
+// e.g. [MsgRequired], and gets its arguments as documented at the key.
+type MessagePrinter interface {
+	Sprintf(key string, args ...any) string
+}
+
+// MessagePrinterFunc is a function that acts as a [MessagePrinter].
+type MessagePrinterFunc func(key string, args ...any) string
+
+// Sprintf formats the message by executing the function itself.
+func (mpf MessagePrinterFunc) Sprintf(key string, args ...any) string { return mpf(key, args...) }
+
+// Keys of messages, see [MessagePrinter]. The arguments of each message are
+// given in parentheses.
+const (
+	MsgRequired            = "required"             // ()
+	MsgRequiredMarker      = "required-marker"      // (), appended to the label
+	MsgMinLength           = "minlength"            // (field name, minimum, length)
+	MsgMaxLength           = "maxlength"            // (field name, maximum, length)
+	MsgFormat              = "format"               // (field name, value)
+	MsgNotANumber          = "not-a-number"         // (field name, value)
+	MsgNotAnInteger        = "not-an-integer"       // (field name, value)
+	MsgNotAnUnsigned       = "not-an-unsigned"      // (field name, value)
+	MsgInvalidValue        = "invalid-value"        // (field name, value)
+	MsgMinValue            = "min-value"            // (field name, formatted minimum, formatted value)
+	MsgMaxValue            = "max-value"            // (field name, formatted maximum, formatted value)
+	MsgNoneOf              = "none-of"              // (field name, value)
+	MsgAnyOf               = "any-of"               // (field name, value, sorted valid values)
+	MsgCompare             = "compare"              // (value, operator symbol, other value)
+	MsgEmailDomainTypo     = "email-domain-typo"    // (domain, probably meant domain)
+	MsgMaxFileSize         = "max-file-size"        // (field name, maximum, size)
+	MsgFileType            = "file-type"            // (file name, field name, accepted types)
+	MsgOTPDigits           = "otp-digits"           // (number of digits)
+	MsgChallengeUnanswered = "challenge-unanswered" // ()
+	MsgUnexpectedField     = "unexpected-field"     // (name), see Form.StrictMode
+	MsgQRCodeContent       = "qrcode-content"       // (finding key, English message), see qrforms.ContentValidator
+	MsgPasswordMismatch    = "password-mismatch"    // (), see PasswordConfirmFields
+)
+
+// defaultMessages are the English format strings of all messages.
+var defaultMessages = map[string]string{
+	MsgRequired:            "Required",
+	MsgRequiredMarker:      "*",
+	MsgMinLength:           "minimum length of %s is %d, but got %d",
+	MsgMaxLength:           "maximum length of %s is %d, but got %d",
+	MsgFormat:              "%s does not match the required format: %v",
+	MsgNotANumber:          "%s does not contain a number: %v",
+	MsgNotAnInteger:        "%s does not contain an integer value: %v",
+	MsgNotAnUnsigned:       "%s does not contain an unsigned integer value: %v",
+	MsgInvalidValue:        "%s does not contain a valid value: %v",
+	MsgMinValue:            "minimum value of %s is %v, but got %v",
+	MsgMaxValue:            "maximum value of %s is %v, but got %v",
+	MsgNoneOf:              "%s contains an invalid value: %v",
+	MsgAnyOf:               "%s does not contain any valid input: %v (expected one of %v)",
+	MsgCompare:             "%v %s %v",
+	MsgEmailDomainTypo:     "this e-mail domain looks like a typo: %s (did you mean %s?)",
+	MsgMaxFileSize:         "maximum size of %s is %d bytes, but got %d",
+	MsgFileType:            "file %q of %s has an unacceptable type, expected one of %s",
+	MsgOTPDigits:           "code must consist of %d digits",
+	MsgChallengeUnanswered: "please solve the challenge",
+	MsgUnexpectedField:     "unexpected field: %s",
+	MsgQRCodeContent:       "%[2]s",
+	MsgPasswordMismatch:    "passwords do not match",
+}
+
+// MessageFormats is a [MessagePrinter] that maps message keys to format
+// strings of package fmt. Keys without a format string are printed with the
+// English default message.
+type MessageFormats map[string]string
+
+// Sprintf formats the message with the given key.
+func (mf MessageFormats) Sprintf(key string, args ...any) string {
+	format, found := mf[key]
+	if !found {
+		if format, found = defaultMessages[key]; !found {
+			return fmt.Sprint(append([]any{key, ": "}, args...)...)
+		}
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// DefaultMessages prints the English messages.
+var DefaultMessages MessagePrinter = MessageFormats(nil)
+
+// SetMessagePrinter sets the printer of all messages of built-in validators
+// of the form's fields, and of the required marker of their labels. A nil
+// printer restores [DefaultMessages]. A printer of a field, see
+// [InputElement.SetMessagePrinter], takes precedence.
+func (f *Form) SetMessagePrinter(mp MessagePrinter) *Form {
+	f.printer = mp
+	return f
+}
+
+// Sprintf formats a message for the given field like the built-in validators
+// do. Validators of other packages use it to honor the message printers.
+func (f *Form) Sprintf(field Field, key string, args ...any) string {
+	return f.sprintf(field, key, args...)
+}
+
+// sprintf formats a message for the given field with the printer of the
+// field, of the form, or with the default printer, in this order.
+func (f *Form) sprintf(field Field, key string, args ...any) string {
+	if fp, ok := field.(interface{ fieldPrinter() MessagePrinter }); ok {
+		if mp := fp.fieldPrinter(); mp != nil {
+			return mp.Sprintf(key, args...)
+		}
+	}
+	if f != nil && f.printer != nil {
+		return f.printer.Sprintf(key, args...)
+	}
+	return DefaultMessages.Sprintf(key, args...)
+}
+
+// fieldMessages stores the message printer of a field. It is embedded into
+// fieldHelp, and therefore into all input-like fields.
+type fieldMessages struct {
+	printer     MessagePrinter
+	formPrinter *MessagePrinter // printer of the form, see Form.addName
+}
+
+// SetMessagePrinter sets the printer of all messages of built-in validators
+// of this field, overriding the printer of the form. A nil printer uses the
+// printer of the form again.
+func (fm *fieldMessages) SetMessagePrinter(mp MessagePrinter) { fm.printer = mp }
+
+func (fm *fieldMessages) setFormPrinter(mp *MessagePrinter) { fm.formPrinter = mp }
+
+// fieldPrinter returns the printer of the field, which is the printer of its
+// form, if it has none.
+func (fm *fieldMessages) fieldPrinter() MessagePrinter {
+	if fm.printer != nil {
+		return fm.printer
+	}
+	if fm.formPrinter != nil {
+		return *fm.formPrinter
+	}
+	return nil
+}
+
+// requiredMarker returns the text that is appended to the label of a
+// required field.
+func (fm *fieldMessages) requiredMarker() string {
+	if mp := fm.fieldPrinter(); mp != nil {
+		return mp.Sprintf(MsgRequiredMarker)
+	}
+	return DefaultMessages.Sprintf(MsgRequiredMarker)
+}
@@ -0,0 +1,94 @@
+// -----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+// -----------------------------------------------------------------------------
+
+package forms_test
+
+import (
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"t73f.de/r/webs/forms"
+)
+
+var germanMessages = forms.MessageFormats{
+	forms.MsgRequired:       "Pflichtfeld",
+	forms.MsgRequiredMarker: " (Pflicht)",
+	forms.MsgMinLength:      "%s muss mindestens %d Zeichen lang sein, hat aber %d",
+	forms.MsgNotAnInteger:   "%s enthält keine ganze Zahl: %v",
+}
+
+func TestMessagePrinterForm(t *testing.T) {
+	f := forms.Define(
+		forms.TextField("name", "Name", forms.Required{}, &forms.MinMaxLength{MinLength: 3}),
+		forms.TextField("age", "Alter", forms.IntValidator()),
+		forms.TextField("code", "Code", forms.AnyOf("a", "b")),
+	).SetMessagePrinter(germanMessages)
+
+	if got := renderForm(f); !strings.Contains(got, `<label for="name">Name (Pflicht)</label>`) {
+		t.Errorf("translated required marker expected, got %s", got)
+	}
+
+	f.SetFormValues(url.Values{"age": {"zehn"}, "code": {"c"}}, nil)
+	if f.IsValid() {
+		t.Fatal("form must not be valid")
+	}
+	exp := forms.Messages{
+		"name": {"Pflichtfeld"},
+		"age":  {"age enthält keine ganze Zahl: zehn"},
+		// no translation: English default
+		"code": {"code does not contain any valid input: c (expected one of [a b])"},
+	}
+	if got := f.Messages(); !maps.EqualFunc(exp, got, slices.Equal) {
+		t.Errorf("%v expected, got %v", exp, got)
+	}
+
+	f.SetFormValues(url.Values{"name": {"Al"}, "age": {"10"}, "code": {"a"}}, nil)
+	f.IsValid()
+	if exp, got := []string{"name muss mindestens 3 Zeichen lang sein, hat aber 2"}, f.Messages()["name"]; !slices.Equal(exp, got) {
+		t.Errorf("%v expected, got %v", exp, got)
+	}
+
+	f.SetMessagePrinter(nil)
+	f.IsValid()
+	if exp, got := []string{"minimum length of name is 3, but got 2"}, f.Messages()["name"]; !slices.Equal(exp, got) {
+		t.Errorf("%v expected, got %v", exp, got)
+	}
+	if got := renderForm(f); !strings.Contains(got, `<label for="name">Name*</label>`) {
+		t.Errorf("default required marker expected, got %s", got)
+	}
+}
+
+func TestMessagePrinterField(t *testing.T) {
+	keys := forms.MessagePrinterFunc(func(key string, args ...any) string { return key })
+	name := forms.TextField("name", "Name", forms.Required{})
+	name.SetMessagePrinter(keys)
+	f := forms.Define(
+		name,
+		forms.TextField("other", "Other", forms.Required{}),
+	).SetMessagePrinter(germanMessages)
+	f.SetFormValues(nil, nil)
+	f.IsValid()
+	exp := forms.Messages{"name": {forms.MsgRequired}, "other": {"Pflichtfeld"}}
+	if got := f.Messages(); !maps.EqualFunc(exp, got, slices.Equal) {
+		t.Errorf("%v expected, got %v", exp, got)
+	}
+	got := renderForm(f)
+	for _, s := range []string{`Name` + forms.MsgRequiredMarker + `</label>`, `Other (Pflicht)</label>`} {
+		if !strings.Contains(got, s) {
+			t.Errorf("%q expected in %s", s, got)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+// -----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+// -----------------------------------------------------------------------------
+
+package forms
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// ----- Micro forms: edit a single field inline
+
+// Names of the submit fields of a micro form, see [Form.MicroForm].
+const (
+	MicroSaveName   = "save"
+	MicroCancelName = "cancel"
+)
+
+// MicroIDPrefix is the prefix of all field identifiers of a micro form. It
+// avoids identifiers that collide with those of the full form, if both are
+// rendered on the same page.
+const MicroIDPrefix = "micro-"
+
+// MicroForm returns a derived form that contains only the field with the
+// given name, e.g. to edit a single value inline as a HTML fragment. It is
+// posted to the given action and is handled there by
+// [Form.ApplyMicroSubmit] of the original form.
+//
+// The micro form shares the field with the original form, together with its
+// current value and the messages of an earlier validation. Besides the
+// field, it contains a save and a cancel button (see [MicroSaveName] and
+// [MicroCancelName]), and the [VersionElement] of the original form, if
+// there is one. All field identifiers start with [MicroIDPrefix].
+//
+// An error is returned, if there is no such field, if it is a submit field,
+// a fieldset, or a version field, or if its name is used by the buttons.
+func (f *Form) MicroForm(fieldName, action string) (*Form, error) {
+	field, err := f.microField(fieldName)
+	if err != nil {
+		return nil, err
+	}
+	micro := &Form{
+		action:      action,
+		method:      http.MethodPost,
+		maxFormSize: f.maxFormSize,
+		fieldnames:  make(map[string]Field, 4),
+		locale:      f.locale,
+		printer:     f.printer,
+		renderer:    f.renderer,
+		parent:      f,
+		idPrefix:    MicroIDPrefix + f.idPrefix,
+	}
+	// The fields are not added with Form.AppendE, because they still belong
+	// to the original form.
+	micro.fields = append(micro.fields, field)
+	if ve := f.versionElement(); ve != nil {
+		micro.fields = append(micro.fields, ve)
+	}
+	save, cancel := SubmitField(MicroSaveName, "Save"), SubmitField(MicroCancelName, "Cancel").SetCancel()
+	save.setFormRenderer(&micro.renderer)
+	cancel.setFormRenderer(&micro.renderer)
+	micro.fields = append(micro.fields, save, cancel)
+	for _, fd := range micro.fields {
+		micro.fieldnames[fd.Name()] = fd
+	}
+	for _, name := range []string{"", fieldName} {
+		for _, msg := range f.messages[name] {
+			micro.messages = micro.messages.Add(name, msg)
+		}
+		for _, warn := range f.warnings[name] {
+			micro.warnings = micro.warnings.Add(name, warn)
+		}
+		for _, d := range f.details[name] {
+			micro.details = addDetail(micro.details, name, d.Code, d.Message, d.Params)
+		}
+	}
+	return micro, nil
+}
+
+// microField returns the field with the given name, if it could be edited
+// by a micro form.
+func (f *Form) microField(fieldName string) (Field, error) {
+	if fieldName == MicroSaveName || fieldName == MicroCancelName {
+		return nil, fmt.Errorf("field name %q is used by a micro form button", fieldName)
+	}
+	field, found := f.fieldnames[fieldName]
+	if !found {
+		return nil, fmt.Errorf("no such field: %v", fieldName)
+	}
+	switch field.(type) {
+	case *SubmitElement, *Fieldset, *VersionElement:
+		return nil, fmt.Errorf("field %q cannot be edited by a micro form", fieldName)
+	}
+	return field, nil
+}
+
+// ApplyMicroSubmit consumes a POST request of a micro form for the field
+// with the given name, see [Form.MicroForm]. Only this field is validated:
+// with its own validators, and with the validators of other fields that
+// refer to it, e.g. [FieldStringEqual]. Messages of the latter are reported
+// for the given field, since only its value was changed. The values of all
+// other fields are not changed.
+//
+// If the submitted value is valid, the field keeps it. Otherwise, the
+// previous value is restored, and the messages are available via
+// [Form.Messages] and the next micro form. A cancelled edit results in
+// [SubmitNoValidate]. If a version check was set with [Form.CheckVersion],
+// a conflicting edit results in [SubmitConflict], and the previous value is
+// restored too.
+//
+// An error is returned, if the field cannot be edited by a micro form.
+func (f *Form) ApplyMicroSubmit(r *http.Request, fieldName string) (SubmitResult, error) {
+	field, err := f.microField(fieldName)
+	if err != nil {
+		return SubmitNoData, err
+	}
+	if r.Method != http.MethodPost {
+		return SubmitNoData, nil
+	}
+	f.messages, f.warnings, f.details = nil, nil, nil
+	if err = f.parseForm(r); err != nil {
+		f.messages = Messages{"": {err.Error()}}
+		return SubmitInvalidData, nil
+	}
+	if _, cancelled := r.PostForm[MicroCancelName]; cancelled {
+		return SubmitNoValidate, nil
+	}
+
+	restore := saveFieldValue(field)
+	values := r.PostForm[fieldName]
+	if mf, isMulti := field.(MultiField); isMulti {
+		err = mf.SetValues(values)
+	} else {
+		err = field.SetValue(strings.TrimSpace(fieldValue(field, values)))
+	}
+	if err != nil {
+		f.messages = f.messages.Add(fieldName, err.Error())
+		restore()
+		return SubmitInvalidData, nil
+	}
+
+	v := newValidation(r)
+	f.checkValidators(field, field.Validators(), fieldName, v)
+	for other := range allFields(f.fields) {
+		if other == field {
+			continue
+		}
+		var refs Validators
+		for _, v := range other.Validators() {
+			if references(v, fieldName) {
+				refs = append(refs, v)
+			}
+		}
+		f.checkValidators(other, refs, fieldName, v)
+	}
+	f.messages, f.warnings, f.details = v.messages, v.warnings, v.details
+
+	if ve := f.versionElement(); ve != nil {
+		if versions := r.PostForm[ve.name]; len(versions) > 0 {
+			ve.SetValue(versions[0])
+		}
+	}
+	conflict, err := f.checkVersion(r.Context())
+	if err != nil {
+		f.messages = f.messages.Add("", err.Error())
+		restore()
+		return SubmitInvalidData, nil
+	}
+	if conflict {
+		f.addCodedMessage("", CodeConflict, ConflictMessage, nil)
+		restore()
+		return SubmitConflict, nil
+	}
+	if len(v.messages) > 0 {
+		restore()
+		return SubmitInvalidData, nil
+	}
+	return SubmitValidData, nil
+}
+
+// saveFieldValue returns a function that restores the current value of the
+// field.
+func saveFieldValue(field Field) func() {
+	if mf, isMulti := field.(MultiField); isMulti {
+		values := slices.Clone(mf.Values())
+		return func() { _ = mf.SetValues(values) }
+	}
+	value := field.Value()
+	return func() { _ = field.SetValue(value) }
+}
@@ -0,0 +1,155 @@
+// -----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+// -----------------------------------------------------------------------------
+
+package forms_test
+
+import (
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"t73f.de/r/webs/forms"
+)
+
+func postMicro(f *forms.Form, fieldName string, vals url.Values) (forms.SubmitResult, error) {
+	r := httptest.NewRequest(http.MethodPost, "/edit/"+fieldName, strings.NewReader(vals.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return f.ApplyMicroSubmit(r, fieldName)
+}
+
+func TestMicroFormRender(t *testing.T) {
+	f := forms.Define(
+		forms.VersionField("version", "7"),
+		forms.TextField("title", "Title", forms.Required{"title"}),
+		forms.TextField("author", "Author"),
+		forms.SubmitField("submit", "Submit"),
+	)
+	f.SetData(forms.Data{"title": "Old", "author": "Ann"})
+	micro, err := f.MicroForm("title", "/edit/title")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := renderForm(micro)
+	exp := `<form action="/edit/title" method="POST">` +
+		`<div><label for="micro-title">Title*</label><input id="micro-title" name="title" type="text" value="Old" required=""></div>` +
+		`<input id="micro-version" name="version" type="hidden" value="7">` +
+		`<div><input id="micro-save" name="save" type="submit" value="Save" class="primary"><input id="micro-cancel" name="cancel" type="submit" value="Cancel" class="cancel" formnovalidate=""></div>` +
+		`</form>`
+	if got != exp {
+		t.Errorf("\nexpected: %s\nbut got:  %s", exp, got)
+	}
+
+	for _, name := range []string{"unknown", "submit", "version", forms.MicroSaveName} {
+		if _, err = f.MicroForm(name, "/"); err == nil {
+			t.Errorf("micro form for %q must fail", name)
+		}
+		if _, err = postMicro(f, name, nil); err == nil {
+			t.Errorf("micro submit for %q must fail", name)
+		}
+	}
+}
+
+func TestMicroSubmitIsolation(t *testing.T) {
+	f := forms.Define(
+		forms.TextField("title", "Title", forms.Required{"title"}),
+		forms.TextField("author", "Author", forms.Required{"author"}),
+	)
+	f.SetData(forms.Data{"title": "Old"}) // author is invalid, but not edited
+
+	sr, err := postMicro(f, "title", url.Values{"title": {"New"}, forms.MicroSaveName: {"Save"}})
+	if err != nil || sr != forms.SubmitValidData {
+		t.Fatalf("valid data expected, got %v/%v", sr, err)
+	}
+	if exp, got := (forms.Data{"title": "New"}), f.Data(); !maps.Equal(exp, got) {
+		t.Errorf("%v expected, got %v", exp, got)
+	}
+
+	sr, _ = postMicro(f, "title", url.Values{"title": {""}, forms.MicroSaveName: {"Save"}})
+	if sr != forms.SubmitInvalidData {
+		t.Fatalf("invalid data expected, got %v", sr)
+	}
+	if exp, got := (forms.Messages{"title": {"title"}}), f.Messages(); !maps.EqualFunc(exp, got, slices.Equal) {
+		t.Errorf("%v expected, got %v", exp, got)
+	}
+	if got := f.Data()["title"]; got != "New" {
+		t.Errorf("previous value must be restored, got %q", got)
+	}
+	micro, _ := f.MicroForm("title", "/edit/title")
+	if got := renderForm(micro); !strings.Contains(got, `<span class="message error" id="micro-title-msg-0">title</span>`) {
+		t.Errorf("message expected in micro form, got %s", got)
+	}
+
+	sr, _ = postMicro(f, "title", url.Values{"title": {""}, forms.MicroCancelName: {"Cancel"}})
+	if sr != forms.SubmitNoValidate {
+		t.Errorf("cancelled edit expected, got %v", sr)
+	}
+	sr, _ = f.ApplyMicroSubmit(httptest.NewRequest(http.MethodGet, "/", nil), "title")
+	if sr != forms.SubmitNoData {
+		t.Errorf("no data expected, got %v", sr)
+	}
+}
+
+func TestMicroSubmitTrimmed(t *testing.T) {
+	f := forms.Define(
+		forms.TextField("title", "Title", forms.Required{"title"}),
+	)
+	sr, err := postMicro(f, "title", url.Values{"title": {"  New \t"}, forms.MicroSaveName: {"Save"}})
+	if err != nil || sr != forms.SubmitValidData {
+		t.Fatalf("valid data expected, got %v/%v", sr, err)
+	}
+	if got := f.Data()["title"]; got != "New" {
+		t.Errorf("trimmed value expected, got %q", got)
+	}
+
+	sr, _ = postMicro(f, "title", url.Values{"title": {"   "}, forms.MicroSaveName: {"Save"}})
+	if sr != forms.SubmitInvalidData {
+		t.Errorf("blank value must be invalid, got %v", sr)
+	}
+}
+
+func TestMicroSubmitCrossField(t *testing.T) {
+	f := forms.Define(
+		forms.TextField("start", "Start"),
+		forms.TextField("end", "End", forms.FieldStringGreater("start", "end must be after start")),
+		forms.TextField("note", "Note", forms.Required{"note"}),
+	)
+	f.SetData(forms.Data{"start": "b", "end": "m"})
+
+	sr, _ := postMicro(f, "start", url.Values{"start": {"x"}})
+	if sr != forms.SubmitInvalidData {
+		t.Fatalf("invalid data expected, got %v", sr)
+	}
+	if exp, got := (forms.Messages{"start": {"end must be after start"}}), f.Messages(); !maps.EqualFunc(exp, got, slices.Equal) {
+		t.Errorf("%v expected, got %v", exp, got)
+	}
+	if got := f.Data()["start"]; got != "b" {
+		t.Errorf("previous value must be restored, got %q", got)
+	}
+
+	if sr, _ = postMicro(f, "start", url.Values{"start": {"c"}}); sr != forms.SubmitValidData {
+		t.Errorf("valid data expected, got %v: %v", sr, f.Messages())
+	}
+
+	// The validator of "end" refers to "start", which is found via the
+	// original form.
+	if sr, _ = postMicro(f, "end", url.Values{"end": {"a"}}); sr != forms.SubmitInvalidData {
+		t.Errorf("invalid data expected, got %v", sr)
+	}
+	if sr, _ = postMicro(f, "end", url.Values{"end": {"d"}}); sr != forms.SubmitValidData {
+		t.Errorf("valid data expected, got %v: %v", sr, f.Messages())
+	}
+}
@@ -0,0 +1,243 @@
+// -----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+// -----------------------------------------------------------------------------
+
+package forms
+
+// ----- Fields with multiple values
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"t73f.de/r/webs/htmls"
+)
+
+// MultiField is a field that may have multiple values, e.g. a <select
+// multiple> element or a group of checkboxes with the same name.
+//
+// Its single value, as returned by Value and accepted by SetValue, contains
+// all values, separated by a newline character. Therefore, a value must not
+// contain a newline character.
+type MultiField interface {
+	Field
+
+	// Values returns all values of the field.
+	Values() []string
+
+	// SetValues sets all values of the field.
+	SetValues([]string) error
+}
+
+// multiValueSeparator separates the values of a MultiField in its single value.
+const multiValueSeparator = "\n"
+
+// multiChoice stores the values of a field with multiple choices.
+type multiChoice struct {
+	name       string
+	label      string
+	choices    []string
+	values     []string
+	validators Validators
+	disabled   bool
+	fieldHelp
+}
+
+func (mc *multiChoice) init(name, label string, choices []string, validators Validators) {
+	mc.name = name
+	mc.label = label
+	mc.choices = checkChoices(choices)
+	mc.validators = validators
+}
+
+// Name returns the element name.
+func (mc *multiChoice) Name() string { return mc.name }
+
+// Value returns all values, separated by a newline character.
+func (mc *multiChoice) Value() string { return strings.Join(mc.values, multiValueSeparator) }
+
+// Values returns all selected values.
+func (mc *multiChoice) Values() []string { return slices.Clone(mc.values) }
+
+// Clear the element.
+func (mc *multiChoice) Clear() { mc.values = nil }
+
+// SetValue sets the values, separated by a newline character.
+func (mc *multiChoice) SetValue(value string) error {
+	if value == "" {
+		mc.values = nil
+		return nil
+	}
+	return mc.SetValues(strings.Split(value, multiValueSeparator))
+}
+
+// SetValues sets the selected values. Empty values are ignored, duplicate
+// values are stored only once.
+func (mc *multiChoice) SetValues(values []string) error {
+	result := make([]string, 0, len(values))
+	var err error
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value == "" || slices.Contains(result, value) {
+			continue
+		}
+		result = append(result, value)
+		if err == nil && !mc.hasChoice(value) {
+			err = fmt.Errorf("no such choice: %q", value)
+		}
+	}
+	if len(result) == 0 {
+		result = nil
+	}
+	mc.values = result
+	return err
+}
+
+func (mc *multiChoice) hasChoice(value string) bool {
+	for i := 0; i < len(mc.choices); i += 2 {
+		if mc.choices[i] == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Validators return the active validators.
+func (mc *multiChoice) Validators() Validators {
+	if mc.disabled {
+		return nil
+	}
+	return mc.validators
+}
+
+// Disable the element.
+func (mc *multiChoice) Disable() { mc.disabled = true }
+
+func (mc *multiChoice) isDisabled() bool { return mc.disabled }
+
+// SetChoices allows to update the choices after field creation, e.g. for
+// dynamically generated choices.
+func (mc *multiChoice) SetChoices(choices []string) { mc.choices = checkChoices(choices) }
+
+// ----- <select multiple ...>...</select> field
+
+// MultiSelectElement represents a select form element, where multiple
+// choices can be selected.
+type MultiSelectElement struct {
+	multiChoice
+}
+
+// MultiSelectField creates a new select element that allows to select
+// multiple choices. Like [SelectField], choices contains pairs of a value
+// and its label.
+func MultiSelectField(name, label string, choices []string, validators ...Validator) *MultiSelectElement {
+	mse := &MultiSelectElement{}
+	mse.init(name, label, choices, validators)
+	return mse
+}
+
+// Render the select element without warnings.
+func (mse *MultiSelectElement) Render(fieldID string, messages []string) *htmls.Node {
+	return mse.RenderWarnings(fieldID, messages, nil)
+}
+
+// RenderWarnings renders the select element, with its error and warning
+// messages.
+func (mse *MultiSelectElement) RenderWarnings(fieldID string, messages, warnings []string) *htmls.Node {
+	valAttrs := makeValidatorAttributes(mse.Validators())
+	attrs := makeAttributes(3, valAttrs, mse.disabled)
+	attrs = append(attrs,
+		htmls.Attribute{Key: "id", Value: fieldID},
+		htmls.Attribute{Key: "name", Value: mse.name},
+		htmls.Attribute{Key: "multiple"},
+	)
+	attrs = addEnablingAttributes(attrs, mse.disabled, valAttrs)
+	attrs = mse.addDescribedBy(attrs, fieldID, len(messages)+len(warnings))
+	attrs = addInvalid(attrs, messages)
+
+	choiceNodes := make([]*htmls.Node, 0, len(mse.choices)/2)
+	for i := 0; i < len(mse.choices); i += 2 {
+		choice := mse.choices[i]
+		selected := slices.Contains(mse.values, choice)
+		optAttrs := makeAttributes(1, nil, mse.disabled, selected)
+		optAttrs = append(optAttrs, htmls.Attribute{Key: "value", Value: choice})
+		optAttrs = addEnablingAttributes(optAttrs, mse.disabled, nil)
+		optAttrs = addBoolAttribute(optAttrs, "selected", selected)
+		choiceNodes = append(choiceNodes, htmls.Elem("option", optAttrs, htmls.Text(mse.choices[i+1])))
+	}
+
+	fr := mse.renderer()
+	return fr.WrapField(mse,
+		renderLabel(mse, fieldID, mse.label),
+		renderAllMessages(fr, fieldID, messages, warnings),
+		htmls.Elem("select", attrs, choiceNodes...),
+		mse.renderHelp(fieldID))
+}
+
+// ----- Group of <input type="checkbox" ...> fields with the same name
+
+// CheckboxGroupElement represents a group of checkboxes with the same name,
+// where each checkbox represents one choice.
+type CheckboxGroupElement struct {
+	multiChoice
+}
+
+// CheckboxGroupField creates a new group of checkboxes. Like [SelectField],
+// choices contains pairs of a value and its label.
+//
+// Since browsers would require each checkbox to be checked, validator
+// attributes like "required" are not rendered for the checkboxes.
+func CheckboxGroupField(name, label string, choices []string, validators ...Validator) *CheckboxGroupElement {
+	cge := &CheckboxGroupElement{}
+	cge.init(name, label, choices, validators)
+	return cge
+}
+
+// Render the group of checkboxes without warnings.
+func (cge *CheckboxGroupElement) Render(fieldID string, messages []string) *htmls.Node {
+	return cge.RenderWarnings(fieldID, messages, nil)
+}
+
+// RenderWarnings renders the group of checkboxes as a fieldset, with its
+// error and warning messages.
+func (cge *CheckboxGroupElement) RenderWarnings(fieldID string, messages, warnings []string) *htmls.Node {
+	fsAttrs := cge.addDescribedBy(htmls.Attrs("id", fieldID, "class", "checkbox-group"), fieldID, len(messages)+len(warnings))
+	fsNode := htmls.Elem("fieldset", fsAttrs)
+	if label := cge.label; label != "" {
+		if cge.Validators().HasRequired() {
+			label += cge.requiredMarker()
+		}
+		fsNode.Children = append(fsNode.Children, htmls.Elem("legend", nil, htmls.Text(label)))
+	}
+	fsNode.Children = append(fsNode.Children, renderAllMessages(cge.renderer(), fieldID, messages, warnings)...)
+	for i := 0; i < len(cge.choices); i += 2 {
+		choice := cge.choices[i]
+		checked := slices.Contains(cge.values, choice)
+		choiceID := fieldID + "-" + strconv.Itoa(i/2)
+		attrs := makeAttributes(4, nil, checked, cge.disabled)
+		attrs = append(attrs,
+			htmls.Attribute{Key: "id", Value: choiceID},
+			htmls.Attribute{Key: "name", Value: cge.name},
+			htmls.Attribute{Key: "type", Value: "checkbox"},
+			htmls.Attribute{Key: "value", Value: choice},
+		)
+		attrs = addBoolAttribute(attrs, "checked", checked)
+		attrs = addEnablingAttributes(attrs, cge.disabled, nil)
+		fsNode.Children = append(fsNode.Children, htmls.Elem("div", nil,
+			htmls.Elem("input", attrs),
+			htmls.Elem("label", htmls.Attrs("for", choiceID), htmls.Text(cge.choices[i+1])),
+		))
+	}
+	fsNode.Children = append(fsNode.Children, cge.renderHelp(fieldID)...)
+	return fsNode
+}
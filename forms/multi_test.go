@@ -0,0 +1,120 @@
+// -----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+// -----------------------------------------------------------------------------
+
+package forms_test
+
+import (
+	"net/url"
+	"slices"
+	"testing"
+
+	"t73f.de/r/webs/forms"
+)
+
+var colorChoices = []string{"r", "Red", "g", "Green", "b", "Blue"}
+
+func TestMultiSelectField(t *testing.T) {
+	mse := forms.MultiSelectField("colors", "Colors", colorChoices, forms.Required{})
+	f := forms.Define(mse)
+	if !f.SetFormValues(url.Values{"colors": {"r", "b", "r"}}, nil) {
+		t.Fatalf("values must be accepted: %v", f.Messages())
+	}
+	if got, exp := mse.Values(), []string{"r", "b"}; !slices.Equal(got, exp) {
+		t.Errorf("values %v expected, got %v", exp, got)
+	}
+	if got, exp := f.Data()["colors"], "r\nb"; got != exp {
+		t.Errorf("data %q expected, got %q", exp, got)
+	}
+	if got, exp := f.MultiData().Get("colors"), []string{"r", "b"}; !slices.Equal(got, exp) {
+		t.Errorf("multi data %v expected, got %v", exp, got)
+	}
+	if !f.IsValid() {
+		t.Errorf("form must be valid: %v", f.Messages())
+	}
+
+	got := renderForm(f)
+	exp := `<form action="" method="POST"><div><label for="colors">Colors*</label>` +
+		`<select id="colors" name="colors" multiple="" required="">` +
+		`<option value="r" selected="">Red</option>` +
+		`<option value="g">Green</option>` +
+		`<option value="b" selected="">Blue</option>` +
+		`</select></div></form>`
+	if got != exp {
+		t.Errorf("\nexpected: %q\nbut got:  %q", exp, got)
+	}
+
+	if f.SetFormValues(url.Values{"colors": {"r", "x"}}, nil) {
+		t.Error("unknown choice must not be accepted")
+	}
+}
+
+func TestCheckboxGroupField(t *testing.T) {
+	cge := forms.CheckboxGroupField("colors", "Colors", colorChoices, forms.Required{})
+	f := forms.Define(cge, forms.SubmitField("save", "Save"))
+	f.SetData(forms.Data{"colors": "g\nb"})
+	if got, exp := cge.Values(), []string{"g", "b"}; !slices.Equal(got, exp) {
+		t.Errorf("values %v expected, got %v", exp, got)
+	}
+
+	got := renderForm(f)
+	exp := `<form action="" method="POST"><fieldset id="colors" class="checkbox-group"><legend>Colors*</legend>` +
+		`<div><input id="colors-0" name="colors" type="checkbox" value="r"><label for="colors-0">Red</label></div>` +
+		`<div><input id="colors-1" name="colors" type="checkbox" value="g" checked=""><label for="colors-1">Green</label></div>` +
+		`<div><input id="colors-2" name="colors" type="checkbox" value="b" checked=""><label for="colors-2">Blue</label></div>` +
+		`</fieldset><div><input id="save" name="save" type="submit" value="Save" class="primary"></div></form>`
+	if got != exp {
+		t.Errorf("\nexpected: %q\nbut got:  %q", exp, got)
+	}
+
+	// Browsers do not send a group without checked boxes.
+	f.Clear()
+	f.SetFormValues(url.Values{"save": {"Save"}}, nil)
+	if value, found := f.PresentData().Lookup("colors"); !found || value != "" {
+		t.Errorf("empty group must be present, got %q/%v", value, found)
+	}
+	if f.IsValid() {
+		t.Error("required group without value must be invalid")
+	}
+}
+
+func TestAnyOfMultiField(t *testing.T) {
+	testcases := []struct {
+		name   string
+		values []string
+		valid  bool
+	}{
+		{"none", nil, true},
+		{"one", []string{"r"}, true},
+		{"all-valid", []string{"r", "g"}, true},
+		{"one-invalid", []string{"r", "b"}, false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := forms.Define(forms.CheckboxGroupField("colors", "Colors", colorChoices, forms.AnyOf("r", "g")))
+			f.SetFormValues(url.Values{"colors": tc.values}, nil)
+			if got := f.IsValid(); got != tc.valid {
+				t.Errorf("valid=%v expected, got %v (messages: %v)", tc.valid, got, f.Messages())
+			}
+		})
+	}
+}
+
+func TestMultiFieldDiff(t *testing.T) {
+	f := forms.Define(forms.MultiSelectField("colors", "Colors", colorChoices))
+	got := renderDiff(t, f, map[string][2]string{"colors": {"r", "g\nb"}})
+	exp := `<table class="form-diff"><tbody><tr><th scope="row">Colors</th>` +
+		`<td class="form-diff-old">Red</td><td class="form-diff-new">Green, Blue</td></tr></tbody></table>`
+	if got != exp {
+		t.Errorf("\nexpected: %q\nbut got:  %q", exp, got)
+	}
+}
@@ -0,0 +1,183 @@
+// -----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+// -----------------------------------------------------------------------------
+
+package forms
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"t73f.de/r/webs/htmls"
+	"t73f.de/r/webs/qrcode"
+)
+
+// ----- One-time code field
+
+// OTPElement represents a one-time code, e.g. of an authenticator app. It is
+// rendered as a group of single-digit inputs, which share the name of the
+// element.
+//
+// The inputs have stable hooks for a script that splits a pasted code across
+// them: the group has the attribute data-otp-digits, each input has the
+// attribute data-otp-index. Without a script, the whole code can be entered
+// into the first input.
+type OTPElement struct {
+	name       string
+	digits     int
+	value      string
+	validators Validators
+	disabled   bool
+	fieldHelp
+}
+
+// OTPField builds a new field for a one-time code with the given number of
+// digits.
+func OTPField(name string, digits int, validators ...Validator) *OTPElement {
+	return &OTPElement{
+		name:       name,
+		digits:     max(digits, 1),
+		validators: validators,
+	}
+}
+
+// Name returns the name of this element.
+func (oe *OTPElement) Name() string { return oe.name }
+
+// Value returns the one-time code.
+func (oe *OTPElement) Value() string { return oe.value }
+
+// Clear the element.
+func (oe *OTPElement) Clear() { oe.value = "" }
+
+// SetValue sets the one-time code. All white space is removed, since codes
+// are often displayed in groups.
+func (oe *OTPElement) SetValue(value string) error {
+	oe.value = strings.Join(strings.Fields(value), "")
+	return nil
+}
+
+// Validators returns all currently active validators, followed by the
+// validator that checks the number of digits of a non-empty code.
+func (oe *OTPElement) Validators() Validators {
+	if oe.disabled {
+		return nil
+	}
+	return append(oe.validators[:len(oe.validators):len(oe.validators)], ValidatorFunc(oe.check))
+}
+
+func (oe *OTPElement) check(f *Form, _ Field) error {
+	if oe.value == "" {
+		return nil
+	}
+	if len(oe.value) != oe.digits || strings.ContainsFunc(oe.value, func(r rune) bool { return r < '0' || r > '9' }) {
+		return CodedValidationError(CodeOTPDigits, f.sprintf(oe, MsgOTPDigits, oe.digits), map[string]any{"digits": oe.digits})
+	}
+	return nil
+}
+
+// Disable the element.
+func (oe *OTPElement) Disable() { oe.disabled = true }
+
+func (oe *OTPElement) isDisabled() bool { return oe.disabled }
+
+// Render the element without warnings.
+func (oe *OTPElement) Render(fieldID string, messages []string) *htmls.Node {
+	return oe.RenderWarnings(fieldID, messages, nil)
+}
+
+// RenderWarnings renders the element as a group of inputs, with its error
+// and warning messages. The first input has the given field identifier, the
+// others have the index appended to it.
+func (oe *OTPElement) RenderWarnings(fieldID string, messages, warnings []string) *htmls.Node {
+	valAttrs := makeValidatorAttributes(oe.validators)
+	numMessages := len(messages) + len(warnings)
+	divNode := htmls.Elem("div", htmls.Attrs("class", "otp", "data-otp-digits", strconv.Itoa(oe.digits)))
+	divNode.Children = append(divNode.Children, renderAllMessages(oe.renderer(), fieldID, messages, warnings)...)
+	for i := range oe.digits {
+		id, autocomplete := fieldID, "one-time-code"
+		if i > 0 {
+			id, autocomplete = fieldID+"-"+strconv.Itoa(i), "off"
+		}
+		value := ""
+		if i < len(oe.value) {
+			value = oe.value[i : i+1]
+			if i == oe.digits-1 {
+				value = oe.value[i:]
+			}
+		}
+		attrs := makeAttributes(8, valAttrs, oe.disabled)
+		attrs = append(attrs,
+			htmls.Attribute{Key: "id", Value: id},
+			htmls.Attribute{Key: "name", Value: oe.name},
+			htmls.Attribute{Key: "type", Value: "text"},
+			htmls.Attribute{Key: "inputmode", Value: "numeric"},
+			htmls.Attribute{Key: "autocomplete", Value: autocomplete},
+			htmls.Attribute{Key: "pattern", Value: "[0-9]*"},
+			htmls.Attribute{Key: "data-otp-index", Value: strconv.Itoa(i)},
+			htmls.Attribute{Key: "value", Value: value},
+		)
+		attrs = addEnablingAttributes(attrs, oe.disabled, valAttrs)
+		attrs = oe.addDescribedBy(attrs, fieldID, numMessages)
+		attrs = addInvalid(attrs, messages)
+		divNode.Children = append(divNode.Children, htmls.Elem("input", attrs))
+	}
+	divNode.Children = append(divNode.Children, oe.renderHelp(fieldID)...)
+	return divNode
+}
+
+// ----- Enrollment of an authenticator app
+
+// EnrollmentQRSize is the size in pixels of the QR code of [EnrollmentWidget].
+const EnrollmentQRSize = 256
+
+// EnrollmentWidget renders the information to enroll an authenticator app:
+// the QR code of the provisioning URI (e.g. "otpauth://totp/...") and the
+// secret for manual entry, grouped in fours. The raw secret is stored in
+// the attribute data-clipboard-text, as a hook for a copy-to-clipboard
+// script.
+//
+// If the URI cannot be encoded as a QR code, nil is returned.
+func EnrollmentWidget(provisioningURI string, secret string) *htmls.Node {
+	q, err := qrcode.New(provisioningURI, qrcode.Medium)
+	if err != nil {
+		return nil
+	}
+	img := q.HTMLImgNode(EnrollmentQRSize, "QR code for the authenticator app")
+	if img == nil {
+		return nil
+	}
+	secret = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, secret)
+	return htmls.Elem("div", htmls.Attrs("class", "otp-enrollment"),
+		img,
+		htmls.Elem("code", htmls.Attrs("class", "otp-secret", "data-clipboard-text", secret),
+			htmls.Text(groupSecret(secret))),
+	)
+}
+
+// groupSecret inserts a space after every four characters.
+func groupSecret(secret string) string {
+	var sb strings.Builder
+	for i, r := range []rune(secret) {
+		if i > 0 && i%4 == 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
@@ -0,0 +1,91 @@
+// -----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+// -----------------------------------------------------------------------------
+
+package forms_test
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"t73f.de/r/webs/forms"
+	"t73f.de/r/webs/htmls/render"
+	"t73f.de/r/webs/qrcode"
+)
+
+func TestOTPFieldValues(t *testing.T) {
+	testcases := []struct {
+		name   string
+		values []string
+		exp    string
+		valid  bool
+	}{
+		{"split", []string{"1", "2", "3", "4", "5", "6"}, "123456", true},
+		{"pasted", []string{"123456", "", "", "", "", ""}, "123456", true},
+		{"grouped", []string{"123 456"}, "123456", true},
+		{"empty", []string{"", "", "", "", "", ""}, "", false},
+		{"short", []string{"1", "2", "3"}, "123", false},
+		{"letters", []string{"12a456"}, "12a456", false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := forms.Define(forms.OTPField("code", 6, forms.Required{"code"}))
+			f.SetFormValues(url.Values{"code": tc.values}, nil)
+			if got := f.Data()["code"]; got != tc.exp {
+				t.Errorf("value %q expected, got %q", tc.exp, got)
+			}
+			if got := f.IsValid(); got != tc.valid {
+				t.Errorf("valid=%v expected, got %v (messages: %v)", tc.valid, got, f.Messages())
+			}
+		})
+	}
+}
+
+func TestOTPFieldRender(t *testing.T) {
+	f := forms.Define(forms.OTPField("code", 3))
+	f.SetData(forms.Data{"code": "12"})
+	got := renderForm(f)
+	exp := `<form action="" method="POST"><div class="otp" data-otp-digits="3">` +
+		`<input id="code" name="code" type="text" inputmode="numeric" autocomplete="one-time-code" pattern="[0-9]*" data-otp-index="0" value="1">` +
+		`<input id="code-1" name="code" type="text" inputmode="numeric" autocomplete="off" pattern="[0-9]*" data-otp-index="1" value="2">` +
+		`<input id="code-2" name="code" type="text" inputmode="numeric" autocomplete="off" pattern="[0-9]*" data-otp-index="2" value="">` +
+		`</div></form>`
+	if got != exp {
+		t.Errorf("\nexpected: %s\nbut got:  %s", exp, got)
+	}
+}
+
+func TestEnrollmentWidget(t *testing.T) {
+	uri := "otpauth://totp/Example:alice@example.com?secret=JBSWY3DPEHPK3PXP&issuer=Example"
+	node := forms.EnrollmentWidget(uri, "JBSWY3DPEHPK3PXP")
+	if node == nil {
+		t.Fatal("no widget rendered")
+	}
+	var sb strings.Builder
+	if err := render.Render(&sb, node); err != nil {
+		t.Fatal(err)
+	}
+	got := sb.String()
+
+	dataURI, err := qrcode.DataURI(uri, qrcode.Medium, forms.EnrollmentQRSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, `src="`+dataURI+`"`) {
+		t.Errorf("QR code of provisioning URI expected, got %s", got)
+	}
+	expCode := `<code class="otp-secret" data-clipboard-text="JBSWY3DPEHPK3PXP">JBSW Y3DP EHPK 3PXP</code>`
+	if !strings.Contains(got, expCode) {
+		t.Errorf("%s expected, got %s", expCode, got)
+	}
+}
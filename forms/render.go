@@ -0,0 +1,134 @@
+// -----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+// -----------------------------------------------------------------------------
+
+package forms
+
+import "t73f.de/r/webs/htmls"
+
+// ----- Customization of rendered fields
+
+// FieldRenderer customizes the HTML structure and the CSS classes of
+// rendered fields, e.g. to adapt them to a CSS framework. See
+// [Form.SetRenderer].
+type FieldRenderer interface {
+	// WrapField combines the parts of a rendered field: its label, its
+	// messages, the control (e.g. <input>, <select>, or <textarea>), and its
+	// help. The label may be nil, messages and help may be empty.
+	WrapField(field Field, label *htmls.Node, messages []*htmls.Node, control *htmls.Node, help []*htmls.Node) *htmls.Node
+
+	// MessageNode renders a single error message or warning of a field. The
+	// node must have the given identifier, since the control refers to it
+	// via "aria-describedby".
+	MessageNode(id, msg string, warning bool) *htmls.Node
+
+	// SubmitClass returns the "class" attribute of a submit field with the
+	// given priority, see [SubmitElement.SetPriority]. An empty class is
+	// not rendered.
+	SubmitClass(prio uint8) string
+}
+
+// DefaultRenderer is the [FieldRenderer] that is used, if none was set.
+type DefaultRenderer struct{}
+
+// WrapField puts all parts into a <div>: the label, the messages, the
+// control, and the help. Only for a checkbox, the control precedes the
+// label, and no messages are rendered.
+func (DefaultRenderer) WrapField(field Field, label *htmls.Node, messages []*htmls.Node, control *htmls.Node, help []*htmls.Node) *htmls.Node {
+	divNode := htmls.Elem("div", nil)
+	divNode.Children = make([]*htmls.Node, 0, 2+len(messages)+len(help))
+	if _, isCheckbox := field.(*CheckboxElement); isCheckbox {
+		divNode.AddChildren(control, label)
+	} else {
+		divNode.AddChildren(label)
+		divNode.AddChildren(messages...)
+		divNode.AddChildren(control)
+	}
+	divNode.AddChildren(help...)
+	return divNode
+}
+
+// CSS classes of rendered messages, depending on their severity.
+const (
+	classMessageError   = "message error"
+	classMessageWarning = "message warning"
+)
+
+// MessageNode renders the message as a <span> with the class "message
+// error" or "message warning".
+func (DefaultRenderer) MessageNode(id, msg string, warning bool) *htmls.Node {
+	class := classMessageError
+	if warning {
+		class = classMessageWarning
+	}
+	return htmls.Elem("span", htmls.Attrs("class", class, "id", id), htmls.Text(msg))
+}
+
+// Priorities of submit fields, see [SubmitElement.SetPriority].
+const (
+	submitPrioCancel = 3 // must always be the last, see se.SetCancel()
+	numSubmitPrios   = submitPrioCancel + 1
+)
+
+var submitPrioClass = [numSubmitPrios]string{"primary", "secondary", "tertiary", "cancel"}
+
+// SubmitClass returns "primary", "secondary", "tertiary", or "cancel".
+func (DefaultRenderer) SubmitClass(prio uint8) string {
+	if int(prio) < len(submitPrioClass) {
+		return submitPrioClass[prio]
+	}
+	return ""
+}
+
+// SetRenderer sets the renderer of all fields of the form. A nil renderer
+// restores the [DefaultRenderer].
+func (f *Form) SetRenderer(fr FieldRenderer) *Form {
+	f.renderer = fr
+	return f
+}
+
+// fieldRenderer returns the renderer of the form.
+func (f *Form) fieldRenderer() FieldRenderer {
+	if f != nil && f.renderer != nil {
+		return f.renderer
+	}
+	return DefaultRenderer{}
+}
+
+// fieldRendering stores the renderer of a field's form. It is embedded into
+// all fields that render messages or CSS classes.
+type fieldRendering struct {
+	formRenderer *FieldRenderer // renderer of the form, see Form.addName
+}
+
+func (fr *fieldRendering) setFormRenderer(r *FieldRenderer) { fr.formRenderer = r }
+
+// renderer returns the renderer of the field's form, or the default one.
+func (fr *fieldRendering) renderer() FieldRenderer {
+	if fr.formRenderer != nil && *fr.formRenderer != nil {
+		return *fr.formRenderer
+	}
+	return DefaultRenderer{}
+}
+
+// renderAllMessages renders all messages and warnings. Each message has an
+// identifier, so that it can be referenced by the field.
+func renderAllMessages(fr FieldRenderer, fieldID string, messages, warnings []string) []*htmls.Node {
+	result := make([]*htmls.Node, 0, len(messages)+len(warnings))
+	for i, msg := range messages {
+		result = append(result, fr.MessageNode(messageID(fieldID, i), msg, false))
+	}
+	for i, msg := range warnings {
+		result = append(result, fr.MessageNode(messageID(fieldID, len(messages)+i), msg, true))
+	}
+	return result
+}
@@ -0,0 +1,122 @@
+// -----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+// -----------------------------------------------------------------------------
+
+package forms_test
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"t73f.de/r/webs/forms"
+	"t73f.de/r/webs/htmls"
+)
+
+// frameworkRenderer adapts the rendered fields to a fictitious CSS framework.
+type frameworkRenderer struct{ forms.DefaultRenderer }
+
+func (frameworkRenderer) WrapField(_ forms.Field, label *htmls.Node, messages []*htmls.Node, control *htmls.Node, help []*htmls.Node) *htmls.Node {
+	node := htmls.Elem("div", htmls.Attrs("class", "mb-3"), label, control)
+	node.AddChildren(messages...)
+	node.AddChildren(help...)
+	return node
+}
+
+func (frameworkRenderer) MessageNode(id, msg string, warning bool) *htmls.Node {
+	class := "invalid-feedback"
+	if warning {
+		class = "form-text text-warning"
+	}
+	return htmls.Elem("div", htmls.Attrs("id", id, "class", class), htmls.Text(msg))
+}
+
+func (frameworkRenderer) SubmitClass(prio uint8) string {
+	if prio == 0 {
+		return "btn btn-primary"
+	}
+	return "btn btn-secondary"
+}
+
+func TestRendererCustom(t *testing.T) {
+	f := forms.Define(
+		forms.TextField("name", "Name", forms.Required{"name required"}),
+		forms.CheckboxField("agree", "Agree"),
+		forms.FieldsetField("extra", "Extra", forms.TextField("note", "Note")),
+		forms.SubmitField("save", "Save"),
+		forms.SubmitField("cancel", "Cancel").SetCancel(),
+	).SetRenderer(frameworkRenderer{})
+	f.SetFormValues(url.Values{}, nil)
+	f.IsValid()
+	got := renderForm(f)
+	exp := `<form action="" method="POST">` +
+		`<div class="mb-3"><label for="name">Name*</label><input id="name" name="name" type="text" value="" required="" aria-describedby="name-msg-0" aria-invalid="true"><div id="name-msg-0" class="invalid-feedback">name required</div></div>` +
+		`<div class="mb-3"><label for="agree">Agree</label><input id="agree" name="agree" type="checkbox" value="agree"></div>` +
+		`<fieldset id="extra" name="extra"><legend>Extra</legend><div class="mb-3"><label for="note">Note</label><input id="note" name="note" type="text" value=""></div></fieldset>` +
+		`<div><input id="save" name="save" type="submit" value="Save" class="btn btn-primary"><input id="cancel" name="cancel" type="submit" value="Cancel" class="btn btn-secondary" formnovalidate=""></div>` +
+		`</form>`
+	if got != exp {
+		t.Errorf("\nexpected: %s\nbut got:  %s", exp, got)
+	}
+
+	// The default renderer is restored.
+	f.SetRenderer(nil)
+	if got = renderForm(f); !strings.Contains(got, `<span class="message error" id="name-msg-0">name required</span>`) ||
+		!strings.Contains(got, `class="primary"`) {
+		t.Errorf("default rendering expected, got %s", got)
+	}
+}
+
+type noClassRenderer struct{ forms.DefaultRenderer }
+
+func (noClassRenderer) SubmitClass(uint8) string { return "" }
+
+func TestRendererNoSubmitClass(t *testing.T) {
+	f := forms.Define(forms.SubmitField("save", "Save")).SetRenderer(noClassRenderer{})
+	if got, exp := renderForm(f), `<form action="" method="POST"><div><input id="save" name="save" type="submit" value="Save"></div></form>`; got != exp {
+		t.Errorf("\nexpected: %s\nbut got:  %s", exp, got)
+	}
+}
+
+func TestRenderSubmitOrder(t *testing.T) {
+	f := forms.Define(
+		forms.SubmitField("back", "Back").SetCancel(),
+		forms.SubmitField("draft", "Draft").SetPriority(1),
+		forms.SubmitField("save", "Save"),
+		forms.SubmitField("preview", "Preview").SetPriority(1),
+		forms.TextField("name", "Name"),
+		forms.SubmitField("delete", "Delete").SetPriority(2),
+		forms.SubmitField("publish", "Publish"),
+	).SetRenderer(noClassRenderer{})
+	submitNames := func() []string {
+		var result []string
+		for _, part := range strings.Split(renderForm(f), `name="`)[1:] {
+			name, _, _ := strings.Cut(part, `"`)
+			result = append(result, name)
+		}
+		return result
+	}
+	if got, exp := submitNames(), []string{"save", "draft", "preview", "back", "name", "publish", "delete"}; !slices.Equal(got, exp) {
+		t.Errorf("priority order %v expected, but got %v", exp, got)
+	}
+
+	f.SetSubmitOrder("back", "delete", "preview")
+	if got, exp := submitNames(), []string{"back", "preview", "save", "draft", "name", "delete", "publish"}; !slices.Equal(got, exp) {
+		t.Errorf("explicit order %v expected, but got %v", exp, got)
+	}
+
+	f.SetSubmitOrder()
+	if got, exp := submitNames(), []string{"save", "draft", "preview", "back", "name", "publish", "delete"}; !slices.Equal(got, exp) {
+		t.Errorf("restored order %v expected, but got %v", exp, got)
+	}
+}
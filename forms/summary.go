@@ -0,0 +1,216 @@
+// -----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+// -----------------------------------------------------------------------------
+
+package forms
+
+import (
+	"strings"
+
+	"t73f.de/r/webs/htmls"
+)
+
+// SummaryOptions configure [Form.RenderSummary].
+type SummaryOptions struct {
+	Table     bool   // Render a table instead of a definition list.
+	SkipEmpty bool   // Do not show fields without a value that are not required.
+	Yes       string // Text of a checked checkbox. Default: "yes".
+	No        string // Text of an unchecked checkbox. Default: "no".
+	Masked    string // Text of a password field with a value. Default: "********".
+}
+
+// RenderSummary renders the values of all fields read-only, e.g. for a page
+// that asks the user to confirm the input before it is finally submitted.
+//
+// Each field results in its label and its value, formatted like in
+// [Form.RenderDiff]: dates and numbers according to the locale of the form,
+// select and checkbox group values with the labels of their choices. The
+// value of a checkbox is shown as a yes/no text, the value of a password
+// field is masked, and line breaks of a text area are preserved. Submit,
+// version, challenge, one-time code, and flow content fields are not shown.
+//
+// The fields of a fieldset with a legend are grouped below the legend. By
+// default, the result is a definition list (<dl class="form-summary">),
+// where each group is a nested list. As a table, each group is a separate
+// <tbody> with the legend as its header. If no field is shown, nil is
+// returned.
+//
+// See [Form.RenderSummaryData] to send the data with the confirmation.
+func (f *Form) RenderSummary(opts SummaryOptions) *htmls.Node {
+	if f == nil {
+		return nil
+	}
+	if opts.Yes == "" {
+		opts.Yes = "yes"
+	}
+	if opts.No == "" {
+		opts.No = "no"
+	}
+	if opts.Masked == "" {
+		opts.Masked = "********"
+	}
+	if opts.Table {
+		return f.summaryTable(f.fields, opts)
+	}
+	return f.summaryList(f.fields, opts)
+}
+
+// summaryEntry is the label and the value nodes of one field.
+type summaryEntry struct {
+	label string
+	value []*htmls.Node
+}
+
+func (f *Form) summaryList(fields []Field, opts SummaryOptions) *htmls.Node {
+	var children []*htmls.Node
+	f.walkSummary(fields, opts,
+		func(e summaryEntry) {
+			children = append(children, htmls.Elem("dt", nil, htmls.Text(e.label)), htmls.Elem("dd", nil, e.value...))
+		},
+		func(fs *Fieldset) {
+			if group := f.summaryList(fs.fields, opts); group != nil {
+				group.Attributes = htmls.Attrs("class", "form-summary-group")
+				children = append(children,
+					htmls.Elem("dt", htmls.Attrs("class", "form-summary-legend"), htmls.Text(fs.legend)),
+					htmls.Elem("dd", nil, group))
+			}
+		})
+	if len(children) == 0 {
+		return nil
+	}
+	return htmls.Elem("dl", htmls.Attrs("class", "form-summary"), children...)
+}
+
+func (f *Form) summaryTable(fields []Field, opts SummaryOptions) *htmls.Node {
+	var bodies []*htmls.Node
+	var rows []*htmls.Node
+	var walk func([]Field, string)
+	walk = func(fields []Field, legend string) {
+		f.walkSummary(fields, opts,
+			func(e summaryEntry) {
+				rows = append(rows, htmls.Elem("tr", nil,
+					htmls.Elem("th", htmls.Attrs("scope", "row"), htmls.Text(e.label)),
+					htmls.Elem("td", nil, e.value...)))
+			},
+			func(fs *Fieldset) {
+				bodies = appendSummaryBody(bodies, legend, rows)
+				rows = nil
+				walk(fs.fields, fs.legend)
+				bodies = appendSummaryBody(bodies, fs.legend, rows)
+				rows = nil
+			})
+	}
+	walk(fields, "")
+	bodies = appendSummaryBody(bodies, "", rows)
+	if len(bodies) == 0 {
+		return nil
+	}
+	return htmls.Elem("table", htmls.Attrs("class", "form-summary"), bodies...)
+}
+
+// appendSummaryBody appends a <tbody> with the given rows, if there are any.
+func appendSummaryBody(bodies []*htmls.Node, legend string, rows []*htmls.Node) []*htmls.Node {
+	if len(rows) == 0 {
+		return bodies
+	}
+	if legend != "" {
+		header := htmls.Elem("tr", htmls.Attrs("class", "form-summary-legend"),
+			htmls.Elem("th", htmls.Attrs("colspan", "2", "scope", "rowgroup"), htmls.Text(legend)))
+		rows = append([]*htmls.Node{header}, rows...)
+	}
+	return append(bodies, htmls.Elem("tbody", nil, rows...))
+}
+
+// walkSummary calls entry for all shown fields, and group for all fieldsets
+// with a legend. The fields of a fieldset without a legend are walked as if
+// they were not within a fieldset.
+func (f *Form) walkSummary(fields []Field, opts SummaryOptions, entry func(summaryEntry), group func(*Fieldset)) {
+	for _, field := range fields {
+		if fs, isFieldset := field.(*Fieldset); isFieldset {
+			if fs.legend != "" {
+				group(fs)
+			} else {
+				f.walkSummary(fs.fields, opts, entry, group)
+			}
+			continue
+		}
+		if e, ok := f.summaryEntry(field, opts); ok {
+			entry(e)
+		}
+	}
+}
+
+// summaryEntry returns the label and the formatted value of the field, and
+// whether it should be shown.
+func (f *Form) summaryEntry(field Field, opts SummaryOptions) (summaryEntry, bool) {
+	var e summaryEntry
+	var value string
+	switch fd := field.(type) {
+	case *InputElement:
+		e.label = fd.label
+		if fd.itype == itypePassword {
+			if fd.value != "" {
+				value = opts.Masked
+			}
+		} else {
+			value = fd.DisplayValue()
+		}
+	case *TextAreaElement:
+		e.label = fd.label
+		for i, line := range strings.Split(strings.ReplaceAll(fd.value, "\r\n", "\n"), "\n") {
+			if i > 0 {
+				e.value = append(e.value, htmls.Elem("br", nil))
+			}
+			e.value = append(e.value, textNodes(line)...)
+		}
+		if fd.value == "" {
+			e.value = nil
+		}
+	case *SelectElement:
+		e.label, value = fd.label, fd.choiceLabel(fd.value)
+	case *MultiSelectElement:
+		e.label, value = fd.label, fd.choiceLabels(fd.Value())
+	case *CheckboxGroupElement:
+		e.label, value = fd.label, fd.choiceLabels(fd.Value())
+	case *CheckboxElement:
+		e.label, value = fd.label, opts.No
+		if fd.value != "" {
+			value = opts.Yes
+		}
+	case *FileElement:
+		e.label, value = fd.label, fd.Filename()
+	default:
+		return e, false
+	}
+	if e.value == nil {
+		e.value = textNodes(value)
+	}
+	if len(e.value) == 0 && opts.SkipEmpty && !field.Validators().HasRequired() {
+		return e, false
+	}
+	return e, true
+}
+
+// RenderSummaryData returns a hidden input element with the given name,
+// whose value contains the data of the form, see [Form.MarshalData]. Placed
+// within the form of a confirmation page, the final submit does not need to
+// send all inputs again: use [Form.UnmarshalData] with the submitted value
+// and validate the form again, since the data may have been changed by the
+// client. Values of password, challenge, file, and submit fields are not
+// contained.
+func (f *Form) RenderSummaryData(name string) (*htmls.Node, error) {
+	data, err := f.MarshalData()
+	if err != nil {
+		return nil, err
+	}
+	return htmls.Elem("input", htmls.Attrs("type", "hidden", "name", name, "value", string(data))), nil
+}
@@ -0,0 +1,180 @@
+// -----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+// -----------------------------------------------------------------------------
+
+package forms_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"t73f.de/r/webs/forms"
+	"t73f.de/r/webs/htmls"
+	"t73f.de/r/webs/htmls/render"
+)
+
+func renderNode(t *testing.T, node *htmls.Node) string {
+	t.Helper()
+	var sb strings.Builder
+	if err := render.Render(&sb, node); err != nil {
+		t.Fatal(err)
+	}
+	return sb.String()
+}
+
+func makeSummaryForm(t *testing.T) *forms.Form {
+	t.Helper()
+	f := forms.Define(
+		forms.TextField("name", "Name", forms.Required{"name required"}),
+		forms.EmailField("email", "E-Mail"),
+		forms.PasswordField("password", "Password"),
+		forms.FieldsetField("order", "Order",
+			forms.SelectField("size", "Size", []string{"s", "Small", "l", "Large"}),
+			forms.MultiSelectField("toppings", "Toppings", []string{"c", "Cheese", "h", "Ham", "o", "Olives"}),
+			forms.CheckboxGroupField("extras", "Extras", []string{"n", "Napkins", "d", "Dip"}),
+			forms.NumberField("count", "Count"),
+			forms.FieldsetField("delivery", "",
+				forms.DateField("date", "Date"),
+				forms.DatetimeField("at", "At"),
+				forms.TimeField("slot", "Slot"),
+			),
+		),
+		forms.TextAreaField("note", "Note"),
+		forms.CheckboxField("gift", "Gift"),
+		forms.CheckboxField("invoice", "Invoice"),
+		forms.TelField("phone", "Phone"),
+		forms.VersionField("version", "3"),
+		forms.FlowContentField("hint", htmls.Text("hint")),
+		forms.SubmitField("confirm", "Confirm"),
+	).SetLocale(forms.Locale{DateLayout: "02.01.2006", DatetimeLayout: "02.01.2006 15:04", DecimalSeparator: ","})
+	f.SetData(forms.Data{
+		"name":     "Alice <A>",
+		"email":    "alice@example.com",
+		"password": "secret",
+		"size":     "l",
+		"toppings": "c\nh",
+		"extras":   "d",
+		"count":    "2.50",
+		"date":     "2025-03-01",
+		"at":       "2025-03-01T18:30",
+		"note":     "Ring twice.\r\nThird floor.",
+		"gift":     "gift",
+	})
+	if msgs := f.Messages(); len(msgs) > 0 {
+		t.Fatalf("unexpected messages: %v", msgs)
+	}
+	return f
+}
+
+func TestRenderSummaryList(t *testing.T) {
+	f := makeSummaryForm(t)
+	got := renderNode(t, f.RenderSummary(forms.SummaryOptions{}))
+	exp := `<dl class="form-summary">` +
+		`<dt>Name</dt><dd>Alice &lt;A&gt;</dd>` +
+		`<dt>E-Mail</dt><dd>alice@example.com</dd>` +
+		`<dt>Password</dt><dd>********</dd>` +
+		`<dt class="form-summary-legend">Order</dt><dd><dl class="form-summary-group">` +
+		`<dt>Size</dt><dd>Large</dd>` +
+		`<dt>Toppings</dt><dd>Cheese, Ham</dd>` +
+		`<dt>Extras</dt><dd>Dip</dd>` +
+		`<dt>Count</dt><dd>2,5</dd>` +
+		`<dt>Date</dt><dd>01.03.2025</dd>` +
+		`<dt>At</dt><dd>01.03.2025 18:30</dd>` +
+		`<dt>Slot</dt><dd></dd>` +
+		`</dl></dd>` +
+		`<dt>Note</dt><dd>Ring twice.<br>Third floor.</dd>` +
+		`<dt>Gift</dt><dd>yes</dd>` +
+		`<dt>Invoice</dt><dd>no</dd>` +
+		`<dt>Phone</dt><dd></dd>` +
+		`</dl>`
+	if got != exp {
+		t.Errorf("expected\n%s\ngot\n%s", exp, got)
+	}
+}
+
+func TestRenderSummaryTable(t *testing.T) {
+	f := makeSummaryForm(t)
+	got := renderNode(t, f.RenderSummary(forms.SummaryOptions{
+		Table: true, SkipEmpty: true, Yes: "ja", No: "nein", Masked: "(set)",
+	}))
+	row := func(label, value string) string {
+		return `<tr><th scope="row">` + label + `</th><td>` + value + `</td></tr>`
+	}
+	exp := `<table class="form-summary">` +
+		`<tbody>` + row("Name", "Alice &lt;A&gt;") + row("E-Mail", "alice@example.com") + row("Password", "(set)") + `</tbody>` +
+		`<tbody><tr class="form-summary-legend"><th colspan="2" scope="rowgroup">Order</th></tr>` +
+		row("Size", "Large") + row("Toppings", "Cheese, Ham") + row("Extras", "Dip") + row("Count", "2,5") +
+		row("Date", "01.03.2025") + row("At", "01.03.2025 18:30") + `</tbody>` +
+		`<tbody>` + row("Note", "Ring twice.<br>Third floor.") + row("Gift", "ja") + row("Invoice", "nein") + `</tbody>` +
+		`</table>`
+	if got != exp {
+		t.Errorf("expected\n%s\ngot\n%s", exp, got)
+	}
+}
+
+func TestRenderSummaryEmpty(t *testing.T) {
+	f := forms.Define(
+		forms.TextField("name", "Name", forms.Required{"name required"}),
+		forms.TextField("nick", "Nickname"),
+		forms.FieldsetField("extra", "Extra", forms.TextAreaField("note", "Note")),
+		forms.SubmitField("save", "Save"),
+	)
+	got := renderNode(t, f.RenderSummary(forms.SummaryOptions{SkipEmpty: true}))
+	if exp := `<dl class="form-summary"><dt>Name</dt><dd></dd></dl>`; got != exp {
+		t.Errorf("expected\n%s\ngot\n%s", exp, got)
+	}
+
+	f = forms.Define(forms.TextField("nick", "Nickname"), forms.SubmitField("save", "Save"))
+	for _, table := range []bool{false, true} {
+		if node := f.RenderSummary(forms.SummaryOptions{Table: table, SkipEmpty: true}); node != nil {
+			t.Errorf("no summary expected, got %s", renderNode(t, node))
+		}
+	}
+	if node := (*forms.Form)(nil).RenderSummary(forms.SummaryOptions{}); node != nil {
+		t.Error("no summary of nil form expected")
+	}
+}
+
+func TestRenderSummaryData(t *testing.T) {
+	f := makeSummaryForm(t)
+	node, err := f.RenderSummaryData("summary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node.Data != "input" {
+		t.Fatalf("input expected, got %s", renderNode(t, node))
+	}
+	attrs := map[string]string{}
+	for _, attr := range node.Attributes {
+		attrs[attr.Key] = attr.Value
+	}
+	if attrs["type"] != "hidden" || attrs["name"] != "summary" {
+		t.Errorf("hidden input expected, got %v", attrs)
+	}
+
+	other := makeSummaryForm(t)
+	other.Clear()
+	if err = other.UnmarshalData([]byte(attrs["value"])); err != nil {
+		t.Fatal(err)
+	}
+	if exp, got := renderNode(t, f.RenderSummary(forms.SummaryOptions{})), renderNode(t, other.RenderSummary(forms.SummaryOptions{})); got != strings.Replace(exp, "<dd>********</dd>", "<dd></dd>", 1) {
+		t.Errorf("summary of restored data differs:\n%s\n%s", exp, got)
+	}
+	var data map[string]any
+	if err = json.Unmarshal([]byte(attrs["value"]), &data); err != nil {
+		t.Fatal(err)
+	}
+	if _, found := data["password"]; found {
+		t.Errorf("password must not be stored: %v", data)
+	}
+}
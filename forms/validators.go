@@ -15,8 +15,12 @@ package forms
 
 import (
 	"fmt"
+	"regexp"
 	"slices"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 	"unicode/utf8"
 
 	"t73f.de/r/webs/htmls"
@@ -24,6 +28,11 @@ import (
 )
 
 // Validator is used to check if a field value is valid.
+//
+// An error that wraps multiple errors, e.g. one created by errors.Join,
+// results in a message for each of the wrapped errors. Errors created by
+// [CodedValidationError] and [CodedStopValidationError] carry a
+// machine-readable code, see [Form.FieldErrors].
 type Validator interface {
 	Check(*Form, Field) error
 }
@@ -42,6 +51,19 @@ type FieldAttributor interface {
 	Attributes() []htmls.Attribute
 }
 
+// fieldReferencer is implemented by validators that depend on the values of
+// other fields, e.g. [FieldStringEqual].
+type fieldReferencer interface {
+	referencedFields() []string
+}
+
+// references returns true, if the validator depends on the value of the
+// field with the given name.
+func references(v Validator, name string) bool {
+	fr, ok := v.(fieldReferencer)
+	return ok && slices.Contains(fr.referencedFields(), name)
+}
+
 // Validators is a sequence of Validator.
 type Validators []Validator
 
@@ -66,20 +88,27 @@ type StopValidationError string
 
 func (sve StopValidationError) Error() string { return string(sve) }
 
+// WarningError is a validation message that does not make the field invalid.
+// It is shown to the user, but the form can be submitted nevertheless.
+type WarningError string
+
+func (we WarningError) Error() string { return string(we) }
+
 // ----- Required: field must have a value.
 
 // Required is a validator that checks if data is available.
 type Required struct{ Message string }
 
 // Check the given field w.r.t. to this validator.
-func (ir Required) Check(_ *Form, field Field) error {
+func (ir Required) Check(f *Form, field Field) error {
 	if field.Value() != "" {
 		return nil
 	}
-	if ir.Message == "" {
-		return StopValidationError("Required")
+	msg := ir.Message
+	if msg == "" {
+		msg = f.sprintf(field, MsgRequired)
 	}
-	return StopValidationError(ir.Message)
+	return CodedStopValidationError(CodeRequired, msg, nil)
 }
 
 // Attributes returns HTML attributes.
@@ -100,6 +129,51 @@ func (Optional) Check(_ *Form, field Field) error {
 	return StopValidationError("")
 }
 
+// ----- RequiredIf / RequiredUnless: field must have a value, depending on another field.
+
+// RequiredIf is a validator that acts like [Required], if the field with
+// the given name has the given value, and like [Optional] otherwise. For a
+// [MultiField], the condition matches if one of its values is equal.
+//
+// Since the condition is checked only on the server, no HTML attribute
+// "required" is emitted.
+func RequiredIf(fieldName, equalsValue, message string) Validator {
+	return &requiredIf{fieldname: fieldName, value: equalsValue, message: message}
+}
+
+// RequiredUnless is a validator that acts like [Optional], if the field with
+// the given name has the given value, and like [Required] otherwise. It is
+// the opposite of [RequiredIf].
+func RequiredUnless(fieldName, equalsValue, message string) Validator {
+	return &requiredIf{fieldname: fieldName, value: equalsValue, message: message, negate: true}
+}
+
+type requiredIf struct {
+	fieldname string
+	value     string
+	message   string
+	negate    bool
+}
+
+func (ri *requiredIf) referencedFields() []string { return []string{ri.fieldname} }
+
+func (ri *requiredIf) Check(f *Form, field Field) error {
+	other, err := f.Field(ri.fieldname)
+	if err != nil {
+		return err
+	}
+	var matches bool
+	if mf, isMulti := other.(MultiField); isMulti {
+		matches = slices.Contains(mf.Values(), ri.value)
+	} else {
+		matches = other.Value() == ri.value
+	}
+	if matches != ri.negate {
+		return Required{Message: ri.message}.Check(f, field)
+	}
+	return Optional{}.Check(f, field)
+}
+
 // ----- MinMaxLength: field must have a value of a specific length.
 
 // MinMaxLength is a validator that checks for a length.
@@ -109,12 +183,14 @@ type MinMaxLength struct {
 }
 
 // Check the given field w.r.t. to this validator.
-func (mml *MinMaxLength) Check(_ *Form, field Field) error {
+func (mml *MinMaxLength) Check(f *Form, field Field) error {
 	if minl, curl := mml.MinLength, utf8.RuneCountInString(field.Value()); minl > 0 && curl < minl {
-		return ValidationError(fmt.Sprintf("minimum length of %s is %d, but got %d", field.Name(), minl, curl))
+		return CodedValidationError(CodeTooShort, f.sprintf(field, MsgMinLength, field.Name(), minl, curl),
+			map[string]any{"min": minl, "length": curl})
 	}
 	if maxl, curl := mml.MaxLength, utf8.RuneCountInString(field.Value()); maxl > 0 && curl > maxl {
-		return ValidationError(fmt.Sprintf("maximum length of %s is %d, but got %d", field.Name(), maxl, curl))
+		return CodedValidationError(CodeTooLong, f.sprintf(field, MsgMaxLength, field.Name(), maxl, curl),
+			map[string]any{"max": maxl, "length": curl})
 	}
 	return nil
 }
@@ -131,6 +207,62 @@ func (mml *MinMaxLength) Attributes() []htmls.Attribute {
 	return result
 }
 
+// ----- Regexp: field must match a regular expression.
+
+// Regexp is a validator that checks whether the whole value matches a
+// regular expression. An empty value is always valid, combine it with
+// [Required], if a value is needed.
+//
+// The regular expression is emitted as the HTML "pattern" attribute, so that
+// browsers are able to check the value. Since the syntax of Go regular
+// expressions and of HTML patterns (i.e. JavaScript) differ, e.g. for flags
+// like "(?i)" or named groups, the HTML pattern can be overridden by
+// Pattern, or suppressed by NoPattern.
+type Regexp struct {
+	Regexp    *regexp.Regexp
+	Message   string
+	Pattern   string // HTML pattern, if it should differ from Regexp.
+	NoPattern bool   // Do not emit a HTML pattern.
+
+	once     sync.Once
+	anchored *regexp.Regexp
+}
+
+// Check the given field w.r.t. to this validator.
+func (rv *Regexp) Check(f *Form, field Field) error {
+	val := field.Value()
+	if val == "" || rv.Regexp == nil {
+		return nil
+	}
+	rv.once.Do(func() {
+		// Both expressions are valid, therefore the anchored one is valid too.
+		rv.anchored = regexp.MustCompile(`^(?:` + rv.Regexp.String() + `)$`)
+	})
+	if rv.anchored.MatchString(val) {
+		return nil
+	}
+	msg := rv.Message
+	if msg == "" {
+		msg = f.sprintf(field, MsgFormat, field.Name(), val)
+	}
+	return CodedValidationError(CodeFormat, msg, map[string]any{"pattern": rv.Regexp.String()})
+}
+
+// Attributes returns HTML attributes.
+func (rv *Regexp) Attributes() []htmls.Attribute {
+	if rv.NoPattern {
+		return nil
+	}
+	pattern := rv.Pattern
+	if pattern == "" && rv.Regexp != nil {
+		pattern = rv.Regexp.String()
+	}
+	if pattern == "" {
+		return nil
+	}
+	return []htmls.Attribute{{Key: "pattern", Value: pattern}}
+}
+
 // ----- MinValue: field must have a minimum value.
 
 // MinValue is a validator that checks for a minimum value.
@@ -139,23 +271,33 @@ type MinValue struct {
 }
 
 // Check the given field w.r.t. to this validator.
-func (mv *MinValue) Check(_ *Form, field Field) error {
+func (mv *MinValue) Check(form *Form, field Field) error {
 	val := field.Value()
 	switch f := field.(type) {
 	case *InputElement:
 		switch f.itype {
-		case itypeNumber:
+		case itypeNumber, itypeRange:
 			fvalue, err := strconv.ParseFloat(val, 64)
 			if err != nil {
-				return ValidationError(fmt.Sprintf("%s does not contain a number: %v", field.Name(), val))
+				return CodedValidationError(CodeNotANumber, form.sprintf(field, MsgNotANumber, field.Name(), val),
+					map[string]any{"value": val})
 			}
 			mvalue, err := strconv.ParseFloat(mv.Value, 64)
 			if err == nil && fvalue < mvalue {
-				return ValidationError(fmt.Sprintf(
-					"minimum value of %s is %v, but got %v", field.Name(), mv.Value, val))
+				locale := form.Locale()
+				return CodedValidationError(CodeTooSmall, form.sprintf(field, MsgMinValue, field.Name(),
+					locale.FormatNumber(mvalue), locale.FormatNumber(fvalue)), map[string]any{"min": mv.Value, "value": val})
+			}
+		case itypeDate, itypeDatetime, itypeMonth, itypeTime:
+			fvalue, mvalue, err := parseTimeBound(form, f, val, mv.Value)
+			if err != nil || val == "" {
+				return err
+			}
+			if fvalue.Before(mvalue) {
+				locale := form.Locale()
+				return CodedValidationError(CodeTooSmall, form.sprintf(field, MsgMinValue, field.Name(),
+					locale.formatTime(f.itype, mvalue), locale.formatTime(f.itype, fvalue)), map[string]any{"min": mv.Value, "value": val})
 			}
-		case itypeDate: // TODO
-		case itypeDatetime: // TODO
 		}
 	}
 	return nil
@@ -174,23 +316,33 @@ type MaxValue struct {
 }
 
 // Check the given field w.r.t. to this validator.
-func (mv *MaxValue) Check(_ *Form, field Field) error {
+func (mv *MaxValue) Check(form *Form, field Field) error {
 	val := field.Value()
 	switch f := field.(type) {
 	case *InputElement:
 		switch f.itype {
-		case itypeNumber:
+		case itypeNumber, itypeRange:
 			fvalue, err := strconv.ParseFloat(val, 64)
 			if err != nil {
-				return ValidationError(fmt.Sprintf("%s does not contain a number: %v", field.Name(), val))
+				return CodedValidationError(CodeNotANumber, form.sprintf(field, MsgNotANumber, field.Name(), val),
+					map[string]any{"value": val})
 			}
 			mvalue, err := strconv.ParseFloat(mv.Value, 64)
 			if err == nil && fvalue > mvalue {
-				return ValidationError(fmt.Sprintf(
-					"minimum value of %s is %v, but got %v", field.Name(), mv.Value, val))
+				locale := form.Locale()
+				return CodedValidationError(CodeTooLarge, form.sprintf(field, MsgMaxValue, field.Name(),
+					locale.FormatNumber(mvalue), locale.FormatNumber(fvalue)), map[string]any{"max": mv.Value, "value": val})
+			}
+		case itypeDate, itypeDatetime, itypeMonth, itypeTime:
+			fvalue, mvalue, err := parseTimeBound(form, f, val, mv.Value)
+			if err != nil || val == "" {
+				return err
+			}
+			if fvalue.After(mvalue) {
+				locale := form.Locale()
+				return CodedValidationError(CodeTooLarge, form.sprintf(field, MsgMaxValue, field.Name(),
+					locale.formatTime(f.itype, mvalue), locale.formatTime(f.itype, fvalue)), map[string]any{"max": mv.Value, "value": val})
 			}
-		case itypeDate: // TODO
-		case itypeDatetime: // TODO
 		}
 	}
 	return nil
@@ -201,13 +353,33 @@ func (mv *MaxValue) Attributes() []htmls.Attribute {
 	return []htmls.Attribute{{Key: "max", Value: mv.Value}}
 }
 
+// parseTimeBound parses the value of a date, date/time, month, or time input
+// element, and the given bound. An invalid bound is an error of the
+// programmer and is returned as is. An invalid non-empty value results in a
+// validation error.
+func parseTimeBound(form *Form, fd *InputElement, value, bound string) (tvalue, tbound time.Time, err error) {
+	layout := fd.timeLayout()
+	if tbound, err = parseHTMLTime(layout, bound, time.UTC); err != nil {
+		return tvalue, tbound, fmt.Errorf("invalid bound %q for %s: %w", bound, fd.name, err)
+	}
+	if value == "" {
+		return tvalue, tbound, nil
+	}
+	if tvalue, err = parseHTMLTime(layout, value, time.UTC); err != nil {
+		return tvalue, tbound, CodedValidationError(CodeInvalidValue, form.sprintf(fd, MsgInvalidValue, fd.name, value),
+			map[string]any{"value": value})
+	}
+	return tvalue, tbound, nil
+}
+
 // ----- Int: field must have an integer value.
 
 // Int is a validator function that checks for an integer value.
-func Int(_ *Form, field Field) error {
+func Int(f *Form, field Field) error {
 	val := field.Value()
 	if _, err := strconv.Atoi(val); err != nil {
-		return ValidationError(fmt.Sprintf("%s does not contain an integer value: %v", field.Name(), val))
+		return CodedValidationError(CodeNotAnInteger, f.sprintf(field, MsgNotAnInteger, field.Name(), val),
+			map[string]any{"value": val})
 	}
 	return nil
 }
@@ -218,10 +390,11 @@ func IntValidator() Validator { return ValidatorFunc(Int) }
 // ----- UInt: field must have an unsigned integer value.
 
 // UInt is a validator function that checks for an unsigned integer value.
-func UInt(_ *Form, field Field) error {
+func UInt(f *Form, field Field) error {
 	val := field.Value()
 	if _, err := strconv.ParseUint(val, 10, 64); err != nil {
-		return ValidationError(fmt.Sprintf("%s does not contain an unsigned integer value: %v", field.Name(), val))
+		return CodedValidationError(CodeNotAnUnsigned, f.sprintf(field, MsgNotAnUnsigned, field.Name(), val),
+			map[string]any{"value": val})
 	}
 	return nil
 }
@@ -243,17 +416,31 @@ type setOf struct {
 	IsNone bool
 }
 
-func (so setOf) Check(_ *Form, field Field) error {
-	val := field.Value()
+func (so setOf) Check(f *Form, field Field) error {
+	if mf, isMulti := field.(MultiField); isMulti {
+		// Each selected value must be valid.
+		for _, val := range mf.Values() {
+			if err := so.checkValue(f, field, val); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return so.checkValue(f, field, field.Value())
+}
+
+func (so setOf) checkValue(f *Form, field Field, val string) error {
 	if so.Set.Contains(val) != so.IsNone {
 		return nil
 	}
 	if so.IsNone {
-		return ValidationError(fmt.Sprintf("%s contains an invalid value: %v", field.Name(), val))
+		return CodedValidationError(CodeInForbiddenSet, f.sprintf(field, MsgNoneOf, field.Name(), val),
+			map[string]any{"value": val})
 	}
 	validElements := slices.Collect(so.Set.Values())
 	slices.Sort(validElements)
-	return ValidationError(fmt.Sprintf("%s does not contain any valid input: %v (expected one of %v)", field.Name(), val, validElements))
+	return CodedValidationError(CodeNotInSet, f.sprintf(field, MsgAnyOf, field.Name(), val, validElements),
+		map[string]any{"value": val, "allowed": validElements})
 }
 
 // ----- StringXXX: field must have a value that compares to a specific constant.
@@ -291,46 +478,51 @@ type stringCompare struct {
 	message string
 }
 
-func (fsc *stringCompare) Check(_ *Form, field Field) error {
-	return compareStringValues(fsc.op, field.Value(), fsc.value, fsc.message)
+func (fsc *stringCompare) Check(f *Form, field Field) error {
+	return compareStringValues(f, field, fsc.op, field.Value(), fsc.value, fsc.message)
 }
 
-func compareStringValues(op int, value, other string, msg string) error {
+func compareStringValues(f *Form, field Field, op int, value, other string, msg string) error {
+	return checkComparison(f, field, op, strings.Compare(value, other), value, other, msg)
+}
+
+// checkComparison checks the result of comparing value with other, according
+// to the comparison operator.
+func checkComparison(f *Form, field Field, op int, c int, value, other string, msg string) error {
 	var msgOp string
 	switch op {
 	case -2:
-		if value < other {
+		if c < 0 {
 			return nil
 		}
 		msgOp = "≥"
 	case -1:
-		if value <= other {
+		if c <= 0 {
 			return nil
 		}
 		msgOp = ">"
 	case 0:
-		if value == other {
+		if c == 0 {
 			return nil
 		}
 		msgOp = "≠"
 	case 1:
-		if value >= other {
+		if c >= 0 {
 			return nil
 		}
 		msgOp = "<"
 	case 2:
-		if value > other {
+		if c > 0 {
 			return nil
 		}
 		msgOp = "≤"
 	default:
 		return fmt.Errorf("comparison value not expected: %d", op)
 	}
-	if msg != "" {
-		return ValidationError(msg)
+	if msg == "" {
+		msg = f.sprintf(field, MsgCompare, value, msgOp, other)
 	}
-	return ValidationError(fmt.Sprintf("%v %s %v", value, msgOp, other))
-
+	return CodedValidationError(CodeCompare, msg, map[string]any{"op": msgOp, "other": other})
 }
 
 // ----- FieldStringXXX: field must have a value that is compared to another field.
@@ -361,17 +553,89 @@ func FieldStringGreater(name string, msg string) Validator {
 }
 
 // fieldStringCompare validates that the current field by comparing with the given one.
-// Comparison is done via string comparison.
+// Comparison is done via string comparison, except if both fields are date
+// or both are date/time input elements with valid values. Then their time
+// values are compared.
 type fieldStringCompare struct {
 	fieldname string
 	op        int
 	message   string
 }
 
+func (fsc *fieldStringCompare) referencedFields() []string { return []string{fsc.fieldname} }
+
 func (fsc *fieldStringCompare) Check(f *Form, field Field) error {
 	other, err := f.Field(fsc.fieldname)
 	if err != nil {
 		return err
 	}
-	return compareStringValues(fsc.op, field.Value(), other.Value(), fsc.message)
+	if isPassword(field) || isPassword(other) {
+		// Passwords must not appear in messages.
+		msg := fsc.message
+		if msg == "" {
+			msg = f.sprintf(field, MsgPasswordMismatch)
+		}
+		return checkComparison(f, field, fsc.op, strings.Compare(field.Value(), other.Value()), "", "", msg)
+	}
+	if fd, isInput := field.(*InputElement); isInput {
+		if od, isOtherInput := other.(*InputElement); isOtherInput && fd.itype == od.itype && fd.timeLayout() != "" {
+			value, bound, errTime := parseTimeBound(f, fd, fd.value, od.value)
+			if errTime == nil && fd.value != "" {
+				locale := f.Locale()
+				return checkComparison(f, field, fsc.op, value.Compare(bound),
+					locale.formatTime(fd.itype, value), locale.formatTime(fd.itype, bound), fsc.message)
+			}
+		}
+	}
+	return compareStringValues(f, field, fsc.op, field.Value(), other.Value(), fsc.message)
+}
+
+// isPassword returns true, if the field is a password field.
+func isPassword(field Field) bool {
+	ie, isInput := field.(*InputElement)
+	return isInput && ie.itype == itypePassword
+}
+
+// ----- WarnIf: field value is suspicious, but not invalid.
+
+// WarnIf is a validator that emits a warning with the given message, if the
+// predicate is true for the field value.
+func WarnIf(pred func(value string) bool, msg string) Validator {
+	return ValidatorFunc(func(_ *Form, field Field) error {
+		if pred(field.Value()) {
+			return WarningError(msg)
+		}
+		return nil
+	})
+}
+
+// ----- EmailDomainTypo: field contains an e-mail address with a suspicious domain.
+
+// EmailDomainTypo is a validator that emits a warning, if the domain of an
+// e-mail address looks like a typo of a well-known domain.
+func EmailDomainTypo() Validator { return ValidatorFunc(checkEmailDomainTypo) }
+
+func checkEmailDomainTypo(f *Form, field Field) error {
+	_, domain, found := strings.Cut(field.Value(), "@")
+	if !found {
+		return nil
+	}
+	domain = strings.ToLower(domain)
+	if correct, isTypo := emailDomainTypos[domain]; isTypo {
+		return WarningError(f.sprintf(field, MsgEmailDomainTypo, domain, correct))
+	}
+	return nil
+}
+
+var emailDomainTypos = map[string]string{
+	"gamil.com":   "gmail.com",
+	"gmial.com":   "gmail.com",
+	"gmai.com":    "gmail.com",
+	"gmail.co":    "gmail.com",
+	"gnail.com":   "gmail.com",
+	"hotmial.com": "hotmail.com",
+	"hotmai.com":  "hotmail.com",
+	"outlok.com":  "outlook.com",
+	"yaho.com":    "yahoo.com",
+	"yahooo.com":  "yahoo.com",
 }
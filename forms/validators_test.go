@@ -14,6 +14,14 @@
 package forms_test
 
 import (
+	"errors"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
 	"testing"
 
 	"t73f.de/r/webs/forms"
@@ -60,3 +68,232 @@ func TestValidatorAnyNoneOf(t *testing.T) {
 		}
 	}
 }
+
+func TestWarnings(t *testing.T) {
+	newForm := func() *forms.Form {
+		return forms.Define(
+			forms.EmailField("email", "E-Mail", forms.Required{}, forms.EmailDomainTypo()),
+			forms.TextField("name", "Name",
+				forms.WarnIf(func(s string) bool { return strings.ToLower(s) == s }, "lower case only"),
+				&forms.MinMaxLength{MinLength: 3}),
+			forms.SubmitField("submit", "Save"),
+		)
+	}
+	submit := func(f *forms.Form, vals url.Values) forms.SubmitResult {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(vals.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		sr, _ := f.OnSubmit(r)
+		return sr
+	}
+
+	f := newForm()
+	if sr := submit(f, url.Values{"email": {"me@gamil.com"}, "name": {"detlef"}, "submit": {"Save"}}); sr != forms.SubmitValidData {
+		t.Errorf("submission with warnings must be valid, but got %v", sr)
+	}
+	if msgs := f.Messages(); len(msgs) != 0 {
+		t.Errorf("no error messages expected, but got %v", msgs)
+	}
+	expWarnings := forms.Messages{
+		"email": {"this e-mail domain looks like a typo: gamil.com (did you mean gmail.com?)"},
+		"name":  {"lower case only"},
+	}
+	if got := f.Warnings(); !maps.EqualFunc(expWarnings, got, slices.Equal) {
+		t.Errorf("expected warnings %v, but got %v", expWarnings, got)
+	}
+	exp := `<span class="message warning" id="name-msg-0">lower case only</span><input id="name"`
+	if got := renderForm(f); !strings.Contains(got, exp) {
+		t.Errorf("warning not rendered: %q", got)
+	}
+
+	f = newForm()
+	if sr := submit(f, url.Values{"email": {"me@example.org"}, "name": {"de"}, "submit": {"Save"}}); sr != forms.SubmitInvalidData {
+		t.Errorf("submission with errors and warnings must be invalid, but got %v", sr)
+	}
+	got := renderForm(f)
+	exp = `<span class="message error" id="name-msg-0">minimum length of name is 3, but got 2</span><span class="message warning" id="name-msg-1">lower case only</span>`
+	if !strings.Contains(got, exp) {
+		t.Errorf("messages not rendered: %q", got)
+	}
+	if strings.Contains(got, "did you mean") {
+		t.Errorf("unexpected e-mail warning: %q", got)
+	}
+
+	f.Clear()
+	if got := f.Warnings(); len(got) != 0 {
+		t.Errorf("warnings must be cleared, but got %v", got)
+	}
+}
+
+func TestValidatorRegexp(t *testing.T) {
+	re := regexp.MustCompile(`[A-Z]{2}-\d{4}|X`)
+	testcases := []struct {
+		value string
+		valid bool
+	}{
+		{"", true},
+		{"AB-1234", true},
+		{"X", true},
+		{"ab-1234", false},
+		{"AB-12345", false},
+		{"xAB-1234", false},
+		{"XX", false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.value, func(t *testing.T) {
+			fd := forms.TextField("code", "Code", &forms.Regexp{Regexp: re})
+			f := forms.Define(fd)
+			f.SetData(forms.Data{"code": tc.value})
+			if got := f.IsValid(); got != tc.valid {
+				t.Errorf("valid=%v expected, got %v (messages: %v)", tc.valid, got, f.Messages())
+			}
+		})
+	}
+
+	f := forms.Define(forms.TextField("code", "Code", &forms.Regexp{Regexp: re, Message: "wrong format"}))
+	f.SetData(forms.Data{"code": "wrong"})
+	if f.IsValid() || !slices.Equal(f.Messages()["code"], []string{"wrong format"}) {
+		t.Errorf("custom message expected, got %v", f.Messages())
+	}
+}
+
+func TestValidatorRegexpPattern(t *testing.T) {
+	re := regexp.MustCompile(`(?i)[a-z]+`)
+	testcases := []struct {
+		name string
+		val  *forms.Regexp
+		exp  string
+	}{
+		{"default", &forms.Regexp{Regexp: re}, ` pattern="(?i)[a-z]+"`},
+		{"override", &forms.Regexp{Regexp: re, Pattern: "[a-zA-Z]+"}, ` pattern="[a-zA-Z]+"`},
+		{"suppress", &forms.Regexp{Regexp: re, NoPattern: true}, ``},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := renderForm(forms.Define(forms.TextField("name", "", tc.val)))
+			exp := `<form action="" method="POST"><div><input id="name" name="name" type="text" value=""` + tc.exp + `></div></form>`
+			if got != exp {
+				t.Errorf("\nexpected: %q\nbut got:  %q", exp, got)
+			}
+		})
+	}
+}
+
+func TestValidatorMinMaxDate(t *testing.T) {
+	testcases := []struct {
+		name  string
+		field *forms.InputElement
+		value string
+		exp   []string
+	}{
+		{"date-empty", forms.DateField("d", "D", &forms.MinValue{Value: "2024-01-05"}), "", nil},
+		{"date-ok", forms.DateField("d", "D", &forms.MinValue{Value: "2024-01-05"}, &forms.MaxValue{Value: "2024-12-31"}), "2024-01-05", nil},
+		{"date-min", forms.DateField("d", "D", &forms.MinValue{Value: "2024-01-05"}), "2024-01-04",
+			[]string{"minimum value of d is 05.01.2024, but got 04.01.2024"}},
+		{"date-max", forms.DateField("d", "D", &forms.MaxValue{Value: "2024-12-31"}), "2025-01-01",
+			[]string{"maximum value of d is 31.12.2024, but got 01.01.2025"}},
+		{"datetime-ok", forms.DatetimeField("d", "D", &forms.MinValue{Value: "2024-01-05T10:00"}), "2024-01-05T10:00", nil},
+		{"datetime-min", forms.DatetimeField("d", "D", &forms.MinValue{Value: "2024-01-05T10:00"}), "2024-01-05T09:59",
+			[]string{"minimum value of d is 05.01.2024 10:00, but got 05.01.2024 09:59"}},
+		{"datetime-max", forms.DatetimeField("d", "D", &forms.MaxValue{Value: "2024-01-05T10:00"}), "2024-01-05T10:01",
+			[]string{"maximum value of d is 05.01.2024 10:00, but got 05.01.2024 10:01"}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := forms.Define(tc.field).SetLocale(forms.Locale{
+				DateLayout:     "02.01.2006",
+				DatetimeLayout: "02.01.2006 15:04",
+			})
+			if !f.SetData(forms.Data{"d": tc.value}) {
+				t.Fatalf("invalid value: %v", f.Messages())
+			}
+			valid := f.IsValid()
+			if got := f.Messages()["d"]; valid != (len(tc.exp) == 0) || !slices.Equal(got, tc.exp) {
+				t.Errorf("expected %q, but got %q", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestValidatorMinMaxInvalidBound(t *testing.T) {
+	f := forms.Define(forms.DateField("d", "D", &forms.MinValue{Value: "05.01.2024"}))
+	field, _ := f.Field("d")
+	_ = field.SetValue("2024-01-05")
+	err := (&forms.MinValue{Value: "05.01.2024"}).Check(f, field)
+	if err == nil {
+		t.Fatal("error expected")
+	}
+	if _, isValidation := err.(forms.ValidationError); isValidation {
+		t.Errorf("invalid bound must not be a validation error: %v", err)
+	}
+	if err = (&forms.MaxValue{Value: "2024-01-05T10:00"}).Check(f, field); err == nil {
+		t.Error("datetime bound for date field must be an error")
+	}
+}
+
+func TestValidatorFieldCompareDate(t *testing.T) {
+	f := forms.Define(
+		forms.DateField("from", "From"),
+		forms.DateField("to", "To", forms.FieldStringGreaterEqual("from", "")),
+	).SetLocale(forms.Locale{DateLayout: "02.01.2006"})
+	if f.SetData(forms.Data{"from": "2024-01-05", "to": "2024-01-04"}) && f.IsValid() {
+		t.Error("must not be valid")
+	}
+	if got, exp := f.Messages()["to"], []string{"04.01.2024 < 05.01.2024"}; !slices.Equal(got, exp) {
+		t.Errorf("expected %q, but got %q", exp, got)
+	}
+	if !f.SetData(forms.Data{"from": "2024-01-05", "to": "2024-01-05"}) || !f.IsValid() {
+		t.Errorf("must be valid, but got %v", f.Messages())
+	}
+}
+
+func TestValidatorRequiredIf(t *testing.T) {
+	mustLength := &forms.MinMaxLength{MinLength: 3}
+	f := forms.Define(
+		forms.SelectField("kind", "Kind", []string{"a", "A", "other", "Other"}),
+		forms.TextField("other", "Other", forms.RequiredIf("kind", "other", "please specify"), mustLength),
+		forms.MultiSelectField("tags", "Tags", []string{"x", "X", "y", "Y"}),
+		forms.TextField("reason", "Reason", forms.RequiredUnless("tags", "x", "")),
+	)
+	testcases := []struct {
+		data     forms.Data
+		messages forms.Messages
+	}{
+		{forms.Data{"kind": "a", "tags": "x"}, nil},
+		{forms.Data{"kind": "other", "tags": "y\nx"}, forms.Messages{"other": {"please specify"}}},
+		{forms.Data{"kind": "other", "other": "ab", "tags": "x"}, forms.Messages{"other": {"minimum length of other is 3, but got 2"}}},
+		{forms.Data{"kind": "a", "other": "ab", "tags": "x"}, forms.Messages{"other": {"minimum length of other is 3, but got 2"}}},
+		{forms.Data{"kind": "other", "other": "abc"}, forms.Messages{"reason": {"Required"}}},
+		{forms.Data{"tags": "y", "reason": "why"}, nil},
+	}
+	for i, tc := range testcases {
+		f.Clear()
+		f.SetData(tc.data)
+		if f.IsValid() != (tc.messages == nil) {
+			t.Errorf("%d: validity must be %v, got %v", i, tc.messages == nil, f.Messages())
+		}
+		if got := f.Messages(); !maps.EqualFunc(got, tc.messages, slices.Equal) {
+			t.Errorf("%d: messages %v expected, got %v", i, tc.messages, got)
+		}
+	}
+	if got := renderForm(f); strings.Contains(got, "required") || strings.Contains(got, "*") {
+		t.Errorf("conditional requirement must not be rendered: %s", got)
+	}
+}
+
+func TestJoinedValidationErrors(t *testing.T) {
+	t.Parallel()
+	joined := forms.ValidatorFunc(func(*forms.Form, forms.Field) error {
+		return errors.Join(forms.ValidationError("e1"), forms.WarningError("w1"), forms.StopValidationError("e2"))
+	})
+	never := forms.ValidatorFunc(func(*forms.Form, forms.Field) error { return forms.ValidationError("never") })
+	form := forms.Define(forms.TextField("name", "Name", joined, never))
+	if form.IsValid() {
+		t.Error("form must be invalid")
+	}
+	if got, exp := form.Messages()["name"], []string{"e1", "e2"}; !slices.Equal(got, exp) {
+		t.Errorf("messages %q expected, got %q", exp, got)
+	}
+	if got, exp := form.Warnings()["name"], []string{"w1"}; !slices.Equal(got, exp) {
+		t.Errorf("warnings %q expected, got %q", exp, got)
+	}
+}
@@ -15,7 +15,9 @@
 package forms
 
 import (
+	"maps"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -23,8 +25,24 @@ import (
 const (
 	htmlDateLayout     = "2006-01-02"
 	htmlDatetimeLayout = "2006-01-02T15:04"
+	htmlMonthLayout    = "2006-01"
+	htmlTimeLayout     = "15:04"
 )
 
+// parseHTMLTime parses a time value of a HTML form with the given layout.
+// Browsers add seconds to time and date/time values, if the step of the
+// input element is less than a minute. Therefore, these values are accepted
+// too.
+func parseHTMLTime(layout, value string, loc *time.Location) (time.Time, error) {
+	result, err := time.ParseInLocation(layout, value, loc)
+	if err != nil && strings.HasSuffix(layout, "15:04") {
+		if withSeconds, err2 := time.ParseInLocation(layout+":05", value, loc); err2 == nil {
+			return withSeconds, nil
+		}
+	}
+	return result, err
+}
+
 // DateValue returns the date as a string suitable for a HTML date field value.
 func DateValue(t time.Time) string {
 	if t.Equal(time.Time{}) {
@@ -41,6 +59,22 @@ func DatetimeValue(t time.Time) string {
 	return t.Format(htmlDatetimeLayout)
 }
 
+// TimeValue returns the time of day as a string suitable for a HTML time field value.
+func TimeValue(t time.Time) string {
+	if t.Equal(time.Time{}) {
+		return ""
+	}
+	return t.Format(htmlTimeLayout)
+}
+
+// MonthValue returns the month as a string suitable for a HTML month field value.
+func MonthValue(t time.Time) string {
+	if t.Equal(time.Time{}) {
+		return ""
+	}
+	return t.Format(htmlMonthLayout)
+}
+
 // IntValue returns the value as a string to be stored in a field.
 func IntValue(i int) string { return strconv.Itoa(i) }
 
@@ -87,7 +121,33 @@ func (d Data) GetDate(fieldName string) time.Time {
 func (d Data) GetDatetime(fieldName string) time.Time {
 	if len(d) > 0 {
 		if value, found := d[fieldName]; found {
-			if result, err := time.ParseInLocation(htmlDatetimeLayout, value, time.Local); err == nil {
+			if result, err := parseHTMLTime(htmlDatetimeLayout, value, time.Local); err == nil {
+				return result
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// GetTime returns the value of the given field as a time.Time, where only
+// the time of day is relevant.
+func (d Data) GetTime(fieldName string) time.Time {
+	if len(d) > 0 {
+		if value, found := d[fieldName]; found {
+			if result, err := parseHTMLTime(htmlTimeLayout, value, time.UTC); err == nil {
+				return result
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// GetMonth returns the value of the given field as a time.Time, with the
+// first day of the month.
+func (d Data) GetMonth(fieldName string) time.Time {
+	if len(d) > 0 {
+		if value, found := d[fieldName]; found {
+			if result, err := time.Parse(htmlMonthLayout, value); err == nil {
 				return result
 			}
 		}
@@ -130,3 +190,85 @@ func (d Data) GetFloat(fieldName string, defaultValue float64) float64 {
 	}
 	return defaultValue
 }
+
+// Diff returns the data of all fields, whose values differ between d and
+// other. The values are taken from other. A field that is missing in other
+// has the empty string as its value.
+func (d Data) Diff(other Data) Data {
+	var result Data
+	for name, value := range d {
+		if otherValue, found := other[name]; !found || otherValue != value {
+			result = addData(result, name, otherValue)
+		}
+	}
+	for name, value := range other {
+		if _, found := d[name]; !found {
+			result = addData(result, name, value)
+		}
+	}
+	return result
+}
+
+func addData(d Data, name, value string) Data {
+	if d == nil {
+		return Data{name: value}
+	}
+	d[name] = value
+	return d
+}
+
+// Merge returns a copy of d, updated by the values of all present fields.
+// Present fields with an empty value are removed, all absent fields retain
+// their value.
+func (d Data) Merge(patch PresentData) Data {
+	result := maps.Clone(d)
+	for name, value := range patch {
+		if value == "" {
+			delete(result, name)
+			continue
+		}
+		if result == nil {
+			result = Data{}
+		}
+		result[name] = value
+	}
+	return result
+}
+
+// MultiData contains all form data, as a map of field names to all field
+// values, see [Form.MultiData].
+type MultiData map[string][]string
+
+// Get all values of a field. Return nil for unknown field.
+func (md MultiData) Get(fieldName string) []string {
+	if len(md) == 0 {
+		return nil
+	}
+	return md[fieldName]
+}
+
+// PresentData contains the data of all fields that were present in a form
+// submission, as a map of field names to field values. In contrast to
+// [Data], the value of a present field may be the empty string, e.g. for an
+// unchecked checkbox. Fields that were not submitted at all are absent.
+type PresentData map[string]string
+
+// Lookup returns the value of the given field and whether it was present.
+func (pd PresentData) Lookup(fieldName string) (string, bool) {
+	value, found := pd[fieldName]
+	return value, found
+}
+
+// Data returns the data of all present fields with a non-empty value.
+func (pd PresentData) Data() Data {
+	if len(pd) == 0 {
+		return nil
+	}
+	result := make(Data, len(pd))
+	for name, value := range pd {
+		if value != "" {
+			result[name] = value
+		}
+	}
+	return result
+}
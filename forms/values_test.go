@@ -0,0 +1,161 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package forms_test
+
+import (
+	"maps"
+	"net/url"
+	"testing"
+	"time"
+
+	"t73f.de/r/webs/forms"
+)
+
+func definePresenceForm() *forms.Form {
+	return forms.Define(
+		forms.TextField("name", "Name"),
+		forms.TextField("city", "City"),
+		forms.CheckboxField("news", "Newsletter"),
+		forms.TextField("locked", "Locked"),
+		forms.SubmitField("save", "Save"),
+	).DisableFields("locked")
+}
+
+func TestPresentData(t *testing.T) {
+	testcases := []struct {
+		name string
+		vals url.Values
+		exp  forms.PresentData
+	}{
+		{"no-values", nil, nil},
+		{"unchecked-checkbox",
+			url.Values{"name": {"me"}, "city": {""}, "save": {"Save"}},
+			forms.PresentData{"name": "me", "city": "", "news": "", "save": "Save"}},
+		{"checked-checkbox",
+			url.Values{"name": {"me"}, "city": {""}, "news": {"news"}, "save": {"Save"}},
+			forms.PresentData{"name": "me", "city": "", "news": "news", "save": "Save"}},
+		{"disabled-field",
+			url.Values{"name": {"me"}, "locked": {"sneaky"}, "save": {"Save"}},
+			forms.PresentData{"name": "me", "news": "", "save": "Save"}},
+		{"partial-js",
+			url.Values{"city": {""}},
+			forms.PresentData{"city": ""}},
+		{"unknown-field",
+			url.Values{"other": {"x"}, "name": {"me"}},
+			forms.PresentData{"name": "me"}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := definePresenceForm()
+			f.SetFormValues(tc.vals, nil)
+			got := f.PresentData()
+			if !maps.Equal(got, tc.exp) {
+				t.Errorf("expected %v, but got %v", tc.exp, got)
+			}
+			if (got == nil) != (tc.exp == nil) {
+				t.Errorf("expected nil-ness %v, but got %v", tc.exp == nil, got == nil)
+			}
+		})
+	}
+
+	f := definePresenceForm()
+	f.SetFormValues(url.Values{"city": {"x"}}, nil)
+	f.Clear()
+	if got := f.PresentData(); got != nil {
+		t.Errorf("no present data expected after Clear, but got %v", got)
+	}
+}
+
+func TestDataDiff(t *testing.T) {
+	old := forms.Data{"a": "1", "b": "2", "c": "3"}
+	testcases := []struct {
+		name  string
+		other forms.Data
+		exp   forms.Data
+	}{
+		{"same", forms.Data{"a": "1", "b": "2", "c": "3"}, nil},
+		{"changed", forms.Data{"a": "1", "b": "x", "c": "3"}, forms.Data{"b": "x"}},
+		{"removed", forms.Data{"a": "1", "c": "3"}, forms.Data{"b": ""}},
+		{"added", forms.Data{"a": "1", "b": "2", "c": "3", "d": "4"}, forms.Data{"d": "4"}},
+		{"nil", nil, forms.Data{"a": "", "b": "", "c": ""}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := old.Diff(tc.other); !maps.Equal(got, tc.exp) {
+				t.Errorf("expected %v, but got %v", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestDataMerge(t *testing.T) {
+	old := forms.Data{"name": "me", "city": "here", "news": "news"}
+
+	// A partial form posted via JS changes only the given fields.
+	f := definePresenceForm()
+	f.SetFormValues(url.Values{"city": {"there"}}, nil)
+	got := old.Merge(f.PresentData())
+	exp := forms.Data{"name": "me", "city": "there", "news": "news"}
+	if !maps.Equal(got, exp) {
+		t.Errorf("expected %v, but got %v", exp, got)
+	}
+	if old["city"] != "here" {
+		t.Error("Merge must not change the original data")
+	}
+
+	// A full form with an unchecked checkbox removes its value.
+	f.SetFormValues(url.Values{"name": {"me"}, "city": {"here"}, "save": {"Save"}}, nil)
+	got = old.Merge(f.PresentData())
+	exp = forms.Data{"name": "me", "city": "here", "save": "Save"}
+	if !maps.Equal(got, exp) {
+		t.Errorf("expected %v, but got %v", exp, got)
+	}
+	if diff := old.Diff(got); !maps.Equal(diff, forms.Data{"news": "", "save": "Save"}) {
+		t.Errorf("unexpected diff %v", diff)
+	}
+}
+
+func TestGetTimeMonth(t *testing.T) {
+	data := forms.Data{"t": "15:04", "m": "2024-02", "bad": "x"}
+	if got := data.GetTime("t"); got.Hour() != 15 || got.Minute() != 4 {
+		t.Errorf("15:04 expected, got %v", got)
+	}
+	if got := forms.TimeValue(data.GetTime("t")); got != "15:04" {
+		t.Errorf("15:04 expected, got %q", got)
+	}
+	if got := data.GetMonth("m"); got.Year() != 2024 || got.Month() != time.February || got.Day() != 1 {
+		t.Errorf("2024-02-01 expected, got %v", got)
+	}
+	if got := forms.MonthValue(data.GetMonth("m")); got != "2024-02" {
+		t.Errorf("2024-02 expected, got %q", got)
+	}
+	data["s"] = "15:04:05"
+	if got := data.GetTime("s"); got.Hour() != 15 || got.Minute() != 4 || got.Second() != 5 {
+		t.Errorf("15:04:05 expected, got %v", got)
+	}
+	for _, name := range []string{"bad", "missing"} {
+		if got := data.GetTime(name); !got.IsZero() {
+			t.Errorf("%s: zero time expected, got %v", name, got)
+		}
+		if got := data.GetMonth(name); !got.IsZero() {
+			t.Errorf("%s: zero month expected, got %v", name, got)
+		}
+	}
+	if got := forms.TimeValue(time.Time{}); got != "" {
+		t.Errorf("empty value expected, got %q", got)
+	}
+	if got := forms.MonthValue(time.Time{}); got != "" {
+		t.Errorf("empty value expected, got %q", got)
+	}
+}
@@ -0,0 +1,127 @@
+// -----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+// -----------------------------------------------------------------------------
+
+package forms
+
+import (
+	"context"
+
+	"t73f.de/r/webs/htmls"
+)
+
+// VersionElement represents the version of the record that is edited by a
+// form, e.g. a timestamp or a counter. It is rendered as a hidden input and
+// allows to detect conflicting edits, see [Form.CheckVersion].
+type VersionElement struct {
+	name     string
+	current  string
+	value    string
+	disabled bool
+}
+
+// VersionField builds a new version field with the current version of the
+// record.
+func VersionField(name, currentVersion string) *VersionElement {
+	return &VersionElement{name: name, current: currentVersion, value: currentVersion}
+}
+
+// Name returns the name of this element.
+func (ve *VersionElement) Name() string { return ve.name }
+
+// Value returns the version, either the current one or the submitted one.
+func (ve *VersionElement) Value() string { return ve.value }
+
+// Clear the element: the version is reset to the current version.
+func (ve *VersionElement) Clear() { ve.value = ve.current }
+
+// SetValue sets the submitted version.
+func (ve *VersionElement) SetValue(value string) error { ve.value = value; return nil }
+
+// SetCurrentVersion updates the current version of the record, e.g. after
+// it was stored.
+func (ve *VersionElement) SetCurrentVersion(version string) {
+	ve.current = version
+	ve.value = version
+}
+
+// Validators returns no validators: the version is checked by
+// [Form.CheckVersion].
+func (*VersionElement) Validators() Validators { return nil }
+
+// Disable the element. A disabled version is not checked.
+func (ve *VersionElement) Disable() { ve.disabled = true }
+
+func (ve *VersionElement) isDisabled() bool { return ve.disabled }
+
+// Render the version element as a hidden input.
+func (ve *VersionElement) Render(fieldID string, _ []string) *htmls.Node {
+	attrs := makeAttributes(4, nil, ve.disabled)
+	attrs = append(attrs,
+		htmls.Attribute{Key: "id", Value: fieldID},
+		htmls.Attribute{Key: "name", Value: ve.name},
+		htmls.Attribute{Key: "type", Value: "hidden"},
+		htmls.Attribute{Key: "value", Value: ve.value},
+	)
+	attrs = addEnablingAttributes(attrs, ve.disabled, nil)
+	return htmls.Elem("input", attrs)
+}
+
+// ConflictMessage is the form-level message, if [Form.OnSubmit] detects a
+// conflicting edit.
+const ConflictMessage = "the record was changed by someone else in the meantime; please review your changes and submit again"
+
+// CheckVersion lets [Form.OnSubmit] compare the submitted version of the
+// form's [VersionElement] with the current version of the record, as
+// returned by loadCurrent. If they differ, OnSubmit returns
+// [SubmitConflict] and adds [ConflictMessage] as a form-level message.
+//
+// The submitted values of all other fields are left intact, so that the
+// form can be rendered again, together with the current record. The version
+// field is updated to the current version, so that a second submit
+// overwrites the record.
+func (f *Form) CheckVersion(loadCurrent func(ctx context.Context) (string, error)) *Form {
+	f.loadVersion = loadCurrent
+	return f
+}
+
+// checkVersion compares the submitted version with the current one. It
+// returns true, if they differ. An error is returned, if the current version
+// could not be loaded.
+func (f *Form) checkVersion(ctx context.Context) (conflict bool, err error) {
+	if f.loadVersion == nil {
+		return false, nil
+	}
+	ve := f.versionElement()
+	if ve == nil {
+		return false, nil
+	}
+	current, err := f.loadVersion(ctx)
+	if err != nil {
+		return false, err
+	}
+	if ve.value == current {
+		return false, nil
+	}
+	ve.SetCurrentVersion(current)
+	return true, nil
+}
+
+// versionElement returns the enabled version field of the form, or nil.
+func (f *Form) versionElement() *VersionElement {
+	for _, field := range f.fieldnames {
+		if ve, isVersion := field.(*VersionElement); isVersion && !ve.disabled {
+			return ve
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+// -----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of sxwebs.
+//
+// sxwebs is licensed under the latest version of the EUPL // (European Union
+// Public License). Please see file LICENSE.txt for your rights and obligations
+// under this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+// -----------------------------------------------------------------------------
+
+package forms_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"t73f.de/r/webs/forms"
+)
+
+// record is a simple versioned record, shared by all editors.
+type record struct {
+	version int
+	title   string
+	fail    bool
+}
+
+func (rec *record) currentVersion(context.Context) (string, error) {
+	if rec.fail {
+		return "", errors.New("store not available")
+	}
+	return strconv.Itoa(rec.version), nil
+}
+
+// editForm builds the form of one editor, who loads the record.
+func editForm(rec *record) *forms.Form {
+	f := forms.Define(
+		forms.VersionField("version", strconv.Itoa(rec.version)),
+		forms.TextField("title", "Title", forms.Required{"title"}),
+		forms.SubmitField("save", "Save"),
+	).CheckVersion(rec.currentVersion)
+	f.SetData(forms.Data{"title": rec.title})
+	return f
+}
+
+// postForm simulates submitting the rendered form with a new title.
+func postForm(t *testing.T, f *forms.Form, title string) forms.SubmitResult {
+	t.Helper()
+	html := renderForm(f)
+	_, after, found := strings.Cut(html, `name="version" type="hidden" value="`)
+	if !found {
+		t.Fatalf("no hidden version field in %s", html)
+	}
+	version, _, _ := strings.Cut(after, `"`)
+	vals := url.Values{"version": {version}, "title": {title}, "save": {"Save"}}
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(vals.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	sr, _ := f.OnSubmit(r)
+	return sr
+}
+
+func TestVersionConflict(t *testing.T) {
+	rec := &record{version: 1, title: "original"}
+
+	// Two editors load the same record.
+	formA := editForm(rec)
+	formB := editForm(rec)
+
+	// A saves first.
+	if sr := postForm(t, formA, "edited by A"); sr != forms.SubmitValidData {
+		t.Fatalf("A: SubmitValidData expected, got %v", sr)
+	}
+	rec.title, rec.version = formA.Data()["title"], rec.version+1
+
+	// B saves later, based on the old version.
+	if sr := postForm(t, formB, "edited by B"); sr != forms.SubmitConflict {
+		t.Fatalf("B: SubmitConflict expected, got %v", sr)
+	}
+	if got := formB.Messages()[""]; len(got) != 1 || got[0] != forms.ConflictMessage {
+		t.Errorf("conflict message expected, got %v", formB.Messages())
+	}
+	if got := formB.Data()["title"]; got != "edited by B" {
+		t.Errorf("submitted value of B must be kept, got %q", got)
+	}
+	if got := formB.Data()["version"]; got != "2" {
+		t.Errorf("version field must be updated to current version, got %q", got)
+	}
+
+	// B reviewed the changes and submits again.
+	if sr := postForm(t, formB, "edited by B"); sr != forms.SubmitValidData {
+		t.Fatalf("B: SubmitValidData expected after review, got %v", sr)
+	}
+}
+
+func TestVersionConflictInvalid(t *testing.T) {
+	rec := &record{version: 1, title: "original"}
+	f := editForm(rec)
+	rec.version = 2
+	if sr := postForm(t, f, ""); sr != forms.SubmitConflict {
+		t.Fatalf("SubmitConflict expected, got %v", sr)
+	}
+	if msgs := f.Messages(); len(msgs["title"]) != 1 || len(msgs[""]) != 1 {
+		t.Errorf("validation and conflict messages expected, got %v", msgs)
+	}
+}
+
+func TestVersionLoadError(t *testing.T) {
+	rec := &record{version: 1, title: "original"}
+	f := editForm(rec)
+	rec.fail = true
+	if sr := postForm(t, f, "new"); sr != forms.SubmitInvalidData {
+		t.Fatalf("SubmitInvalidData expected, got %v", sr)
+	}
+	if got := f.Messages()[""]; len(got) != 1 || got[0] != "store not available" {
+		t.Errorf("load error message expected, got %v", f.Messages())
+	}
+}
+
+func TestVersionWithoutCheck(t *testing.T) {
+	f := forms.Define(
+		forms.VersionField("version", "7"),
+		forms.TextField("title", "Title"),
+		forms.SubmitField("save", "Save"),
+	)
+	if sr := postForm(t, f, "new"); sr != forms.SubmitValidData {
+		t.Fatalf("SubmitValidData expected without version check, got %v", sr)
+	}
+	f.Clear()
+	if got := f.Data()["version"]; got != "7" {
+		t.Errorf("Clear must reset to current version, got %q", got)
+	}
+}
@@ -0,0 +1,86 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package htmls
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/binary"
+	"slices"
+	"strings"
+)
+
+// canonicalVersion is the first byte of every canonical serialization. It
+// changes only with a new major version of this module.
+const canonicalVersion = 1
+
+// CanonicalBytes returns a canonical serialization of the node tree, which
+// is the input of [Fingerprint]. It is exposed for debugging; it is not
+// meant to be parsed.
+//
+// The tree is normalized by [Compact] with [CompactOptions.Conservative]
+// set, but no element is removed. Tag names and attribute keys are lower
+// cased, attributes are sorted by key, then by value. Every string is
+// prefixed by its length, every list by its number of entries, so that
+// adjacent fields cannot be confused.
+//
+// The serialization is deterministic across processes and platforms. It is
+// stable within a major version of this module.
+func CanonicalBytes(node *Node) []byte {
+	node = Compact(node, CompactOptions{Removable: []string{}, Conservative: true})
+	return appendCanonical([]byte{canonicalVersion}, node)
+}
+
+// Fingerprint returns the SHA-256 hash of the canonical serialization of
+// the node tree, see [CanonicalBytes]. Trees that differ only in the order
+// of attributes or in insignificant whitespace have the same fingerprint.
+// It can be used as a cache key or to compute an ETag.
+func Fingerprint(node *Node) [32]byte {
+	return sha256.Sum256(CanonicalBytes(node))
+}
+
+func appendCanonical(buf []byte, node *Node) []byte {
+	if node == nil {
+		return append(buf, 0)
+	}
+	buf = append(buf, byte(node.Type))
+	if node.Type != ElementNode {
+		return appendString(buf, node.Data)
+	}
+	buf = appendString(buf, strings.ToLower(node.Data))
+
+	attrs := make([]Attribute, len(node.Attributes))
+	for i, attr := range node.Attributes {
+		attrs[i] = Attribute{Key: strings.ToLower(attr.Key), Value: attr.Value}
+	}
+	slices.SortFunc(attrs, func(a, b Attribute) int {
+		return cmp.Or(strings.Compare(a.Key, b.Key), strings.Compare(a.Value, b.Value))
+	})
+	buf = binary.AppendUvarint(buf, uint64(len(attrs)))
+	for _, attr := range attrs {
+		buf = appendString(buf, attr.Key)
+		buf = appendString(buf, attr.Value)
+	}
+
+	buf = binary.AppendUvarint(buf, uint64(len(node.Children)))
+	for _, child := range node.Children {
+		buf = appendCanonical(buf, child)
+	}
+	return buf
+}
+
+func appendString(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
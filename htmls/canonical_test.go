@@ -0,0 +1,153 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package htmls_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"t73f.de/r/webs/htmls"
+	"t73f.de/r/webs/htmls/render"
+)
+
+func TestFingerprintEqual(t *testing.T) {
+	testcases := []struct {
+		name string
+		a, b *htmls.Node
+	}{
+		{"attribute order",
+			htmls.Elem("a", htmls.Attrs("href", "/x", "class", "link", "id", "x")),
+			htmls.Elem("a", htmls.Attrs("id", "x", "href", "/x", "class", "link"))},
+		{"case",
+			htmls.Elem("DIV", htmls.Attrs("CLASS", "c")),
+			htmls.Elem("div", htmls.Attrs("class", "c"))},
+		{"adjacent text",
+			htmls.Elem("p", nil, htmls.Text("Hello, "), htmls.Text("World")),
+			htmls.Elem("p", nil, htmls.Text("Hello, World"))},
+		{"whitespace",
+			htmls.Elem("ul", nil, htmls.Text("\n  "), htmls.Elem("li", nil, htmls.Text("x")), htmls.Text("\n")),
+			htmls.Elem("ul", nil, htmls.Text(" "), htmls.Elem("li", nil, htmls.Text("x")), htmls.Text(" "))},
+		{"split text",
+			htmls.Elem("p", nil, htmls.Text("a"), htmls.Text(" "), htmls.Elem("b", nil)),
+			htmls.Elem("p", nil, htmls.Text("a "), htmls.Elem("b", nil))},
+		{"empty text",
+			htmls.Elem("p", nil, htmls.Text(""), htmls.Text("x")),
+			htmls.Elem("p", nil, htmls.Text("x"))},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if !bytes.Equal(htmls.CanonicalBytes(tc.a), htmls.CanonicalBytes(tc.b)) {
+				t.Errorf("equal canonical bytes expected:\n%q\n%q", htmls.CanonicalBytes(tc.a), htmls.CanonicalBytes(tc.b))
+			}
+			if htmls.Fingerprint(tc.a) != htmls.Fingerprint(tc.b) {
+				t.Error("equal fingerprints expected")
+			}
+		})
+	}
+}
+
+func TestFingerprintDifferent(t *testing.T) {
+	nodes := []*htmls.Node{
+		nil,
+		htmls.Text("x"),
+		htmls.Raw("x"),
+		{Type: htmls.CommentNode, Data: "x"},
+		htmls.Elem("x", nil),
+		htmls.Elem("p", nil),
+		htmls.Elem("div", nil),
+		htmls.Elem("p", htmls.Attrs("a", "bc")),
+		htmls.Elem("p", htmls.Attrs("ab", "c")),
+		htmls.Elem("p", htmls.Attrs("a", "b", "c", "")),
+		htmls.Elem("p", htmls.Attrs("a", "b"), htmls.Text("c")),
+		htmls.Elem("p", nil, htmls.Text("abc")),
+		htmls.Elem("p", nil, htmls.Text("ab"), htmls.Elem("b", nil, htmls.Text("c"))),
+		htmls.Elem("p", nil, htmls.Elem("b", nil, htmls.Text("ab")), htmls.Text("c")),
+		htmls.Elem("p", nil, htmls.Elem("b", nil, htmls.Text("ab"), htmls.Text(" c"))),
+		htmls.Elem("p", nil, htmls.Text("a "), htmls.Elem("b", nil)),
+		htmls.Elem("pre", nil, htmls.Text("  x")),
+		htmls.Elem("pre", nil, htmls.Text(" x")),
+	}
+	seen := map[[32]byte]int{}
+	for i, node := range nodes {
+		fp := htmls.Fingerprint(node)
+		if j, found := seen[fp]; found {
+			t.Errorf("nodes %d and %d have the same fingerprint: %q", j, i, htmls.CanonicalBytes(node))
+		}
+		seen[fp] = i
+	}
+}
+
+func TestFingerprintUnchanged(t *testing.T) {
+	node := htmls.Elem("p", htmls.Attrs("b", "2", "a", "1"), htmls.Text("x"), htmls.Text("y"))
+	_ = htmls.Fingerprint(node)
+	if node.Attributes[0].Key != "b" || len(node.Children) != 2 {
+		t.Error("node tree must not be modified")
+	}
+}
+
+func TestCanonicalBytesStable(t *testing.T) {
+	// The canonical serialization must not change within a major version.
+	node := htmls.Elem("a", htmls.Attrs("href", "/", "class", "c"), htmls.Text("Home"))
+	exp := "\x01\x02\x01a\x02\x05class\x01c\x04href\x01/\x01\x01\x04Home"
+	if got := string(htmls.CanonicalBytes(node)); got != exp {
+		t.Errorf("\nexpected %q\nbut got  %q", exp, got)
+	}
+}
+
+// semanticHTML renders the normalized tree, to detect true collisions.
+func semanticHTML(node *htmls.Node) string {
+	var sb strings.Builder
+	node = htmls.Compact(node, htmls.CompactOptions{Removable: []string{}, Conservative: true})
+	if err := render.Render(&sb, node); err != nil {
+		return "error: " + err.Error()
+	}
+	return sb.String()
+}
+
+func FuzzFingerprint(f *testing.F) {
+	f.Add("a", "bc")
+	f.Add("ab", "c")
+	f.Add("", "")
+	f.Add(" ", "\n")
+	f.Add("p", "<b>")
+	f.Add("\x00\x01", "\x02")
+	f.Fuzz(func(t *testing.T, a, b string) {
+		variants := []*htmls.Node{
+			htmls.Text(a + b),
+			htmls.Raw(a + b),
+			{Type: htmls.CommentNode, Data: a + b},
+			htmls.Elem("p", htmls.Attrs(a, b)),
+			htmls.Elem("p", htmls.Attrs(a+b, "")),
+			htmls.Elem("p", htmls.Attrs(b, a)),
+			htmls.Elem("p", htmls.Attrs("k", a, "l", b)),
+			htmls.Elem("p", htmls.Attrs("k", a+b)),
+			htmls.Elem("p", nil, htmls.Text(a), htmls.Elem("b", nil, htmls.Text(b))),
+			htmls.Elem("p", nil, htmls.Elem("b", nil, htmls.Text(a)), htmls.Text(b)),
+			htmls.Elem("p", nil, htmls.Text(a), htmls.Raw(b)),
+			htmls.Elem(a, nil, htmls.Text(b)),
+			htmls.Elem(a, htmls.Attrs(b, "")),
+			htmls.Elem("pre", nil, htmls.Text(a+b)),
+		}
+		seen := map[[32]byte]int{}
+		for i, node := range variants {
+			fp := htmls.Fingerprint(node)
+			if j, found := seen[fp]; found && semanticHTML(variants[j]) != semanticHTML(node) {
+				t.Errorf("variants %d and %d collide: %q / %q",
+					j, i, htmls.CanonicalBytes(variants[j]), htmls.CanonicalBytes(node))
+			}
+			seen[fp] = i
+		}
+	})
+}
@@ -0,0 +1,151 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package htmls
+
+import (
+	"slices"
+	"strings"
+
+	"t73f.de/r/webs/htmls/tags"
+)
+
+// CompactOptions control the behaviour of [Compact].
+type CompactOptions struct {
+	// Removable lists the tags of element nodes that are dropped, if they
+	// have neither attributes nor children. If nil, [DefaultRemovable] is
+	// used. Use an empty, non-nil slice to keep all element nodes.
+	Removable []string
+
+	// InPlace signals to modify the given tree. Otherwise a modified copy is
+	// returned and the given tree is left unchanged.
+	InPlace bool
+
+	// Conservative signals to replace whitespace-only text nodes by a single
+	// space instead of removing them. This keeps inline words separated.
+	Conservative bool
+}
+
+// DefaultRemovable lists the tags of element nodes that are removed by
+// [Compact], if no other tags are specified.
+var DefaultRemovable = []string{"span", "div"}
+
+// Compact normalizes the given node tree: adjacent text nodes are merged,
+// empty or whitespace-only text nodes are removed, and empty element nodes
+// with removable tags are dropped. Whitespace within whitespace sensitive
+// elements, like "pre", is not changed. Comment nodes and raw nodes are never
+// modified.
+//
+// The resulting node is nil, if the given node itself was removed.
+func Compact(node *Node, opts CompactOptions) *Node {
+	if node == nil {
+		return nil
+	}
+	removable := opts.Removable
+	if removable == nil {
+		removable = DefaultRemovable
+	}
+	c := compacter{removable: removable, opts: opts}
+	if !opts.InPlace {
+		node = cloneNode(node)
+	}
+	return c.compact(node, false)
+}
+
+type compacter struct {
+	removable []string
+	opts      CompactOptions
+}
+
+func (c *compacter) compact(node *Node, keepSpace bool) *Node {
+	switch node.Type {
+	case TextNode:
+		return c.compactText(node, keepSpace)
+	case ElementNode:
+		// no-op, fall through
+	default:
+		return node
+	}
+
+	keepSpace = keepSpace || tags.IsWhitespaceSensitive(node.Data)
+	children := node.Children[:0]
+	for _, child := range node.Children {
+		if child == nil {
+			continue
+		}
+		if child.Type != TextNode {
+			if child = c.compact(child, keepSpace); child == nil {
+				continue
+			}
+		}
+		if n := len(children); n > 0 && child.Type == TextNode && children[n-1].Type == TextNode {
+			children[n-1].Data += child.Data
+			continue
+		}
+		children = append(children, child)
+	}
+	clear(node.Children[len(children):])
+
+	result := children[:0]
+	for _, child := range children {
+		if child.Type == TextNode {
+			if child = c.compactText(child, keepSpace); child == nil {
+				continue
+			}
+		}
+		result = append(result, child)
+	}
+	clear(children[len(result):])
+	if len(result) == 0 {
+		result = nil
+	}
+	node.Children = result
+
+	if len(node.Children) == 0 && len(node.Attributes) == 0 && slices.Contains(c.removable, node.Data) {
+		return nil
+	}
+	return node
+}
+
+func (c *compacter) compactText(node *Node, keepSpace bool) *Node {
+	if node.Data == "" {
+		return nil
+	}
+	if keepSpace || strings.TrimSpace(node.Data) != "" {
+		return node
+	}
+	if c.opts.Conservative {
+		node.Data = " "
+		return node
+	}
+	return nil
+}
+
+func cloneNode(node *Node) *Node {
+	if node == nil {
+		return nil
+	}
+	result := &Node{
+		Data:       node.Data,
+		Attributes: slices.Clone(node.Attributes),
+		Type:       node.Type,
+		origin:     node.origin,
+	}
+	if len(node.Children) > 0 {
+		result.Children = make([]*Node, len(node.Children))
+		for i, child := range node.Children {
+			result.Children[i] = cloneNode(child)
+		}
+	}
+	return result
+}
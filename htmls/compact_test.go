@@ -0,0 +1,139 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package htmls_test
+
+import (
+	"strings"
+	"testing"
+
+	"t73f.de/r/webs/htmls"
+	"t73f.de/r/webs/htmls/render"
+)
+
+func TestCompact(t *testing.T) {
+	testcases := []struct {
+		name string
+		node *htmls.Node
+		opts htmls.CompactOptions
+		exp  string
+	}{
+		{"nil", nil, htmls.CompactOptions{}, ""},
+		{"empty-text", htmls.Text(""), htmls.CompactOptions{}, ""},
+		{"merge",
+			htmls.Elem("p", nil, htmls.Text("a"), htmls.Text("b"), htmls.Text("c")),
+			htmls.CompactOptions{},
+			"<p>abc</p>"},
+		{"merge-removed",
+			htmls.Elem("p", nil, htmls.Text("a"), htmls.Elem("span", nil), htmls.Text("b")),
+			htmls.CompactOptions{},
+			"<p>ab</p>"},
+		{"space-only",
+			htmls.Elem("p", nil, htmls.Elem("b", nil, htmls.Text("a")), htmls.Text(" \n "), htmls.Elem("i", nil, htmls.Text("b"))),
+			htmls.CompactOptions{},
+			"<p><b>a</b><i>b</i></p>"},
+		{"space-only-conservative",
+			htmls.Elem("p", nil, htmls.Elem("b", nil, htmls.Text("a")), htmls.Text(" \n "), htmls.Elem("i", nil, htmls.Text("b"))),
+			htmls.CompactOptions{Conservative: true},
+			"<p><b>a</b> <i>b</i></p>"},
+		{"pre",
+			htmls.Elem("pre", nil, htmls.Text("  "), htmls.Elem("code", nil, htmls.Text(" "))),
+			htmls.CompactOptions{},
+			"<pre>  <code> </code></pre>"},
+		{"nested-empty",
+			htmls.Elem("div", nil, htmls.Elem("div", nil, htmls.Elem("span", nil, htmls.Text(" "))), htmls.Text("x")),
+			htmls.CompactOptions{},
+			"<div>x</div>"},
+		{"all-empty",
+			htmls.Elem("div", nil, htmls.Elem("span", nil)),
+			htmls.CompactOptions{},
+			""},
+		{"attributes",
+			htmls.Elem("div", nil, htmls.Elem("span", htmls.Attrs("class", "icon"))),
+			htmls.CompactOptions{},
+			"<div><span class=\"icon\"></span></div>"},
+		{"removable",
+			htmls.Elem("div", nil, htmls.Elem("span", nil), htmls.Elem("em", nil)),
+			htmls.CompactOptions{Removable: []string{"em"}},
+			"<div><span></span></div>"},
+		{"keep-all",
+			htmls.Elem("div", nil, htmls.Elem("span", nil)),
+			htmls.CompactOptions{Removable: []string{}},
+			"<div><span></span></div>"},
+		{"raw-comment",
+			htmls.Elem("p", nil,
+				htmls.Text("a"),
+				&htmls.Node{Type: htmls.RawNode, Data: "  "},
+				htmls.Text("b"),
+				&htmls.Node{Type: htmls.CommentNode, Data: " "},
+				htmls.Text("c")),
+			htmls.CompactOptions{},
+			"<p>a  b<--   -->c</p>"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var before strings.Builder
+			if err := render.Render(&before, tc.node); err != nil {
+				t.Fatal(err)
+			}
+			got := renderString(t, htmls.Compact(tc.node, tc.opts))
+			if got != tc.exp {
+				t.Errorf("\nexpected: %q\n but got: %q", tc.exp, got)
+			}
+			if after := renderString(t, tc.node); after != before.String() {
+				t.Errorf("original node was changed:\n%q\n%q", before.String(), after)
+			}
+		})
+	}
+}
+
+func TestCompactInPlace(t *testing.T) {
+	node := htmls.Elem("p", nil, htmls.Text("a"), htmls.Text("b"), htmls.Elem("span", nil))
+	result := htmls.Compact(node, htmls.CompactOptions{InPlace: true})
+	if result != node {
+		t.Error("in place compaction must return the same node")
+	}
+	if got, exp := renderString(t, node), "<p>ab</p>"; got != exp {
+		t.Errorf("expected %q, but got %q", exp, got)
+	}
+}
+
+func TestCompactConservativeEquivalence(t *testing.T) {
+	node := htmls.Elem("div", nil,
+		htmls.Elem("p", nil,
+			htmls.Text("Hello"), htmls.Text(" "), htmls.Text(""),
+			htmls.Elem("em", nil, htmls.Text("World")),
+			htmls.Text("\n  "),
+			htmls.Elem("a", htmls.Attrs("href", "/"), htmls.Text("Home"), htmls.Text("!")),
+		),
+		htmls.Text("   "),
+		htmls.Elem("pre", nil, htmls.Text("x"), htmls.Text("  "), htmls.Text("y")),
+	)
+	before := renderString(t, node)
+	after := renderString(t, htmls.Compact(node, htmls.CompactOptions{Conservative: true}))
+	if exp, got := collapseSpace(before), collapseSpace(after); exp != got {
+		t.Errorf("rendering differs:\n%q\n%q", exp, got)
+	}
+}
+
+func renderString(t *testing.T, node *htmls.Node) string {
+	t.Helper()
+	var sb strings.Builder
+	if err := render.Render(&sb, node); err != nil {
+		t.Fatal(err)
+	}
+	return sb.String()
+}
+
+func collapseSpace(s string) string { return strings.Join(strings.Fields(s), " ") }
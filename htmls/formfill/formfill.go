@@ -0,0 +1,261 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+// Package formfill re-populates the form controls of a node tree with
+// submitted values, e.g. to show a form that was not built by package forms
+// again after a validation failure.
+package formfill
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+
+	"t73f.de/r/webs/htmls"
+)
+
+// Options controls how form controls are filled.
+type Options struct {
+	// Passwords allows to fill password inputs. By default, they are left
+	// untouched, so that a password is never sent back to the client.
+	Passwords bool
+}
+
+// Fill sets the values of all form controls of the tree to the given values,
+// with default options. See [Options.Fill].
+func Fill(root *htmls.Node, values url.Values) *htmls.Node {
+	return Options{}.Fill(root, values)
+}
+
+// Fill sets the values of all form controls of the tree to the given values.
+// The tree is modified in place and returned.
+//
+// The values are matched to the controls like a browser builds them when
+// submitting a form: the n-th text-like input, single select, or text area of
+// a name receives the n-th value of that name. A checkbox or radio button is
+// checked, if its value ("on", if it has none) is one of the values of its
+// name. A checkbox whose name has no value at all is unchecked, because
+// browsers do not submit unchecked checkboxes. An option of a select is
+// selected, if its value (its text, if it has no value attribute) is one of
+// the values of the select. A select with the "multiple" attribute consumes
+// all values of its name.
+//
+// Controls without a name, disabled controls, buttons, file inputs, and
+// password inputs (unless allowed by the options) are left untouched. So are
+// text-like inputs, selects, radio buttons, and text areas whose name has no
+// value. Elements that are not form controls are never changed.
+func (opts Options) Fill(root *htmls.Node, values url.Values) *htmls.Node {
+	f := filler{opts: opts, values: values, used: map[string]int{}}
+	f.walk(root)
+	return root
+}
+
+type filler struct {
+	opts   Options
+	values url.Values
+	used   map[string]int // number of consumed values per name
+}
+
+func (f *filler) walk(node *htmls.Node) {
+	if node == nil || node.Type != htmls.ElementNode {
+		return
+	}
+	switch strings.ToLower(node.Data) {
+	case "input":
+		f.fillInput(node)
+		return
+	case "textarea":
+		f.fillTextarea(node)
+		return
+	case "select":
+		f.fillSelect(node)
+		return
+	}
+	for _, child := range node.Children {
+		f.walk(child)
+	}
+}
+
+// next returns the next unused value of the given name.
+func (f *filler) next(name string) (string, bool) {
+	vals := f.values[name]
+	pos := f.used[name]
+	if pos >= len(vals) {
+		return "", false
+	}
+	f.used[name] = pos + 1
+	return vals[pos], true
+}
+
+func (f *filler) fillInput(node *htmls.Node) {
+	name, ok := controlName(node)
+	if !ok {
+		return
+	}
+	switch typ := strings.ToLower(getAttr(node, "type")); typ {
+	case "checkbox", "radio":
+		vals, found := f.values[name]
+		if !found && typ == "radio" {
+			return
+		}
+		value, hasValue := lookupAttr(node, "value")
+		if !hasValue {
+			value = "on"
+		}
+		setBoolAttr(node, "checked", slices.Contains(vals, value))
+	case "submit", "button", "reset", "image", "file":
+	case "password":
+		if !f.opts.Passwords {
+			return
+		}
+		fallthrough
+	default:
+		if value, found := f.next(name); found {
+			setAttr(node, "value", value)
+		}
+	}
+}
+
+func (f *filler) fillTextarea(node *htmls.Node) {
+	name, ok := controlName(node)
+	if !ok {
+		return
+	}
+	if value, found := f.next(name); found {
+		node.Children = []*htmls.Node{htmls.Text(value)}
+	}
+}
+
+func (f *filler) fillSelect(node *htmls.Node) {
+	name, ok := controlName(node)
+	if !ok {
+		return
+	}
+	var vals []string
+	multiple := hasAttr(node, "multiple")
+	if multiple {
+		vals = f.values[name]
+		if vals == nil {
+			return
+		}
+		f.used[name] = len(vals)
+	} else {
+		value, found := f.next(name)
+		if !found {
+			return
+		}
+		vals = []string{value}
+	}
+	for _, option := range options(node, nil) {
+		value, hasValue := lookupAttr(option, "value")
+		if !hasValue {
+			value = strings.Join(strings.Fields(textContent(option)), " ")
+		}
+		selected := slices.Contains(vals, value)
+		setBoolAttr(option, "selected", selected)
+		if selected && !multiple {
+			// Only the first matching option of a single select is selected.
+			vals = nil
+		}
+	}
+}
+
+// options returns all option elements of a select, including those within
+// an optgroup.
+func options(node *htmls.Node, result []*htmls.Node) []*htmls.Node {
+	for _, child := range node.Children {
+		if child == nil || child.Type != htmls.ElementNode {
+			continue
+		}
+		switch strings.ToLower(child.Data) {
+		case "option":
+			result = append(result, child)
+		case "optgroup":
+			result = options(child, result)
+		}
+	}
+	return result
+}
+
+// controlName returns the name of a form control, if it takes part in a
+// form submission.
+func controlName(node *htmls.Node) (string, bool) {
+	name := getAttr(node, "name")
+	if name == "" || hasAttr(node, "disabled") {
+		return "", false
+	}
+	return name, true
+}
+
+func lookupAttr(node *htmls.Node, key string) (string, bool) {
+	for _, attr := range node.Attributes {
+		if strings.EqualFold(attr.Key, key) {
+			return attr.Value, true
+		}
+	}
+	return "", false
+}
+
+func getAttr(node *htmls.Node, key string) string {
+	value, _ := lookupAttr(node, key)
+	return value
+}
+
+func hasAttr(node *htmls.Node, key string) bool {
+	_, found := lookupAttr(node, key)
+	return found
+}
+
+func setAttr(node *htmls.Node, key, value string) {
+	for i, attr := range node.Attributes {
+		if strings.EqualFold(attr.Key, key) {
+			node.Attributes[i].Value = value
+			return
+		}
+	}
+	node.Attributes = append(node.Attributes, htmls.Attribute{Key: key, Value: value})
+}
+
+// setBoolAttr adds or removes a boolean attribute.
+func setBoolAttr(node *htmls.Node, key string, set bool) {
+	if set {
+		if !hasAttr(node, key) {
+			node.Attributes = append(node.Attributes, htmls.Attribute{Key: key})
+		}
+		return
+	}
+	node.Attributes = slices.DeleteFunc(node.Attributes, func(attr htmls.Attribute) bool {
+		return strings.EqualFold(attr.Key, key)
+	})
+}
+
+// textContent returns the concatenated text of all text nodes of the tree.
+func textContent(node *htmls.Node) string {
+	var sb strings.Builder
+	var collect func(*htmls.Node)
+	collect = func(n *htmls.Node) {
+		if n == nil {
+			return
+		}
+		switch n.Type {
+		case htmls.TextNode:
+			sb.WriteString(n.Data)
+		case htmls.ElementNode:
+			for _, child := range n.Children {
+				collect(child)
+			}
+		}
+	}
+	collect(node)
+	return sb.String()
+}
@@ -0,0 +1,169 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package formfill_test
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"t73f.de/r/webs/htmls"
+	"t73f.de/r/webs/htmls/formfill"
+	"t73f.de/r/webs/htmls/render"
+)
+
+func renderNode(t *testing.T, node *htmls.Node) string {
+	t.Helper()
+	var sb strings.Builder
+	if err := render.Render(&sb, node); err != nil {
+		t.Fatal(err)
+	}
+	return sb.String()
+}
+
+func input(attrs ...string) *htmls.Node { return htmls.Elem("input", htmls.Attrs(attrs...)) }
+
+func option(text string, attrs ...string) *htmls.Node {
+	return htmls.Elem("option", htmls.Attrs(attrs...), htmls.Text(text))
+}
+
+func TestFill(t *testing.T) {
+	testcases := []struct {
+		name   string
+		node   *htmls.Node
+		values url.Values
+		exp    string
+	}{
+		{"text", input("type", "text", "name", "a"), url.Values{"a": {"x<y"}},
+			`<input type="text" name="a" value="x&lt;y">`},
+		{"text-replace", input("name", "a", "value", "old"), url.Values{"a": {"new"}},
+			`<input name="a" value="new">`},
+		{"text-missing", input("name", "a", "value", "old"), url.Values{"b": {"new"}},
+			`<input name="a" value="old">`},
+		{"email", input("type", "email", "name", "a"), url.Values{"a": {"a@example.com"}},
+			`<input type="email" name="a" value="a@example.com">`},
+		{"number", input("type", "number", "name", "a"), url.Values{"a": {"17"}},
+			`<input type="number" name="a" value="17">`},
+		{"hidden", input("type", "hidden", "name", "a"), url.Values{"a": {"h"}},
+			`<input type="hidden" name="a" value="h">`},
+		{"password", input("type", "password", "name", "a"), url.Values{"a": {"secret"}},
+			`<input type="password" name="a">`},
+		{"submit", input("type", "submit", "name", "a", "value", "Go"), url.Values{"a": {"x"}},
+			`<input type="submit" name="a" value="Go">`},
+		{"file", input("type", "file", "name", "a"), url.Values{"a": {"x"}},
+			`<input type="file" name="a">`},
+		{"no-name", input("type", "text"), url.Values{"": {"x"}},
+			`<input type="text">`},
+		{"disabled", input("name", "a", "disabled", ""), url.Values{"a": {"x"}},
+			`<input name="a" disabled="">`},
+		{"same-name-texts",
+			htmls.Elem("div", nil, input("name", "a"), input("name", "a"), input("name", "a")),
+			url.Values{"a": {"1", "2"}},
+			`<div><input name="a" value="1"><input name="a" value="2"><input name="a"></div>`},
+
+		{"checkbox", input("type", "checkbox", "name", "c", "value", "x"), url.Values{"c": {"x"}},
+			`<input type="checkbox" name="c" value="x" checked="">`},
+		{"checkbox-on", input("type", "checkbox", "name", "c"), url.Values{"c": {"on"}},
+			`<input type="checkbox" name="c" checked="">`},
+		{"checkbox-uncheck", input("type", "checkbox", "name", "c", "value", "x", "checked", ""), url.Values{"c": {"y"}},
+			`<input type="checkbox" name="c" value="x">`},
+		{"checkbox-absent", input("type", "checkbox", "name", "c", "checked", ""), url.Values{},
+			`<input type="checkbox" name="c">`},
+		{"checkbox-multi",
+			htmls.Elem("div", nil,
+				input("type", "checkbox", "name", "c", "value", "1"),
+				input("type", "checkbox", "name", "c", "value", "2", "checked", ""),
+				input("type", "checkbox", "name", "c", "value", "3")),
+			url.Values{"c": {"3", "1"}},
+			`<div><input type="checkbox" name="c" value="1" checked=""><input type="checkbox" name="c" value="2"><input type="checkbox" name="c" value="3" checked=""></div>`},
+		{"radio",
+			htmls.Elem("div", nil,
+				input("type", "radio", "name", "r", "value", "1", "checked", ""),
+				input("type", "radio", "name", "r", "value", "2")),
+			url.Values{"r": {"2"}},
+			`<div><input type="radio" name="r" value="1"><input type="radio" name="r" value="2" checked=""></div>`},
+		{"radio-absent", input("type", "radio", "name", "r", "value", "1", "checked", ""), url.Values{},
+			`<input type="radio" name="r" value="1" checked="">`},
+
+		{"select",
+			htmls.Elem("select", htmls.Attrs("name", "s"),
+				option("One", "value", "1", "selected", ""), option("Two", "value", "2"), option("Three")),
+			url.Values{"s": {"2"}},
+			`<select name="s"><option value="1">One</option><option value="2" selected="">Two</option><option>Three</option></select>`},
+		{"select-text",
+			htmls.Elem("select", htmls.Attrs("name", "s"), option("One"), option(" Two\n Three ")),
+			url.Values{"s": {"Two Three"}},
+			"<select name=\"s\"><option>One</option><option selected=\"\"> Two\n Three </option></select>"},
+		{"select-nomatch",
+			htmls.Elem("select", htmls.Attrs("name", "s"), option("One", "value", "1", "selected", "")),
+			url.Values{"s": {"9"}},
+			`<select name="s"><option value="1">One</option></select>`},
+		{"select-first-match",
+			htmls.Elem("select", htmls.Attrs("name", "s"), option("A", "value", "1"), option("B", "value", "1")),
+			url.Values{"s": {"1"}},
+			`<select name="s"><option value="1" selected="">A</option><option value="1">B</option></select>`},
+		{"select-optgroup",
+			htmls.Elem("select", htmls.Attrs("name", "s"),
+				htmls.Elem("optgroup", htmls.Attrs("label", "G"), option("One", "value", "1")),
+				option("Two", "value", "2", "selected", "")),
+			url.Values{"s": {"1"}},
+			`<select name="s"><optgroup label="G"><option value="1" selected="">One</option></optgroup><option value="2">Two</option></select>`},
+		{"select-multiple",
+			htmls.Elem("select", htmls.Attrs("name", "s", "multiple", ""),
+				option("One", "value", "1"), option("Two", "value", "2", "selected", ""), option("Three", "value", "3")),
+			url.Values{"s": {"1", "3"}},
+			`<select name="s" multiple=""><option value="1" selected="">One</option><option value="2">Two</option><option value="3" selected="">Three</option></select>`},
+		{"select-absent",
+			htmls.Elem("select", htmls.Attrs("name", "s"), option("One", "value", "1", "selected", "")),
+			url.Values{},
+			`<select name="s"><option value="1" selected="">One</option></select>`},
+
+		{"textarea", htmls.Elem("textarea", htmls.Attrs("name", "t"), htmls.Text("old"), htmls.Text("er")),
+			url.Values{"t": {"line 1\n<line 2>"}},
+			"<textarea name=\"t\">line 1\n&lt;line 2&gt;</textarea>"},
+		{"textarea-empty", htmls.Elem("textarea", htmls.Attrs("name", "t")), url.Values{"t": {"x"}},
+			`<textarea name="t">x</textarea>`},
+		{"textarea-absent", htmls.Elem("textarea", htmls.Attrs("name", "t"), htmls.Text("old")), url.Values{},
+			`<textarea name="t">old</textarea>`},
+
+		{"non-controls",
+			htmls.Elem("form", htmls.Attrs("name", "a"),
+				htmls.Elem("p", htmls.Attrs("name", "a", "value", "v"), htmls.Text("a")),
+				htmls.Elem("button", htmls.Attrs("name", "a", "value", "b"), htmls.Text("B")),
+				htmls.Elem("output", htmls.Attrs("name", "a"), htmls.Text("o")),
+				input("name", "a")),
+			url.Values{"a": {"1"}},
+			`<form name="a"><p name="a" value="v">a</p><button name="a" value="b">B</button><output name="a">o</output><input name="a" value="1"></form>`},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			node := formfill.Fill(tc.node, tc.values)
+			if node != tc.node {
+				t.Error("root node must be returned")
+			}
+			if got := renderNode(t, node); got != tc.exp {
+				t.Errorf("\nexpected %s\ngot      %s", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestFillPasswords(t *testing.T) {
+	node := htmls.Elem("div", nil, input("type", "password", "name", "p"), input("type", "password", "name", "p"))
+	values := url.Values{"p": {"s1", "s2"}}
+	exp := `<div><input type="password" name="p" value="s1"><input type="password" name="p" value="s2"></div>`
+	if got := renderNode(t, formfill.Options{Passwords: true}.Fill(node, values)); got != exp {
+		t.Errorf("\nexpected %s\ngot      %s", exp, got)
+	}
+}
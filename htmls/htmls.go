@@ -17,6 +17,13 @@
 // a full HTML document.
 package htmls
 
+import (
+	"os"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+)
+
 // A Node consists of a NodeType and some Data (tag name for element nodes,
 // content for text nodes, comment nodes, and some more). An element node
 // may also contain some Attributes and Children. Data is always not escaped,
@@ -26,6 +33,36 @@ type Node struct {
 	Attributes []Attribute
 	Children   []*Node
 	Type       NodeType
+
+	origin string // source position of construction, only in debug mode
+}
+
+// Origin returns the source position ("file:line") of the Go code that
+// constructed the node. It is only recorded in debug mode, see [SetDebug].
+// Otherwise, the empty string is returned.
+func (node *Node) Origin() string { return node.origin }
+
+// DebugEnv is the name of the environment variable that enables debug mode
+// when the program starts, if its value is not empty.
+const DebugEnv = "WEBS_HTMLS_DEBUG"
+
+var debug atomic.Bool
+
+func init() { debug.Store(os.Getenv(DebugEnv) != "") }
+
+// SetDebug enables or disables debug mode. In debug mode, the source
+// position of the code that constructs a node with [Elem], [Text], or [Raw]
+// is recorded, see [Node.Origin]. Debug mode slows down the construction of
+// nodes and should not be enabled in production.
+func SetDebug(enabled bool) { debug.Store(enabled) }
+
+// callerOrigin returns the source position of the caller of the node
+// constructor.
+func callerOrigin() string {
+	if _, file, line, ok := runtime.Caller(2); ok {
+		return file + ":" + strconv.Itoa(line)
+	}
+	return ""
 }
 
 // AddChildren adds some more children to the Node.
@@ -87,22 +124,44 @@ func Elem(tag string, attrs []Attribute, children ...*Node) *Node {
 		}
 		children = newChildren
 	}
-	return &Node{
+	node := &Node{
 		Data:       tag,
 		Attributes: attrs,
 		Children:   children,
 		Type:       ElementNode,
 	}
+	if debug.Load() {
+		node.origin = callerOrigin()
+	}
+	return node
 }
 
 // Text returns a text node.
 func Text(data string) *Node {
-	return &Node{
+	node := &Node{
 		Data:       data,
 		Attributes: nil,
 		Children:   nil,
 		Type:       TextNode,
 	}
+	if debug.Load() {
+		node.origin = callerOrigin()
+	}
+	return node
+}
+
+// Raw returns a raw node, which contains already processed HTML text.
+func Raw(data string) *Node {
+	node := &Node{
+		Data:       data,
+		Attributes: nil,
+		Children:   nil,
+		Type:       RawNode,
+	}
+	if debug.Load() {
+		node.origin = callerOrigin()
+	}
+	return node
 }
 
 // Attrs returns a slice of [Attribute] values.
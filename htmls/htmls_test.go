@@ -0,0 +1,76 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package htmls_test
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"t73f.de/r/webs/htmls"
+)
+
+func TestOrigin(t *testing.T) {
+	htmls.SetDebug(true)
+	defer htmls.SetDebug(false)
+
+	_, file, line, _ := runtime.Caller(0)
+	nodes := []*htmls.Node{
+		htmls.Elem("p", nil),
+		htmls.Text("text"),
+		htmls.Raw("<br>"),
+	}
+	for i, node := range nodes {
+		exp := file + ":" + strconv.Itoa(line+i+2)
+		if got := node.Origin(); got != exp {
+			t.Errorf("node %d: expected origin %q, but got %q", i, exp, got)
+		}
+	}
+	if origin := htmls.Compact(nodes[0], htmls.CompactOptions{}).Origin(); !strings.HasSuffix(origin, "htmls_test.go:"+strconv.Itoa(line+2)) {
+		t.Errorf("compacted node lost its origin: %q", origin)
+	}
+
+	htmls.SetDebug(false)
+	if got := htmls.Elem("p", nil).Origin(); got != "" {
+		t.Errorf("no origin expected, but got %q", got)
+	}
+}
+
+func TestNoDebugAllocs(t *testing.T) {
+	htmls.SetDebug(false)
+	if allocs := testing.AllocsPerRun(100, func() { _ = htmls.Elem("p", nil) }); allocs != 1 {
+		t.Errorf("Elem: expected 1 allocation, but got %v", allocs)
+	}
+	if allocs := testing.AllocsPerRun(100, func() { _ = htmls.Text("text") }); allocs != 1 {
+		t.Errorf("Text: expected 1 allocation, but got %v", allocs)
+	}
+}
+
+func BenchmarkElem(b *testing.B) {
+	htmls.SetDebug(false)
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = htmls.Elem("p", nil, htmls.Text("text"))
+	}
+}
+
+func BenchmarkElemDebug(b *testing.B) {
+	htmls.SetDebug(true)
+	defer htmls.SetDebug(false)
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = htmls.Elem("p", nil, htmls.Text("text"))
+	}
+}
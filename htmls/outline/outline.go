@@ -0,0 +1,239 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+// Package outline provides functions to work with the headings of a node
+// tree, e.g. to assign identifiers to headings and to build a table of
+// contents.
+package outline
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"t73f.de/r/webs/htmls"
+)
+
+// Exclude selects container elements, whose headings are ignored. An element
+// is selected, if it has the given tag, if Tag is not empty, and if it has
+// the given class, if Class is not empty. An Exclude with neither tag nor
+// class selects no element.
+type Exclude struct {
+	Tag   string
+	Class string
+}
+
+func (ex Exclude) matches(node *htmls.Node) bool {
+	if ex.Tag == "" && ex.Class == "" {
+		return false
+	}
+	if ex.Tag != "" && node.Data != ex.Tag {
+		return false
+	}
+	return ex.Class == "" || slices.Contains(strings.Fields(getAttr(node, "class")), ex.Class)
+}
+
+// AssignHeadingIDs sets the "id" attribute of all headings (h1 ... h6) of
+// the tree that do not have one. The identifier consists of the given prefix
+// and the slug of the heading text: letters are lowercased, some letters
+// with diacritics are replaced by their base letters, and all other
+// characters are replaced by a single dash. Identifiers are unique within the
+// tree: a numeric suffix is appended, if the identifier is already used.
+//
+// Headings within an excluded container are ignored. The tree is modified
+// in place.
+func AssignHeadingIDs(root *htmls.Node, prefix string, excludes ...Exclude) {
+	used := map[string]bool{}
+	walkElements(root, nil, func(node *htmls.Node) {
+		if id := getAttr(node, "id"); id != "" {
+			used[id] = true
+		}
+	})
+	walkElements(root, excludes, func(node *htmls.Node) {
+		if headingLevel(node) == 0 || getAttr(node, "id") != "" {
+			return
+		}
+		slug := Slugify(textContent(node))
+		if slug == "" {
+			slug = "section"
+		}
+		id := prefix + slug
+		for i := 2; used[id]; i++ {
+			id = prefix + slug + "-" + strconv.Itoa(i)
+		}
+		used[id] = true
+		node.Attributes = append(node.Attributes, htmls.Attribute{Key: "id", Value: id})
+	})
+}
+
+// TOC returns a table of contents of all headings of the tree with a level
+// between minLevel and maxLevel. It is a <nav class="toc"> with a nested
+// ordered list of links to the headings.
+//
+// Only headings with an identifier are listed, see [AssignHeadingIDs].
+// Headings within an excluded container are ignored. A heading that skips a
+// level, e.g. a h4 after a h2, is nested only one level deeper. A heading
+// with a level above the first listed heading is placed at the top level of
+// the list. If no heading is listed, nil is returned.
+func TOC(root *htmls.Node, minLevel, maxLevel int, excludes ...Exclude) *htmls.Node {
+	type entry struct {
+		level int
+		list  *htmls.Node
+		item  *htmls.Node // last item of the list
+	}
+	var stack []entry
+	walkElements(root, excludes, func(node *htmls.Node) {
+		level := headingLevel(node)
+		if level == 0 || level < minLevel || level > maxLevel {
+			return
+		}
+		id := getAttr(node, "id")
+		if id == "" {
+			return
+		}
+		item := htmls.Elem("li", nil,
+			htmls.Elem("a", htmls.Attrs("href", "#"+id), htmls.Text(strings.Join(strings.Fields(textContent(node)), " "))))
+		if len(stack) == 0 {
+			stack = append(stack, entry{level: level, list: htmls.Elem("ol", nil)})
+		}
+		for len(stack) > 1 && stack[len(stack)-1].level > level {
+			if stack[len(stack)-2].level < level {
+				// A skipped level is filled, e.g. a h3 after a h4 after a h2:
+				// continue the current list.
+				stack[len(stack)-1].level = level
+				break
+			}
+			stack = stack[:len(stack)-1]
+		}
+		top := &stack[len(stack)-1]
+		if level < top.level {
+			// Only at the top level of the list: the first heading was not
+			// the one with the highest level.
+			top.level = level
+		} else if level > top.level && top.item != nil {
+			sublist := htmls.Elem("ol", nil)
+			top.item.AddChildren(sublist)
+			stack = append(stack, entry{level: level, list: sublist})
+			top = &stack[len(stack)-1]
+		}
+		top.list.AddChildren(item)
+		top.item = item
+	})
+	if len(stack) == 0 {
+		return nil
+	}
+	return htmls.Elem("nav", htmls.Attrs("class", "toc"), stack[0].list)
+}
+
+// Slugify returns a string suitable as an identifier: letters are lowercased,
+// some letters with diacritics are replaced by their base letters, and all
+// sequences of other characters are replaced by a single dash. Leading and
+// trailing dashes are removed.
+func Slugify(s string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range s {
+		r = unicode.ToLower(r)
+		if folded, found := foldMap[r]; found {
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			sb.WriteString(folded)
+			dash = false
+			continue
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			sb.WriteRune(r)
+			dash = false
+			continue
+		}
+		if unicode.Is(unicode.Mn, r) {
+			continue // combining mark, e.g. of a decomposed letter
+		}
+		dash = true
+	}
+	return sb.String()
+}
+
+// foldMap maps lowercase letters with diacritics to their base letters.
+var foldMap = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ă': "a", 'ą': "a",
+	'æ': "ae", 'ç': "c", 'ć': "c", 'č': "c", 'ď': "d", 'đ': "d", 'ð': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ė': "e", 'ę': "e", 'ě': "e",
+	'ğ': "g", 'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i", 'į': "i", 'ı': "i",
+	'ł': "l", 'ľ': "l", 'ñ': "n", 'ń': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ő': "o", 'œ': "oe",
+	'ŕ': "r", 'ř': "r", 'ß': "ss", 'ś': "s", 'š': "s", 'ş': "s", 'ť': "t", 'ţ': "t", 'þ': "th",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u", 'ű': "u", 'ų': "u",
+	'ý': "y", 'ÿ': "y", 'ź': "z", 'ż': "z", 'ž': "z",
+}
+
+// walkElements calls fn for all element nodes of the tree in document order,
+// but not for excluded elements and their descendants.
+func walkElements(node *htmls.Node, excludes []Exclude, fn func(*htmls.Node)) {
+	if node == nil || node.Type != htmls.ElementNode {
+		return
+	}
+	for _, ex := range excludes {
+		if ex.matches(node) {
+			return
+		}
+	}
+	fn(node)
+	for _, child := range node.Children {
+		walkElements(child, excludes, fn)
+	}
+}
+
+// headingLevel returns the level of a heading element, or 0, if the node is
+// not a heading.
+func headingLevel(node *htmls.Node) int {
+	if tag := node.Data; len(tag) == 2 && (tag[0] == 'h' || tag[0] == 'H') && '1' <= tag[1] && tag[1] <= '6' {
+		return int(tag[1] - '0')
+	}
+	return 0
+}
+
+func getAttr(node *htmls.Node, key string) string {
+	for _, attr := range node.Attributes {
+		if attr.Key == key {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// textContent returns the concatenated text of all text nodes of the tree.
+func textContent(node *htmls.Node) string {
+	var sb strings.Builder
+	var collect func(*htmls.Node)
+	collect = func(n *htmls.Node) {
+		if n == nil {
+			return
+		}
+		switch n.Type {
+		case htmls.TextNode:
+			sb.WriteString(n.Data)
+		case htmls.ElementNode:
+			for _, child := range n.Children {
+				collect(child)
+			}
+		}
+	}
+	collect(node)
+	return sb.String()
+}
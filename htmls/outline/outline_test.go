@@ -0,0 +1,154 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package outline_test
+
+import (
+	"strings"
+	"testing"
+
+	"t73f.de/r/webs/htmls"
+	"t73f.de/r/webs/htmls/outline"
+	"t73f.de/r/webs/htmls/render"
+)
+
+func renderNode(t *testing.T, node *htmls.Node) string {
+	t.Helper()
+	var sb strings.Builder
+	if err := render.Render(&sb, node); err != nil {
+		t.Fatal(err)
+	}
+	return sb.String()
+}
+
+func heading(tag, text string, attrs ...string) *htmls.Node {
+	return htmls.Elem(tag, htmls.Attrs(attrs...), htmls.Text(text))
+}
+
+func TestSlugify(t *testing.T) {
+	testcases := []struct {
+		in, exp string
+	}{
+		{"", ""},
+		{"Introduction", "introduction"},
+		{"  Getting   started! ", "getting-started"},
+		{"Größe & Maße", "grosse-masse"},
+		{"Ça va? Très bien.", "ca-va-tres-bien"},
+		{"Café au lait", "cafe-au-lait"},
+		{"Привет, мир", "привет-мир"},
+		{"Version 2.0", "version-2-0"},
+		{"---", ""},
+	}
+	for _, tc := range testcases {
+		if got := outline.Slugify(tc.in); got != tc.exp {
+			t.Errorf("Slugify(%q): %q expected, got %q", tc.in, tc.exp, got)
+		}
+	}
+}
+
+func TestAssignHeadingIDs(t *testing.T) {
+	makeTree := func() *htmls.Node {
+		return htmls.Elem("article", nil,
+			heading("h1", "Title"),
+			heading("h2", "Usage"),
+			heading("h3", "Usage"),
+			heading("h2", "Custom", "id", "usage-2"),
+			heading("h2", "Usage"),
+			heading("h2", "Überblick"),
+			heading("h2", "!!!"),
+			htmls.Elem("aside", htmls.Attrs("class", "note box"), heading("h2", "Note")),
+			htmls.Elem("h3", nil, htmls.Text("With "), htmls.Elem("code", nil, htmls.Text("code"))),
+		)
+	}
+	exp := `<article><h1 id="doc-title">Title</h1><h2 id="doc-usage">Usage</h2>` +
+		`<h3 id="doc-usage-2">Usage</h3><h2 id="usage-2">Custom</h2><h2 id="doc-usage-3">Usage</h2>` +
+		`<h2 id="doc-uberblick">Überblick</h2><h2 id="doc-section">!!!</h2>` +
+		`<aside class="note box"><h2>Note</h2></aside>` +
+		`<h3 id="doc-with-code">With <code>code</code></h3></article>`
+	for range 3 {
+		root := makeTree()
+		outline.AssignHeadingIDs(root, "doc-", outline.Exclude{Class: "note"})
+		if got := renderNode(t, root); got != exp {
+			t.Errorf("\nexpected: %s\ngot:      %s", exp, got)
+		}
+	}
+
+	// Pre-existing identifiers are respected, even without prefix.
+	root := makeTree()
+	outline.AssignHeadingIDs(root, "", outline.Exclude{Class: "note"})
+	got := renderNode(t, root)
+	if !strings.Contains(got, `<h3 id="usage-3">Usage</h3><h2 id="usage-2">Custom</h2><h2 id="usage-4">Usage</h2>`) {
+		t.Errorf("unexpected identifiers: %s", got)
+	}
+
+	// Assigning twice does not change anything.
+	outline.AssignHeadingIDs(root, "", outline.Exclude{Class: "note"})
+	if again := renderNode(t, root); again != got {
+		t.Errorf("second assignment changed tree:\n%s\n%s", got, again)
+	}
+}
+
+func TestTOC(t *testing.T) {
+	root := htmls.Elem("main", nil,
+		heading("h1", "Title"),
+		heading("h2", "A"),
+		heading("h3", "A.1"),
+		heading("h3", "A.2"),
+		heading("h2", "B"),
+		heading("h4", "B.x"),
+		heading("h3", "B.1"),
+		htmls.Elem("nav", nil, heading("h2", "Menu")),
+		heading("h2", "C"),
+	)
+	outline.AssignHeadingIDs(root, "")
+	got := renderNode(t, outline.TOC(root, 2, 4, outline.Exclude{Tag: "nav"}))
+	exp := `<nav class="toc"><ol>` +
+		`<li><a href="#a">A</a><ol><li><a href="#a-1">A.1</a></li><li><a href="#a-2">A.2</a></li></ol></li>` +
+		`<li><a href="#b">B</a><ol><li><a href="#b-x">B.x</a></li><li><a href="#b-1">B.1</a></li></ol></li>` +
+		`<li><a href="#c">C</a></li>` +
+		`</ol></nav>`
+	if got != exp {
+		t.Errorf("\nexpected: %s\ngot:      %s", exp, got)
+	}
+
+	got = renderNode(t, outline.TOC(root, 3, 3, outline.Exclude{Tag: "nav"}))
+	exp = `<nav class="toc"><ol><li><a href="#a-1">A.1</a></li><li><a href="#a-2">A.2</a></li>` +
+		`<li><a href="#b-1">B.1</a></li></ol></nav>`
+	if got != exp {
+		t.Errorf("\nexpected: %s\ngot:      %s", exp, got)
+	}
+}
+
+func TestTOCMalformed(t *testing.T) {
+	root := htmls.Elem("div", nil,
+		heading("h3", "Deep", "id", "deep"),
+		heading("h2", "Shallow", "id", "shallow"),
+		heading("h4", "Deeper", "id", "deeper"),
+		heading("h2", "No id"),
+		heading("h3", "Last", "id", "last"),
+	)
+	got := renderNode(t, outline.TOC(root, 1, 6))
+	exp := `<nav class="toc"><ol><li><a href="#deep">Deep</a></li>` +
+		`<li><a href="#shallow">Shallow</a><ol><li><a href="#deeper">Deeper</a></li>` +
+		`<li><a href="#last">Last</a></li></ol></li></ol></nav>`
+	if got != exp {
+		t.Errorf("\nexpected: %s\ngot:      %s", exp, got)
+	}
+
+	if toc := outline.TOC(htmls.Elem("p", nil, htmls.Text("no headings")), 1, 6); toc != nil {
+		t.Errorf("no table of contents expected, got %s", renderNode(t, toc))
+	}
+	if toc := outline.TOC(nil, 1, 6); toc != nil {
+		t.Error("no table of contents expected for nil tree")
+	}
+}
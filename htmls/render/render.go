@@ -30,17 +30,31 @@ import (
 // minimal and many special rules are ignored. The function is intended for
 // testing purposes only.
 func Render(w io.Writer, node *htmls.Node) error {
+	return doRender(w, node, false)
+}
+
+// RenderWithOrigins works like [Render], but writes the origin of each
+// element as an HTML comment before the element. Origins are only available
+// in debug mode, see [htmls.SetDebug]. The function is intended for
+// debugging generated markup.
+func RenderWithOrigins(w io.Writer, node *htmls.Node) error {
+	return doRender(w, node, true)
+}
+
+func doRender(w io.Writer, node *htmls.Node, withOrigins bool) error {
 	if mw, ok := w.(myWriter); ok {
-		return render(mw, node)
+		return render(mw, node, withOrigins, nil)
 	}
 	buf := bufio.NewWriter(w)
-	if err := render(buf, node); err != nil {
+	if err := render(buf, node, withOrigins, nil); err != nil {
 		return err
 	}
 	return buf.Flush()
 }
 
-func render(w myWriter, node *htmls.Node) error {
+// render writes the node. If st is not nil, the output is flushed at the
+// points specified by st.
+func render(w myWriter, node *htmls.Node, withOrigins bool, st *streamer) error {
 	if node == nil {
 		return nil
 	}
@@ -67,6 +81,18 @@ func render(w myWriter, node *htmls.Node) error {
 		return fmt.Errorf("unknown node type: %v", node.Type)
 	}
 
+	if origin := node.Origin(); withOrigins && origin != "" {
+		if _, err := w.WriteString("<!-- "); err != nil {
+			return err
+		}
+		if err := escapeComment(w, origin); err != nil {
+			return err
+		}
+		if _, err := w.WriteString(" -->"); err != nil {
+			return err
+		}
+	}
+
 	tag := node.Data
 	if err := w.WriteByte('<'); err != nil {
 		return err
@@ -118,16 +144,21 @@ func render(w myWriter, node *htmls.Node) error {
 					return err
 				}
 			} else {
-				if err := render(w, child); err != nil {
+				if err := render(w, child, withOrigins, st); err != nil {
 					return err
 				}
 			}
 		}
 	} else {
-		for _, child := range node.Children {
-			if err := render(w, child); err != nil {
+		for i, child := range node.Children {
+			if err := render(w, child, withOrigins, st); err != nil {
 				return err
 			}
+			if st != nil && i+1 < len(node.Children) && st.isChunkPoint(tag, i+1) {
+				if err := st.flush(); err != nil {
+					return err
+				}
+			}
 		}
 	}
 
@@ -140,6 +171,9 @@ func render(w myWriter, node *htmls.Node) error {
 	if err := w.WriteByte('>'); err != nil {
 		return err
 	}
+	if st != nil && tag == "head" && st.flushHead {
+		return st.flush()
+	}
 	return nil
 }
 
@@ -76,3 +76,38 @@ func TestRender(t *testing.T) {
 		})
 	}
 }
+
+func TestRenderWithOrigins(t *testing.T) {
+	node := htmls.Elem("p", nil, htmls.Text("a"))
+	var sb strings.Builder
+	if err := render.RenderWithOrigins(&sb, node); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := sb.String(), "<p>a</p>"; got != exp {
+		t.Errorf("without debug mode, expected %q, but got %q", exp, got)
+	}
+
+	htmls.SetDebug(true)
+	node = htmls.Elem("p", nil, htmls.Elem("b", nil, htmls.Text("a")))
+	htmls.SetDebug(false)
+	sb.Reset()
+	if err := render.RenderWithOrigins(&sb, node); err != nil {
+		t.Fatal(err)
+	}
+	got := sb.String()
+	exp := "<!-- " + node.Origin() + " --><p><!-- " + node.Children[0].Origin() + " --><b>a</b></p>"
+	if got != exp {
+		t.Errorf("expected %q, but got %q", exp, got)
+	}
+	if !strings.Contains(got, "render_test.go:") {
+		t.Errorf("origin must point to test file: %q", got)
+	}
+
+	sb.Reset()
+	if err := render.Render(&sb, node); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := sb.String(), "<p><b>a</b></p>"; got != exp {
+		t.Errorf("Render must not emit origins, expected %q, but got %q", exp, got)
+	}
+}
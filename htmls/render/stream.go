@@ -0,0 +1,101 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package render
+
+import (
+	"bufio"
+	"errors"
+	"net/http"
+	"slices"
+
+	"t73f.de/r/webs/htmls"
+)
+
+// DefaultChunkSize is the number of children of a chunked element, after
+// which [Stream] flushes the output, if [StreamOptions.ChunkSize] is not
+// positive.
+const DefaultChunkSize = 500
+
+// StreamOptions specify the points where [Stream] flushes its output.
+type StreamOptions struct {
+	// NoHeadFlush disables flushing after the head element.
+	NoHeadFlush bool
+
+	// ChunkTags lists the tags of elements, whose direct children are sent
+	// in chunks of ChunkSize children, e.g. "tbody" for large tables.
+	ChunkTags []string
+
+	// ChunkSize is the number of children per chunk. If not positive,
+	// DefaultChunkSize is used.
+	ChunkSize int
+
+	// WithOrigins writes the origin of each element, like RenderWithOrigins.
+	WithOrigins bool
+}
+
+// Stream writes the given node like [Render], but flushes the output at the
+// points specified by the options, so that a browser is able to render a
+// large page progressively. The output is flushed after the head element,
+// and after every ChunkSize direct children of an element listed in
+// ChunkTags. In addition, the output is flushed at the end.
+//
+// If an error occurs, rendering stops and the error is returned. All output
+// up to the last flush point has been sent to the client, the rest is
+// discarded. Since the status code and the header are sent with the first
+// flush, the caller cannot signal the error by a status code anymore. It
+// should log the error instead.
+func Stream(w http.ResponseWriter, node *htmls.Node, opts StreamOptions) error {
+	chunkSize := opts.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	st := streamer{
+		buf:       bufio.NewWriter(w),
+		rc:        http.NewResponseController(w),
+		flushHead: !opts.NoHeadFlush,
+		chunkTags: opts.ChunkTags,
+		chunkSize: chunkSize,
+	}
+	if err := render(st.buf, node, opts.WithOrigins, &st); err != nil {
+		return err
+	}
+	return st.flush()
+}
+
+// streamer stores the data needed to flush the output of [Stream].
+type streamer struct {
+	buf       *bufio.Writer
+	rc        *http.ResponseController
+	flushHead bool
+	chunkTags []string
+	chunkSize int
+}
+
+// isChunkPoint returns true, if the output should be flushed after the
+// given number of children of an element with the given tag.
+func (st *streamer) isChunkPoint(tag string, numChildren int) bool {
+	return numChildren%st.chunkSize == 0 && slices.Contains(st.chunkTags, tag)
+}
+
+// flush the buffered output to the client. A ResponseWriter that does not
+// support flushing is not an error: the output is then only written to it.
+func (st *streamer) flush() error {
+	if err := st.buf.Flush(); err != nil {
+		return err
+	}
+	if err := st.rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package render_test
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"t73f.de/r/webs/htmls"
+	"t73f.de/r/webs/htmls/render"
+)
+
+// flushRecorder records the data written between two flushes.
+type flushRecorder struct {
+	header  http.Header
+	chunks  []string
+	pending strings.Builder
+}
+
+func (fr *flushRecorder) Header() http.Header {
+	if fr.header == nil {
+		fr.header = http.Header{}
+	}
+	return fr.header
+}
+func (fr *flushRecorder) WriteHeader(int) {}
+func (fr *flushRecorder) Write(p []byte) (int, error) {
+	return fr.pending.Write(p)
+}
+func (fr *flushRecorder) Flush() {
+	fr.chunks = append(fr.chunks, fr.pending.String())
+	fr.pending.Reset()
+}
+
+// plainWriter is a ResponseWriter that is not able to flush.
+type plainWriter struct{ strings.Builder }
+
+func (*plainWriter) Header() http.Header { return http.Header{} }
+func (*plainWriter) WriteHeader(int)     {}
+
+func makeDocument(numRows int, extra ...*htmls.Node) *htmls.Node {
+	tbody := htmls.Elem("tbody", nil)
+	for i := range numRows {
+		tbody.AddChildren(htmls.Elem("tr", nil, htmls.Elem("td", nil, htmls.Text(strconv.Itoa(i)))))
+	}
+	tbody.AddChildren(extra...)
+	return htmls.Elem("html", nil,
+		htmls.Elem("head", nil, htmls.Elem("title", nil, htmls.Text("Report"))),
+		htmls.Elem("body", nil, htmls.Elem("table", nil, tbody)),
+	)
+}
+
+func TestStream(t *testing.T) {
+	doc := makeDocument(7)
+	var sb strings.Builder
+	if err := render.Render(&sb, doc); err != nil {
+		t.Fatal(err)
+	}
+	full := sb.String()
+
+	testcases := []struct {
+		name   string
+		opts   render.StreamOptions
+		suffix []string
+	}{
+		{"default", render.StreamOptions{}, []string{"</head>", "</html>"}},
+		{"no-head", render.StreamOptions{NoHeadFlush: true}, []string{"</html>"}},
+		{"chunks", render.StreamOptions{ChunkTags: []string{"tbody"}, ChunkSize: 3},
+			[]string{"</head>", "<td>2</td></tr>", "<td>5</td></tr>", "</html>"}},
+		{"exact-chunks", render.StreamOptions{ChunkTags: []string{"tbody"}, ChunkSize: 7},
+			[]string{"</head>", "</html>"}},
+		{"other-tag", render.StreamOptions{ChunkTags: []string{"ul"}, ChunkSize: 1},
+			[]string{"</head>", "</html>"}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var fr flushRecorder
+			if err := render.Stream(&fr, doc, tc.opts); err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Join(fr.chunks, ""); got != full || fr.pending.Len() > 0 {
+				t.Errorf("expected output %q, but got %q (pending %q)", full, got, fr.pending.String())
+			}
+			if len(fr.chunks) != len(tc.suffix) {
+				t.Fatalf("%d flushes expected, but got %d: %q", len(tc.suffix), len(fr.chunks), fr.chunks)
+			}
+			for i, chunk := range fr.chunks {
+				if !strings.HasSuffix(chunk, tc.suffix[i]) {
+					t.Errorf("chunk %d should end with %q, but is %q", i, tc.suffix[i], chunk)
+				}
+			}
+		})
+	}
+}
+
+func TestStreamError(t *testing.T) {
+	doc := makeDocument(4, htmls.Elem("br", nil, htmls.Text("error")))
+	var fr flushRecorder
+	err := render.Stream(&fr, doc, render.StreamOptions{ChunkTags: []string{"tbody"}, ChunkSize: 3})
+	if err == nil {
+		t.Fatal("error expected")
+	}
+	if len(fr.chunks) != 2 {
+		t.Fatalf("2 flushes expected, but got %d: %q", len(fr.chunks), fr.chunks)
+	}
+	if last := fr.chunks[1]; !strings.HasSuffix(last, "<td>2</td></tr>") {
+		t.Errorf("last flushed chunk should end with third row, but is %q", last)
+	}
+	if got := fr.pending.String(); got != "" {
+		t.Errorf("output after last flush point must be discarded, but got %q", got)
+	}
+}
+
+func TestStreamWithoutFlusher(t *testing.T) {
+	var pw plainWriter
+	if err := render.Stream(&pw, makeDocument(2), render.StreamOptions{ChunkTags: []string{"tbody"}, ChunkSize: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if got := pw.String(); !strings.HasSuffix(got, "</html>") {
+		t.Errorf("incomplete output: %q", got)
+	}
+}
+
+// firstFlushWriter records the time of the first flush, i.e. the time when
+// a client would receive the first bytes.
+type firstFlushWriter struct {
+	plainWriter
+	start, first time.Time
+}
+
+func (fw *firstFlushWriter) Flush() {
+	if fw.first.IsZero() {
+		fw.first = time.Now()
+	}
+}
+
+func BenchmarkFirstFlush(b *testing.B) {
+	doc := makeDocument(20_000)
+	b.Run("render", func(b *testing.B) {
+		var total time.Duration
+		for b.Loop() {
+			fw := firstFlushWriter{start: time.Now()}
+			_ = render.Render(&fw, doc)
+			fw.Flush() // the server flushes, when the handler returns
+			total += fw.first.Sub(fw.start)
+		}
+		b.ReportMetric(float64(total.Nanoseconds())/float64(b.N), "ns/first-flush")
+	})
+	b.Run("stream", func(b *testing.B) {
+		var total time.Duration
+		for b.Loop() {
+			fw := firstFlushWriter{start: time.Now()}
+			_ = render.Stream(&fw, doc, render.StreamOptions{ChunkTags: []string{"tbody"}})
+			total += fw.first.Sub(fw.start)
+		}
+		b.ReportMetric(float64(total.Nanoseconds())/float64(b.N), "ns/first-flush")
+	})
+}
@@ -36,3 +36,13 @@ func IsLiteralChildTextTag(tag string) bool {
 	}
 	return false
 }
+
+// IsWhitespaceSensitive returns true, if whitespace in children text nodes of
+// the given tag is significant and must not be changed.
+func IsWhitespaceSensitive(tag string) bool {
+	switch tag {
+	case "pre", "textarea", "listing":
+		return true
+	}
+	return IsLiteralChildTextTag(tag)
+}
@@ -0,0 +1,111 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2024-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2024-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package login
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultRealm is the default value of [Provider.Realm].
+const DefaultRealm = "Restricted"
+
+// RequiredBasic ensures a user authenticated by HTTP Basic authentication,
+// e.g. for API endpoints that cannot use the login cookie. The username and
+// password of the "Authorization" header are checked by the Authenticator of
+// the provider, subject to the same lockout after failed logins as
+// [Provider.Login], see [RateLimits].
+//
+// On success, the session is stored in the request context with an empty
+// SessionID, so that [Session] works in handlers. No session is created. A
+// request without valid credentials is answered with status code 401 and a
+// challenge for the realm [Provider.Realm]. Gates are not checked.
+func (lp *Provider) RequiredBasic(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok {
+			lp.unauthorized(w, "Basic", "")
+			return
+		}
+		if !lp.validateUsernamePassword(username, password) {
+			lp.logger.Info("invalid basic auth attempt")
+			lp.emit(r, EventLoginInvalid, "", nil)
+			lp.unauthorized(w, "Basic", "")
+			return
+		}
+		ctx := r.Context()
+		remote := clientHost(r)
+		if lp.limiter.isLocked(remote, username, time.Now()) {
+			lp.logger.InfoContext(ctx, "basic auth locked", "username", username, "remote", remote)
+			lp.emit(r, EventLoginLocked, username, nil)
+			lp.unauthorized(w, "Basic", "")
+			return
+		}
+		userinfo, err := lp.auth.Authenticate(ctx, username, password)
+		if err != nil {
+			lp.logger.InfoContext(ctx, "basic auth failed", "error", err)
+			lp.emit(r, EventLoginFailed, username, err)
+			if lp.limiter.fail(remote, username, time.Now()) {
+				lp.logger.WarnContext(ctx, "login lockout", "username", username, "remote", remote)
+			}
+			lp.unauthorized(w, "Basic", "")
+			return
+		}
+		lp.limiter.succeed(username)
+		next.ServeHTTP(w, r.WithContext(withSession(ctx, &SessionInfo{User: userinfo})))
+	})
+}
+
+// RequiredToken returns a middleware that ensures a client authenticated by
+// a static API token, e.g. for machine clients. The token is read like the
+// token of a [TokenAuthenticator], see [Provider.SetTokenAuthenticator], and
+// checked by the given lookup function. A failed lookup delays further
+// checks of all tokens sent from the same client host.
+//
+// On success, the session is stored in the request context, marked as
+// created by a token. Otherwise, the request is answered with status code
+// 401 and a challenge for the realm [Provider.Realm].
+func (lp *Provider) RequiredToken(lookup func(ctx context.Context, token string) (UserInfo, error)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, hasToken := lp.headerToken(r)
+			if !hasToken {
+				lp.unauthorized(w, "Bearer", "")
+				return
+			}
+			session, err := lp.checkTokenWith(r, token, lookup)
+			if err != nil {
+				lp.unauthorized(w, "Bearer", "invalid_token")
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(withSession(r.Context(), session)))
+		})
+	}
+}
+
+// unauthorized answers the request with status code 401 and a challenge of
+// the given authentication scheme.
+func (lp *Provider) unauthorized(w http.ResponseWriter, scheme, errCode string) {
+	challenge := scheme + " realm=" + strconv.Quote(lp.Realm)
+	if scheme == "Basic" {
+		challenge += `, charset="UTF-8"`
+	}
+	if errCode != "" {
+		challenge += ", error=" + strconv.Quote(errCode)
+	}
+	w.Header().Set("WWW-Authenticate", challenge)
+	http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+}
@@ -0,0 +1,121 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2024-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2024-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package login_test
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"t73f.de/r/webs/login"
+)
+
+func sessionHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session := login.Session(r.Context())
+		fmt.Fprintf(w, "%s %q %v", session.User.Name(), session.SessionID, session.ByToken)
+	})
+}
+
+func TestRequiredBasic(t *testing.T) {
+	lp := login.MakeProvider(slog.New(slog.DiscardHandler), &login.TestAuthenticator{}, &login.RAMSessions{}, &login.SimpleRedirector{})
+	lp.SetRateLimits(login.RateLimits{Window: time.Minute, MaxFailsUser: 2, Lockout: time.Minute})
+	lp.Realm = "API"
+	h := lp.RequiredBasic(sessionHandler())
+
+	testcases := []struct {
+		name     string
+		user, pw string // no header, if user is empty
+		expCode  int
+		expBody  string
+	}{
+		{"missing", "", "", http.StatusUnauthorized, ""},
+		{"wrong", "quinn", "wrong", http.StatusUnauthorized, ""},
+		{"invalid", "quinn", "", http.StatusUnauthorized, ""},
+		{"success", "alice", "pw", http.StatusOK, `alice "" false`},
+		{"repeated", "alice", "pw", http.StatusOK, `alice "" false`},
+		{"wrong-again", "quinn", "wrong", http.StatusUnauthorized, ""},
+		{"locked", "quinn", "quinn", http.StatusUnauthorized, ""},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api", nil)
+			if tc.user != "" {
+				r.SetBasicAuth(tc.user, tc.pw)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != tc.expCode {
+				t.Errorf("status %d expected, got %d", tc.expCode, w.Code)
+			}
+			if tc.expCode == http.StatusOK {
+				if got := w.Body.String(); got != tc.expBody {
+					t.Errorf("%q expected, got %q", tc.expBody, got)
+				}
+			} else if exp, got := `Basic realm="API", charset="UTF-8"`, w.Header().Get("WWW-Authenticate"); got != exp {
+				t.Errorf("challenge %q expected, got %q", exp, got)
+			}
+		})
+	}
+}
+
+func TestRequiredToken(t *testing.T) {
+	lp := login.MakeProvider(slog.New(slog.DiscardHandler), &login.TestAuthenticator{}, &login.RAMSessions{}, &login.SimpleRedirector{})
+	tokens := map[string]login.UserInfo{"s3cr3t": testUser("robot")}
+	h := lp.RequiredToken(func(_ context.Context, token string) (login.UserInfo, error) {
+		if user, found := tokens[token]; found {
+			return user, nil
+		}
+		return nil, login.ErrInvalidToken
+	})(sessionHandler())
+
+	testcases := []struct {
+		name      string
+		header    string
+		expCode   int
+		expHeader string
+	}{
+		{"missing", "", http.StatusUnauthorized, `Bearer realm="Restricted"`},
+		{"scheme", "Basic s3cr3t", http.StatusUnauthorized, `Bearer realm="Restricted"`},
+		{"wrong", "Bearer other", http.StatusUnauthorized, `Bearer realm="Restricted", error="invalid_token"`},
+		{"success", "Bearer s3cr3t", http.StatusOK, ""},
+	}
+	for i, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api", nil)
+			r.RemoteAddr = fmt.Sprintf("192.0.2.%d:1234", i+1) // failed lookups are rated per client
+			if tc.header != "" {
+				r.Header.Set("Authorization", tc.header)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != tc.expCode {
+				t.Errorf("status %d expected, got %d", tc.expCode, w.Code)
+			}
+			if got := w.Header().Get("WWW-Authenticate"); got != tc.expHeader {
+				t.Errorf("challenge %q expected, got %q", tc.expHeader, got)
+			}
+			if tc.expCode == http.StatusOK {
+				if got := w.Body.String(); !strings.HasPrefix(got, "robot ") || !strings.HasSuffix(got, " true") {
+					t.Errorf("token session of robot expected, got %q", got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2024-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2024-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package login
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// CookieCodec converts between the identifier of a session and the value of
+// the authentication cookie.
+type CookieCodec interface {
+	// Encode returns the cookie value for the given session identifier.
+	Encode(SessionID) string
+
+	// Decode returns the session identifier of the given cookie value, or an
+	// error, if the value was not produced by Encode.
+	Decode(string) (SessionID, error)
+}
+
+// SetCookieCodec sets the codec of the authentication cookie. It must be
+// called before the first request is served.
+//
+// By default, i.e. with a nil codec, the cookie contains a random value and
+// the session identifier is its hash value. A stolen session identifier can
+// therefore not be used as a cookie value. With a codec, the session
+// identifier is random and the cookie value is produced by the codec, e.g.
+// a signed identifier, see [HMACCodec]. Sessions created before the codec
+// was changed are no longer valid.
+func (lp *Provider) SetCookieCodec(cc CookieCodec) { lp.codec = cc }
+
+// MinHMACKeyLen is the minimum length of a key of a [HMACCodec].
+const MinHMACKeyLen = 32
+
+// maxCookieValueLen limits the length of a cookie value to be decoded.
+const maxCookieValueLen = 512
+
+// ErrCookieSignature is signaled, if a cookie value has no valid signature.
+var ErrCookieSignature = errors.New("invalid cookie signature")
+
+// HMACCodec is a [CookieCodec] that signs the session identifier with
+// HMAC-SHA256 and a server secret. Without the secret, a session identifier,
+// e.g. from a stolen session database, cannot be used as a cookie value.
+//
+// For key rotation, a codec may accept signatures of previous keys, while
+// it always signs with the current key.
+type HMACCodec struct {
+	keys [][]byte // current key first
+}
+
+// NewHMACCodec creates a codec that signs with the given key and accepts
+// signatures of the key and of the previous keys. All keys must be at least
+// [MinHMACKeyLen] bytes long.
+func NewHMACCodec(key []byte, previousKeys ...[]byte) (*HMACCodec, error) {
+	keys := make([][]byte, 0, 1+len(previousKeys))
+	for _, k := range append([][]byte{key}, previousKeys...) {
+		if len(k) < MinHMACKeyLen {
+			return nil, fmt.Errorf("HMAC key must have at least %d bytes, got %d", MinHMACKeyLen, len(k))
+		}
+		keys = append(keys, slices.Clone(k))
+	}
+	return &HMACCodec{keys: keys}, nil
+}
+
+// Encode returns the session identifier, followed by a dot and the signature
+// of the current key.
+func (hc *HMACCodec) Encode(sessid SessionID) string {
+	return string(sessid) + "." + base64.RawURLEncoding.EncodeToString(hc.sign(hc.keys[0], sessid))
+}
+
+// Decode returns the session identifier of the cookie value, if it was
+// signed by one of the keys.
+func (hc *HMACCodec) Decode(value string) (SessionID, error) {
+	if len(value) > maxCookieValueLen {
+		return "", ErrCookieSignature
+	}
+	id, sig, found := strings.Cut(value, ".")
+	if !found || id == "" {
+		return "", ErrCookieSignature
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil {
+		return "", ErrCookieSignature
+	}
+	sessid := SessionID(id)
+	for _, key := range hc.keys {
+		if hmac.Equal(mac, hc.sign(key, sessid)) {
+			return sessid, nil
+		}
+	}
+	return "", ErrCookieSignature
+}
+
+func (*HMACCodec) sign(key []byte, sessid SessionID) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(sessid))
+	return mac.Sum(nil)
+}
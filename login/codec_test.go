@@ -0,0 +1,123 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2024-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2024-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package login_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"t73f.de/r/webs/login"
+)
+
+var (
+	oldKey = bytes.Repeat([]byte{1}, login.MinHMACKeyLen)
+	newKey = bytes.Repeat([]byte{2}, login.MinHMACKeyLen)
+)
+
+func mustHMACCodec(t *testing.T, key []byte, previousKeys ...[]byte) *login.HMACCodec {
+	t.Helper()
+	hc, err := login.NewHMACCodec(key, previousKeys...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hc
+}
+
+func TestHMACCodec(t *testing.T) {
+	if _, err := login.NewHMACCodec(newKey, oldKey[:login.MinHMACKeyLen-1]); err == nil {
+		t.Error("short key must be rejected")
+	}
+
+	oldCodec := mustHMACCodec(t, oldKey)
+	rotated := mustHMACCodec(t, newKey, oldKey)
+	newCodec := mustHMACCodec(t, newKey)
+	oldValue := oldCodec.Encode("abc")
+	newValue := rotated.Encode("abc")
+	if oldValue == newValue || newValue != newCodec.Encode("abc") {
+		t.Errorf("rotated codec must sign with new key: %q %q", oldValue, newValue)
+	}
+
+	testcases := []struct {
+		name  string
+		codec *login.HMACCodec
+		value string
+		ok    bool
+	}{
+		{"same-key", oldCodec, oldValue, true},
+		{"rotated-old", rotated, oldValue, true},
+		{"rotated-new", rotated, newValue, true},
+		{"dropped-key", newCodec, oldValue, false},
+		{"unsigned", rotated, "abc", false},
+		{"empty-signature", rotated, "abc.", false},
+		{"empty-id", rotated, strings.TrimPrefix(newValue, "abc"), false},
+		{"other-id", rotated, "abd" + strings.TrimPrefix(newValue, "abc"), false},
+		{"bad-base64", rotated, "abc.!!!", false},
+		{"too-long", rotated, strings.Repeat("a", 1024) + ".x", false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			sessid, err := tc.codec.Decode(tc.value)
+			if tc.ok {
+				if err != nil || sessid != "abc" {
+					t.Errorf("abc expected, got %q / %v", sessid, err)
+				}
+			} else if !errors.Is(err, login.ErrCookieSignature) {
+				t.Errorf("ErrCookieSignature expected, got %q / %v", sessid, err)
+			}
+		})
+	}
+}
+
+func TestProviderCookieCodec(t *testing.T) {
+	sessions := &login.RAMSessions{}
+	lp := login.MakeProvider(slog.New(slog.DiscardHandler), &login.TestAuthenticator{}, sessions, &login.SimpleRedirector{})
+	lp.SetCookieCodec(mustHMACCodec(t, newKey))
+	h := lp.EnrichUserInfo(lp.Required(sessionHandler()))
+
+	cookie := loginCookie(t, lp, "alice")
+	if w := get(h, "/", cookie); w.Code != http.StatusOK || !strings.HasPrefix(w.Body.String(), "alice ") {
+		t.Fatalf("signed cookie must pass, got %d %q", w.Code, w.Body.String())
+	}
+	all, _ := sessions.ListSessions(context.Background(), nil)
+	if len(all) != 1 {
+		t.Fatalf("one session expected, got %v", all)
+	}
+	sessid := string(all[0].SessionID)
+	if !strings.HasPrefix(cookie.Value, sessid+".") {
+		t.Errorf("cookie %q must contain session identifier %q", cookie.Value, sessid)
+	}
+
+	for _, value := range []string{sessid, sessid + ".", mustHMACCodec(t, oldKey).Encode(all[0].SessionID)} {
+		if w := get(h, "/", &http.Cookie{Name: cookie.Name, Value: value}); w.Code == http.StatusOK {
+			t.Errorf("cookie %q must not pass", value)
+		}
+	}
+
+	// A minted session uses the codec too.
+	value, mintedID, err := lp.MintSession(context.Background(), testUser("bob"), login.DefaultMaxMintTTL, "support")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(value, string(mintedID)+".") {
+		t.Errorf("minted cookie %q must be signed", value)
+	}
+	if w := get(h, "/", &http.Cookie{Name: cookie.Name, Value: value}); w.Code != http.StatusOK {
+		t.Errorf("minted cookie must pass, got %d", w.Code)
+	}
+}
@@ -0,0 +1,173 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package login
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CookieSpec specifies the attributes of the authentication cookie. The
+// cookie is always sent with the HttpOnly attribute.
+type CookieSpec struct {
+	Name     string        // default: "auth"
+	Path     string        // default: "/"
+	Domain   string        // default: no Domain attribute
+	MaxAge   int           // in seconds, default: 366 days
+	Secure   bool          // send cookie only via HTTPS
+	SameSite http.SameSite // default: http.SameSiteLaxMode
+}
+
+// Defaults for a CookieSpec.
+const (
+	DefaultCookieName   = "auth"
+	DefaultCookiePath   = "/"
+	DefaultCookieMaxAge = 366 * 24 * 3600
+)
+
+// Cookie name prefixes, that browsers treat specially.
+const (
+	HostCookiePrefix   = "__Host-"
+	SecureCookiePrefix = "__Secure-"
+)
+
+// ErrInvalidCookieSpec is returned, if a cookie specification results in a
+// cookie that browsers would reject.
+var ErrInvalidCookieSpec = errors.New("invalid cookie specification")
+
+// withDefaults returns the effective specification.
+func (cs CookieSpec) withDefaults() CookieSpec {
+	if cs.Name == "" {
+		cs.Name = DefaultCookieName
+	}
+	if cs.Path == "" {
+		cs.Path = DefaultCookiePath
+	}
+	if cs.MaxAge == 0 {
+		cs.MaxAge = DefaultCookieMaxAge
+	}
+	if cs.SameSite == 0 {
+		cs.SameSite = http.SameSiteLaxMode
+	}
+	return cs
+}
+
+// Validate checks the effective cookie specification for combinations that
+// browsers would silently drop:
+//
+//   - SameSite=None requires Secure.
+//   - The prefix "__Secure-" requires Secure.
+//   - The prefix "__Host-" requires Secure, Path=/, and no Domain.
+func (cs CookieSpec) Validate() error {
+	cs = cs.withDefaults()
+	if err := (&http.Cookie{Name: cs.Name, Path: cs.Path, Domain: cs.Domain}).Valid(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidCookieSpec, err)
+	}
+	if cs.MaxAge < 0 {
+		return fmt.Errorf("%w: negative max age %d", ErrInvalidCookieSpec, cs.MaxAge)
+	}
+	if cs.SameSite == http.SameSiteNoneMode && !cs.Secure {
+		return fmt.Errorf("%w: SameSite=None requires Secure", ErrInvalidCookieSpec)
+	}
+	if hasPrefixFold(cs.Name, SecureCookiePrefix) && !cs.Secure {
+		return fmt.Errorf("%w: prefix %s requires Secure", ErrInvalidCookieSpec, SecureCookiePrefix)
+	}
+	if hasPrefixFold(cs.Name, HostCookiePrefix) {
+		if !cs.Secure {
+			return fmt.Errorf("%w: prefix %s requires Secure", ErrInvalidCookieSpec, HostCookiePrefix)
+		}
+		if cs.Path != "/" {
+			return fmt.Errorf("%w: prefix %s requires Path=/, got %q", ErrInvalidCookieSpec, HostCookiePrefix, cs.Path)
+		}
+		if cs.Domain != "" {
+			return fmt.Errorf("%w: prefix %s forbids Domain, got %q", ErrInvalidCookieSpec, HostCookiePrefix, cs.Domain)
+		}
+	}
+	return nil
+}
+
+// hasPrefixFold reports whether s begins with prefix, ignoring case, as
+// browsers do for cookie prefixes.
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+// String returns the attributes of the specification, as they would appear
+// in a Set-Cookie header.
+func (cs CookieSpec) String() string {
+	var sb strings.Builder
+	sb.WriteString(cs.Name)
+	sb.WriteString("=...; Path=")
+	sb.WriteString(cs.Path)
+	if cs.Domain != "" {
+		sb.WriteString("; Domain=")
+		sb.WriteString(cs.Domain)
+	}
+	sb.WriteString("; Max-Age=")
+	sb.WriteString(strconv.Itoa(cs.MaxAge))
+	sb.WriteString("; HttpOnly")
+	if cs.Secure {
+		sb.WriteString("; Secure")
+	}
+	switch cs.SameSite {
+	case http.SameSiteLaxMode:
+		sb.WriteString("; SameSite=Lax")
+	case http.SameSiteStrictMode:
+		sb.WriteString("; SameSite=Strict")
+	case http.SameSiteNoneMode:
+		sb.WriteString("; SameSite=None")
+	}
+	return sb.String()
+}
+
+// MakeProviderWithCookie makes a new authenticator, like [MakeProvider], but
+// with the given specification of the authentication cookie. An error is
+// returned, if the specification is not valid, see [CookieSpec.Validate].
+func MakeProviderWithCookie(logger *slog.Logger, auth Authenticator, sess SessionManager, redir Redirector, cs CookieSpec) (*Provider, error) {
+	if err := cs.Validate(); err != nil {
+		return nil, err
+	}
+	lp := MakeProvider(logger, auth, sess, redir)
+	lp.cookie = cs.withDefaults()
+	return lp, nil
+}
+
+// CookieSpec returns the effective specification of the authentication
+// cookie.
+func (lp *Provider) CookieSpec() CookieSpec { return lp.cookie }
+
+func (lp *Provider) setAuthCookie(w http.ResponseWriter, value string) {
+	http.SetCookie(w, lp.makeCookie(value, lp.cookie.MaxAge))
+}
+
+func (lp *Provider) clearAuthCookie(w http.ResponseWriter) {
+	http.SetCookie(w, lp.makeCookie("", -1))
+}
+
+func (lp *Provider) makeCookie(value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     lp.cookie.Name,
+		Value:    value,
+		Path:     lp.cookie.Path,
+		Domain:   lp.cookie.Domain,
+		MaxAge:   maxAge,
+		Secure:   lp.cookie.Secure,
+		HttpOnly: true, // TODO: "false" possibly needed for htmx
+		SameSite: lp.cookie.SameSite,
+	}
+}
@@ -0,0 +1,121 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package login_test
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"t73f.de/r/webs/login"
+)
+
+func makeCookieProvider(cs login.CookieSpec) (*login.Provider, error) {
+	return login.MakeProviderWithCookie(
+		slog.New(slog.DiscardHandler),
+		&login.TestAuthenticator{},
+		&login.RAMSessions{},
+		&login.SimpleRedirector{},
+		cs,
+	)
+}
+
+func TestCookieSpecInvalid(t *testing.T) {
+	testcases := []struct {
+		name string
+		cs   login.CookieSpec
+	}{
+		{"none-insecure", login.CookieSpec{SameSite: http.SameSiteNoneMode}},
+		{"secure-prefix-insecure", login.CookieSpec{Name: "__Secure-auth"}},
+		{"host-prefix-insecure", login.CookieSpec{Name: "__Host-auth"}},
+		{"host-prefix-lowercase", login.CookieSpec{Name: "__host-auth"}},
+		{"host-prefix-path", login.CookieSpec{Name: "__Host-auth", Secure: true, Path: "/app"}},
+		{"host-prefix-domain", login.CookieSpec{Name: "__Host-auth", Secure: true, Domain: "example.org"}},
+		{"invalid-name", login.CookieSpec{Name: "a b"}},
+		{"negative-max-age", login.CookieSpec{MaxAge: -1}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			lp, err := makeCookieProvider(tc.cs)
+			if !errors.Is(err, login.ErrInvalidCookieSpec) {
+				t.Errorf("ErrInvalidCookieSpec expected, got %v", err)
+			}
+			if lp != nil {
+				t.Error("no provider expected")
+			}
+		})
+	}
+}
+
+func TestCookieSpecDefault(t *testing.T) {
+	lp := login.MakeProvider(
+		slog.New(slog.DiscardHandler),
+		&login.TestAuthenticator{},
+		&login.RAMSessions{},
+		&login.SimpleRedirector{},
+	)
+	exp := "auth=...; Path=/; Max-Age=31622400; HttpOnly; SameSite=Lax"
+	if got := lp.CookieSpec().String(); got != exp {
+		t.Errorf("expected %q, got %q", exp, got)
+	}
+}
+
+func TestCookieSpecSetCookie(t *testing.T) {
+	testcases := []struct {
+		name string
+		cs   login.CookieSpec
+		spec string
+		set  []string
+	}{
+		{"host", login.CookieSpec{Name: "__Host-auth", Secure: true, SameSite: http.SameSiteNoneMode},
+			"__Host-auth=...; Path=/; Max-Age=31622400; HttpOnly; Secure; SameSite=None",
+			[]string{"__Host-auth=", "; Path=/; Max-Age=31622400; HttpOnly; Secure; SameSite=None"}},
+		{"secure", login.CookieSpec{Name: "__Secure-auth", Secure: true, Path: "/app", Domain: "example.org", MaxAge: 3600, SameSite: http.SameSiteStrictMode},
+			"__Secure-auth=...; Path=/app; Domain=example.org; Max-Age=3600; HttpOnly; Secure; SameSite=Strict",
+			[]string{"__Secure-auth=", "; Path=/app; Domain=example.org; Max-Age=3600; HttpOnly; Secure; SameSite=Strict"}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			lp, err := makeCookieProvider(tc.cs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := lp.CookieSpec().String(); got != tc.spec {
+				t.Errorf("spec %q expected, got %q", tc.spec, got)
+			}
+
+			form := url.Values{lp.UsernameKey: {"user"}, lp.PasswordKey: {"user"}}
+			r := httptest.NewRequest(http.MethodPost, "/login/", strings.NewReader(form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+			lp.Login().ServeHTTP(w, r)
+			var found bool
+			for _, sc := range w.Result().Header.Values("Set-Cookie") {
+				if strings.HasPrefix(sc, tc.set[0]) && !strings.HasPrefix(sc, tc.set[0]+";") {
+					found = true
+					if !strings.HasSuffix(sc, tc.set[1]) {
+						t.Errorf("Set-Cookie %q does not end with %q", sc, tc.set[1])
+					}
+				}
+			}
+			if !found {
+				t.Errorf("no login cookie %q found", tc.set[0])
+			}
+		})
+	}
+}
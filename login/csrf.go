@@ -0,0 +1,122 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2024-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2024-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package login
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// CSRFCookieSuffix is appended to the name of the authentication cookie, to
+// name the cookie that stores the token against login CSRF.
+const CSRFCookieSuffix = "-csrf"
+
+// csrfMaxAge is the maximum age in seconds of the token cookie.
+const csrfMaxAge = 3600
+
+// csrfTokenLen is the length of an encoded token.
+const csrfTokenLen = 43
+
+// ErrLoginCSRF signals a login request that might be forged by another site.
+var ErrLoginCSRF = errors.New("possible login CSRF")
+
+// EnableLoginCSRF protects [Provider.Login] against login CSRF, i.e. a
+// forged request of another site that logs the user in with an account of
+// the attacker. It is disabled by default.
+//
+// When enabled, the login form must contain the token returned by
+// [Provider.LoginForm] as the value named in CSRFKey. It must match the
+// token cookie, which is set by LoginForm. In addition, the host of the
+// "Origin" header, or the "Referer" header, if there is no "Origin" header,
+// must be one of the allowed hosts. Without allowed hosts, it must be the
+// host of the request. Requests without both headers are accepted, if the
+// tokens match. Otherwise, the user is redirected to the login page.
+func (lp *Provider) EnableLoginCSRF(allowedHosts ...string) {
+	lp.csrf = true
+	lp.csrfHosts = slices.Clone(allowedHosts)
+}
+
+// LoginForm prepares a login form, if protection against login CSRF is
+// enabled, see [Provider.EnableLoginCSRF]. It sets the token cookie, if the
+// request has no valid one, and returns the token. It must be embedded as
+// a hidden value of the login form, named in CSRFKey. If the protection is
+// not enabled, no cookie is set and the empty string is returned.
+func (lp *Provider) LoginForm(w http.ResponseWriter, r *http.Request) string {
+	if !lp.csrf {
+		return ""
+	}
+	if token := lp.csrfCookie(r); token != "" {
+		return token
+	}
+	var buf [32]byte
+	_, _ = rand.Read(buf[:])
+	token := base64.RawURLEncoding.EncodeToString(buf[:])
+	http.SetCookie(w, lp.makeCSRFCookie(token, csrfMaxAge))
+	return token
+}
+
+func (lp *Provider) makeCSRFCookie(value string, maxAge int) *http.Cookie {
+	cookie := lp.makeCookie(value, maxAge)
+	cookie.Name += CSRFCookieSuffix
+	return cookie
+}
+
+// csrfCookie returns the token of the token cookie, or the empty string.
+func (lp *Provider) csrfCookie(r *http.Request) string {
+	cookie, err := r.Cookie(lp.cookie.Name + CSRFCookieSuffix)
+	if err != nil || len(cookie.Value) != csrfTokenLen {
+		return ""
+	}
+	return cookie.Value
+}
+
+// checkLoginCSRF returns an error, if protection against login CSRF is
+// enabled and the request might be forged.
+func (lp *Provider) checkLoginCSRF(r *http.Request) error {
+	if !lp.csrf {
+		return nil
+	}
+	if !lp.isAllowedOrigin(r) {
+		return ErrLoginCSRF
+	}
+	token := lp.csrfCookie(r)
+	if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(r.PostFormValue(lp.CSRFKey))) != 1 {
+		return ErrLoginCSRF
+	}
+	return nil
+}
+
+// isAllowedOrigin checks the "Origin" or "Referer" header of the request.
+func (lp *Provider) isAllowedOrigin(r *http.Request) bool {
+	source := r.Header.Get("Origin")
+	if source == "" {
+		if source = r.Header.Get("Referer"); source == "" {
+			return true
+		}
+	}
+	u, err := url.Parse(source)
+	if err != nil || u.Host == "" {
+		return false // e.g. "null"
+	}
+	if len(lp.csrfHosts) == 0 {
+		return strings.EqualFold(u.Host, r.Host)
+	}
+	return slices.ContainsFunc(lp.csrfHosts, func(host string) bool { return strings.EqualFold(u.Host, host) })
+}
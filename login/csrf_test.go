@@ -0,0 +1,130 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2024-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2024-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package login_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"t73f.de/r/webs/login"
+)
+
+func TestLoginCSRF(t *testing.T) {
+	newProvider := func(hosts ...string) *login.Provider {
+		lp := login.MakeProvider(slog.New(slog.DiscardHandler), &login.TestAuthenticator{}, &login.RAMSessions{}, &login.SimpleRedirector{})
+		lp.SetRateLimits(login.RateLimits{})
+		lp.EnableLoginCSRF(hosts...)
+		return lp
+	}
+	// loginForm returns the token and its cookie.
+	loginForm := func(lp *login.Provider) (string, *http.Cookie) {
+		w := httptest.NewRecorder()
+		token := lp.LoginForm(w, httptest.NewRequest(http.MethodGet, "/login/", nil))
+		cookies := w.Result().Cookies()
+		if len(cookies) != 1 || cookies[0].Name != login.DefaultCookieName+login.CSRFCookieSuffix || cookies[0].Value != token {
+			t.Fatalf("token cookie %q expected, got %v", token, cookies)
+		}
+		return token, cookies[0]
+	}
+	post := func(lp *login.Provider, token string, cookie *http.Cookie, header ...string) *httptest.ResponseRecorder {
+		form := url.Values{lp.UsernameKey: {"alice"}, lp.PasswordKey: {"pw"}}
+		if token != "" {
+			form.Set(lp.CSRFKey, token)
+		}
+		r := httptest.NewRequest(http.MethodPost, "/login/", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if cookie != nil {
+			r.AddCookie(cookie)
+		}
+		for i := 0; i+1 < len(header); i += 2 {
+			r.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		lp.Login().ServeHTTP(w, r)
+		return w
+	}
+	loggedIn := func(w *httptest.ResponseRecorder) bool {
+		for _, cookie := range w.Result().Cookies() {
+			if cookie.Name == login.DefaultCookieName && cookie.Value != "" {
+				return true
+			}
+		}
+		return false
+	}
+
+	testcases := []struct {
+		name     string
+		hosts    []string
+		token    string // "": no token, "-": the issued token
+		noCookie bool
+		header   []string
+		exp      bool
+	}{
+		{"token", nil, "-", false, nil, true},
+		{"no-token", nil, "", false, nil, false},
+		{"wrong-token", nil, "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA", false, nil, false},
+		{"no-cookie", nil, "-", true, nil, false},
+		{"same-origin", nil, "-", false, []string{"Origin", "http://example.com"}, true},
+		{"cross-origin", nil, "-", false, []string{"Origin", "https://evil.example"}, false},
+		{"null-origin", nil, "-", false, []string{"Origin", "null"}, false},
+		{"same-referer", nil, "-", false, []string{"Referer", "http://example.com/login/"}, true},
+		{"cross-referer", nil, "-", false, []string{"Referer", "https://evil.example/page"}, false},
+		{"origin-before-referer", nil, "-", false, []string{"Origin", "https://evil.example", "Referer", "http://example.com/"}, false},
+		{"allowed-host", []string{"login.example.com"}, "-", false, []string{"Origin", "https://LOGIN.example.com"}, true},
+		{"not-allowed-host", []string{"login.example.com"}, "-", false, []string{"Origin", "http://example.com"}, false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			lp := newProvider(tc.hosts...)
+			token, cookie := loginForm(lp)
+			if tc.token != "-" {
+				token = tc.token
+			}
+			if tc.noCookie {
+				cookie = nil
+			}
+			w := post(lp, token, cookie, tc.header...)
+			if got := loggedIn(w); got != tc.exp {
+				t.Errorf("login %v expected, got %v", tc.exp, got)
+			}
+		})
+	}
+
+	lp := newProvider()
+	token, cookie := loginForm(lp)
+	r := httptest.NewRequest(http.MethodGet, "/login/", nil)
+	r.AddCookie(cookie)
+	w := httptest.NewRecorder()
+	if got := lp.LoginForm(w, r); got != token || len(w.Result().Cookies()) != 0 {
+		t.Errorf("token %q must be reused, got %q, %v", token, got, w.Result().Cookies())
+	}
+	w = post(lp, token, cookie)
+	if cookies := w.Result().Cookies(); !loggedIn(w) || cookies[0].Name != cookie.Name || cookies[0].MaxAge >= 0 {
+		t.Errorf("token cookie must be removed after login, got %v", cookies)
+	}
+
+	// Without protection, no token is needed.
+	lp = login.MakeProvider(slog.New(slog.DiscardHandler), &login.TestAuthenticator{}, &login.RAMSessions{}, &login.SimpleRedirector{})
+	w = httptest.NewRecorder()
+	if token = lp.LoginForm(w, httptest.NewRequest(http.MethodGet, "/login/", nil)); token != "" || len(w.Result().Cookies()) != 0 {
+		t.Errorf("no token expected, got %q, %v", token, w.Result().Cookies())
+	}
+	if w = post(lp, "", nil, "Origin", "https://evil.example"); !loggedIn(w) {
+		t.Error("login without protection must succeed")
+	}
+}
@@ -0,0 +1,153 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package login
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"t73f.de/r/webs/ip"
+)
+
+// EventKind classifies an authentication event.
+type EventKind uint8
+
+// Constants for EventKind.
+const (
+	_ EventKind = iota
+
+	// EventLogin signals a successful login.
+	EventLogin
+
+	// EventLoginFailed signals a login with wrong username or password.
+	EventLoginFailed
+
+	// EventLoginInvalid signals a login with a malformed username or
+	// password, e.g. an empty one.
+	EventLoginInvalid
+
+	// EventLoginRated signals a login that was rejected, because another
+	// login of the same user is in progress.
+	EventLoginRated
+
+	// EventSessionFailed signals that no session could be created for an
+	// authenticated user.
+	EventSessionFailed
+
+	// EventLogout signals a logout.
+	EventLogout
+
+	// EventSessionMinted signals a session that was created without a login,
+	// see [Provider.MintSession].
+	EventSessionMinted
+
+	// EventSessionRevoked signals the removal of a minted session, see
+	// [Provider.RevokeMinted].
+	EventSessionRevoked
+
+	// EventLoginLocked signals a login that was rejected, because its client
+	// IP address or its username is locked out after too many failed
+	// logins, see [RateLimits].
+	EventLoginLocked
+
+	// EventImpersonate signals that a user started to impersonate another
+	// user, see [Provider.Impersonate]. Username is the real user.
+	EventImpersonate
+
+	// EventImpersonateEnd signals the end of an impersonation, see
+	// [Provider.StopImpersonation].
+	EventImpersonateEnd
+
+	// EventTokenFailed signals a request with an invalid or unknown API
+	// token, see [TokenAuthenticator].
+	EventTokenFailed
+
+	// EventTokenRated signals a token that was rejected without checking,
+	// because a failed token check of the same client host is too recent.
+	EventTokenRated
+
+	// EventTokenLocked signals a token that was rejected without checking,
+	// because its client IP address is locked out, see [RateLimits].
+	EventTokenLocked
+
+	numEventKinds = iota
+)
+
+var eventKindNames = [numEventKinds]string{
+	"", "login", "login-failed", "login-invalid", "login-rated", "session-failed", "logout",
+	"session-minted", "session-revoked", "login-locked",
+	"impersonate", "impersonate-end",
+	"token-failed", "token-rated", "token-locked",
+}
+
+// String returns a textual representation of the event kind.
+func (k EventKind) String() string {
+	if k < numEventKinds {
+		return eventKindNames[k]
+	}
+	return "unknown"
+}
+
+// Event describes an authentication event.
+type Event struct {
+	Kind       EventKind
+	Time       time.Time
+	Username   string // empty, if the username is not known or invalid
+	RemoteAddr string
+	Err        error  // cause of a failure, if any
+	Reason     string // reason of a minted or revoked session
+}
+
+// EventSink receives authentication events, e.g. to build statistics or an
+// audit log. It is called synchronously and must therefore return quickly.
+// It may be called concurrently.
+type EventSink interface {
+	Emit(context.Context, Event)
+}
+
+// EventSinkFunc is an EventSink inside a function.
+type EventSinkFunc func(context.Context, Event)
+
+// Emit the event.
+func (esf EventSinkFunc) Emit(ctx context.Context, ev Event) { esf(ctx, ev) }
+
+// SetEventSink sets the receiver of all authentication events. A nil sink
+// disables the events.
+func (lp *Provider) SetEventSink(sink EventSink) { lp.events = sink }
+
+// emit an event to the event sink, if one was set.
+func (lp *Provider) emit(r *http.Request, kind EventKind, username string, err error) {
+	if sink := lp.events; sink != nil {
+		sink.Emit(r.Context(), Event{
+			Kind:       kind,
+			Time:       time.Now(),
+			Username:   username,
+			RemoteAddr: ip.GetRemoteAddr(r),
+			Err:        err,
+		})
+	}
+}
+
+// emitReason emits an event without a request, e.g. of a minted session.
+func (lp *Provider) emitReason(ctx context.Context, kind EventKind, username, reason string) {
+	if sink := lp.events; sink != nil {
+		sink.Emit(ctx, Event{
+			Kind:     kind,
+			Time:     time.Now(),
+			Username: username,
+			Reason:   reason,
+		})
+	}
+}
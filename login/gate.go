@@ -0,0 +1,200 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2024-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2024-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package login
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Gate is a check that an authenticated user must pass before continuing,
+// e.g. the acceptance of updated terms of service.
+type Gate interface {
+	// Passed returns true, if the user passed the gate.
+	Passed(ctx context.Context, user UserInfo) (bool, error)
+
+	// RedirectTarget returns the URL of the page, where the user is able to
+	// pass the gate.
+	RedirectTarget(r *http.Request) string
+}
+
+// AddGate registers a gate, which is checked by [Provider.Required]. Gates
+// are checked in the order of their registration. All gates must be added
+// before the first request is served.
+func (lp *Provider) AddGate(g Gate) { lp.gates = append(lp.gates, g) }
+
+// ExemptFromGates lists paths that are not checked by gates, typically the
+// pages of the gates themselves and static resources needed by them. A path
+// ending with a slash exempts all paths with this prefix.
+//
+// The path of the redirect target of a failing gate is always exempt from
+// this gate, to avoid redirect loops.
+func (lp *Provider) ExemptFromGates(paths ...string) { lp.exempt = append(lp.exempt, paths...) }
+
+// RequiredWithGates works like [Provider.Required], but checks the given
+// gates after the registered ones, e.g. for an area with additional
+// requirements.
+func (lp *Provider) RequiredWithGates(gates ...Gate) func(http.Handler) http.Handler {
+	gates = slices.Clone(gates)
+	return func(next http.Handler) http.Handler { return lp.required(next, gates, lp.requiredRedirect) }
+}
+
+// checkGates checks the registered gates, followed by the extra gates. It
+// returns true, if the request may continue. Otherwise the
+// response was already written, typically a redirect to the first failing
+// gate. Sessions created by an API token are not checked, since they do not
+// belong to a human user, who could pass a gate.
+func (lp *Provider) checkGates(w http.ResponseWriter, r *http.Request, session *SessionInfo, extraGates []Gate) bool {
+	if (len(lp.gates) == 0 && len(extraGates) == 0) || session.ByToken || lp.isExemptFromGates(r.URL.Path) {
+		return true
+	}
+	ctx := r.Context()
+	for _, g := range slices.Concat(lp.gates, extraGates) {
+		passed, err := g.Passed(ctx, session.User)
+		if err != nil {
+			lp.logger.ErrorContext(ctx, "gate", "user", session.User.Name(), "error", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return false
+		}
+		if passed {
+			continue
+		}
+		target := g.RedirectTarget(r)
+		if u, err := url.Parse(target); err == nil && u.Path == r.URL.Path {
+			continue
+		}
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			http.SetCookie(w, lp.makeResumeCookie(r.URL.RequestURI(), resumeMaxAge))
+		}
+		http.Redirect(w, r, target, http.StatusSeeOther)
+		return false
+	}
+	return true
+}
+
+func (lp *Provider) isExemptFromGates(path string) bool {
+	for _, exempt := range lp.exempt {
+		if path == exempt || (strings.HasSuffix(exempt, "/") && strings.HasPrefix(path, exempt)) {
+			return true
+		}
+	}
+	return false
+}
+
+// resumeMaxAge is the maximum age in seconds of the cookie that stores the
+// originally requested URL.
+const resumeMaxAge = 3600
+
+// ResumeCookieSuffix is appended to the name of the authentication cookie,
+// to name the cookie that stores the originally requested URL, while a gate
+// is passed.
+const ResumeCookieSuffix = "-resume"
+
+func (lp *Provider) makeResumeCookie(value string, maxAge int) *http.Cookie {
+	cookie := lp.makeCookie(value, maxAge)
+	cookie.Name += ResumeCookieSuffix
+	return cookie
+}
+
+// ResumeAfterGate redirects the user to the URL that was originally
+// requested, before a gate redirected to its page. It is typically called
+// by the handler of a gate's page, after the user passed the gate. If there
+// is no such URL, the success redirect of the [Redirector] is used.
+func (lp *Provider) ResumeAfterGate(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie(lp.cookie.Name + ResumeCookieSuffix)
+	if err == nil {
+		http.SetCookie(w, lp.makeResumeCookie("", -1))
+		if target := cookie.Value; isLocalURL(target) {
+			http.Redirect(w, r, target, http.StatusSeeOther)
+			return
+		}
+	}
+	var user UserInfo
+	if session := Session(r.Context()); session != nil {
+		user = session.User
+	}
+	lp.redir.SuccessRedirect(w, r, user)
+}
+
+// isLocalURL returns true, if the URL refers to a path of the same site.
+func isLocalURL(target string) bool {
+	if !strings.HasPrefix(target, "/") || strings.HasPrefix(target, "//") || strings.HasPrefix(target, "/\\") {
+		return false
+	}
+	u, err := url.Parse(target)
+	return err == nil && u.Scheme == "" && u.Host == ""
+}
+
+// ----- Acceptance of terms
+
+// AcceptanceStore stores the version of the terms that a user accepted.
+type AcceptanceStore interface {
+	// AcceptedVersion returns the version of the terms the user accepted
+	// last, or the empty string, if the user never accepted them.
+	AcceptedVersion(ctx context.Context, user UserInfo) (string, error)
+
+	// Accept stores the acceptance of the given version of the terms.
+	Accept(ctx context.Context, user UserInfo, version string) error
+}
+
+// TermsGate is a gate that requires the acceptance of the current version
+// of some terms, e.g. the terms of service.
+type TermsGate struct {
+	Version string          // current version of the terms
+	Store   AcceptanceStore // accepted versions
+	URL     string          // page that shows the terms
+}
+
+// Passed returns true, if the user accepted the current version.
+func (tg *TermsGate) Passed(ctx context.Context, user UserInfo) (bool, error) {
+	version, err := tg.Store.AcceptedVersion(ctx, user)
+	return err == nil && version == tg.Version, err
+}
+
+// RedirectTarget returns the URL of the terms page.
+func (tg *TermsGate) RedirectTarget(*http.Request) string { return tg.URL }
+
+// Accept stores that the user accepted the current version of the terms.
+func (tg *TermsGate) Accept(ctx context.Context, user UserInfo) error {
+	return tg.Store.Accept(ctx, user, tg.Version)
+}
+
+// RAMAcceptances is an AcceptanceStore that stores the accepted versions in
+// main memory, indexed by user name.
+type RAMAcceptances struct {
+	mx       sync.Mutex
+	versions map[string]string
+}
+
+// AcceptedVersion returns the version the user accepted last.
+func (ra *RAMAcceptances) AcceptedVersion(_ context.Context, user UserInfo) (string, error) {
+	ra.mx.Lock()
+	defer ra.mx.Unlock()
+	return ra.versions[user.Name()], nil
+}
+
+// Accept stores the accepted version.
+func (ra *RAMAcceptances) Accept(_ context.Context, user UserInfo, version string) error {
+	ra.mx.Lock()
+	defer ra.mx.Unlock()
+	if ra.versions == nil {
+		ra.versions = map[string]string{}
+	}
+	ra.versions[user.Name()] = version
+	return nil
+}
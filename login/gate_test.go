@@ -0,0 +1,220 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2024-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2024-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package login_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"t73f.de/r/webs/login"
+)
+
+// flagGate is a gate that is passed, if its flag is set.
+type flagGate struct {
+	passed bool
+	target string
+	err    error
+}
+
+func (fg *flagGate) Passed(context.Context, login.UserInfo) (bool, error) { return fg.passed, fg.err }
+func (fg *flagGate) RedirectTarget(*http.Request) string                  { return fg.target }
+
+func newGateProvider(t *testing.T) (*login.Provider, *login.RAMTokens, *http.ServeMux) {
+	t.Helper()
+	lp := login.MakeProvider(
+		slog.New(slog.DiscardHandler),
+		&login.TestAuthenticator{},
+		&login.RAMSessions{},
+		&login.SimpleRedirector{SuccessURL: "/home"},
+	)
+	tokens := &login.RAMTokens{}
+	lp.SetTokenAuthenticator(tokens)
+	mux := http.NewServeMux()
+	mux.Handle("/", lp.EnrichUserInfo(lp.Required(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s %s", login.Session(r.Context()).User.Name(), r.URL.Path)
+	}))))
+	return lp, tokens, mux
+}
+
+func get(h http.Handler, target string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, target, nil)
+	for _, cookie := range cookies {
+		r.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func resumeCookie(w *httptest.ResponseRecorder) *http.Cookie {
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == login.DefaultCookieName+login.ResumeCookieSuffix {
+			return cookie
+		}
+	}
+	return nil
+}
+
+func TestGateOrder(t *testing.T) {
+	lp, _, mux := newGateProvider(t)
+	first := &flagGate{target: "/first"}
+	second := &flagGate{target: "/second"}
+	lp.AddGate(first)
+	lp.AddGate(second)
+	cookie := loginCookie(t, lp, "alice")
+
+	for _, step := range []struct {
+		pass     *flagGate
+		expCode  int
+		expWhere string
+	}{
+		{nil, http.StatusSeeOther, "/first"},
+		{first, http.StatusSeeOther, "/second"},
+		{second, http.StatusOK, ""},
+	} {
+		if step.pass != nil {
+			step.pass.passed = true
+		}
+		w := get(mux, "/data", cookie)
+		if w.Code != step.expCode {
+			t.Fatalf("status %d expected, got %d", step.expCode, w.Code)
+		}
+		if got := w.Header().Get("Location"); got != step.expWhere {
+			t.Errorf("redirect to %q expected, got %q", step.expWhere, got)
+		}
+	}
+}
+
+func TestGateResume(t *testing.T) {
+	lp, _, mux := newGateProvider(t)
+	gate := &login.TermsGate{Version: "2025-01", Store: &login.RAMAcceptances{}, URL: "/terms"}
+	lp.AddGate(gate)
+	mux.Handle("POST /terms", lp.EnrichUserInfo(lp.Required(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := gate.Accept(r.Context(), login.Session(r.Context()).User); err != nil {
+			t.Fatal(err)
+		}
+		lp.ResumeAfterGate(w, r)
+	}))))
+	cookie := loginCookie(t, lp, "bob")
+
+	w := get(mux, "/private/report?year=2025", cookie)
+	if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/terms" {
+		t.Fatalf("redirect to /terms expected, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+	resume := resumeCookie(w)
+	if resume == nil || resume.Value != "/private/report?year=2025" {
+		t.Fatalf("resume cookie with original URL expected, got %v", resume)
+	}
+
+	// The terms page itself is not gated.
+	if w = get(mux, "/terms", cookie); w.Code != http.StatusOK {
+		t.Errorf("terms page must be reachable, got status %d", w.Code)
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/terms", nil)
+	r.AddCookie(cookie)
+	r.AddCookie(resume)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	if got := w.Header().Get("Location"); w.Code != http.StatusSeeOther || got != "/private/report?year=2025" {
+		t.Fatalf("redirect to original URL expected, got %d %q", w.Code, got)
+	}
+	if cleared := resumeCookie(w); cleared == nil || cleared.MaxAge >= 0 {
+		t.Errorf("resume cookie must be cleared, got %v", cleared)
+	}
+
+	if w = get(mux, "/private/report?year=2025", cookie); w.Code != http.StatusOK || w.Body.String() != "bob /private/report" {
+		t.Errorf("access after acceptance expected, got %d %q", w.Code, w.Body.String())
+	}
+
+	// A new version of the terms must be accepted again.
+	gate.Version = "2025-06"
+	if w = get(mux, "/data", cookie); w.Code != http.StatusSeeOther {
+		t.Errorf("redirect for new version expected, got %d", w.Code)
+	}
+}
+
+func TestGateResumeWithoutCookie(t *testing.T) {
+	lp, _, _ := newGateProvider(t)
+	for _, target := range []string{"", "https://evil.example/", "//evil.example/", "javascript:alert(1)"} {
+		r := httptest.NewRequest(http.MethodGet, "/terms", nil)
+		if target != "" {
+			r.AddCookie(&http.Cookie{Name: login.DefaultCookieName + login.ResumeCookieSuffix, Value: target})
+		}
+		w := httptest.NewRecorder()
+		lp.ResumeAfterGate(w, r)
+		if got := w.Header().Get("Location"); got != "/home" {
+			t.Errorf("%q: success redirect expected, got %q", target, got)
+		}
+	}
+}
+
+func TestGateExemptions(t *testing.T) {
+	lp, tokens, mux := newGateProvider(t)
+	lp.AddGate(&flagGate{target: "/terms"})
+	lp.ExemptFromGates("/static/", "/imprint")
+	cookie := loginCookie(t, lp, "carol")
+
+	for _, tc := range []struct {
+		path    string
+		expCode int
+	}{
+		{"/static/app.css", http.StatusOK},
+		{"/static/", http.StatusOK},
+		{"/imprint", http.StatusOK},
+		{"/imprint/more", http.StatusSeeOther},
+		{"/staticx", http.StatusSeeOther},
+		{"/terms", http.StatusOK},
+	} {
+		if w := get(mux, tc.path, cookie); w.Code != tc.expCode {
+			t.Errorf("%s: status %d expected, got %d", tc.path, tc.expCode, w.Code)
+		}
+	}
+
+	// API tokens are not gated.
+	token, err := tokens.Create(testUser("robot"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := httptest.NewRequest(http.MethodGet, "/data", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Errorf("token request must not be gated, got %d", w.Code)
+	}
+}
+
+func TestRequiredWithGates(t *testing.T) {
+	lp, _, mux := newGateProvider(t)
+	admin := &flagGate{target: "/mfa"}
+	mux.Handle("/admin/", lp.EnrichUserInfo(lp.RequiredWithGates(admin)(http.NotFoundHandler())))
+	cookie := loginCookie(t, lp, "dave")
+
+	if w := get(mux, "/data", cookie); w.Code != http.StatusOK {
+		t.Errorf("ungated area expected, got %d", w.Code)
+	}
+	if w := get(mux, "/admin/", cookie); w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/mfa" {
+		t.Errorf("redirect to /mfa expected, got %d", w.Code)
+	}
+	admin.err = errors.New("store failed")
+	if w := get(mux, "/admin/", cookie); w.Code != http.StatusInternalServerError {
+		t.Errorf("status 500 expected, got %d", w.Code)
+	}
+}
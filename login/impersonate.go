@@ -0,0 +1,154 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2024-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2024-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package login
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// ErrNotImpersonating is signaled, if a session does not impersonate a user.
+var ErrNotImpersonating = errors.New("session does not impersonate a user")
+
+// Impersonator returns the real user of a session, which impersonates
+// another user, see [Provider.Impersonate]. Otherwise, nil is returned.
+func (si *SessionInfo) Impersonator() UserInfo {
+	if si == nil || si.ActingAs == nil {
+		return nil
+	}
+	return si.impersonator
+}
+
+// impersonation is stored in the session manager as the user of a session,
+// which impersonates another user. Its name is the name of the real user,
+// so that a session manager, which only stores the name, falls back to the
+// real user.
+type impersonation struct {
+	real   UserInfo
+	target UserInfo
+}
+
+func (imp *impersonation) Name() string { return imp.real.Name() }
+
+// makeSessionInfo returns the session data of a user stored in the session
+// manager.
+func makeSessionInfo(sessid SessionID, userinfo UserInfo) *SessionInfo {
+	if imp, ok := userinfo.(*impersonation); ok {
+		return &SessionInfo{SessionID: sessid, User: imp.target, ActingAs: imp.target, impersonator: imp.real}
+	}
+	return &SessionInfo{SessionID: sessid, User: userinfo}
+}
+
+// userAttrs returns the attributes to log the user of a session. An
+// impersonated user is logged together with the real user.
+func userAttrs(userinfo UserInfo) []any {
+	if imp, ok := userinfo.(*impersonation); ok {
+		return []any{"user", imp.real.Name(), "acting-as", imp.target.Name()}
+	}
+	return []any{"user", userinfo.Name()}
+}
+
+// Impersonate lets the logged-in user of the request act as the target
+// user, e.g. for support staff that need to view the site as the target
+// user. The check, whether the user is allowed to do this, is left to the
+// caller.
+//
+// The session is replaced by a new one, whose cookie is set. In this
+// session, [Session] returns the target user as SessionInfo.User and as
+// SessionInfo.ActingAs, while [SessionInfo.Impersonator] returns the real
+// user. Logins, logouts, and the impersonation itself are logged with both
+// identities. If the session already impersonates a user, the real user
+// now impersonates the target user. See [Provider.StopImpersonation] to
+// revert.
+//
+// The session manager must return the user information exactly as it was
+// stored. A session manager, which rebuilds it from the user name, returns
+// the real user.
+func (lp *Provider) Impersonate(w http.ResponseWriter, r *http.Request, target UserInfo) error {
+	if target == nil {
+		return errors.New("no user to impersonate")
+	}
+	userinfo, sessid, err := lp.checkCookie(r)
+	if err != nil {
+		return err
+	}
+	realUser := userinfo
+	if imp, ok := userinfo.(*impersonation); ok {
+		realUser = imp.real
+	}
+	imp := &impersonation{real: realUser, target: target}
+	if err = lp.replaceSession(w, r, sessid, imp); err != nil {
+		return err
+	}
+	lp.logger.InfoContext(r.Context(), "Impersonate", userAttrs(imp)...)
+	lp.emit(r, EventImpersonate, realUser.Name(), nil)
+	return nil
+}
+
+// StopImpersonation reverts [Provider.Impersonate]: the session is replaced
+// by a new session of the real user, whose cookie is set. If the session
+// does not impersonate a user, [ErrNotImpersonating] is returned.
+func (lp *Provider) StopImpersonation(w http.ResponseWriter, r *http.Request) error {
+	userinfo, sessid, err := lp.checkCookie(r)
+	if err != nil {
+		return err
+	}
+	imp, ok := userinfo.(*impersonation)
+	if !ok {
+		return ErrNotImpersonating
+	}
+	if err = lp.replaceSession(w, r, sessid, imp.real); err != nil {
+		return err
+	}
+	lp.logger.InfoContext(r.Context(), "Stop impersonation", userAttrs(imp)...)
+	lp.emit(r, EventImpersonateEnd, imp.real.Name(), nil)
+	return nil
+}
+
+// replaceSession removes the given session and creates a new one for the
+// user. If the session manager stores metadata, the new session keeps the
+// expiry time and the mint data of the old one, e.g. a minted session stays
+// short-lived and can still be revoked by [Provider.RevokeMinted].
+func (lp *Provider) replaceSession(w http.ResponseWriter, r *http.Request, sessid SessionID, userinfo UserInfo) error {
+	ctx := r.Context()
+	auth, newID := lp.newSession()
+	if err := lp.setReplacedSession(ctx, sessid, userinfo, newID); err != nil {
+		lp.logger.ErrorContext(ctx, "set-session", "error", err)
+		return err
+	}
+	if err := lp.sess.Remove(ctx, sessid); err != nil {
+		lp.logger.ErrorContext(ctx, "unable to remove auth", "error", err)
+	}
+	lp.setAuthCookie(w, auth)
+	return nil
+}
+
+func (lp *Provider) setReplacedSession(ctx context.Context, sessid SessionID, userinfo UserInfo, newID SessionID) error {
+	msm, ok := lp.sess.(MetaSessionManager)
+	if !ok {
+		return lp.sess.SetUserAuth(ctx, userinfo, newID)
+	}
+	entries, err := msm.ListSessions(ctx, func(se SessionEntry) bool { return se.SessionID == sessid })
+	if err != nil {
+		return err
+	}
+	meta := SessionMeta{Created: time.Now()}
+	if len(entries) > 0 {
+		old := entries[0].Meta
+		meta.Expires, meta.Minted, meta.Reason = old.Expires, old.Minted, old.Reason
+	}
+	return msm.SetUserAuthMeta(ctx, userinfo, newID, meta)
+}
@@ -0,0 +1,157 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2024-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2024-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package login_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"t73f.de/r/webs/login"
+)
+
+func TestImpersonate(t *testing.T) {
+	var logs strings.Builder
+	lp := login.MakeProvider(slog.New(slog.NewTextHandler(&logs, nil)), &login.TestAuthenticator{}, login.NewMemorySessionManager(0, 0), &login.SimpleRedirector{})
+	h := lp.EnrichUserInfo(lp.Required(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session := login.Session(r.Context())
+		impersonator := "-"
+		if user := session.Impersonator(); user != nil {
+			impersonator = user.Name()
+		}
+		fmt.Fprintf(w, "%s %v %s", session.User.Name(), session.ActingAs != nil, impersonator)
+	})))
+	// call calls fn with a request with the given cookie and returns the new
+	// cookie.
+	call := func(cookie *http.Cookie, fn func(http.ResponseWriter, *http.Request) error) (*http.Cookie, error) {
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		r.AddCookie(cookie)
+		w := httptest.NewRecorder()
+		if err := fn(w, r); err != nil {
+			return nil, err
+		}
+		return w.Result().Cookies()[0], nil
+	}
+	impersonate := func(name string) func(http.ResponseWriter, *http.Request) error {
+		return func(w http.ResponseWriter, r *http.Request) error { return lp.Impersonate(w, r, testUser(name)) }
+	}
+	expectUser := func(cookie *http.Cookie, exp string) {
+		t.Helper()
+		if w := get(h, "/", cookie); w.Code != http.StatusOK || w.Body.String() != exp {
+			t.Errorf("%q expected, got %d %q", exp, w.Code, w.Body.String())
+		}
+	}
+
+	admin := loginCookie(t, lp, "admin")
+	expectUser(admin, "admin false -")
+	if _, err := call(admin, lp.StopImpersonation); !errors.Is(err, login.ErrNotImpersonating) {
+		t.Errorf("ErrNotImpersonating expected, got %v", err)
+	}
+
+	asBob, err := call(admin, impersonate("bob"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectUser(asBob, "bob true admin")
+	if w := get(h, "/", admin); w.Code == http.StatusOK {
+		t.Error("session before impersonation must be replaced")
+	}
+
+	// A new impersonation keeps the real user.
+	asCarol, err := call(asBob, impersonate("carol"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectUser(asCarol, "carol true admin")
+
+	back, err := call(asCarol, lp.StopImpersonation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectUser(back, "admin false -")
+	if w := get(h, "/", asCarol); w.Code == http.StatusOK {
+		t.Error("impersonating session must be replaced")
+	}
+
+	asBob, err = call(back, impersonate("bob"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	get(lp.Logout(), "/logout", asBob)
+	for _, exp := range []string{
+		`msg=Impersonate user=admin acting-as=bob`,
+		`msg=Impersonate user=admin acting-as=carol`,
+		`msg="Stop impersonation" user=admin acting-as=carol`,
+		`msg=Logout user=admin acting-as=bob`,
+	} {
+		if !strings.Contains(logs.String(), exp) {
+			t.Errorf("%q expected in log:\n%s", exp, logs.String())
+		}
+	}
+	if w := get(h, "/", asBob); w.Code == http.StatusOK {
+		t.Error("logout must end the impersonation")
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	if err = lp.Impersonate(httptest.NewRecorder(), r, testUser("bob")); err == nil {
+		t.Error("impersonation without session must fail")
+	}
+}
+
+func TestImpersonateMinted(t *testing.T) {
+	sessions := &login.RAMSessions{}
+	lp := login.MakeProvider(slog.New(slog.DiscardHandler), &login.TestAuthenticator{}, sessions, &login.SimpleRedirector{})
+	ctx := context.Background()
+	value, _, err := lp.MintSession(ctx, testUser("support"), 10*time.Minute, "ticket 42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	call := func(value string, fn func(http.ResponseWriter, *http.Request) error) string {
+		t.Helper()
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		r.AddCookie(&http.Cookie{Name: login.DefaultCookieName, Value: value})
+		w := httptest.NewRecorder()
+		if err = fn(w, r); err != nil {
+			t.Fatal(err)
+		}
+		return w.Result().Cookies()[0].Value
+	}
+	expectMinted := func(user string) {
+		t.Helper()
+		minted, _ := sessions.ListSessions(ctx, login.IsMinted)
+		if len(minted) != 1 || minted[0].User.Name() != user || minted[0].Meta.Reason != "ticket 42" ||
+			minted[0].Meta.Expires.Sub(minted[0].Meta.Created) > 10*time.Minute {
+			t.Errorf("one short-lived minted session of %q expected, got %v", user, minted)
+		}
+	}
+
+	value = call(value, func(w http.ResponseWriter, r *http.Request) error { return lp.Impersonate(w, r, testUser("bob")) })
+	expectMinted("support")
+	value = call(value, lp.StopImpersonation)
+	expectMinted("support")
+	_ = call(value, func(w http.ResponseWriter, r *http.Request) error { return lp.Impersonate(w, r, testUser("carol")) })
+
+	if count, err := lp.RevokeMinted(ctx); err != nil || count != 1 {
+		t.Errorf("one revoked session expected, got %d / %v", count, err)
+	}
+	if all, _ := sessions.ListSessions(ctx, nil); len(all) != 0 {
+		t.Errorf("no session expected, got %v", all)
+	}
+}
@@ -29,6 +29,7 @@ import (
 	"time"
 	"unicode/utf8"
 
+	"t73f.de/r/webs/middleware/contextinfo"
 	"t73f.de/r/zero/contexts"
 )
 
@@ -39,24 +40,40 @@ type Provider struct {
 	auth   Authenticator
 	sess   SessionManager
 	redir  Redirector
+	tokens TokenAuthenticator
+	gates  []Gate
+	exempt []string // paths that are not checked by gates
+	events EventSink
+	codec  CookieCodec // nil: cookie value is hashed to the session identifier
 
-	PassLen      int // max length of username and password
-	authlen      int // max length of cookie value
-	cookiePath   string
-	maxCookieAge int
-	secureCookie bool
+	csrf      bool     // login CSRF protection enabled
+	csrfHosts []string // allowed hosts of Origin / Referer headers
+	returnTo  bool     // return to the requested URL after login
+
+	PassLen int // max length of username and password
+	authlen int // max length of cookie value
+	cookie  CookieSpec
 
 	UsernameKey string
 	PasswordKey string
-	cookieName  string
+	CSRFKey     string // form value with the token against login CSRF
+	NextKey     string // form value with the URL to return to after login
+	TokenHeader string // header that contains an API token
+	Realm       string // realm of a HTTP authentication challenge
+
+	// MaxMintTTL is the maximum lifetime of a session created by
+	// [Provider.MintSession].
+	MaxMintTTL time.Duration
 
 	mxAuthProgress sync.Mutex
 	authProgress   map[string]struct{}
 	authWait       time.Duration
+	limiter        rateLimiter
 }
 
 // MakeProvider make a new authenticator. Typically, you only need one
-// authenticator for an application.
+// authenticator for an application. The authentication cookie uses the
+// defaults of [CookieSpec], see [MakeProviderWithCookie] to change them.
 func MakeProvider(logger *slog.Logger, auth Authenticator, sess SessionManager, redir Redirector) *Provider {
 	provider := Provider{
 		logger: logger,
@@ -64,19 +81,22 @@ func MakeProvider(logger *slog.Logger, auth Authenticator, sess SessionManager,
 		sess:   sess,
 		redir:  redir,
 
-		PassLen:      127,
-		authlen:      32,
-		cookiePath:   "/", // TODO: should be set-able
-		maxCookieAge: 366 * 24 * 3600,
-		secureCookie: false,
+		PassLen: 127,
+		authlen: 32,
+		cookie:  CookieSpec{}.withDefaults(),
 
 		UsernameKey: "username",
 		PasswordKey: "password",
-		cookieName:  "auth",
+		CSRFKey:     "csrf",
+		NextKey:     "next",
+		TokenHeader: "Authorization",
+		Realm:       DefaultRealm,
+		MaxMintTTL:  DefaultMaxMintTTL,
 
 		authProgress: map[string]struct{}{},
-		authWait:     2 * time.Second, // wait time for multiple logins
+		authWait:     DefaultRateLimits.Busy, // wait time for multiple logins
 	}
+	provider.limiter.limits = DefaultRateLimits
 	return &provider
 }
 
@@ -106,6 +126,16 @@ type (
 	SessionInfo struct {
 		SessionID SessionID
 		User      UserInfo
+
+		// ByToken signals that the session was created by an API token, not by
+		// a cookie. SessionID is synthetic and there is no SessionManager entry.
+		ByToken bool
+
+		// ActingAs is the impersonated user, if the real user impersonates
+		// another user, see [Provider.Impersonate]. Then, it is equal to User.
+		ActingAs UserInfo
+
+		impersonator UserInfo // real user of an impersonation
 	}
 
 	// SessionID is an identifier for a session.
@@ -124,6 +154,14 @@ type SessionManager interface {
 	Remove(context.Context, SessionID) error
 }
 
+// SessionEnumerator is implemented by session managers, which are able to
+// list all sessions of a user, see [Provider.LogoutAll].
+type SessionEnumerator interface {
+	// SessionsForUser returns the identifiers of all sessions of the user
+	// with the given name.
+	SessionsForUser(ctx context.Context, username string) ([]SessionID, error)
+}
+
 // ErrNoSuchSession signals that the given session identifier is invalid.
 var ErrNoSuchSession = errors.New("no such session")
 
@@ -148,18 +186,33 @@ type Redirector interface {
 // Login creates a handler to implement a POST request from the login web page.
 func (lp *Provider) Login() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := lp.checkLoginCSRF(r); err != nil {
+			lp.logger.InfoContext(r.Context(), "login rejected", "error", err)
+			lp.emit(r, EventLoginInvalid, "", err)
+			lp.loginRedirect(w, r)
+			return
+		}
 		username := strings.TrimSpace(r.FormValue(lp.UsernameKey))
 		password := strings.TrimSpace(r.FormValue(lp.PasswordKey))
 
 		if !lp.validateUsernamePassword(username, password) {
 			lp.logger.Info("invalid password attempt")
+			lp.emit(r, EventLoginInvalid, "", nil)
 			lp.loginRedirect(w, r)
 			return
 		}
 
 		ctx := r.Context()
+		remote := clientHost(r)
+		if lp.limiter.isLocked(remote, username, time.Now()) {
+			lp.logger.InfoContext(ctx, "login locked", "username", username, "remote", remote)
+			lp.emit(r, EventLoginLocked, username, nil)
+			lp.loginRedirect(w, r)
+			return
+		}
 		if !lp.rateAndWait(username) {
 			lp.logger.InfoContext(ctx, "login rated", "username", username)
+			lp.emit(r, EventLoginRated, username, nil)
 			lp.loginRedirect(w, r)
 			return
 		}
@@ -167,10 +220,19 @@ func (lp *Provider) Login() http.Handler {
 		userinfo, err := lp.auth.Authenticate(ctx, username, password)
 		if err != nil {
 			lp.logger.InfoContext(ctx, "login failed", "error", err)
+			lp.emit(r, EventLoginFailed, username, err)
+			if lp.limiter.fail(remote, username, time.Now()) {
+				lp.logger.WarnContext(ctx, "login lockout", "username", username, "remote", remote)
+			}
 			lp.loginRedirect(w, r)
 			return
 		}
 
+		lp.limiter.succeed(username)
+		if lp.csrf {
+			// A new token is used for the next login.
+			http.SetCookie(w, lp.makeCSRFCookie("", -1))
+		}
 		lp.LoginUser(w, r, userinfo)
 	})
 }
@@ -187,16 +249,19 @@ func (lp *Provider) validateUsernamePassword(username, password string) bool {
 func (lp *Provider) rateAndWait(username string) bool {
 	lp.mxAuthProgress.Lock()
 	defer lp.mxAuthProgress.Unlock()
+	if lp.authWait <= 0 {
+		return true
+	}
 	if _, found := lp.authProgress[username]; found {
 		return false
 	}
 	lp.authProgress[username] = struct{}{}
-	go func(name string) {
-		time.Sleep(lp.authWait)
+	go func(name string, wait time.Duration) {
+		time.Sleep(wait)
 		lp.mxAuthProgress.Lock()
 		delete(lp.authProgress, name)
 		lp.mxAuthProgress.Unlock()
-	}(username)
+	}(username, lp.authWait)
 
 	return true
 }
@@ -209,24 +274,40 @@ func (lp *Provider) loginRedirect(w http.ResponseWriter, r *http.Request) {
 func (lp *Provider) LoginUser(w http.ResponseWriter, r *http.Request, userinfo UserInfo) {
 	ctx := r.Context()
 
-	hasher := sha512.New512_256()
-	_, _ = io.CopyN(hasher, rand.Reader, 32)
-	auth := lp.asHex(hasher)
+	auth, sessid := lp.newSession()
 	lp.setAuthCookie(w, auth)
-
-	hasher.Reset()
-	hasher.Write([]byte(auth))
-	sessid := SessionID(lp.asHex(hasher))
 	if err := lp.sess.SetUserAuth(ctx, userinfo, sessid); err != nil {
 		lp.logger.Error("set-session", "error", err)
+		lp.emit(r, EventSessionFailed, userinfo.Name(), err)
 		lp.redir.ErrorRedirect(w, r, userinfo, err)
 		return
 	}
 	lp.logger.Info("Login", "user", userinfo.Name())
-	r = r.WithContext(withSession(ctx, &SessionInfo{SessionID: sessid, User: userinfo}))
+	lp.emit(r, EventLogin, userinfo.Name(), nil)
+	ctx = withSession(ctx, &SessionInfo{SessionID: sessid, User: userinfo})
+	if target := lp.takeReturnURL(w, r); target != "" {
+		ctx = withReturnURL(ctx, target)
+	}
+	r = r.WithContext(ctx)
 	lp.redir.SuccessRedirect(w, r, userinfo)
 }
 
+// newSession returns a random cookie value and the identifier of the session
+// that belongs to it.
+func (lp *Provider) newSession() (string, SessionID) {
+	hasher := sha512.New512_256()
+	_, _ = io.CopyN(hasher, rand.Reader, 32)
+	auth := lp.asHex(hasher)
+	if lp.codec != nil {
+		sessid := SessionID(auth)
+		return lp.codec.Encode(sessid), sessid
+	}
+
+	hasher.Reset()
+	hasher.Write([]byte(auth))
+	return auth, SessionID(lp.asHex(hasher))
+}
+
 // Logout creates a handler that implements a logout.
 func (lp *Provider) Logout() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -239,7 +320,39 @@ func (lp *Provider) Logout() http.Handler {
 			if err != nil {
 				lp.logger.Error("unable to remove auth", "error", err)
 			}
-			lp.logger.Info("Logout", "user", userinfo.Name())
+			lp.logger.Info("Logout", userAttrs(userinfo)...)
+			lp.emit(r, EventLogout, userinfo.Name(), nil)
+		}
+		lp.clearAuthCookie(w)
+		lp.redir.LogoutRedirect(w, r)
+	})
+}
+
+// LogoutAll creates a handler that logs the current user out everywhere, i.e.
+// all sessions of the user are removed. If the session manager does not
+// implement [SessionEnumerator], only the current session is removed.
+func (lp *Provider) LogoutAll() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userinfo, auth, err := lp.checkCookie(r)
+		if err != nil {
+			lp.logger.Info("invalid cookie", "error", err)
+		} else {
+			ctx := r.Context()
+			sessids := []SessionID{auth}
+			if se, ok := lp.sess.(SessionEnumerator); !ok {
+				lp.logger.WarnContext(ctx, "session manager cannot enumerate sessions, only current session removed")
+			} else if userSessions, errEnum := se.SessionsForUser(ctx, userinfo.Name()); errEnum != nil {
+				lp.logger.ErrorContext(ctx, "unable to enumerate sessions", "error", errEnum)
+			} else {
+				sessids = append(sessids, userSessions...)
+			}
+			for _, sessid := range sessids {
+				if err = lp.sess.Remove(ctx, sessid); err != nil {
+					lp.logger.Error("unable to remove auth", "error", err)
+				}
+			}
+			lp.logger.Info("Logout everywhere", userAttrs(userinfo)...)
+			lp.emit(r, EventLogout, userinfo.Name(), nil)
 		}
 		lp.clearAuthCookie(w)
 		lp.redir.LogoutRedirect(w, r)
@@ -259,14 +372,34 @@ func Session(ctx context.Context) *SessionInfo {
 
 var withSession, getSession = contexts.WithAndValue[*SessionInfo](sessionKeyType{})
 
+// ContextInfoName is the name of the user name of the current session in a
+// snapshot of package contextinfo.
+const ContextInfoName = "user"
+
+func init() {
+	contextinfo.Register(ContextInfoName, func(ctx context.Context) (string, bool) {
+		if session := Session(ctx); session != nil && session.User != nil {
+			return session.User.Name(), true
+		}
+		return "", false
+	})
+}
+
 // EnrichUserInfo is a middleware that retrieves the user info based on the
 // cookie and stores it in the request context.
 //
 // Function User() will provide the actual user info for handlers.
+//
+// If a token authenticator is set and the request contains an API token, the
+// token is used for authentication instead of the cookie.
 func (lp *Provider) EnrichUserInfo(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if userinfo, sessid, err := lp.checkCookie(r); err == nil {
-			ctx := withSession(r.Context(), &SessionInfo{SessionID: sessid, User: userinfo})
+		if token, hasToken := lp.requestToken(r); hasToken {
+			if session, err := lp.checkToken(r, token); err == nil {
+				r = r.WithContext(withSession(r.Context(), session))
+			}
+		} else if userinfo, sessid, err := lp.checkCookie(r); err == nil {
+			ctx := withSession(r.Context(), makeSessionInfo(sessid, userinfo))
 			r = r.WithContext(ctx)
 		}
 		next.ServeHTTP(w, r)
@@ -280,12 +413,29 @@ func (lp *Provider) EnrichUserInfo(next http.Handler) http.Handler {
 // functor EnrichUserInfo.
 //
 // Function User() can be used to retrieve the actual user inside a handler.
+//
+// A request with an invalid API token is not redirected, but answered with
+// status code 401, because it was not issued by a human user.
+//
+// After the session was validated, all gates registered by [Provider.AddGate]
+// are checked.
 func (lp *Provider) Required(next http.Handler) http.Handler {
+	return lp.required(next, nil, lp.requiredRedirect)
+}
+
+// required ensures a logged-in user, who passed all gates. A request of an
+// anonymous user is answered by deny.
+func (lp *Provider) required(next http.Handler, extraGates []Gate, deny http.HandlerFunc) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if session := Session(r.Context()); session != nil {
-			next.ServeHTTP(w, r)
+			if lp.checkGates(w, r, session, extraGates) {
+				next.ServeHTTP(w, r)
+			}
+		} else if _, hasToken := lp.requestToken(r); hasToken {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
 		} else {
-			lp.loginRedirect(w, r)
+			deny(w, r)
 		}
 	})
 }
@@ -293,20 +443,32 @@ func (lp *Provider) Required(next http.Handler) http.Handler {
 var errInvalidCookie = errors.New("invalid cookie")
 
 func (lp *Provider) checkCookie(r *http.Request) (UserInfo, SessionID, error) {
-	cookie := lp.getAuthCookie(r)
-	if cookie == "" {
-		return nil, "", errInvalidCookie
+	var auth SessionID
+	if lp.codec != nil {
+		cookie, err := r.Cookie(lp.cookie.Name)
+		if err != nil {
+			return nil, "", errInvalidCookie
+		}
+		if auth, err = lp.codec.Decode(cookie.Value); err != nil {
+			lp.logger.Info("bad authentication", "error", err)
+			return nil, "", err
+		}
+	} else {
+		cookie := lp.getAuthCookie(r)
+		if cookie == "" {
+			return nil, "", errInvalidCookie
+		}
+		hasher := sha512.New512_256()
+		hasher.Write([]byte(cookie))
+		auth = SessionID(lp.asHex(hasher))
 	}
-	hasher := sha512.New512_256()
-	hasher.Write([]byte(cookie))
-	auth := SessionID(lp.asHex(hasher))
 	ctx := r.Context()
 	userinfo, err := lp.sess.UserAuth(ctx, auth)
 	return userinfo, auth, err
 }
 
 func (lp *Provider) getAuthCookie(r *http.Request) string {
-	cookie, err := r.Cookie(lp.cookieName)
+	cookie, err := r.Cookie(lp.cookie.Name)
 	if err != nil {
 		return ""
 	}
@@ -318,30 +480,6 @@ func (lp *Provider) getAuthCookie(r *http.Request) string {
 	return auth
 }
 
-func (lp *Provider) setAuthCookie(w http.ResponseWriter, value string) {
-	http.SetCookie(w, &http.Cookie{
-		Name:     lp.cookieName,
-		Value:    value,
-		Path:     lp.cookiePath,
-		MaxAge:   lp.maxCookieAge,
-		Secure:   lp.secureCookie,
-		HttpOnly: true, // TODO: "false" possibly needed for htmx
-		SameSite: http.SameSiteLaxMode,
-	})
-}
-
-func (lp *Provider) clearAuthCookie(w http.ResponseWriter) {
-	http.SetCookie(w, &http.Cookie{
-		Name:     lp.cookieName,
-		Value:    "",
-		Path:     lp.cookiePath,
-		MaxAge:   -1,
-		Secure:   lp.secureCookie,
-		HttpOnly: true,
-		SameSite: http.SameSiteLaxMode,
-	})
-}
-
 func (lp *Provider) asHex(hasher hash.Hash) string {
 	return fmt.Sprintf("%x", hasher.Sum(nil))[0:lp.authlen]
 }
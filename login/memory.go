@@ -0,0 +1,240 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2024-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2024-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package login
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultSessionTTL is the lifetime of an unused session of a
+// [MemorySessions], if no other lifetime is given.
+const DefaultSessionTTL = 7 * 24 * time.Hour
+
+// MemorySessions is a SessionManager that stores its sessions in main
+// memory, with a limited number of sessions per user. It is safe for
+// concurrent use.
+//
+// A session expires, if it was not used for the configured lifetime, or at
+// its fixed expiry time, see [SessionMeta]. Expired sessions are removed when
+// accessed, and periodically by a background goroutine, which runs until
+// [MemorySessions.Close] is called.
+type MemorySessions struct {
+	maxPerUser int
+	ttl        time.Duration
+
+	mx       sync.Mutex // protects the following maps
+	sessions map[SessionID]*memorySession
+	users    map[string]map[SessionID]struct{} // sessions per user name
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+type memorySession struct {
+	user    UserInfo
+	expires time.Time
+	meta    SessionMeta
+}
+
+// NewMemorySessionManager creates a new in-memory session manager. A user may
+// have at most maxSessionsPerUser sessions, or an unlimited number, if the
+// value is not positive. A session expires, if it is not used for the given
+// lifetime, or for [DefaultSessionTTL], if the lifetime is not positive.
+func NewMemorySessionManager(maxSessionsPerUser int, ttl time.Duration) *MemorySessions {
+	if ttl <= 0 {
+		ttl = DefaultSessionTTL
+	}
+	ms := &MemorySessions{
+		maxPerUser: maxSessionsPerUser,
+		ttl:        ttl,
+		sessions:   map[SessionID]*memorySession{},
+		users:      map[string]map[SessionID]struct{}{},
+		stop:       make(chan struct{}),
+	}
+	go ms.sweep(max(min(ttl/2, 10*time.Minute), time.Second))
+	return ms
+}
+
+// Close stops the background removal of expired sessions. The sessions are
+// still available.
+func (ms *MemorySessions) Close() { ms.stopOnce.Do(func() { close(ms.stop) }) }
+
+func (ms *MemorySessions) sweep(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ms.stop:
+			return
+		case now := <-ticker.C:
+			ms.mx.Lock()
+			for sessid, session := range ms.sessions {
+				if now.After(session.expires) {
+					ms.remove(sessid, session)
+				}
+			}
+			ms.mx.Unlock()
+		}
+	}
+}
+
+// SetUserAuth stores user information to the given session. If the user
+// already has the maximum number of sessions, [ErrTooManySessions] is
+// returned.
+func (ms *MemorySessions) SetUserAuth(ctx context.Context, userinfo UserInfo, auth SessionID) error {
+	return ms.SetUserAuthMeta(ctx, userinfo, auth, SessionMeta{Created: time.Now()})
+}
+
+// SetUserAuthMeta stores user information and metadata to the given session,
+// like [MemorySessions.SetUserAuth].
+func (ms *MemorySessions) SetUserAuthMeta(_ context.Context, userinfo UserInfo, auth SessionID, meta SessionMeta) error {
+	now := time.Now()
+	session := &memorySession{user: userinfo, expires: meta.Expires, meta: meta}
+	if session.expires.IsZero() {
+		session.expires = now.Add(ms.ttl)
+	}
+	name := userinfo.Name()
+
+	ms.mx.Lock()
+	defer ms.mx.Unlock()
+	if old, found := ms.sessions[auth]; found {
+		ms.remove(auth, old)
+	}
+	for sessid := range ms.users[name] {
+		if other := ms.sessions[sessid]; now.After(other.expires) {
+			ms.remove(sessid, other)
+		}
+	}
+	userSessions := ms.users[name]
+	if ms.maxPerUser > 0 && len(userSessions) >= ms.maxPerUser {
+		return ErrTooManySessions
+	}
+	if userSessions == nil {
+		userSessions = map[SessionID]struct{}{}
+		ms.users[name] = userSessions
+	}
+	userSessions[auth] = struct{}{}
+	ms.sessions[auth] = session
+	return nil
+}
+
+// UserAuth returns user information for the given session. Using a session
+// extends its lifetime, unless it has a fixed expiry time.
+func (ms *MemorySessions) UserAuth(_ context.Context, auth SessionID) (UserInfo, error) {
+	now := time.Now()
+	ms.mx.Lock()
+	defer ms.mx.Unlock()
+	session, found := ms.sessions[auth]
+	if !found {
+		return nil, ErrNoSuchSession
+	}
+	if now.After(session.expires) {
+		ms.remove(auth, session)
+		return nil, ErrNoSuchSession
+	}
+	if session.meta.Expires.IsZero() {
+		session.expires = now.Add(ms.ttl)
+	}
+	return session.user, nil
+}
+
+// Remove the session. Effectively, the user is logged out.
+func (ms *MemorySessions) Remove(_ context.Context, auth SessionID) error {
+	ms.mx.Lock()
+	if session, found := ms.sessions[auth]; found {
+		ms.remove(auth, session)
+	}
+	ms.mx.Unlock()
+	return nil
+}
+
+// RemoveAllForUser removes all sessions of the user with the given name,
+// i.e. the user is logged out everywhere.
+func (ms *MemorySessions) RemoveAllForUser(_ context.Context, username string) error {
+	ms.mx.Lock()
+	for sessid := range ms.users[username] {
+		delete(ms.sessions, sessid)
+	}
+	delete(ms.users, username)
+	ms.mx.Unlock()
+	return nil
+}
+
+// SessionsForUser returns the identifiers of all sessions of the user with
+// the given name, which are not expired.
+func (ms *MemorySessions) SessionsForUser(_ context.Context, username string) ([]SessionID, error) {
+	now := time.Now()
+	var result []SessionID
+	ms.mx.Lock()
+	for sessid := range ms.users[username] {
+		if !now.After(ms.sessions[sessid].expires) {
+			result = append(result, sessid)
+		}
+	}
+	ms.mx.Unlock()
+	slices.Sort(result)
+	return result, nil
+}
+
+// remove the session. The mutex must be locked.
+func (ms *MemorySessions) remove(auth SessionID, session *memorySession) {
+	delete(ms.sessions, auth)
+	name := session.user.Name()
+	if userSessions := ms.users[name]; userSessions != nil {
+		delete(userSessions, auth)
+		if len(userSessions) == 0 {
+			delete(ms.users, name)
+		}
+	}
+}
+
+// SessionCount returns the number of sessions that are not expired.
+func (ms *MemorySessions) SessionCount(ctx context.Context) (int, error) {
+	entries, err := ms.ListSessions(ctx, nil)
+	return len(entries), err
+}
+
+// ListSessions returns all sessions that are not expired and that match the
+// filter, ordered by their creation time.
+func (ms *MemorySessions) ListSessions(_ context.Context, filter func(SessionEntry) bool) ([]SessionEntry, error) {
+	now := time.Now()
+	var result []SessionEntry
+	ms.mx.Lock()
+	for sessid, session := range ms.sessions {
+		if now.After(session.expires) {
+			continue
+		}
+		se := SessionEntry{SessionID: sessid, User: session.user, Meta: session.meta}
+		if filter == nil || filter(se) {
+			result = append(result, se)
+		}
+	}
+	ms.mx.Unlock()
+	sortSessionEntries(result)
+	return result, nil
+}
+
+// sortSessionEntries sorts the entries by creation time and identifier.
+func sortSessionEntries(entries []SessionEntry) {
+	slices.SortFunc(entries, func(a, b SessionEntry) int {
+		if c := a.Meta.Created.Compare(b.Meta.Created); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.SessionID, b.SessionID)
+	})
+}
@@ -0,0 +1,202 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2024-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2024-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package login_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"t73f.de/r/webs/login"
+)
+
+func TestMemorySessions(t *testing.T) {
+	ms := login.NewMemorySessionManager(2, time.Hour)
+	defer ms.Close()
+	ctx := context.Background()
+
+	for _, sessid := range []login.SessionID{"a1", "a2"} {
+		if err := ms.SetUserAuth(ctx, testUser("alice"), sessid); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ms.SetUserAuth(ctx, testUser("alice"), "a3"); !errors.Is(err, login.ErrTooManySessions) {
+		t.Errorf("ErrTooManySessions expected, got %v", err)
+	}
+	if err := ms.SetUserAuth(ctx, testUser("bob"), "b1"); err != nil {
+		t.Errorf("other user must not be limited, got %v", err)
+	}
+	if user, err := ms.UserAuth(ctx, "a2"); err != nil || user.Name() != "alice" {
+		t.Errorf("alice expected, got %v / %v", user, err)
+	}
+	if _, err := ms.UserAuth(ctx, "a3"); !errors.Is(err, login.ErrNoSuchSession) {
+		t.Errorf("ErrNoSuchSession expected, got %v", err)
+	}
+
+	if err := ms.Remove(ctx, "a1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ms.UserAuth(ctx, "a1"); !errors.Is(err, login.ErrNoSuchSession) {
+		t.Errorf("removed session must be invalid, got %v", err)
+	}
+	if err := ms.SetUserAuth(ctx, testUser("alice"), "a3"); err != nil {
+		t.Errorf("removed session must free a slot, got %v", err)
+	}
+
+	if err := ms.RemoveAllForUser(ctx, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	for _, sessid := range []login.SessionID{"a2", "a3"} {
+		if _, err := ms.UserAuth(ctx, sessid); !errors.Is(err, login.ErrNoSuchSession) {
+			t.Errorf("session %v must be removed, got %v", sessid, err)
+		}
+	}
+	if user, err := ms.UserAuth(ctx, "b1"); err != nil || user.Name() != "bob" {
+		t.Errorf("session of bob must remain, got %v / %v", user, err)
+	}
+	if n, _ := ms.SessionCount(ctx); n != 1 {
+		t.Errorf("one session expected, got %d", n)
+	}
+}
+
+func TestMemorySessionsExpiry(t *testing.T) {
+	const ttl = 100 * time.Millisecond
+	ms := login.NewMemorySessionManager(1, ttl)
+	defer ms.Close()
+	ctx := context.Background()
+	if err := ms.SetUserAuth(ctx, testUser("alice"), "a1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ms.SetUserAuth(ctx, testUser("bob"), "b1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Using a session extends its lifetime.
+	for range 3 {
+		time.Sleep(ttl / 2)
+		if _, err := ms.UserAuth(ctx, "a1"); err != nil {
+			t.Fatalf("used session must not expire, got %v", err)
+		}
+	}
+	if _, err := ms.UserAuth(ctx, "b1"); !errors.Is(err, login.ErrNoSuchSession) {
+		t.Errorf("unused session must expire, got %v", err)
+	}
+
+	// An expired session does not count against the limit.
+	if err := ms.SetUserAuth(ctx, testUser("bob"), "b2"); err != nil {
+		t.Errorf("expired session must free a slot, got %v", err)
+	}
+	time.Sleep(2 * ttl)
+	if err := ms.SetUserAuth(ctx, testUser("alice"), "a2"); err != nil {
+		t.Errorf("expired session must free a slot, got %v", err)
+	}
+	if n, _ := ms.SessionCount(ctx); n != 1 {
+		t.Errorf("one session expected, got %d", n)
+	}
+}
+
+func TestMemorySessionsMinted(t *testing.T) {
+	ms := login.NewMemorySessionManager(0, 0)
+	defer ms.Close()
+	lp := login.MakeProvider(slog.New(slog.DiscardHandler), &login.TestAuthenticator{}, ms, &login.SimpleRedirector{})
+	ctx := context.Background()
+	if _, _, err := lp.MintSession(ctx, testUser("alice"), time.Minute, "support"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ms.SetUserAuth(ctx, testUser("alice"), "a1"); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := lp.RevokeMinted(ctx); err != nil || n != 1 {
+		t.Errorf("one revoked session expected, got %d / %v", n, err)
+	}
+	if all, _ := ms.ListSessions(ctx, nil); len(all) != 1 || all[0].SessionID != "a1" {
+		t.Errorf("login session must remain, got %v", all)
+	}
+}
+
+func TestMemorySessionsConcurrent(t *testing.T) {
+	ms := login.NewMemorySessionManager(10, time.Hour)
+	defer ms.Close()
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			user := testUser(fmt.Sprintf("user%d", i))
+			for j := range 100 {
+				sessid := login.SessionID(fmt.Sprintf("%s-%d", user, j))
+				if err := ms.SetUserAuth(ctx, user, sessid); err != nil {
+					_ = ms.RemoveAllForUser(ctx, user.Name())
+					continue
+				}
+				_, _ = ms.UserAuth(ctx, sessid)
+				_ = ms.Remove(ctx, sessid)
+			}
+		})
+	}
+	wg.Wait()
+	if n, _ := ms.SessionCount(ctx); n != 0 {
+		t.Errorf("no session expected, got %d", n)
+	}
+}
+
+func TestLogoutAll(t *testing.T) {
+	for _, enumerate := range []bool{true, false} {
+		t.Run(fmt.Sprint(enumerate), func(t *testing.T) {
+			ms := login.NewMemorySessionManager(0, time.Hour)
+			defer ms.Close()
+			var sm login.SessionManager = ms
+			if !enumerate {
+				sm = plainSessions{ms}
+			}
+			var logs strings.Builder
+			lp := login.MakeProvider(slog.New(slog.NewTextHandler(&logs, nil)), &login.TestAuthenticator{}, sm, &login.SimpleRedirector{})
+			h := lp.EnrichUserInfo(lp.Required(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, login.Session(r.Context()).User.Name())
+			})))
+
+			// Login directly, because repeated logins of a user are rated.
+			userCookie := func(name string) *http.Cookie {
+				w := httptest.NewRecorder()
+				lp.LoginUser(w, httptest.NewRequest(http.MethodPost, "/login/", nil), testUser(name))
+				return w.Result().Cookies()[0]
+			}
+			cookies := []*http.Cookie{userCookie("alice"), userCookie("alice"), userCookie("alice")}
+			bob := userCookie("bob")
+
+			w := get(lp.LogoutAll(), "/logout", cookies[1])
+			if got := w.Result().Cookies(); len(got) != 1 || got[0].MaxAge >= 0 {
+				t.Errorf("cookie must be cleared, got %v", got)
+			}
+			for i, cookie := range cookies {
+				w = get(h, "/", cookie)
+				if exp := enumerate || i == 1; (w.Code != http.StatusOK) != exp {
+					t.Errorf("cookie %d: invalidated=%v expected, got status %d", i, exp, w.Code)
+				}
+			}
+			if w = get(h, "/", bob); w.Code != http.StatusOK || w.Body.String() != "bob" {
+				t.Errorf("session of bob must remain, got %d %q", w.Code, w.Body.String())
+			}
+			if hasWarning := strings.Contains(logs.String(), "level=WARN"); hasWarning == enumerate {
+				t.Errorf("warning logged: %v, but enumerate %v\n%s", hasWarning, enumerate, logs.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2024-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2024-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package login
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultMaxMintTTL is the default value of [Provider.MaxMintTTL].
+const DefaultMaxMintTTL = time.Hour
+
+// SessionMeta describes how a session was created.
+type SessionMeta struct {
+	Created time.Time
+	Expires time.Time // fixed end of the session; zero for the default lifetime
+
+	// Minted signals a session created by [Provider.MintSession], not by a
+	// login of the user.
+	Minted bool
+	Reason string // why the session was minted
+}
+
+// SessionEntry is a session together with its metadata.
+type SessionEntry struct {
+	SessionID SessionID
+	User      UserInfo
+	Meta      SessionMeta
+}
+
+// IsMinted is a filter for [MetaSessionManager.ListSessions] that selects
+// only minted sessions.
+func IsMinted(se SessionEntry) bool { return se.Meta.Minted }
+
+// MetaSessionManager is implemented by session managers, which store
+// metadata of their sessions. It is needed to mint sessions.
+type MetaSessionManager interface {
+	SessionManager
+
+	// SetUserAuthMeta associates an user info with a session identifier,
+	// like SetUserAuth, and stores the metadata of the session. A session
+	// with a non-zero expiry time must not be used after that time, and its
+	// lifetime must not be extended.
+	SetUserAuthMeta(context.Context, UserInfo, SessionID, SessionMeta) error
+
+	// ListSessions returns all sessions that are not expired and that
+	// match the filter. A nil filter matches all sessions.
+	ListSessions(ctx context.Context, filter func(SessionEntry) bool) ([]SessionEntry, error)
+}
+
+// ErrInvalidMint is signaled if a session cannot be minted with the given
+// arguments.
+var ErrInvalidMint = errors.New("invalid session mint")
+
+// ErrNoSessionMeta is signaled if the session manager does not implement
+// [MetaSessionManager].
+var ErrNoSessionMeta = errors.New("session manager does not store metadata")
+
+// MintSession creates a session for the given user without a login, e.g. for
+// support tooling that must act as a specific user. The session is created
+// like [Provider.LoginUser] does, but the cookie value is returned instead
+// of being set in a response. A client uses it as the value of the
+// authentication cookie.
+//
+// The session expires after the given lifetime, which must be positive and
+// not greater than [Provider.MaxMintTTL], regardless of the maximum age of
+// the cookie. A reason must be given. It is stored in the session metadata,
+// logged, and emitted as an event of kind [EventSessionMinted]. The session
+// manager must implement [MetaSessionManager].
+func (lp *Provider) MintSession(ctx context.Context, userinfo UserInfo, ttl time.Duration, reason string) (string, SessionID, error) {
+	msm, ok := lp.sess.(MetaSessionManager)
+	if !ok {
+		return "", "", ErrNoSessionMeta
+	}
+	if userinfo == nil {
+		return "", "", fmt.Errorf("%w: no user", ErrInvalidMint)
+	}
+	if ttl <= 0 || ttl > lp.MaxMintTTL {
+		return "", "", fmt.Errorf("%w: lifetime %v not in (0, %v]", ErrInvalidMint, ttl, lp.MaxMintTTL)
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return "", "", fmt.Errorf("%w: no reason", ErrInvalidMint)
+	}
+
+	auth, sessid := lp.newSession()
+	now := time.Now()
+	meta := SessionMeta{Created: now, Expires: now.Add(ttl), Minted: true, Reason: reason}
+	if err := msm.SetUserAuthMeta(ctx, userinfo, sessid, meta); err != nil {
+		lp.logger.ErrorContext(ctx, "mint-session", "user", userinfo.Name(), "error", err)
+		return "", "", err
+	}
+	lp.logger.InfoContext(ctx, "Minted session", "user", userinfo.Name(), "ttl", ttl, "reason", reason)
+	lp.emitReason(ctx, EventSessionMinted, userinfo.Name(), reason)
+	return auth, sessid, nil
+}
+
+// RevokeMinted removes all minted sessions and returns their number. Each
+// removal is logged and emitted as an event of kind [EventSessionRevoked].
+func (lp *Provider) RevokeMinted(ctx context.Context) (int, error) {
+	msm, ok := lp.sess.(MetaSessionManager)
+	if !ok {
+		return 0, ErrNoSessionMeta
+	}
+	entries, err := msm.ListSessions(ctx, IsMinted)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, se := range entries {
+		if err = msm.Remove(ctx, se.SessionID); err != nil {
+			lp.logger.ErrorContext(ctx, "revoke-session", "user", se.User.Name(), "error", err)
+			return count, err
+		}
+		count++
+		lp.logger.InfoContext(ctx, "Revoked session", "user", se.User.Name(), "reason", se.Meta.Reason)
+		lp.emitReason(ctx, EventSessionRevoked, se.User.Name(), se.Meta.Reason)
+	}
+	return count, nil
+}
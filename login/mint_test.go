@@ -0,0 +1,148 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2024-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2024-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package login_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"t73f.de/r/webs/login"
+)
+
+func newMintProvider(t *testing.T) (*login.Provider, *login.RAMSessions, http.Handler) {
+	t.Helper()
+	sessions := &login.RAMSessions{}
+	lp := login.MakeProvider(slog.New(slog.DiscardHandler), &login.TestAuthenticator{}, sessions, &login.SimpleRedirector{})
+	h := lp.EnrichUserInfo(lp.Required(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, login.Session(r.Context()).User.Name())
+	})))
+	return lp, sessions, h
+}
+
+func TestMintSession(t *testing.T) {
+	lp, sessions, h := newMintProvider(t)
+	var events []login.Event
+	lp.SetEventSink(login.EventSinkFunc(func(_ context.Context, ev login.Event) { events = append(events, ev) }))
+	ctx := context.Background()
+
+	value, sessid, err := lp.MintSession(ctx, testUser("alice"), 10*time.Minute, "  ticket 42 ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cookie := &http.Cookie{Name: login.DefaultCookieName, Value: value}
+	if w := get(h, "/", cookie); w.Code != http.StatusOK || w.Body.String() != "alice" {
+		t.Errorf("minted session must pass, got %d %q", w.Code, w.Body.String())
+	}
+	if len(events) != 1 || events[0].Kind != login.EventSessionMinted || events[0].Username != "alice" || events[0].Reason != "ticket 42" {
+		t.Errorf("minted event expected, got %v", events)
+	}
+
+	loginCookie(t, lp, "bob")
+	all, err := sessions.ListSessions(ctx, nil)
+	if err != nil || len(all) != 2 {
+		t.Fatalf("two sessions expected, got %v / %v", all, err)
+	}
+	minted, err := sessions.ListSessions(ctx, login.IsMinted)
+	if err != nil || len(minted) != 1 {
+		t.Fatalf("one minted session expected, got %v / %v", minted, err)
+	}
+	if se := minted[0]; se.SessionID != sessid || se.User.Name() != "alice" || !se.Meta.Minted || se.Meta.Reason != "ticket 42" ||
+		se.Meta.Expires.Sub(se.Meta.Created) != 10*time.Minute {
+		t.Errorf("unexpected minted session %v", se)
+	}
+
+	events = nil
+	count, err := lp.RevokeMinted(ctx)
+	if err != nil || count != 1 {
+		t.Errorf("one revoked session expected, got %d / %v", count, err)
+	}
+	if w := get(h, "/", cookie); w.Code == http.StatusOK {
+		t.Error("revoked session must not pass")
+	}
+	if len(events) != 1 || events[0].Kind != login.EventSessionRevoked {
+		t.Errorf("revoked event expected, got %v", events)
+	}
+	if all, _ = sessions.ListSessions(ctx, nil); len(all) != 1 || all[0].Meta.Minted || all[0].User.Name() != "bob" {
+		t.Errorf("session of bob must remain, got %v", all)
+	}
+}
+
+func TestMintSessionExpiry(t *testing.T) {
+	lp, sessions, h := newMintProvider(t)
+	if spec := lp.CookieSpec(); time.Duration(spec.MaxAge)*time.Second < time.Hour {
+		t.Fatalf("cookie must live longer than the session, got %v", spec)
+	}
+	value, _, err := lp.MintSession(context.Background(), testUser("alice"), 50*time.Millisecond, "short")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cookie := &http.Cookie{Name: login.DefaultCookieName, Value: value}
+	if w := get(h, "/", cookie); w.Code != http.StatusOK {
+		t.Fatalf("minted session must pass, got %d", w.Code)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if w := get(h, "/", cookie); w.Code != http.StatusSeeOther {
+		t.Errorf("expired session must redirect to login, got %d", w.Code)
+	}
+	if all, _ := sessions.ListSessions(context.Background(), nil); len(all) != 0 {
+		t.Errorf("no session expected, got %v", all)
+	}
+}
+
+type plainSessions struct{ login.SessionManager }
+
+func TestMintSessionErrors(t *testing.T) {
+	lp, sessions, _ := newMintProvider(t)
+	ctx := context.Background()
+	testcases := []struct {
+		name   string
+		user   login.UserInfo
+		ttl    time.Duration
+		reason string
+	}{
+		{"no-user", nil, time.Minute, "r"},
+		{"zero-ttl", testUser("alice"), 0, "r"},
+		{"negative-ttl", testUser("alice"), -time.Minute, "r"},
+		{"too-long", testUser("alice"), login.DefaultMaxMintTTL + time.Second, "r"},
+		{"no-reason", testUser("alice"), time.Minute, " "},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, _, err := lp.MintSession(ctx, tc.user, tc.ttl, tc.reason); !errors.Is(err, login.ErrInvalidMint) {
+				t.Errorf("ErrInvalidMint expected, got %v", err)
+			}
+		})
+	}
+	if all, _ := sessions.ListSessions(ctx, nil); len(all) != 0 {
+		t.Errorf("no session expected, got %v", all)
+	}
+
+	lp.MaxMintTTL = 2 * login.DefaultMaxMintTTL
+	if _, _, err := lp.MintSession(ctx, testUser("alice"), login.DefaultMaxMintTTL+time.Second, "r"); err != nil {
+		t.Errorf("larger maximum lifetime must be accepted, got %v", err)
+	}
+
+	lp = login.MakeProvider(slog.New(slog.DiscardHandler), &login.TestAuthenticator{}, plainSessions{&login.RAMSessions{}}, &login.SimpleRedirector{})
+	if _, _, err := lp.MintSession(ctx, testUser("alice"), time.Minute, "r"); !errors.Is(err, login.ErrNoSessionMeta) {
+		t.Errorf("ErrNoSessionMeta expected, got %v", err)
+	}
+	if _, err := lp.RevokeMinted(ctx); !errors.Is(err, login.ErrNoSessionMeta) {
+		t.Errorf("ErrNoSessionMeta expected, got %v", err)
+	}
+}
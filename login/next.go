@@ -0,0 +1,98 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2024-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2024-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package login
+
+import (
+	"context"
+	"net/http"
+
+	"t73f.de/r/zero/contexts"
+)
+
+// ReturnCookieSuffix is appended to the name of the authentication cookie,
+// to name the cookie that stores the URL an anonymous user requested, before
+// being redirected to the login page.
+const ReturnCookieSuffix = "-return"
+
+// returnMaxAge is the maximum age in seconds of the cookie that stores the
+// URL to return to after login.
+const returnMaxAge = 600
+
+// EnableReturnTo lets the user return to the originally requested URL after
+// login. It is disabled by default.
+//
+// When enabled, [Provider.Required] and its variants store the path and the
+// query of a GET or HEAD request of an anonymous user in a short-lived
+// cookie, before redirecting to the login page. Alternatively, the login
+// form may send the URL as the value named in NextKey, which takes
+// precedence. [Provider.LoginUser] consumes the URL and passes it to the
+// success redirect of the [Redirector], see [ReturnURL]. Only URLs that
+// refer to a path of the same site are accepted, to prevent open redirects.
+func (lp *Provider) EnableReturnTo() { lp.returnTo = true }
+
+// requiredRedirect redirects an anonymous user to the login page, and
+// remembers the requested URL, if enabled.
+func (lp *Provider) requiredRedirect(w http.ResponseWriter, r *http.Request) {
+	if lp.returnTo && (r.Method == http.MethodGet || r.Method == http.MethodHead) {
+		if target := r.URL.RequestURI(); isLocalURL(target) {
+			http.SetCookie(w, lp.makeReturnCookie(target, returnMaxAge))
+		}
+	}
+	lp.loginRedirect(w, r)
+}
+
+func (lp *Provider) makeReturnCookie(value string, maxAge int) *http.Cookie {
+	cookie := lp.makeCookie(value, maxAge)
+	cookie.Name += ReturnCookieSuffix
+	return cookie
+}
+
+// takeReturnURL returns the URL to return to after login, or the empty
+// string, if there is no valid one. A stored URL is removed.
+func (lp *Provider) takeReturnURL(w http.ResponseWriter, r *http.Request) string {
+	if !lp.returnTo {
+		return ""
+	}
+	var target string
+	if cookie, err := r.Cookie(lp.cookie.Name + ReturnCookieSuffix); err == nil {
+		http.SetCookie(w, lp.makeReturnCookie("", -1))
+		target = cookie.Value
+	}
+	if next := r.FormValue(lp.NextKey); next != "" {
+		target = next
+	}
+	if !isLocalURL(target) {
+		if target != "" {
+			lp.logger.InfoContext(r.Context(), "return URL rejected", "url", target)
+		}
+		return ""
+	}
+	return target
+}
+
+type returnKeyType struct{}
+
+var withReturnURL, getReturnURL = contexts.WithAndValue[string](returnKeyType{})
+
+// ReturnURL returns the URL the user should return to after login, or the
+// empty string, if there is none. It is only set in the context of the
+// request passed to [Redirector.SuccessRedirect], if enabled by
+// [Provider.EnableReturnTo]. The URL always refers to a path of the same
+// site.
+func ReturnURL(ctx context.Context) string {
+	if target, found := getReturnURL(ctx); found {
+		return target
+	}
+	return ""
+}
@@ -0,0 +1,114 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2024-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2024-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package login_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"t73f.de/r/webs/login"
+)
+
+func TestReturnTo(t *testing.T) {
+	lp := login.MakeProvider(slog.New(slog.DiscardHandler), &login.TestAuthenticator{}, &login.RAMSessions{}, &login.SimpleRedirector{SuccessURL: "/home"})
+	lp.SetRateLimits(login.RateLimits{})
+	lp.EnableReturnTo()
+	h := lp.EnrichUserInfo(lp.Required(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})))
+
+	returnCookie := func(w *httptest.ResponseRecorder) *http.Cookie {
+		for _, cookie := range w.Result().Cookies() {
+			if cookie.Name == login.DefaultCookieName+login.ReturnCookieSuffix {
+				return cookie
+			}
+		}
+		return nil
+	}
+	post := func(next string, cookie *http.Cookie) *httptest.ResponseRecorder {
+		form := url.Values{lp.UsernameKey: {"alice"}, lp.PasswordKey: {"alice"}}
+		if next != "" {
+			form.Set(lp.NextKey, next)
+		}
+		r := httptest.NewRequest(http.MethodPost, "/login/", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if cookie != nil {
+			r.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		lp.Login().ServeHTTP(w, r)
+		return w
+	}
+
+	w := get(h, "/docs/page?x=1&y=2")
+	if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/login/" {
+		t.Fatalf("redirect to login page expected, got %d %v", w.Code, w.Header())
+	}
+	cookie := returnCookie(w)
+	if cookie == nil || cookie.Value != "/docs/page?x=1&y=2" {
+		t.Fatalf("return cookie expected, got %v", cookie)
+	}
+	if w = post("", cookie); w.Header().Get("Location") != "/docs/page?x=1&y=2" {
+		t.Errorf("redirect to requested URL expected, got %v", w.Header())
+	}
+	if removed := returnCookie(w); removed == nil || removed.MaxAge >= 0 {
+		t.Errorf("return cookie must be removed, got %v", removed)
+	}
+
+	if w = post("/other", cookie); w.Header().Get("Location") != "/other" {
+		t.Errorf("posted URL must take precedence, got %v", w.Header())
+	}
+	if w = post("", nil); w.Header().Get("Location") != "/home" {
+		t.Errorf("success URL expected without return URL, got %v", w.Header())
+	}
+
+	if w = post("/\\evil.example", nil); w.Header().Get("Location") != "/home" {
+		t.Errorf("backslash URL must be rejected, got %v", w.Header())
+	}
+	for _, next := range []string{"https://evil.example/", "//evil.example/x", "javascript:alert(1)", "docs"} {
+		if w = post(next, nil); w.Header().Get("Location") != "/home" {
+			t.Errorf("%q must be rejected, got %v", next, w.Header())
+		}
+		forged := &http.Cookie{Name: login.DefaultCookieName + login.ReturnCookieSuffix, Value: next}
+		if w = post("", forged); w.Header().Get("Location") != "/home" {
+			t.Errorf("%q in cookie must be rejected, got %v", next, w.Header())
+		}
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/docs/page", nil)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if cookie = returnCookie(w); cookie != nil {
+		t.Errorf("no return cookie expected for POST, got %v", cookie)
+	}
+}
+
+func TestReturnToDisabled(t *testing.T) {
+	lp := login.MakeProvider(slog.New(slog.DiscardHandler), &login.TestAuthenticator{}, &login.RAMSessions{}, &login.SimpleRedirector{SuccessURL: "/home"})
+	h := lp.EnrichUserInfo(lp.Required(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})))
+	if w := get(h, "/docs/page"); len(w.Result().Cookies()) != 1 {
+		t.Errorf("only the authentication cookie must be cleared, got %v", w.Result().Cookies())
+	}
+
+	form := url.Values{lp.UsernameKey: {"alice"}, lp.PasswordKey: {"alice"}, lp.NextKey: {"/docs/page"}}
+	r := httptest.NewRequest(http.MethodPost, "/login/", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	lp.Login().ServeHTTP(w, r)
+	if got := w.Header().Get("Location"); got != "/home" {
+		t.Errorf("success URL expected, got %q", got)
+	}
+}
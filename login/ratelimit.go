@@ -0,0 +1,190 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2024-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2024-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package login
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimits configures the lockout after failed logins. Failed logins are
+// counted per client IP address and per username within a time window. When
+// a counter reaches its maximum, further logins from that address or for that
+// username are rejected for the lockout duration, without checking the
+// password. Failed checks of API tokens count for the client IP address
+// too. A successful login resets the counter of its username, but not
+// the one of its address: otherwise, an attacker could log into an own
+// account between the attempts to reset the limit.
+type RateLimits struct {
+	Window       time.Duration // period, in which failed logins are counted
+	MaxFailsIP   int           // maximum failed logins per client IP; 0: no limit
+	MaxFailsUser int           // maximum failed logins per username; 0: no limit
+	Lockout      time.Duration // duration of a lockout
+
+	// Busy is the period after a login attempt, in which further logins with
+	// the same username are rejected. It is not checked, if not positive.
+	Busy time.Duration
+}
+
+// DefaultRateLimits are the rate limits of a new [Provider].
+var DefaultRateLimits = RateLimits{
+	Window:       15 * time.Minute,
+	MaxFailsIP:   20,
+	MaxFailsUser: 5,
+	Lockout:      15 * time.Minute,
+	Busy:         2 * time.Second,
+}
+
+// SetRateLimits changes the lockout after failed logins. All current
+// counters and lockouts are discarded. A window or lockout duration that is
+// not positive disables the lockout.
+func (lp *Provider) SetRateLimits(rl RateLimits) {
+	lp.limiter.reset(rl)
+	lp.mxAuthProgress.Lock()
+	lp.authWait = rl.Busy
+	lp.mxAuthProgress.Unlock()
+}
+
+// RateLimits returns the current configuration of the lockout after failed
+// logins.
+func (lp *Provider) RateLimits() RateLimits {
+	lp.limiter.mx.Lock()
+	defer lp.limiter.mx.Unlock()
+	return lp.limiter.limits
+}
+
+// LockoutCount returns the number of client IP addresses and usernames that
+// are currently locked out. Therefore, a Provider is a [LockoutCounter].
+func (lp *Provider) LockoutCount(context.Context) (int, error) {
+	return lp.limiter.lockoutCount(time.Now()), nil
+}
+
+// rateLimiter counts failed logins per client IP and per username.
+type rateLimiter struct {
+	mx        sync.Mutex // protects the following fields
+	limits    RateLimits
+	ips       map[string]*failCounter
+	users     map[string]*failCounter
+	lastClean time.Time
+}
+
+type failCounter struct {
+	count       int
+	start       time.Time // start of the current window
+	lockedUntil time.Time
+}
+
+func (rl *rateLimiter) reset(limits RateLimits) {
+	rl.mx.Lock()
+	rl.limits = limits
+	rl.ips = nil
+	rl.users = nil
+	rl.mx.Unlock()
+}
+
+func (rl *rateLimiter) enabled() bool {
+	return rl.limits.Window > 0 && rl.limits.Lockout > 0
+}
+
+// isLocked returns true, if the client IP or the username are locked out.
+func (rl *rateLimiter) isLocked(remote, username string, now time.Time) bool {
+	rl.mx.Lock()
+	defer rl.mx.Unlock()
+	if !rl.enabled() {
+		return false
+	}
+	rl.clean(now)
+	for _, fc := range []*failCounter{rl.ips[remote], rl.users[username]} {
+		if fc != nil && now.Before(fc.lockedUntil) {
+			return true
+		}
+	}
+	return false
+}
+
+// fail counts a failed login. It returns true, if a lockout started. An
+// empty username, e.g. of a failed token check, is not counted.
+func (rl *rateLimiter) fail(remote, username string, now time.Time) bool {
+	rl.mx.Lock()
+	defer rl.mx.Unlock()
+	if !rl.enabled() {
+		return false
+	}
+	rl.clean(now)
+	if rl.ips == nil {
+		rl.ips = map[string]*failCounter{}
+		rl.users = map[string]*failCounter{}
+	}
+	lockedIP := rl.count(rl.ips, remote, rl.limits.MaxFailsIP, now)
+	lockedUser := username != "" && rl.count(rl.users, username, rl.limits.MaxFailsUser, now)
+	return lockedIP || lockedUser
+}
+
+func (rl *rateLimiter) count(counters map[string]*failCounter, key string, maxFails int, now time.Time) bool {
+	if maxFails <= 0 {
+		return false
+	}
+	fc := counters[key]
+	if fc == nil || now.Sub(fc.start) >= rl.limits.Window {
+		fc = &failCounter{start: now}
+		counters[key] = fc
+	}
+	fc.count++
+	if fc.count >= maxFails && !now.Before(fc.lockedUntil) {
+		fc.lockedUntil = now.Add(rl.limits.Lockout)
+		fc.count = 0
+		fc.start = now
+		return true
+	}
+	return false
+}
+
+// succeed resets the counter of the username of a successful login. The
+// counter of the client IP is kept, see [RateLimits].
+func (rl *rateLimiter) succeed(username string) {
+	rl.mx.Lock()
+	delete(rl.users, username)
+	rl.mx.Unlock()
+}
+
+// clean removes all counters, whose window and lockout are over. It runs at
+// most once per window. The mutex must be locked.
+func (rl *rateLimiter) clean(now time.Time) {
+	if now.Sub(rl.lastClean) < rl.limits.Window {
+		return
+	}
+	rl.lastClean = now
+	for _, counters := range []map[string]*failCounter{rl.ips, rl.users} {
+		for key, fc := range counters {
+			if now.Sub(fc.start) >= rl.limits.Window && !now.Before(fc.lockedUntil) {
+				delete(counters, key)
+			}
+		}
+	}
+}
+
+func (rl *rateLimiter) lockoutCount(now time.Time) int {
+	rl.mx.Lock()
+	defer rl.mx.Unlock()
+	count := 0
+	for _, counters := range []map[string]*failCounter{rl.ips, rl.users} {
+		for _, fc := range counters {
+			if now.Before(fc.lockedUntil) {
+				count++
+			}
+		}
+	}
+	return count
+}
@@ -0,0 +1,179 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2024-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2024-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package login_test
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"t73f.de/r/webs/login"
+)
+
+func newRateProvider(rl login.RateLimits) (*login.Provider, *[]login.EventKind) {
+	lp := login.MakeProvider(slog.New(slog.DiscardHandler), &login.TestAuthenticator{}, &login.RAMSessions{}, &login.SimpleRedirector{})
+	lp.SetRateLimits(rl)
+	var events []login.EventKind
+	lp.SetEventSink(login.EventSinkFunc(func(_ context.Context, ev login.Event) { events = append(events, ev.Kind) }))
+	return lp, &events
+}
+
+var tryPort atomic.Int64
+
+// tryLogin returns true, if the login was successful. Each attempt uses a
+// new connection, and therefore a new source port.
+func tryLogin(lp *login.Provider, remote, username, password string) bool {
+	port := 1024 + tryPort.Add(1)%60000
+	return tryLoginFrom(lp, net.JoinHostPort(remote, strconv.FormatInt(port, 10)), username, password)
+}
+
+// tryLoginFrom returns true, if the login from the given address was
+// successful.
+func tryLoginFrom(lp *login.Provider, addr, username, password string) bool {
+	form := url.Values{lp.UsernameKey: {username}, lp.PasswordKey: {password}}
+	r := httptest.NewRequest(http.MethodPost, "/login/", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.RemoteAddr = addr
+	w := httptest.NewRecorder()
+	lp.Login().ServeHTTP(w, r)
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Value != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRateLimits(t *testing.T) {
+	type attempt struct {
+		remote, username, password string
+		success                    bool
+	}
+	limits := login.RateLimits{Window: time.Minute, MaxFailsIP: 3, MaxFailsUser: 2, Lockout: time.Minute}
+	testcases := []struct {
+		name     string
+		attempts []attempt
+	}{
+		{"spray", []attempt{
+			{"192.0.2.1", "xa", "pw", false},
+			{"192.0.2.1", "xb", "pw", false},
+			{"192.0.2.1", "xc", "pw", false},
+			{"192.0.2.1", "alice", "pw", false},
+			{"192.0.2.2", "alice", "pw", true},
+		}},
+		{"username", []attempt{
+			{"192.0.2.1", "quinn", "wrong", false},
+			{"192.0.2.2", "quinn", "wrong", false},
+			{"192.0.2.3", "quinn", "quinn", false},
+			{"192.0.2.3", "alice", "pw", true},
+		}},
+		{"reset", []attempt{
+			{"192.0.2.1", "quinn", "wrong", false},
+			{"192.0.2.2", "quinn", "quinn", true},
+			{"192.0.2.3", "quinn", "wrong", false},
+			{"192.0.2.4", "quinn", "quinn", true},
+		}},
+		{"spray-own-account", []attempt{
+			{"192.0.2.1", "xa", "pw", false},
+			{"192.0.2.1", "xb", "pw", false},
+			{"192.0.2.1", "alice", "pw", true},
+			{"192.0.2.1", "xc", "pw", false},
+			{"192.0.2.1", "alice", "pw", false},
+		}},
+		{"invalid", []attempt{
+			{"192.0.2.1", "", "pw", false},
+			{"192.0.2.1", "", "pw", false},
+			{"192.0.2.1", "", "pw", false},
+			{"192.0.2.1", "alice", "pw", true},
+		}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			lp, _ := newRateProvider(limits)
+			for i, at := range tc.attempts {
+				if got := tryLogin(lp, at.remote, at.username, at.password); got != at.success {
+					t.Errorf("attempt %d %v: success %v expected, got %v", i, at, at.success, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimitsLockout(t *testing.T) {
+	lp, events := newRateProvider(login.RateLimits{Window: time.Minute, MaxFailsIP: 2, Lockout: 100 * time.Millisecond})
+	for _, username := range []string{"xa", "xb"} {
+		tryLogin(lp, "192.0.2.1", username, "pw")
+	}
+	if n, _ := lp.LockoutCount(context.Background()); n != 1 {
+		t.Errorf("one lockout expected, got %d", n)
+	}
+	if tryLogin(lp, "192.0.2.1", "alice", "pw") {
+		t.Error("locked out address must not log in")
+	}
+	if got := (*events)[len(*events)-1]; got != login.EventLoginLocked {
+		t.Errorf("event %v expected, got %v", login.EventLoginLocked, got)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	if n, _ := lp.LockoutCount(context.Background()); n != 0 {
+		t.Errorf("no lockout expected, got %d", n)
+	}
+	if !tryLogin(lp, "192.0.2.1", "alice", "pw") {
+		t.Error("login must succeed after lockout")
+	}
+
+	// Each attempt comes from another port of the same address.
+	lp, _ = newRateProvider(login.RateLimits{Window: time.Minute, MaxFailsIP: 3, Lockout: time.Minute})
+	for i, username := range []string{"xa", "xb", "xc"} {
+		addr := fmt.Sprintf("192.0.2.7:%d", 40000+i)
+		if tryLoginFrom(lp, addr, username, "pw") {
+			t.Fatalf("login of %s must fail", username)
+		}
+	}
+	if tryLoginFrom(lp, "192.0.2.7:40100", "alice", "pw") {
+		t.Error("locked out address must not log in from another port")
+	}
+	if !tryLoginFrom(lp, "192.0.2.8:40100", "alice", "pw") {
+		t.Error("other address must log in")
+	}
+
+	// Without limits, there is no lockout.
+	lp, _ = newRateProvider(login.RateLimits{})
+	for _, username := range []string{"xa", "xb", "xc", "xd", "xe", "xf"} {
+		tryLogin(lp, "192.0.2.1", username, "pw")
+	}
+	if !tryLogin(lp, "192.0.2.1", "alice", "pw") {
+		t.Error("login must succeed without limits")
+	}
+}
+
+func TestRateLimitsDefault(t *testing.T) {
+	lp := login.MakeProvider(slog.New(slog.DiscardHandler), &login.TestAuthenticator{}, &login.RAMSessions{}, &login.SimpleRedirector{})
+	if got := lp.RateLimits(); got != login.DefaultRateLimits {
+		t.Errorf("%v expected, got %v", login.DefaultRateLimits, got)
+	}
+	// Repeated logins of a username are rejected for a while.
+	if !tryLogin(lp, "192.0.2.1", "alice", "pw") || tryLogin(lp, "192.0.2.1", "alice", "pw") {
+		t.Error("second login of alice must be rejected")
+	}
+}
@@ -32,7 +32,13 @@ func (sr *SimpleRedirector) LoginRedirect(w http.ResponseWriter, r *http.Request
 }
 
 // SuccessRedirect performs a redirection after the user was successfully authenticated.
+// The user returns to the originally requested URL, if there is one, see
+// [Provider.EnableReturnTo].
 func (sr *SimpleRedirector) SuccessRedirect(w http.ResponseWriter, r *http.Request, _ UserInfo) {
+	if target := ReturnURL(r.Context()); target != "" {
+		http.Redirect(w, r, target, http.StatusSeeOther)
+		return
+	}
 	if sr.SuccessURL == "" {
 		sr.SuccessURL = "/"
 	}
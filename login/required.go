@@ -0,0 +1,81 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2024-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2024-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package login
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// RequiredStatus returns a middleware that ensures a logged-in user, like
+// [Provider.Required], but answers a request of an anonymous user with the
+// given status code and a JSON error object, instead of redirecting to the
+// login page, e.g. for requests of scripts. The response has no
+// "WWW-Authenticate" header. A status code that is not a client error
+// status is replaced by 401.
+//
+// The session is validated and the gates are checked as by Required.
+func (lp *Provider) RequiredStatus(code int) func(http.Handler) http.Handler {
+	if code < 400 || code > 499 {
+		code = http.StatusUnauthorized
+	}
+	deny := func(w http.ResponseWriter, _ *http.Request) { lp.denyStatus(w, code) }
+	return func(next http.Handler) http.Handler { return lp.required(next, nil, deny) }
+}
+
+// RequiredAPI ensures a logged-in user, like [Provider.RequiredStatus] with
+// status code 401.
+func (lp *Provider) RequiredAPI(next http.Handler) http.Handler {
+	return lp.RequiredStatus(http.StatusUnauthorized)(next)
+}
+
+// RequiredNegotiate ensures a logged-in user. An anonymous user is
+// redirected to the login page, like [Provider.Required], unless the request
+// was sent by a script: then it is answered like [Provider.RequiredAPI]. A
+// request was sent by a script, if its "X-Requested-With" header is
+// "XMLHttpRequest", or if it accepts "application/json", but not
+// "text/html".
+func (lp *Provider) RequiredNegotiate(next http.Handler) http.Handler {
+	return lp.required(next, nil, func(w http.ResponseWriter, r *http.Request) {
+		if isScriptRequest(r) {
+			lp.denyStatus(w, http.StatusUnauthorized)
+		} else {
+			lp.requiredRedirect(w, r)
+		}
+	})
+}
+
+func isScriptRequest(r *http.Request) bool {
+	if strings.EqualFold(r.Header.Get("X-Requested-With"), "XMLHttpRequest") {
+		return true
+	}
+	accept := strings.Join(r.Header.Values("Accept"), ",")
+	return strings.Contains(accept, "application/json") && !strings.Contains(accept, "text/html")
+}
+
+// denyStatus answers a request of an anonymous user with a status code and
+// a JSON error object.
+func (lp *Provider) denyStatus(w http.ResponseWriter, code int) {
+	lp.clearAuthCookie(w)
+	body, _ := json.Marshal(struct {
+		Error  string `json:"error"`
+		Status int    `json:"status"`
+	}{"login required", code})
+	h := w.Header()
+	h.Set("Content-Type", "application/json")
+	h.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(code)
+	_, _ = w.Write(body)
+}
@@ -0,0 +1,94 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2024-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2024-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package login_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"t73f.de/r/webs/login"
+)
+
+func TestRequiredVariants(t *testing.T) {
+	lp := login.MakeProvider(slog.New(slog.DiscardHandler), &login.TestAuthenticator{}, &login.RAMSessions{}, &login.SimpleRedirector{})
+	cookie := loginCookie(t, lp, "alice")
+	variants := map[string]func(http.Handler) http.Handler{
+		"required":  lp.Required,
+		"status403": lp.RequiredStatus(http.StatusForbidden),
+		"status200": lp.RequiredStatus(http.StatusOK),
+		"api":       lp.RequiredAPI,
+		"negotiate": lp.RequiredNegotiate,
+	}
+
+	testcases := []struct {
+		variant string
+		header  []string
+		expCode int // 0: redirect to login page
+	}{
+		{"required", []string{"Accept", "application/json"}, 0},
+		{"status403", nil, http.StatusForbidden},
+		{"status200", nil, http.StatusUnauthorized},
+		{"api", []string{"Accept", "text/html"}, http.StatusUnauthorized},
+		{"negotiate", nil, 0},
+		{"negotiate", []string{"Accept", "text/html,application/xhtml+xml,*/*;q=0.8"}, 0},
+		{"negotiate", []string{"Accept", "text/html, application/json"}, 0},
+		{"negotiate", []string{"Accept", "application/json"}, http.StatusUnauthorized},
+		{"negotiate", []string{"X-Requested-With", "XMLHttpRequest"}, http.StatusUnauthorized},
+		{"negotiate", []string{"Accept", "text/html", "X-Requested-With", "xmlhttprequest"}, http.StatusUnauthorized},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.variant, func(t *testing.T) {
+			h := lp.EnrichUserInfo(variants[tc.variant](sessionHandler()))
+
+			r := httptest.NewRequest(http.MethodGet, "/api", nil)
+			for i := 0; i+1 < len(tc.header); i += 2 {
+				r.Header.Set(tc.header[i], tc.header[i+1])
+			}
+			r.AddCookie(cookie)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != http.StatusOK || !strings.HasPrefix(w.Body.String(), `alice "`) {
+				t.Errorf("logged-in user must pass, got %d %q", w.Code, w.Body.String())
+			}
+
+			r = httptest.NewRequest(http.MethodGet, "/api", nil)
+			for i := 0; i+1 < len(tc.header); i += 2 {
+				r.Header.Set(tc.header[i], tc.header[i+1])
+			}
+			w = httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if tc.expCode == 0 {
+				if w.Code != http.StatusSeeOther {
+					t.Errorf("redirect expected, got %d", w.Code)
+				}
+				return
+			}
+			if w.Code != tc.expCode {
+				t.Errorf("status %d expected, got %d", tc.expCode, w.Code)
+			}
+			if got := w.Header().Get("Content-Type"); got != "application/json" {
+				t.Errorf("JSON expected, got %q", got)
+			}
+			if got := w.Header().Get("WWW-Authenticate"); got != "" {
+				t.Errorf("no challenge expected, got %q", got)
+			}
+			if exp, got := `{"error":"login required","status":`, w.Body.String(); !strings.HasPrefix(got, exp) {
+				t.Errorf("%q expected, got %q", exp, got)
+			}
+		})
+	}
+}
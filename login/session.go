@@ -15,6 +15,7 @@ package login
 
 import (
 	"context"
+	"slices"
 	"sync"
 	"time"
 )
@@ -27,16 +28,29 @@ type RAMSessions struct {
 type sessionData struct {
 	user    UserInfo
 	expires time.Time
+	meta    SessionMeta
 }
 
 // SetUserAuth stores user information to the given seesion.
-func (rs *RAMSessions) SetUserAuth(_ context.Context, userinfo UserInfo, auth SessionID) error {
+func (rs *RAMSessions) SetUserAuth(ctx context.Context, userinfo UserInfo, auth SessionID) error {
+	return rs.SetUserAuthMeta(ctx, userinfo, auth, SessionMeta{Created: time.Now()})
+}
+
+// SetUserAuthMeta stores user information and metadata to the given session.
+// A session with an expiry time in its metadata ends at this time. Otherwise
+// it ends after seven days, which are extended while the session is used.
+func (rs *RAMSessions) SetUserAuthMeta(_ context.Context, userinfo UserInfo, auth SessionID, meta SessionMeta) error {
 	session := sessionData{
 		user:    userinfo,
-		expires: time.Now().Add(7 * 24 * time.Hour),
+		expires: meta.Expires,
+		meta:    meta,
+	}
+	if session.expires.IsZero() {
+		session.expires = time.Now().Add(7 * 24 * time.Hour)
 	}
 
 	rs.mx.Lock()
+	defer rs.mx.Unlock()
 	numSessions := len(rs.sessions)
 	if numSessions == 0 {
 		rs.sessions = map[SessionID]*sessionData{auth: &session}
@@ -45,7 +59,6 @@ func (rs *RAMSessions) SetUserAuth(_ context.Context, userinfo UserInfo, auth Se
 	} else {
 		rs.sessions[auth] = &session
 	}
-	rs.mx.Unlock()
 	return nil
 }
 
@@ -67,7 +80,7 @@ func (rs *RAMSessions) UserAuth(_ context.Context, auth SessionID) (UserInfo, er
 		delete(rs.sessions, auth)
 		return nil, ErrNoSuchSession
 	}
-	if session.expires.Before(now.Add(3 * 24 * time.Hour)) {
+	if session.meta.Expires.IsZero() && session.expires.Before(now.Add(3*24*time.Hour)) {
 		session.expires = now.Add(7 * 24 * time.Hour)
 	}
 	return session.user, nil
@@ -82,3 +95,53 @@ func (rs *RAMSessions) Remove(_ context.Context, auth SessionID) error {
 	rs.mx.Unlock()
 	return nil
 }
+
+// SessionsForUser returns the identifiers of all sessions of the user with
+// the given name, which are not expired.
+func (rs *RAMSessions) SessionsForUser(_ context.Context, username string) ([]SessionID, error) {
+	now := time.Now()
+	var result []SessionID
+	rs.mx.Lock()
+	for sessid, session := range rs.sessions {
+		if !now.After(session.expires) && session.user.Name() == username {
+			result = append(result, sessid)
+		}
+	}
+	rs.mx.Unlock()
+	slices.Sort(result)
+	return result, nil
+}
+
+// SessionCount returns the number of sessions that are not expired.
+func (rs *RAMSessions) SessionCount(context.Context) (int, error) {
+	now := time.Now()
+	count := 0
+	rs.mx.Lock()
+	for _, session := range rs.sessions {
+		if !now.After(session.expires) {
+			count++
+		}
+	}
+	rs.mx.Unlock()
+	return count, nil
+}
+
+// ListSessions returns all sessions that are not expired and that match the
+// filter, ordered by their creation time.
+func (rs *RAMSessions) ListSessions(_ context.Context, filter func(SessionEntry) bool) ([]SessionEntry, error) {
+	now := time.Now()
+	var result []SessionEntry
+	rs.mx.Lock()
+	for sessid, session := range rs.sessions {
+		if now.After(session.expires) {
+			continue
+		}
+		se := SessionEntry{SessionID: sessid, User: session.user, Meta: session.meta}
+		if filter == nil || filter(se) {
+			result = append(result, se)
+		}
+	}
+	rs.mx.Unlock()
+	sortSessionEntries(result)
+	return result, nil
+}
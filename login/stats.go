@@ -0,0 +1,391 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package login
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"hash/maphash"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"t73f.de/r/webs/htmls"
+	"t73f.de/r/webs/htmls/render"
+)
+
+// Default values of [Stats].
+const (
+	DefaultStatsTopN         = 10
+	DefaultStatsMaxUsernames = 10000
+)
+
+// DefaultStatsWindows are the time windows of [Stats], if no windows are
+// given.
+var DefaultStatsWindows = []time.Duration{5 * time.Minute, time.Hour, 24 * time.Hour}
+
+// LockoutCounter is implemented by stores of locked-out users, which are able
+// to report the number of current lockouts.
+type LockoutCounter interface {
+	LockoutCount(context.Context) (int, error)
+}
+
+// SessionCounter is implemented by session managers, which are able to report
+// the number of active sessions, e.g. [RAMSessions].
+type SessionCounter interface {
+	SessionCount(context.Context) (int, error)
+}
+
+// Stats is an [EventSink] that collects statistics about authentication
+// events, e.g. for an admin dashboard. It counts the events of each kind in
+// rolling time windows, and the failed logins per username.
+//
+// Events are counted with a resolution of one minute, based on their time.
+// Counters are updated atomically. Only failed logins need a short lock of
+// one of several shards of the per-username counters.
+type Stats struct {
+	// Next receives all events after they were counted, if not nil.
+	Next EventSink
+
+	// TopN is the number of usernames with the most failed logins in a
+	// snapshot. If not positive, DefaultStatsTopN is used.
+	TopN int
+
+	// Lockouts reports the number of current lockouts, if not nil.
+	Lockouts LockoutCounter
+
+	// Sessions reports the number of active sessions, if not nil.
+	Sessions SessionCounter
+
+	windows     []time.Duration
+	buckets     []statsBucket
+	maxUsers    int
+	numUsers    atomic.Int64
+	seed        maphash.Seed
+	userShards  [statsShards]userShard
+	lastCleanup atomic.Int64 // minute of the last cleanup of the user shards
+}
+
+// statsBucket contains the counts of one minute.
+type statsBucket struct {
+	minute atomic.Int64 // minute since the Unix epoch
+	counts [numEventKinds]atomic.Uint64
+}
+
+const statsShards = 16
+
+type userShard struct {
+	mx    sync.Mutex
+	users map[string]*userFailures
+}
+
+type userFailures struct {
+	count      atomic.Uint64
+	lastMinute atomic.Int64
+}
+
+// NewStats creates a new statistics collector with the given time windows.
+// Windows are rounded up to full minutes. If no windows are given,
+// [DefaultStatsWindows] are used.
+//
+// Failed logins are tracked for at most maxUsernames different usernames, to
+// limit memory usage. If maxUsernames is not positive,
+// DefaultStatsMaxUsernames is used.
+func NewStats(maxUsernames int, windows ...time.Duration) *Stats {
+	if len(windows) == 0 {
+		windows = DefaultStatsWindows
+	}
+	wins := make([]time.Duration, 0, len(windows))
+	longest := int64(1)
+	for _, win := range windows {
+		minutes := max(int64((win+time.Minute-1)/time.Minute), 1)
+		longest = max(longest, minutes)
+		wins = append(wins, time.Duration(minutes)*time.Minute)
+	}
+	if maxUsernames <= 0 {
+		maxUsernames = DefaultStatsMaxUsernames
+	}
+	st := &Stats{
+		windows:  wins,
+		buckets:  make([]statsBucket, longest),
+		maxUsers: maxUsernames,
+		seed:     maphash.MakeSeed(),
+	}
+	for i := range st.buckets {
+		st.buckets[i].minute.Store(-1)
+	}
+	return st
+}
+
+// Emit counts the event, and forwards it to the next event sink.
+func (st *Stats) Emit(ctx context.Context, ev Event) {
+	st.record(ev)
+	if next := st.Next; next != nil {
+		next.Emit(ctx, ev)
+	}
+}
+
+func (st *Stats) record(ev Event) {
+	if ev.Kind == 0 || ev.Kind >= numEventKinds {
+		return
+	}
+	t := ev.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	minute := t.Unix() / 60
+	if minute < 0 {
+		return
+	}
+	b := &st.buckets[minute%int64(len(st.buckets))]
+	for {
+		cur := b.minute.Load()
+		if cur == minute {
+			break
+		}
+		if cur > minute {
+			return // event is too old, the bucket was already reused
+		}
+		if b.minute.CompareAndSwap(cur, minute) {
+			for i := range b.counts {
+				b.counts[i].Store(0)
+			}
+			break
+		}
+	}
+	b.counts[ev.Kind].Add(1)
+
+	if ev.Kind == EventLoginFailed && ev.Username != "" {
+		st.recordFailure(ev.Username, minute)
+	}
+}
+
+func (st *Stats) shard(username string) *userShard {
+	return &st.userShards[maphash.String(st.seed, username)%statsShards]
+}
+
+func (st *Stats) recordFailure(username string, minute int64) {
+	shard := st.shard(username)
+	shard.mx.Lock()
+	uf, found := shard.users[username]
+	if !found {
+		if st.numUsers.Load() >= int64(st.maxUsers) {
+			shard.mx.Unlock()
+			return
+		}
+		if shard.users == nil {
+			shard.users = map[string]*userFailures{}
+		}
+		uf = &userFailures{}
+		shard.users[username] = uf
+		st.numUsers.Add(1)
+	}
+	shard.mx.Unlock()
+	uf.count.Add(1)
+	for {
+		last := uf.lastMinute.Load()
+		if last >= minute || uf.lastMinute.CompareAndSwap(last, minute) {
+			break
+		}
+	}
+}
+
+// cleanupUsers removes all usernames without a failed login within the
+// longest window. It is done at most once per minute.
+func (st *Stats) cleanupUsers(minute int64) {
+	last := st.lastCleanup.Load()
+	if last >= minute || !st.lastCleanup.CompareAndSwap(last, minute) {
+		return
+	}
+	oldest := minute - int64(len(st.buckets))
+	for i := range st.userShards {
+		shard := &st.userShards[i]
+		shard.mx.Lock()
+		for name, uf := range shard.users {
+			if uf.lastMinute.Load() <= oldest {
+				delete(shard.users, name)
+				st.numUsers.Add(-1)
+			}
+		}
+		shard.mx.Unlock()
+	}
+}
+
+// StatsSnapshot contains the statistics at a specific time.
+type StatsSnapshot struct {
+	Time           time.Time         `json:"time"`
+	Windows        []WindowStats     `json:"windows"`
+	TopFailures    []UsernameFailure `json:"top_failures"`
+	Lockouts       *int              `json:"lockouts,omitempty"`
+	ActiveSessions *int              `json:"active_sessions,omitempty"`
+}
+
+// WindowStats contains the number of events of each kind within a time
+// window. The keys of Counts are the names of the event kinds.
+type WindowStats struct {
+	Window time.Duration     `json:"-"`
+	Counts map[string]uint64 `json:"counts"`
+}
+
+// MarshalJSON encodes the window as a string, e.g. "5m0s".
+func (ws WindowStats) MarshalJSON() ([]byte, error) {
+	type plainStats WindowStats
+	return json.Marshal(struct {
+		Window string `json:"window"`
+		plainStats
+	}{ws.Window.String(), plainStats(ws)})
+}
+
+// UsernameFailure contains the number of failed logins of a username.
+type UsernameFailure struct {
+	Username string `json:"username"`
+	Failures uint64 `json:"failures"`
+}
+
+// Snapshot returns the current statistics.
+func (st *Stats) Snapshot() *StatsSnapshot {
+	return st.SnapshotAt(context.Background(), time.Now())
+}
+
+// SnapshotAt returns the statistics at the given time. The context is used
+// to retrieve the number of lockouts and active sessions.
+//
+// The failures of a username are counted since its first failed login,
+// unless there was no failed login within the longest window.
+func (st *Stats) SnapshotAt(ctx context.Context, now time.Time) *StatsSnapshot {
+	minute := now.Unix() / 60
+	snap := &StatsSnapshot{Time: now, Windows: make([]WindowStats, 0, len(st.windows))}
+	for _, win := range st.windows {
+		oldest := minute - int64(win/time.Minute)
+		var counts [numEventKinds]uint64
+		for i := range st.buckets {
+			b := &st.buckets[i]
+			if m := b.minute.Load(); oldest < m && m <= minute {
+				for kind := range counts {
+					counts[kind] += b.counts[kind].Load()
+				}
+			}
+		}
+		ws := WindowStats{Window: win, Counts: make(map[string]uint64, numEventKinds-1)}
+		for kind := EventKind(1); kind < numEventKinds; kind++ {
+			ws.Counts[kind.String()] = counts[kind]
+		}
+		snap.Windows = append(snap.Windows, ws)
+	}
+
+	st.cleanupUsers(minute)
+	snap.TopFailures = st.topFailures(minute)
+
+	if lc := st.Lockouts; lc != nil {
+		if n, err := lc.LockoutCount(ctx); err == nil {
+			snap.Lockouts = &n
+		}
+	}
+	if sc := st.Sessions; sc != nil {
+		if n, err := sc.SessionCount(ctx); err == nil {
+			snap.ActiveSessions = &n
+		}
+	}
+	return snap
+}
+
+func (st *Stats) topFailures(minute int64) []UsernameFailure {
+	oldest := minute - int64(len(st.buckets))
+	result := []UsernameFailure{}
+	for i := range st.userShards {
+		shard := &st.userShards[i]
+		shard.mx.Lock()
+		for name, uf := range shard.users {
+			if uf.lastMinute.Load() > oldest {
+				result = append(result, UsernameFailure{Username: name, Failures: uf.count.Load()})
+			}
+		}
+		shard.mx.Unlock()
+	}
+	slices.SortFunc(result, func(a, b UsernameFailure) int {
+		if c := cmp.Compare(b.Failures, a.Failures); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Username, b.Username)
+	})
+	topN := st.TopN
+	if topN <= 0 {
+		topN = DefaultStatsTopN
+	}
+	if len(result) > topN {
+		result = result[:topN]
+	}
+	return result
+}
+
+// Handler returns a handler that serves the current statistics. If the
+// request accepts "application/json", or if the query parameter "format" has
+// the value "json", the snapshot is encoded as JSON. Otherwise, it is
+// rendered as a HTML table, see [StatsSnapshot.Render].
+func (st *Stats) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		snap := st.SnapshotAt(r.Context(), time.Now())
+		h := w.Header()
+		h.Set("Cache-Control", "no-store")
+		if r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json") {
+			h.Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(snap)
+			return
+		}
+		h.Set("Content-Type", "text/html; charset=utf-8")
+		_ = render.Render(w, snap.Render())
+	})
+}
+
+// Render the snapshot as a HTML table.
+func (snap *StatsSnapshot) Render() *htmls.Node {
+	headRow := htmls.Elem("tr", nil, htmls.Elem("th", htmls.Attrs("scope", "col"), htmls.Text("Event")))
+	for _, ws := range snap.Windows {
+		headRow.AddChildren(htmls.Elem("th", htmls.Attrs("scope", "col"), htmls.Text(ws.Window.String())))
+	}
+	rows := []*htmls.Node{}
+	for kind := EventKind(1); kind < numEventKinds; kind++ {
+		row := htmls.Elem("tr", nil, htmls.Elem("th", htmls.Attrs("scope", "row"), htmls.Text(kind.String())))
+		for _, ws := range snap.Windows {
+			row.AddChildren(htmls.Elem("td", nil, htmls.Text(strconv.FormatUint(ws.Counts[kind.String()], 10))))
+		}
+		rows = append(rows, row)
+	}
+	for _, gauge := range []struct {
+		name  string
+		value *int
+	}{{"lockouts", snap.Lockouts}, {"active-sessions", snap.ActiveSessions}} {
+		if gauge.value != nil {
+			rows = append(rows, htmls.Elem("tr", nil,
+				htmls.Elem("th", htmls.Attrs("scope", "row"), htmls.Text(gauge.name)),
+				htmls.Elem("td", htmls.Attrs("colspan", strconv.Itoa(len(snap.Windows))), htmls.Text(strconv.Itoa(*gauge.value))),
+			))
+		}
+	}
+	for _, uf := range snap.TopFailures {
+		rows = append(rows, htmls.Elem("tr", htmls.Attrs("class", "top-failure"),
+			htmls.Elem("th", htmls.Attrs("scope", "row"), htmls.Text(uf.Username)),
+			htmls.Elem("td", htmls.Attrs("colspan", strconv.Itoa(len(snap.Windows))), htmls.Text(strconv.FormatUint(uf.Failures, 10))),
+		))
+	}
+	return htmls.Elem("table", htmls.Attrs("class", "login-stats"),
+		htmls.Elem("thead", nil, headRow),
+		htmls.Elem("tbody", nil, rows...),
+	)
+}
@@ -0,0 +1,227 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package login_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"t73f.de/r/webs/login"
+)
+
+var statsStart = time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+func emitAt(st *login.Stats, minutes int, kind login.EventKind, username string) {
+	st.Emit(context.Background(), login.Event{
+		Kind:     kind,
+		Time:     statsStart.Add(time.Duration(minutes) * time.Minute),
+		Username: username,
+	})
+}
+
+func windowCounts(snap *login.StatsSnapshot, kind login.EventKind) []uint64 {
+	result := make([]uint64, 0, len(snap.Windows))
+	for _, ws := range snap.Windows {
+		result = append(result, ws.Counts[kind.String()])
+	}
+	return result
+}
+
+func TestStatsWindows(t *testing.T) {
+	st := login.NewStats(0, 5*time.Minute, time.Hour)
+	emitAt(st, 0, login.EventLogin, "alice")
+	emitAt(st, 1, login.EventLogin, "bob")
+	emitAt(st, 1, login.EventLoginFailed, "mallory")
+	emitAt(st, 30, login.EventLogin, "alice")
+	emitAt(st, 58, login.EventLogout, "alice")
+
+	testcases := []struct {
+		minutes int
+		logins  []uint64
+		failed  []uint64
+		logouts []uint64
+	}{
+		{0, []uint64{1, 1}, []uint64{0, 0}, []uint64{0, 0}},
+		{4, []uint64{2, 2}, []uint64{1, 1}, []uint64{0, 0}},
+		{5, []uint64{1, 2}, []uint64{1, 1}, []uint64{0, 0}},
+		{6, []uint64{0, 2}, []uint64{0, 1}, []uint64{0, 0}},
+		{59, []uint64{0, 3}, []uint64{0, 1}, []uint64{1, 1}},
+		{60, []uint64{0, 2}, []uint64{0, 1}, []uint64{1, 1}},
+		{61, []uint64{0, 1}, []uint64{0, 0}, []uint64{1, 1}},
+		{200, []uint64{0, 0}, []uint64{0, 0}, []uint64{0, 0}},
+	}
+	for _, tc := range testcases {
+		snap := st.SnapshotAt(context.Background(), statsStart.Add(time.Duration(tc.minutes)*time.Minute))
+		if got := windowCounts(snap, login.EventLogin); !slices.Equal(got, tc.logins) {
+			t.Errorf("minute %d: logins %v expected, got %v", tc.minutes, tc.logins, got)
+		}
+		if got := windowCounts(snap, login.EventLoginFailed); !slices.Equal(got, tc.failed) {
+			t.Errorf("minute %d: failures %v expected, got %v", tc.minutes, tc.failed, got)
+		}
+		if got := windowCounts(snap, login.EventLogout); !slices.Equal(got, tc.logouts) {
+			t.Errorf("minute %d: logouts %v expected, got %v", tc.minutes, tc.logouts, got)
+		}
+	}
+}
+
+func TestStatsRollOver(t *testing.T) {
+	st := login.NewStats(0, 10*time.Minute)
+	// Many rounds through the ring of buckets.
+	for minute := range 100 {
+		for range minute % 3 {
+			emitAt(st, minute, login.EventLoginFailed, "")
+		}
+	}
+	snap := st.SnapshotAt(context.Background(), statsStart.Add(99*time.Minute))
+	var exp uint64
+	for minute := 90; minute < 100; minute++ {
+		exp += uint64(minute % 3)
+	}
+	if got := windowCounts(snap, login.EventLoginFailed); !slices.Equal(got, []uint64{exp}) {
+		t.Errorf("%d failures expected, got %v", exp, got)
+	}
+
+	// Events older than the ring are ignored.
+	emitAt(st, 50, login.EventLoginFailed, "")
+	snap = st.SnapshotAt(context.Background(), statsStart.Add(99*time.Minute))
+	if got := windowCounts(snap, login.EventLoginFailed); !slices.Equal(got, []uint64{exp}) {
+		t.Errorf("old event must be ignored, got %v", got)
+	}
+}
+
+func TestStatsTopFailures(t *testing.T) {
+	st := login.NewStats(3, 5*time.Minute, time.Hour)
+	st.TopN = 2
+	for i, name := range []string{"carol", "bob", "alice", "bob", "carol", "dave", "carol"} {
+		emitAt(st, i, login.EventLoginFailed, name)
+	}
+	snap := st.SnapshotAt(context.Background(), statsStart.Add(10*time.Minute))
+	exp := []login.UsernameFailure{{Username: "carol", Failures: 3}, {Username: "bob", Failures: 2}}
+	if !slices.Equal(snap.TopFailures, exp) {
+		t.Errorf("%v expected, got %v", exp, snap.TopFailures)
+	}
+	st.TopN = 10
+	snap = st.SnapshotAt(context.Background(), statsStart.Add(10*time.Minute))
+	if got := len(snap.TopFailures); got != 3 {
+		t.Errorf("only three usernames must be tracked, got %v", snap.TopFailures)
+	}
+
+	// After the longest window, the usernames are forgotten.
+	snap = st.SnapshotAt(context.Background(), statsStart.Add(2*time.Hour))
+	if len(snap.TopFailures) != 0 {
+		t.Errorf("no failures expected, got %v", snap.TopFailures)
+	}
+	emitAt(st, 121, login.EventLoginFailed, "dave")
+	snap = st.SnapshotAt(context.Background(), statsStart.Add(122*time.Minute))
+	if exp = []login.UsernameFailure{{Username: "dave", Failures: 1}}; !slices.Equal(snap.TopFailures, exp) {
+		t.Errorf("%v expected, got %v", exp, snap.TopFailures)
+	}
+}
+
+func TestStatsConcurrent(t *testing.T) {
+	st := login.NewStats(0)
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			for range 1000 {
+				emitAt(st, 0, login.EventLoginFailed, "eve")
+			}
+		})
+	}
+	wg.Wait()
+	snap := st.SnapshotAt(context.Background(), statsStart)
+	if got := windowCounts(snap, login.EventLoginFailed); !slices.Equal(got, []uint64{8000, 8000, 8000}) {
+		t.Errorf("8000 failures expected, got %v", got)
+	}
+	if got := snap.TopFailures; len(got) != 1 || got[0].Failures != 8000 {
+		t.Errorf("8000 failures of eve expected, got %v", got)
+	}
+}
+
+type lockoutCounter int
+
+func (lc lockoutCounter) LockoutCount(context.Context) (int, error) { return int(lc), nil }
+
+func TestStatsProvider(t *testing.T) {
+	sessions := &login.RAMSessions{}
+	lp := login.MakeProvider(slog.New(slog.DiscardHandler), &login.TestAuthenticator{}, sessions, &login.SimpleRedirector{})
+	var forwarded []login.EventKind
+	st := login.NewStats(0)
+	st.Next = login.EventSinkFunc(func(_ context.Context, ev login.Event) { forwarded = append(forwarded, ev.Kind) })
+	st.Sessions = sessions
+	st.Lockouts = lockoutCounter(2)
+	lp.SetEventSink(st)
+
+	for _, username := range []string{"alice", "xavier", ""} {
+		form := url.Values{lp.UsernameKey: {username}, lp.PasswordKey: {"secret"}}
+		r := httptest.NewRequest(http.MethodPost, "/login/", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		lp.Login().ServeHTTP(httptest.NewRecorder(), r)
+	}
+	exp := []login.EventKind{login.EventLogin, login.EventLoginFailed, login.EventLoginInvalid}
+	if !slices.Equal(forwarded, exp) {
+		t.Errorf("events %v expected, got %v", exp, forwarded)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/stats", nil)
+	r.Header.Set("Accept", "application/json")
+	rr := httptest.NewRecorder()
+	st.Handler().ServeHTTP(rr, r)
+	var snap struct {
+		Windows []struct {
+			Window string            `json:"window"`
+			Counts map[string]uint64 `json:"counts"`
+		} `json:"windows"`
+		TopFailures    []login.UsernameFailure `json:"top_failures"`
+		Lockouts       *int                    `json:"lockouts"`
+		ActiveSessions *int                    `json:"active_sessions"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &snap); err != nil {
+		t.Fatalf("invalid JSON %q: %v", rr.Body.String(), err)
+	}
+	if len(snap.Windows) != 3 || snap.Windows[0].Window != "5m0s" {
+		t.Fatalf("three windows expected, got %v", snap.Windows)
+	}
+	if counts := snap.Windows[0].Counts; counts["login"] != 1 || counts["login-failed"] != 1 || counts["login-invalid"] != 1 {
+		t.Errorf("one event of each kind expected, got %v", counts)
+	}
+	if got := snap.TopFailures; len(got) != 1 || got[0].Username != "xavier" {
+		t.Errorf("failure of xavier expected, got %v", got)
+	}
+	if snap.ActiveSessions == nil || *snap.ActiveSessions != 1 {
+		t.Errorf("one active session expected, got %v", snap.ActiveSessions)
+	}
+	if snap.Lockouts == nil || *snap.Lockouts != 2 {
+		t.Errorf("two lockouts expected, got %v", snap.Lockouts)
+	}
+
+	rr = httptest.NewRecorder()
+	st.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/stats", nil))
+	body := rr.Body.String()
+	for _, s := range []string{`<table class="login-stats">`, `<th scope="row">login-failed</th><td>1</td><td>1</td><td>1</td>`,
+		`<th scope="row">active-sessions</th><td colspan="3">1</td>`, `<tr class="top-failure"><th scope="row">xavier</th>`} {
+		if !strings.Contains(body, s) {
+			t.Errorf("%q expected in %s", s, body)
+		}
+	}
+}
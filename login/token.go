@@ -0,0 +1,183 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2024-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2024-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package login
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TokenAuthenticator allows to authenticate a machine client by an API token.
+type TokenAuthenticator interface {
+	// AuthenticateToken checks the given token, giving some data about the
+	// user the token belongs to.
+	AuthenticateToken(ctx context.Context, token string) (UserInfo, error)
+}
+
+// ErrInvalidToken is signaled if the given API token is not valid.
+var ErrInvalidToken = errors.New("invalid token")
+
+// SetTokenAuthenticator enables authentication by API tokens.
+//
+// The token is read from the header named in TokenHeader. If it is the
+// "Authorization" header, the "Bearer" scheme is expected.
+func (lp *Provider) SetTokenAuthenticator(ta TokenAuthenticator) { lp.tokens = ta }
+
+func (lp *Provider) requestToken(r *http.Request) (string, bool) {
+	if lp.tokens == nil {
+		return "", false
+	}
+	return lp.headerToken(r)
+}
+
+// headerToken returns the API token of the request, read from the header
+// named in TokenHeader.
+func (lp *Provider) headerToken(r *http.Request) (string, bool) {
+	if lp.TokenHeader == "" {
+		return "", false
+	}
+	value := strings.TrimSpace(r.Header.Get(lp.TokenHeader))
+	if value == "" {
+		return "", false
+	}
+	if http.CanonicalHeaderKey(lp.TokenHeader) == "Authorization" {
+		scheme, token, found := strings.Cut(value, " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") {
+			return "", false
+		}
+		value = strings.TrimSpace(token)
+	}
+	return value, true
+}
+
+func (lp *Provider) checkToken(r *http.Request, token string) (*SessionInfo, error) {
+	return lp.checkTokenWith(r, token, lp.tokens.AuthenticateToken)
+}
+
+// checkTokenWith checks the token of the request with the given lookup
+// function. Failed lookups are rated per client host, not per token, so
+// that guessing many different tokens is throttled too. The host is taken
+// from the connection, since a header like "X-Forwarded-For" could be
+// changed with every guess. Failed lookups also count as failed logins of
+// the client host, see [RateLimits].
+func (lp *Provider) checkTokenWith(r *http.Request, token string, lookup func(context.Context, string) (UserInfo, error)) (*SessionInfo, error) {
+	ctx := r.Context()
+	if token == "" || len(token) > 4*lp.PassLen {
+		lp.logger.InfoContext(ctx, "invalid token attempt")
+		lp.emit(r, EventTokenFailed, "", ErrInvalidToken)
+		return nil, ErrInvalidToken
+	}
+	hasher := sha512.New512_256()
+	hasher.Write([]byte(token))
+	sessid := SessionID(lp.asHex(hasher))
+
+	remote := clientHost(r)
+	if lp.limiter.isLocked(remote, "", time.Now()) {
+		lp.logger.InfoContext(ctx, "token locked", "remote", remote)
+		lp.emit(r, EventTokenLocked, "", nil)
+		return nil, ErrInvalidToken
+	}
+	rateKey := "\x00token:" + remote
+	if lp.isRated(rateKey) {
+		lp.logger.InfoContext(ctx, "token rated", "remote", remote)
+		lp.emit(r, EventTokenRated, "", nil)
+		return nil, ErrInvalidToken
+	}
+	userinfo, err := lookup(ctx, token)
+	if err != nil {
+		lp.logger.InfoContext(ctx, "token failed", "error", err)
+		lp.emit(r, EventTokenFailed, "", err)
+		lp.rateAndWait(rateKey)
+		if lp.limiter.fail(remote, "", time.Now()) {
+			lp.logger.WarnContext(ctx, "token lockout", "remote", remote)
+		}
+		return nil, err
+	}
+	return &SessionInfo{SessionID: sessid, User: userinfo, ByToken: true}, nil
+}
+
+// clientHost returns the host of the connection of the request.
+func clientHost(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+func (lp *Provider) isRated(key string) bool {
+	lp.mxAuthProgress.Lock()
+	_, found := lp.authProgress[key]
+	lp.mxAuthProgress.Unlock()
+	return found
+}
+
+// RAMTokens is a TokenAuthenticator that stores its tokens in main memory.
+//
+// Tokens are only stored as hash values. Since the lookup is done with the
+// hash value of a given token, the lookup time does not depend on the
+// similarity of the token to a stored token.
+type RAMTokens struct {
+	mx     sync.RWMutex // protect the following map
+	tokens map[[sha512.Size256]byte]UserInfo
+}
+
+// Create a new random token for the given user and store it.
+func (rt *RAMTokens) Create(userinfo UserInfo) (string, error) {
+	var buf [32]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf[:])
+	rt.Add(token, userinfo)
+	return token, nil
+}
+
+// Add the given token for the given user.
+func (rt *RAMTokens) Add(token string, userinfo UserInfo) {
+	key := sha512.Sum512_256([]byte(token))
+	rt.mx.Lock()
+	if rt.tokens == nil {
+		rt.tokens = map[[sha512.Size256]byte]UserInfo{}
+	}
+	rt.tokens[key] = userinfo
+	rt.mx.Unlock()
+}
+
+// Remove the given token. It cannot be used any more.
+func (rt *RAMTokens) Remove(token string) {
+	key := sha512.Sum512_256([]byte(token))
+	rt.mx.Lock()
+	delete(rt.tokens, key)
+	rt.mx.Unlock()
+}
+
+// AuthenticateToken returns the user information of the given token.
+func (rt *RAMTokens) AuthenticateToken(_ context.Context, token string) (UserInfo, error) {
+	key := sha512.Sum512_256([]byte(token))
+	rt.mx.RLock()
+	userinfo, found := rt.tokens[key]
+	rt.mx.RUnlock()
+	if !found {
+		return nil, ErrInvalidToken
+	}
+	return userinfo, nil
+}
@@ -0,0 +1,217 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2024-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2024-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package login_test
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"t73f.de/r/webs/login"
+)
+
+type testUser string
+
+func (u testUser) Name() string { return string(u) }
+
+func newTestProvider(t *testing.T) (*login.Provider, *login.RAMTokens, http.Handler) {
+	t.Helper()
+	lp := login.MakeProvider(
+		slog.New(slog.DiscardHandler),
+		&login.TestAuthenticator{},
+		&login.RAMSessions{},
+		&login.SimpleRedirector{},
+	)
+	tokens := &login.RAMTokens{}
+	lp.SetTokenAuthenticator(tokens)
+	h := lp.EnrichUserInfo(lp.Required(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session := login.Session(r.Context())
+		fmt.Fprintf(w, "%s %v", session.User.Name(), session.ByToken)
+	})))
+	return lp, tokens, h
+}
+
+func loginCookie(t *testing.T, lp *login.Provider, username string) *http.Cookie {
+	t.Helper()
+	form := url.Values{lp.UsernameKey: {username}, lp.PasswordKey: {username}}
+	r := httptest.NewRequest(http.MethodPost, "/login/", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	lp.Login().ServeHTTP(w, r)
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Value != "" {
+			return cookie
+		}
+	}
+	t.Fatal("no login cookie")
+	return nil
+}
+
+func TestTokenAuthentication(t *testing.T) {
+	lp, tokens, h := newTestProvider(t)
+	token, err := tokens.Create(testUser("robot"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cookie := loginCookie(t, lp, "human")
+
+	testcases := []struct {
+		name    string
+		cookie  *http.Cookie
+		auth    string
+		expCode int
+		expBody string
+	}{
+		{"anonymous", nil, "", http.StatusSeeOther, ""},
+		{"cookie-only", cookie, "", http.StatusOK, "human false"},
+		{"token-only", nil, "Bearer " + token, http.StatusOK, "robot true"},
+		{"both", cookie, "Bearer " + token, http.StatusOK, "robot true"},
+		{"invalid-token", nil, "Bearer invalid", http.StatusUnauthorized, ""},
+		{"invalid-token-cookie", cookie, "Bearer invalid", http.StatusUnauthorized, ""},
+		{"basic-scheme", cookie, "Basic " + token, http.StatusOK, "human false"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.cookie != nil {
+				r.AddCookie(tc.cookie)
+			}
+			if tc.auth != "" {
+				r.Header.Set("Authorization", tc.auth)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != tc.expCode {
+				t.Errorf("expected status %d, but got %d", tc.expCode, w.Code)
+			}
+			if tc.expBody != "" && w.Body.String() != tc.expBody {
+				t.Errorf("expected body %q, but got %q", tc.expBody, w.Body.String())
+			}
+			if tc.auth != "" && len(w.Result().Cookies()) > 0 {
+				t.Errorf("token request must not set cookies, got %v", w.Result().Cookies())
+			}
+		})
+	}
+}
+
+func TestTokenHeader(t *testing.T) {
+	lp, tokens, h := newTestProvider(t)
+	lp.TokenHeader = "X-Api-Token"
+	tokens.Add("secret", testUser("robot"))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-Api-Token", "secret")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if got, exp := w.Body.String(), "robot true"; got != exp {
+		t.Errorf("expected %q, but got %q", exp, got)
+	}
+
+	tokens.Remove("secret")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("removed token must not authenticate, got status %d", w.Code)
+	}
+}
+
+func TestTokenRatedPerClient(t *testing.T) {
+	_, tokens, h := newTestProvider(t)
+	token, err := tokens.Create(testUser("robot"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := func(remote, token string) int {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = remote
+		r.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	if code := request("192.0.2.1:1000", "guess-1"); code != http.StatusUnauthorized {
+		t.Errorf("status 401 expected, got %d", code)
+	}
+	// Another guess, even the right token, is rated from the same host.
+	if code := request("192.0.2.1:2000", token); code != http.StatusUnauthorized {
+		t.Errorf("guess from the same host must be rated, got %d", code)
+	}
+	// A changed X-Forwarded-For header does not matter.
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "192.0.2.1:3000"
+	r.Header.Set("X-Forwarded-For", "203.0.113.9")
+	r.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("forwarded guess must be rated, got %d", w.Code)
+	}
+	if code := request("198.51.100.1:1000", token); code != http.StatusOK {
+		t.Errorf("other host must not be rated, got %d", code)
+	}
+}
+
+func TestTokenEvents(t *testing.T) {
+	lp, _, h := newTestProvider(t)
+	lp.SetRateLimits(login.RateLimits{Window: time.Minute, MaxFailsIP: 2, Lockout: time.Minute, Busy: time.Minute})
+	var events []login.Event
+	lp.SetEventSink(login.EventSinkFunc(func(_ context.Context, ev login.Event) { events = append(events, ev) }))
+	request := func(remote, token string) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = remote
+		r.Header.Set("Authorization", "Bearer "+token)
+		h.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	request("192.0.2.1:1000", "guess-1")
+	request("192.0.2.1:1001", "guess-2")
+	request("192.0.2.2:1000", "guess-3")
+	request("192.0.2.2:1001", "guess-4") // rated, not counted
+	lp.SetRateLimits(login.RateLimits{Window: time.Minute, MaxFailsIP: 1, Lockout: time.Minute})
+	request("192.0.2.3:1000", "guess-5")
+	request("192.0.2.3:1001", "guess-6")
+
+	exp := []struct {
+		kind   login.EventKind
+		remote string
+	}{
+		{login.EventTokenFailed, "192.0.2.1:1000"},
+		{login.EventTokenRated, "192.0.2.1:1001"},
+		{login.EventTokenFailed, "192.0.2.2:1000"},
+		{login.EventTokenRated, "192.0.2.2:1001"},
+		{login.EventTokenFailed, "192.0.2.3:1000"},
+		{login.EventTokenLocked, "192.0.2.3:1001"},
+	}
+	if len(events) != len(exp) {
+		t.Fatalf("%d events expected, got %v", len(exp), events)
+	}
+	for i, ev := range events {
+		if ev.Kind != exp[i].kind || ev.RemoteAddr != exp[i].remote || ev.Username != "" {
+			t.Errorf("event %d: %v from %q expected, got %+v", i, exp[i].kind, exp[i].remote, ev)
+		}
+	}
+	if events[0].Err == nil {
+		t.Error("failed token event must have an error")
+	}
+	if n, _ := lp.LockoutCount(context.Background()); n != 1 {
+		t.Errorf("one lockout expected, got %d", n)
+	}
+}
@@ -0,0 +1,510 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+// Package cache provides a middleware functor that caches responses of GET
+// requests in memory. Stale responses are served while they are refreshed
+// in the background (stale-while-revalidate), and when the handler fails
+// (stale-if-error).
+package cache
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"t73f.de/r/webs/middleware"
+)
+
+// Default values of a [Config].
+const (
+	DefaultRefreshTimeout = 10 * time.Second
+	DefaultMaxRefreshes   = 4
+	DefaultMaxBody        = 1 << 20
+	DefaultMaxEntries     = 1000
+)
+
+// Values of the [StatusHeader] of a response.
+const (
+	StatusMiss         = "miss"           // Served by the handler, then stored.
+	StatusHit          = "hit"            // Served fresh from the cache.
+	StatusStale        = "stale"          // Served stale from the cache, refresh in background.
+	StatusStaleIfError = "stale-if-error" // Served stale from the cache, because the handler failed.
+)
+
+// StatusHeader is the header key that tells how the response was served.
+const StatusHeader = "X-Cache"
+
+// Config stores all configuration data to build a caching functor.
+//
+// The age of a cached response determines how a request is served:
+//
+//   - Below SoftTTL, the response is fresh and is served from the cache.
+//   - From SoftTTL to HardTTL, the response is stale. It is served from the
+//     cache immediately, with a "Warning" header, and a refresh is started
+//     in the background.
+//   - From HardTTL on, the request waits for the handler. If the handler
+//     fails with a server error, and the age is below HardTTL plus
+//     StaleIfError, the stale response is served instead, with the status
+//     "200 OK".
+//
+// All responses served from the cache have an "Age" header and the
+// [StatusHeader].
+type Config struct {
+	// SoftTTL is the duration, a response is fresh. If not positive, no
+	// response is cached.
+	SoftTTL time.Duration
+
+	// HardTTL is the maximum age of a response that is served without
+	// waiting for the handler. If it is less than SoftTTL, SoftTTL is used,
+	// i.e. stale responses are never served while being refreshed.
+	HardTTL time.Duration
+
+	// StaleIfError is the duration after HardTTL, where a response is
+	// served, if the handler fails.
+	StaleIfError time.Duration
+
+	// Key returns the cache key of a request. An empty key disables caching
+	// for the request. If nil, the host and the request URI are used, but
+	// requests with a "Cookie" or an "Authorization" header are not cached,
+	// because their responses may depend on the user. A custom key must
+	// contain everything that distinguishes the responses, e.g. the user.
+	Key func(*http.Request) string
+
+	// RefreshTimeout is the maximum duration of a background refresh. If
+	// not positive, DefaultRefreshTimeout is used.
+	RefreshTimeout time.Duration
+
+	// MaxRefreshes is the maximum number of concurrently running
+	// background refreshes. A refresh that timed out runs until its handler
+	// returns. If the maximum is reached, no further refresh is started, and
+	// stale responses are still served. If not positive,
+	// DefaultMaxRefreshes is used.
+	MaxRefreshes int
+
+	// MaxBody is the maximum size of a response body to be stored. A larger
+	// body is not buffered further: it is passed through to the client, or
+	// discarded by a background refresh. If not positive, DefaultMaxBody is
+	// used.
+	MaxBody int
+
+	// MaxEntries is the maximum number of stored responses. If not
+	// positive, DefaultMaxEntries is used.
+	MaxEntries int
+
+	// Draining returns true, if the server is shutting down, e.g.
+	// [t73f.de/r/webs/middleware/health.Registry.Draining]. Then no
+	// background refresh is started. If nil, the server is never draining.
+	Draining func() bool
+
+	// OnRefresh is called after a background refresh finished, with the
+	// cache key and the status code of the handler. A refresh that did not
+	// finish in time has status 0.
+	OnRefresh func(key string, status int)
+
+	// Now returns the current time. If nil, time.Now is used.
+	Now func() time.Time
+}
+
+// Build the Functor from the configuration.
+//
+// Only GET requests are cached, and only responses with status "200 OK",
+// without a "Set-Cookie" or a "Vary" header, and without a "Cache-Control"
+// header that contains "no-store" or "private". Responses are buffered,
+// before they are sent to the client. If the body exceeds MaxBody, or if the
+// handler flushes the response, e.g. to stream events, the response is
+// passed through to the client and is not stored.
+//
+// A background refresh uses a copy of the request with its own context,
+// which is not canceled with the request, but after RefreshTimeout. At
+// most one refresh per key is running at any time. Requests that wait for
+// an expired response share a running refresh.
+func (c *Config) Build() middleware.Functor {
+	if c.SoftTTL <= 0 {
+		return middleware.NilFunctor
+	}
+	st := &store{
+		softTTL:      c.SoftTTL,
+		hardTTL:      max(c.HardTTL, c.SoftTTL),
+		staleIfError: max(c.StaleIfError, 0),
+		key:          c.Key,
+		timeout:      c.RefreshTimeout,
+		refreshes:    make(chan struct{}, cmpOr(c.MaxRefreshes, DefaultMaxRefreshes)),
+		maxBody:      cmpOr(c.MaxBody, DefaultMaxBody),
+		maxEntries:   cmpOr(c.MaxEntries, DefaultMaxEntries),
+		draining:     c.Draining,
+		onRefresh:    c.OnRefresh,
+		now:          c.Now,
+		entries:      map[string]*entry{},
+		flights:      map[string]*flight{},
+	}
+	if st.key == nil {
+		st.key = defaultKey
+	}
+	if st.timeout <= 0 {
+		st.timeout = DefaultRefreshTimeout
+	}
+	if st.now == nil {
+		st.now = time.Now
+	}
+	return middleware.Declare(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			st.serve(next, w, r)
+		})
+	}, "cache")
+}
+
+// defaultKey returns the host and the request URI, if the request does not
+// identify a user.
+func defaultKey(r *http.Request) string {
+	if r.Header.Get("Cookie") != "" || r.Header.Get("Authorization") != "" {
+		return ""
+	}
+	return r.Host + r.URL.RequestURI()
+}
+
+func cmpOr(val, def int) int {
+	if val <= 0 {
+		return def
+	}
+	return val
+}
+
+// entry is a stored response.
+type entry struct {
+	status int
+	header http.Header
+	body   []byte
+	stored time.Time
+
+	// incomplete is true, if the body was not buffered: it was passed
+	// through to the client, or it was discarded.
+	incomplete bool
+}
+
+// flight is a running call of the handler for a key.
+type flight struct {
+	done chan struct{}
+	resp *entry // response of the handler, nil if it did not finish
+}
+
+type store struct {
+	softTTL, hardTTL, staleIfError time.Duration
+
+	key        func(*http.Request) string
+	timeout    time.Duration
+	refreshes  chan struct{} // semaphore of background refreshes
+	maxBody    int
+	maxEntries int
+	draining   func() bool
+	onRefresh  func(string, int)
+	now        func() time.Time
+
+	mx      sync.Mutex
+	entries map[string]*entry
+	flights map[string]*flight
+}
+
+func (st *store) serve(next http.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		next.ServeHTTP(w, r)
+		return
+	}
+	key := st.key(r)
+	if key == "" {
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	now := st.now()
+	st.mx.Lock()
+	e := st.entries[key]
+	st.mx.Unlock()
+
+	if e != nil {
+		age := now.Sub(e.stored)
+		if age < st.softTTL {
+			st.write(w, e, now, StatusHit)
+			return
+		}
+		if age < st.hardTTL {
+			st.refreshBackground(next, r, key)
+			st.write(w, e, now, StatusStale)
+			return
+		}
+	}
+
+	resp := st.fetch(next, w, r, key)
+	if resp.incomplete {
+		return // already sent to the client
+	}
+	if resp.status >= 500 && e != nil {
+		if now := st.now(); now.Sub(e.stored) < st.hardTTL+st.staleIfError {
+			st.write(w, e, now, StatusStaleIfError)
+			return
+		}
+	}
+	writeResponse(w, resp, StatusMiss)
+}
+
+// fetch calls the handler for the request, and stores its response. If the
+// handler is already running for the key, e.g. in a background refresh,
+// its response is used, if it is successful.
+func (st *store) fetch(next http.Handler, w http.ResponseWriter, r *http.Request, key string) *entry {
+	st.mx.Lock()
+	fl, running := st.flights[key]
+	if !running {
+		fl = &flight{done: make(chan struct{})}
+		st.flights[key] = fl
+	}
+	st.mx.Unlock()
+
+	if running {
+		select {
+		case <-fl.done:
+			if resp := fl.resp; resp != nil && !resp.incomplete && resp.status < 500 {
+				return resp
+			}
+		case <-r.Context().Done():
+		}
+		return st.call(next, w, r)
+	}
+	st.land(key, fl, st.call(next, w, r))
+	return fl.resp
+}
+
+// refreshBackground starts a refresh of the key in the background, if none
+// is running, if the maximum number of refreshes is not reached, and if the
+// server is not draining.
+func (st *store) refreshBackground(next http.Handler, r *http.Request, key string) {
+	if st.draining != nil && st.draining() {
+		return
+	}
+	select {
+	case st.refreshes <- struct{}{}:
+	default:
+		return
+	}
+	st.mx.Lock()
+	if _, running := st.flights[key]; running {
+		st.mx.Unlock()
+		<-st.refreshes
+		return
+	}
+	fl := &flight{done: make(chan struct{})}
+	st.flights[key] = fl
+	st.mx.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), st.timeout)
+	req := r.Clone(ctx)
+	req.Body = http.NoBody
+	go func() {
+		defer cancel()
+
+		// The slot is released only when the handler returns, even after a
+		// timeout, because a handler may ignore the canceled context.
+		done := make(chan *entry, 1)
+		go func() {
+			defer func() { <-st.refreshes }()
+			done <- st.call(next, nil, req)
+		}()
+		var resp *entry
+		select {
+		case resp = <-done:
+		case <-ctx.Done():
+		}
+		st.land(key, fl, resp)
+		if onRefresh := st.onRefresh; onRefresh != nil {
+			status := 0
+			if resp != nil {
+				status = resp.status
+			}
+			onRefresh(key, status)
+		}
+	}()
+}
+
+// land finishes a flight: the response is stored, if it is cacheable, and
+// all waiting requests are notified.
+func (st *store) land(key string, fl *flight, resp *entry) {
+	st.mx.Lock()
+	if resp != nil && st.cacheable(resp) {
+		st.storeEntry(key, resp)
+	}
+	delete(st.flights, key)
+	fl.resp = resp
+	st.mx.Unlock()
+	close(fl.done)
+}
+
+// call lets the handler serve the request into a buffer. If w is not nil,
+// the response is passed through to it, when the body exceeds the maximum
+// size, or when the handler flushes. Without w, a larger body is discarded.
+func (st *store) call(next http.Handler, w http.ResponseWriter, r *http.Request) *entry {
+	bw := bufferWriter{header: http.Header{}, maxBody: st.maxBody, w: w}
+	next.ServeHTTP(&bw, r)
+	if bw.status == 0 {
+		bw.status = http.StatusOK
+	}
+	if bw.passed || bw.discarded {
+		return &entry{status: bw.status, header: bw.header, stored: st.now(), incomplete: true}
+	}
+	return &entry{status: bw.status, header: bw.header, body: bw.body.Bytes(), stored: st.now()}
+}
+
+func (st *store) cacheable(e *entry) bool {
+	if e.incomplete || e.status != http.StatusOK || len(e.body) > st.maxBody {
+		return false
+	}
+	if len(e.header.Values("Set-Cookie")) > 0 || len(e.header.Values("Vary")) > 0 {
+		return false
+	}
+	for _, cc := range e.header.Values("Cache-Control") {
+		for directive := range strings.SplitSeq(cc, ",") {
+			if d := strings.ToLower(strings.TrimSpace(directive)); d == "no-store" || d == "private" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// storeEntry stores the response. The store must be locked. If the store is
+// full, expired entries are removed, and then the oldest one.
+func (st *store) storeEntry(key string, e *entry) {
+	if _, found := st.entries[key]; !found && len(st.entries) >= st.maxEntries {
+		maxAge := st.hardTTL + st.staleIfError
+		var oldestKey string
+		var oldest time.Time
+		for k, other := range st.entries {
+			if e.stored.Sub(other.stored) >= maxAge {
+				delete(st.entries, k)
+			} else if oldestKey == "" || other.stored.Before(oldest) {
+				oldestKey, oldest = k, other.stored
+			}
+		}
+		if len(st.entries) >= st.maxEntries {
+			delete(st.entries, oldestKey)
+		}
+	}
+	st.entries[key] = e
+}
+
+// write a stored response.
+func (st *store) write(w http.ResponseWriter, e *entry, now time.Time, status string) {
+	header := w.Header()
+	header.Set("Age", strconv.FormatInt(int64(max(now.Sub(e.stored), 0)/time.Second), 10))
+	switch status {
+	case StatusStale:
+		header.Set("Warning", `110 - "Response is Stale"`)
+	case StatusStaleIfError:
+		header.Set("Warning", `111 - "Revalidation Failed"`)
+	}
+	writeResponse(w, e, status)
+}
+
+func writeResponse(w http.ResponseWriter, e *entry, status string) {
+	header := w.Header()
+	for k, v := range e.header {
+		header[k] = slices.Clone(v)
+	}
+	header.Set(StatusHeader, status)
+	header.Set("Content-Length", strconv.Itoa(len(e.body)))
+	if status == StatusStaleIfError {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(e.status)
+	}
+	_, _ = w.Write(e.body)
+}
+
+// bufferWriter buffers a response, up to a maximum size of the body.
+type bufferWriter struct {
+	header  http.Header
+	status  int
+	body    bytes.Buffer
+	maxBody int
+
+	w         http.ResponseWriter // client, nil for a background refresh
+	passed    bool                // response is passed through to w
+	discarded bool                // body exceeded maxBody without w
+}
+
+func (bw *bufferWriter) Header() http.Header {
+	if bw.passed {
+		return bw.w.Header()
+	}
+	return bw.header
+}
+
+func (bw *bufferWriter) WriteHeader(code int) {
+	if bw.status == 0 && (code < 100 || code > 199) {
+		bw.status = code
+	}
+}
+
+func (bw *bufferWriter) Write(data []byte) (int, error) {
+	if bw.status == 0 {
+		bw.status = http.StatusOK
+	}
+	if bw.passed {
+		return bw.w.Write(data)
+	}
+	if bw.discarded {
+		return len(data), nil
+	}
+	if bw.body.Len()+len(data) > bw.maxBody {
+		if bw.w == nil {
+			bw.discarded = true
+			bw.body = bytes.Buffer{}
+			return len(data), nil
+		}
+		bw.passThrough()
+		return bw.w.Write(data)
+	}
+	return bw.body.Write(data)
+}
+
+// Flush passes the response through to the client, because the handler
+// streams it. A background refresh ignores it.
+func (bw *bufferWriter) Flush() {
+	if bw.w == nil {
+		return
+	}
+	bw.passThrough()
+	_ = http.NewResponseController(bw.w).Flush()
+}
+
+// passThrough sends the header and the buffered body to the client. All
+// further writes go directly to the client.
+func (bw *bufferWriter) passThrough() {
+	if bw.passed {
+		return
+	}
+	bw.passed = true
+	header := bw.w.Header()
+	for k, v := range bw.header {
+		header[k] = v
+	}
+	header.Set(StatusHeader, StatusMiss)
+	if bw.status == 0 {
+		bw.status = http.StatusOK
+	}
+	bw.w.WriteHeader(bw.status)
+	_, _ = bw.w.Write(bw.body.Bytes())
+	bw.body = bytes.Buffer{}
+}
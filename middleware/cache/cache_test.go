@@ -0,0 +1,458 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package cache_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"t73f.de/r/webs/middleware/cache"
+)
+
+// clock is a manual clock for tests.
+type clock struct {
+	mx  sync.Mutex
+	now time.Time
+}
+
+func newClock() *clock { return &clock{now: time.Date(2025, time.May, 1, 12, 0, 0, 0, time.UTC)} }
+
+func (c *clock) Now() time.Time {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.mx.Lock()
+	c.now = c.now.Add(d)
+	c.mx.Unlock()
+}
+
+// origin is a handler that returns a numbered version of its content.
+type origin struct {
+	calls   atomic.Int32
+	status  atomic.Int32 // status code; 0 means 200
+	block   chan struct{}
+	started chan struct{}
+}
+
+func (o *origin) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := o.calls.Add(1)
+	if o.started != nil {
+		o.started <- struct{}{}
+	}
+	if o.block != nil {
+		select {
+		case <-o.block:
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if status := o.status.Load(); status != 0 {
+		http.Error(w, "failure", int(status))
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = w.Write([]byte("v" + string(rune('0'+n))))
+}
+
+type response struct {
+	status int
+	body   string
+	cache  string
+	age    string
+	warn   string
+}
+
+func get(h http.Handler, path string) response {
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+	return response{
+		status: rr.Code,
+		body:   rr.Body.String(),
+		cache:  rr.Header().Get(cache.StatusHeader),
+		age:    rr.Header().Get("Age"),
+		warn:   rr.Header().Get("Warning"),
+	}
+}
+
+func check(t *testing.T, got, exp response) {
+	t.Helper()
+	if got != exp {
+		t.Errorf("expected %+v, but got %+v", exp, got)
+	}
+}
+
+func refreshes(cfg *cache.Config) <-chan int {
+	ch := make(chan int, 8)
+	cfg.OnRefresh = func(_ string, status int) { ch <- status }
+	return ch
+}
+
+func waitRefresh(t *testing.T, ch <-chan int) int {
+	t.Helper()
+	select {
+	case status := <-ch:
+		return status
+	case <-time.After(5 * time.Second):
+		t.Fatal("no refresh")
+		return 0
+	}
+}
+
+func TestCacheWindows(t *testing.T) {
+	clk := newClock()
+	cfg := cache.Config{SoftTTL: 10 * time.Second, HardTTL: time.Minute, StaleIfError: time.Hour, Now: clk.Now}
+	refreshed := refreshes(&cfg)
+	var o origin
+	h := cfg.Build()(&o)
+
+	check(t, get(h, "/a"), response{200, "v1", cache.StatusMiss, "", ""})
+	clk.Advance(5 * time.Second)
+	check(t, get(h, "/a"), response{200, "v1", cache.StatusHit, "5", ""})
+
+	// Soft TTL passed: stale response, refresh in background.
+	clk.Advance(10 * time.Second)
+	check(t, get(h, "/a"), response{200, "v1", cache.StatusStale, "15", `110 - "Response is Stale"`})
+	if status := waitRefresh(t, refreshed); status != http.StatusOK {
+		t.Errorf("refresh status 200 expected, got %d", status)
+	}
+	check(t, get(h, "/a"), response{200, "v2", cache.StatusHit, "0", ""})
+
+	// Hard TTL passed: the request waits for the handler.
+	clk.Advance(2 * time.Minute)
+	check(t, get(h, "/a"), response{200, "v3", cache.StatusMiss, "", ""})
+	if got := o.calls.Load(); got != 3 {
+		t.Errorf("three calls expected, got %d", got)
+	}
+
+	// Other keys are cached separately.
+	check(t, get(h, "/b"), response{200, "v4", cache.StatusMiss, "", ""})
+	check(t, get(h, "/b"), response{200, "v4", cache.StatusHit, "0", ""})
+}
+
+func TestCacheStaleIfError(t *testing.T) {
+	clk := newClock()
+	cfg := cache.Config{SoftTTL: 10 * time.Second, HardTTL: time.Minute, StaleIfError: 5 * time.Minute, Now: clk.Now}
+	refreshed := refreshes(&cfg)
+	var o origin
+	h := cfg.Build()(&o)
+
+	check(t, get(h, "/"), response{200, "v1", cache.StatusMiss, "", ""})
+	o.status.Store(http.StatusServiceUnavailable)
+
+	// A failed background refresh keeps the stale response.
+	clk.Advance(30 * time.Second)
+	check(t, get(h, "/"), response{200, "v1", cache.StatusStale, "30", `110 - "Response is Stale"`})
+	if status := waitRefresh(t, refreshed); status != http.StatusServiceUnavailable {
+		t.Errorf("refresh status 503 expected, got %d", status)
+	}
+
+	// Within the stale-if-error window.
+	clk.Advance(time.Minute)
+	check(t, get(h, "/"), response{200, "v1", cache.StatusStaleIfError, "90", `111 - "Revalidation Failed"`})
+
+	// After the window, the error is sent.
+	clk.Advance(5 * time.Minute)
+	check(t, get(h, "/"), response{503, "failure\n", cache.StatusMiss, "", ""})
+
+	// Errors are not stored.
+	o.status.Store(0)
+	check(t, get(h, "/"), response{200, "v5", cache.StatusMiss, "", ""})
+}
+
+func TestCacheDedupRefresh(t *testing.T) {
+	clk := newClock()
+	cfg := cache.Config{SoftTTL: 10 * time.Second, HardTTL: time.Minute, Now: clk.Now}
+	refreshed := refreshes(&cfg)
+	o := origin{}
+	h := cfg.Build()(&o)
+	check(t, get(h, "/"), response{200, "v1", cache.StatusMiss, "", ""})
+
+	o.block = make(chan struct{})
+	o.started = make(chan struct{}, 16)
+	clk.Advance(20 * time.Second)
+	for range 10 {
+		check(t, get(h, "/"), response{200, "v1", cache.StatusStale, "20", `110 - "Response is Stale"`})
+	}
+	<-o.started
+
+	// After the hard TTL, requests wait for the running refresh.
+	clk.Advance(time.Minute)
+	var wg sync.WaitGroup
+	results := make([]response, 5)
+	for i := range results {
+		wg.Go(func() { results[i] = get(h, "/") })
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(o.block)
+	wg.Wait()
+	waitRefresh(t, refreshed)
+	for _, res := range results {
+		check(t, res, response{200, "v2", cache.StatusMiss, "", ""})
+	}
+	if got := o.calls.Load(); got != 2 {
+		t.Errorf("two calls expected, got %d", got)
+	}
+}
+
+func TestCacheRefreshTimeoutAndDrain(t *testing.T) {
+	clk := newClock()
+	draining := atomic.Bool{}
+	cfg := cache.Config{
+		SoftTTL: time.Second, HardTTL: time.Minute, RefreshTimeout: 10 * time.Millisecond,
+		Draining: draining.Load, Now: clk.Now,
+	}
+	refreshed := refreshes(&cfg)
+	o := origin{}
+	h := cfg.Build()(&o)
+	check(t, get(h, "/"), response{200, "v1", cache.StatusMiss, "", ""})
+
+	// The background refresh has its own context.
+	o.block = make(chan struct{})
+	clk.Advance(2 * time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequestWithContext(ctx, http.MethodGet, "/", nil))
+	cancel()
+	if status := waitRefresh(t, refreshed); status != 0 {
+		t.Errorf("timed out refresh expected, got %d", status)
+	}
+	close(o.block)
+
+	draining.Store(true)
+	check(t, get(h, "/"), response{200, "v1", cache.StatusStale, "2", `110 - "Response is Stale"`})
+	select {
+	case status := <-refreshed:
+		t.Errorf("no refresh expected while draining, got %d", status)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+// stubborn is a handler that ignores the canceled context of its request.
+type stubborn struct {
+	mx              sync.Mutex
+	block           chan struct{}
+	running, maxRun int
+}
+
+func (s *stubborn) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	s.mx.Lock()
+	s.running++
+	s.maxRun = max(s.maxRun, s.running)
+	block := s.block
+	s.mx.Unlock()
+	if block != nil {
+		<-block
+	}
+	s.mx.Lock()
+	s.running--
+	s.mx.Unlock()
+	_, _ = w.Write([]byte("ok"))
+}
+
+func TestCacheRefreshLimit(t *testing.T) {
+	clk := newClock()
+	cfg := cache.Config{
+		SoftTTL: time.Second, HardTTL: time.Minute, RefreshTimeout: 10 * time.Millisecond,
+		MaxRefreshes: 1, Now: clk.Now,
+	}
+	refreshed := refreshes(&cfg)
+	s := &stubborn{}
+	h := cfg.Build()(s)
+	for _, path := range []string{"/a", "/b", "/c"} {
+		check(t, get(h, path), response{200, "ok", cache.StatusMiss, "", ""})
+	}
+
+	block := make(chan struct{})
+	s.mx.Lock()
+	s.block = block
+	s.mx.Unlock()
+	clk.Advance(2 * time.Second)
+	get(h, "/a")
+	if status := waitRefresh(t, refreshed); status != 0 {
+		t.Errorf("timed out refresh expected, got %d", status)
+	}
+
+	// The handler of the timed out refresh is still running.
+	get(h, "/b")
+	get(h, "/c")
+	select {
+	case status := <-refreshed:
+		t.Errorf("no refresh expected, got %d", status)
+	case <-time.After(50 * time.Millisecond):
+	}
+	s.mx.Lock()
+	maxRun := s.maxRun
+	s.mx.Unlock()
+	if maxRun != 1 {
+		t.Errorf("at most one running handler expected, got %d", maxRun)
+	}
+
+	// After the handler returned, a refresh is started again.
+	close(block)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		get(h, "/b")
+		select {
+		case status := <-refreshed:
+			if status != http.StatusOK {
+				t.Errorf("status 200 expected, got %d", status)
+			}
+			return
+		case <-time.After(5 * time.Millisecond):
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("no refresh after the handler returned")
+		}
+	}
+}
+
+func TestCacheNotCacheable(t *testing.T) {
+	cfg := cache.Config{SoftTTL: time.Minute}
+	var calls atomic.Int32
+	h := cfg.Build()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		switch r.URL.Path {
+		case "/cookie":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "x"})
+		case "/private":
+			w.Header().Set("Cache-Control", "max-age=60, Private")
+		case "/created":
+			w.WriteHeader(http.StatusCreated)
+		}
+		_, _ = w.Write([]byte("body"))
+	}))
+	for _, path := range []string{"/cookie", "/private", "/created"} {
+		get(h, path)
+		if res := get(h, path); res.cache != cache.StatusMiss {
+			t.Errorf("%s: must not be cached, got %+v", path, res)
+		}
+	}
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/", nil))
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/", nil))
+	if got := calls.Load(); got != 8 {
+		t.Errorf("8 calls expected, got %d", got)
+	}
+
+	var o origin
+	if fn := (&cache.Config{}).Build(); fn(&o) != http.Handler(&o) {
+		t.Error("functor without TTL must do nothing")
+	}
+}
+
+func TestCachePerUser(t *testing.T) {
+	cfg := cache.Config{SoftTTL: time.Minute}
+	h := cfg.Build()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/vary" {
+			w.Header().Set("Vary", "Accept-Language")
+		}
+		user := "anonymous"
+		if cookie, err := r.Cookie("user"); err == nil {
+			user = cookie.Value
+		} else if auth := r.Header.Get("Authorization"); auth != "" {
+			user = auth
+		}
+		_, _ = w.Write([]byte(user + " " + r.Header.Get("Accept-Language")))
+	}))
+	getAs := func(path, header, value string) response {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		if header != "" {
+			r.Header.Set(header, value)
+		}
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, r)
+		return response{status: rr.Code, body: rr.Body.String(), cache: rr.Header().Get(cache.StatusHeader)}
+	}
+
+	check(t, getAs("/dash", "Cookie", "user=alice"), response{status: 200, body: "alice "})
+	check(t, getAs("/dash", "Cookie", "user=bob"), response{status: 200, body: "bob "})
+	check(t, getAs("/dash", "Authorization", "Bearer carol"), response{status: 200, body: "Bearer carol "})
+	check(t, getAs("/dash", "", ""), response{status: 200, body: "anonymous ", cache: cache.StatusMiss})
+	check(t, getAs("/dash", "", ""), response{status: 200, body: "anonymous ", cache: cache.StatusHit})
+	check(t, getAs("/dash", "Cookie", "user=bob"), response{status: 200, body: "bob "})
+
+	check(t, getAs("/vary", "Accept-Language", "de"), response{status: 200, body: "anonymous de", cache: cache.StatusMiss})
+	check(t, getAs("/vary", "Accept-Language", "en"), response{status: 200, body: "anonymous en", cache: cache.StatusMiss})
+}
+
+// flushRecorder records, whether the response was flushed.
+type flushRecorder struct {
+	*httptest.ResponseRecorder
+	flushed chan struct{}
+}
+
+func (fr *flushRecorder) Flush() {
+	fr.ResponseRecorder.Flush()
+	select {
+	case fr.flushed <- struct{}{}:
+	default:
+	}
+}
+
+func TestCachePassThrough(t *testing.T) {
+	cfg := cache.Config{SoftTTL: time.Minute, MaxBody: 8}
+	release := make(chan struct{})
+	var calls atomic.Int32
+	h := cfg.Build()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		switch r.URL.Path {
+		case "/large":
+			_, _ = w.Write([]byte("12345"))
+			_, _ = w.Write([]byte("67890"))
+		case "/stream":
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = w.Write([]byte("data"))
+			http.NewResponseController(w).Flush()
+			<-release
+			_, _ = w.Write([]byte("done"))
+		}
+	}))
+
+	for range 2 {
+		if res := get(h, "/large"); res.body != "1234567890" || res.cache != cache.StatusMiss {
+			t.Errorf("large body must be passed through, got %+v", res)
+		}
+	}
+
+	fr := &flushRecorder{ResponseRecorder: httptest.NewRecorder(), flushed: make(chan struct{}, 1)}
+	done := make(chan struct{})
+	go func() {
+		h.ServeHTTP(fr, httptest.NewRequest(http.MethodGet, "/stream", nil))
+		close(done)
+	}()
+	select {
+	case <-fr.flushed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream was not flushed")
+	}
+	close(release)
+	<-done
+	if got := fr.Body.String(); got != "datadone" {
+		t.Errorf("streamed body expected, got %q", got)
+	}
+	if res := get(h, "/stream"); res.cache != cache.StatusMiss {
+		t.Errorf("streamed response must not be cached, got %+v", res)
+	}
+	if got := calls.Load(); got != 4 {
+		t.Errorf("4 calls expected, got %d", got)
+	}
+}
@@ -41,7 +41,7 @@ func (cf Func) Check(w http.ResponseWriter, r *http.Request) (context.Context, b
 
 // Build a Checker middleware.
 func Build(c Checker) middleware.Functor {
-	return func(next http.Handler) http.Handler {
+	return middleware.Declare(func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			if ctx, ok := c.Check(w, r); ok {
 				if ctx != nil && ctx != r.Context() {
@@ -50,5 +50,5 @@ func Build(c Checker) middleware.Functor {
 				next.ServeHTTP(w, r)
 			}
 		})
-	}
+	}, "check")
 }
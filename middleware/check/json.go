@@ -0,0 +1,184 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package check
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// DefaultJSONMaxBytes is the maximum body size of [JSONBody] and [JSONInto],
+// if no positive maximum is given.
+const DefaultJSONMaxBytes = 1 << 20
+
+// JSONBody returns a Checker that reads the body of a request as a JSON
+// object, and validates it with the given function. The validation function
+// may be nil.
+//
+// The request must have a content type of "application/json". A charset
+// parameter is tolerated, if it specifies UTF-8. A body larger than maxBytes
+// is rejected with status 413, a wrong content type with status 415, invalid
+// JSON and a failed validation with status 400. All error responses are
+// problem details according to RFC 9457.
+//
+// If the check succeeds, the body of the request can be read again by the
+// next handler. Alternatively, the decoded value is available via
+// [JSONValue] with type map[string]any.
+func JSONBody(maxBytes int64, validate func(decoded map[string]any) error) Checker {
+	return Func(func(w http.ResponseWriter, r *http.Request) (context.Context, bool) {
+		var decoded map[string]any
+		if !decodeJSON(w, r, maxBytes, false, &decoded) {
+			return nil, false
+		}
+		if decoded == nil {
+			writeProblem(w, http.StatusBadRequest, "JSON object expected")
+			return nil, false
+		}
+		if validate != nil {
+			if err := validate(decoded); err != nil {
+				writeProblem(w, http.StatusBadRequest, err.Error())
+				return nil, false
+			}
+		}
+		return withJSONValue(r.Context(), decoded), true
+	})
+}
+
+// JSONInto returns a Checker that decodes the body of a request into a value
+// of type T, and validates it with the given function. The validation
+// function may be nil. Fields of the JSON object that are not present in T
+// are an error.
+//
+// Apart from that, it behaves like [JSONBody]. The decoded value is available
+// via [JSONValue] with type *T.
+func JSONInto[T any](maxBytes int64, validate func(*T) error) Checker {
+	return Func(func(w http.ResponseWriter, r *http.Request) (context.Context, bool) {
+		decoded := new(T)
+		if !decodeJSON(w, r, maxBytes, true, decoded) {
+			return nil, false
+		}
+		if validate != nil {
+			if err := validate(decoded); err != nil {
+				writeProblem(w, http.StatusBadRequest, err.Error())
+				return nil, false
+			}
+		}
+		return withJSONValue(r.Context(), decoded), true
+	})
+}
+
+// JSONValue returns the value decoded by [JSONBody] or [JSONInto]. The type
+// parameter must be map[string]any for JSONBody, and *T for JSONInto[T].
+func JSONValue[T any](ctx context.Context) (T, bool) {
+	val, ok := ctx.Value(jsonKeyType[T]{}).(T)
+	return val, ok
+}
+
+type jsonKeyType[T any] struct{}
+
+func withJSONValue[T any](ctx context.Context, val T) context.Context {
+	return context.WithValue(ctx, jsonKeyType[T]{}, val)
+}
+
+// decodeJSON reads the body of the request, decodes it into the value, and
+// re-installs the body. On failure, an error response is written.
+func decodeJSON(w http.ResponseWriter, r *http.Request, maxBytes int64, strict bool, val any) bool {
+	if detail, ok := checkJSONContentType(r.Header.Get("Content-Type")); !ok {
+		writeProblem(w, http.StatusUnsupportedMediaType, detail)
+		return false
+	}
+	if maxBytes <= 0 {
+		maxBytes = DefaultJSONMaxBytes
+	}
+	if r.ContentLength > maxBytes {
+		writeProblem(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("body larger than %d bytes", maxBytes))
+		return false
+	}
+
+	var buf []byte
+	if r.Body != nil {
+		var err error
+		buf, err = io.ReadAll(io.LimitReader(r.Body, maxBytes+1))
+		_ = r.Body.Close()
+		if err != nil {
+			writeProblem(w, http.StatusBadRequest, "unable to read body")
+			return false
+		}
+	}
+	if int64(len(buf)) > maxBytes {
+		writeProblem(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("body larger than %d bytes", maxBytes))
+		return false
+	}
+	r.Body = io.NopCloser(bytes.NewReader(buf))
+
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	if strict {
+		dec.DisallowUnknownFields()
+	}
+	if err := dec.Decode(val); err != nil {
+		if errors.Is(err, io.EOF) {
+			writeProblem(w, http.StatusBadRequest, "empty body")
+		} else {
+			writeProblem(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		}
+		return false
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		writeProblem(w, http.StatusBadRequest, "invalid JSON: data after top-level value")
+		return false
+	}
+	return true
+}
+
+func checkJSONContentType(ct string) (string, bool) {
+	if ct == "" {
+		return "missing content type, application/json expected", false
+	}
+	mediaType, params, err := mime.ParseMediaType(ct)
+	if err != nil || mediaType != "application/json" {
+		return fmt.Sprintf("content type %q not supported, application/json expected", ct), false
+	}
+	if cs, found := params["charset"]; found && !strings.EqualFold(cs, "utf-8") {
+		return fmt.Sprintf("charset %q not supported, utf-8 expected", cs), false
+	}
+	return "", true
+}
+
+// problem is a problem detail according to RFC 9457.
+type problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+func writeProblem(w http.ResponseWriter, code int, detail string) {
+	h := w.Header()
+	h.Set("Content-Type", "application/problem+json")
+	h.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(code),
+		Status: code,
+		Detail: detail,
+	})
+}
@@ -0,0 +1,157 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package check_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"t73f.de/r/webs/middleware/check"
+)
+
+type item struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+func validateItem(it *item) error {
+	if it.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+func TestJSONInto(t *testing.T) {
+	var gotItem *item
+	var gotBody string
+	h := check.Build(check.JSONInto(32, validateItem))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotItem, _ = check.JSONValue[*item](r.Context())
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	testcases := []struct {
+		name   string
+		ct     string
+		body   string
+		status int
+		detail string
+	}{
+		{"ok", "application/json", `{"name":"a","count":3}`, http.StatusNoContent, ""},
+		{"charset", "application/json; charset=UTF-8", `{"name":"a"}`, http.StatusNoContent, ""},
+		{"no-ct", "", `{"name":"a"}`, http.StatusUnsupportedMediaType, "missing content type"},
+		{"wrong-ct", "text/plain", `{"name":"a"}`, http.StatusUnsupportedMediaType, "text/plain"},
+		{"wrong-charset", "application/json; charset=latin1", `{"name":"a"}`, http.StatusUnsupportedMediaType, "latin1"},
+		{"oversized", "application/json", `{"name":"` + strings.Repeat("x", 32) + `"}`, http.StatusRequestEntityTooLarge, "32 bytes"},
+		{"invalid", "application/json", `{"name":`, http.StatusBadRequest, "invalid JSON"},
+		{"empty", "application/json", ``, http.StatusBadRequest, "empty body"},
+		{"trailing", "application/json", `{"name":"a"} {}`, http.StatusBadRequest, "after top-level"},
+		{"unknown", "application/json", `{"name":"a","size":1}`, http.StatusBadRequest, "unknown field"},
+		{"validation", "application/json", `{"count":1}`, http.StatusBadRequest, "name is required"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotItem, gotBody = nil, ""
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body))
+			if tc.ct != "" {
+				r.Header.Set("Content-Type", tc.ct)
+			}
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, r)
+			if rr.Code != tc.status {
+				t.Fatalf("status %d expected, got %d (%s)", tc.status, rr.Code, rr.Body.String())
+			}
+			if tc.status == http.StatusNoContent {
+				if gotItem == nil || gotItem.Name != "a" {
+					t.Errorf("decoded value not in context: %v", gotItem)
+				}
+				if gotBody != tc.body {
+					t.Errorf("body not re-installed: %q", gotBody)
+				}
+				return
+			}
+			if gotItem != nil {
+				t.Error("handler was called")
+			}
+			checkProblem(t, rr, tc.status, tc.detail)
+		})
+	}
+}
+
+func TestJSONBody(t *testing.T) {
+	var got map[string]any
+	h := check.Build(check.JSONBody(0, func(m map[string]any) error {
+		if _, found := m["id"]; !found {
+			return errors.New("id missing")
+		}
+		return nil
+	}))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = check.JSONValue[map[string]any](r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"id":1,"extra":true}`))
+	r.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, r)
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("status %d expected, got %d", http.StatusNoContent, rr.Code)
+	}
+	if got["extra"] != true {
+		t.Errorf("decoded value not in context: %v", got)
+	}
+
+	for _, body := range []string{`{"extra":true}`, `[1,2]`, `null`} {
+		got = nil
+		r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		rr = httptest.NewRecorder()
+		h.ServeHTTP(rr, r)
+		if got != nil {
+			t.Errorf("handler called for %q", body)
+		}
+		checkProblem(t, rr, http.StatusBadRequest, "")
+	}
+}
+
+func checkProblem(t *testing.T, rr *httptest.ResponseRecorder, status int, detail string) {
+	t.Helper()
+	if rr.Code != status {
+		t.Errorf("status %d expected, got %d", status, rr.Code)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Errorf("problem content type expected, got %q", ct)
+	}
+	var p struct {
+		Type   string `json:"type"`
+		Title  string `json:"title"`
+		Status int    `json:"status"`
+		Detail string `json:"detail"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &p); err != nil {
+		t.Fatalf("invalid problem body %q: %v", rr.Body.String(), err)
+	}
+	if p.Status != status || p.Title != http.StatusText(status) || p.Type != "about:blank" {
+		t.Errorf("unexpected problem: %+v", p)
+	}
+	if !strings.Contains(p.Detail, detail) {
+		t.Errorf("detail %q does not contain %q", p.Detail, detail)
+	}
+}
@@ -0,0 +1,268 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package middleware
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"slices"
+	"sync"
+)
+
+// ConstraintKind specifies the type of a [Constraint].
+type ConstraintKind uint8
+
+// Constants for ConstraintKind.
+const (
+	_ ConstraintKind = iota
+
+	// KindProvides signals that a functor provides a named capability, e.g.
+	// a request identifier.
+	KindProvides
+
+	// KindAfter signals that a functor must process a request after all
+	// functors that provide the named capability.
+	KindAfter
+
+	// KindBefore signals that a functor must process a request before all
+	// functors that provide the named capability.
+	KindBefore
+
+	// KindMemberOf signals that a functor belongs to the named group.
+	KindMemberOf
+
+	// KindInnermostOf signals that a functor must be the innermost one of all
+	// members of the named group, i.e. it processes a request as the last one.
+	KindInnermostOf
+)
+
+// Constraint restricts the position of a functor within a middleware.
+//
+// Constraints are purely advisory. They do not change the behaviour of a
+// functor, but allow to detect misordered middleware with [Validate].
+type Constraint struct {
+	Kind ConstraintKind
+	Name string
+}
+
+// Provides returns a constraint that declares a provided capability.
+func Provides(capability string) Constraint { return Constraint{KindProvides, capability} }
+
+// After returns a constraint that requires to be placed after the providers of
+// the given capability.
+func After(capability string) Constraint { return Constraint{KindAfter, capability} }
+
+// Before returns a constraint that requires to be placed before the providers
+// of the given capability.
+func Before(capability string) Constraint { return Constraint{KindBefore, capability} }
+
+// MemberOf returns a constraint that declares membership of a group.
+func MemberOf(group string) Constraint { return Constraint{KindMemberOf, group} }
+
+// InnermostOf returns a constraint that requires to be the innermost functor
+// of the given group. It implies membership of the group.
+func InnermostOf(group string) Constraint { return Constraint{KindInnermostOf, group} }
+
+func (c Constraint) String() string {
+	switch c.Kind {
+	case KindProvides:
+		return "provides:" + c.Name
+	case KindAfter:
+		return "wants-after:" + c.Name
+	case KindBefore:
+		return "wants-before:" + c.Name
+	case KindMemberOf:
+		return "member-of:" + c.Name
+	case KindInnermostOf:
+		return "must-be-innermost-of:" + c.Name
+	}
+	return fmt.Sprintf("unknown-%d:%s", c.Kind, c.Name)
+}
+
+// GroupBodyTransformers is the group of all functors that change the body of
+// a response.
+const GroupBodyTransformers = "body-transformers"
+
+// declaration stores the name and the constraints of a functor.
+type declaration struct {
+	name        string
+	constraints []Constraint
+}
+
+var (
+	mxDeclarations sync.RWMutex
+	declarations   = map[uintptr]declaration{}
+)
+
+// Declare the name and the constraints of the given functor. The functor
+// itself is returned, to allow chaining.
+//
+// Declarations are stored per function, not per function value. All functors
+// that are created by the same function literal share their declaration.
+func Declare(f Functor, name string, constraints ...Constraint) Functor {
+	if f == nil {
+		return f
+	}
+	decl := declaration{name: name, constraints: slices.Clone(constraints)}
+	mxDeclarations.Lock()
+	declarations[funcKey(f)] = decl
+	mxDeclarations.Unlock()
+	return f
+}
+
+// Name returns the declared name of the given functor. If no name was
+// declared, the name of the underlying Go function is returned.
+func Name(f Functor) string {
+	if f == nil {
+		return ""
+	}
+	key := funcKey(f)
+	if decl, found := lookupDeclaration(key); found && decl.name != "" {
+		return decl.name
+	}
+	if fn := runtime.FuncForPC(key); fn != nil {
+		return fn.Name()
+	}
+	return ""
+}
+
+func lookupDeclaration(key uintptr) (declaration, bool) {
+	mxDeclarations.RLock()
+	decl, found := declarations[key]
+	mxDeclarations.RUnlock()
+	return decl, found
+}
+
+func funcKey(f Functor) uintptr { return reflect.ValueOf(f).Pointer() }
+
+// Problem describes a violated constraint of a middleware.
+type Problem struct {
+	// Position of the functor in the order of request processing. The
+	// outermost functor, which sees the request first, has position 0.
+	Position int
+
+	Name       string     // Name of the functor.
+	Constraint Constraint // The violated constraint.
+	Message    string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%d %s (%v): %s", p.Position, p.Name, p.Constraint, p.Message)
+}
+
+// Validate checks the declared constraints of all functors of the given
+// middleware and returns all violations. It is intended to be called on
+// startup, so that misordered middleware can be logged or rejected.
+//
+// In addition, it reports contradictory constraints, which cannot be
+// satisfied by any order.
+func Validate(m Middleware) []Problem {
+	// Functors are applied from the inside out: reverse them to get the order
+	// of request processing.
+	funcs := slices.Collect(m.Functors())
+	slices.Reverse(funcs)
+
+	names := make([]string, len(funcs))
+	decls := make([]declaration, len(funcs))
+	for i, f := range funcs {
+		names[i] = Name(f)
+		decls[i], _ = lookupDeclaration(funcKey(f))
+	}
+
+	// Collect all edges "from" must process requests before "to".
+	type edge struct {
+		from, to   int
+		owner      int
+		constraint Constraint
+	}
+	var edges []edge
+	for i, decl := range decls {
+		for _, c := range decl.constraints {
+			for j, other := range decls {
+				if i == j {
+					continue
+				}
+				switch c.Kind {
+				case KindAfter:
+					if other.has(KindProvides, c.Name) {
+						edges = append(edges, edge{j, i, i, c})
+					}
+				case KindBefore:
+					if other.has(KindProvides, c.Name) {
+						edges = append(edges, edge{i, j, i, c})
+					}
+				case KindInnermostOf:
+					if other.has(KindMemberOf, c.Name) || other.has(KindInnermostOf, c.Name) {
+						edges = append(edges, edge{j, i, i, c})
+					}
+				}
+			}
+		}
+	}
+
+	var problems []Problem
+	for _, e := range edges {
+		if e.from < e.to {
+			continue
+		}
+		other := e.from
+		if other == e.owner {
+			other = e.to
+		}
+		problems = append(problems, Problem{
+			Position:   e.owner,
+			Name:       names[e.owner],
+			Constraint: e.constraint,
+			Message:    fmt.Sprintf("conflicts with %s at position %d", names[other], other),
+		})
+	}
+
+	// Check whether there is an order that satisfies all constraints.
+	indegree := make([]int, len(funcs))
+	for _, e := range edges {
+		indegree[e.to]++
+	}
+	queue := make([]int, 0, len(funcs))
+	for i, d := range indegree {
+		if d == 0 {
+			queue = append(queue, i)
+		}
+	}
+	for numSorted := 0; numSorted < len(queue); numSorted++ {
+		for _, e := range edges {
+			if e.from == queue[numSorted] {
+				if indegree[e.to]--; indegree[e.to] == 0 {
+					queue = append(queue, e.to)
+				}
+			}
+		}
+	}
+	if len(queue) < len(funcs) {
+		for i, d := range indegree {
+			if d > 0 {
+				problems = append(problems, Problem{
+					Position: i,
+					Name:     names[i],
+					Message:  "contradictory constraints, no valid order exists",
+				})
+			}
+		}
+	}
+	return problems
+}
+
+func (decl declaration) has(kind ConstraintKind, name string) bool {
+	return slices.Contains(decl.constraints, Constraint{kind, name})
+}
@@ -0,0 +1,115 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package middleware_test
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+	"testing"
+
+	"t73f.de/r/webs/middleware"
+	"t73f.de/r/webs/middleware/logging"
+	"t73f.de/r/webs/middleware/reqid"
+	"t73f.de/r/webs/middleware/status"
+)
+
+func provider(h http.Handler) http.Handler    { return h }
+func consumer(h http.Handler) http.Handler    { return h }
+func transformer(h http.Handler) http.Handler { return h }
+func innermost(h http.Handler) http.Handler   { return h }
+func cyclic(h http.Handler) http.Handler      { return h }
+func undeclared(h http.Handler) http.Handler  { return h }
+
+func init() {
+	middleware.Declare(provider, "provider", middleware.Provides("cap"))
+	middleware.Declare(consumer, "consumer", middleware.After("cap"), middleware.Provides("other"))
+	middleware.Declare(transformer, "transformer", middleware.MemberOf("body"))
+	middleware.Declare(innermost, "innermost", middleware.InnermostOf("body"))
+	middleware.Declare(cyclic, "cyclic", middleware.Provides("cap"), middleware.Before("other"), middleware.After("other"))
+}
+
+func TestValidate(t *testing.T) {
+	testcases := []struct {
+		name  string
+		chain middleware.Chain
+		exp   []string // names of functors with problems
+	}{
+		{"empty", middleware.NewChain(), nil},
+		{"undeclared", middleware.NewChain(undeclared, undeclared), nil},
+		{"valid", middleware.NewChain(provider, undeclared, consumer, transformer, innermost), nil},
+		{"missing-provider", middleware.NewChain(consumer), nil},
+		{"after", middleware.NewChain(consumer, provider), []string{"consumer"}},
+		{"innermost", middleware.NewChain(innermost, transformer), []string{"innermost"}},
+		{"innermost-twice", middleware.NewChain(transformer, innermost, innermost), []string{"innermost", "innermost", "innermost"}},
+		{"contradictory", middleware.NewChain(cyclic, consumer), []string{"cyclic", "cyclic", "consumer"}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			for _, p := range middleware.Validate(tc.chain) {
+				got = append(got, p.Name)
+			}
+			if !slices.Equal(got, tc.exp) {
+				t.Errorf("expected problems for %v, but got %v", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestValidatePosition(t *testing.T) {
+	problems := middleware.Validate(middleware.NewChain(undeclared, consumer, provider))
+	if len(problems) != 1 {
+		t.Fatalf("one problem expected, but got %v", problems)
+	}
+	p := problems[0]
+	if p.Position != 1 || p.Constraint != middleware.After("cap") {
+		t.Errorf("unexpected problem: %v", p)
+	}
+
+	// Lists are built from the inside out.
+	lst := middleware.NewList(consumer, middleware.NewList(provider, nil))
+	if problems = middleware.Validate(lst); len(problems) != 0 {
+		t.Errorf("no problems expected, but got %v", problems)
+	}
+}
+
+func TestValidateBuiltin(t *testing.T) {
+	reqidCfg := reqid.Config{}
+	logCfg := logging.ReqConfig{Logger: slog.New(slog.DiscardHandler), WithRequestID: true}
+	statusCfg := status.Config{}
+
+	valid := middleware.NewChain(reqidCfg.Build(), logCfg.Build(), statusCfg.Build())
+	if problems := middleware.Validate(valid); len(problems) != 0 {
+		t.Errorf("no problems expected, but got %v", problems)
+	}
+
+	invalid := middleware.NewChain(logCfg.Build(), reqidCfg.Build())
+	problems := middleware.Validate(invalid)
+	if len(problems) != 1 || problems[0].Name != "logging-request" {
+		t.Errorf("logging problem expected, but got %v", problems)
+	}
+}
+
+func TestName(t *testing.T) {
+	if got, exp := middleware.Name(provider), "provider"; got != exp {
+		t.Errorf("expected name %q, but got %q", exp, got)
+	}
+	if got, exp := middleware.Name(undeclared), "t73f.de/r/webs/middleware_test.undeclared"; got != exp {
+		t.Errorf("expected name %q, but got %q", exp, got)
+	}
+	if got := middleware.Name(nil); got != "" {
+		t.Errorf("empty name expected, but got %q", got)
+	}
+}
@@ -0,0 +1,151 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+// Package contextinfo collects selected values of a request context, e.g. to
+// attach them to error reports and log entries.
+//
+// Values are provided by extractors, which are registered by name. Packages
+// that store values in a context register their extractors when they are
+// imported: [t73f.de/r/webs/middleware/reqid] ("request-id"),
+// [t73f.de/r/webs/login] ("user"), and
+// [t73f.de/r/webs/middleware/experiment] ("experiments").
+package contextinfo
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"unicode/utf8"
+)
+
+// Extractor retrieves a value from a context. It returns false, if the
+// context does not contain the value.
+type Extractor func(context.Context) (string, bool)
+
+// MaxValueLength is the maximum length of a value in bytes. Longer values are
+// truncated and marked with a trailing "…".
+const MaxValueLength = 256
+
+var (
+	mx         sync.RWMutex
+	extractors = map[string]Extractor{}
+)
+
+// Register an extractor with the given name. Typically, it is called in an
+// init function of the package that stores the value in a context.
+//
+// Register panics, if the name is empty, if the extractor is nil, or if
+// another extractor was registered with the same name.
+func Register(name string, fn Extractor) {
+	if name == "" {
+		panic("contextinfo: empty name")
+	}
+	if fn == nil {
+		panic("contextinfo: nil extractor for " + name)
+	}
+	mx.Lock()
+	defer mx.Unlock()
+	if _, found := extractors[name]; found {
+		panic("contextinfo: extractor registered twice: " + name)
+	}
+	extractors[name] = fn
+}
+
+// Names returns the sorted names of all registered extractors.
+func Names() []string {
+	mx.RLock()
+	defer mx.RUnlock()
+	names := make([]string, 0, len(extractors))
+	for name := range extractors {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Snapshot runs all registered extractors on the given context, in the order
+// of their names, and returns the values that were found.
+//
+// A panicking extractor does not affect the others; its value is omitted.
+// Values are truncated to [MaxValueLength] bytes. If no value was found, nil
+// is returned.
+func Snapshot(ctx context.Context) map[string]string {
+	var result map[string]string
+	for _, ev := range extractorsByName() {
+		if value, found := extract(ctx, ev.fn); found {
+			if result == nil {
+				result = make(map[string]string)
+			}
+			result[ev.name] = truncate(value)
+		}
+	}
+	return result
+}
+
+// Attrs returns the snapshot of the context as log attributes, sorted by
+// name.
+func Attrs(ctx context.Context) []slog.Attr {
+	snapshot := Snapshot(ctx)
+	if len(snapshot) == 0 {
+		return nil
+	}
+	attrs := make([]slog.Attr, 0, len(snapshot))
+	for _, ev := range extractorsByName() {
+		if value, found := snapshot[ev.name]; found {
+			attrs = append(attrs, slog.String(ev.name, value))
+		}
+	}
+	return attrs
+}
+
+type namedExtractor struct {
+	name string
+	fn   Extractor
+}
+
+func extractorsByName() []namedExtractor {
+	mx.RLock()
+	defer mx.RUnlock()
+	result := make([]namedExtractor, 0, len(extractors))
+	for name, fn := range extractors {
+		result = append(result, namedExtractor{name, fn})
+	}
+	slices.SortFunc(result, func(a, b namedExtractor) int { return cmp.Compare(a.name, b.name) })
+	return result
+}
+
+// extract runs the extractor and recovers from a panic.
+func extract(ctx context.Context, fn Extractor) (value string, found bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			value, found = "", false
+		}
+	}()
+	return fn(ctx)
+}
+
+// truncate shortens a value to at most MaxValueLength bytes, without
+// splitting a multi-byte character.
+func truncate(value string) string {
+	if len(value) <= MaxValueLength {
+		return value
+	}
+	const ellipsis = "…"
+	end := MaxValueLength - len(ellipsis)
+	for end > 0 && !utf8.RuneStart(value[end]) {
+		end--
+	}
+	return value[:end] + ellipsis
+}
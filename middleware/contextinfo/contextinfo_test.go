@@ -0,0 +1,151 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package contextinfo_test
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"t73f.de/r/webs/login"
+	"t73f.de/r/webs/middleware/contextinfo"
+	"t73f.de/r/webs/middleware/experiment"
+	"t73f.de/r/webs/middleware/reqid"
+)
+
+type testKeyType struct{}
+
+func testValue(ctx context.Context) (string, bool) {
+	s, ok := ctx.Value(testKeyType{}).(string)
+	return s, ok
+}
+
+func init() {
+	contextinfo.Register("test-value", testValue)
+	contextinfo.Register("test-panic", func(ctx context.Context) (string, bool) {
+		if s, _ := testValue(ctx); s == "panic" {
+			panic("extractor failed")
+		}
+		return "still here", true
+	})
+}
+
+func TestSnapshot(t *testing.T) {
+	ctx := context.WithValue(context.Background(), testKeyType{}, "value")
+	snapshot := contextinfo.Snapshot(ctx)
+	if exp := map[string]string{"test-value": "value", "test-panic": "still here"}; !maps.Equal(snapshot, exp) {
+		t.Errorf("%v expected, got %v", exp, snapshot)
+	}
+
+	ctx = context.WithValue(context.Background(), testKeyType{}, "panic")
+	snapshot = contextinfo.Snapshot(ctx)
+	if exp := map[string]string{"test-value": "panic"}; !maps.Equal(snapshot, exp) {
+		t.Errorf("panicking extractor must be omitted: %v expected, got %v", exp, snapshot)
+	}
+
+	attrs := contextinfo.Attrs(ctx)
+	if len(attrs) != 1 || !attrs[0].Equal(slog.String("test-value", "panic")) {
+		t.Errorf("one attribute expected, got %v", attrs)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	long := strings.Repeat("ä", contextinfo.MaxValueLength)
+	ctx := context.WithValue(context.Background(), testKeyType{}, long)
+	got := contextinfo.Snapshot(ctx)["test-value"]
+	if len(got) > contextinfo.MaxValueLength || !utf8.ValidString(got) || !strings.HasSuffix(got, "…") {
+		t.Errorf("truncated value expected, got %d bytes: %q", len(got), got)
+	}
+	exact := strings.Repeat("x", contextinfo.MaxValueLength)
+	ctx = context.WithValue(context.Background(), testKeyType{}, exact)
+	if got = contextinfo.Snapshot(ctx)["test-value"]; got != exact {
+		t.Errorf("value of maximum length must not be truncated, got %q", got)
+	}
+}
+
+func TestRegisterInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		fn   contextinfo.Extractor
+	}{
+		{"", testValue},
+		{"nil", nil},
+		{"test-value", testValue},
+		{reqid.ContextInfoName, testValue},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("registration of %q must panic", tc.name)
+				}
+			}()
+			contextinfo.Register(tc.name, tc.fn)
+		}()
+	}
+}
+
+func TestBuiltin(t *testing.T) {
+	names := contextinfo.Names()
+	for _, name := range []string{reqid.ContextInfoName, login.ContextInfoName, experiment.ContextInfoName} {
+		if !slices.Contains(names, name) {
+			t.Errorf("%q must be registered, got %v", name, names)
+		}
+	}
+	if !slices.IsSorted(names) {
+		t.Errorf("names must be sorted: %v", names)
+	}
+
+	lp := login.MakeProvider(slog.New(slog.DiscardHandler), &login.TestAuthenticator{}, &login.RAMSessions{}, &login.SimpleRedirector{})
+	form := url.Values{lp.UsernameKey: {"alice"}, lp.PasswordKey: {"secret"}}
+	r := httptest.NewRequest(http.MethodPost, "/login/", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	lp.Login().ServeHTTP(w, r)
+
+	var snapshot map[string]string
+	var h http.Handler = http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		snapshot = contextinfo.Snapshot(r.Context())
+	})
+	h = lp.EnrichUserInfo(h)
+	expCfg := experiment.Config{Name: "button", Variants: []experiment.Choice{{Name: "blue", Weight: 1}}}
+	h = expCfg.Build()(h)
+	reqidCfg := reqid.Config{WithContext: true}
+	h = reqidCfg.Build()(h)
+
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, cookie := range w.Result().Cookies() {
+		r.AddCookie(cookie)
+	}
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	if got := snapshot[login.ContextInfoName]; got != "alice" {
+		t.Errorf("user alice expected, got %q", got)
+	}
+	if got := snapshot[experiment.ContextInfoName]; got != "button=blue" {
+		t.Errorf("experiment variant expected, got %q", got)
+	}
+	if got := snapshot[reqid.ContextInfoName]; got == "" {
+		t.Error("request id expected")
+	}
+
+	if got := contextinfo.Snapshot(context.Background()); !maps.Equal(got, map[string]string{"test-panic": "still here"}) {
+		t.Errorf("no built-in values expected, got %v", got)
+	}
+}
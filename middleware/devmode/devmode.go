@@ -0,0 +1,187 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+// Package devmode provides a middleware functor to be used during
+// development. It disables all caching and makes timing information visible
+// in the browser.
+package devmode
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"t73f.de/r/webs/middleware"
+	"t73f.de/r/webs/middleware/reqid"
+)
+
+// RequestIDHeaderKey is the key of the response header that echoes the
+// request identifier.
+const RequestIDHeaderKey = "X-Dev-Request-ID"
+
+// AppTimingName is the name of the Server-Timing metric that contains the
+// duration of the handler.
+const AppTimingName = "app"
+
+// Build the development mode functor.
+//
+// If not enabled, [middleware.NilFunctor] is returned. Otherwise, every
+// response gets the headers "Cache-Control: no-store" and "Pragma: no-cache",
+// while the headers "ETag", "Last-Modified", and "Expires" are removed.
+// Conditional request headers are removed too, so that a handler never
+// responds with 304 (Not Modified).
+//
+// A "Server-Timing" header contains the duration of the handler until the
+// response header was written, and all timings added with [AddTiming] until
+// then. If a request identifier was set, see package reqid, it is echoed in
+// the header [RequestIDHeaderKey].
+func Build(enabled bool) middleware.Functor {
+	if !enabled {
+		return middleware.NilFunctor
+	}
+	return middleware.Declare(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tl := &timingList{}
+			ctx := context.WithValue(r.Context(), ctxKeyType{}, tl)
+			r = r.WithContext(ctx)
+			for _, key := range conditionalHeaders {
+				r.Header.Del(key)
+			}
+
+			drw := devRespWriter{w: w, r: r, tl: tl, start: time.Now()}
+			next.ServeHTTP(&drw, r)
+			drw.prepareHeader()
+		})
+	}, "devmode", middleware.After(reqid.Capability))
+}
+
+var conditionalHeaders = []string{
+	"If-Match", "If-None-Match", "If-Modified-Since", "If-Unmodified-Since", "If-Range",
+}
+
+// AddTiming adds a named duration to the Server-Timing header of the
+// response, e.g. the duration of a database query. It is safe to be called
+// concurrently. If the development mode is not enabled, or if the response
+// header was already written, the timing is ignored.
+func AddTiming(ctx context.Context, name string, d time.Duration) {
+	if tl, ok := ctx.Value(ctxKeyType{}).(*timingList); ok {
+		tl.add(name, d)
+	}
+}
+
+type ctxKeyType struct{}
+
+type timing struct {
+	name string
+	dur  time.Duration
+}
+
+// timingList accumulates the timings of one request.
+type timingList struct {
+	mx      sync.Mutex
+	timings []timing
+	closed  bool
+}
+
+func (tl *timingList) add(name string, d time.Duration) {
+	tl.mx.Lock()
+	if !tl.closed {
+		tl.timings = append(tl.timings, timing{name, d})
+	}
+	tl.mx.Unlock()
+}
+
+// close returns all timings, and ignores all further timings.
+func (tl *timingList) close() []timing {
+	tl.mx.Lock()
+	defer tl.mx.Unlock()
+	tl.closed = true
+	return tl.timings
+}
+
+type devRespWriter struct {
+	w     http.ResponseWriter
+	r     *http.Request
+	tl    *timingList
+	start time.Time
+
+	prepared bool
+}
+
+func (drw *devRespWriter) Header() http.Header { return drw.w.Header() }
+
+func (drw *devRespWriter) WriteHeader(code int) {
+	drw.prepareHeader()
+	drw.w.WriteHeader(code)
+}
+
+func (drw *devRespWriter) Write(data []byte) (int, error) {
+	drw.prepareHeader()
+	return drw.w.Write(data)
+}
+
+// Unwrap returns the underlying response writer, see [http.ResponseController].
+func (drw *devRespWriter) Unwrap() http.ResponseWriter { return drw.w }
+
+// prepareHeader updates the response header once, before it is written.
+func (drw *devRespWriter) prepareHeader() {
+	if drw.prepared {
+		return
+	}
+	drw.prepared = true
+	elapsed := time.Since(drw.start)
+
+	h := drw.w.Header()
+	h.Set("Cache-Control", "no-store")
+	h.Set("Pragma", "no-cache")
+	h.Del("ETag")
+	h.Del("Last-Modified")
+	h.Del("Expires")
+
+	h.Add("Server-Timing", formatTiming(AppTimingName, elapsed))
+	for _, t := range drw.tl.close() {
+		h.Add("Server-Timing", formatTiming(t.name, t.dur))
+	}
+
+	if id := reqid.GetRequestID(drw.r.Context()); !id.IsInvalid() {
+		h.Set(RequestIDHeaderKey, id.String())
+	} else if s := drw.r.Header.Get(reqid.DefaultHeaderKey); s != "" {
+		h.Set(RequestIDHeaderKey, s)
+	}
+}
+
+// formatTiming formats a Server-Timing metric, with the duration in
+// milliseconds. Characters of the name that are not allowed in a token are
+// replaced by "-".
+func formatTiming(name string, d time.Duration) string {
+	name = strings.Map(func(r rune) rune {
+		if isTokenChar(r) {
+			return r
+		}
+		return '-'
+	}, name)
+	if name == "" {
+		name = "-"
+	}
+	return name + ";dur=" + strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 3, 64)
+}
+
+func isTokenChar(r rune) bool {
+	if 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' {
+		return true
+	}
+	return r < 128 && strings.ContainsRune("!#$%&'*+-.^_`|~", r)
+}
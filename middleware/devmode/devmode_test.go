@@ -0,0 +1,147 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package devmode_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"t73f.de/r/webs/middleware/devmode"
+	"t73f.de/r/webs/middleware/reqid"
+)
+
+func TestHeaders(t *testing.T) {
+	var gotConditional string
+	handler := devmode.Build(true)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotConditional = r.Header.Get("If-None-Match")
+		devmode.AddTiming(r.Context(), "db", 1500*time.Microsecond)
+		devmode.AddTiming(r.Context(), "tpl render", 2*time.Millisecond)
+		h := w.Header()
+		h.Set("Cache-Control", "max-age=3600")
+		h.Set("ETag", `"abc"`)
+		h.Set("Last-Modified", "Mon, 01 Jan 2024 00:00:00 GMT")
+		_, _ = w.Write([]byte("Hello"))
+		devmode.AddTiming(r.Context(), "late", time.Millisecond)
+	}))
+	reqidcfg := reqid.Config{WithContext: true}
+	handler = reqidcfg.Build()(handler)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("If-None-Match", `"abc"`)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, r)
+
+	if gotConditional != "" {
+		t.Errorf("conditional request header must be removed, got %q", gotConditional)
+	}
+	h := rr.Header()
+	if got := h.Get("Cache-Control"); got != "no-store" {
+		t.Errorf("Cache-Control: no-store expected, got %q", got)
+	}
+	if got := h.Get("Pragma"); got != "no-cache" {
+		t.Errorf("Pragma: no-cache expected, got %q", got)
+	}
+	for _, key := range []string{"ETag", "Last-Modified"} {
+		if got := h.Get(key); got != "" {
+			t.Errorf("%s must be removed, got %q", key, got)
+		}
+	}
+	timings := h.Values("Server-Timing")
+	if len(timings) != 3 {
+		t.Fatalf("three timings expected, got %v", timings)
+	}
+	if !strings.HasPrefix(timings[0], devmode.AppTimingName+";dur=") {
+		t.Errorf("app timing expected, got %q", timings[0])
+	}
+	if got, exp := timings[1], "db;dur=1.500"; got != exp {
+		t.Errorf("timing %q expected, got %q", exp, got)
+	}
+	if got, exp := timings[2], "tpl-render;dur=2.000"; got != exp {
+		t.Errorf("timing %q expected, got %q", exp, got)
+	}
+	if got := h.Get(devmode.RequestIDHeaderKey); got == "" || got != r.Header.Get(reqid.DefaultHeaderKey) {
+		t.Errorf("request id %q expected, got %q", r.Header.Get(reqid.DefaultHeaderKey), got)
+	}
+	if got := rr.Body.String(); got != "Hello" {
+		t.Errorf("body %q expected, got %q", "Hello", got)
+	}
+}
+
+func TestNoWrite(t *testing.T) {
+	handler := devmode.Build(true)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("ETag", `"abc"`)
+	}))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	h := rr.Header()
+	if got := h.Get("Cache-Control"); got != "no-store" {
+		t.Errorf("Cache-Control: no-store expected, got %q", got)
+	}
+	if got := h.Get("ETag"); got != "" {
+		t.Errorf("ETag must be removed, got %q", got)
+	}
+	if got := h.Get(devmode.RequestIDHeaderKey); got != "" {
+		t.Errorf("no request id expected, got %q", got)
+	}
+}
+
+func TestConcurrentTimings(t *testing.T) {
+	const numTimings = 100
+	handler := devmode.Build(true)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var wg sync.WaitGroup
+		for i := range numTimings {
+			wg.Go(func() {
+				devmode.AddTiming(r.Context(), "t"+strconv.Itoa(i), time.Duration(i)*time.Millisecond)
+			})
+		}
+		wg.Wait()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got := len(rr.Header().Values("Server-Timing")); got != numTimings+1 {
+		t.Errorf("%d timings expected, got %d", numTimings+1, got)
+	}
+	if got := rr.Code; got != http.StatusNoContent {
+		t.Errorf("status %d expected, got %d", http.StatusNoContent, got)
+	}
+}
+
+type plainHandler struct{}
+
+func (*plainHandler) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("ETag", `"abc"`)
+}
+
+func TestDisabled(t *testing.T) {
+	h := &plainHandler{}
+	if got := devmode.Build(false)(h); got != http.Handler(h) {
+		t.Errorf("disabled mode must not wrap handler, got %T", got)
+	}
+
+	// Without the development mode, timings are ignored.
+	devmode.AddTiming(context.Background(), "db", time.Second)
+	allocs := testing.AllocsPerRun(100, func() {
+		devmode.AddTiming(context.Background(), "db", time.Second)
+	})
+	if allocs != 0 {
+		t.Errorf("AddTiming must not allocate when disabled, got %v allocations", allocs)
+	}
+}
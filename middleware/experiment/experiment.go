@@ -0,0 +1,240 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+// Package experiment provides a middleware functor that assigns visitors to
+// variants of an A/B experiment.
+//
+// Each visitor is identified by a sticky cookie. The variant is derived from
+// a hash of the visitor identifier, the experiment name, and a salt. It is
+// not stored, so it is stable as long as the configuration does not change.
+package experiment
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"log/slog"
+	"maps"
+	"math/bits"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"t73f.de/r/webs/login"
+	"t73f.de/r/webs/middleware"
+	"t73f.de/r/webs/middleware/contextinfo"
+	"t73f.de/r/zero/contexts"
+)
+
+// DefaultCookieName is the default value for [Config.CookieName].
+const DefaultCookieName = "webs-visitor"
+
+// DefaultTTL is the default value for [Config.TTL].
+const DefaultTTL = 90 * 24 * time.Hour
+
+// Choice is a variant of an experiment, together with its relative weight.
+type Choice struct {
+	Name   string
+	Weight uint
+}
+
+// Config stores all configuration data to build an experiment functor.
+type Config struct {
+	// Name of the experiment. It must be unique among all experiments.
+	Name string
+
+	// Variants of the experiment. A visitor is assigned to a variant with a
+	// probability proportional to its weight.
+	//
+	// Assignments are derived from the relative position of the weights.
+	// If the sum of all weights is kept constant (e.g. percentages), changing
+	// weights only re-assigns visitors in the changed ranges. For example,
+	// visitors of variant "A" stay there, when the weights change from
+	// A:50, B:50 to A:50, B:30, C:20.
+	Variants []Choice
+
+	// CookieName is the name of the cookie that stores the visitor
+	// identifier. Experiments with the same cookie name share it.
+	CookieName string
+
+	// TTL is the maximum age of the cookie.
+	TTL time.Duration
+
+	// Salt is mixed into the hash, to get independent assignments for
+	// different experiments or for a restart of an experiment.
+	Salt string
+
+	// Header is the name of an optional response header, which contains
+	// the assigned variant. It allows caches to separate variants.
+	Header string
+
+	// Logger logs the assignment of a new visitor, if not nil.
+	Logger *slog.Logger
+}
+
+// Build the Functor from the configuration.
+func (c *Config) Build() middleware.Functor {
+	var total uint64
+	for _, v := range c.Variants {
+		total += uint64(v.Weight)
+	}
+	if c.Name == "" || total == 0 {
+		return middleware.NilFunctor
+	}
+	name, salt, header, logger := c.Name, c.Salt, c.Header, c.Logger
+	variants := slices.Clone(c.Variants)
+	cookieName := c.CookieName
+	if cookieName == "" {
+		cookieName = DefaultCookieName
+	}
+	ttl := c.TTL
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+
+	return middleware.Declare(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			visitor, isNew := visitorID(r, cookieName)
+			if isNew {
+				http.SetCookie(w, &http.Cookie{
+					Name:     cookieName,
+					Value:    visitor,
+					Path:     "/",
+					MaxAge:   int(ttl.Seconds()),
+					HttpOnly: true,
+					SameSite: http.SameSiteLaxMode,
+				})
+			}
+
+			variant := assign(variants, total, salt, name, visitor)
+			if isNew && logger != nil {
+				logger.InfoContext(ctx, "experiment assignment",
+					"experiment", name, "variant", variant, "visitor", visitor)
+			}
+			if header != "" {
+				w.Header().Set(header, variant)
+			}
+
+			vs, _ := getVisitor(ctx)
+			assigned := maps.Clone(vs.variants)
+			if assigned == nil {
+				assigned = make(map[string]string, 1)
+			}
+			assigned[name] = variant
+			ctx = withVisitor(ctx, visitorState{
+				ids:      addVisitorID(vs.ids, cookieName, visitor),
+				variants: assigned,
+			})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}, "experiment")
+}
+
+// visitorID returns the identifier of the visitor, and whether it is a new
+// one, which must be stored in a cookie.
+func visitorID(r *http.Request, cookieName string) (string, bool) {
+	if vs, found := getVisitor(r.Context()); found {
+		if id, found := vs.ids[cookieName]; found {
+			// An outer experiment already determined the visitor.
+			return id, false
+		}
+	}
+	if cookie, err := r.Cookie(cookieName); err == nil && cookie.Value != "" {
+		return cookie.Value, false
+	}
+	if session := login.Session(r.Context()); session != nil && session.SessionID != "" {
+		sum := sha256.Sum256([]byte(session.SessionID))
+		return hex.EncodeToString(sum[:16]), true
+	}
+	return rand.Text(), true
+}
+
+// assign a variant to the visitor.
+func assign(variants []Choice, total uint64, salt, name, visitor string) string {
+	h := sha256.New()
+	h.Write([]byte(salt))
+	h.Write([]byte{0})
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write([]byte(visitor))
+	sum := h.Sum(nil)
+
+	// pos is uniformly distributed in [0, total).
+	pos, _ := bits.Mul64(binary.BigEndian.Uint64(sum), total)
+	for _, v := range variants {
+		if pos < uint64(v.Weight) {
+			return v.Name
+		}
+		pos -= uint64(v.Weight)
+	}
+	return variants[len(variants)-1].Name // not reached
+}
+
+type visitorState struct {
+	ids      map[string]string // cookie name -> visitor identifier
+	variants map[string]string // experiment name -> variant name
+}
+
+func addVisitorID(ids map[string]string, cookieName, visitor string) map[string]string {
+	if ids[cookieName] == visitor {
+		return ids
+	}
+	result := maps.Clone(ids)
+	if result == nil {
+		result = make(map[string]string, 1)
+	}
+	result[cookieName] = visitor
+	return result
+}
+
+type ctxKeyType struct{}
+
+var withVisitor, getVisitor = contexts.WithAndValue[visitorState](ctxKeyType{})
+
+// ContextInfoName is the name of the assigned variants in a snapshot of
+// package contextinfo. The value lists all experiments with their variant,
+// e.g. "button=blue,layout=wide", sorted by experiment name.
+const ContextInfoName = "experiments"
+
+func init() {
+	contextinfo.Register(ContextInfoName, func(ctx context.Context) (string, bool) {
+		vs, found := getVisitor(ctx)
+		if !found || len(vs.variants) == 0 {
+			return "", false
+		}
+		var sb strings.Builder
+		for _, name := range slices.Sorted(maps.Keys(vs.variants)) {
+			if sb.Len() > 0 {
+				sb.WriteByte(',')
+			}
+			sb.WriteString(name)
+			sb.WriteByte('=')
+			sb.WriteString(vs.variants[name])
+		}
+		return sb.String(), true
+	})
+}
+
+// Variant returns the name of the variant of the given experiment that was
+// assigned to the current visitor, or the empty string if the experiment was
+// not active for the request.
+func Variant(ctx context.Context, name string) string {
+	if vs, found := getVisitor(ctx); found {
+		return vs.variants[name]
+	}
+	return ""
+}
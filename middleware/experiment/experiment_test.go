@@ -0,0 +1,158 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package experiment_test
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"t73f.de/r/webs/middleware"
+	"t73f.de/r/webs/middleware/experiment"
+	"t73f.de/r/webs/middleware/middlewaretest"
+)
+
+// variantHandler writes the variants of the experiments "one" and "two".
+var variantHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	_, _ = fmt.Fprintf(w, "%s/%s",
+		experiment.Variant(r.Context(), "one"), experiment.Variant(r.Context(), "two"))
+})
+
+func serve(h http.Handler, visitor string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if visitor != "" {
+		r.AddCookie(&http.Cookie{Name: experiment.DefaultCookieName, Value: visitor})
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestStickyAssignment(t *testing.T) {
+	logger, recs := middlewaretest.CaptureLogs(t)
+	cfg := experiment.Config{
+		Name:     "one",
+		Variants: []experiment.Choice{{"A", 1}, {"B", 1}},
+		Header:   "X-Experiment-One",
+		Logger:   logger,
+	}
+	h := cfg.Build()(variantHandler)
+
+	w := serve(h, "")
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != experiment.DefaultCookieName || cookies[0].Value == "" {
+		t.Fatalf("expected visitor cookie, but got %v", cookies)
+	}
+	variant := w.Header().Get("X-Experiment-One")
+	if variant != "A" && variant != "B" {
+		t.Fatalf("unexpected variant %q", variant)
+	}
+	if got := w.Body.String(); got != variant+"/" {
+		t.Errorf("expected variant %q in context, but got %q", variant, got)
+	}
+	if got := len(recs.All()); got != 1 {
+		t.Errorf("new visitor must be logged once, but got %d records", got)
+	}
+
+	for range 10 {
+		w = serve(h, cookies[0].Value)
+		if got := w.Header().Get("X-Experiment-One"); got != variant {
+			t.Errorf("variant must be sticky: expected %q, but got %q", variant, got)
+		}
+		if got := w.Result().Cookies(); len(got) != 0 {
+			t.Errorf("no new cookie expected, but got %v", got)
+		}
+	}
+	if got := len(recs.All()); got != 1 {
+		t.Errorf("known visitor must not be logged, but got %d records", got)
+	}
+}
+
+func TestCompose(t *testing.T) {
+	one := experiment.Config{Name: "one", Variants: []experiment.Choice{{"A", 1}, {"B", 1}}}
+	two := experiment.Config{Name: "two", Variants: []experiment.Choice{{"X", 1}, {"Y", 1}}}
+	h := middleware.Apply(middleware.NewChain(one.Build(), two.Build()), variantHandler)
+
+	w := serve(h, "")
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("exactly one visitor cookie expected, but got %v", cookies)
+	}
+	first := w.Body.String()
+	if len(first) != 3 {
+		t.Fatalf("both experiments must be assigned, but got %q", first)
+	}
+	if got := serve(h, cookies[0].Value).Body.String(); got != first {
+		t.Errorf("expected variants %q, but got %q", first, got)
+	}
+}
+
+func TestDistribution(t *testing.T) {
+	weights := map[string]uint{"A": 50, "B": 30, "C": 20}
+	cfg := experiment.Config{
+		Name:     "one",
+		Variants: []experiment.Choice{{"A", weights["A"]}, {"B", weights["B"]}, {"C", weights["C"]}},
+		Salt:     "distribution",
+	}
+	h := cfg.Build()(variantHandler)
+
+	const numVisitors = 10000
+	counts := map[string]int{}
+	for i := range numVisitors {
+		w := serve(h, fmt.Sprintf("visitor-%d", i))
+		counts[w.Body.String()[:1]]++
+	}
+	for name, weight := range weights {
+		exp := float64(weight) / 100
+		got := float64(counts[name]) / numVisitors
+		if math.Abs(got-exp) > 0.02 {
+			t.Errorf("variant %s: expected ratio %.2f, but got %.3f", name, exp, got)
+		}
+	}
+}
+
+func TestWeightChange(t *testing.T) {
+	before := experiment.Config{Name: "one", Variants: []experiment.Choice{{"A", 50}, {"B", 50}}}
+	after := experiment.Config{Name: "one", Variants: []experiment.Choice{{"A", 50}, {"B", 30}, {"C", 20}}}
+	hBefore, hAfter := before.Build()(variantHandler), after.Build()(variantHandler)
+
+	for i := range 1000 {
+		visitor := fmt.Sprintf("visitor-%d", i)
+		vBefore := serve(hBefore, visitor).Body.String()
+		vAfter := serve(hAfter, visitor).Body.String()
+		if vBefore == "A/" && vAfter != "A/" {
+			t.Errorf("visitor %s moved from A to %q", visitor, vAfter)
+		}
+		if vAfter == "B/" && vBefore != "B/" {
+			t.Errorf("visitor %s moved from %q to B", visitor, vBefore)
+		}
+	}
+}
+
+func TestInvalidConfig(t *testing.T) {
+	for _, cfg := range []experiment.Config{
+		{Variants: []experiment.Choice{{"A", 1}}},
+		{Name: "one"},
+		{Name: "one", Variants: []experiment.Choice{{"A", 0}}},
+	} {
+		if got := cfg.Build(); got(variantHandler) == nil {
+			t.Errorf("%v: functor must return a handler", cfg)
+		}
+		if got := serve(cfg.Build()(variantHandler), "").Body.String(); got != "/" {
+			t.Errorf("%v: no variant expected, but got %q", cfg, got)
+		}
+	}
+}
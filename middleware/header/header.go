@@ -40,7 +40,7 @@ func (c *Config) Build() middleware.Functor {
 	}
 	constMap := maps.Clone(c.Constants)
 	funcMap := maps.Clone(c.Functions)
-	return func(next http.Handler) http.Handler {
+	return middleware.Declare(func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			header := w.Header()
 			for k, v := range constMap {
@@ -55,5 +55,5 @@ func (c *Config) Build() middleware.Functor {
 			}
 			next.ServeHTTP(w, r)
 		})
-	}
+	}, "header")
 }
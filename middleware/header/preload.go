@@ -0,0 +1,162 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package header
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+
+	"t73f.de/r/webs/middleware"
+)
+
+// Resource describes a resource that should be preloaded by the client.
+type Resource struct {
+	Href        string // URL of the resource, e.g. a hashed asset path.
+	As          string // Destination, e.g. "style", "script", "font".
+	Type        string // Optional MIME type, e.g. "font/woff2".
+	Crossorigin string // Optional CORS mode: "anonymous" or "use-credentials".
+}
+
+// Link returns the value of a "Link" header that preloads the resource, as
+// specified in RFC 8288.
+func (res Resource) Link() string {
+	var sb strings.Builder
+	sb.WriteByte('<')
+	writeLinkTarget(&sb, res.Href)
+	sb.WriteString(">; rel=preload")
+	writeLinkParam(&sb, "as", res.As)
+	writeLinkParam(&sb, "type", res.Type)
+	switch co := res.Crossorigin; co {
+	case "":
+	case "anonymous":
+		sb.WriteString("; crossorigin")
+	default:
+		writeLinkParam(&sb, "crossorigin", co)
+	}
+	return sb.String()
+}
+
+func writeLinkTarget(sb *strings.Builder, href string) {
+	const hex = "0123456789ABCDEF"
+	for i := range len(href) {
+		ch := href[i]
+		if ch <= ' ' || ch >= 0x7f || ch == '<' || ch == '>' || ch == '"' {
+			sb.WriteByte('%')
+			sb.WriteByte(hex[ch>>4])
+			sb.WriteByte(hex[ch&0xf])
+		} else {
+			sb.WriteByte(ch)
+		}
+	}
+}
+
+func writeLinkParam(sb *strings.Builder, key, value string) {
+	if value == "" {
+		return
+	}
+	sb.WriteString("; ")
+	sb.WriteString(key)
+	sb.WriteByte('=')
+	if isToken(value) {
+		sb.WriteString(value)
+		return
+	}
+	sb.WriteByte('"')
+	for _, ch := range value {
+		if ch == '"' || ch == '\\' {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(ch)
+	}
+	sb.WriteByte('"')
+}
+
+// isToken returns true, if the value is a token according to RFC 9110.
+func isToken(value string) bool {
+	for _, ch := range value {
+		switch {
+		case 'a' <= ch && ch <= 'z', 'A' <= ch && ch <= 'Z', '0' <= ch && ch <= '9':
+		case strings.ContainsRune("!#$%&'*+-.^_`|~", ch):
+		default:
+			return false
+		}
+	}
+	return value != ""
+}
+
+// PreloadConfig stores all configuration data to build a functor that
+// announces resources to be preloaded.
+type PreloadConfig struct {
+	// Resources returns the resources to preload for the given request.
+	// Typically, it depends on the route of the request.
+	Resources func(*http.Request) []Resource
+
+	// EarlyHints enables sending an informational "103 Early Hints" response
+	// with the "Link" headers, before the handler is invoked. This is only
+	// done for HTTP/1.1 and later, and only if the response writer of package
+	// net/http can be reached, possibly through the "Unwrap" methods of
+	// wrapping writers, see [http.ResponseController]. The early hints are
+	// sent directly to it, so that wrapping writers, e.g. of logging or
+	// status, do not take them as the final status code. Otherwise, e.g.
+	// with a [net/http/httptest.ResponseRecorder], only the "Link" headers of
+	// the final response are sent.
+	EarlyHints bool
+}
+
+// Build the Functor from the configuration.
+//
+// The "Link" headers are added to the final response. If enabled, they are
+// also sent with an early hints response.
+func (c *PreloadConfig) Build() middleware.Functor {
+	resources := c.Resources
+	if resources == nil {
+		return middleware.NilFunctor
+	}
+	earlyHints := c.EarlyHints
+	return middleware.Declare(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if res := resources(r); len(res) > 0 {
+				header := w.Header()
+				for _, resource := range res {
+					header.Add("Link", resource.Link())
+				}
+				if earlyHints && r.ProtoAtLeast(1, 1) {
+					if hw := informationalWriter(w); hw != nil {
+						hw.WriteHeader(http.StatusEarlyHints)
+					}
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}, "header-preload")
+}
+
+// informationalWriter returns the response writer of package net/http that is
+// wrapped by the given writer, or nil, if there is none. Only this writer is
+// known to send informational responses, instead of treating them as the
+// final status code.
+func informationalWriter(w http.ResponseWriter) http.ResponseWriter {
+	for w != nil {
+		if t := reflect.TypeOf(w); t.Kind() == reflect.Pointer && t.Elem().PkgPath() == "net/http" {
+			return w
+		}
+		uw, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return nil
+		}
+		w = uw.Unwrap()
+	}
+	return nil
+}
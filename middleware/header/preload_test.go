@@ -0,0 +1,178 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package header_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/textproto"
+	"slices"
+	"testing"
+
+	"t73f.de/r/webs/middleware/header"
+)
+
+func TestResourceLink(t *testing.T) {
+	testcases := []struct {
+		res header.Resource
+		exp string
+	}{
+		{header.Resource{Href: "/style.css", As: "style"}, `</style.css>; rel=preload; as=style`},
+		{header.Resource{Href: "/f.woff2", As: "font", Type: "font/woff2", Crossorigin: "anonymous"},
+			`</f.woff2>; rel=preload; as=font; type="font/woff2"; crossorigin`},
+		{header.Resource{Href: "/a b>.js", As: "script", Crossorigin: "use-credentials"},
+			`</a%20b%3E.js>; rel=preload; as=script; crossorigin=use-credentials`},
+		{header.Resource{Href: "/x", Type: `a "q" \`}, `</x>; rel=preload; type="a \"q\" \\"`},
+	}
+	for _, tc := range testcases {
+		if got := tc.res.Link(); got != tc.exp {
+			t.Errorf("%v: expected %q, but got %q", tc.res, tc.exp, got)
+		}
+	}
+}
+
+func preloadResources(r *http.Request) []header.Resource {
+	if r.URL.Path != "/page" {
+		return nil
+	}
+	return []header.Resource{
+		{Href: "/style.css", As: "style"},
+		{Href: "/font.woff2", As: "font", Type: "font/woff2", Crossorigin: "anonymous"},
+	}
+}
+
+var expPreloadLinks = []string{
+	`</style.css>; rel=preload; as=style`,
+	`</font.woff2>; rel=preload; as=font; type="font/woff2"; crossorigin`,
+}
+
+func TestPreload(t *testing.T) {
+	cfg := header.PreloadConfig{Resources: preloadResources}
+	h := cfg.Build()(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "page")
+	}))
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/page", nil))
+	if got := rr.Header().Values("Link"); !slices.Equal(got, expPreloadLinks) {
+		t.Errorf("expected links %q, but got %q", expPreloadLinks, got)
+	}
+	if rr.Code != http.StatusOK || rr.Body.String() != "page" {
+		t.Errorf("unexpected response %d %q", rr.Code, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/other", nil))
+	if got := rr.Header().Values("Link"); len(got) != 0 {
+		t.Errorf("no links expected, but got %q", got)
+	}
+
+	// No early hints for HTTP/1.0
+	cfg.EarlyHints = true
+	h = cfg.Build()(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "page")
+	}))
+	rr = httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/page", nil)
+	r.Proto, r.ProtoMajor, r.ProtoMinor = "HTTP/1.0", 1, 0
+	h.ServeHTTP(rr, r)
+	if rr.Code != http.StatusOK || rr.Body.String() != "page" {
+		t.Errorf("unexpected response %d %q", rr.Code, rr.Body.String())
+	}
+
+	// A writer without support for informational responses only gets the
+	// links of the final response.
+	h = cfg.Build()(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.NotFound(w, nil)
+	}))
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/page", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("status 404 expected, but got %d", rr.Code)
+	}
+	if got := rr.Header().Values("Link"); !slices.Equal(got, expPreloadLinks) {
+		t.Errorf("expected links %q, but got %q", expPreloadLinks, got)
+	}
+}
+
+// statusWriter records the first status code, like a logging middleware.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sw *statusWriter) WriteHeader(code int) {
+	if sw.status == 0 {
+		sw.status = code
+	}
+	sw.ResponseWriter.WriteHeader(code)
+}
+
+func (sw *statusWriter) Unwrap() http.ResponseWriter { return sw.ResponseWriter }
+
+func TestPreloadEarlyHints(t *testing.T) {
+	cfg := header.PreloadConfig{Resources: preloadResources, EarlyHints: true}
+	h := cfg.Build()(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "page")
+	}))
+	statusCh := make(chan int, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sw := &statusWriter{ResponseWriter: w}
+		h.ServeHTTP(sw, r)
+		statusCh <- sw.status
+	}))
+	defer srv.Close()
+
+	var hintCodes []int
+	var hintLinks []string
+	trace := &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+			hintCodes = append(hintCodes, code)
+			hintLinks = header.Values("Link")
+			return nil
+		},
+	}
+	req, err := http.NewRequestWithContext(
+		httptrace.WithClientTrace(t.Context(), trace), http.MethodGet, srv.URL+"/page", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(hintCodes, []int{http.StatusEarlyHints}) {
+		t.Errorf("expected one early hints response, but got %v", hintCodes)
+	}
+	if !slices.Equal(hintLinks, expPreloadLinks) {
+		t.Errorf("expected early hint links %q, but got %q", expPreloadLinks, hintLinks)
+	}
+	if resp.StatusCode != http.StatusOK || string(body) != "page" {
+		t.Errorf("unexpected final response %d %q", resp.StatusCode, body)
+	}
+	if got := resp.Header.Values("Link"); !slices.Equal(got, expPreloadLinks) {
+		t.Errorf("expected final links %q, but got %q", expPreloadLinks, got)
+	}
+	if status := <-statusCh; status != 0 {
+		t.Errorf("wrapping writer must not see the early hints, but got %d", status)
+	}
+}
@@ -0,0 +1,181 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package header
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"t73f.de/r/webs/middleware"
+)
+
+// Profile is a named header configuration, e.g. for a specific environment.
+// It extends the configuration of its parent profile: its constants and
+// functions are added to those of the parent, replacing them by key.
+//
+// The sentinel [Unset] as a value of Constants, or a nil value of Functions,
+// removes the key inherited from the parent. If a profile defines a key both
+// as a constant and as a function, the constant is used, as in
+// [Config.Build].
+type Profile struct {
+	Parent string // Name of the parent profile, empty for none.
+	Config
+}
+
+// Unset is a sentinel value of [Profile.Constants] that removes an inherited
+// header key. It cannot be a valid header value.
+const Unset = "\x00unset"
+
+// Profiles maps profile names to their profiles.
+type Profiles map[string]Profile
+
+// Errors of resolving a profile.
+var (
+	ErrUnknownProfile = errors.New("unknown header profile")
+	ErrProfileCycle   = errors.New("cyclic header profile")
+)
+
+// BuildProfile resolves the named profile and builds its Functor, see
+// [Profiles.Resolve] and [Config.Build].
+func (ps Profiles) BuildProfile(name string) (middleware.Functor, error) {
+	cfg, err := ps.Resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.Build(), nil
+}
+
+// Resolve returns the configuration of the named profile, with all
+// inherited keys. Header keys are canonicalized. An error is returned, if
+// the profile or one of its ancestors is unknown, or if the chain of parents
+// contains a cycle.
+func (ps Profiles) Resolve(name string) (Config, error) {
+	entries, err := ps.resolve(name)
+	if err != nil {
+		return Config{}, err
+	}
+	var cfg Config
+	for key, e := range entries {
+		if e.fn != nil {
+			if cfg.Functions == nil {
+				cfg.Functions = map[string]Function{}
+			}
+			cfg.Functions[key] = e.fn
+		} else {
+			if cfg.Constants == nil {
+				cfg.Constants = map[string]string{}
+			}
+			cfg.Constants[key] = e.value
+		}
+	}
+	return cfg, nil
+}
+
+// profileEntry is the resolved value of one header key.
+type profileEntry struct {
+	value  string
+	fn     Function
+	origin string // name of the profile that defined the key
+}
+
+func (ps Profiles) resolve(name string) (map[string]profileEntry, error) {
+	var chain []string
+	for pname := name; pname != ""; pname = ps[pname].Parent {
+		if slices.Contains(chain, pname) {
+			return nil, fmt.Errorf("%w: %q", ErrProfileCycle, append(chain, pname))
+		}
+		if _, found := ps[pname]; !found {
+			if len(chain) == 0 {
+				return nil, fmt.Errorf("%w: %q", ErrUnknownProfile, pname)
+			}
+			return nil, fmt.Errorf("%w: %q, parent of %q", ErrUnknownProfile, pname, chain[len(chain)-1])
+		}
+		chain = append(chain, pname)
+	}
+
+	entries := map[string]profileEntry{}
+	for _, pname := range slices.Backward(chain) {
+		p := ps[pname]
+		for key, fn := range p.Functions {
+			key = http.CanonicalHeaderKey(key)
+			if fn == nil {
+				delete(entries, key)
+			} else {
+				entries[key] = profileEntry{fn: fn, origin: pname}
+			}
+		}
+		for key, value := range p.Constants {
+			key = http.CanonicalHeaderKey(key)
+			if value == Unset {
+				delete(entries, key)
+			} else {
+				entries[key] = profileEntry{value: value, origin: pname}
+			}
+		}
+	}
+	return entries, nil
+}
+
+// ProfileDiff lists the header keys that differ between two resolved
+// profiles. All lists are sorted.
+type ProfileDiff struct {
+	Added   []string // Keys only in the second profile.
+	Changed []string // Keys in both profiles, with different values.
+	Removed []string // Keys only in the first profile.
+}
+
+// IsEmpty returns true, if both profiles resolve to the same header keys and
+// values.
+func (d ProfileDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Changed) == 0 && len(d.Removed) == 0
+}
+
+// Diff compares the resolved profiles from and to. Since functions cannot be
+// compared, a function key is considered changed, if it was defined by
+// different profiles, or if a constant replaces it or vice versa.
+func (ps Profiles) Diff(from, to string) (ProfileDiff, error) {
+	fromEntries, err := ps.resolve(from)
+	if err != nil {
+		return ProfileDiff{}, err
+	}
+	toEntries, err := ps.resolve(to)
+	if err != nil {
+		return ProfileDiff{}, err
+	}
+	var diff ProfileDiff
+	for key, fe := range fromEntries {
+		te, found := toEntries[key]
+		switch {
+		case !found:
+			diff.Removed = append(diff.Removed, key)
+		case (fe.fn == nil) != (te.fn == nil):
+			diff.Changed = append(diff.Changed, key)
+		case fe.fn == nil && fe.value != te.value:
+			diff.Changed = append(diff.Changed, key)
+		case fe.fn != nil && fe.origin != te.origin:
+			diff.Changed = append(diff.Changed, key)
+		}
+	}
+	for key := range toEntries {
+		if _, found := fromEntries[key]; !found {
+			diff.Added = append(diff.Added, key)
+		}
+	}
+	slices.Sort(diff.Added)
+	slices.Sort(diff.Changed)
+	slices.Sort(diff.Removed)
+	return diff, nil
+}
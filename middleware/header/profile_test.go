@@ -0,0 +1,186 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package header_test
+
+import (
+	"errors"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"t73f.de/r/webs/middleware/header"
+)
+
+func makeProfiles() header.Profiles {
+	requestID := func(string, *http.Request) string { return "req-1" }
+	debug := func(_ string, r *http.Request) string { return r.URL.Path }
+	return header.Profiles{
+		"base": {Config: header.Config{
+			Constants: map[string]string{
+				"Server":                  "webs",
+				"X-Content-Type-Options":  "nosniff",
+				"content-security-policy": "default-src 'self'",
+			},
+			Functions: map[string]header.Function{"X-Request-Id": requestID},
+		}},
+		"prod": {Parent: "base", Config: header.Config{
+			Constants: map[string]string{
+				"Content-Security-Policy":   "default-src 'none'",
+				"Strict-Transport-Security": "max-age=63072000",
+			},
+		}},
+		"staging": {Parent: "base", Config: header.Config{
+			Constants: map[string]string{"Server": header.Unset},
+			Functions: map[string]header.Function{"X-Debug-Path": debug},
+		}},
+		"local": {Parent: "staging", Config: header.Config{
+			Constants: map[string]string{"X-Request-Id": "local"},
+			Functions: map[string]header.Function{"X-Debug-Path": nil},
+		}},
+	}
+}
+
+func TestProfileResolve(t *testing.T) {
+	ps := makeProfiles()
+	testcases := []struct {
+		name   string
+		consts map[string]string
+		funcs  []string
+	}{
+		{"base", map[string]string{
+			"Server": "webs", "X-Content-Type-Options": "nosniff", "Content-Security-Policy": "default-src 'self'",
+		}, []string{"X-Request-Id"}},
+		{"prod", map[string]string{
+			"Server": "webs", "X-Content-Type-Options": "nosniff", "Content-Security-Policy": "default-src 'none'",
+			"Strict-Transport-Security": "max-age=63072000",
+		}, []string{"X-Request-Id"}},
+		{"staging", map[string]string{
+			"X-Content-Type-Options": "nosniff", "Content-Security-Policy": "default-src 'self'",
+		}, []string{"X-Debug-Path", "X-Request-Id"}},
+		{"local", map[string]string{
+			"X-Content-Type-Options": "nosniff", "Content-Security-Policy": "default-src 'self'", "X-Request-Id": "local",
+		}, nil},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := ps.Resolve(tc.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !maps.Equal(cfg.Constants, tc.consts) {
+				t.Errorf("constants %v expected, but got %v", tc.consts, cfg.Constants)
+			}
+			if got := slices.Sorted(maps.Keys(cfg.Functions)); !slices.Equal(got, tc.funcs) {
+				t.Errorf("functions %v expected, but got %v", tc.funcs, got)
+			}
+		})
+	}
+}
+
+func TestBuildProfile(t *testing.T) {
+	fn, err := makeProfiles().BuildProfile("staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	fn(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/debug", nil))
+	exp := http.Header{
+		"X-Content-Type-Options":  {"nosniff"},
+		"Content-Security-Policy": {"default-src 'self'"},
+		"X-Request-Id":            {"req-1"},
+		"X-Debug-Path":            {"/debug"},
+	}
+	if got := rr.Header(); !maps.EqualFunc(got, exp, slices.Equal) {
+		t.Errorf("expected: %v, but got %v", exp, got)
+	}
+}
+
+func TestProfileErrors(t *testing.T) {
+	ps := makeProfiles()
+	ps["orphan"] = header.Profile{Parent: "missing"}
+	ps["a"] = header.Profile{Parent: "b"}
+	ps["b"] = header.Profile{Parent: "c"}
+	ps["c"] = header.Profile{Parent: "a"}
+	ps["self"] = header.Profile{Parent: "self"}
+	ps["below-cycle"] = header.Profile{Parent: "a"}
+
+	testcases := []struct {
+		name string
+		exp  error
+	}{
+		{"unknown", header.ErrUnknownProfile},
+		{"orphan", header.ErrUnknownProfile},
+		{"a", header.ErrProfileCycle},
+		{"self", header.ErrProfileCycle},
+		{"below-cycle", header.ErrProfileCycle},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ps.BuildProfile(tc.name); !errors.Is(err, tc.exp) {
+				t.Errorf("error %v expected, but got %v", tc.exp, err)
+			}
+			if _, err := ps.Diff("base", tc.name); !errors.Is(err, tc.exp) {
+				t.Errorf("diff: error %v expected, but got %v", tc.exp, err)
+			}
+		})
+	}
+}
+
+func TestProfileDiff(t *testing.T) {
+	ps := makeProfiles()
+	testcases := []struct {
+		from, to string
+		exp      header.ProfileDiff
+	}{
+		{"base", "base", header.ProfileDiff{}},
+		{"base", "prod", header.ProfileDiff{
+			Added:   []string{"Strict-Transport-Security"},
+			Changed: []string{"Content-Security-Policy"},
+		}},
+		{"prod", "staging", header.ProfileDiff{
+			Added:   []string{"X-Debug-Path"},
+			Changed: []string{"Content-Security-Policy"},
+			Removed: []string{"Server", "Strict-Transport-Security"},
+		}},
+		{"staging", "local", header.ProfileDiff{
+			Changed: []string{"X-Request-Id"},
+			Removed: []string{"X-Debug-Path"},
+		}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.from+"-"+tc.to, func(t *testing.T) {
+			got, err := ps.Diff(tc.from, tc.to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got.Added, tc.exp.Added) || !slices.Equal(got.Changed, tc.exp.Changed) || !slices.Equal(got.Removed, tc.exp.Removed) {
+				t.Errorf("expected %+v, but got %+v", tc.exp, got)
+			}
+			if got.IsEmpty() != tc.exp.IsEmpty() {
+				t.Errorf("IsEmpty %v expected", tc.exp.IsEmpty())
+			}
+		})
+	}
+
+	// A function that is redefined by a profile is changed, even if it is
+	// the same function.
+	ps["redefined"] = header.Profile{Parent: "base", Config: header.Config{
+		Functions: map[string]header.Function{"X-Request-Id": ps["base"].Functions["X-Request-Id"]},
+	}}
+	if got, _ := ps.Diff("base", "redefined"); !slices.Equal(got.Changed, []string{"X-Request-Id"}) {
+		t.Errorf("redefined function must be changed, got %+v", got)
+	}
+}
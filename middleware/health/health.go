@@ -0,0 +1,241 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+// Package health provides handlers for liveness and readiness probes, e.g.
+// to be served at "/healthz" and "/readyz".
+//
+// Components register named checks of their dependencies at a [Registry].
+// The readiness handler runs all checks, the liveness handler only reports
+// whether the process is shutting down.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Default values of a [Registry].
+const (
+	DefaultTimeout       = time.Second
+	DefaultCacheDuration = time.Second
+)
+
+// Status values of a [Report] and of a [CheckResult].
+const (
+	StatusOK       = "ok"       // All checks succeeded.
+	StatusDegraded = "degraded" // Only non-critical checks failed.
+	StatusFail     = "fail"     // A critical check failed.
+	StatusDraining = "draining" // The process is shutting down.
+)
+
+// Check checks a dependency, e.g. a database connection. It returns an error,
+// if the dependency is not usable. It should return, if the context is done.
+type Check func(context.Context) error
+
+// Pinger is implemented by dependencies that can be pinged, e.g. *sql.DB.
+type Pinger interface {
+	PingContext(context.Context) error
+}
+
+// Registry stores the checks to determine the readiness of the process. Its
+// zero value is ready to use. The configuration fields must not be changed
+// after the first handler was built.
+type Registry struct {
+	// Timeout is the maximum duration of a single check. A check that needs
+	// more time has failed. If not positive, DefaultTimeout is used.
+	Timeout time.Duration
+
+	// CacheDuration is the period, for which the result of all checks is
+	// reused, to protect the dependencies from too many probes. If zero,
+	// DefaultCacheDuration is used. If negative, no result is reused.
+	CacheDuration time.Duration
+
+	mx       sync.Mutex // Serializes the checks and protects the fields below.
+	checks   []namedCheck
+	cached   *Report
+	cachedAt time.Time
+
+	draining atomic.Bool
+}
+
+type namedCheck struct {
+	name     string
+	critical bool
+	check    Check
+}
+
+// Register a named check. If a critical check fails, the process is not
+// ready. If a non-critical check fails, the process is ready, but degraded.
+func (reg *Registry) Register(name string, critical bool, check Check) {
+	reg.mx.Lock()
+	reg.checks = append(reg.checks, namedCheck{name: name, critical: critical, check: check})
+	reg.cached = nil
+	reg.mx.Unlock()
+}
+
+// RegisterPinger registers a check that pings the given dependency, e.g. an
+// *sql.DB.
+func (reg *Registry) RegisterPinger(name string, critical bool, p Pinger) {
+	reg.Register(name, critical, p.PingContext)
+}
+
+// Drain signals that the process is shutting down. Afterwards, both the
+// liveness and the readiness handler respond with status code 503 (Service
+// Unavailable), so that no new requests are routed to the process.
+//
+// It may be registered with [http.Server.RegisterOnShutdown].
+func (reg *Registry) Drain() { reg.draining.Store(true) }
+
+// Draining returns true, if the process is shutting down, see [Registry.Drain].
+func (reg *Registry) Draining() bool { return reg.draining.Load() }
+
+// Report is the result of all checks.
+type Report struct {
+	Status string        `json:"status"`
+	Checks []CheckResult `json:"checks,omitempty"`
+}
+
+// CheckResult is the result of a single check.
+type CheckResult struct {
+	Name     string        `json:"name"`
+	Critical bool          `json:"critical"`
+	Status   string        `json:"status"`
+	Error    string        `json:"error,omitempty"`
+	Latency  time.Duration `json:"-"`
+}
+
+// MarshalJSON encodes the latency in milliseconds.
+func (cr CheckResult) MarshalJSON() ([]byte, error) {
+	type plainResult CheckResult
+	return json.Marshal(struct {
+		plainResult
+		LatencyMS float64 `json:"latency_ms"`
+	}{plainResult(cr), float64(cr.Latency) / float64(time.Millisecond)})
+}
+
+// Ready returns true, if the process is ready.
+func (rep *Report) Ready() bool {
+	return rep.Status == StatusOK || rep.Status == StatusDegraded
+}
+
+// Run all checks concurrently, or return the cached result. If the process
+// is shutting down, no checks are run.
+func (reg *Registry) Run(ctx context.Context) *Report {
+	if reg.Draining() {
+		return &Report{Status: StatusDraining}
+	}
+	reg.mx.Lock()
+	defer reg.mx.Unlock()
+	cacheDuration := reg.CacheDuration
+	if cacheDuration == 0 {
+		cacheDuration = DefaultCacheDuration
+	}
+	if reg.cached != nil && cacheDuration > 0 && time.Since(reg.cachedAt) < cacheDuration {
+		return reg.cached
+	}
+
+	timeout := reg.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	// The report may be shared with other requests. It must not depend on
+	// the cancellation of the current one.
+	ctx = context.WithoutCancel(ctx)
+	results := make([]CheckResult, len(reg.checks))
+	var wg sync.WaitGroup
+	for i, nc := range reg.checks {
+		wg.Go(func() { results[i] = runCheck(ctx, nc, timeout) })
+	}
+	wg.Wait()
+
+	rep := &Report{Status: StatusOK, Checks: results}
+	for _, res := range results {
+		if res.Status == StatusOK {
+			continue
+		}
+		if res.Critical {
+			rep.Status = StatusFail
+			break
+		}
+		rep.Status = StatusDegraded
+	}
+	reg.cached, reg.cachedAt = rep, time.Now()
+	return rep
+}
+
+// runCheck runs a single check. It returns after the timeout, even if the
+// check ignores the cancellation of its context.
+func runCheck(ctx context.Context, nc namedCheck, timeout time.Duration) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	errCh := make(chan error, 1)
+	start := time.Now()
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				errCh <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		errCh <- nc.check(ctx)
+	}()
+	var err error
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		err = fmt.Errorf("timeout after %v", timeout)
+	}
+	res := CheckResult{Name: nc.name, Critical: nc.critical, Status: StatusOK, Latency: time.Since(start)}
+	if err != nil {
+		res.Status, res.Error = StatusFail, err.Error()
+	}
+	return res
+}
+
+// LiveHandler returns a handler for liveness probes. It responds with status
+// code 200 (OK), unless the process is shutting down.
+func (reg *Registry) LiveHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		rep := &Report{Status: StatusOK}
+		if reg.Draining() {
+			rep.Status = StatusDraining
+		}
+		writeReport(w, rep)
+	})
+}
+
+// ReadyHandler returns a handler for readiness probes. It runs all checks,
+// see [Registry.Run], and responds with status code 200 (OK), if the process
+// is ready, or with 503 (Service Unavailable) otherwise. The JSON body lists
+// the status and the latency of each check.
+func (reg *Registry) ReadyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, reg.Run(r.Context()))
+	})
+}
+
+func writeReport(w http.ResponseWriter, rep *Report) {
+	h := w.Header()
+	h.Set("Content-Type", "application/json")
+	h.Set("Cache-Control", "no-store")
+	code := http.StatusOK
+	if !rep.Ready() {
+		code = http.StatusServiceUnavailable
+	}
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(rep)
+}
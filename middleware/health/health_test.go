@@ -0,0 +1,208 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"t73f.de/r/webs/middleware/health"
+)
+
+type jsonReport struct {
+	Status string `json:"status"`
+	Checks []struct {
+		Name      string  `json:"name"`
+		Critical  bool    `json:"critical"`
+		Status    string  `json:"status"`
+		Error     string  `json:"error"`
+		LatencyMS float64 `json:"latency_ms"`
+	} `json:"checks"`
+}
+
+func probe(t *testing.T, h http.Handler) (int, jsonReport) {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if got := rr.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("JSON content type expected, got %q", got)
+	}
+	var rep jsonReport
+	if err := json.Unmarshal(rr.Body.Bytes(), &rep); err != nil {
+		t.Fatalf("invalid JSON %q: %v", rr.Body.String(), err)
+	}
+	return rr.Code, rep
+}
+
+func okCheck(context.Context) error { return nil }
+
+var errDown = errors.New("down")
+
+func failCheck(context.Context) error { return errDown }
+
+func TestReadyCriticality(t *testing.T) {
+	testcases := []struct {
+		name     string
+		critical bool
+		check    health.Check
+		code     int
+		status   string
+	}{
+		{"ok", true, okCheck, http.StatusOK, health.StatusOK},
+		{"degraded", false, failCheck, http.StatusOK, health.StatusDegraded},
+		{"fail", true, failCheck, http.StatusServiceUnavailable, health.StatusFail},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var reg health.Registry
+			reg.Register("db", true, okCheck)
+			reg.Register("dep", tc.critical, tc.check)
+			code, rep := probe(t, reg.ReadyHandler())
+			if code != tc.code || rep.Status != tc.status {
+				t.Errorf("%d/%q expected, got %d/%q", tc.code, tc.status, code, rep.Status)
+			}
+			if len(rep.Checks) != 2 {
+				t.Fatalf("two checks expected, got %v", rep.Checks)
+			}
+			if chk := rep.Checks[1]; chk.Name != "dep" || chk.Critical != tc.critical {
+				t.Errorf("wrong check: %v", chk)
+			} else if tc.name != "ok" {
+				if chk.Status != health.StatusFail || chk.Error != errDown.Error() {
+					t.Errorf("failed check expected, got %v", chk)
+				}
+			}
+		})
+	}
+}
+
+func TestReadyTimeout(t *testing.T) {
+	reg := health.Registry{Timeout: 20 * time.Millisecond}
+	block := make(chan struct{})
+	defer close(block)
+	reg.Register("ignoring", true, func(context.Context) error { <-block; return nil })
+	reg.Register("respecting", false, func(ctx context.Context) error { <-ctx.Done(); return ctx.Err() })
+	reg.Register("fast", true, okCheck)
+
+	start := time.Now()
+	code, rep := probe(t, reg.ReadyHandler())
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("checks must run concurrently with timeout, but took %v", elapsed)
+	}
+	if code != http.StatusServiceUnavailable || rep.Status != health.StatusFail {
+		t.Errorf("failure expected, got %d/%q", code, rep.Status)
+	}
+	for _, chk := range rep.Checks {
+		exp := health.StatusFail
+		if chk.Name == "fast" {
+			exp = health.StatusOK
+		}
+		if chk.Status != exp {
+			t.Errorf("check %q: %q expected, got %q", chk.Name, exp, chk.Status)
+		}
+		if chk.Name == "ignoring" && chk.LatencyMS < 20 {
+			t.Errorf("latency must be at least the timeout, got %vms", chk.LatencyMS)
+		}
+	}
+}
+
+func TestReadyCache(t *testing.T) {
+	var calls atomic.Int32
+	check := func(context.Context) error { calls.Add(1); return nil }
+
+	reg := health.Registry{CacheDuration: time.Hour}
+	reg.Register("counted", true, check)
+	h := reg.ReadyHandler()
+	for range 10 {
+		probe(t, h)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("check must be cached, but was called %d times", got)
+	}
+
+	reg.Register("other", false, okCheck)
+	if _, rep := probe(t, h); len(rep.Checks) != 2 || calls.Load() != 2 {
+		t.Errorf("registration must invalidate cache, got %v after %d calls", rep.Checks, calls.Load())
+	}
+
+	calls.Store(0)
+	noCache := health.Registry{CacheDuration: -1}
+	noCache.Register("counted", true, check)
+	h = noCache.ReadyHandler()
+	for range 3 {
+		probe(t, h)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("check must not be cached, but was called %d times", got)
+	}
+}
+
+func TestDrain(t *testing.T) {
+	var calls atomic.Int32
+	var reg health.Registry
+	reg.Register("counted", true, func(context.Context) error { calls.Add(1); return nil })
+
+	if code, rep := probe(t, reg.LiveHandler()); code != http.StatusOK || rep.Status != health.StatusOK {
+		t.Errorf("live expected, got %d/%q", code, rep.Status)
+	}
+	if code, _ := probe(t, reg.ReadyHandler()); code != http.StatusOK {
+		t.Errorf("ready expected, got %d", code)
+	}
+
+	srv := httptest.NewServer(reg.LiveHandler())
+	srv.Config.RegisterOnShutdown(reg.Drain)
+	if err := srv.Config.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+	// The hook of RegisterOnShutdown runs in its own goroutine.
+	for i := 0; i < 100 && !reg.Draining(); i++ {
+		time.Sleep(time.Millisecond)
+	}
+	if !reg.Draining() {
+		t.Fatal("registry must drain on server shutdown")
+	}
+
+	calls.Store(0)
+	for _, h := range []http.Handler{reg.LiveHandler(), reg.ReadyHandler()} {
+		if code, rep := probe(t, h); code != http.StatusServiceUnavailable || rep.Status != health.StatusDraining {
+			t.Errorf("draining expected, got %d/%q", code, rep.Status)
+		}
+	}
+	if got := calls.Load(); got != 0 {
+		t.Errorf("no checks expected while draining, got %d", got)
+	}
+}
+
+type pinger struct{ err error }
+
+func (p *pinger) PingContext(context.Context) error { return p.err }
+
+func TestRegisterPinger(t *testing.T) {
+	reg := health.Registry{CacheDuration: -1}
+	p := &pinger{}
+	reg.RegisterPinger("db", true, p)
+	if code, _ := probe(t, reg.ReadyHandler()); code != http.StatusOK {
+		t.Errorf("ready expected, got %d", code)
+	}
+	p.err = errDown
+	if code, rep := probe(t, reg.ReadyHandler()); code != http.StatusServiceUnavailable || rep.Checks[0].Error != errDown.Error() {
+		t.Errorf("failure expected, got %d/%v", code, rep.Checks)
+	}
+}
@@ -0,0 +1,28 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+// Package integration contains tests and examples that combine several
+// middleware packages.
+//
+// The recommended order of the middleware functors, from the outermost to the
+// innermost, is:
+//
+//  1. reqid: create a request identifier and store it in the context.
+//  2. logging.ReqConfig: log the request, including its identifier.
+//  3. logging.RespConfig: log the response, after it was possibly changed by
+//     the status middleware.
+//  4. status: replace error responses by application defined ones.
+//
+// Handlers should log with a logger created by [reqid.Config.WithLogger], so
+// that each log record carries the request identifier.
+package integration
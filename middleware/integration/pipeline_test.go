@@ -0,0 +1,145 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package integration_test
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"t73f.de/r/webs/middleware"
+	"t73f.de/r/webs/middleware/logging"
+	"t73f.de/r/webs/middleware/middlewaretest"
+	"t73f.de/r/webs/middleware/reqid"
+	"t73f.de/r/webs/middleware/status"
+)
+
+// buildPipeline assembles the recommended middleware chain around a small
+// application handler. All log records are written to the given logger.
+func buildPipeline(logger *slog.Logger) http.Handler {
+	reqidCfg := reqid.Config{WithContext: true, WithResponse: true}
+	appLogger := reqidCfg.WithLogger(logger)
+
+	reqLog := logging.ReqConfig{Logger: logger, WithRequestID: true}
+	respLog := logging.RespConfig{Logger: logger, WithRequestID: true}
+	statusCfg := status.Config{
+		HandlerMap: status.HandlerMap{
+			http.StatusNotFound: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				appLogger.InfoContext(r.Context(), "not found", "path", r.URL.Path)
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte("custom not found"))
+			}),
+			http.StatusInternalServerError: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				appLogger.InfoContext(r.Context(), "internal error")
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = w.Write([]byte("custom internal error"))
+			}),
+		},
+		NoClearMap: map[int]bool{
+			http.StatusNotFound:            true,
+			http.StatusInternalServerError: true,
+		},
+	}
+	recoverer := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				if val := recover(); val != nil {
+					appLogger.ErrorContext(r.Context(), "panic", "value", val)
+					w.WriteHeader(http.StatusInternalServerError)
+				}
+			}()
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /ok", func(w http.ResponseWriter, r *http.Request) {
+		appLogger.DebugContext(r.Context(), "handling ok")
+		_, _ = w.Write([]byte("ok"))
+	})
+	mux.HandleFunc("GET /panic", func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	})
+
+	chain := middleware.NewChain(
+		reqidCfg.Build(), reqLog.Build(), respLog.Build(), statusCfg.Build(), recoverer)
+	return middleware.Apply(chain, mux)
+}
+
+func TestFullPipeline(t *testing.T) {
+	logger, recs := middlewaretest.CaptureLogs(t)
+	h := buildPipeline(logger)
+
+	testcases := []struct {
+		name    string
+		path    string
+		code    int
+		body    string
+		numRecs int
+		errRecs int
+	}{
+		{"success", "/ok", http.StatusOK, "ok", 3, 0},
+		{"not-found", "/missing", http.StatusNotFound, "custom not found", 3, 0},
+		{"panic", "/panic", http.StatusInternalServerError, "custom internal error", 4, 1},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			recs.Reset()
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			if w.Code != tc.code {
+				t.Errorf("expected status %d, but got %d", tc.code, w.Code)
+			}
+			if got := w.Body.String(); got != tc.body {
+				t.Errorf("expected body %q, but got %q", tc.body, got)
+			}
+
+			reqID := w.Header().Get(reqid.DefaultHeaderKey)
+			if reqID == "" {
+				t.Fatal("no request id in response")
+			}
+			all := recs.All()
+			if len(all) != tc.numRecs {
+				t.Errorf("expected %d log records, but got %d", tc.numRecs, len(all))
+			}
+			for _, rec := range all {
+				if got := rec.RequestID(); got != reqID {
+					t.Errorf("record %q: expected request id %q, but got %q", rec.Message, reqID, got)
+				}
+			}
+			if got := recs.ByRequestID(reqID); len(got) != len(all) {
+				t.Errorf("expected %d records by request id, but got %d", len(all), len(got))
+			}
+			if got := recs.WithLevel(slog.LevelError); len(got) != tc.errRecs {
+				t.Errorf("expected %d error records, but got %d", tc.errRecs, len(got))
+			}
+		})
+	}
+}
+
+func Example() {
+	h := buildPipeline(slog.New(slog.DiscardHandler))
+	for _, path := range []string{"/ok", "/missing", "/panic"} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		fmt.Println(path, w.Code, w.Body.String())
+	}
+	// Output:
+	// /ok 200 ok
+	// /missing 404 custom not found
+	// /panic 500 custom internal error
+}
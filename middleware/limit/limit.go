@@ -0,0 +1,202 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+// Package limit provides a middleware functor that limits the number of
+// concurrently served requests. Excess requests are queued for a while, and
+// rejected if the queue is full.
+package limit
+
+import (
+	"container/list"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"t73f.de/r/webs/middleware"
+)
+
+// DefaultRetryAfter is the default value for [Config.RetryAfter].
+const DefaultRetryAfter = time.Second
+
+// Stats contains gauges and counters of a limiter, e.g. to be exported as
+// metrics. Its zero value is ready to use. It may be shared by several
+// limiters to get aggregated values.
+type Stats struct {
+	active   atomic.Int64
+	queued   atomic.Int64
+	rejected atomic.Uint64
+}
+
+// Active returns the number of requests that are currently served.
+func (st *Stats) Active() int { return int(st.active.Load()) }
+
+// Queued returns the number of requests that currently wait to be served.
+func (st *Stats) Queued() int { return int(st.queued.Load()) }
+
+// Rejected returns the number of requests that were rejected, because the
+// queue was full, or because they waited too long.
+func (st *Stats) Rejected() uint64 { return st.rejected.Load() }
+
+// Config stores all configuration data to build a limiting functor.
+type Config struct {
+	// MaxConcurrent is the maximum number of requests that are served
+	// concurrently. If not positive, no limit is applied.
+	MaxConcurrent int
+
+	// MaxQueue is the maximum number of requests that wait to be served.
+	// Requests are admitted in the order of their arrival.
+	MaxQueue int
+
+	// QueueTimeout is the maximum duration a request waits in the queue. If
+	// not positive, a request waits until its context is done.
+	QueueTimeout time.Duration
+
+	// RetryAfter is the value of the Retry-After header of a rejected
+	// request. It is rounded up to full seconds. If not positive,
+	// DefaultRetryAfter is used.
+	RetryAfter time.Duration
+
+	// OnRejected serves rejected requests. If nil, the status code 503
+	// (Service Unavailable) is returned, together with a Retry-After header.
+	OnRejected http.Handler
+
+	// Stats is updated by the limiter, if not nil.
+	Stats *Stats
+}
+
+// Build the Functor from the configuration.
+//
+// A request that waits in the queue is removed from it, if its context is
+// done, e.g. because the client disconnected. Such a request is not served
+// and does not occupy a slot.
+func (c *Config) Build() middleware.Functor {
+	if c.MaxConcurrent <= 0 {
+		return middleware.NilFunctor
+	}
+	lim := &limiter{
+		maxConcurrent: c.MaxConcurrent,
+		maxQueue:      max(c.MaxQueue, 0),
+		stats:         c.Stats,
+	}
+	if lim.stats == nil {
+		lim.stats = &Stats{}
+	}
+	queueTimeout := c.QueueTimeout
+	onRejected := c.OnRejected
+	if onRejected == nil {
+		retryAfter := c.RetryAfter
+		if retryAfter <= 0 {
+			retryAfter = DefaultRetryAfter
+		}
+		onRejected = unavailableHandler(retryAfter)
+	}
+
+	return middleware.Declare(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !lim.acquire(r, queueTimeout) {
+				lim.stats.rejected.Add(1)
+				if r.Context().Err() == nil {
+					onRejected.ServeHTTP(w, r)
+				}
+				return
+			}
+			defer lim.release()
+			next.ServeHTTP(w, r)
+		})
+	}, "limit")
+}
+
+// unavailableHandler returns a handler that rejects a request with status
+// code 503 and a Retry-After header.
+func unavailableHandler(retryAfter time.Duration) http.Handler {
+	seconds := strconv.Itoa(int(math.Ceil(retryAfter.Seconds())))
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", seconds)
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+	})
+}
+
+// limiter is a semaphore with a FIFO queue of waiting requests. A released
+// slot is handed over directly to the first waiting request, so that later
+// requests cannot overtake it.
+type limiter struct {
+	maxConcurrent int
+	maxQueue      int
+	stats         *Stats
+
+	mx      sync.Mutex
+	active  int
+	waiting list.List // of chan struct{}
+}
+
+// acquire a slot for the request. It returns false, if the request must be
+// rejected.
+func (lim *limiter) acquire(r *http.Request, queueTimeout time.Duration) bool {
+	lim.mx.Lock()
+	if lim.active < lim.maxConcurrent && lim.waiting.Len() == 0 {
+		lim.active++
+		lim.mx.Unlock()
+		lim.stats.active.Add(1)
+		return true
+	}
+	if lim.waiting.Len() >= lim.maxQueue {
+		lim.mx.Unlock()
+		return false
+	}
+	ready := make(chan struct{})
+	elem := lim.waiting.PushBack(ready)
+	lim.mx.Unlock()
+	lim.stats.queued.Add(1)
+	defer lim.stats.queued.Add(-1)
+
+	var timeout <-chan time.Time
+	if queueTimeout > 0 {
+		timer := time.NewTimer(queueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case <-ready:
+		return true
+	case <-r.Context().Done():
+	case <-timeout:
+	}
+
+	lim.mx.Lock()
+	select {
+	case <-ready:
+		// The slot was handed over, while the request gave up. Pass it on.
+		lim.mx.Unlock()
+		lim.release()
+	default:
+		lim.waiting.Remove(elem)
+		lim.mx.Unlock()
+	}
+	return false
+}
+
+// release the slot of a request, by handing it over to the first waiting
+// request, or by freeing it.
+func (lim *limiter) release() {
+	lim.mx.Lock()
+	defer lim.mx.Unlock()
+	if front := lim.waiting.Front(); front != nil {
+		close(lim.waiting.Remove(front).(chan struct{}))
+		return
+	}
+	lim.active--
+	lim.stats.active.Add(-1)
+}
@@ -0,0 +1,242 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package limit_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"t73f.de/r/webs/middleware/limit"
+)
+
+// waitFor polls the condition, until it is true.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// blockingHandler records the order of served requests, by their path, and
+// blocks until the gate is closed.
+type blockingHandler struct {
+	gate  chan struct{}
+	mx    sync.Mutex
+	order []string
+}
+
+func newBlockingHandler() *blockingHandler { return &blockingHandler{gate: make(chan struct{})} }
+
+func (bh *blockingHandler) ServeHTTP(_ http.ResponseWriter, r *http.Request) {
+	bh.mx.Lock()
+	bh.order = append(bh.order, r.URL.Path)
+	bh.mx.Unlock()
+	<-bh.gate
+}
+
+func (bh *blockingHandler) served() []string {
+	bh.mx.Lock()
+	defer bh.mx.Unlock()
+	return slices.Clone(bh.order)
+}
+
+// start serves a request in its own goroutine. The returned channel
+// delivers the response.
+func start(h http.Handler, r *http.Request) <-chan *httptest.ResponseRecorder {
+	ch := make(chan *httptest.ResponseRecorder, 1)
+	go func() {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		ch <- w
+	}()
+	return ch
+}
+
+func TestLimitNil(t *testing.T) {
+	cfg := limit.Config{}
+	w := httptest.NewRecorder()
+	cfg.Build()(http.NotFoundHandler()).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("unlimited handler expected, got status %d", w.Code)
+	}
+}
+
+func TestLimitCeiling(t *testing.T) {
+	const maxConcurrent, numRequests = 4, 64
+	var current, highest atomic.Int32
+	slow := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		n := current.Add(1)
+		for {
+			old := highest.Load()
+			if n <= old || highest.CompareAndSwap(old, n) {
+				break
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+		current.Add(-1)
+	})
+	var stats limit.Stats
+	cfg := limit.Config{MaxConcurrent: maxConcurrent, MaxQueue: numRequests, Stats: &stats}
+	h := cfg.Build()(slow)
+
+	var wg sync.WaitGroup
+	for range numRequests {
+		wg.Go(func() {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			if w.Code != http.StatusOK {
+				t.Errorf("status 200 expected, got %d", w.Code)
+			}
+		})
+	}
+	wg.Wait()
+	if got := highest.Load(); got > maxConcurrent {
+		t.Errorf("at most %d concurrent requests expected, got %d", maxConcurrent, got)
+	}
+	if active, queued, rejected := stats.Active(), stats.Queued(), stats.Rejected(); active != 0 || queued != 0 || rejected != 0 {
+		t.Errorf("empty stats expected, got active=%d, queued=%d, rejected=%d", active, queued, rejected)
+	}
+}
+
+func TestLimitQueueOrderAndRejection(t *testing.T) {
+	bh := newBlockingHandler()
+	var stats limit.Stats
+	cfg := limit.Config{MaxConcurrent: 1, MaxQueue: 3, RetryAfter: 1500 * time.Millisecond, Stats: &stats}
+	h := cfg.Build()(bh)
+
+	var responses []<-chan *httptest.ResponseRecorder
+	responses = append(responses, start(h, httptest.NewRequest(http.MethodGet, "/0", nil)))
+	waitFor(t, "first request", func() bool { return stats.Active() == 1 })
+	for i, path := range []string{"/1", "/2", "/3"} {
+		responses = append(responses, start(h, httptest.NewRequest(http.MethodGet, path, nil)))
+		waitFor(t, "queued request "+path, func() bool { return stats.Queued() == i+1 })
+	}
+
+	// Queue is full: reject.
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/4", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status 503 expected, got %d", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After: 2 expected, got %q", got)
+	}
+	if got := stats.Rejected(); got != 1 {
+		t.Errorf("one rejected request expected, got %d", got)
+	}
+
+	close(bh.gate)
+	for _, ch := range responses {
+		if w := <-ch; w.Code != http.StatusOK {
+			t.Errorf("status 200 expected, got %d", w.Code)
+		}
+	}
+	if got, exp := bh.served(), []string{"/0", "/1", "/2", "/3"}; !slices.Equal(got, exp) {
+		t.Errorf("FIFO order %v expected, got %v", exp, got)
+	}
+}
+
+func TestLimitQueueTimeout(t *testing.T) {
+	bh := newBlockingHandler()
+	var stats limit.Stats
+	rejected := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	cfg := limit.Config{
+		MaxConcurrent: 1,
+		MaxQueue:      1,
+		QueueTimeout:  10 * time.Millisecond,
+		OnRejected:    rejected,
+		Stats:         &stats,
+	}
+	h := cfg.Build()(bh)
+
+	first := start(h, httptest.NewRequest(http.MethodGet, "/0", nil))
+	waitFor(t, "first request", func() bool { return stats.Active() == 1 })
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/1", nil))
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("custom rejection expected, got %d", w.Code)
+	}
+	if queued, rejected := stats.Queued(), stats.Rejected(); queued != 0 || rejected != 1 {
+		t.Errorf("queued=0, rejected=1 expected, got %d, %d", queued, rejected)
+	}
+	close(bh.gate)
+	<-first
+	if got := bh.served(); !slices.Equal(got, []string{"/0"}) {
+		t.Errorf("only first request must be served, got %v", got)
+	}
+}
+
+func TestLimitClientDisconnect(t *testing.T) {
+	bh := newBlockingHandler()
+	var stats limit.Stats
+	cfg := limit.Config{MaxConcurrent: 1, MaxQueue: 2, Stats: &stats}
+	h := cfg.Build()(bh)
+
+	first := start(h, httptest.NewRequest(http.MethodGet, "/0", nil))
+	waitFor(t, "first request", func() bool { return stats.Active() == 1 })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	gone := start(h, httptest.NewRequestWithContext(ctx, http.MethodGet, "/gone", nil))
+	waitFor(t, "queued request", func() bool { return stats.Queued() == 1 })
+	cancel()
+	<-gone
+	waitFor(t, "empty queue", func() bool { return stats.Queued() == 0 })
+
+	close(bh.gate)
+	<-first
+	waitFor(t, "free slot", func() bool { return stats.Active() == 0 })
+
+	// The slot of the disconnected client was not leaked.
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/1", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("status 200 expected, got %d", w.Code)
+	}
+	if got, exp := bh.served(), []string{"/0", "/1"}; !slices.Equal(got, exp) {
+		t.Errorf("%v expected, got %v", exp, got)
+	}
+}
+
+func TestLimitStress(t *testing.T) {
+	// Many clients give up while queued. Afterwards, no slot must be lost,
+	// since the gauges mirror the state of the limiter.
+	var stats limit.Stats
+	cfg := limit.Config{MaxConcurrent: 2, MaxQueue: 8, QueueTimeout: time.Millisecond, Stats: &stats}
+	h := cfg.Build()(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		time.Sleep(500 * time.Microsecond)
+	}))
+	var wg sync.WaitGroup
+	for range 200 {
+		wg.Go(func() {
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		})
+	}
+	wg.Wait()
+	if active, queued := stats.Active(), stats.Queued(); active != 0 || queued != 0 {
+		t.Errorf("no active or queued requests expected, got %d, %d", active, queued)
+	}
+}
@@ -16,17 +16,34 @@
 package logging
 
 import (
+	"context"
+	"errors"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"sync"
+	"time"
 
 	"t73f.de/r/webs/ip"
 	"t73f.de/r/webs/middleware"
+	"t73f.de/r/webs/middleware/contextinfo"
 	"t73f.de/r/webs/middleware/reqid"
+	"t73f.de/r/webs/middleware/status"
 )
 
 // DefaultRequestIDKey is the default name of the request id log attribute.
 const DefaultRequestIDKey = "id"
 
+// ContextInfoKey is the name of the log attribute group that contains the
+// snapshot of package contextinfo.
+const ContextInfoKey = "context"
+
+// contextInfoAttr returns the snapshot of the context as a group attribute.
+// An empty group is omitted by all log handlers.
+func contextInfoAttr(ctx context.Context) slog.Attr {
+	return slog.Attr{Key: ContextInfoKey, Value: slog.GroupValue(contextinfo.Attrs(ctx)...)}
+}
+
 // ReqConfig stores all configuration data to build a request logger.
 type ReqConfig struct {
 	Logger        *slog.Logger
@@ -35,6 +52,10 @@ type ReqConfig struct {
 	WithRequestID bool
 	WithRemote    bool
 	WithHeaders   bool
+
+	// WithContextInfo logs the snapshot of selected context values, see
+	// package contextinfo, as the attribute group "context".
+	WithContextInfo bool
 }
 
 // Build the Functor from the configuration.
@@ -49,7 +70,8 @@ func (c *ReqConfig) Build() middleware.Functor {
 		msg = "REQ"
 	}
 	withRequestID, withRemote, withHeaders := c.WithRequestID, c.WithRemote, c.WithHeaders
-	return func(next http.Handler) http.Handler {
+	withContextInfo := c.WithContextInfo
+	return middleware.Declare(func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			var requestIDAttr, remoteAttr, headerAttr slog.Attr
 			if withRequestID {
@@ -64,13 +86,17 @@ func (c *ReqConfig) Build() middleware.Functor {
 			if withHeaders {
 				headerAttr = slog.Any("header", r.Header)
 			}
+			var contextAttr slog.Attr
+			if withContextInfo {
+				contextAttr = contextInfoAttr(r.Context())
+			}
 
 			logger.LogAttrs(r.Context(), level, msg, requestIDAttr,
 				slog.String("method", r.Method), slog.Any("url", r.URL),
-				remoteAttr, headerAttr)
+				remoteAttr, headerAttr, contextAttr)
 			next.ServeHTTP(w, r)
 		})
-	}
+	}, "logging-request", middleware.After(reqid.Capability))
 }
 
 // RespConfig stores all confguration data to build a response logger.
@@ -80,9 +106,34 @@ type RespConfig struct {
 	Message       string
 	WithRequestID bool
 	WithHeaders   bool
+
+	// WithContextInfo logs the snapshot of selected context values, see
+	// package contextinfo, as the attribute group "context". The snapshot
+	// is taken after the next handler returns.
+	WithContextInfo bool
+
+	// SlowThreshold, if positive, lets responses that took longer be logged
+	// with SlowLevel and the attributes "slow" and "duration".
+	SlowThreshold time.Duration
+
+	// SlowLevel is the log level of slow responses. If it is not above
+	// Level, the next higher standard level is used, e.g. slog.LevelWarn
+	// for slog.LevelInfo.
+	SlowLevel slog.Level
 }
 
 // Build the Functor from the configuration.
+//
+// If the response was replaced by the status middleware, the attributes
+// "intercepted" and "original" (status code) are logged too, so that it can
+// be distinguished from a response of the handler itself.
+//
+// If writing the response failed, the first error is logged as attribute
+// "write_error", together with the attribute "intended", the number of bytes
+// the handler tried to write, or the announced "Content-Length", if it is
+// larger. If the request context was canceled before the handler returned,
+// e.g. because the client closed the connection, the attribute
+// "client_gone" is logged. Handlers may use [ClientGone] to stop early.
 func (c *RespConfig) Build() middleware.Functor {
 	logger := c.Logger
 	if logger == nil {
@@ -93,11 +144,39 @@ func (c *RespConfig) Build() middleware.Functor {
 	if msg == "" {
 		msg = "RSP"
 	}
-	withRequestID, withHeaders := c.WithRequestID, c.WithHeaders
-	return func(next http.Handler) http.Handler {
+	withRequestID, withHeaders, withContextInfo := c.WithRequestID, c.WithHeaders, c.WithContextInfo
+	slowThreshold, slowLevel := c.SlowThreshold, c.SlowLevel
+	if slowLevel <= level {
+		slowLevel = level + 4
+	}
+	return middleware.Declare(func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			logw := logResponseWriter{w: w}
+			var start time.Time
+			if slowThreshold > 0 {
+				start = time.Now()
+			}
+			ctx, ic := status.WithInterception(r.Context())
+			logw := logResponseWriter{w: w, gone: make(chan struct{})}
+			stop := context.AfterFunc(ctx, logw.closeGone)
+			r = r.WithContext(context.WithValue(ctx, clientGoneKey{}, (<-chan struct{})(logw.gone)))
 			next.ServeHTTP(&logw, r)
+			stop()
+
+			logLevel := level
+			var slowAttr, durationAttr slog.Attr
+			if slowThreshold > 0 {
+				if elapsed := time.Since(start); elapsed > slowThreshold {
+					logLevel = slowLevel
+					slowAttr = slog.Bool("slow", true)
+					durationAttr = slog.Duration("duration", elapsed)
+				}
+			}
+
+			var interceptedAttr, originalAttr slog.Attr
+			if ic.Intercepted() {
+				interceptedAttr = slog.Bool("intercepted", true)
+				originalAttr = slog.Int("original", ic.Code())
+			}
 
 			var requestIDAttr, headerAttr slog.Attr
 			if withRequestID {
@@ -106,20 +185,52 @@ func (c *RespConfig) Build() middleware.Functor {
 			if withHeaders {
 				headerAttr = slog.Any("header", logw.Header())
 			}
+			var contextAttr slog.Attr
+			if withContextInfo {
+				contextAttr = contextInfoAttr(r.Context())
+			}
 
-			logger.LogAttrs(r.Context(), level, msg, requestIDAttr,
+			var writeErrAttr, intendedAttr, goneAttr slog.Attr
+			if err := logw.writeErr; err != nil {
+				writeErrAttr = slog.String("write_error", err.Error())
+				intendedAttr = slog.Int("intended", logw.intendedLength())
+			}
+			if errors.Is(ctx.Err(), context.Canceled) {
+				goneAttr = slog.Bool("client_gone", true)
+			}
+
+			logger.LogAttrs(r.Context(), logLevel, msg, requestIDAttr,
 				slog.String("method", r.Method), slog.Any("url", r.URL),
 				slog.Int("status", logw.code), slog.Int("length", logw.length),
-				headerAttr)
-
+				writeErrAttr, intendedAttr, goneAttr,
+				interceptedAttr, originalAttr, headerAttr, slowAttr, durationAttr, contextAttr)
 		})
+	}, "logging-response", middleware.After(reqid.Capability))
+}
+
+// clientGoneKey is the context key of the channel returned by [ClientGone].
+type clientGoneKey struct{}
+
+// ClientGone returns a channel that is closed, when the client of the
+// request has gone away: either the request context was canceled, or
+// writing the response failed. The latter is only detected, if the
+// response logger of this package is active; otherwise the channel is the
+// Done channel of the context.
+func ClientGone(ctx context.Context) <-chan struct{} {
+	if gone, ok := ctx.Value(clientGoneKey{}).(<-chan struct{}); ok {
+		return gone
 	}
+	return ctx.Done()
 }
 
 type logResponseWriter struct {
-	w      http.ResponseWriter
-	code   int
-	length int
+	w        http.ResponseWriter
+	code     int
+	length   int
+	intended int   // number of bytes the handler tried to write
+	writeErr error // first error of the underlying Write
+	gone     chan struct{}
+	goneOnce sync.Once
 }
 
 func (lrw *logResponseWriter) Header() http.Header { return lrw.w.Header() }
@@ -127,9 +238,28 @@ func (lrw *logResponseWriter) Header() http.Header { return lrw.w.Header() }
 func (lrw *logResponseWriter) Write(data []byte) (int, error) {
 	length, err := lrw.w.Write(data)
 	lrw.length += length
+	lrw.intended += len(data)
+	if err != nil && lrw.writeErr == nil {
+		lrw.writeErr = err
+		lrw.closeGone()
+	}
 	return length, err
 }
 func (lrw *logResponseWriter) WriteHeader(code int) {
 	lrw.code = code
 	lrw.w.WriteHeader(code)
 }
+
+// Unwrap returns the underlying response writer, e.g. to flush a streamed
+// response with a [http.ResponseController].
+func (lrw *logResponseWriter) Unwrap() http.ResponseWriter { return lrw.w }
+
+func (lrw *logResponseWriter) closeGone() { lrw.goneOnce.Do(func() { close(lrw.gone) }) }
+
+// intendedLength returns the number of bytes that should have been written.
+func (lrw *logResponseWriter) intendedLength() int {
+	if cl, err := strconv.Atoi(lrw.Header().Get("Content-Length")); err == nil && cl > lrw.intended {
+		return cl
+	}
+	return lrw.intended
+}
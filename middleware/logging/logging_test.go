@@ -17,13 +17,17 @@ import (
 	"context"
 	"io"
 	"log/slog"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"slices"
+	"strconv"
 	"testing"
+	"time"
 
 	"t73f.de/r/webs/middleware/logging"
 	"t73f.de/r/webs/middleware/reqid"
+	"t73f.de/r/webs/middleware/status"
 	"t73f.de/r/zero/snow"
 )
 
@@ -175,6 +179,216 @@ func TestResponseLogging(t *testing.T) {
 	}
 }
 
+func TestResponseLoggingSlow(t *testing.T) {
+	logh := testLoggingHandler{}
+	cfg := logging.RespConfig{Logger: slog.New(&logh), SlowThreshold: 20 * time.Millisecond}
+	handler := cfg.Build()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(30 * time.Millisecond)
+		}
+	}))
+
+	for _, path := range []string{"/fast", "/slow"} {
+		logh.records = nil
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		if len(logh.records) != 1 {
+			t.Fatalf("%s: expected one log record, got %d", path, len(logh.records))
+		}
+		rec := logh.records[0]
+		isSlow := false
+		var duration time.Duration
+		rec.Attrs(func(a slog.Attr) bool {
+			switch a.Key {
+			case "slow":
+				isSlow = a.Value.Bool()
+			case "duration":
+				duration = a.Value.Duration()
+			}
+			return true
+		})
+		if expSlow := path == "/slow"; isSlow != expSlow {
+			t.Errorf("%s: slow=%v expected", path, expSlow)
+		} else if expSlow && (rec.Level != slog.LevelWarn || duration < cfg.SlowThreshold) {
+			t.Errorf("%s: level WARN and duration above threshold expected, got %v/%v", path, rec.Level, duration)
+		} else if !expSlow && rec.Level != slog.LevelInfo {
+			t.Errorf("%s: level INFO expected, got %v", path, rec.Level)
+		}
+	}
+}
+
+func TestResponseLoggingIntercepted(t *testing.T) {
+	logh := testLoggingHandler{}
+	cfg := logging.RespConfig{Logger: slog.New(&logh)}
+	statuscfg := status.Config{HandlerMap: status.HandlerMap{
+		http.StatusNotFound: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, "Custom")
+		}),
+	}}
+	handler := cfg.Build()(statuscfg.Build()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+		}
+	})))
+
+	testcases := []struct {
+		path     string
+		expAttrs []string
+	}{
+		{"/ok", []string{"status", "0", "length", "0"}},
+		{"/missing", []string{"status", "404", "length", "6", "intercepted", "true", "original", "404"}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.path, func(t *testing.T) {
+			logh.records = nil
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tc.path, nil))
+			if len(logh.records) != 1 {
+				t.Fatalf("expected one log record, got %d", len(logh.records))
+			}
+			attrs := []string{}
+			logh.records[0].Attrs(func(a slog.Attr) bool {
+				if a.Key != "method" && a.Key != "url" && !a.Equal(slog.Attr{}) {
+					attrs = append(attrs, a.Key, a.Value.String())
+				}
+				return true
+			})
+			if !slices.Equal(tc.expAttrs, attrs) {
+				t.Errorf("attrs expected:\n%v, got:\n%v", tc.expAttrs, attrs)
+			}
+		})
+	}
+}
+
+func TestContextInfoLogging(t *testing.T) {
+	logh := testLoggingHandler{}
+	logger := slog.New(&logh)
+	reqCfg := logging.ReqConfig{Logger: logger, WithContextInfo: true}
+	respCfg := logging.RespConfig{Logger: logger, WithContextInfo: true}
+	reqidCfg := reqid.Config{WithContext: true}
+	handler := reqidCfg.Build()(reqCfg.Build()(respCfg.Build()(http.NotFoundHandler())))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if len(logh.records) != 2 {
+		t.Fatalf("expected two log records, got %d", len(logh.records))
+	}
+	for _, rec := range logh.records {
+		var group []slog.Attr
+		rec.Attrs(func(a slog.Attr) bool {
+			if a.Key == logging.ContextInfoKey {
+				group = a.Value.Group()
+			}
+			return true
+		})
+		if len(group) != 1 || group[0].Key != reqid.ContextInfoName || group[0].Value.String() == "" {
+			t.Errorf("%s: request id expected in context group, got %v", rec.Message, group)
+		}
+	}
+}
+
+func TestResponseLoggingClientGone(t *testing.T) {
+	logh := testLoggingHandler{}
+	respCfg := logging.RespConfig{Logger: slog.New(&logh)}
+	const intended = 100 << 20
+
+	var handlerErr error
+	goneClosed := false
+	started, done := make(chan struct{}), make(chan struct{})
+	handler := respCfg.Build()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", strconv.Itoa(intended))
+		chunk := make([]byte, 32<<10)
+		for i := 0; i < intended/len(chunk); i++ {
+			if _, handlerErr = w.Write(chunk); handlerErr != nil {
+				break
+			}
+			if i == 0 {
+				_ = http.NewResponseController(w).Flush()
+				close(started)
+			}
+		}
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+		select {
+		case <-logging.ClientGone(r.Context()):
+			goneClosed = true
+		default:
+		}
+	}))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(done)
+		handler.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = io.WriteString(conn, "GET / HTTP/1.1\r\nHost: localhost\r\n\r\n"); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	if _, err = conn.Read(make([]byte, 1024)); err != nil {
+		t.Fatal(err)
+	}
+	_ = conn.Close()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("handler did not finish")
+	}
+
+	if handlerErr == nil {
+		t.Error("handler must see the write error")
+	}
+	if !goneClosed {
+		t.Error("ClientGone channel must be closed")
+	}
+	if len(logh.records) != 1 {
+		t.Fatalf("expected one log record, got %d", len(logh.records))
+	}
+	attrs := map[string]slog.Value{}
+	logh.records[0].Attrs(func(a slog.Attr) bool {
+		attrs[a.Key] = a.Value
+		return true
+	})
+	if got, found := attrs["write_error"]; !found || handlerErr == nil || got.String() != handlerErr.Error() {
+		t.Errorf("write error %v expected, got %v", handlerErr, got)
+	}
+	if got := attrs["intended"]; got.Int64() != intended {
+		t.Errorf("intended length %d expected, got %v", intended, got)
+	}
+	if got := attrs["length"]; got.Int64() >= intended {
+		t.Errorf("length must be less than %d, got %v", intended, got)
+	}
+	if got := attrs["client_gone"]; !got.Bool() {
+		t.Errorf("client_gone expected, got %v", got)
+	}
+}
+
+func TestResponseLoggingNoClientGone(t *testing.T) {
+	logh := testLoggingHandler{}
+	respCfg := logging.RespConfig{Logger: slog.New(&logh)}
+	var gone <-chan struct{}
+	handler := respCfg.Build()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gone = logging.ClientGone(r.Context())
+		_, _ = io.WriteString(w, "Hello")
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	select {
+	case <-gone:
+		t.Error("ClientGone channel must not be closed")
+	default:
+	}
+	logh.records[0].Attrs(func(a slog.Attr) bool {
+		if a.Key == "write_error" || a.Key == "intended" || a.Key == "client_gone" {
+			t.Errorf("unexpected attribute %v", a)
+		}
+		return true
+	})
+}
+
 type testcases []struct {
 	path          string
 	logger        *slog.Logger
@@ -0,0 +1,182 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+// Package middlewaretest provides utilities to test middleware, especially
+// their logging behaviour.
+package middlewaretest
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"t73f.de/r/webs/middleware/logging"
+	"t73f.de/r/webs/middleware/reqid"
+)
+
+// Record is a captured log record. All attributes are flattened: the key of
+// an attribute within a group is prefixed by the group name and a dot.
+type Record struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	Attrs   map[string]slog.Value
+}
+
+// RequestID returns the request identifier stored in the record, or the
+// empty string. Both the key of the logging middleware and the key of the
+// request identifier logger are checked.
+func (r *Record) RequestID() string {
+	for _, key := range []string{logging.DefaultRequestIDKey, reqid.DefaultLoggingKey} {
+		if val, found := r.Attrs[key]; found {
+			return val.String()
+		}
+	}
+	return ""
+}
+
+// Records stores all captured log records.
+type Records struct {
+	mx      sync.Mutex
+	records []*Record
+}
+
+// CaptureLogs returns a logger that captures all records, and the query
+// object to retrieve them. All levels are captured.
+func CaptureLogs(t testing.TB) (*slog.Logger, *Records) {
+	t.Helper()
+	recs := &Records{}
+	t.Cleanup(recs.Reset)
+	return slog.New(&captureHandler{recs: recs}), recs
+}
+
+// All returns all captured records.
+func (recs *Records) All() []*Record {
+	recs.mx.Lock()
+	defer recs.mx.Unlock()
+	return slices.Clone(recs.records)
+}
+
+// Reset removes all captured records.
+func (recs *Records) Reset() {
+	recs.mx.Lock()
+	recs.records = nil
+	recs.mx.Unlock()
+}
+
+// ByRequestID returns all records with the given request identifier.
+func (recs *Records) ByRequestID(id string) []*Record {
+	return recs.filter(func(r *Record) bool { return r.RequestID() == id })
+}
+
+// WithLevel returns all records with the given level.
+func (recs *Records) WithLevel(level slog.Level) []*Record {
+	return recs.filter(func(r *Record) bool { return r.Level == level })
+}
+
+func (recs *Records) filter(pred func(*Record) bool) []*Record {
+	recs.mx.Lock()
+	defer recs.mx.Unlock()
+	var result []*Record
+	for _, r := range recs.records {
+		if pred(r) {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+func (recs *Records) add(r *Record) {
+	recs.mx.Lock()
+	recs.records = append(recs.records, r)
+	recs.mx.Unlock()
+}
+
+// captureHandler is a slog.Handler that stores records in a Records object.
+type captureHandler struct {
+	recs   *Records
+	attrs  map[string]slog.Value // attributes added by WithAttrs
+	prefix string                // group prefix, added by WithGroup
+}
+
+func (*captureHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (ch *captureHandler) Handle(_ context.Context, r slog.Record) error {
+	rec := &Record{
+		Time:    r.Time,
+		Level:   r.Level,
+		Message: r.Message,
+		Attrs:   make(map[string]slog.Value, len(ch.attrs)+r.NumAttrs()),
+	}
+	for k, v := range ch.attrs {
+		rec.Attrs[k] = v
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		flattenAttr(rec.Attrs, ch.prefix, a)
+		return true
+	})
+	ch.recs.add(rec)
+	return nil
+}
+
+func (ch *captureHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return ch
+	}
+	result := ch.clone()
+	for _, a := range attrs {
+		flattenAttr(result.attrs, ch.prefix, a)
+	}
+	return result
+}
+
+func (ch *captureHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return ch
+	}
+	result := ch.clone()
+	result.prefix = ch.prefix + name + "."
+	return result
+}
+
+func (ch *captureHandler) clone() *captureHandler {
+	attrs := make(map[string]slog.Value, len(ch.attrs))
+	for k, v := range ch.attrs {
+		attrs[k] = v
+	}
+	return &captureHandler{recs: ch.recs, attrs: attrs, prefix: ch.prefix}
+}
+
+func flattenAttr(m map[string]slog.Value, prefix string, a slog.Attr) {
+	val := a.Value.Resolve()
+	if val.Kind() == slog.KindGroup {
+		groupAttrs := val.Group()
+		if len(groupAttrs) == 0 {
+			return
+		}
+		if a.Key != "" {
+			prefix = prefix + a.Key + "."
+		}
+		for _, ga := range groupAttrs {
+			flattenAttr(m, prefix, ga)
+		}
+		return
+	}
+	if a.Key == "" {
+		return
+	}
+	m[prefix+a.Key] = val
+}
@@ -0,0 +1,59 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package middlewaretest_test
+
+import (
+	"log/slog"
+	"testing"
+
+	"t73f.de/r/webs/middleware/middlewaretest"
+)
+
+func TestCaptureLogs(t *testing.T) {
+	logger, recs := middlewaretest.CaptureLogs(t)
+	logger.Info("first", "id", "17", slog.Group("g", "a", 1, slog.Group("h", "b", 2)))
+	logger.With("x", "y").WithGroup("grp").Warn("second", "REQ-ID", "18", "c", 3)
+	logger.Debug("third", slog.Group("empty"))
+
+	all := recs.All()
+	if len(all) != 3 {
+		t.Fatalf("3 records expected, but got %d", len(all))
+	}
+	first := all[0]
+	for key, exp := range map[string]string{"id": "17", "g.a": "1", "g.h.b": "2"} {
+		if got, found := first.Attrs[key]; !found || got.String() != exp {
+			t.Errorf("attribute %q: expected %q, but got %v", key, exp, got)
+		}
+	}
+	second := all[1]
+	for key, exp := range map[string]string{"x": "y", "grp.REQ-ID": "18", "grp.c": "3"} {
+		if got, found := second.Attrs[key]; !found || got.String() != exp {
+			t.Errorf("attribute %q: expected %q, but got %v", key, exp, got)
+		}
+	}
+	if got := len(all[2].Attrs); got != 0 {
+		t.Errorf("no attributes expected, but got %v", all[2].Attrs)
+	}
+
+	if got := recs.ByRequestID("17"); len(got) != 1 || got[0] != first {
+		t.Errorf("ByRequestID: unexpected result %v", got)
+	}
+	if got := recs.WithLevel(slog.LevelWarn); len(got) != 1 || got[0] != second {
+		t.Errorf("WithLevel: unexpected result %v", got)
+	}
+	recs.Reset()
+	if got := recs.All(); len(got) != 0 {
+		t.Errorf("no records expected after reset, but got %v", got)
+	}
+}
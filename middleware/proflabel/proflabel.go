@@ -0,0 +1,73 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+// Package proflabel provides a middleware functor that sets pprof labels for
+// each request, so that CPU and goroutine profiles can be filtered by route,
+// method, and request id.
+package proflabel
+
+import (
+	"context"
+	"net/http"
+	"runtime/pprof"
+
+	"t73f.de/r/webs/middleware"
+	"t73f.de/r/webs/middleware/reqid"
+)
+
+// Names of the labels.
+const (
+	LabelPattern   = "pattern"
+	LabelMethod    = "method"
+	LabelRequestID = "reqid"
+)
+
+// Config stores all configuration data to build a labelling functor.
+//
+// If the functor is not applied, there is no overhead at all.
+type Config struct {
+	// Pattern returns the route pattern of the request. If nil, the pattern
+	// of the request is used, which is set by http.ServeMux for the handler
+	// of a route. Empty patterns are not used as labels.
+	Pattern func(*http.Request) string
+
+	// WithRequestID adds the request id as a label, if there is one, see
+	// [reqid.GetRequestID].
+	WithRequestID bool
+}
+
+// Build the Functor from the configuration.
+func (c *Config) Build() middleware.Functor {
+	pattern := c.Pattern
+	if pattern == nil {
+		pattern = func(r *http.Request) string { return r.Pattern }
+	}
+	withRequestID := c.WithRequestID
+	return middleware.Declare(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			labels := make([]string, 0, 6)
+			labels = append(labels, LabelMethod, r.Method)
+			if p := pattern(r); p != "" {
+				labels = append(labels, LabelPattern, p)
+			}
+			if withRequestID {
+				if id := reqid.GetRequestID(r.Context()); !id.IsInvalid() {
+					labels = append(labels, LabelRequestID, id.String())
+				}
+			}
+			pprof.Do(r.Context(), pprof.Labels(labels...), func(ctx context.Context) {
+				next.ServeHTTP(w, r.WithContext(ctx))
+			})
+		})
+	}, "proflabel", middleware.After(reqid.Capability))
+}
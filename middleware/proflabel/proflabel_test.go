@@ -0,0 +1,77 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package proflabel_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"runtime/pprof"
+	"testing"
+
+	"t73f.de/r/webs/middleware/proflabel"
+	"t73f.de/r/webs/middleware/reqid"
+)
+
+func labelsOf(r *http.Request) map[string]string {
+	result := map[string]string{}
+	pprof.ForLabels(r.Context(), func(key, value string) bool {
+		result[key] = value
+		return true
+	})
+	return result
+}
+
+func TestLabels(t *testing.T) {
+	var got map[string]string
+	hf := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) { got = labelsOf(r) })
+
+	cfg := proflabel.Config{WithRequestID: true}
+	reqidCfg := reqid.Config{WithContext: true}
+	mux := http.NewServeMux()
+	mux.Handle("GET /item/{id}", reqidCfg.Build()(cfg.Build()(hf)))
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/item/17", nil))
+	if len(got) != 3 || got[proflabel.LabelMethod] != "GET" || got[proflabel.LabelPattern] != "GET /item/{id}" || got[proflabel.LabelRequestID] == "" {
+		t.Errorf("unexpected labels %v", got)
+	}
+
+	cfg = proflabel.Config{Pattern: func(*http.Request) string { return "custom" }}
+	cfg.Build()(hf).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", nil))
+	if len(got) != 2 || got[proflabel.LabelMethod] != "POST" || got[proflabel.LabelPattern] != "custom" {
+		t.Errorf("unexpected labels %v", got)
+	}
+
+	// Labels are only set during handler execution.
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	cfg.Build()(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})).ServeHTTP(httptest.NewRecorder(), r)
+	if got = labelsOf(r); len(got) != 0 {
+		t.Errorf("no labels expected after handler, got %v", got)
+	}
+}
+
+func BenchmarkWithoutLabels(b *testing.B) {
+	hf := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+	w, r := httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil)
+	for b.Loop() {
+		hf.ServeHTTP(w, r)
+	}
+}
+
+func BenchmarkWithLabels(b *testing.B) {
+	cfg := proflabel.Config{}
+	h := cfg.Build()(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	w, r := httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil)
+	for b.Loop() {
+		h.ServeHTTP(w, r)
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"t73f.de/r/zero/snow"
 
 	"t73f.de/r/webs/middleware"
+	"t73f.de/r/webs/middleware/contextinfo"
 )
 
 // DefaultHeaderKey specifies the HTTP header key, where the request ID should be stored.
@@ -31,6 +32,10 @@ const DefaultHeaderKey = "X-Request-Id"
 // DefaultLoggingKey is the default value for [Config.LoggingKey].
 const DefaultLoggingKey = "REQ-ID"
 
+// Capability is the name of the capability provided by the functor, see
+// [middleware.Provides].
+const Capability = "request-id"
+
 // Config stores all configuration to build a Functor.
 type Config struct {
 	HeaderKey    string // Key of HTTP request / response that sores the value
@@ -56,7 +61,7 @@ func (c *Config) Build() middleware.Functor {
 	}
 	withContext := c.WithContext
 	withResponse := c.WithResponse
-	return func(next http.Handler) http.Handler {
+	return middleware.Declare(func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			id := gen.Create(appID)
 			if withContext {
@@ -69,13 +74,26 @@ func (c *Config) Build() middleware.Functor {
 			}
 			next.ServeHTTP(w, r)
 		})
-	}
+	}, "reqid", middleware.Provides(Capability))
 }
 
 type ctxKeyType struct{}
 
 var withReqID, getReqID = contexts.WithAndValue[snow.Key](ctxKeyType{})
 
+// ContextInfoName is the name of the request identification in a snapshot of
+// package contextinfo.
+const ContextInfoName = "request-id"
+
+func init() {
+	contextinfo.Register(ContextInfoName, func(ctx context.Context) (string, bool) {
+		if id, ok := getReqID(ctx); ok {
+			return id.String(), true
+		}
+		return "", false
+	})
+}
+
 // GetRequestID returns the request identification injected by the middleware functor.
 func GetRequestID(ctx context.Context) snow.Key {
 	if id, ok := getReqID(ctx); ok {
@@ -0,0 +1,276 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+// Package shadow provides a middleware functor that mirrors requests to a
+// shadow handler, e.g. a rewritten implementation, and compares the
+// responses, without affecting the response to the client.
+package shadow
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"t73f.de/r/webs/middleware"
+)
+
+// Default values of a [Config].
+const (
+	DefaultTimeout     = 5 * time.Second
+	DefaultMaxBody     = 1 << 20
+	DefaultMaxInFlight = 16
+)
+
+// DefaultMethods lists the methods of requests that are mirrored by
+// default. They are idempotent, so that mirroring them does not cause side
+// effects twice.
+var DefaultMethods = []string{http.MethodGet, http.MethodHead, http.MethodOptions}
+
+// CapturedResponse describes a response, either of the primary or of the
+// shadow handler.
+type CapturedResponse struct {
+	Status   int
+	Header   http.Header
+	BodySize int64
+	BodyHash [sha256.Size]byte // SHA-256 hash of the body
+
+	// Err is not nil, if the shadow handler panicked or did not finish in
+	// time. The other fields are then not valid.
+	Err error
+}
+
+// Config stores all configuration data to build a mirroring functor.
+type Config struct {
+	// Target is the shadow handler that receives a copy of the requests.
+	Target http.Handler
+
+	// Sampler selects the requests to be mirrored. If nil, all requests
+	// with an allowed method are mirrored.
+	Sampler func(*http.Request) bool
+
+	// Methods lists the methods of requests that may be mirrored. If empty,
+	// DefaultMethods is used.
+	Methods []string
+
+	// Timeout is the maximum duration of the shadow handler. If not
+	// positive, DefaultTimeout is used.
+	Timeout time.Duration
+
+	// MaxBody is the maximum size of a request body that is buffered to be
+	// sent to the shadow handler. Requests with larger bodies are not
+	// mirrored. If not positive, DefaultMaxBody is used.
+	MaxBody int64
+
+	// MaxInFlight is the maximum number of concurrently running shadow
+	// handlers. A handler that did not finish in time counts, until it
+	// returns. If this number is reached, requests are not mirrored. If not
+	// positive, DefaultMaxInFlight is used.
+	MaxInFlight int
+
+	// Compare is called with both responses, after the shadow handler
+	// finished. It is called in its own goroutine.
+	Compare func(primary, shadow CapturedResponse)
+}
+
+// Build the Functor from the configuration.
+//
+// The request is served by the next handler, as usual. If it is selected
+// for mirroring, a copy of the request is sent to the Target handler, after
+// the next handler returned. The copy has its own context, which is not
+// canceled with the request, but after the timeout. Its body, if any, is
+// buffered. The response of the Target handler is discarded, after it was
+// captured for comparison.
+func (c *Config) Build() middleware.Functor {
+	target := c.Target
+	if target == nil {
+		return middleware.NilFunctor
+	}
+	sampler := c.Sampler
+	methods := slices.Clone(c.Methods)
+	if len(methods) == 0 {
+		methods = DefaultMethods
+	}
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	maxBody := c.MaxBody
+	if maxBody <= 0 {
+		maxBody = DefaultMaxBody
+	}
+	maxInFlight := c.MaxInFlight
+	if maxInFlight <= 0 {
+		maxInFlight = DefaultMaxInFlight
+	}
+	inFlight := make(chan struct{}, maxInFlight)
+	compare := c.Compare
+
+	return middleware.Declare(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !slices.Contains(methods, r.Method) || (sampler != nil && !sampler(r)) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			body, ok := bufferBody(r, maxBody)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			select {
+			case inFlight <- struct{}{}:
+			default:
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Clone the request before the next handler is able to modify it.
+			shadowReq := r.Clone(context.WithoutCancel(r.Context()))
+			shadowReq.Body = io.NopCloser(bytes.NewReader(body))
+			if body == nil {
+				shadowReq.Body = http.NoBody
+			}
+
+			cw := newCaptureWriter(w)
+			next.ServeHTTP(cw, r)
+			primary := cw.captured()
+
+			go func() {
+				shadow := serveShadow(target, shadowReq, timeout, func() { <-inFlight })
+				if compare != nil {
+					compare(primary, shadow)
+				}
+			}()
+		})
+	}, "shadow")
+}
+
+// bufferBody reads the body of the request into a buffer, and re-installs
+// it. If the body is larger than maxBody, false is returned, but the request
+// can still be served.
+func bufferBody(r *http.Request, maxBody int64) ([]byte, bool) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, true
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBody+1))
+	if err != nil || int64(len(body)) > maxBody {
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+		return nil, false
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return body, true
+}
+
+// serveShadow lets the target handler serve the request, and captures its
+// response. Release is called when the target handler returned, which may be
+// after the timeout, so that a hung handler still counts as in flight.
+func serveShadow(target http.Handler, r *http.Request, timeout time.Duration, release func()) CapturedResponse {
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+	r = r.WithContext(ctx)
+
+	done := make(chan CapturedResponse, 1)
+	go func() {
+		defer release()
+		cw := newCaptureWriter(nil)
+		defer func() {
+			if val := recover(); val != nil {
+				done <- CapturedResponse{Err: fmt.Errorf("shadow handler panicked: %v", val)}
+			}
+		}()
+		target.ServeHTTP(cw, r)
+		done <- cw.captured()
+	}()
+
+	select {
+	case resp := <-done:
+		return resp
+	case <-ctx.Done():
+		return CapturedResponse{Err: ctx.Err()}
+	}
+}
+
+// captureWriter captures a response, while passing it to the underlying
+// writer, if there is one.
+type captureWriter struct {
+	w      http.ResponseWriter
+	header http.Header // only used without underlying writer
+	status int
+	saved  http.Header // header at the time the status was written
+	size   int64
+	hash   hash.Hash
+}
+
+func newCaptureWriter(w http.ResponseWriter) *captureWriter {
+	return &captureWriter{w: w, hash: sha256.New()}
+}
+
+func (cw *captureWriter) Header() http.Header {
+	if cw.w != nil {
+		return cw.w.Header()
+	}
+	if cw.header == nil {
+		cw.header = http.Header{}
+	}
+	return cw.header
+}
+
+func (cw *captureWriter) WriteHeader(code int) {
+	if cw.w != nil {
+		cw.w.WriteHeader(code)
+	}
+	if cw.status != 0 || (code >= 100 && code <= 199) {
+		return
+	}
+	cw.status = code
+	cw.saved = cw.Header().Clone()
+}
+
+func (cw *captureWriter) Write(data []byte) (int, error) {
+	if cw.status == 0 {
+		cw.WriteHeader(http.StatusOK)
+	}
+	n := len(data)
+	var err error
+	if cw.w != nil {
+		n, err = cw.w.Write(data)
+	}
+	cw.size += int64(n)
+	cw.hash.Write(data[:n])
+	return n, err
+}
+
+// Unwrap returns the underlying writer, for http.ResponseController.
+func (cw *captureWriter) Unwrap() http.ResponseWriter { return cw.w }
+
+func (cw *captureWriter) captured() CapturedResponse {
+	resp := CapturedResponse{
+		Status:   cw.status,
+		Header:   cw.saved,
+		BodySize: cw.size,
+	}
+	if resp.Status == 0 {
+		resp.Status = http.StatusOK
+		resp.Header = cw.Header().Clone()
+	}
+	cw.hash.Sum(resp.BodyHash[:0])
+	return resp
+}
@@ -0,0 +1,247 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package shadow_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"t73f.de/r/webs/middleware/shadow"
+)
+
+// echoHandler writes the method, the path, and the request body.
+func echoHandler(prefix string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("X-Test", r.Header.Get("X-Test"))
+		_, _ = io.WriteString(w, prefix+r.Method+" "+r.URL.Path+" "+string(body))
+	})
+}
+
+type result struct{ primary, shadow shadow.CapturedResponse }
+
+func collect(cfg *shadow.Config) <-chan result {
+	ch := make(chan result, 8)
+	cfg.Compare = func(primary, shadow shadow.CapturedResponse) { ch <- result{primary, shadow} }
+	return ch
+}
+
+func waitResult(t *testing.T, ch <-chan result) result {
+	t.Helper()
+	select {
+	case res := <-ch:
+		return res
+	case <-time.After(5 * time.Second):
+		t.Fatal("no comparison")
+		return result{}
+	}
+}
+
+func expectNoResult(t *testing.T, ch <-chan result) {
+	t.Helper()
+	select {
+	case res := <-ch:
+		t.Errorf("no comparison expected, got %v", res)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func serve(h http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestShadowSameResponse(t *testing.T) {
+	primary := echoHandler("")
+	cfg := shadow.Config{Target: echoHandler("")}
+	ch := collect(&cfg)
+	newRequest := func() *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/path", strings.NewReader("body"))
+		r.Header.Set("X-Test", "value")
+		return r
+	}
+
+	plain := serve(primary, newRequest())
+	mirrored := serve(cfg.Build()(primary), newRequest())
+	if plain.Code != mirrored.Code || plain.Body.String() != mirrored.Body.String() ||
+		!reflect.DeepEqual(plain.Header(), mirrored.Header()) {
+		t.Errorf("response changed by shadowing:\n%v %v %q\n%v %v %q",
+			plain.Code, plain.Header(), plain.Body,
+			mirrored.Code, mirrored.Header(), mirrored.Body)
+	}
+
+	res := waitResult(t, ch)
+	if res.shadow.Err != nil {
+		t.Fatal(res.shadow.Err)
+	}
+	if !reflect.DeepEqual(res.primary, res.shadow) {
+		t.Errorf("responses should be equal:\n%v\n%v", res.primary, res.shadow)
+	}
+	if got := res.primary.BodySize; got != int64(plain.Body.Len()) {
+		t.Errorf("body size %d expected, got %d", plain.Body.Len(), got)
+	}
+	if got := res.shadow.Header.Get("X-Test"); got != "value" {
+		t.Errorf("headers were not copied, got %q", got)
+	}
+}
+
+func TestShadowDifferentResponse(t *testing.T) {
+	cfg := shadow.Config{Target: echoHandler("new ")}
+	ch := collect(&cfg)
+	w := serve(cfg.Build()(echoHandler("")), httptest.NewRequest(http.MethodGet, "/", nil))
+	if got, exp := w.Body.String(), "GET / "; got != exp {
+		t.Errorf("expected body %q, got %q", exp, got)
+	}
+	res := waitResult(t, ch)
+	if res.primary.BodyHash == res.shadow.BodyHash {
+		t.Error("body hashes should differ")
+	}
+	if res.primary.Status != http.StatusOK || res.shadow.Status != http.StatusOK {
+		t.Errorf("status 200 expected, got %d/%d", res.primary.Status, res.shadow.Status)
+	}
+}
+
+func TestShadowMethods(t *testing.T) {
+	cfg := shadow.Config{Target: echoHandler("")}
+	ch := collect(&cfg)
+	h := cfg.Build()(echoHandler(""))
+	for _, method := range []string{http.MethodPost, http.MethodPatch, http.MethodPut, http.MethodDelete} {
+		serve(h, httptest.NewRequest(method, "/", strings.NewReader("data")))
+	}
+	expectNoResult(t, ch)
+
+	cfg.Methods = []string{http.MethodPost}
+	w := serve(cfg.Build()(echoHandler("")), httptest.NewRequest(http.MethodPost, "/", strings.NewReader("data")))
+	if got, exp := w.Body.String(), "POST / data"; got != exp {
+		t.Errorf("expected body %q, got %q", exp, got)
+	}
+	if res := waitResult(t, ch); res.primary.BodyHash != res.shadow.BodyHash {
+		t.Error("shadow handler did not get the same body")
+	}
+}
+
+func TestShadowSampler(t *testing.T) {
+	cfg := shadow.Config{
+		Target:  echoHandler(""),
+		Sampler: func(r *http.Request) bool { return r.URL.Path == "/sampled" },
+	}
+	ch := collect(&cfg)
+	h := cfg.Build()(echoHandler(""))
+	serve(h, httptest.NewRequest(http.MethodGet, "/other", nil))
+	expectNoResult(t, ch)
+	serve(h, httptest.NewRequest(http.MethodGet, "/sampled", nil))
+	waitResult(t, ch)
+}
+
+func TestShadowLargeBody(t *testing.T) {
+	cfg := shadow.Config{Target: echoHandler(""), MaxBody: 4}
+	ch := collect(&cfg)
+	w := serve(cfg.Build()(echoHandler("")), httptest.NewRequest(http.MethodGet, "/", strings.NewReader("too large")))
+	if got, exp := w.Body.String(), "GET / too large"; got != exp {
+		t.Errorf("expected body %q, got %q", exp, got)
+	}
+	expectNoResult(t, ch)
+}
+
+func TestShadowTimeout(t *testing.T) {
+	target := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		w.WriteHeader(http.StatusGatewayTimeout)
+	})
+	cfg := shadow.Config{Target: target, Timeout: 10 * time.Millisecond}
+	ch := collect(&cfg)
+
+	// The request context is canceled after the primary handler returned,
+	// but this must not cancel the shadow request.
+	ctx, cancel := context.WithCancel(context.Background())
+	start := time.Now()
+	serve(cfg.Build()(echoHandler("")), httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+	cancel()
+	res := waitResult(t, ch)
+	if !errors.Is(res.shadow.Err, context.DeadlineExceeded) {
+		t.Errorf("deadline exceeded expected, got %v", res.shadow.Err)
+	}
+	if elapsed := time.Since(start); elapsed < 10*time.Millisecond {
+		t.Errorf("shadow request was canceled too early, after %v", elapsed)
+	}
+}
+
+func TestShadowPanic(t *testing.T) {
+	target := http.HandlerFunc(func(http.ResponseWriter, *http.Request) { panic("boom") })
+	cfg := shadow.Config{Target: target}
+	ch := collect(&cfg)
+	w := serve(cfg.Build()(echoHandler("")), httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("status 200 expected, got %d", w.Code)
+	}
+	if res := waitResult(t, ch); res.shadow.Err == nil {
+		t.Error("error expected for panicking shadow handler")
+	}
+}
+
+func TestShadowNoTarget(t *testing.T) {
+	cfg := shadow.Config{}
+	w := serve(cfg.Build()(echoHandler("")), httptest.NewRequest(http.MethodGet, "/", nil))
+	if got, exp := w.Body.String(), "GET / "; got != exp {
+		t.Errorf("expected body %q, got %q", exp, got)
+	}
+}
+
+func TestShadowHungTarget(t *testing.T) {
+	hang := make(chan struct{})
+	started := make(chan struct{}, 4)
+	target := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		started <- struct{}{}
+		<-hang // ignores the context
+	})
+	cfg := shadow.Config{Target: target, Timeout: 10 * time.Millisecond, MaxInFlight: 1}
+	ch := collect(&cfg)
+	h := cfg.Build()(echoHandler(""))
+
+	serve(h, httptest.NewRequest(http.MethodGet, "/", nil))
+	<-started
+	if res := waitResult(t, ch); !errors.Is(res.shadow.Err, context.DeadlineExceeded) {
+		t.Errorf("deadline exceeded expected, got %v", res.shadow.Err)
+	}
+
+	// The hung handler still occupies the only slot.
+	serve(h, httptest.NewRequest(http.MethodGet, "/", nil))
+	expectNoResult(t, ch)
+	if len(started) != 0 {
+		t.Error("no further shadow handler expected while the first one hangs")
+	}
+
+	close(hang)
+	deadline := time.Now().Add(5 * time.Second)
+	for len(started) == 0 && time.Now().Before(deadline) {
+		serve(h, httptest.NewRequest(http.MethodGet, "/", nil))
+		time.Sleep(time.Millisecond)
+	}
+	if len(started) == 0 {
+		t.Error("slot must be released after the handler returned")
+	}
+}
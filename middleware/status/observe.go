@@ -0,0 +1,88 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package status
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// Counter counts intercepted responses per original status code, e.g. to be
+// exported as metrics. Its zero value is ready to use. Its method Observe
+// can be used as [Config.OnIntercept].
+type Counter struct {
+	mx     sync.Mutex
+	counts map[int]uint64
+}
+
+// Observe counts an intercepted response with the given status code.
+func (c *Counter) Observe(code int, _ *http.Request) {
+	c.mx.Lock()
+	if c.counts == nil {
+		c.counts = map[int]uint64{}
+	}
+	c.counts[code]++
+	c.mx.Unlock()
+}
+
+// Count returns the number of intercepted responses with the given status
+// code.
+func (c *Counter) Count(code int) uint64 {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return c.counts[code]
+}
+
+// Counts returns a copy of all counts, as a map of status codes to the
+// number of intercepted responses.
+func (c *Counter) Counts() map[int]uint64 {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return maps.Clone(c.counts)
+}
+
+// Interception records whether the status middleware replaced a response.
+// It is placed in a request context by an outer middleware, e.g. a response
+// logger, see [WithInterception].
+type Interception struct {
+	code atomic.Int32
+}
+
+// Intercepted returns true, if the response was replaced.
+func (ic *Interception) Intercepted() bool { return ic.code.Load() != 0 }
+
+// Code returns the status code of the replaced response, or 0.
+func (ic *Interception) Code() int { return int(ic.code.Load()) }
+
+func (ic *Interception) set(code int) { ic.code.Store(int32(code)) }
+
+type interceptionKey struct{}
+
+// WithInterception returns a context that records an interception of the
+// status middleware, when it is used for an inner request. The interception
+// can be inspected after the inner handler returned.
+func WithInterception(ctx context.Context) (context.Context, *Interception) {
+	ic := &Interception{}
+	return context.WithValue(ctx, interceptionKey{}, ic), ic
+}
+
+func interceptionFrom(ctx context.Context) *Interception {
+	if ic, ok := ctx.Value(interceptionKey{}).(*Interception); ok {
+		return ic
+	}
+	return nil
+}
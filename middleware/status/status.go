@@ -22,9 +22,13 @@
 //	                                    "https://2017.4042307.org",
 //	                                    http.StatusTemporaryRedirect)}}
 //	f := cfg.Build()
+//
+// Intercepted responses can be observed with [Config.OnIntercept], e.g. by a
+// [Counter], and by an outer middleware with [WithInterception].
 package status
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
@@ -41,6 +45,12 @@ type Config struct {
 	// NoClearMap maps HTTP status codes to a boolean value that signals not
 	// to clear the HTTP header before calling the handler.
 	NoClearMap map[int]bool
+
+	// OnIntercept, if not nil, is called whenever a handler of HandlerMap
+	// replaced a response, with the original status code and a copy of the
+	// request. It is called in its own goroutine, so that it does not delay
+	// the response. A panic of OnIntercept is recovered.
+	OnIntercept func(code int, r *http.Request)
 }
 
 // HandlerMap maps HTTP status codes to handler.
@@ -54,19 +64,21 @@ func (c Config) Build() middleware.Functor {
 		m = HandlerMap{}
 	}
 	nc := c.NoClearMap
-	return func(next http.Handler) http.Handler {
+	onIntercept := c.OnIntercept
+	return middleware.Declare(func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			srw := statusRespWriter{m: m, nc: nc, w: w, r: r}
+			srw := statusRespWriter{m: m, nc: nc, onIntercept: onIntercept, w: w, r: r}
 			next.ServeHTTP(&srw, r)
 		})
-	}
+	}, "status", middleware.MemberOf(middleware.GroupBodyTransformers))
 }
 
 type statusRespWriter struct {
-	m  HandlerMap
-	nc map[int]bool
-	w  http.ResponseWriter
-	r  *http.Request
+	m           HandlerMap
+	nc          map[int]bool
+	onIntercept func(int, *http.Request)
+	w           http.ResponseWriter
+	r           *http.Request
 
 	found bool
 }
@@ -75,8 +87,13 @@ func (srw *statusRespWriter) Header() http.Header {
 	return srw.w.Header()
 }
 func (srw *statusRespWriter) WriteHeader(code int) {
+	if srw.found {
+		// Response was already replaced, ignore superfluous calls.
+		return
+	}
 	if h, found := srw.m[code]; found {
 		srw.found = true
+		srw.observe(code)
 		if nc := srw.nc; nc == nil || !nc[code] {
 			clear(srw.w.Header())
 		}
@@ -93,6 +110,23 @@ func (srw *statusRespWriter) Write(data []byte) (int, error) {
 	return srw.w.Write(data)
 }
 
+// observe records the interception in the request context, and calls the
+// OnIntercept hook.
+func (srw *statusRespWriter) observe(code int) {
+	ctx := srw.r.Context()
+	if ic := interceptionFrom(ctx); ic != nil {
+		ic.set(code)
+	}
+	if onIntercept := srw.onIntercept; onIntercept != nil {
+		r := srw.r.Clone(context.WithoutCancel(ctx))
+		r.Body = http.NoBody
+		go func() {
+			defer func() { _ = recover() }()
+			onIntercept(code, r)
+		}()
+	}
+}
+
 // BaseRedirectHandler returns a handler that redirects each request it
 // receives using the given status code. The redirect URL is calculated by
 // appending the requests URL (a path, an optional query, and an optional
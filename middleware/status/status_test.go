@@ -17,6 +17,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"t73f.de/r/webs/middleware/status"
 )
@@ -164,3 +165,77 @@ func TestBaseRedirectHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestOnIntercept(t *testing.T) {
+	type event struct {
+		code int
+		path string
+	}
+	events := make(chan event, 10)
+	var counter status.Counter
+	cfg := status.Config{
+		HandlerMap: status.HandlerMap{
+			http.StatusNotFound: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusGone)
+			}),
+		},
+		OnIntercept: func(code int, r *http.Request) {
+			counter.Observe(code, r)
+			events <- event{code, r.URL.Path}
+		},
+	}
+	handler := cfg.Build()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+			w.WriteHeader(http.StatusNotFound) // superfluous
+		case "/error":
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+
+	for _, path := range []string{"/ok", "/error", "/missing"} {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	select {
+	case ev := <-events:
+		if ev.code != http.StatusNotFound || ev.path != "/missing" {
+			t.Errorf("unexpected event: %v", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("hook not called")
+	}
+	select {
+	case ev := <-events:
+		t.Errorf("hook called more than once: %v", ev)
+	case <-time.After(20 * time.Millisecond):
+	}
+	if got := counter.Counts(); len(got) != 1 || got[http.StatusNotFound] != 1 {
+		t.Errorf("one 404 expected, got %v", got)
+	}
+}
+
+func TestOnInterceptPanic(t *testing.T) {
+	called := make(chan struct{})
+	cfg := status.Config{
+		HandlerMap: status.HandlerMap{
+			http.StatusNotFound: http.RedirectHandler("/foo", http.StatusTemporaryRedirect),
+		},
+		OnIntercept: func(int, *http.Request) {
+			close(called)
+			panic("hook")
+		},
+	}
+	handler := cfg.Build()(http.NotFoundHandler())
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got := rr.Code; got != http.StatusTemporaryRedirect {
+		t.Errorf("code %d expected, got: %d", http.StatusTemporaryRedirect, got)
+	}
+	select {
+	case <-called:
+	case <-time.After(time.Second):
+		t.Fatal("hook not called")
+	}
+}
@@ -0,0 +1,155 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package transform
+
+import (
+	"bytes"
+	"html"
+	"net/http"
+	"strings"
+)
+
+// InjectAttribute returns a transformation that adds an attribute to all
+// start tags with one of the given tag names, e.g. a nonce to all "script"
+// and "style" elements. The value of the attribute is calculated per
+// request. If the value is empty, the body is not changed. Tags that already
+// contain the attribute are not changed.
+//
+// The transformation uses a conservative byte-level scanner: it skips
+// comments and respects quoted attribute values, but it does not implement
+// the full HTML parsing algorithm.
+func InjectAttribute(key string, value func(*http.Request) string, tags ...string) Func {
+	key = strings.ToLower(key)
+	lowerTags := make([][]byte, len(tags))
+	for i, tag := range tags {
+		lowerTags[i] = []byte(strings.ToLower(tag))
+	}
+	return func(r *http.Request, body []byte) ([]byte, error) {
+		val := value(r)
+		if val == "" {
+			return body, nil
+		}
+		attr := []byte(" " + key + `="` + html.EscapeString(val) + `"`)
+		return injectAttribute(body, []byte(key), attr, lowerTags), nil
+	}
+}
+
+func injectAttribute(body, key, attr []byte, tags [][]byte) []byte {
+	var result bytes.Buffer
+	result.Grow(len(body))
+	last := 0
+	for pos := 0; pos < len(body); {
+		lt := bytes.IndexByte(body[pos:], '<')
+		if lt < 0 {
+			break
+		}
+		pos += lt
+		if bytes.HasPrefix(body[pos:], []byte("<!--")) {
+			end := bytes.Index(body[pos+4:], []byte("-->"))
+			if end < 0 {
+				break
+			}
+			pos += 4 + end + 3
+			continue
+		}
+		nameEnd := pos + 1
+		for nameEnd < len(body) && isNameChar(body[nameEnd]) {
+			nameEnd++
+		}
+		tagEnd := findTagEnd(body, nameEnd)
+		if tagEnd < 0 {
+			break
+		}
+		name := bytes.ToLower(body[pos+1 : nameEnd])
+		if len(name) > 0 && containsTag(tags, name) && !hasAttribute(body[nameEnd:tagEnd], key) {
+			result.Write(body[last:nameEnd])
+			result.Write(attr)
+			last = nameEnd
+		}
+		pos = tagEnd + 1
+		if bytes.Equal(name, []byte("script")) || bytes.Equal(name, []byte("style")) {
+			// Skip raw text, which may contain '<'.
+			end := bytes.Index(bytes.ToLower(body[pos:]), append([]byte("</"), name...))
+			if end < 0 {
+				break
+			}
+			pos += end
+		}
+	}
+	if last == 0 {
+		return body
+	}
+	result.Write(body[last:])
+	return result.Bytes()
+}
+
+func isNameChar(ch byte) bool {
+	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || '0' <= ch && ch <= '9' || ch == '-'
+}
+
+// findTagEnd returns the position of the closing '>' of a tag, respecting
+// quoted attribute values, or -1.
+func findTagEnd(body []byte, pos int) int {
+	var quote byte
+	for ; pos < len(body); pos++ {
+		ch := body[pos]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == '>':
+			return pos
+		}
+	}
+	return -1
+}
+
+func containsTag(tags [][]byte, name []byte) bool {
+	for _, tag := range tags {
+		if bytes.Equal(tag, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasAttribute returns true, if the attribute part of a tag contains an
+// attribute with the given lower case key.
+func hasAttribute(attrs, key []byte) bool {
+	for pos := 0; pos < len(attrs); {
+		ch := attrs[pos]
+		switch {
+		case ch == '"' || ch == '\'':
+			end := bytes.IndexByte(attrs[pos+1:], ch)
+			if end < 0 {
+				return false
+			}
+			pos += end + 2
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' || ch == '\f' || ch == '/' || ch == '=':
+			pos++
+		default:
+			start := pos
+			for pos < len(attrs) && !bytes.ContainsRune([]byte(" \t\n\r\f/=>\"'"), rune(attrs[pos])) {
+				pos++
+			}
+			if bytes.EqualFold(attrs[start:pos], key) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,199 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+// Package transform provides a middleware functor that transforms the body of
+// a response after the handler produced it, e.g. to post-process HTML.
+package transform
+
+import (
+	"bytes"
+	"log/slog"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"t73f.de/r/webs/middleware"
+)
+
+// DefaultMaxBuffer is the default value of [Config.MaxBuffer].
+const DefaultMaxBuffer = 1 << 20
+
+// Func transforms the body of a response to the given request.
+type Func func(r *http.Request, body []byte) ([]byte, error)
+
+// Config stores all configuration data to build a transforming functor.
+type Config struct {
+	// ContentTypes lists the media types of responses to be transformed.
+	// If empty, only "text/html" is transformed.
+	ContentTypes []string
+
+	// MaxBuffer is the maximum size of a response body that is buffered for
+	// transformation. Larger bodies are sent untransformed. If not positive,
+	// DefaultMaxBuffer is used.
+	MaxBuffer int64
+
+	// Transform is the transformation function.
+	Transform Func
+
+	// Logger logs responses that could not be transformed, if not nil.
+	Logger *slog.Logger
+}
+
+// Build the Functor from the configuration.
+//
+// Matching responses are buffered, transformed, and then sent with an
+// updated "Content-Length" header. If the body was changed, an "ETag"
+// header is removed, because it does not identify the new body. If the
+// buffer limit is exceeded or if the transformation fails, the original body
+// is sent.
+func (c *Config) Build() middleware.Functor {
+	transform := c.Transform
+	if transform == nil {
+		return middleware.NilFunctor
+	}
+	contentTypes := slices.Clone(c.ContentTypes)
+	if len(contentTypes) == 0 {
+		contentTypes = []string{"text/html"}
+	}
+	maxBuffer := c.MaxBuffer
+	if maxBuffer <= 0 {
+		maxBuffer = DefaultMaxBuffer
+	}
+	logger := c.Logger
+	return middleware.Declare(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+			tw := transformWriter{
+				w:            w,
+				r:            r,
+				contentTypes: contentTypes,
+				maxBuffer:    maxBuffer,
+				logger:       logger,
+			}
+			next.ServeHTTP(&tw, r)
+			tw.finish(transform)
+		})
+	}, "transform", middleware.MemberOf(middleware.GroupBodyTransformers))
+}
+
+type writeMode uint8
+
+const (
+	modeUndecided writeMode = iota
+	modeBuffer
+	modePassthrough
+)
+
+type transformWriter struct {
+	w            http.ResponseWriter
+	r            *http.Request
+	contentTypes []string
+	maxBuffer    int64
+	logger       *slog.Logger
+
+	mode writeMode
+	code int
+	buf  bytes.Buffer
+}
+
+func (tw *transformWriter) Header() http.Header { return tw.w.Header() }
+
+func (tw *transformWriter) WriteHeader(code int) {
+	if code >= 100 && code <= 199 {
+		// Informational responses, e.g. early hints, are sent immediately.
+		tw.w.WriteHeader(code)
+		return
+	}
+	if tw.mode != modeUndecided {
+		return
+	}
+	tw.code = code
+	if bodyAllowed(code) && tw.matches() {
+		tw.mode = modeBuffer
+		return
+	}
+	tw.mode = modePassthrough
+	tw.w.WriteHeader(code)
+}
+
+func (tw *transformWriter) Write(data []byte) (int, error) {
+	if tw.mode == modeUndecided {
+		header := tw.w.Header()
+		if _, found := header["Content-Type"]; !found {
+			header.Set("Content-Type", http.DetectContentType(data))
+		}
+		tw.WriteHeader(http.StatusOK)
+	}
+	if tw.mode == modePassthrough {
+		return tw.w.Write(data)
+	}
+	if int64(tw.buf.Len()+len(data)) > tw.maxBuffer {
+		if tw.logger != nil {
+			tw.logger.InfoContext(tw.r.Context(), "response too large to transform",
+				"url", tw.r.URL, "limit", tw.maxBuffer)
+		}
+		if err := tw.passthrough(); err != nil {
+			return 0, err
+		}
+		return tw.w.Write(data)
+	}
+	return tw.buf.Write(data)
+}
+
+// passthrough sends the buffered data and switches to passthrough mode.
+func (tw *transformWriter) passthrough() error {
+	tw.mode = modePassthrough
+	tw.w.WriteHeader(tw.code)
+	_, err := tw.w.Write(tw.buf.Bytes())
+	tw.buf.Reset()
+	return err
+}
+
+func (tw *transformWriter) finish(transform Func) {
+	if tw.mode != modeBuffer {
+		return
+	}
+	body := tw.buf.Bytes()
+	result, err := transform(tw.r, body)
+	if err != nil {
+		if tw.logger != nil {
+			tw.logger.WarnContext(tw.r.Context(), "unable to transform response",
+				"url", tw.r.URL, "error", err)
+		}
+		_ = tw.passthrough()
+		return
+	}
+	header := tw.w.Header()
+	if !bytes.Equal(result, body) {
+		header.Del("Etag")
+	}
+	header.Set("Content-Length", strconv.Itoa(len(result)))
+	tw.w.WriteHeader(tw.code)
+	_, _ = tw.w.Write(result)
+}
+
+func (tw *transformWriter) matches() bool {
+	mediaType, _, err := mime.ParseMediaType(tw.w.Header().Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	return slices.Contains(tw.contentTypes, mediaType)
+}
+
+func bodyAllowed(code int) bool {
+	return code != http.StatusNoContent && code != http.StatusNotModified
+}
@@ -0,0 +1,183 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package transform_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"t73f.de/r/webs/middleware/middlewaretest"
+	"t73f.de/r/webs/middleware/transform"
+)
+
+func upper(_ *http.Request, body []byte) ([]byte, error) { return bytes.ToUpper(body), nil }
+
+func htmlHandler(body string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)-strings.Count(body, "|")))
+		w.Header().Set("ETag", `"abc"`)
+		for chunk := range strings.SplitSeq(body, "|") {
+			_, _ = io.WriteString(w, chunk)
+		}
+	})
+}
+
+func TestTransform(t *testing.T) {
+	cfg := transform.Config{Transform: upper}
+	w := httptest.NewRecorder()
+	cfg.Build()(htmlHandler("<p>|hello|</p>")).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got, exp := w.Body.String(), "<P>HELLO</P>"; got != exp {
+		t.Errorf("expected body %q, but got %q", exp, got)
+	}
+	if got := w.Header().Get("Content-Length"); got != strconv.Itoa(w.Body.Len()) {
+		t.Errorf("wrong Content-Length %q for body length %d", got, w.Body.Len())
+	}
+	if got := w.Header().Get("ETag"); got != "" {
+		t.Errorf("ETag must be removed for a changed body, but got %q", got)
+	}
+
+	// Unchanged bodies retain their ETag
+	w = httptest.NewRecorder()
+	cfg.Build()(htmlHandler("<P>HELLO</P>")).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got := w.Header().Get("ETag"); got != `"abc"` {
+		t.Errorf("ETag must be retained for an unchanged body, but got %q", got)
+	}
+
+	// Other content types are not transformed
+	w = httptest.NewRecorder()
+	cfg.Build()(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = io.WriteString(w, "hello")
+	})).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got := w.Body.String(); got != "hello" {
+		t.Errorf("plain text must not be transformed, but got %q", got)
+	}
+
+	// Sniffed content type
+	w = httptest.NewRecorder()
+	cfg.Build()(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "<!DOCTYPE html><p>hi</p>")
+	})).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got, exp := w.Body.String(), "<!DOCTYPE HTML><P>HI</P>"; got != exp {
+		t.Errorf("expected body %q, but got %q", exp, got)
+	}
+}
+
+func TestTransformCapFallback(t *testing.T) {
+	logger, recs := middlewaretest.CaptureLogs(t)
+	cfg := transform.Config{Transform: upper, MaxBuffer: 8, Logger: logger}
+	w := httptest.NewRecorder()
+	body := "<p>|hello|</p>"
+	cfg.Build()(htmlHandler(body)).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got, exp := w.Body.String(), "<p>hello</p>"; got != exp {
+		t.Errorf("expected untouched body %q, but got %q", exp, got)
+	}
+	if got := w.Header().Get("Content-Length"); got != "12" {
+		t.Errorf("expected original Content-Length, but got %q", got)
+	}
+	if got := w.Header().Get("ETag"); got != `"abc"` {
+		t.Errorf("expected original ETag, but got %q", got)
+	}
+	if got := len(recs.WithLevel(slog.LevelInfo)); got != 1 {
+		t.Errorf("expected one log record, but got %d", got)
+	}
+}
+
+func TestTransformErrorFallback(t *testing.T) {
+	logger, recs := middlewaretest.CaptureLogs(t)
+	cfg := transform.Config{
+		Transform: func(*http.Request, []byte) ([]byte, error) { return nil, errors.New("failed") },
+		Logger:    logger,
+	}
+	w := httptest.NewRecorder()
+	cfg.Build()(htmlHandler("<p>|hello|</p>")).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got, exp := w.Body.String(), "<p>hello</p>"; got != exp {
+		t.Errorf("expected untouched body %q, but got %q", exp, got)
+	}
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status 200, but got %d", w.Code)
+	}
+	if got := len(recs.WithLevel(slog.LevelWarn)); got != 1 {
+		t.Errorf("expected one warning, but got %d", got)
+	}
+}
+
+func TestTransformStatus(t *testing.T) {
+	cfg := transform.Config{Transform: upper}
+	w := httptest.NewRecorder()
+	cfg.Build()(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, "missing")
+	})).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusNotFound || w.Body.String() != "MISSING" {
+		t.Errorf("unexpected response %d %q", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	cfg.Build()(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusNotModified)
+	})).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusNotModified || w.Header().Get("Content-Length") != "" {
+		t.Errorf("unexpected response %d %v", w.Code, w.Header())
+	}
+}
+
+func TestInjectAttribute(t *testing.T) {
+	nonce := func(*http.Request) string { return "n&1" }
+	inject := transform.InjectAttribute("nonce", nonce, "script", "style")
+	testcases := []struct {
+		name string
+		inp  string
+		exp  string
+	}{
+		{"empty", "", ""},
+		{"none", "<p>text</p>", "<p>text</p>"},
+		{"script", `<script src="a.js"></script>`, `<script nonce="n&amp;1" src="a.js"></script>`},
+		{"upper", `<SCRIPT>x</SCRIPT>`, `<SCRIPT nonce="n&amp;1">x</SCRIPT>`},
+		{"both", `<style>p{}</style><script>`, `<style nonce="n&amp;1">p{}</style><script nonce="n&amp;1">`},
+		{"present", `<script NONCE="x">`, `<script NONCE="x">`},
+		{"quoted", `<script data-x="nonce">`, `<script nonce="n&amp;1" data-x="nonce">`},
+		{"quoted-gt", `<p title="a>b"><script>`, `<p title="a>b"><script nonce="n&amp;1">`},
+		{"prefix", `<scripts><scripted>`, `<scripts><scripted>`},
+		{"comment", `<!-- <script> --><script>`, `<!-- <script> --><script nonce="n&amp;1">`},
+		{"raw-text", `<script>if (a<style) {}</script>`, `<script nonce="n&amp;1">if (a<style) {}</script>`},
+		{"unclosed", `<p><script`, `<p><script`},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := inject(nil, []byte(tc.inp))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.exp {
+				t.Errorf("expected %q, but got %q", tc.exp, got)
+			}
+		})
+	}
+
+	noNonce := transform.InjectAttribute("nonce", func(*http.Request) string { return "" }, "script")
+	if got, _ := noNonce(nil, []byte("<script>")); string(got) != "<script>" {
+		t.Errorf("empty value must not change body, but got %q", got)
+	}
+}
@@ -0,0 +1,173 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package qrcode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"time"
+)
+
+// FrameSpec specifies one frame of an animated QR code.
+type FrameSpec struct {
+	Content string
+	Level   RecoveryLevel
+	Delay   time.Duration // how long the frame is shown
+}
+
+// APNG returns an animated PNG that shows the QR codes of all frames in turn,
+// looping forever. The first frame is also the default image, which is shown
+// by viewers that do not support animated PNG.
+//
+// All frames must result in QR codes of the same version. The size is
+// interpreted as in [QRCode.Image].
+func APNG(frames []FrameSpec, size int) ([]byte, error) {
+	if len(frames) == 0 {
+		return nil, errors.New("no frames to encode")
+	}
+
+	images := make([][]byte, len(frames))
+	version := 0
+	for i, frame := range frames {
+		q, err := New(frame.Content, frame.Level)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			version = q.VersionNumber
+		} else if q.VersionNumber != version {
+			return nil, fmt.Errorf("frame %d has version %d, expected %d", i, q.VersionNumber, version)
+		}
+		if images[i], err = q.PNG(size); err != nil {
+			return nil, err
+		}
+	}
+
+	var b bytes.Buffer
+	var seq uint32
+	var width, height uint32
+	for i, img := range images {
+		chunks, err := pngChunks(img)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			b.Write(pngSignature)
+		}
+		for _, ch := range chunks {
+			switch ch.typ {
+			case "IHDR":
+				if i == 0 {
+					width = binary.BigEndian.Uint32(ch.data[0:4])
+					height = binary.BigEndian.Uint32(ch.data[4:8])
+					writePNGChunk(&b, ch.typ, ch.data)
+
+					var actl [8]byte
+					binary.BigEndian.PutUint32(actl[0:4], uint32(len(images)))
+					binary.BigEndian.PutUint32(actl[4:8], 0) // loop forever
+					writePNGChunk(&b, "acTL", actl[:])
+				}
+				writePNGChunk(&b, "fcTL", frameControl(seq, width, height, frames[i].Delay))
+				seq++
+			case "IDAT":
+				if i == 0 {
+					writePNGChunk(&b, ch.typ, ch.data)
+				} else {
+					fdat := make([]byte, 4+len(ch.data))
+					binary.BigEndian.PutUint32(fdat[0:4], seq)
+					copy(fdat[4:], ch.data)
+					writePNGChunk(&b, "fdAT", fdat)
+					seq++
+				}
+			case "IEND":
+				// Written after the last frame.
+			default:
+				if i == 0 {
+					writePNGChunk(&b, ch.typ, ch.data)
+				}
+			}
+		}
+	}
+	writePNGChunk(&b, "IEND", nil)
+	return b.Bytes(), nil
+}
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+type pngChunk struct {
+	typ  string
+	data []byte
+}
+
+// pngChunks splits an encoded PNG image into its chunks.
+func pngChunks(img []byte) ([]pngChunk, error) {
+	if !bytes.HasPrefix(img, pngSignature) {
+		return nil, errors.New("not a PNG image")
+	}
+	var result []pngChunk
+	for pos := len(pngSignature); pos < len(img); {
+		if pos+8 > len(img) {
+			return nil, errors.New("truncated PNG chunk header")
+		}
+		length := int(binary.BigEndian.Uint32(img[pos : pos+4]))
+		end := pos + 8 + length + 4
+		if length < 0 || end > len(img) {
+			return nil, errors.New("truncated PNG chunk")
+		}
+		result = append(result, pngChunk{
+			typ:  string(img[pos+4 : pos+8]),
+			data: img[pos+8 : pos+8+length],
+		})
+		pos = end
+	}
+	return result, nil
+}
+
+func writePNGChunk(b *bytes.Buffer, typ string, data []byte) {
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], uint32(len(data)))
+	b.Write(buf[:])
+	crc := crc32.NewIEEE()
+	crc.Write([]byte(typ))
+	crc.Write(data)
+	b.WriteString(typ)
+	b.Write(data)
+	binary.BigEndian.PutUint32(buf[:], crc.Sum32())
+	b.Write(buf[:])
+}
+
+// frameControl returns the data of a "fcTL" chunk.
+func frameControl(seq, width, height uint32, delay time.Duration) []byte {
+	const (
+		disposeNone = 0
+		blendSource = 0
+	)
+	ms := delay.Milliseconds()
+	ms = max(0, min(ms, 1<<16-1))
+
+	var fctl [26]byte
+	binary.BigEndian.PutUint32(fctl[0:4], seq)
+	binary.BigEndian.PutUint32(fctl[4:8], width)
+	binary.BigEndian.PutUint32(fctl[8:12], height)
+	binary.BigEndian.PutUint32(fctl[12:16], 0) // x offset
+	binary.BigEndian.PutUint32(fctl[16:20], 0) // y offset
+	binary.BigEndian.PutUint16(fctl[20:22], uint16(ms))
+	binary.BigEndian.PutUint16(fctl[22:24], 1000)
+	fctl[24] = disposeNone
+	fctl[25] = blendSource
+	return fctl[:]
+}
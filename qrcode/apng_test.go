@@ -0,0 +1,112 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package qrcode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image/png"
+	"testing"
+	"time"
+)
+
+func TestAPNG(t *testing.T) {
+	frames := []FrameSpec{
+		{Content: "123456", Level: Medium, Delay: 30 * time.Second},
+		{Content: "234567", Level: Medium, Delay: 30 * time.Second},
+		{Content: "345678", Level: Medium, Delay: 30 * time.Second},
+	}
+	data, err := APNG(frames, -2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Validate chunk structure.
+	pos := len(pngSignature)
+	var types []string
+	var numFrames, numFCTL uint32
+	expSeq := uint32(0)
+	for pos < len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		typ := string(data[pos+4 : pos+8])
+		chunk := data[pos+8 : pos+8+length]
+		crc := binary.BigEndian.Uint32(data[pos+8+length:])
+		if got := crc32.ChecksumIEEE(data[pos+4 : pos+8+length]); got != crc {
+			t.Errorf("chunk %q: invalid CRC %x, expected %x", typ, crc, got)
+		}
+		switch typ {
+		case "acTL":
+			numFrames = binary.BigEndian.Uint32(chunk[0:4])
+			if plays := binary.BigEndian.Uint32(chunk[4:8]); plays != 0 {
+				t.Errorf("animation must loop forever, but got %d plays", plays)
+			}
+		case "fcTL", "fdAT":
+			if seq := binary.BigEndian.Uint32(chunk[0:4]); seq != expSeq {
+				t.Errorf("chunk %q: expected sequence %d, but got %d", typ, expSeq, seq)
+			}
+			expSeq++
+			if typ == "fcTL" {
+				numFCTL++
+				if delay := binary.BigEndian.Uint16(chunk[20:22]); delay != 30000 {
+					t.Errorf("expected delay 30000ms, but got %d", delay)
+				}
+			}
+		}
+		types = append(types, typ)
+		pos += 8 + length + 4
+	}
+	if numFrames != uint32(len(frames)) || numFCTL != numFrames {
+		t.Errorf("expected %d frames, got %d acTL frames and %d fcTL chunks", len(frames), numFrames, numFCTL)
+	}
+	if len(types) < 4 || types[0] != "IHDR" || types[1] != "acTL" || types[2] != "fcTL" || types[len(types)-1] != "IEND" {
+		t.Errorf("unexpected chunk order: %v", types)
+	}
+
+	// Non-APNG viewers must see the first frame.
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := New(frames[0].Content, frames[0].Level)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := q.Image(-2)
+	if img.Bounds() != exp.Bounds() {
+		t.Fatalf("expected bounds %v, but got %v", exp.Bounds(), img.Bounds())
+	}
+	for y := range exp.Bounds().Dy() {
+		for x := range exp.Bounds().Dx() {
+			r1, g1, b1, _ := img.At(x, y).RGBA()
+			r2, g2, b2, _ := exp.At(x, y).RGBA()
+			if r1 != r2 || g1 != g2 || b1 != b2 {
+				t.Fatalf("pixel (%d,%d) differs", x, y)
+			}
+		}
+	}
+}
+
+func TestAPNGErrors(t *testing.T) {
+	if _, err := APNG(nil, 100); err == nil {
+		t.Error("error expected for empty frames")
+	}
+	frames := []FrameSpec{
+		{Content: "1", Level: Low},
+		{Content: "https://example.org/a/very/long/url/that/needs/a/larger/version", Level: Highest},
+	}
+	if _, err := APNG(frames, 100); err == nil {
+		t.Error("error expected for different versions")
+	}
+}
@@ -0,0 +1,82 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package qrcode
+
+import (
+	"bytes"
+	"context"
+	"image/png"
+	"io"
+)
+
+// NewContext constructs a QRCode like [New], but completely encodes it,
+// while checking the context for cancellation.
+//
+// Cancellation is checked between the expensive phases: before and after
+// the data encoding, before the error correction, and before the evaluation
+// of each of the eight data masks. A phase itself is not interrupted. If the
+// context is done, its error is returned.
+func NewContext(ctx context.Context, content string, level RecoveryLevel) (*QRCode, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	q, err := New(content, level)
+	if err != nil {
+		return nil, err
+	}
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err = q.encodeContext(ctx); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// PNGContext returns the QR Code as a PNG image, like [QRCode.PNG], but
+// checks the context for cancellation, see [QRCode.WriteContext].
+func (q *QRCode) PNGContext(ctx context.Context, size int) ([]byte, error) {
+	var b bytes.Buffer
+	if err := q.WriteContext(ctx, size, &b); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// WriteContext encodes the QR Code as a PNG image to the given writer, like
+// [QRCode.Write], but checks the context for cancellation.
+//
+// If the QR code is not encoded yet, cancellation is checked like in
+// [NewContext]. In addition, it is checked before the image is drawn and
+// before the PNG image is encoded. Nothing is written, if the context is
+// done.
+func (q *QRCode) WriteContext(ctx context.Context, size int, w io.Writer) error {
+	if q.Strict {
+		if err := q.CheckColors(); err != nil {
+			return err
+		}
+	}
+	if err := q.encodeContext(ctx); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	img := q.Image(size)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	return encoder.Encode(w, img)
+}
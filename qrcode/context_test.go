@@ -0,0 +1,125 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package qrcode
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+// phaseContext is a context that is canceled after its error was checked a
+// given number of times, i.e. at a specific phase boundary.
+type phaseContext struct {
+	context.Context
+	remaining int
+	checks    int
+}
+
+func (pc *phaseContext) Err() error {
+	pc.checks++
+	if pc.remaining <= 0 {
+		return context.Canceled
+	}
+	pc.remaining--
+	return nil
+}
+
+func TestNewContextPhases(t *testing.T) {
+	const content = "https://example.com/cancellation"
+	ref, err := New(content, Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refBitmap := ref.Bitmap()
+
+	// Count the phase boundaries of a complete encoding.
+	pc := &phaseContext{Context: context.Background(), remaining: 1 << 30}
+	if _, err = NewContext(pc, content, Medium); err != nil {
+		t.Fatal(err)
+	}
+	numChecks := pc.checks
+	if numChecks < 8+3 {
+		t.Fatalf("at least one check per mask expected, got %d", numChecks)
+	}
+
+	for phase := range numChecks {
+		pc = &phaseContext{Context: context.Background(), remaining: phase}
+		q, err := NewContext(pc, content, Medium)
+		if !errors.Is(err, context.Canceled) || q != nil {
+			t.Errorf("phase %d: cancellation expected, got %v/%v", phase, q, err)
+		}
+		if pc.checks != phase+1 {
+			t.Errorf("phase %d: must return at first cancellation, but got %d checks", phase, pc.checks)
+		}
+	}
+
+	// Cancellation must not affect later encodings.
+	q, err := NewContext(context.Background(), content, Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := q.Bitmap(); !slices.EqualFunc(got, refBitmap, slices.Equal) {
+		t.Error("bitmap differs from reference")
+	}
+}
+
+func TestWriteContextResume(t *testing.T) {
+	const content = "resume after cancellation"
+	ref, err := New(content, High)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refPNG, err := ref.PNG(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for phase := range 12 {
+		q, err := New(content, High)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		pc := &phaseContext{Context: context.Background(), remaining: phase}
+		if err = q.WriteContext(pc, 100, &buf); err == nil {
+			continue // all phases passed
+		}
+		if !errors.Is(err, context.Canceled) || buf.Len() != 0 {
+			t.Errorf("phase %d: cancellation without output expected, got %v/%d bytes", phase, err, buf.Len())
+		}
+
+		// The QR code is still usable after a cancellation.
+		got, err := q.PNG(100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, refPNG) {
+			t.Errorf("phase %d: PNG differs from reference after cancellation", phase)
+		}
+	}
+}
+
+func TestNewContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewContext(ctx, "content", Low); !errors.Is(err, context.Canceled) {
+		t.Errorf("context.Canceled expected, got %v", err)
+	}
+	if _, err := NewContext(context.Background(), "", Low); !errors.Is(err, ErrEmptyContent) {
+		t.Errorf("ErrEmptyContent expected, got %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package qrcode
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+)
+
+// MinContrastRatio is the minimum contrast ratio between foreground and
+// background color that is considered to be scannable. It is the value that
+// WCAG 2 requires for normal text (level AA).
+const MinContrastRatio = 4.5
+
+// ErrLowContrast is returned, if the colors of a QR code do not provide enough
+// contrast to be scanned reliably.
+var ErrLowContrast = errors.New("insufficient color contrast")
+
+// ContrastOK returns the contrast ratio of both colors, as defined by WCAG 2,
+// and whether the ratio is at least [MinContrastRatio]. The ratio ranges from
+// 1 (no contrast) to 21 (black and white).
+func ContrastOK(fg, bg color.Color) (ratio float64, ok bool) {
+	l1, l2 := relativeLuminance(fg), relativeLuminance(bg)
+	if l1 < l2 {
+		l1, l2 = l2, l1
+	}
+	ratio = (l1 + 0.05) / (l2 + 0.05)
+	return ratio, ratio >= MinContrastRatio
+}
+
+// relativeLuminance computes the relative luminance of a color, according to
+// https://www.w3.org/TR/WCAG21/#dfn-relative-luminance
+func relativeLuminance(c color.Color) float64 {
+	r, g, b, _ := c.RGBA()
+	return 0.2126*linearChannel(r) + 0.7152*linearChannel(g) + 0.0722*linearChannel(b)
+}
+
+func linearChannel(v uint32) float64 {
+	c := float64(v) / 0xffff
+	if c <= 0.04045 {
+		return c / 12.92
+	}
+	return math.Pow((c+0.055)/1.055, 2.4)
+}
+
+// CheckColors returns an error wrapping [ErrLowContrast], if the foreground
+// and the background color do not provide enough contrast. It is also an
+// error if the foreground color is lighter than the background color, since
+// this results in a symbol with an unexpected polarity. Use Inverted to
+// render light modules on a dark background.
+func (q *QRCode) CheckColors() error {
+	ratio, ok := ContrastOK(q.ForegroundColor, q.BackgroundColor)
+	if !ok {
+		return fmt.Errorf("%w: ratio %.2f is below %.1f", ErrLowContrast, ratio, MinContrastRatio)
+	}
+	if relativeLuminance(q.ForegroundColor) > relativeLuminance(q.BackgroundColor) {
+		return fmt.Errorf("%w: foreground color is lighter than background color", ErrLowContrast)
+	}
+	return nil
+}
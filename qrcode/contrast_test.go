@@ -0,0 +1,104 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package qrcode
+
+import (
+	"errors"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestContrastOK(t *testing.T) {
+	testcases := []struct {
+		name   string
+		fg, bg color.Color
+		ratio  float64
+		ok     bool
+	}{
+		{"black-white", color.Black, color.White, 21, true},
+		{"white-black", color.White, color.Black, 21, true},
+		{"white-white", color.White, color.White, 1, false},
+		{"gray-white", color.RGBA{0x77, 0x77, 0x77, 0xff}, color.White, 4.48, false},
+		{"darkgray-white", color.RGBA{0x76, 0x76, 0x76, 0xff}, color.White, 4.54, true},
+		{"blue-white", color.RGBA{0x00, 0x00, 0xff, 0xff}, color.White, 8.59, true},
+		{"red-white", color.RGBA{0xff, 0x00, 0x00, 0xff}, color.White, 4.00, false},
+		{"yellow-black", color.RGBA{0xff, 0xff, 0x00, 0xff}, color.Black, 19.56, true},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ratio, ok := ContrastOK(tc.fg, tc.bg)
+			if math.Abs(ratio-tc.ratio) > 0.01 {
+				t.Errorf("expected ratio %.2f, but got %.4f", tc.ratio, ratio)
+			}
+			if ok != tc.ok {
+				t.Errorf("expected ok=%v, but got %v", tc.ok, ok)
+			}
+		})
+	}
+}
+
+func TestCheckColors(t *testing.T) {
+	q, err := New("12345", Low)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = q.CheckColors(); err != nil {
+		t.Errorf("default colors must be ok: %v", err)
+	}
+	q.ForegroundColor, q.BackgroundColor = q.BackgroundColor, q.ForegroundColor
+	if err = q.CheckColors(); !errors.Is(err, ErrLowContrast) {
+		t.Errorf("swapped colors must be detected, but got %v", err)
+	}
+	if _, err = q.PNG(-1); err != nil {
+		t.Errorf("non-strict PNG must not fail: %v", err)
+	}
+	q.Strict = true
+	if _, err = q.PNG(-1); !errors.Is(err, ErrLowContrast) {
+		t.Errorf("strict PNG must fail, but got %v", err)
+	}
+	q.ForegroundColor = color.RGBA{0xdd, 0xdd, 0xdd, 0xff}
+	if _, err = q.PNG(-1); !errors.Is(err, ErrLowContrast) {
+		t.Errorf("strict PNG must fail for low contrast, but got %v", err)
+	}
+}
+
+func TestInvertedImage(t *testing.T) {
+	q, err := New("http://example.org", Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	normal := q.Image(-2)
+	q.Inverted = true
+	inverted := q.Image(-2)
+	if normal.Bounds() != inverted.Bounds() {
+		t.Fatalf("bounds differ: %v vs %v", normal.Bounds(), inverted.Bounds())
+	}
+	bitmap := q.Bitmap()
+	for y := range normal.Bounds().Dy() {
+		for x := range normal.Bounds().Dx() {
+			n, i := color.GrayModel.Convert(normal.At(x, y)), color.GrayModel.Convert(inverted.At(x, y))
+			if n == i {
+				t.Fatalf("pixel (%d,%d) not inverted: %v", x, y, n)
+			}
+			if exp := bitmap[y/2][x/2]; (n == color.Gray{0}) != exp {
+				t.Fatalf("pixel (%d,%d): expected set=%v", x, y, exp)
+			}
+		}
+	}
+	// Border is drawn in foreground color.
+	if c := color.GrayModel.Convert(inverted.At(0, 0)); c != (color.Gray{0}) {
+		t.Errorf("border must be dark, but got %v", c)
+	}
+}
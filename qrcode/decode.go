@@ -0,0 +1,525 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package qrcode
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"math/bits"
+	"strings"
+
+	"t73f.de/r/webs/qrcode/internal/reedsolomon"
+)
+
+// Decode reads the content of a QR code from an image, e.g. to verify a QR
+// code after a logo was placed over it.
+//
+// Only clean, axis-aligned images are supported, like the ones produced by
+// this package: the symbol is located by the bounding box of its dark
+// pixels, and each module is sampled at its center. Light symbols on a dark
+// background are supported too. Camera images are out of scope.
+func Decode(img image.Image) (string, error) {
+	content, err := decodeImage(img, false)
+	if errors.Is(err, ErrSymbolNotFound) || errors.Is(err, ErrUnreadable) {
+		if content, err2 := decodeImage(img, true); err2 == nil {
+			return content, nil
+		}
+	}
+	return content, err
+}
+
+func decodeImage(img image.Image, inverted bool) (string, error) {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width == 0 || height == 0 {
+		return "", ErrSymbolNotFound
+	}
+	gray := make([]uint8, width*height)
+	lo, hi := uint8(math.MaxUint8), uint8(0)
+	for y := range height {
+		for x := range width {
+			g := color.GrayModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.Gray).Y
+			gray[y*width+x] = g
+			lo, hi = min(lo, g), max(hi, g)
+		}
+	}
+	if lo == hi {
+		return "", ErrSymbolNotFound
+	}
+	threshold := (uint16(lo) + uint16(hi)) / 2
+	dark := func(x, y int) bool { return (uint16(gray[y*width+x]) <= threshold) != inverted }
+
+	x0, y0, x1, y1, found := boundingBox(width, height, dark)
+	if !found {
+		return "", ErrSymbolNotFound
+	}
+
+	// The top row of the symbol starts with the seven dark modules of the
+	// top left finder pattern. They give an estimate of the module size.
+	run := 0
+	for x := x0; x <= x1 && dark(x, y0); x++ {
+		run++
+	}
+	symbolSize := validSymbolSize(float64(x1-x0+1) / (float64(run) / float64(finderPatternSize)))
+	if symbolSize == 0 || (y1-y0+1)*2 < (x1-x0+1) || (x1-x0+1)*2 < (y1-y0+1) {
+		return "", ErrSymbolNotFound
+	}
+	moduleWidth := float64(x1-x0+1) / float64(symbolSize)
+	moduleHeight := float64(y1-y0+1) / float64(symbolSize)
+
+	grid := make([][]bool, symbolSize)
+	for j := range grid {
+		grid[j] = make([]bool, symbolSize)
+		y := y0 + int((float64(j)+0.5)*moduleHeight)
+		for i := range grid[j] {
+			grid[j][i] = dark(x0+int((float64(i)+0.5)*moduleWidth), y)
+		}
+	}
+	return decodeSymbol(grid)
+}
+
+// validSymbolSize returns the symbol size of a valid version that is nearest
+// to the given estimate, or zero if there is none.
+func validSymbolSize(estimate float64) int {
+	if math.IsNaN(estimate) || math.IsInf(estimate, 0) {
+		return 0
+	}
+	version := int(math.Round((estimate - 17) / 4))
+	if version < MinVersion || version > MaxVersion {
+		return 0
+	}
+	return 17 + 4*version
+}
+
+// boundingBox returns the smallest rectangle that contains all dark cells.
+func boundingBox(width, height int, dark func(x, y int) bool) (x0, y0, x1, y1 int, found bool) {
+	x0, y0, x1, y1 = width, height, -1, -1
+	for y := range height {
+		for x := range width {
+			if dark(x, y) {
+				x0, y0, x1, y1 = min(x0, x), min(y0, y), max(x1, x), max(y1, y)
+			}
+		}
+	}
+	return x0, y0, x1, y1, x1 >= 0
+}
+
+// DecodeBitmap reads the content of a QR code from a bitmap, as returned by
+// [QRCode.Bitmap]: bitmap[y][x] is true, if the module at (x, y) is dark. The
+// bitmap may include a quiet zone.
+//
+// Errors in the data modules are corrected, as long as they do not exceed
+// the recovery capacity of the symbol. The format and version information
+// are read from both copies, tolerating up to three wrong modules.
+func DecodeBitmap(bitmap [][]bool) (string, error) {
+	height := len(bitmap)
+	if height == 0 {
+		return "", ErrSymbolNotFound
+	}
+	width := len(bitmap[0])
+	for _, row := range bitmap {
+		if len(row) != width {
+			return "", fmt.Errorf("%w: rows of different length", ErrSymbolNotFound)
+		}
+	}
+	x0, y0, x1, y1, found := boundingBox(width, height, func(x, y int) bool { return bitmap[y][x] })
+	if !found {
+		return "", ErrSymbolNotFound
+	}
+	symbolSize := x1 - x0 + 1
+	if y1-y0+1 != symbolSize || validSymbolSize(float64(symbolSize)) != symbolSize {
+		return "", fmt.Errorf("%w: invalid size %dx%d", ErrSymbolNotFound, symbolSize, y1-y0+1)
+	}
+	grid := make([][]bool, symbolSize)
+	for j := range grid {
+		grid[j] = bitmap[y0+j][x0 : x1+1]
+	}
+	return decodeSymbol(grid)
+}
+
+// decodeSymbol decodes a symbol without quiet zone: grid[y][x] is true, if
+// the module at (x, y) is dark.
+func decodeSymbol(grid [][]bool) (string, error) {
+	symbolSize := len(grid)
+	level, mask, err := readFormatInfo(grid)
+	if err != nil {
+		return "", err
+	}
+	versionNumber, err := readVersionInfo(grid)
+	if err != nil {
+		return "", err
+	}
+	var version *qrCodeVersion
+	for i := range versions {
+		if v := &versions[i]; v.version == versionNumber && v.level == level {
+			version = v
+			break
+		}
+	}
+	if version == nil || version.symbolSize() != symbolSize {
+		return "", fmt.Errorf("%w: unknown version %d", ErrUnreadable, versionNumber)
+	}
+
+	// Lay down the function patterns, to find the data modules.
+	m := &regularSymbol{
+		version:    *version,
+		mask:       mask,
+		symbol:     newSymbol(symbolSize, 0),
+		symbolSize: symbolSize,
+	}
+	m.addFinderPatterns()
+	m.addAlignmentPatterns()
+	m.addTimingPatterns()
+	m.addFormatInfo()
+	m.addVersionInfo()
+
+	numCodewords := 0
+	for _, b := range version.block {
+		numCodewords += b.numBlocks * b.numCodewords
+	}
+	codewords := make([]byte, numCodewords)
+	i := 0
+	for x, y := range m.dataModules() {
+		if i == 8*numCodewords {
+			break // remainder bits
+		}
+		if grid[y][x] != maskBit(mask, x, y) {
+			codewords[i/8] |= 0x80 >> (i % 8)
+		}
+		i++
+	}
+
+	data, err := correctBlocks(version, codewords)
+	if err != nil {
+		return "", err
+	}
+	return decodeSegments(data, versionNumber)
+}
+
+// readFormatInfo reads both copies of the format information and returns
+// the recovery level and the mask pattern of the nearest valid format.
+func readFormatInfo(grid [][]bool) (RecoveryLevel, int, error) {
+	size := len(grid)
+	fpSize := finderPatternSize
+	var first, second uint32
+	setBit := func(value *uint32, i, x, y int) {
+		if grid[y][x] {
+			*value |= 1 << i
+		}
+	}
+	for i := 0; i <= 5; i++ {
+		setBit(&first, i, fpSize+1, i)
+	}
+	setBit(&first, 6, fpSize+1, fpSize)
+	setBit(&first, 7, fpSize+1, fpSize+1)
+	setBit(&first, 8, fpSize, fpSize+1)
+	for i := 9; i <= 14; i++ {
+		setBit(&first, i, 14-i, fpSize+1)
+	}
+	for i := 0; i <= 7; i++ {
+		setBit(&second, i, size-i-1, fpSize+1)
+	}
+	for i := 8; i <= 14; i++ {
+		setBit(&second, i, fpSize+1, size-fpSize+i-8)
+	}
+
+	formatID, distance := -1, 4
+	for id, seq := range formatBitSequence {
+		for _, value := range []uint32{first, second} {
+			if d := bits.OnesCount32(value ^ seq.regular); d < distance {
+				formatID, distance = id, d
+			}
+		}
+	}
+	if formatID < 0 {
+		return 0, 0, fmt.Errorf("%w: format information", ErrUnreadable)
+	}
+	var level RecoveryLevel
+	switch formatID >> 3 {
+	case 0b01:
+		level = Low
+	case 0b00:
+		level = Medium
+	case 0b11:
+		level = High
+	case 0b10:
+		level = Highest
+	}
+	return level, formatID & 0x7, nil
+}
+
+// readVersionInfo returns the version number of the symbol. Symbols of
+// version 7 and above store it in two copies, the others are identified by
+// their size.
+func readVersionInfo(grid [][]bool) (int, error) {
+	size := len(grid)
+	versionNumber := (size - 17) / 4
+	if versionNumber < 7 {
+		return versionNumber, nil
+	}
+	var first, second uint32
+	for i := range versionInfoLengthBits {
+		if grid[size-finderPatternSize-4+i%3][i/3] {
+			first |= 1 << i
+		}
+		if grid[i/3][size-finderPatternSize-4+i%3] {
+			second |= 1 << i
+		}
+	}
+	result, distance := -1, 4
+	for v := 7; v < len(versionBitSequence); v++ {
+		for _, value := range []uint32{first, second} {
+			if d := bits.OnesCount32(value ^ versionBitSequence[v]); d < distance {
+				result, distance = v, d
+			}
+		}
+	}
+	if result != versionNumber {
+		return 0, fmt.Errorf("%w: version information", ErrUnreadable)
+	}
+	return result, nil
+}
+
+// correctBlocks deinterleaves the codewords into blocks, corrects each
+// block, and returns the concatenated data codewords.
+func correctBlocks(version *qrCodeVersion, codewords []byte) ([]byte, error) {
+	type dataBlock struct {
+		codewords        []byte
+		numDataCodewords int
+	}
+	var blocks []dataBlock
+	maxCodewords := 0
+	for _, b := range version.block {
+		for range b.numBlocks {
+			blocks = append(blocks, dataBlock{make([]byte, 0, b.numCodewords), b.numDataCodewords})
+		}
+		maxCodewords = max(maxCodewords, b.numCodewords)
+	}
+
+	// Data codewords, followed by error correction codewords, see
+	// [QRCode.encodeBlocks].
+	pos := 0
+	for i := range maxCodewords {
+		for j := range blocks {
+			if i < blocks[j].numDataCodewords {
+				blocks[j].codewords = append(blocks[j].codewords, codewords[pos])
+				pos++
+			}
+		}
+	}
+	for i := range maxCodewords {
+		for j := range blocks {
+			if i < cap(blocks[j].codewords)-blocks[j].numDataCodewords {
+				blocks[j].codewords = append(blocks[j].codewords, codewords[pos])
+				pos++
+			}
+		}
+	}
+
+	data := make([]byte, 0, version.numDataBits()/8)
+	for _, b := range blocks {
+		if _, err := reedsolomon.Correct(b.codewords, len(b.codewords)-b.numDataCodewords); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrUnreadable, err)
+		}
+		data = append(data, b.codewords[:b.numDataCodewords]...)
+	}
+	return data, nil
+}
+
+// bitReader reads bits from a byte slice, most significant bit first.
+type bitReader struct {
+	data []byte
+	pos  int
+}
+
+func (br *bitReader) remaining() int { return 8*len(br.data) - br.pos }
+
+func (br *bitReader) read(n int) (uint32, bool) {
+	if n > br.remaining() {
+		return 0, false
+	}
+	var result uint32
+	for range n {
+		result <<= 1
+		if br.data[br.pos/8]&(0x80>>(br.pos%8)) != 0 {
+			result |= 1
+		}
+		br.pos++
+	}
+	return result, true
+}
+
+// alphanumericCharacters lists the characters of alphanumeric mode, indexed
+// by their value, see encodeAlphanumericCharacter.
+const alphanumericCharacters = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+// decodeSegments decodes the data segments of the corrected data codewords.
+func decodeSegments(data []byte, versionNumber int) (string, error) {
+	encoder := dataEncoderFor(versionNumber)
+	br := bitReader{data: data}
+	errTruncated := fmt.Errorf("%w: truncated data segment", ErrUnreadable)
+	var sb strings.Builder
+	fnc1 := false
+
+	for br.remaining() >= 4 {
+		indicator, _ := br.read(4)
+		var mode dataMode
+		switch indicator {
+		case 0b0000: // terminator
+			return sb.String(), nil
+		case 0b0001:
+			mode = dataModeNumeric
+		case 0b0010:
+			mode = dataModeAlphanumeric
+		case 0b0100:
+			mode = dataModeByte
+		case 0b1000:
+			mode = dataModeKanji
+		case 0b0111: // ECI: the designator is skipped, the content is returned as is
+			first, ok := br.read(8)
+			if !ok {
+				return "", errTruncated
+			}
+			rest := 0
+			switch {
+			case first&0x80 == 0:
+			case first&0xc0 == 0x80:
+				rest = 8
+			case first&0xe0 == 0xc0:
+				rest = 16
+			default:
+				return "", fmt.Errorf("%w: invalid ECI designator", ErrUnreadable)
+			}
+			if _, ok = br.read(rest); !ok {
+				return "", errTruncated
+			}
+			continue
+		case 0b0101: // FNC1 in first position
+			fnc1 = true
+			continue
+		case 0b1001: // FNC1 in second position, followed by an application indicator
+			if _, ok := br.read(8); !ok {
+				return "", errTruncated
+			}
+			fnc1 = true
+			continue
+		default:
+			return "", fmt.Errorf("%w: unknown mode indicator %04b", ErrUnreadable, indicator)
+		}
+
+		count, ok := br.read(encoder.charCountBits(mode))
+		if !ok {
+			return "", errTruncated
+		}
+		if err := decodeSegment(&sb, &br, mode, int(count), fnc1); err != nil {
+			return "", err
+		}
+	}
+	return sb.String(), nil
+}
+
+// decodeSegment decodes count characters of a data segment in the given mode.
+func decodeSegment(sb *strings.Builder, br *bitReader, mode dataMode, count int, fnc1 bool) error {
+	errTruncated := fmt.Errorf("%w: truncated data segment", ErrUnreadable)
+	errInvalid := fmt.Errorf("%w: invalid data segment", ErrUnreadable)
+	switch mode {
+	case dataModeNumeric:
+		for count > 0 {
+			digits := min(count, 3)
+			value, ok := br.read(1 + 3*digits)
+			if !ok {
+				return errTruncated
+			}
+			s := fmt.Sprintf("%0*d", digits, value)
+			if len(s) != digits {
+				return errInvalid
+			}
+			sb.WriteString(s)
+			count -= digits
+		}
+	case dataModeAlphanumeric:
+		var segment []byte
+		for count > 0 {
+			if count == 1 {
+				value, ok := br.read(6)
+				if !ok {
+					return errTruncated
+				}
+				if value >= 45 {
+					return errInvalid
+				}
+				segment = append(segment, alphanumericCharacters[value])
+				break
+			}
+			value, ok := br.read(11)
+			if !ok {
+				return errTruncated
+			}
+			if value >= 45*45 {
+				return errInvalid
+			}
+			segment = append(segment, alphanumericCharacters[value/45], alphanumericCharacters[value%45])
+			count -= 2
+		}
+		if fnc1 {
+			segment = unescapeFNC1(segment)
+		}
+		sb.Write(segment)
+	case dataModeByte:
+		for range count {
+			value, ok := br.read(8)
+			if !ok {
+				return errTruncated
+			}
+			sb.WriteByte(byte(value))
+		}
+	case dataModeKanji:
+		// Kanji are returned as Shift JIS double-byte characters.
+		for range count {
+			value, ok := br.read(13)
+			if !ok {
+				return errTruncated
+			}
+			c := (value/0xc0)<<8 | value%0xc0
+			if c < 0x1f00 {
+				c += 0x8140
+			} else {
+				c += 0xc140
+			}
+			sb.WriteByte(byte(c >> 8))
+			sb.WriteByte(byte(c))
+		}
+	}
+	return nil
+}
+
+// unescapeFNC1 reverses escapeFNC1: a single percent sign is a group
+// separator, two of them are a percent sign.
+func unescapeFNC1(data []byte) []byte {
+	result := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		if data[i] != '%' {
+			result = append(result, data[i])
+		} else if i+1 < len(data) && data[i+1] == '%' {
+			result = append(result, '%')
+			i++
+		} else {
+			result = append(result, GroupSeparator)
+		}
+	}
+	return result
+}
@@ -0,0 +1,210 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+func TestDecodeBitmapRoundTrip(t *testing.T) {
+	testcases := []struct {
+		name    string
+		content string
+		opts    Options
+	}{
+		{"numeric", "01234567890123456789", Options{Level: Medium}},
+		{"alphanumeric", "HELLO WORLD $%*+-./:", Options{Level: Low}},
+		{"byte", "https://example.com/path?query=1", Options{Level: High}},
+		{"utf-8", "Grüße aus Köln 🌞", Options{Level: Highest}},
+		{"mixed", "ABCDEF1234567890123456789abcdef", Options{Level: Medium}},
+		{"kanji", "\x93\x5f\x8e\x9a\x82\xa0ABC", Options{Level: Medium, ShiftJIS: true}},
+		{"gs1", "01095060001343521\x1d10ABC%123", Options{Level: Medium, GS1: true}},
+		{"eci", "Grüße", Options{Level: Low, ECI: 26}},
+		{"eci-large", "data", Options{Level: Low, ECI: MaxECI}},
+		{"version-7", strings.Repeat("version seven ", 8), Options{Level: Medium, MinVersion: 7}},
+		{"version-40", strings.Repeat("The quick brown fox jumps over the lazy dog. ", 36), Options{Level: High}},
+		{"mask", "forced mask", Options{Level: Low, ForceMask: intPtr(5)}},
+		{"no-border", "no border", Options{Level: Low, DisableBorder: true}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			q, err := NewWithOptions([]byte(tc.content), tc.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := DecodeBitmap(q.Bitmap())
+			if err != nil {
+				t.Fatalf("version %d: %v", q.VersionNumber, err)
+			}
+			if got != tc.content {
+				t.Errorf("version %d:\nexpected %q\nbut got  %q", q.VersionNumber, tc.content, got)
+			}
+		})
+	}
+}
+
+func TestDecodeAllVersionsAndLevels(t *testing.T) {
+	for version := MinVersion; version <= MaxVersion; version++ {
+		for _, level := range []RecoveryLevel{Low, Medium, High, Highest} {
+			content := strings.Repeat("x", version)
+			q, err := NewWithVersion(content, level, version)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := DecodeBitmap(q.Bitmap())
+			if err != nil || got != content {
+				t.Errorf("version %d, level %d: %q expected, got %q (%v)", version, level, content, got, err)
+			}
+		}
+	}
+}
+
+// flipDataModules flips n different data modules of the bitmap.
+func flipDataModules(q *QRCode, bitmap [][]bool, n int, rng *rand.Rand) {
+	var positions []image.Point
+	for y, row := range q.Modules() {
+		for x, mt := range row {
+			if mt == ModuleData {
+				positions = append(positions, image.Point{x, y})
+			}
+		}
+	}
+	for _, i := range rng.Perm(len(positions))[:n] {
+		p := positions[i]
+		bitmap[p.Y][p.X] = !bitmap[p.Y][p.X]
+	}
+}
+
+func TestDecodeErrorCorrection(t *testing.T) {
+	rng := rand.New(rand.NewPCG(17, 4))
+	testcases := []struct {
+		version  int
+		level    RecoveryLevel
+		capacity int // number of correctable codewords of the smallest block
+	}{
+		{1, Low, 3},
+		{1, Highest, 8},
+		{2, Medium, 8},
+		{5, High, 9},
+		{10, Highest, 14},
+		{25, Medium, 14},
+	}
+	for _, tc := range testcases {
+		q, err := NewWithVersion("0123456789", tc.level, tc.version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for n := 0; n <= tc.capacity; n++ {
+			// Each flipped module damages at most one codeword, so no block has
+			// more than n wrong codewords.
+			bitmap := q.Bitmap()
+			flipDataModules(q, bitmap, n, rng)
+			if got, err := DecodeBitmap(bitmap); err != nil || got != "0123456789" {
+				t.Errorf("version %d, level %d, %d flipped modules: got %q (%v)", tc.version, tc.level, n, got, err)
+			}
+		}
+	}
+}
+
+func TestDecodeTooManyErrors(t *testing.T) {
+	q, err := NewWithVersion("too many errors", Low, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bitmap := q.Bitmap()
+	// Invert all data modules.
+	for y, row := range q.Modules() {
+		for x, mt := range row {
+			if mt == ModuleData {
+				bitmap[y][x] = !bitmap[y][x]
+			}
+		}
+	}
+	if got, err := DecodeBitmap(bitmap); !errors.Is(err, ErrUnreadable) {
+		t.Errorf("ErrUnreadable expected, got %q (%v)", got, err)
+	}
+}
+
+func TestDecodeFormatErrors(t *testing.T) {
+	q, err := NewWithVersion("format", Medium, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bitmap := q.Bitmap()
+	qz := q.quietZone
+	// Damage the first copies of the format and version information.
+	for _, p := range []image.Point{{8, 0}, {8, 1}, {8, 2}, {0, 8}, {1, 8}, {0, 49 - 11}, {1, 49 - 10}} {
+		bitmap[qz+p.Y][qz+p.X] = !bitmap[qz+p.Y][qz+p.X]
+	}
+	if got, err := DecodeBitmap(bitmap); err != nil || got != "format" {
+		t.Errorf("%q expected, got %q (%v)", "format", got, err)
+	}
+}
+
+func TestDecodeBitmapNotFound(t *testing.T) {
+	testcases := [][][]bool{
+		nil,
+		{{false, false}, {false, false}},
+		{{true, true}, {true, true}},
+		{{true}, {true, false}},
+	}
+	for i, bitmap := range testcases {
+		if _, err := DecodeBitmap(bitmap); !errors.Is(err, ErrSymbolNotFound) {
+			t.Errorf("%d: ErrSymbolNotFound expected, got %v", i, err)
+		}
+	}
+}
+
+func TestDecodeImage(t *testing.T) {
+	const content = "https://example.com/decode"
+	q, err := New(content, Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, size := range []int{-3, 0, 100, 257} {
+		data, err := q.PNG(size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := Decode(img); err != nil || got != content {
+			t.Errorf("size %d: %q expected, got %q (%v)", size, content, got, err)
+		}
+	}
+
+	q.ForegroundColor = color.RGBA{0x00, 0x33, 0x66, 0xff}
+	q.BackgroundColor = color.RGBA{0xff, 0xee, 0xcc, 0xff}
+	if got, err := Decode(q.Image(200)); err != nil || got != content {
+		t.Errorf("colors: %q expected, got %q (%v)", content, got, err)
+	}
+	q.Inverted = true
+	if got, err := Decode(q.Image(200)); err != nil || got != content {
+		t.Errorf("inverted: %q expected, got %q (%v)", content, got, err)
+	}
+
+	empty := image.NewGray(image.Rect(0, 0, 50, 50))
+	if _, err := Decode(empty); !errors.Is(err, ErrSymbolNotFound) {
+		t.Errorf("ErrSymbolNotFound expected, got %v", err)
+	}
+}
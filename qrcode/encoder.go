@@ -23,7 +23,7 @@
 package qrcode
 
 import (
-	"errors"
+	"bytes"
 	"fmt"
 
 	"t73f.de/r/webs/qrcode/internal/bitset"
@@ -34,7 +34,7 @@ import (
 // The main data portion of a QR Code consists of one or more segments of data.
 // A segment consists of:
 //
-// - The segment Data Mode: numeric, alphanumeric, or byte.
+// - The segment Data Mode: numeric, alphanumeric, byte, or kanji.
 // - The length of segment in bits.
 // - Encoded data.
 //
@@ -47,15 +47,20 @@ import (
 // encoded at a higher density of 3 numbers (e.g. 123) per 10 bits.
 //
 // Some data can be represented in multiple modes. Numeric data can be
-// represented in all three modes, whereas alphanumeric data (e.g. 'A') can be
-// represented in alphanumeric and byte mode.
+// represented in numeric, alphanumeric, and byte mode, whereas alphanumeric
+// data (e.g. 'A') can be represented in alphanumeric and byte mode.
+//
+// Kanji mode encodes double-byte Shift JIS characters with 13 bits each,
+// instead of 16 bits in byte mode. Since a pair of bytes may be a Shift JIS
+// character or not, depending on the encoding of the data, kanji mode must be
+// enabled explicitly for data that is encoded in Shift JIS.
 //
 // Starting a new segment (to use a different Data Mode) has a cost, the bits to
 // state the new segment Data Mode and length. To minimise each QR Code's symbol
 // size, an optimisation routine coalesces segment types where possible, to
 // reduce the encoded data length.
 //
-// There are several other data modes available (e.g. Kanji mode) which are not
+// There are several other data modes available (e.g. ECI mode) which are not
 // implemented here.
 
 // A segment encoding mode.
@@ -72,8 +77,57 @@ const (
 	dataModeNumeric
 	dataModeAlphanumeric
 	dataModeByte
+
+	// dataModeKanji is not part of the ordering above: kanji data can only
+	// be encoded with dataModeKanji and dataModeByte.
+	dataModeKanji
 )
 
+// canEncode returns true, if data of the other mode can be encoded with
+// this mode.
+func (m dataMode) canEncode(other dataMode) bool {
+	switch {
+	case m == other, other == dataModeNone:
+		return true
+	case other == dataModeKanji:
+		return m == dataModeByte
+	case m == dataModeKanji:
+		return false
+	default:
+		return other < m
+	}
+}
+
+// combine returns the lowest mode that is able to encode data of both modes.
+func (m dataMode) combine(other dataMode) dataMode {
+	if m.canEncode(other) {
+		return m
+	}
+	if other.canEncode(m) {
+		return other
+	}
+	return dataModeByte
+}
+
+// numChars returns the number of characters of data with the given number
+// of bytes.
+func (m dataMode) numChars(numBytes int) int {
+	if m == dataModeKanji {
+		return numBytes / 2
+	}
+	return numBytes
+}
+
+// numChars returns the number of characters of a segment with the given
+// data, if encoded in the given mode. In FNC1 mode, a percent sign is
+// encoded as two characters in alphanumeric mode.
+func (d *dataEncoder) numChars(m dataMode, data []byte) int {
+	if m == dataModeAlphanumeric && d.fnc1 {
+		return len(data) + bytes.Count(data, []byte{'%'})
+	}
+	return m.numChars(len(data))
+}
+
 // segment is a single segment of data.
 type segment struct {
 	// Data Mode (e.g. numeric).
@@ -93,11 +147,15 @@ type dataEncoder struct {
 	numericModeIndicator      *bitset.Bitset
 	alphanumericModeIndicator *bitset.Bitset
 	byteModeIndicator         *bitset.Bitset
+	kanjiModeIndicator        *bitset.Bitset
 
 	// Character count lengths.
 	numNumericCharCountBits      int
 	numAlphanumericCharCountBits int
 	numByteCharCountBits         int
+	numKanjiCharCountBits        int
+
+	encodeOptions
 
 	// The raw input data.
 	data []byte
@@ -109,6 +167,51 @@ type dataEncoder struct {
 	optimised []segment
 }
 
+// encodeOptions are options of a data encoder, which change the encoding of
+// the content.
+type encodeOptions struct {
+	// Classify Shift JIS double-byte characters as kanji.
+	kanji bool
+
+	// Prepend the FNC1 mode indicator (first position) for GS1 data. The
+	// group separator (GS) is encoded as a percent sign in alphanumeric
+	// mode, a percent sign as two of them.
+	fnc1 bool
+
+	// ECI assignment number. If positive, an ECI header is prepended.
+	eci int
+
+	// How the data is split into segments.
+	segmentation Segmentation
+}
+
+// Mode indicators that do not start a data segment.
+var (
+	eciModeIndicator       = bitset.New(b0, b1, b1, b1)
+	fnc1FirstModeIndicator = bitset.New(b0, b1, b0, b1)
+)
+
+// header returns the bits that precede the data segments: the ECI header
+// and the FNC1 mode indicator, if requested.
+func (o encodeOptions) header() *bitset.Bitset {
+	header := bitset.New()
+	if o.eci > 0 {
+		header.Append(eciModeIndicator)
+		switch eci := uint32(o.eci); {
+		case eci < 1<<7:
+			header.AppendUint32(eci, 8)
+		case eci < 1<<14:
+			header.AppendUint32(0b10<<14|eci, 16)
+		default:
+			header.AppendUint32(0b110<<21|eci, 24)
+		}
+	}
+	if o.fnc1 {
+		header.Append(fnc1FirstModeIndicator)
+	}
+	return header
+}
+
 // allDataEncoder return all available encoder.
 var allDataEncoder = []dataEncoder{
 	{
@@ -117,9 +220,11 @@ var allDataEncoder = []dataEncoder{
 		numericModeIndicator:         bitset.New(b0, b0, b0, b1),
 		alphanumericModeIndicator:    bitset.New(b0, b0, b1, b0),
 		byteModeIndicator:            bitset.New(b0, b1, b0, b0),
+		kanjiModeIndicator:           bitset.New(b1, b0, b0, b0),
 		numNumericCharCountBits:      10,
 		numAlphanumericCharCountBits: 9,
 		numByteCharCountBits:         8,
+		numKanjiCharCountBits:        8,
 	},
 	{
 		minVersion:                   10,
@@ -127,9 +232,11 @@ var allDataEncoder = []dataEncoder{
 		numericModeIndicator:         bitset.New(b0, b0, b0, b1),
 		alphanumericModeIndicator:    bitset.New(b0, b0, b1, b0),
 		byteModeIndicator:            bitset.New(b0, b1, b0, b0),
+		kanjiModeIndicator:           bitset.New(b1, b0, b0, b0),
 		numNumericCharCountBits:      12,
 		numAlphanumericCharCountBits: 11,
 		numByteCharCountBits:         16,
+		numKanjiCharCountBits:        10,
 	},
 	{
 		minVersion:                   27,
@@ -137,18 +244,34 @@ var allDataEncoder = []dataEncoder{
 		numericModeIndicator:         bitset.New(b0, b0, b0, b1),
 		alphanumericModeIndicator:    bitset.New(b0, b0, b1, b0),
 		byteModeIndicator:            bitset.New(b0, b1, b0, b0),
+		kanjiModeIndicator:           bitset.New(b1, b0, b0, b0),
 		numNumericCharCountBits:      14,
 		numAlphanumericCharCountBits: 13,
 		numByteCharCountBits:         16,
+		numKanjiCharCountBits:        12,
 	},
 }
 
+// dataEncoderFor returns a copy of the data encoder that is responsible for
+// the given version. The copy may be used to encode data, without changing
+// the shared encoders of allDataEncoder.
+func dataEncoderFor(version int) dataEncoder {
+	for _, de := range allDataEncoder {
+		if de.minVersion <= version && version <= de.maxVersion {
+			return de
+		}
+	}
+	return allDataEncoder[len(allDataEncoder)-1]
+}
+
 // encode data as one or more segments and return the encoded data.
 //
-// The returned data does not include the terminator bit sequence.
+// The returned data includes the header, i.e. the ECI header and the FNC1
+// mode indicator, if requested, but does not include the terminator bit
+// sequence.
 func (d *dataEncoder) encode(data []byte) (*bitset.Bitset, error) {
 	if len(data) == 0 {
-		return nil, errors.New("no data to encode")
+		return nil, ErrEmptyContent
 	}
 
 	d.data = data
@@ -158,38 +281,58 @@ func (d *dataEncoder) encode(data []byte) (*bitset.Bitset, error) {
 	// Classify data into unoptimised segments.
 	highestRequiredMode := d.classifyDataModes()
 
-	// Optimise segments.
-	err := d.optimiseDataModes()
-	if err != nil {
-		return nil, err
+	switch d.segmentation {
+	case SegmentationSingle:
+		d.optimised = []segment{{dataMode: highestRequiredMode, data: d.data}}
+	case SegmentationByte:
+		d.optimised = []segment{{dataMode: dataModeByte, data: d.data}}
+	default:
+		if err := d.optimiseSegments(highestRequiredMode); err != nil {
+			return nil, err
+		}
+	}
+	for _, s := range d.optimised {
+		if _, err := d.encodedLength(s.dataMode, d.numChars(s.dataMode, s.data)); err != nil {
+			return nil, err
+		}
+	}
+
+	// Encode data.
+	encoded := d.header()
+	for _, s := range d.optimised {
+		d.encodeDataRaw(s.data, s.dataMode, encoded)
+	}
+
+	return encoded, nil
+}
+
+// optimiseSegments computes the segments with the shortest encoded length:
+// either the optimised segments, or a single segment with the highest
+// required data mode.
+func (d *dataEncoder) optimiseSegments(highestRequiredMode dataMode) error {
+	if err := d.optimiseDataModes(); err != nil {
+		return err
 	}
 
 	// Check if a single byte encoded segment would be more efficient.
 	optimizedLength := 0
 	for _, s := range d.optimised {
-		length, errEncoded := d.encodedLength(s.dataMode, len(s.data))
-		if errEncoded != nil {
-			return nil, errEncoded
+		length, err := d.encodedLength(s.dataMode, d.numChars(s.dataMode, s.data))
+		if err != nil {
+			return err
 		}
 		optimizedLength += length
 	}
 
-	singleByteSegmentLength, err := d.encodedLength(highestRequiredMode, len(d.data))
+	singleByteSegmentLength, err := d.encodedLength(highestRequiredMode, d.numChars(highestRequiredMode, d.data))
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	if singleByteSegmentLength <= optimizedLength {
 		d.optimised = []segment{{dataMode: highestRequiredMode, data: d.data}}
 	}
-
-	// Encode data.
-	encoded := bitset.New()
-	for _, s := range d.optimised {
-		d.encodeDataRaw(s.data, s.dataMode, encoded)
-	}
-
-	return encoded, nil
+	return nil
 }
 
 // classifyDataModes classifies the raw data into unoptimised segments.
@@ -197,7 +340,8 @@ func (d *dataEncoder) encode(data []byte) (*bitset.Bitset, error) {
 // [numeric, 3, "123"] [alphanumeric, 2, "ZZ"] [byte, 4, "#!#!"].
 //
 // Returns the highest data mode needed to encode the data. e.g. for a mixed
-// numeric/alphanumeric input, the highest is alphanumeric.
+// numeric/alphanumeric input, the highest is alphanumeric. For mixed kanji and
+// other input, the highest is byte.
 //
 // dataModeNone < dataModeNumeric < dataModeAlphanumeric < dataModeByte
 func (d *dataEncoder) classifyDataModes() dataMode {
@@ -205,11 +349,18 @@ func (d *dataEncoder) classifyDataModes() dataMode {
 	mode := dataModeNone
 	highestRequiredMode := mode
 
-	for i, v := range d.data {
+	for i := 0; i < len(d.data); {
+		v := d.data[i]
+		width := 1
 		newMode := dataModeNone
 		switch {
+		case d.kanji && isKanji(d.data[i:]):
+			newMode = dataModeKanji
+			width = 2
 		case v >= 0x30 && v <= 0x39:
 			newMode = dataModeNumeric
+		case d.fnc1 && v == GroupSeparator:
+			newMode = dataModeAlphanumeric
 		case v == 0x20 || v == 0x24 || v == 0x25 || v == 0x2a || v == 0x2b || v ==
 			0x2d || v == 0x2e || v == 0x2f || v == 0x3a || (v >= 0x41 && v <= 0x5a):
 			newMode = dataModeAlphanumeric
@@ -225,15 +376,33 @@ func (d *dataEncoder) classifyDataModes() dataMode {
 			mode = newMode
 		}
 
-		if newMode > highestRequiredMode {
-			highestRequiredMode = newMode
-		}
+		highestRequiredMode = highestRequiredMode.combine(newMode)
+		i += width
 	}
 
 	d.actual = append(d.actual, segment{dataMode: mode, data: d.data[start:len(d.data)]})
 	return highestRequiredMode
 }
 
+// isKanji returns true, if data starts with a Shift JIS double-byte
+// character that can be encoded in kanji mode, i.e. in the ranges
+// 0x8140-0x9FFC and 0xE040-0xEBBF.
+func isKanji(data []byte) bool {
+	if len(data) < 2 {
+		return false
+	}
+	if lsb := data[1]; lsb < 0x40 || lsb == 0x7f || lsb > 0xfc {
+		return false
+	}
+	switch c := uint16(data[0])<<8 | uint16(data[1]); {
+	case c >= 0x8140 && c <= 0x9ffc:
+		return true
+	case c >= 0xe040 && c <= 0xebbf:
+		return true
+	}
+	return false
+}
+
 // optimiseDataModes optimises the list of segments to reduce the overall output
 // encoded data length.
 //
@@ -246,15 +415,17 @@ func (d *dataEncoder) classifyDataModes() dataMode {
 func (d *dataEncoder) optimiseDataModes() error {
 	for i := 0; i < len(d.actual); {
 		mode := d.actual[i].dataMode
-		numChars := len(d.actual[i].data)
+		numBytes := len(d.actual[i].data)
+		numChars := d.numChars(mode, d.actual[i].data)
 
 		j := i + 1
 		for j < len(d.actual) {
-			nextNumChars := len(d.actual[j].data)
+			nextData := d.actual[j].data
 			nextMode := d.actual[j].dataMode
-			if nextMode > mode {
+			if !mode.canEncode(nextMode) {
 				break
 			}
+			nextNumChars := d.numChars(mode, nextData)
 
 			coalescedLength, err := d.encodedLength(mode, numChars+nextNumChars)
 			if err != nil {
@@ -266,13 +437,14 @@ func (d *dataEncoder) optimiseDataModes() error {
 				return err
 			}
 
-			seperateLength2, err := d.encodedLength(nextMode, nextNumChars)
+			seperateLength2, err := d.encodedLength(nextMode, d.numChars(nextMode, nextData))
 			if err != nil {
 				return err
 			}
 
 			if coalescedLength < seperateLength1+seperateLength2 {
 				j++
+				numBytes += len(nextData)
 				numChars += nextNumChars
 			} else {
 				break
@@ -280,7 +452,7 @@ func (d *dataEncoder) optimiseDataModes() error {
 		}
 
 		optimised := segment{dataMode: mode,
-			data: make([]byte, 0, numChars)}
+			data: make([]byte, 0, numBytes)}
 
 		for k := i; k < j; k++ {
 			optimised.data = append(optimised.data, d.actual[k].data...)
@@ -302,7 +474,7 @@ func (d *dataEncoder) encodeDataRaw(data []byte, dataMode dataMode, encoded *bit
 	encoded.Append(modeIndicator)
 
 	// Append character count.
-	encoded.AppendUint32(uint32(len(data)), charCountBits)
+	encoded.AppendUint32(uint32(d.numChars(dataMode, data)), charCountBits)
 
 	// Append data.
 	switch dataMode {
@@ -321,6 +493,9 @@ func (d *dataEncoder) encodeDataRaw(data []byte, dataMode dataMode, encoded *bit
 			encoded.AppendUint32(value, bitsUsed)
 		}
 	case dataModeAlphanumeric:
+		if d.fnc1 {
+			data = escapeFNC1(data)
+		}
 		for i := 0; i < len(data); i += 2 {
 			charsRemaining := len(data) - i
 
@@ -339,7 +514,35 @@ func (d *dataEncoder) encodeDataRaw(data []byte, dataMode dataMode, encoded *bit
 		}
 	case dataModeByte:
 		encoded.AppendBytes(data)
+	case dataModeKanji:
+		for i := 0; i+1 < len(data); i += 2 {
+			c := uint32(data[i])<<8 | uint32(data[i+1])
+			if c <= 0x9ffc {
+				c -= 0x8140
+			} else {
+				c -= 0xc140
+			}
+			encoded.AppendUint32((c>>8)*0xc0+(c&0xff), 13)
+		}
+	}
+}
+
+// escapeFNC1 replaces the group separators by a percent sign, and each
+// percent sign by two of them, as required for alphanumeric mode in FNC1
+// mode.
+func escapeFNC1(data []byte) []byte {
+	result := make([]byte, 0, len(data)+bytes.Count(data, []byte{'%'}))
+	for _, b := range data {
+		switch b {
+		case GroupSeparator:
+			result = append(result, '%')
+		case '%':
+			result = append(result, '%', '%')
+		default:
+			result = append(result, b)
+		}
 	}
+	return result
 }
 
 // modeIndicator returns the segment header bits for a segment of type dataMode.
@@ -351,6 +554,8 @@ func (d *dataEncoder) modeIndicator(dataMode dataMode) *bitset.Bitset {
 		return d.alphanumericModeIndicator
 	case dataModeByte:
 		return d.byteModeIndicator
+	case dataModeKanji:
+		return d.kanjiModeIndicator
 	default:
 		panic("Unknown data mode")
 	}
@@ -366,6 +571,8 @@ func (d *dataEncoder) charCountBits(dataMode dataMode) int {
 		return d.numAlphanumericCharCountBits
 	case dataModeByte:
 		return d.numByteCharCountBits
+	case dataModeKanji:
+		return d.numKanjiCharCountBits
 	default:
 		panic("Unknown data mode")
 	}
@@ -387,12 +594,12 @@ func (d *dataEncoder) encodedLength(dataMode dataMode, n int) (int, error) {
 	charCountBits := d.charCountBits(dataMode)
 
 	if modeIndicator == nil {
-		return 0, errors.New("mode not supported")
+		return 0, errModeNotSupported
 	}
 
 	maxLength := (1 << uint8(charCountBits)) - 1
 	if n > maxLength {
-		return 0, errors.New("length too long to be represented")
+		return 0, errLengthTooLong
 	}
 
 	length := modeIndicator.Len() + charCountBits
@@ -407,6 +614,8 @@ func (d *dataEncoder) encodedLength(dataMode dataMode, n int) (int, error) {
 		length += 6 * (n % 2)
 	case dataModeByte:
 		length += 8 * n
+	case dataModeKanji:
+		length += 13 * n
 	}
 	return length, nil
 }
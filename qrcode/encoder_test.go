@@ -64,6 +64,57 @@ func TestClassifyDataMode(t *testing.T) {
 		}
 	}
 }
+
+func TestClassifyKanjiDataMode(t *testing.T) {
+	tests := []struct {
+		data    []byte
+		actual  []segment
+		highest dataMode
+	}{
+		{
+			[]byte{0x93, 0x5f, 0xe4, 0xaa},
+			[]segment{
+				{dataModeKanji, []byte{0x93, 0x5f, 0xe4, 0xaa}},
+			},
+			dataModeKanji,
+		},
+		{
+			// "A", kanji, invalid second byte, kanji outside of kanji
+			// range, numeric, dangling lead byte.
+			[]byte{0x41, 0x93, 0x5f, 0x93, 0x7f, 0xec, 0x40, 0x31, 0x93},
+			[]segment{
+				{dataModeAlphanumeric, []byte{0x41}},
+				{dataModeKanji, []byte{0x93, 0x5f}},
+				{dataModeByte, []byte{0x93, 0x7f, 0xec, 0x40}},
+				{dataModeNumeric, []byte{0x31}},
+				{dataModeByte, []byte{0x93}},
+			},
+			dataModeByte,
+		},
+	}
+
+	for _, test := range tests {
+		encoder := allDataEncoder[0] //(dataEncoderType1To9)
+		encoder.kanji = true
+		encoder.data = test.data
+		if highest := encoder.classifyDataModes(); highest != test.highest {
+			t.Errorf("highest mode %s expected, got %s", dataModeString(test.highest), dataModeString(highest))
+		}
+		if !slices.EqualFunc(test.actual, encoder.actual, equalSegment) {
+			t.Errorf("Got %v, expected %v", encoder.actual, test.actual)
+		}
+
+		// Without kanji mode, no kanji segments are classified.
+		encoder = allDataEncoder[0]
+		encoder.data = test.data
+		encoder.classifyDataModes()
+		for _, seg := range encoder.actual {
+			if seg.dataMode == dataModeKanji {
+				t.Errorf("unexpected kanji segment in %v", encoder.actual)
+			}
+		}
+	}
+}
 func equalSegment(s1, s2 segment) bool {
 	if s1.dataMode == s2.dataMode {
 		return slices.Equal(s1.data, s2.data)
@@ -71,6 +122,42 @@ func equalSegment(s1, s2 segment) bool {
 	return false
 }
 
+func TestFNC1Encoding(t *testing.T) {
+	testCases := []struct {
+		data    string
+		escaped string // data, as encoded in alphanumeric mode without FNC1
+	}{
+		// GTIN, batch (variable length), serial number.
+		{"0109501101530003" + "10ABC123\x1d" + "21XYZ", "0109501101530003" + "10ABC123%" + "21XYZ"},
+		{"10A%B\x1d21C", "10A%%B%21C"},
+		// Byte mode keeps the group separator.
+		{"10abc\x1d21def", "10abc\x1d21def"},
+	}
+	for _, tc := range testCases {
+		for _, de := range allDataEncoder {
+			fnc1 := de
+			fnc1.fnc1 = true
+			got, err := fnc1.encode([]byte(tc.data))
+			if err != nil {
+				t.Errorf("%q: %v", tc.data, err)
+				continue
+			}
+			plain := de
+			encoded, err := plain.encode([]byte(tc.escaped))
+			if err != nil {
+				t.Errorf("%q: %v", tc.escaped, err)
+				continue
+			}
+			exp := bitset.Clone(fnc1FirstModeIndicator)
+			exp.Append(encoded)
+			if !got.Equals(exp) {
+				t.Errorf("%q (version %d-%d): encoded differently\nexpected %s\ngot      %s",
+					tc.data, de.minVersion, de.maxVersion, exp, got)
+			}
+		}
+	}
+}
+
 func TestByteModeLengthCalculations(t *testing.T) {
 	tests := []struct {
 		dataEncoderType int
@@ -128,6 +215,22 @@ func TestSingleModeEncodings(t *testing.T) {
 			dataModeByte, "123",
 			bitset.NewFromBase2String("0100 00000000 00000011 00110001 00110010 00110011"),
 		},
+		// Example of ISO/IEC 18004: "点茗" in Shift JIS.
+		{
+			0, // dataEncoderType1To9,
+			dataModeKanji, "\x93\x5f\xe4\xaa",
+			bitset.NewFromBase2String("1000 00000010 0110110011111 1101010101010"),
+		},
+		{
+			1, // dataEncoderType10To26,
+			dataModeKanji, "\x93\x5f\xe4\xaa",
+			bitset.NewFromBase2String("1000 0000000010 0110110011111 1101010101010"),
+		},
+		{
+			2, // dataEncoderType27To40,
+			dataModeKanji, "\x93\x5f",
+			bitset.NewFromBase2String("1000 000000000001 0110110011111"),
+		},
 	}
 
 	for _, test := range tests {
@@ -323,6 +426,55 @@ func TestOptimiseEncoding(t *testing.T) {
 				{dataModeNumeric, 10},
 			},
 		},
+		// A single kanji character is absorbed by the byte segments.
+		{
+			0, // dataEncoderType1To9,
+			[]testModeSegment{
+				{dataModeByte, 1},  // length = 4 + 8 + 8 = 20 bits
+				{dataModeKanji, 1}, // length = 4 + 8 + 13 = 25 bits
+				{dataModeByte, 1},  // 20 bits
+			},
+			[]testModeSegment{
+				{dataModeByte, 4}, // length = 4 + 8 + 32 = 44 bits
+			},
+		},
+		// A kanji segment never absorbs a byte segment.
+		{
+			0, // dataEncoderType1To9,
+			[]testModeSegment{
+				{dataModeKanji, 10},
+				{dataModeByte, 1},
+			},
+			[]testModeSegment{
+				{dataModeKanji, 10},
+				{dataModeByte, 1},
+			},
+		},
+		// Long kanji segments are not absorbed by byte segments.
+		{
+			1, // dataEncoderType10To26,
+			[]testModeSegment{
+				{dataModeByte, 1},
+				{dataModeKanji, 10},
+				{dataModeAlphanumeric, 20},
+			},
+			[]testModeSegment{
+				{dataModeByte, 1},
+				{dataModeKanji, 10},
+				{dataModeAlphanumeric, 20},
+			},
+		},
+		// Mixed numeric and kanji data is encoded as bytes.
+		{
+			0, // dataEncoderType1To9,
+			[]testModeSegment{
+				{dataModeNumeric, 1},
+				{dataModeKanji, 2},
+			},
+			[]testModeSegment{
+				{dataModeByte, 5},
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -331,27 +483,27 @@ func TestOptimiseEncoding(t *testing.T) {
 			numTotalChars += v.numChars
 		}
 
-		data := make([]byte, numTotalChars)
+		data := make([]byte, 0, 2*numTotalChars)
 
-		i := 0
 		for _, v := range test.actual {
 			for j := 0; j < v.numChars; j++ {
 				switch v.dataMode {
 				case dataModeNumeric:
-					data[i] = '1'
+					data = append(data, '1')
 				case dataModeAlphanumeric:
-					data[i] = 'A'
+					data = append(data, 'A')
 				case dataModeByte:
-					data[i] = '#'
+					data = append(data, '#')
+				case dataModeKanji:
+					data = append(data, 0x93, 0x5f)
 				default:
 					t.Fatal("Unrecognised data mode")
 				}
-
-				i++
 			}
 		}
 
 		encoder := allDataEncoder[test.dataEncoderType]
+		encoder.kanji = true
 
 		_, err := encoder.encode(data)
 
@@ -365,7 +517,7 @@ func TestOptimiseEncoding(t *testing.T) {
 			} else {
 				for i, s := range test.optimised {
 					if encoder.optimised[i].dataMode != s.dataMode ||
-						len(encoder.optimised[i].data) != s.numChars {
+						s.dataMode.numChars(len(encoder.optimised[i].data)) != s.numChars {
 						ok = false
 						break
 					}
@@ -400,7 +552,7 @@ func segmentsString(segments []segment) string {
 		if i > 0 {
 			result += ", "
 		}
-		result += fmt.Sprintf("%d*%s", len(segment.data),
+		result += fmt.Sprintf("%d*%s", segment.dataMode.numChars(len(segment.data)),
 			dataModeString(segment.dataMode))
 	}
 	result += "]"
@@ -416,6 +568,8 @@ func dataModeString(d dataMode) string {
 		return "alphanumeric"
 	case dataModeByte:
 		return "byte"
+	case dataModeKanji:
+		return "kanji"
 	}
 	return "unknown"
 }
@@ -0,0 +1,121 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package qrcode
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors returned by [New] and all functions that create QR codes.
+//
+// These errors are part of the API: an error that signals too long content
+// will always satisfy errors.Is(err, ErrContentTooLong), and it can be
+// inspected with errors.As as a [*CapacityError]. Unexpected failures of the
+// encoder are returned as a [*EncodeError].
+var (
+	// ErrContentTooLong signals that the content does not fit into the
+	// largest QR code version of the requested recovery level, or into the
+	// requested version.
+	ErrContentTooLong = errors.New("content too long to encode")
+
+	// ErrInvalidLevel signals an unknown recovery level.
+	ErrInvalidLevel = errors.New("invalid recovery level")
+
+	// ErrEmptyContent signals that there is no content to encode.
+	ErrEmptyContent = errors.New("no content to encode")
+
+	// ErrInvalidVersion signals a version outside of the range 1-40.
+	ErrInvalidVersion = errors.New("invalid version")
+
+	// ErrInvalidOptions signals invalid [Options], other than an invalid
+	// level or version.
+	ErrInvalidOptions = errors.New("invalid options")
+
+	// ErrInvalidQuality signals a JPEG quality outside of the range 1-100.
+	ErrInvalidQuality = errors.New("invalid JPEG quality")
+)
+
+// CapacityError describes content that is too long for a QR code.
+type CapacityError struct {
+	Length      int           // Length of the content in bytes.
+	Level       RecoveryLevel // The requested recovery level.
+	Version     int           // The largest version that was considered.
+	MaxCapacity int           // Maximum number of characters of the content's data mode at this level and version.
+}
+
+func (ce *CapacityError) Error() string {
+	return fmt.Sprintf("%v: %d characters, maximum at level %d and version %d is %d",
+		ErrContentTooLong, ce.Length, ce.Level, ce.Version, ce.MaxCapacity)
+}
+
+// Is allows to match a CapacityError with [ErrContentTooLong].
+func (ce *CapacityError) Is(target error) bool { return target == ErrContentTooLong }
+
+// EncodeError wraps an unexpected failure of the encoder.
+type EncodeError struct {
+	Err error
+}
+
+func (ee *EncodeError) Error() string { return "qrcode: unable to encode: " + ee.Err.Error() }
+
+// Unwrap returns the underlying cause.
+func (ee *EncodeError) Unwrap() error { return ee.Err }
+
+// Errors returned by [Decode] and [DecodeBitmap].
+var (
+	// ErrSymbolNotFound signals that no QR code symbol could be located.
+	ErrSymbolNotFound = errors.New("no QR code found")
+
+	// ErrUnreadable signals a QR code symbol that could not be decoded,
+	// e.g. because it has too many errors.
+	ErrUnreadable = errors.New("QR code not readable")
+)
+
+// Internal errors of the data encoder.
+var (
+	errModeNotSupported = errors.New("mode not supported")
+	errLengthTooLong    = errors.New("length too long to be represented")
+)
+
+// newCapacityError creates an error for content that does not fit into the
+// given version at the given level.
+func newCapacityError(content string, level RecoveryLevel, version int, opts encodeOptions) *CapacityError {
+	encoder := dataEncoderFor(version)
+	encoder.encodeOptions = opts
+	encoder.data = []byte(content)
+	mode := encoder.classifyDataModes()
+
+	maxCapacity := 0
+	for _, v := range versions {
+		if v.level == level && v.version == version {
+			numDataBits := v.numDataBits()
+			numDataBits -= opts.header().Len()
+			// The encoded length grows monotonically with the number of
+			// characters: find the largest number that fits.
+			lo, hi := 0, numDataBits
+			for lo < hi {
+				mid := (lo + hi + 1) / 2
+				if length, err := encoder.encodedLength(mode, mid); err == nil && length <= numDataBits {
+					lo = mid
+				} else {
+					hi = mid - 1
+				}
+			}
+			maxCapacity = lo
+			break
+		}
+	}
+	return &CapacityError{Length: len(content), Level: level, Version: version, MaxCapacity: maxCapacity}
+}
@@ -0,0 +1,249 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package qrcode
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Severity classifies a [Finding] of [Inspect].
+type Severity uint8
+
+// Constants for Severity.
+const (
+	// SeverityInfo is a hint that needs no action.
+	SeverityInfo Severity = iota
+
+	// SeverityWarning signals content that can be encoded, but that will
+	// probably cause problems when scanned.
+	SeverityWarning
+
+	// SeverityError signals content that cannot be encoded.
+	SeverityError
+)
+
+var severityNames = [...]string{"info", "warning", "error"}
+
+// String returns a textual representation of the severity.
+func (s Severity) String() string {
+	if int(s) < len(severityNames) {
+		return severityNames[s]
+	}
+	return "unknown"
+}
+
+// Keys of findings, see [Finding]. They are stable and can be used to
+// translate the message of a finding.
+const (
+	FindingEmpty           = "empty"             // content is empty
+	FindingInvalidUTF8     = "invalid-utf8"      // byte sequence is not valid UTF-8
+	FindingByteOrderMark   = "byte-order-mark"   // U+FEFF, e.g. from a copied text file
+	FindingControl         = "control-character" // control character other than tab, CR, LF
+	FindingNonPrintable    = "non-printable"     // invisible character, e.g. a zero-width space
+	FindingLeadingSpace    = "leading-space"     // content starts with white space
+	FindingTrailingSpace   = "trailing-space"    // content ends with white space
+	FindingURLInvalid      = "url-invalid"       // URL cannot be parsed
+	FindingURLNoHost       = "url-no-host"       // URL has no host name
+	FindingURLInsecure     = "url-insecure"      // URL uses plain HTTP
+	FindingURLMixedScripts = "url-mixed-scripts" // label of host name mixes scripts, e.g. Latin and Cyrillic
+	FindingTooLong         = "too-long"          // content does not fit into any version at the lowest level
+)
+
+// Finding is a problem of the content, as found by [Inspect].
+type Finding struct {
+	Severity Severity
+	Key      string // one of the FindingXXX constants
+	Pos      int    // byte position within the content, or -1
+	Message  string // English description
+}
+
+// String returns a textual representation of the finding.
+func (f Finding) String() string {
+	if f.Pos < 0 {
+		return fmt.Sprintf("%v: %s", f.Severity, f.Message)
+	}
+	return fmt.Sprintf("%v at %d: %s", f.Severity, f.Pos, f.Message)
+}
+
+// LevelCapacity is the capacity verdict of the content at one recovery
+// level.
+type LevelCapacity struct {
+	Level   RecoveryLevel
+	Version int // smallest version that stores the content, 0 if too long
+}
+
+// Fits returns true, if the content can be stored at the recovery level.
+func (lc LevelCapacity) Fits() bool { return lc.Version > 0 }
+
+// Report is the result of [Inspect].
+type Report struct {
+	Findings []Finding
+	Levels   [Highest + 1]LevelCapacity // indexed by the recovery level
+}
+
+// HasErrors returns true, if at least one finding has [SeverityError].
+func (r *Report) HasErrors() bool { return r.maxSeverity() == SeverityError }
+
+// HasWarnings returns true, if at least one finding has [SeverityWarning]
+// or [SeverityError].
+func (r *Report) HasWarnings() bool { return r.maxSeverity() >= SeverityWarning }
+
+func (r *Report) maxSeverity() Severity {
+	result := SeverityInfo
+	for _, f := range r.Findings {
+		result = max(result, f.Severity)
+	}
+	return result
+}
+
+// SuggestLevel returns the highest recovery level, where the content fits
+// into a QR code of at most the given version. It returns false, if the
+// content does not fit into this version at any level.
+func (r *Report) SuggestLevel(maxVersion int) (RecoveryLevel, bool) {
+	for level := Highest; level >= Low; level-- {
+		if lc := r.Levels[level]; lc.Fits() && lc.Version <= maxVersion {
+			return level, true
+		}
+	}
+	return Low, false
+}
+
+// Inspect checks the content of a QR code and reports all problems at once,
+// e.g. to validate user input. In addition, it computes the smallest version
+// for every recovery level. Inspect does not build a symbol.
+//
+// Content is checked for invalid UTF-8, a byte order mark, control and
+// invisible characters, and leading or trailing white space. Content that
+// starts with "http://" or "https://" is checked as a URL: it must be
+// parsable and must contain a host name, whose labels should not mix Latin,
+// Greek, and Cyrillic letters, a basic check against confusable host names.
+func Inspect(content string) Report {
+	var r Report
+	for level := Low; level <= Highest; level++ {
+		r.Levels[level].Level = level
+	}
+	if content == "" {
+		r.add(SeverityError, FindingEmpty, -1, "content is empty")
+		return r
+	}
+	r.inspectCharacters(content)
+	r.inspectURL(content)
+	for level := Low; level <= Highest; level++ {
+		opts := Options{Level: level}.withDefaults()
+		_, _, version, err := encodeContent(content, opts)
+		if err == nil {
+			r.Levels[level].Version = version.version
+		} else if level == Low {
+			var ce *CapacityError
+			if errors.As(err, &ce) {
+				r.add(SeverityError, FindingTooLong, -1,
+					fmt.Sprintf("content too long: %d bytes, maximum is %d", ce.Length, ce.MaxCapacity))
+			} else {
+				r.add(SeverityError, FindingTooLong, -1, err.Error())
+			}
+		}
+	}
+	return r
+}
+
+func (r *Report) add(sev Severity, key string, pos int, msg string) {
+	r.Findings = append(r.Findings, Finding{Severity: sev, Key: key, Pos: pos, Message: msg})
+}
+
+// inspectCharacters checks all characters of the content.
+func (r *Report) inspectCharacters(content string) {
+	if ch, _ := utf8.DecodeRuneInString(content); unicode.IsSpace(ch) {
+		r.add(SeverityWarning, FindingLeadingSpace, 0, "content starts with white space")
+	}
+	for pos, ch := range content {
+		switch {
+		case ch == utf8.RuneError:
+			if _, size := utf8.DecodeRuneInString(content[pos:]); size == 1 {
+				r.add(SeverityWarning, FindingInvalidUTF8, pos, "invalid UTF-8 byte sequence")
+			}
+		case ch == '\t' || ch == '\n' || ch == '\r':
+		case ch == '\uFEFF':
+			r.add(SeverityWarning, FindingByteOrderMark, pos, "byte order mark")
+		case unicode.IsControl(ch):
+			r.add(SeverityWarning, FindingControl, pos, fmt.Sprintf("control character %U", ch))
+		case !unicode.IsGraphic(ch):
+			r.add(SeverityWarning, FindingNonPrintable, pos, fmt.Sprintf("invisible character %U", ch))
+		}
+	}
+	trimmed := strings.TrimRightFunc(content, unicode.IsSpace)
+	if len(trimmed) < len(content) && trimmed != "" {
+		r.add(SeverityWarning, FindingTrailingSpace, len(trimmed), "content ends with white space")
+	}
+}
+
+// inspectURL checks content that looks like a HTTP URL.
+func (r *Report) inspectURL(content string) {
+	lower := strings.ToLower(content)
+	if !strings.HasPrefix(lower, "http://") && !strings.HasPrefix(lower, "https://") {
+		return
+	}
+	u, err := url.Parse(content)
+	if err != nil {
+		r.add(SeverityWarning, FindingURLInvalid, -1, fmt.Sprintf("invalid URL: %v", err))
+		return
+	}
+	hostname := u.Hostname()
+	if hostname == "" {
+		r.add(SeverityWarning, FindingURLNoHost, -1, "URL without host name")
+		return
+	}
+	if strings.EqualFold(u.Scheme, "http") {
+		r.add(SeverityInfo, FindingURLInsecure, 0, "URL does not use HTTPS")
+	}
+	hostPos := strings.Index(content, hostname)
+	offset := 0
+	for label := range strings.SplitSeq(hostname, ".") {
+		if mixesScripts(label) {
+			pos := -1
+			if hostPos >= 0 {
+				pos = hostPos + offset
+			}
+			r.add(SeverityWarning, FindingURLMixedScripts, pos,
+				fmt.Sprintf("host name label %q mixes scripts", label))
+		}
+		offset += len(label) + 1
+	}
+}
+
+// confusableScripts are scripts with many letters that look like letters of
+// another script.
+var confusableScripts = []*unicode.RangeTable{unicode.Latin, unicode.Greek, unicode.Cyrillic}
+
+// mixesScripts returns true, if the label contains letters of more than one
+// of the confusable scripts.
+func mixesScripts(label string) bool {
+	found := -1
+	for _, ch := range label {
+		for i, script := range confusableScripts {
+			if unicode.Is(script, ch) {
+				if found >= 0 && found != i {
+					return true
+				}
+				found = i
+				break
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package qrcode
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func findingKeys(r Report) []string {
+	result := make([]string, 0, len(r.Findings))
+	for _, f := range r.Findings {
+		result = append(result, fmt.Sprintf("%s@%d", f.Key, f.Pos))
+	}
+	return result
+}
+
+func TestInspectFindings(t *testing.T) {
+	testcases := []struct {
+		name    string
+		content string
+		exp     []string
+	}{
+		{"clean", "HELLO WORLD", []string{}},
+		{"empty", "", []string{"empty@-1"}},
+		{"invalid utf8", "ab\xffc", []string{"invalid-utf8@2"}},
+		{"bom", "\uFEFFabc", []string{"byte-order-mark@0"}},
+		{"control", "a\x00b\x1bc\x7f", []string{"control-character@1", "control-character@3", "control-character@5"}},
+		{"newline and tab", "a\tb\r\nc", []string{}},
+		{"non-printable", "a\u200Bb", []string{"non-printable@1"}},
+		{"padding", "  abc \n", []string{"leading-space@0", "trailing-space@5"}},
+		{"url", "https://example.com/path?q=1", []string{}},
+		{"url insecure", "http://example.com", []string{"url-insecure@0"}},
+		{"url invalid", "https://exa mple.com/", []string{"url-invalid@-1"}},
+		{"url no host", "https:///path", []string{"url-no-host@-1"}},
+		{"url mixed scripts", "https://www.p\u0430ypal.com/", []string{"url-mixed-scripts@12"}},
+		{"url single script", "https://пример.рф/", []string{}},
+		{"other scheme", "mailto:a@b", []string{}},
+		{"too long", strings.Repeat("a", 3000), []string{"too-long@-1"}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := findingKeys(Inspect(tc.content)); !slices.Equal(got, tc.exp) {
+				t.Errorf("%q expected, got %q", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestInspectSeverity(t *testing.T) {
+	r := Inspect("http://example.com")
+	if r.HasWarnings() || r.HasErrors() {
+		t.Errorf("insecure URL is only an info: %v", r.Findings)
+	}
+	r = Inspect(" x")
+	if !r.HasWarnings() || r.HasErrors() {
+		t.Errorf("leading space is a warning: %v", r.Findings)
+	}
+	r = Inspect("")
+	if !r.HasErrors() {
+		t.Errorf("empty content is an error: %v", r.Findings)
+	}
+}
+
+func TestInspectCapacity(t *testing.T) {
+	r := Inspect("HELLO WORLD")
+	var versions []int
+	for _, lc := range r.Levels {
+		versions = append(versions, lc.Version)
+	}
+	if exp := []int{1, 1, 1, 2}; !slices.Equal(versions, exp) {
+		t.Errorf("versions %v expected, got %v", exp, versions)
+	}
+	if level, ok := r.SuggestLevel(1); !ok || level != High {
+		t.Errorf("level High expected for version 1, got %v/%v", level, ok)
+	}
+	if level, ok := r.SuggestLevel(2); !ok || level != Highest {
+		t.Errorf("level Highest expected for version 2, got %v/%v", level, ok)
+	}
+
+	// Fits only into a large version at level Low.
+	r = Inspect(strings.Repeat("a", 2900))
+	if !r.Levels[Low].Fits() || r.Levels[Medium].Fits() || r.HasErrors() {
+		t.Errorf("only level Low must fit: %v, %v", r.Levels, r.Findings)
+	}
+	if _, ok := r.SuggestLevel(10); ok {
+		t.Error("content must not fit into version 10")
+	}
+	for _, lc := range Inspect(strings.Repeat("a", 3000)).Levels {
+		if lc.Fits() {
+			t.Errorf("content must not fit at level %v", lc.Level)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package bitset
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// MaxSerializedBits is the maximum number of bits of a serialized Bitset
+// that is accepted when reading it.
+const MaxSerializedBits = 1 << 24
+
+// errInvalidLength signals a serialized Bitset with an invalid number of
+// bits.
+var errInvalidLength = errors.New("bitset: invalid length")
+
+// NewFromBytes returns a Bitset that consists of the first numBits bits of
+// data, most significant bit first. data is copied; bits beyond numBits are
+// ignored.
+//
+// The function panics if data contains less than numBits bits.
+func NewFromBytes(data []byte, numBits int) *Bitset {
+	if numBits < 0 || numBits > 8*len(data) {
+		panic(fmt.Sprintf("numBits %d out of range 0-%d", numBits, 8*len(data)))
+	}
+	bits := make([]byte, (numBits+7)/8)
+	copy(bits, data)
+	if rest := numBits % 8; rest != 0 {
+		bits[len(bits)-1] &= 0xff << (8 - rest)
+	}
+	return &Bitset{numBits: numBits, bits: bits}
+}
+
+// Bytes returns the packed bits, most significant bit first. The last byte
+// is padded with zero bits. Together with [Bitset.Len], the result can be
+// passed to [NewFromBytes].
+func (b *Bitset) Bytes() []byte {
+	return NewFromBytes(b.bits, b.numBits).bits
+}
+
+// WriteTo writes the Bitset to w: the number of bits as an unsigned varint,
+// followed by the packed bits, see [Bitset.Bytes].
+func (b *Bitset) WriteTo(w io.Writer) (int64, error) {
+	buf := binary.AppendUvarint(nil, uint64(b.numBits))
+	buf = append(buf, b.Bytes()...)
+	n, err := w.Write(buf)
+	return int64(n), err
+}
+
+// ReadFrom replaces the content of the Bitset by a Bitset read from r, as
+// written by [Bitset.WriteTo]. It reads no more bytes than needed, so that
+// further data can be read from r afterwards.
+func (b *Bitset) ReadFrom(r io.Reader) (int64, error) {
+	cr := countingByteReader{r: r}
+	numBits, err := binary.ReadUvarint(&cr)
+	if err != nil {
+		if err == io.EOF && cr.n > 0 {
+			err = io.ErrUnexpectedEOF
+		}
+		return cr.n, err
+	}
+	if numBits > MaxSerializedBits {
+		return cr.n, fmt.Errorf("%w: %d bits", errInvalidLength, numBits)
+	}
+	data := make([]byte, (numBits+7)/8)
+	for i := range data {
+		if data[i], err = cr.ReadByte(); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return cr.n, err
+		}
+	}
+	*b = *NewFromBytes(data, int(numBits))
+	return cr.n, nil
+}
+
+// countingByteReader reads single bytes from a reader and counts them.
+type countingByteReader struct {
+	r   io.Reader
+	n   int64
+	buf [1]byte
+}
+
+func (cr *countingByteReader) ReadByte() (byte, error) {
+	if br, ok := cr.r.(io.ByteReader); ok {
+		c, err := br.ReadByte()
+		if err == nil {
+			cr.n++
+		}
+		return c, err
+	}
+	if _, err := io.ReadFull(cr.r, cr.buf[:]); err != nil {
+		return 0, err
+	}
+	cr.n++
+	return cr.buf[0], nil
+}
+
+// MarshalText returns the Bitset as text: the number of bits, a colon, and
+// the packed bits in hexadecimal, e.g. "12:a5f0".
+func (b *Bitset) MarshalText() ([]byte, error) {
+	return []byte(strconv.Itoa(b.numBits) + ":" + hex.EncodeToString(b.Bytes())), nil
+}
+
+// UnmarshalText replaces the content of the Bitset by the text produced by
+// [Bitset.MarshalText].
+func (b *Bitset) UnmarshalText(text []byte) error {
+	length, digits, found := strings.Cut(string(text), ":")
+	if !found {
+		return errors.New("bitset: missing colon")
+	}
+	numBits, err := strconv.Atoi(length)
+	if err != nil || numBits < 0 || numBits > MaxSerializedBits {
+		return fmt.Errorf("%w: %q", errInvalidLength, length)
+	}
+	data, err := hex.DecodeString(digits)
+	if err != nil {
+		return fmt.Errorf("bitset: %w", err)
+	}
+	if len(data) != (numBits+7)/8 {
+		return fmt.Errorf("%w: %d bits with %d bytes", errInvalidLength, numBits, len(data))
+	}
+	*b = *NewFromBytes(data, numBits)
+	return nil
+}
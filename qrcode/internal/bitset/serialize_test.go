@@ -0,0 +1,151 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// This file was originally created by Tom Harwood under an MIT license, but
+// later changed to fulfil the needs of webs. The text of the original license
+// can be found in file ORIG_LICENSE. The following statements affects the
+// original code as found on https://github.com/skip2/go-qrcode (Commit:
+// da1b6568686e89143e94f980a98bc2dbd5537f13, 2020-06-17):
+//
+// go-qrcode
+// Copyright 2014 Tom Harwood
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package bitset
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+func randomBitset(rng *rand.Rand, numBits int) *Bitset {
+	b := New()
+	for range numBits {
+		b.AppendBools(rng.IntN(2) == 1)
+	}
+	return b
+}
+
+func TestNewFromBytes(t *testing.T) {
+	b := NewFromBytes([]byte{0xff, 0xff}, 11)
+	if got := b.String(); got != "numBits=11, bits= 11111111 111" {
+		t.Errorf("unexpected bitset: %s", got)
+	}
+	if got := b.Bytes(); !bytes.Equal(got, []byte{0xff, 0xe0}) {
+		t.Errorf("trailing bits must be zero, got %x", got)
+	}
+	b.AppendBools(false, true)
+	if got := b.Bytes(); !bytes.Equal(got, []byte{0xff, 0xe8}) {
+		t.Errorf("appending after masked bits failed, got %x", got)
+	}
+	if got := NewFromBytes(nil, 0); got.Len() != 0 || len(got.Bytes()) != 0 {
+		t.Errorf("empty bitset expected, got %s", got)
+	}
+}
+
+func TestBytesNoGarbage(t *testing.T) {
+	// A clone must not share storage, so that appending to it does not set
+	// bits beyond the length of the original.
+	b := NewFromBase2String("101")
+	clone := Clone(b)
+	clone.AppendBools(true, true, true)
+	if got := b.Bytes(); !bytes.Equal(got, []byte{0xa0}) {
+		t.Errorf("original changed by clone: %x", got)
+	}
+	b.AppendBools(false)
+	if got := b.String(); got != "numBits=4, bits= 1010" {
+		t.Errorf("unexpected bits after append: %s", got)
+	}
+}
+
+func TestSerializeRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewPCG(7, 11))
+	for range 300 {
+		numBits := rng.IntN(200)
+		b := randomBitset(rng, numBits)
+
+		if got := NewFromBytes(b.Bytes(), b.Len()); !got.Equals(b) {
+			t.Errorf("Bytes: %s expected, got %s", b, got)
+		}
+		if got := b.Bytes(); len(got) != (numBits+7)/8 {
+			t.Errorf("%d bytes expected, got %d", (numBits+7)/8, len(got))
+		}
+
+		var buf bytes.Buffer
+		n, err := b.WriteTo(&buf)
+		if err != nil || n != int64(buf.Len()) {
+			t.Fatalf("WriteTo: %d bytes written, %d reported (%v)", buf.Len(), n, err)
+		}
+		buf.WriteString("rest")
+		var got Bitset
+		if m, err := got.ReadFrom(iotestOneByte{&buf}); err != nil || m != n {
+			t.Errorf("ReadFrom: %d bytes expected, got %d (%v)", n, m, err)
+		}
+		if !got.Equals(b) || !bytes.Equal(got.Bytes(), b.Bytes()) {
+			t.Errorf("ReadFrom: %s expected, got %s", b, &got)
+		}
+		if buf.String() != "rest" {
+			t.Errorf("ReadFrom must not read more than needed, rest is %q", buf.String())
+		}
+
+		text, err := b.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var fromText Bitset
+		if err = fromText.UnmarshalText(text); err != nil || !fromText.Equals(b) {
+			t.Errorf("UnmarshalText(%q): %s expected, got %s (%v)", text, b, &fromText, err)
+		}
+	}
+}
+
+// iotestOneByte hides the io.ByteReader of a reader.
+type iotestOneByte struct{ r io.Reader }
+
+func (r iotestOneByte) Read(p []byte) (int, error) { return r.r.Read(p) }
+
+func TestReadFromErrors(t *testing.T) {
+	testcases := []struct {
+		name string
+		data string
+		err  error
+	}{
+		{"empty", "", io.EOF},
+		{"truncated length", "\x80", io.ErrUnexpectedEOF},
+		{"truncated data", "\x09\xff", io.ErrUnexpectedEOF},
+		{"too long", "\xff\xff\xff\xff\x0f", errInvalidLength},
+	}
+	for _, tc := range testcases {
+		var b Bitset
+		if _, err := b.ReadFrom(strings.NewReader(tc.data)); !errors.Is(err, tc.err) {
+			t.Errorf("%s: %v expected, got %v", tc.name, tc.err, err)
+		}
+	}
+}
+
+func TestMarshalText(t *testing.T) {
+	b := NewFromBase2String("1010 0101 1111")
+	text, _ := b.MarshalText()
+	if got := string(text); got != "12:a5f0" {
+		t.Errorf("12:a5f0 expected, got %q", got)
+	}
+	for _, invalid := range []string{"", "12", "x:00", "-1:", "12:a5", "12:a5f0f0", "4:zz"} {
+		var b Bitset
+		if err := b.UnmarshalText([]byte(invalid)); err == nil {
+			t.Errorf("error expected for %q", invalid)
+		}
+	}
+}
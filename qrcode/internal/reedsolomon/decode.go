@@ -0,0 +1,196 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package reedsolomon
+
+import (
+	"errors"
+
+	"t73f.de/r/webs/qrcode/internal/bitset"
+)
+
+// ErrTooManyErrors signals a block with more errors than can be corrected
+// with its error correction bytes.
+var ErrTooManyErrors = errors.New("too many errors to correct")
+
+// Decode is the inverse of [Encode]: it corrects the errors of the encoded
+// data, which ends with numECBytes error correction bytes, and returns the
+// data without them, together with the number of corrected bytes.
+//
+// An error is returned, if the encoded data is not a whole number of bytes,
+// or if it has more than numECBytes/2 erroneous bytes, see [Correct].
+func Decode(data *bitset.Bitset, numECBytes int) (*bitset.Bitset, int, error) {
+	if data.Len()%8 != 0 {
+		return nil, 0, errors.New("encoded data is not a whole number of bytes")
+	}
+	block := make([]byte, data.Len()/8)
+	for i := range block {
+		block[i] = data.ByteAt(8 * i)
+	}
+	numCorrected, err := Correct(block, numECBytes)
+	if err != nil {
+		return nil, 0, err
+	}
+	result := bitset.New()
+	result.AppendBytes(block[:len(block)-numECBytes])
+	return result, numCorrected, nil
+}
+
+// Correct corrects the errors of a block in place. The block consists of
+// data bytes, followed by numECBytes error correction bytes, as produced by
+// [Encode]. It returns the number of corrected bytes.
+//
+// Up to numECBytes/2 erroneous bytes can be corrected. If there are more,
+// [ErrTooManyErrors] is returned, and the block is left unchanged. Note that
+// a block with many more errors may be miscorrected into another valid
+// block; this cannot be detected.
+func Correct(block []byte, numECBytes int) (int, error) {
+	if numECBytes <= 0 || numECBytes > len(block) {
+		return 0, ErrTooManyErrors
+	}
+	syndromes, ok := calcSyndromes(block, numECBytes)
+	if ok {
+		return 0, nil
+	}
+
+	locator := berlekampMassey(syndromes)
+	numErrors := len(locator) - 1
+	if 2*numErrors > numECBytes {
+		return 0, ErrTooManyErrors
+	}
+
+	// Chien search: the byte at index i has power p = n-1-i. It is erroneous,
+	// if the inverse of alpha^p is a root of the error locator.
+	n := len(block)
+	positions := make([]int, 0, numErrors)
+	for i := range n {
+		p := n - 1 - i
+		if evalPoly(locator, gfExpTable[(255-p%255)%255]) == gfZero {
+			positions = append(positions, i)
+		}
+	}
+	if len(positions) != numErrors {
+		return 0, ErrTooManyErrors
+	}
+
+	// Forney: the error evaluator is S(x)*Λ(x) mod x^numECBytes. Since the
+	// generator polynomial starts with alpha^0, the error magnitude is
+	// X * Ω(X^-1) / Λ'(X^-1).
+	evaluator := multiplyPoly(syndromes, locator)[:numECBytes]
+	derivative := make([]gfElement, numErrors)
+	for i := 1; i < len(locator); i += 2 {
+		derivative[i-1] = locator[i]
+	}
+	corrected := make([]byte, n)
+	copy(corrected, block)
+	for _, i := range positions {
+		p := (n - 1 - i) % 255
+		x := gfExpTable[p]
+		xInv := gfExpTable[(255-p)%255]
+		denominator := evalPoly(derivative, xInv)
+		if denominator == gfZero {
+			return 0, ErrTooManyErrors
+		}
+		magnitude := gfMultiply(x, gfDivide(evalPoly(evaluator, xInv), denominator))
+		corrected[i] ^= byte(magnitude)
+	}
+	if _, ok = calcSyndromes(corrected, numECBytes); !ok {
+		return 0, ErrTooManyErrors
+	}
+	copy(block, corrected)
+	return numErrors, nil
+}
+
+// calcSyndromes evaluates the block, as a polynomial, at the roots of the
+// generator polynomial. It returns true, if all syndromes are zero, i.e. the
+// block has no detectable errors.
+func calcSyndromes(block []byte, numECBytes int) ([]gfElement, bool) {
+	syndromes := make([]gfElement, numECBytes)
+	ok := true
+	for j := range syndromes {
+		// Horner's method, the first byte is the highest coefficient.
+		alpha := gfExpTable[j%255]
+		var s gfElement
+		for _, b := range block {
+			s = gfAdd(gfMultiply(s, alpha), gfElement(b))
+		}
+		syndromes[j] = s
+		ok = ok && s == gfZero
+	}
+	return syndromes, ok
+}
+
+// berlekampMassey computes the error locator polynomial Λ(x) from the
+// syndromes. The polynomial is returned with its lowest coefficient first,
+// which is always one. Its degree is the number of errors.
+func berlekampMassey(syndromes []gfElement) []gfElement {
+	current := []gfElement{gfOne}  // C(x)
+	previous := []gfElement{gfOne} // B(x)
+	numErrors, shift := 0, 1
+	lastDiscrepancy := gfOne
+
+	for n, s := range syndromes {
+		discrepancy := s
+		for i := 1; i <= numErrors && i < len(current); i++ {
+			discrepancy = gfAdd(discrepancy, gfMultiply(current[i], syndromes[n-i]))
+		}
+		if discrepancy == gfZero {
+			shift++
+			continue
+		}
+
+		// C(x) = C(x) - d/b * x^shift * B(x)
+		factor := gfDivide(discrepancy, lastDiscrepancy)
+		next := make([]gfElement, max(len(current), len(previous)+shift))
+		copy(next, current)
+		for i, c := range previous {
+			next[i+shift] = gfAdd(next[i+shift], gfMultiply(factor, c))
+		}
+
+		if 2*numErrors <= n {
+			previous = current
+			numErrors = n + 1 - numErrors
+			lastDiscrepancy = discrepancy
+			shift = 1
+		} else {
+			shift++
+		}
+		current = next
+	}
+
+	// Remove trailing zero coefficients.
+	for len(current) > 1 && current[len(current)-1] == gfZero {
+		current = current[:len(current)-1]
+	}
+	return current
+}
+
+// evalPoly evaluates the polynomial, lowest coefficient first, at x.
+func evalPoly(poly []gfElement, x gfElement) gfElement {
+	var result gfElement
+	for i := len(poly) - 1; i >= 0; i-- {
+		result = gfAdd(gfMultiply(result, x), poly[i])
+	}
+	return result
+}
+
+// multiplyPoly multiplies two polynomials, lowest coefficient first.
+func multiplyPoly(a, b []gfElement) []gfElement {
+	result := make([]gfElement, len(a)+len(b)-1)
+	for i, x := range a {
+		for j, y := range b {
+			result[i+j] = gfAdd(result[i+j], gfMultiply(x, y))
+		}
+	}
+	return result
+}
@@ -0,0 +1,134 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package reedsolomon
+
+import (
+	"bytes"
+	"errors"
+	"math/rand/v2"
+	"testing"
+
+	"t73f.de/r/webs/qrcode/internal/bitset"
+)
+
+func encodeBytes(data []byte, numECBytes int) []byte {
+	b := bitset.New()
+	b.AppendBytes(data)
+	encoded := Encode(b, numECBytes)
+	result := make([]byte, encoded.Len()/8)
+	for i := range result {
+		result[i] = encoded.ByteAt(8 * i)
+	}
+	return result
+}
+
+func TestCorrect(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	for _, tc := range []struct{ numData, numEC int }{{19, 7}, {16, 10}, {9, 17}, {13, 22}, {116, 30}, {15, 30}} {
+		for range 50 {
+			data := make([]byte, tc.numData)
+			for i := range data {
+				data[i] = byte(rng.UintN(256))
+			}
+			block := encodeBytes(data, tc.numEC)
+			orig := bytes.Clone(block)
+
+			numErrors := tc.numEC / 2
+			for _, i := range rng.Perm(len(block))[:numErrors] {
+				block[i] ^= byte(1 + rng.UintN(255))
+			}
+			got, err := Correct(block, tc.numEC)
+			if err != nil {
+				t.Fatalf("%d/%d: %d errors: %v", tc.numData, tc.numEC, numErrors, err)
+			}
+			if got != numErrors {
+				t.Errorf("%d/%d: %d corrected errors expected, got %d", tc.numData, tc.numEC, numErrors, got)
+			}
+			if !bytes.Equal(block, orig) {
+				t.Errorf("%d/%d: block not corrected", tc.numData, tc.numEC)
+			}
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 5))
+	for range 200 {
+		numData, numEC := 1+rng.IntN(60), 2+rng.IntN(29)
+		data := bitset.New()
+		for range numData {
+			data.AppendByte(byte(rng.UintN(256)), 8)
+		}
+		encoded := Encode(data, numEC)
+
+		// Corrupt up to numEC/2 bytes by flipping some of their bits.
+		numErrors := rng.IntN(numEC/2 + 1)
+		corrupted := bitset.New()
+		errorPos := rng.Perm(numData + numEC)[:numErrors]
+		for i := range numData + numEC {
+			b := encoded.ByteAt(8 * i)
+			for _, pos := range errorPos {
+				if pos == i {
+					b ^= byte(1 + rng.UintN(255))
+				}
+			}
+			corrupted.AppendByte(b, 8)
+		}
+
+		got, numCorrected, err := Decode(corrupted, numEC)
+		if err != nil {
+			t.Fatalf("%d/%d with %d errors: %v", numData, numEC, numErrors, err)
+		}
+		if !got.Equals(data) {
+			t.Errorf("%d/%d with %d errors: data %v expected, got %v", numData, numEC, numErrors, data, got)
+		}
+		if numCorrected != numErrors {
+			t.Errorf("%d/%d: %d corrected bytes expected, got %d", numData, numEC, numErrors, numCorrected)
+		}
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	data := bitset.New()
+	data.AppendBytes([]byte("decode"))
+	encoded := Encode(data, 4)
+	if _, _, err := Decode(encoded.Substr(0, encoded.Len()-1), 4); err == nil {
+		t.Error("error expected for partial byte")
+	}
+	corrupted := bitset.New()
+	for i := range encoded.Len() / 8 {
+		b := encoded.ByteAt(8 * i)
+		if i < 3 {
+			b = ^b
+		}
+		corrupted.AppendByte(b, 8)
+	}
+	if _, _, err := Decode(corrupted, 4); !errors.Is(err, ErrTooManyErrors) {
+		t.Errorf("ErrTooManyErrors expected, got %v", err)
+	}
+}
+
+func TestCorrectTooManyErrors(t *testing.T) {
+	block := encodeBytes([]byte("too many errors!"), 10)
+	for i := range 6 {
+		block[2*i] ^= 0xff
+	}
+	orig := bytes.Clone(block)
+	if _, err := Correct(block, 10); !errors.Is(err, ErrTooManyErrors) {
+		t.Errorf("ErrTooManyErrors expected, got %v", err)
+	}
+	if !bytes.Equal(block, orig) {
+		t.Error("block must not be changed")
+	}
+}
@@ -20,7 +20,8 @@
 // SPDX-FileCopyrightText: 2025-present Detlef Stern
 //-----------------------------------------------------------------------------
 
-// Package reedsolomon provides error correction encoding for QR Code 2005.
+// Package reedsolomon provides error correction encoding and decoding for QR
+// Code 2005.
 //
 // QR Code 2005 uses a Reed-Solomon error correcting code to detect and correct
 // errors encountered during decoding.
@@ -0,0 +1,82 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+//go:build tools
+
+// Genvectors regenerates all generated reference vectors of the qrcode
+// package from the current implementation. Vectors from other sources are
+// left untouched. Intentional changes of the encoder are then reviewable as
+// changes of the vector file.
+//
+// Usage, from the root of the repository:
+//
+//	go run -tags tools ./qrcode/internal/tools/genvectors [qrcode/testdata/vectors.json]
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	"t73f.de/r/webs/qrcode"
+	"t73f.de/r/webs/qrcode/internal/vectors"
+)
+
+var levels = map[string]qrcode.RecoveryLevel{
+	"L": qrcode.Low,
+	"M": qrcode.Medium,
+	"Q": qrcode.High,
+	"H": qrcode.Highest,
+}
+
+func main() {
+	path := "qrcode/testdata/vectors.json"
+	if len(os.Args) > 1 {
+		path = os.Args[1]
+	}
+	if err := run(path); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(path string) error {
+	vecs, err := vectors.Load(path)
+	if err != nil {
+		return err
+	}
+	for i, vec := range vecs {
+		if vec.Source != vectors.SourceGenerated {
+			continue
+		}
+		level, found := levels[vec.Level]
+		if !found {
+			return fmt.Errorf("vector %q: unknown level %q", vec.Name, vec.Level)
+		}
+		q, err := qrcode.New(vec.Content, level)
+		if err != nil {
+			return fmt.Errorf("vector %q: %w", vec.Name, err)
+		}
+		q.DisableBorder = vec.DisableBorder
+		if vec.PadPattern != "" {
+			pattern, err := hex.DecodeString(vec.PadPattern)
+			if err != nil {
+				return fmt.Errorf("vector %q: %w", vec.Name, err)
+			}
+			q.PadFunc = qrcode.PadPattern(pattern)
+		}
+		vecs[i].Version = q.VersionNumber
+		vecs[i].Matrix = vectors.MatrixRows(q.Bitmap())
+	}
+	return vectors.Save(path, vecs)
+}
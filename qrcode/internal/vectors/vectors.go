@@ -0,0 +1,91 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+// Package vectors defines the format of reference test vectors for QR codes.
+//
+// A vector file is a JSON array of [Vector]. Vectors either come from an
+// external source, e.g. the examples of ISO/IEC 18004 or a symbol that was
+// reported to be incompatible with some scanner, or they are generated from
+// the current implementation to detect unintended changes. Only generated
+// vectors are rewritten by the generator tool.
+package vectors
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+)
+
+// SourceGenerated marks a vector that was generated from the current
+// implementation.
+const SourceGenerated = "generated"
+
+// Vector is a reference test vector.
+type Vector struct {
+	Name    string `json:"name"`
+	Source  string `json:"source"` // e.g. "ISO/IEC 18004 Annex I", or SourceGenerated
+	Content string `json:"content"`
+	Level   string `json:"level"` // "L", "M", "Q", or "H"
+
+	// Options of the QR code.
+	DisableBorder bool   `json:"disable_border,omitempty"`
+	PadPattern    string `json:"pad_pattern,omitempty"` // hex encoded
+
+	// Expected results. Empty values are not checked.
+	Version   int      `json:"version"`
+	Mask      *int     `json:"mask,omitempty"`
+	Codewords string   `json:"codewords,omitempty"` // hex encoded final codeword sequence
+	Matrix    []string `json:"matrix,omitempty"`    // rows of modules: '#' is dark, '.' is light
+}
+
+// Load the vectors from the given file.
+func Load(path string) ([]Vector, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var result []Vector
+	if err = json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Save the vectors to the given file.
+func Save(path string, vecs []Vector) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(vecs); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// MatrixRows converts a bitmap into rows of a [Vector.Matrix].
+func MatrixRows(bitmap [][]bool) []string {
+	result := make([]string, len(bitmap))
+	for y, row := range bitmap {
+		var sb strings.Builder
+		for _, module := range row {
+			if module {
+				sb.WriteByte('#')
+			} else {
+				sb.WriteByte('.')
+			}
+		}
+		result[y] = sb.String()
+	}
+	return result
+}
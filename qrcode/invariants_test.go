@@ -0,0 +1,192 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package qrcode
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"math/rand/v2"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// invariantCase is an element of the corpus of invariant tests.
+type invariantCase struct {
+	name    string
+	content string
+	opts    Options
+}
+
+func invariantCorpus() []invariantCase {
+	withOpts := func(level RecoveryLevel, fn func(*Options)) Options {
+		opts := Options{Level: level}
+		fn(&opts)
+		return opts
+	}
+	return []invariantCase{
+		{"numeric", "01234567890123456789", Options{Level: Low}},
+		{"alphanumeric", "HELLO WORLD $%*+-./:", Options{Level: Medium}},
+		{"url", "https://example.com/path?query=value#fragment", Options{Level: High}},
+		{"mixed", "ABC123abc!\"§456DEF", Options{Level: Highest}},
+		{"utf8", "Grüße aus Köln, Ελληνικά, 日本語", Options{Level: Medium}},
+		{"large", strings.Repeat("0123456789ABCDEFabcdef", 60), Options{Level: Low}},
+		{"version", "short", withOpts(Medium, func(o *Options) { o.MinVersion = 12 })},
+		{"mask", "masked", withOpts(Low, func(o *Options) { mask := 5; o.ForceMask = &mask })},
+		{"single", "ABC123abc", withOpts(Medium, func(o *Options) { o.Segmentation = SegmentationSingle })},
+		{"byte", "0123456789", withOpts(Medium, func(o *Options) { o.Segmentation = SegmentationByte })},
+		{"eci", "UTF-8 ✓", withOpts(Medium, func(o *Options) { o.ECI = 26 })},
+		{"gs1", "01095011010209171719050810ABCD1234\x1d2110", withOpts(Medium, func(o *Options) { o.GS1 = true })},
+		{"shift-jis", "\x93\xfa\x96\x7b\x8c\xea", withOpts(Low, func(o *Options) { o.ShiftJIS = true })},
+		{"border", "no border", withOpts(Low, func(o *Options) { o.DisableBorder = true; o.QuietZone = 2 })},
+	}
+}
+
+// encodeInvariantCase returns all outputs of a corpus case as one byte slice.
+func encodeInvariantCase(tc invariantCase) ([]byte, error) {
+	q, err := NewWithOptions([]byte(tc.content), tc.opts)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	for _, row := range q.Bitmap() {
+		for _, b := range row {
+			if b {
+				buf.WriteByte('1')
+			} else {
+				buf.WriteByte('0')
+			}
+		}
+		buf.WriteByte('\n')
+	}
+	buf.WriteString(q.ToSmallString(false))
+	img, err := q.PNGWithMetadata(128)
+	if err != nil {
+		return nil, err
+	}
+	buf.Write(img)
+	fmt.Fprintf(&buf, "%d %d", q.Mask(), q.PenaltyScore())
+	return buf.Bytes(), nil
+}
+
+func TestEncodeDeterministicParallel(t *testing.T) {
+	corpus := invariantCorpus()
+	exp := make([][]byte, len(corpus))
+	for i, tc := range corpus {
+		got, err := encodeInvariantCase(tc)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		exp[i] = got
+	}
+
+	workers := max(runtime.GOMAXPROCS(0), 2)
+	var mx sync.Mutex
+	var failures []string
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Go(func() {
+			rnd := rand.New(rand.NewPCG(uint64(w), 1))
+			for range 2 {
+				for _, i := range rnd.Perm(len(corpus)) {
+					got, err := encodeInvariantCase(corpus[i])
+					if err == nil && bytes.Equal(got, exp[i]) {
+						continue
+					}
+					mx.Lock()
+					failures = append(failures, fmt.Sprintf("worker %d: %s: output differs (error: %v)", w, corpus[i].name, err))
+					mx.Unlock()
+				}
+			}
+		})
+	}
+	wg.Wait()
+	for _, f := range failures {
+		t.Error(f)
+	}
+}
+
+// packageStateChecksum returns a checksum of all package-level tables that
+// are used to encode and to decode QR codes.
+func packageStateChecksum() [sha256.Size]byte {
+	h := sha256.New()
+	for _, de := range allDataEncoder {
+		fmt.Fprintln(h, de.minVersion, de.maxVersion,
+			de.numericModeIndicator, de.alphanumericModeIndicator, de.byteModeIndicator, de.kanjiModeIndicator,
+			de.numNumericCharCountBits, de.numAlphanumericCharCountBits, de.numByteCharCountBits, de.numKanjiCharCountBits,
+			de.encodeOptions, de.data, de.actual, de.optimised)
+	}
+	fmt.Fprintln(h, eciModeIndicator, fnc1FirstModeIndicator)
+	fmt.Fprintln(h, versions)
+	fmt.Fprintln(h, formatBitSequence)
+	fmt.Fprintln(h, versionBitSequence)
+	fmt.Fprintln(h, alignmentPatternCenter)
+	fmt.Fprintln(h, finderPattern, finderPatternHorizontalBorder, finderPatternVerticalBorder, alignmentPattern)
+	var result [sha256.Size]byte
+	h.Sum(result[:0])
+	return result
+}
+
+func TestNoPackageStateMutation(t *testing.T) {
+	before := packageStateChecksum()
+	check := func(name string) {
+		t.Helper()
+		if after := packageStateChecksum(); after != before {
+			t.Errorf("%s changed package-level state", name)
+			before = after
+		}
+	}
+
+	for _, tc := range invariantCorpus() {
+		if _, err := encodeInvariantCase(tc); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		check("encoding " + tc.name)
+	}
+
+	q, err := New("https://example.com/", Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = DecodeBitmap(q.Bitmap()); err != nil {
+		t.Fatal(err)
+	}
+	check("DecodeBitmap")
+	if _, err = Decode(q.Image(256)); err != nil {
+		t.Fatal(err)
+	}
+	check("Decode")
+
+	_ = Inspect(strings.Repeat("ABC", 2000))
+	check("Inspect")
+
+	if _, err = New(strings.Repeat("x", 3000), Highest); err == nil {
+		t.Error("capacity error expected")
+	}
+	check("capacity error")
+	if _, err = NewWithVersion(strings.Repeat("1", 100), Low, 1); err == nil {
+		t.Error("capacity error expected")
+	}
+	check("capacity error with version")
+
+	if _, err = NewShiftJIS("\x93\xfa\x96\x7b", Medium); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = NewGS1("0109501101020917", Medium); err != nil {
+		t.Fatal(err)
+	}
+	check("NewShiftJIS/NewGS1")
+}
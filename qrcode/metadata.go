@@ -0,0 +1,276 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package qrcode
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+// Metadata describes how a QR code was produced. It does not contain the
+// content, only its SHA-256 hash.
+type Metadata struct {
+	ContentHash   [sha256.Size]byte
+	Level         RecoveryLevel
+	Version       int
+	Mask          int
+	QuietZone     int
+	DisableBorder bool
+	Inverted      bool
+	Segmentation  Segmentation
+	ECI           int
+	GS1           bool
+	ShiftJIS      bool
+
+	// PackageVersion is the module version of this package, as recorded in
+	// the build information of the program. It is empty, if the version is
+	// not known, and it is truncated to [MaxPackageVersionLength] bytes.
+	PackageVersion string
+}
+
+// MaxPackageVersionLength is the maximum length of [Metadata.PackageVersion].
+// Together with the other fields, it bounds the length of an encoded
+// metadata string to less than 150 characters.
+const MaxPackageVersionLength = 64
+
+// ErrNoMetadata signals a PNG image without embedded metadata, see
+// [ReadPNGMetadata].
+var ErrNoMetadata = errors.New("no QR code metadata found")
+
+// ErrInvalidMetadata signals an encoded metadata string that cannot be
+// parsed, see [ParseMetadata].
+var ErrInvalidMetadata = errors.New("invalid QR code metadata")
+
+// Metadata returns the metadata of the QR code. The symbol is encoded, if
+// this was not done before, to determine the mask pattern.
+func (q *QRCode) Metadata() Metadata {
+	q.encode()
+	opts := q.encoder.encodeOptions
+	return Metadata{
+		ContentHash:    sha256.Sum256([]byte(q.content)),
+		Level:          q.recoveryLevel,
+		Version:        q.VersionNumber,
+		Mask:           q.mask,
+		QuietZone:      q.quietZone,
+		DisableBorder:  q.DisableBorder,
+		Inverted:       q.Inverted,
+		Segmentation:   opts.segmentation,
+		ECI:            opts.eci,
+		GS1:            opts.fnc1,
+		ShiftJIS:       opts.kanji,
+		PackageVersion: packageVersion(),
+	}
+}
+
+// packageVersion returns the module version of this package, as stored in
+// the build information.
+var packageVersion = sync.OnceValue(func() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	const modulePath = "t73f.de/r/webs"
+	version := ""
+	if info.Main.Path == modulePath {
+		version = info.Main.Version
+	} else {
+		for _, dep := range info.Deps {
+			if dep.Path == modulePath {
+				if dep.Replace != nil {
+					dep = dep.Replace
+				}
+				version = dep.Version
+				break
+			}
+		}
+	}
+	if version == "(devel)" {
+		return ""
+	}
+	return truncateVersion(version)
+})
+
+func truncateVersion(version string) string {
+	if len(version) > MaxPackageVersionLength {
+		return version[:MaxPackageVersionLength]
+	}
+	return version
+}
+
+// metadataFormat is the version of the binary format of encoded metadata.
+const metadataFormat = 1
+
+// Flags of the encoded metadata.
+const (
+	metaDisableBorder = 1 << iota
+	metaInverted
+	metaGS1
+	metaShiftJIS
+)
+
+// Encode returns the metadata as a compact string, which is safe to use in
+// file names and URLs: it consists only of letters, digits, "-", and "_".
+func (md Metadata) Encode() string {
+	var flags byte
+	if md.DisableBorder {
+		flags |= metaDisableBorder
+	}
+	if md.Inverted {
+		flags |= metaInverted
+	}
+	if md.GS1 {
+		flags |= metaGS1
+	}
+	if md.ShiftJIS {
+		flags |= metaShiftJIS
+	}
+	version := truncateVersion(md.PackageVersion)
+
+	buf := make([]byte, 0, 1+sha256.Size+6+3*binary.MaxVarintLen32+len(version))
+	buf = append(buf, metadataFormat)
+	buf = append(buf, md.ContentHash[:]...)
+	buf = append(buf, byte(md.Level), byte(md.Version), byte(md.Mask), byte(md.Segmentation), flags)
+	buf = binary.AppendUvarint(buf, uint64(md.QuietZone))
+	buf = binary.AppendUvarint(buf, uint64(md.ECI))
+	buf = append(buf, byte(len(version)))
+	buf = append(buf, version...)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// ParseMetadata parses a string produced by [Metadata.Encode]. An error
+// wrapping [ErrInvalidMetadata] is returned, if the string is malformed or
+// if it contains invalid values.
+func ParseMetadata(s string) (Metadata, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Metadata{}, fmt.Errorf("%w: %w", ErrInvalidMetadata, err)
+	}
+	const fixedLen = 1 + sha256.Size + 5
+	if len(buf) < fixedLen {
+		return Metadata{}, fmt.Errorf("%w: too short", ErrInvalidMetadata)
+	}
+	if buf[0] != metadataFormat {
+		return Metadata{}, fmt.Errorf("%w: unknown format %d", ErrInvalidMetadata, buf[0])
+	}
+	var md Metadata
+	copy(md.ContentHash[:], buf[1:1+sha256.Size])
+	pos := 1 + sha256.Size
+	md.Level = RecoveryLevel(buf[pos])
+	md.Version = int(buf[pos+1])
+	md.Mask = int(buf[pos+2])
+	md.Segmentation = Segmentation(buf[pos+3])
+	flags := buf[pos+4]
+	md.DisableBorder = flags&metaDisableBorder != 0
+	md.Inverted = flags&metaInverted != 0
+	md.GS1 = flags&metaGS1 != 0
+	md.ShiftJIS = flags&metaShiftJIS != 0
+	buf = buf[fixedLen:]
+
+	quietZone, n := binary.Uvarint(buf)
+	if n <= 0 || quietZone > 1<<16 {
+		return Metadata{}, fmt.Errorf("%w: quiet zone", ErrInvalidMetadata)
+	}
+	md.QuietZone = int(quietZone)
+	buf = buf[n:]
+	eci, n := binary.Uvarint(buf)
+	if n <= 0 || eci > MaxECI {
+		return Metadata{}, fmt.Errorf("%w: ECI", ErrInvalidMetadata)
+	}
+	md.ECI = int(eci)
+	buf = buf[n:]
+
+	if len(buf) == 0 || int(buf[0]) != len(buf)-1 || len(buf)-1 > MaxPackageVersionLength {
+		return Metadata{}, fmt.Errorf("%w: package version", ErrInvalidMetadata)
+	}
+	md.PackageVersion = string(buf[1:])
+
+	if md.Level < Low || md.Level > Highest {
+		return Metadata{}, fmt.Errorf("%w: %w: %d", ErrInvalidMetadata, ErrInvalidLevel, md.Level)
+	}
+	if md.Version < MinVersion || md.Version > MaxVersion {
+		return Metadata{}, fmt.Errorf("%w: %w: %d", ErrInvalidMetadata, ErrInvalidVersion, md.Version)
+	}
+	if md.Mask > 7 {
+		return Metadata{}, fmt.Errorf("%w: mask %d", ErrInvalidMetadata, md.Mask)
+	}
+	if md.Segmentation > SegmentationByte {
+		return Metadata{}, fmt.Errorf("%w: segmentation %d", ErrInvalidMetadata, md.Segmentation)
+	}
+	return md, nil
+}
+
+// pngMetadataKeyword is the keyword of the "tEXt" chunk that stores the
+// encoded metadata.
+const pngMetadataKeyword = "QRCodeMetadata"
+
+// PNGWithMetadata returns the QR code as a PNG image, like [QRCode.PNG],
+// with the encoded metadata stored in a "tEXt" chunk with the keyword
+// "QRCodeMetadata". Standard tools, e.g. exiftool or pngcheck, show it as a
+// textual property of the image. See [ReadPNGMetadata] to extract it.
+func (q *QRCode) PNGWithMetadata(size int) ([]byte, error) {
+	img, err := q.PNG(size)
+	if err != nil {
+		return nil, err
+	}
+	return embedPNGText(img, pngMetadataKeyword, q.Metadata().Encode())
+}
+
+// embedPNGText inserts a "tEXt" chunk directly after the "IHDR" chunk of the
+// PNG image.
+func embedPNGText(img []byte, keyword, text string) ([]byte, error) {
+	chunks, err := pngChunks(img)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	b.Grow(len(img) + len(keyword) + len(text) + 13)
+	b.Write(pngSignature)
+	for _, ch := range chunks {
+		writePNGChunk(&b, ch.typ, ch.data)
+		if ch.typ == "IHDR" {
+			data := make([]byte, 0, len(keyword)+1+len(text))
+			data = append(data, keyword...)
+			data = append(data, 0)
+			data = append(data, text...)
+			writePNGChunk(&b, "tEXt", data)
+		}
+	}
+	return b.Bytes(), nil
+}
+
+// ReadPNGMetadata extracts the metadata of a PNG image produced by
+// [QRCode.PNGWithMetadata]. If the image contains no metadata, an error
+// wrapping [ErrNoMetadata] is returned.
+func ReadPNGMetadata(img []byte) (Metadata, error) {
+	chunks, err := pngChunks(img)
+	if err != nil {
+		return Metadata{}, err
+	}
+	for _, ch := range chunks {
+		if ch.typ != "tEXt" {
+			continue
+		}
+		keyword, text, found := bytes.Cut(ch.data, []byte{0})
+		if found && string(keyword) == pngMetadataKeyword {
+			return ParseMetadata(string(text))
+		}
+	}
+	return Metadata{}, ErrNoMetadata
+}
@@ -0,0 +1,122 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package qrcode
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"image/png"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestMetadataEncode(t *testing.T) {
+	mask := 5
+	q, err := NewWithOptions([]byte("https://example.com/secret"), Options{
+		Level:        High,
+		QuietZone:    2,
+		ForceMask:    &mask,
+		Segmentation: SegmentationByte,
+		ECI:          26,
+		Inverted:     true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	md := q.Metadata()
+	if md.ContentHash != sha256.Sum256([]byte("https://example.com/secret")) {
+		t.Error("wrong content hash")
+	}
+	if md.Level != High || md.Version != q.VersionNumber || md.Mask != mask || md.QuietZone != 2 ||
+		md.Segmentation != SegmentationByte || md.ECI != 26 || !md.Inverted || md.DisableBorder {
+		t.Errorf("wrong metadata: %+v", md)
+	}
+
+	md.PackageVersion = "v1.2.3"
+	s := md.Encode()
+	if !regexp.MustCompile(`^[A-Za-z0-9_-]+$`).MatchString(s) {
+		t.Errorf("encoding %q is not filename-safe", s)
+	}
+	if strings.Contains(s, "secret") {
+		t.Errorf("encoding %q must not contain the content", s)
+	}
+	got, err := ParseMetadata(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != md {
+		t.Errorf("%+v expected, got %+v", md, got)
+	}
+
+	md.PackageVersion = strings.Repeat("v", 2*MaxPackageVersionLength)
+	s = md.Encode()
+	if len(s) >= 150 {
+		t.Errorf("encoding too long: %d characters", len(s))
+	}
+	if got, err = ParseMetadata(s); err != nil || len(got.PackageVersion) != MaxPackageVersionLength {
+		t.Errorf("truncated package version expected, got %q/%v", got.PackageVersion, err)
+	}
+}
+
+func TestParseMetadataInvalid(t *testing.T) {
+	q, err := New("metadata", Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := q.Metadata().Encode()
+	invalidVersion := q.Metadata()
+	invalidVersion.Version = 41
+	for _, s := range []string{"", "!!!", valid[:20], valid + "AA", invalidVersion.Encode()} {
+		if _, err := ParseMetadata(s); !errors.Is(err, ErrInvalidMetadata) {
+			t.Errorf("%q: ErrInvalidMetadata expected, got %v", s, err)
+		}
+	}
+}
+
+func TestPNGMetadata(t *testing.T) {
+	q, err := New("metadata", Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := q.PNGWithMetadata(-2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = png.Decode(bytes.NewReader(img)); err != nil {
+		t.Fatalf("invalid PNG: %v", err)
+	}
+	if !bytes.Contains(img, []byte("tEXtQRCodeMetadata\x00")) {
+		t.Error("tEXt chunk expected")
+	}
+	md, err := ReadPNGMetadata(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := q.Metadata(); md != exp {
+		t.Errorf("%+v expected, got %+v", exp, md)
+	}
+
+	foreign, err := q.PNG(-2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ReadPNGMetadata(foreign); !errors.Is(err, ErrNoMetadata) {
+		t.Errorf("ErrNoMetadata expected, got %v", err)
+	}
+	if _, err = ReadPNGMetadata([]byte("GIF89a")); err == nil {
+		t.Error("error expected for non-PNG data")
+	}
+}
@@ -0,0 +1,75 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package qrcode
+
+import "slices"
+
+// ModuleType classifies a module of a QR code by its function. External
+// renderers use it to decide which modules may be stylized, e.g. drawn as
+// rounded dots, or dropped, e.g. for a logo, relying on error correction.
+type ModuleType uint8
+
+// Constants for ModuleType.
+const (
+	ModuleQuietZone   ModuleType = iota // border around the symbol
+	ModuleFinder                        // one of the three finder patterns
+	ModuleSeparator                     // light border of a finder pattern
+	ModuleAlignment                     // alignment pattern
+	ModuleTiming                        // horizontal or vertical timing pattern
+	ModuleFormatInfo                    // format information, including the dark module
+	ModuleVersionInfo                   // version information (version 7 and above)
+	ModuleData                          // data and error correction codewords, and remainder bits
+)
+
+func (mt ModuleType) String() string {
+	switch mt {
+	case ModuleQuietZone:
+		return "quiet-zone"
+	case ModuleFinder:
+		return "finder"
+	case ModuleSeparator:
+		return "separator"
+	case ModuleAlignment:
+		return "alignment"
+	case ModuleTiming:
+		return "timing"
+	case ModuleFormatInfo:
+		return "format-info"
+	case ModuleVersionInfo:
+		return "version-info"
+	case ModuleData:
+		return "data"
+	}
+	return "unknown"
+}
+
+// IsFunctionPattern returns true, if the module belongs to a pattern that is
+// needed to locate and decode the symbol. Such modules are not covered by
+// error correction and should be drawn unchanged.
+func (mt ModuleType) IsFunctionPattern() bool {
+	return mt != ModuleQuietZone && mt != ModuleData
+}
+
+// Modules returns the type of each module of the QR code.
+//
+// modules[y][x] is the type of the module at (x, y), in the same coordinates
+// as [QRCode.Bitmap], i.e. including the quiet zone.
+func (q *QRCode) Modules() [][]ModuleType {
+	q.encode()
+	result := make([][]ModuleType, len(q.symbol.moduleType))
+	for y, row := range q.symbol.moduleType {
+		result[y] = slices.Clone(row)
+	}
+	return result
+}
@@ -0,0 +1,98 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package qrcode
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestModulesCounts(t *testing.T) {
+	testcases := []struct {
+		version int
+		exp     map[ModuleType]int
+	}{
+		{2, map[ModuleType]int{
+			ModuleQuietZone:  33*33 - 25*25,
+			ModuleFinder:     3 * 7 * 7,
+			ModuleSeparator:  3 * 15,
+			ModuleAlignment:  1 * 5 * 5,
+			ModuleTiming:     2 * 9,
+			ModuleFormatInfo: 2*15 + 1,
+			ModuleData:       44*8 + 7,
+		}},
+		{7, map[ModuleType]int{
+			ModuleQuietZone:   53*53 - 45*45,
+			ModuleFinder:      3 * 7 * 7,
+			ModuleSeparator:   3 * 15,
+			ModuleAlignment:   6 * 5 * 5,
+			ModuleTiming:      2*29 - 2*5, // two alignment patterns cross the timing patterns
+			ModuleFormatInfo:  2*15 + 1,
+			ModuleVersionInfo: 2 * 18,
+			ModuleData:        196 * 8,
+		}},
+	}
+	for _, tc := range testcases {
+		q, err := NewWithVersion("webs", Medium, tc.version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		modules := q.Modules()
+		bitmap := q.Bitmap()
+		if len(modules) != len(bitmap) {
+			t.Errorf("version %d: %d rows expected, got %d", tc.version, len(bitmap), len(modules))
+		}
+		got := map[ModuleType]int{}
+		for y, row := range modules {
+			if len(row) != len(bitmap[y]) {
+				t.Errorf("version %d: row %d: %d columns expected, got %d", tc.version, y, len(bitmap[y]), len(row))
+			}
+			for _, mt := range row {
+				got[mt]++
+			}
+		}
+		if !maps.Equal(got, tc.exp) {
+			t.Errorf("version %d:\nexpected %v\nbut got  %v", tc.version, tc.exp, got)
+		}
+	}
+}
+
+func TestModulesFunctionPatterns(t *testing.T) {
+	q, err := NewWithVersion("webs", Medium, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	qz := q.quietZone
+	modules := q.Modules()
+	bitmap := q.Bitmap()
+
+	// Modules of the finder patterns and timing patterns have fixed values.
+	if mt := modules[qz][qz]; mt != ModuleFinder || !bitmap[qz][qz] {
+		t.Errorf("dark finder module expected at top left corner, got %v/%v", mt, bitmap[qz][qz])
+	}
+	if mt := modules[qz+7][qz+7]; mt != ModuleSeparator || bitmap[qz+7][qz+7] {
+		t.Errorf("light separator module expected, got %v/%v", mt, bitmap[qz+7][qz+7])
+	}
+	for i := 8; i < 25-8; i++ {
+		if mt := modules[qz+6][qz+i]; mt != ModuleTiming || bitmap[qz+6][qz+i] != (i%2 == 0) {
+			t.Errorf("timing module expected at (%d,6), got %v/%v", i, mt, bitmap[qz+6][qz+i])
+		}
+	}
+	if mt := modules[qz+18][qz+18]; mt != ModuleAlignment {
+		t.Errorf("alignment module expected at center, got %v", mt)
+	}
+	if mt := modules[qz+25-8][qz+8]; mt != ModuleFormatInfo || !bitmap[qz+25-8][qz+8] {
+		t.Errorf("dark module expected, got %v/%v", mt, bitmap[qz+25-8][qz+8])
+	}
+}
@@ -0,0 +1,171 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package qrcode
+
+import (
+	"fmt"
+	"image/color"
+)
+
+// Segmentation specifies how the content is split into segments of
+// different data modes.
+type Segmentation uint8
+
+// Values for Segmentation.
+const (
+	// SegmentationOptimal splits the content into segments with the shortest
+	// encoded length.
+	SegmentationOptimal Segmentation = iota
+
+	// SegmentationSingle encodes the content as a single segment, with the
+	// lowest data mode that is able to encode all of the content.
+	SegmentationSingle
+
+	// SegmentationByte encodes the content as a single segment in byte mode.
+	SegmentationByte
+)
+
+// Default values of [Options].
+const (
+	DefaultQuietZone = 4 // quiet zone required by the specification
+	MinVersion       = 1
+	MaxVersion       = 40
+	MaxECI           = 999999
+)
+
+// Options specify how a QR code is constructed, see [NewWithOptions]. The
+// zero value is valid and results in the same QR code as [New] with level
+// [Low].
+type Options struct {
+	// Level is the error recovery level.
+	Level RecoveryLevel
+
+	// MinVersion and MaxVersion restrict the version of the QR code. The
+	// smallest version in this range that is able to store the content is
+	// used. A zero value means [MinVersion] and [MaxVersion], respectively.
+	MinVersion int
+	MaxVersion int
+
+	// QuietZone is the size of the border in modules. If zero,
+	// [DefaultQuietZone] is used. Use DisableBorder for no border.
+	QuietZone int
+
+	// DisableBorder omits the border, see [QRCode.DisableBorder].
+	DisableBorder bool
+
+	// ForceMask, if not nil, specifies the mask pattern (0-7). Otherwise the
+	// mask pattern with the lowest penalty score is used.
+	ForceMask *int
+
+	// Segmentation specifies how the content is split into segments.
+	Segmentation Segmentation
+
+	// ECI, if positive, is the ECI assignment number of the character set
+	// of the content, e.g. 26 for UTF-8. An ECI header is prepended to the
+	// data.
+	ECI int
+
+	// GS1 encodes the content as GS1 data, see [NewGS1].
+	GS1 bool
+
+	// ShiftJIS encodes kanji characters of Shift JIS content in kanji mode,
+	// see [NewShiftJIS].
+	ShiftJIS bool
+
+	// ForegroundColor and BackgroundColor are the colors of the QR code. If
+	// nil, black and white are used.
+	ForegroundColor color.Color
+	BackgroundColor color.Color
+
+	// Inverted, Strict, and PadFunc initialize the fields of the same name
+	// of [QRCode].
+	Inverted bool
+	Strict   bool
+	PadFunc  func(i int) byte
+}
+
+// Validate checks the options. It returns an error wrapping
+// [ErrInvalidLevel], [ErrInvalidVersion], or [ErrInvalidOptions].
+func (o *Options) Validate() error {
+	if o.Level < Low || o.Level > Highest {
+		return fmt.Errorf("%w: %d", ErrInvalidLevel, o.Level)
+	}
+	if o.MinVersion < 0 || o.MinVersion > MaxVersion {
+		return fmt.Errorf("%w: minimum %d", ErrInvalidVersion, o.MinVersion)
+	}
+	if o.MaxVersion < 0 || o.MaxVersion > MaxVersion {
+		return fmt.Errorf("%w: maximum %d", ErrInvalidVersion, o.MaxVersion)
+	}
+	if o.MaxVersion > 0 && o.MinVersion > o.MaxVersion {
+		return fmt.Errorf("%w: minimum %d greater than maximum %d", ErrInvalidVersion, o.MinVersion, o.MaxVersion)
+	}
+	if o.QuietZone < 0 {
+		return fmt.Errorf("%w: negative quiet zone %d", ErrInvalidOptions, o.QuietZone)
+	}
+	if o.ForceMask != nil && (*o.ForceMask < 0 || *o.ForceMask > 7) {
+		return fmt.Errorf("%w: mask %d not in range 0-7", ErrInvalidOptions, *o.ForceMask)
+	}
+	if o.Segmentation > SegmentationByte {
+		return fmt.Errorf("%w: unknown segmentation %d", ErrInvalidOptions, o.Segmentation)
+	}
+	if o.ECI < 0 || o.ECI > MaxECI {
+		return fmt.Errorf("%w: ECI %d not in range 0-%d", ErrInvalidOptions, o.ECI, MaxECI)
+	}
+	return nil
+}
+
+// withDefaults returns the options with all zero values replaced by their
+// defaults. The options must be valid.
+func (o Options) withDefaults() Options {
+	if o.MinVersion == 0 {
+		o.MinVersion = MinVersion
+	}
+	if o.MaxVersion == 0 {
+		o.MaxVersion = MaxVersion
+	}
+	if o.QuietZone == 0 {
+		o.QuietZone = DefaultQuietZone
+	}
+	if o.ForegroundColor == nil {
+		o.ForegroundColor = color.Black
+	}
+	if o.BackgroundColor == nil {
+		o.BackgroundColor = color.White
+	}
+	return o
+}
+
+// encodeOptions returns the options of the data encoder.
+func (o *Options) encodeOptions() encodeOptions {
+	return encodeOptions{
+		kanji:        o.ShiftJIS,
+		fnc1:         o.GS1,
+		eci:          o.ECI,
+		segmentation: o.Segmentation,
+	}
+}
+
+// NewWithOptions constructs a QRCode as specified by the options. It is the
+// canonical constructor; all other constructors are shortcuts for specific
+// options.
+//
+// An error occurs if the options are not valid (see [Options.Validate]), if
+// the content is empty ([ErrEmptyContent]), or if the content is too long
+// ([ErrContentTooLong], as a [*CapacityError]).
+func NewWithOptions(content []byte, opts Options) (*QRCode, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	return newQRCode(string(content), opts.withDefaults())
+}
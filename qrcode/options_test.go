@@ -0,0 +1,216 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package qrcode
+
+import (
+	"errors"
+	"image/color"
+	"slices"
+	"strings"
+	"testing"
+
+	"t73f.de/r/webs/qrcode/internal/bitset"
+)
+
+func intPtr(i int) *int { return &i }
+
+func TestOptionsValidate(t *testing.T) {
+	testCases := []struct {
+		opts Options
+		exp  error
+	}{
+		{Options{}, nil},
+		{Options{Level: Highest, MinVersion: 3, MaxVersion: 3, ForceMask: intPtr(7), ECI: MaxECI}, nil},
+		{Options{Level: Highest + 1}, ErrInvalidLevel},
+		{Options{MinVersion: -1}, ErrInvalidVersion},
+		{Options{MaxVersion: 41}, ErrInvalidVersion},
+		{Options{MinVersion: 5, MaxVersion: 4}, ErrInvalidVersion},
+		{Options{QuietZone: -1}, ErrInvalidOptions},
+		{Options{ForceMask: intPtr(8)}, ErrInvalidOptions},
+		{Options{ForceMask: intPtr(-1)}, ErrInvalidOptions},
+		{Options{Segmentation: SegmentationByte + 1}, ErrInvalidOptions},
+		{Options{ECI: MaxECI + 1}, ErrInvalidOptions},
+	}
+	for i, tc := range testCases {
+		err := tc.opts.Validate()
+		if tc.exp == nil && err != nil || !errors.Is(err, tc.exp) {
+			t.Errorf("%d: %v expected, got %v", i, tc.exp, err)
+		}
+		if _, err = NewWithOptions([]byte("content"), tc.opts); tc.exp != nil && !errors.Is(err, tc.exp) {
+			t.Errorf("%d: NewWithOptions must validate, got %v", i, err)
+		}
+	}
+}
+
+func TestOptionsDefaults(t *testing.T) {
+	q, err := New("https://example.org", Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	qo, err := NewWithOptions([]byte("https://example.org"), Options{Level: Medium})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.EqualFunc(q.Bitmap(), qo.Bitmap(), slices.Equal) {
+		t.Error("New and NewWithOptions differ")
+	}
+	if qo.ForegroundColor != color.Black || qo.BackgroundColor != color.White || qo.quietZone != DefaultQuietZone {
+		t.Errorf("unexpected defaults %v %v %d", qo.ForegroundColor, qo.BackgroundColor, qo.quietZone)
+	}
+}
+
+func TestOptionsVersions(t *testing.T) {
+	content := []byte("HELLO WORLD")
+	q, err := NewWithOptions(content, Options{MinVersion: 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.VersionNumber != 12 {
+		t.Errorf("version 12 expected, got %d", q.VersionNumber)
+	}
+
+	_, err = NewWithOptions([]byte(strings.Repeat("A", 100)), Options{MaxVersion: 2})
+	var ce *CapacityError
+	if !errors.As(err, &ce) {
+		t.Fatalf("expected CapacityError, but got %v", err)
+	}
+	if ce.Version != 2 || ce.MaxCapacity != 47 {
+		t.Errorf("capacity 47 at version 2 expected, got %d at %d", ce.MaxCapacity, ce.Version)
+	}
+
+	q, err = NewWithOptions([]byte(strings.Repeat("A", 100)), Options{MinVersion: 3, MaxVersion: 9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.VersionNumber != 4 {
+		t.Errorf("version 4 expected, got %d", q.VersionNumber)
+	}
+}
+
+func TestOptionsRendering(t *testing.T) {
+	opts := Options{
+		QuietZone:       1,
+		ForceMask:       intPtr(5),
+		ForegroundColor: color.RGBA{0, 0, 0x80, 0xff},
+		BackgroundColor: color.RGBA{0xff, 0xff, 0xe0, 0xff},
+		Inverted:        true,
+		Strict:          true,
+		PadFunc:         PadPattern([]byte{0}),
+	}
+	q, err := NewWithOptions([]byte("https://example.org"), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	symbolSize := q.version.symbolSize()
+	if got, exp := len(q.Bitmap()), symbolSize+2; got != exp {
+		t.Errorf("bitmap size %d expected, got %d", exp, got)
+	}
+	if got, exp := q.ImageSize(-1), symbolSize+2; got != exp {
+		t.Errorf("image size %d expected, got %d", exp, got)
+	}
+	if q.mask != 5 {
+		t.Errorf("mask 5 expected, got %d", q.mask)
+	}
+	if q.ForegroundColor != opts.ForegroundColor || q.BackgroundColor != opts.BackgroundColor ||
+		!q.Inverted || !q.Strict || q.PadFunc == nil {
+		t.Error("fields are not initialized from options")
+	}
+
+	opts.DisableBorder = true
+	if q, err = NewWithOptions([]byte("https://example.org"), opts); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(q.Bitmap()); got != symbolSize {
+		t.Errorf("bitmap size %d expected, got %d", symbolSize, got)
+	}
+
+	// Each forced mask results in a different symbol.
+	var bitmaps [][][]bool
+	for mask := range 8 {
+		q, err = NewWithOptions([]byte("https://example.org"), Options{ForceMask: intPtr(mask)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		bm := q.Bitmap()
+		for i, other := range bitmaps {
+			if slices.EqualFunc(bm, other, slices.Equal) {
+				t.Errorf("masks %d and %d result in the same symbol", i, mask)
+			}
+		}
+		bitmaps = append(bitmaps, bm)
+	}
+}
+
+func TestOptionsSegmentation(t *testing.T) {
+	content := []byte("0123456789ABCDEFGHIJ0123456789abc")
+	testCases := []struct {
+		segmentation Segmentation
+		exp          string
+	}{
+		{SegmentationOptimal, "[10*numeric, 10*alphanumeric, 10*numeric, 3*byte]"},
+		{SegmentationSingle, "[33*byte]"},
+		{SegmentationByte, "[33*byte]"},
+	}
+	for _, tc := range testCases {
+		q, err := NewWithOptions(content, Options{Segmentation: tc.segmentation})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := segmentsString(q.encoder.optimised); got != tc.exp {
+			t.Errorf("segmentation %d: %q expected, got %q", tc.segmentation, tc.exp, got)
+		}
+	}
+
+	q, err := NewWithOptions([]byte("0123456789ABCDEFGHIJ"), Options{Segmentation: SegmentationSingle})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := segmentsString(q.encoder.optimised), "[20*alphanumeric]"; got != exp {
+		t.Errorf("%q expected, got %q", exp, got)
+	}
+}
+
+func TestOptionsHeader(t *testing.T) {
+	testCases := []struct {
+		opts Options
+		exp  string
+	}{
+		{Options{}, ""},
+		{Options{ECI: 26}, "0111" + "00011010"},
+		{Options{ECI: 1000}, "0111" + "10" + "00001111101000"},
+		{Options{ECI: MaxECI}, "0111" + "110" + "011110100001000111111"},
+		{Options{GS1: true}, "0101"},
+		{Options{ECI: 3, GS1: true}, "0111" + "00000011" + "0101"},
+	}
+	for _, tc := range testCases {
+		opts := tc.opts
+		if got, exp := opts.encodeOptions().header(), bitset.NewFromBase2String(tc.exp); !got.Equals(exp) {
+			t.Errorf("%+v: expected header %v, got %v", tc.opts, exp, got)
+		}
+
+		q, err := NewWithOptions([]byte("HELLO"), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		plain, err := New("HELLO", Low)
+		if err != nil {
+			t.Fatal(err)
+		}
+		exp := bitset.NewFromBase2String(tc.exp)
+		exp.Append(plain.data)
+		if !q.data.Equals(exp) {
+			t.Errorf("%+v: header not prepended to data", tc.opts)
+		}
+	}
+}
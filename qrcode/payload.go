@@ -0,0 +1,279 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package qrcode
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Contact is the data of a contact, to be encoded as a vCard or a MeCard,
+// see [VCard] and [MeCard]. Only the name is required.
+type Contact struct {
+	Name     string // Formatted name of the person.
+	Org      string // Organization.
+	Title    string // Job title.
+	Phone    string // Primary phone number.
+	Phone2   string // Secondary phone number.
+	Email    string
+	URL      string
+	Address  string // Postal address, as a single line.
+	PhotoURL string // URL of a photo of the person.
+	Note     string
+}
+
+// Event is the data of a calendar event, to be encoded as an iCalendar
+// VEVENT, see [VEvent]. Summary and Start are required.
+type Event struct {
+	Summary     string
+	Start       time.Time
+	End         time.Time // Zero, if the event has no end time.
+	Location    string
+	URL         string
+	Description string
+}
+
+// payloadKind specifies the format of a payload.
+type payloadKind uint8
+
+const (
+	payloadVCard payloadKind = iota
+	payloadMeCard
+	payloadVEvent
+)
+
+// Payload is structured content of a QR code, e.g. a contact or a calendar
+// event. Its String method returns the content to be encoded. Use
+// [Payload.FitTo] to make it fit into a QR code of a given version.
+type Payload struct {
+	kind    payloadKind
+	contact Contact
+	event   Event
+}
+
+// VCard returns a payload that encodes the contact as a vCard 3.0.
+func VCard(c Contact) Payload { return Payload{kind: payloadVCard, contact: c} }
+
+// MeCard returns a payload that encodes the contact as a MeCard. A MeCard
+// has no fields for the organization, the job title, and the photo, so
+// these are ignored.
+func MeCard(c Contact) Payload { return Payload{kind: payloadMeCard, contact: c} }
+
+// VEvent returns a payload that encodes the event as an iCalendar VEVENT.
+// Times are encoded in UTC.
+func VEvent(e Event) Payload { return Payload{kind: payloadVEvent, event: e} }
+
+// Contact returns the contact of a vCard or MeCard payload.
+func (p Payload) Contact() Contact { return p.contact }
+
+// Event returns the event of a VEVENT payload.
+func (p Payload) Event() Event { return p.event }
+
+// String returns the content of the payload, which is encoded in a QR code.
+func (p Payload) String() string {
+	var sb strings.Builder
+	switch p.kind {
+	case payloadVCard:
+		c := p.contact
+		sb.WriteString("BEGIN:VCARD\r\nVERSION:3.0\r\n")
+		writeProperty(&sb, "N", escapeVCard(c.Name)+";;;;")
+		writeProperty(&sb, "FN", escapeVCard(c.Name))
+		writeProperty(&sb, "ORG", escapeVCard(c.Org))
+		writeProperty(&sb, "TITLE", escapeVCard(c.Title))
+		writeProperty(&sb, "TEL;TYPE=CELL", escapeVCard(c.Phone))
+		writeProperty(&sb, "TEL", escapeVCard(c.Phone2))
+		writeProperty(&sb, "EMAIL", escapeVCard(c.Email))
+		writeProperty(&sb, "URL", escapeVCard(c.URL))
+		if c.Address != "" {
+			writeProperty(&sb, "ADR", ";;"+escapeVCard(c.Address)+";;;;")
+		}
+		writeProperty(&sb, "PHOTO;VALUE=URI", escapeVCard(c.PhotoURL))
+		writeProperty(&sb, "NOTE", escapeVCard(c.Note))
+		sb.WriteString("END:VCARD")
+	case payloadMeCard:
+		c := p.contact
+		sb.WriteString("MECARD:")
+		for _, field := range [][2]string{
+			{"N", c.Name}, {"TEL", c.Phone}, {"TEL", c.Phone2}, {"EMAIL", c.Email},
+			{"URL", c.URL}, {"ADR", c.Address}, {"NOTE", c.Note},
+		} {
+			if field[1] != "" {
+				sb.WriteString(field[0])
+				sb.WriteByte(':')
+				sb.WriteString(escapeMeCard(field[1]))
+				sb.WriteByte(';')
+			}
+		}
+		sb.WriteByte(';')
+	case payloadVEvent:
+		e := p.event
+		sb.WriteString("BEGIN:VEVENT\r\n")
+		writeProperty(&sb, "SUMMARY", escapeVCard(e.Summary))
+		writeProperty(&sb, "DTSTART", formatEventTime(e.Start))
+		writeProperty(&sb, "DTEND", formatEventTime(e.End))
+		writeProperty(&sb, "LOCATION", escapeVCard(e.Location))
+		writeProperty(&sb, "URL", escapeVCard(e.URL))
+		writeProperty(&sb, "DESCRIPTION", escapeVCard(e.Description))
+		sb.WriteString("END:VEVENT")
+	}
+	return sb.String()
+}
+
+// writeProperty writes a property of a vCard or VEVENT, if it has a value.
+func writeProperty(sb *strings.Builder, name, value string) {
+	if value == "" {
+		return
+	}
+	sb.WriteString(name)
+	sb.WriteByte(':')
+	sb.WriteString(value)
+	sb.WriteString("\r\n")
+}
+
+var (
+	vCardEscaper  = strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\r\n", `\n`, "\n", `\n`)
+	meCardEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ":", `\:`, ",", `\,`, `"`, `\"`)
+)
+
+func escapeVCard(s string) string  { return vCardEscaper.Replace(s) }
+func escapeMeCard(s string) string { return meCardEscaper.Replace(s) }
+
+func formatEventTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// Dropped describes an optional field of a payload that was removed or
+// truncated by [Payload.FitTo].
+type Dropped struct {
+	Field     string // Name of the field, e.g. "PhotoURL".
+	Truncated bool   // The field was shortened, not removed.
+}
+
+// optionalField is a field of a payload that may be dropped, or truncated,
+// to make the payload fit.
+type optionalField struct {
+	name     string
+	truncate bool
+	meCard   bool // field is encoded in a MeCard too
+	value    func(*Payload) *string
+}
+
+// Optional fields in the order they are dropped.
+var (
+	contactFields = []optionalField{
+		{"PhotoURL", false, false, func(p *Payload) *string { return &p.contact.PhotoURL }},
+		{"Phone2", false, true, func(p *Payload) *string { return &p.contact.Phone2 }},
+		{"Note", true, true, func(p *Payload) *string { return &p.contact.Note }},
+		{"URL", false, true, func(p *Payload) *string { return &p.contact.URL }},
+		{"Address", false, true, func(p *Payload) *string { return &p.contact.Address }},
+		{"Title", false, false, func(p *Payload) *string { return &p.contact.Title }},
+		{"Org", false, false, func(p *Payload) *string { return &p.contact.Org }},
+	}
+	eventFields = []optionalField{
+		{"Description", true, false, func(p *Payload) *string { return &p.event.Description }},
+		{"URL", false, false, func(p *Payload) *string { return &p.event.URL }},
+		{"Location", false, false, func(p *Payload) *string { return &p.event.Location }},
+	}
+)
+
+// ellipsis is appended to a truncated field.
+const ellipsis = "…"
+
+// FitTo returns a payload that fits into a QR code of the given maximum
+// version and recovery level, together with the optional fields that were
+// dropped or truncated to achieve this, in this order.
+//
+// The fields of a contact are dropped in this order: PhotoURL, Phone2, Note,
+// URL, Address, Title, and Org. The note is first truncated, with an
+// ellipsis appended, and only dropped, if even its first character does not
+// fit. The name, the primary phone number, and the email address are never
+// dropped. A MeCard does not encode PhotoURL, Title, and Org, therefore
+// they are never dropped from it. The fields of an event are dropped in this
+// order: Description, which is truncated first, URL, and Location.
+//
+// The fit is checked by encoding the data, without building a symbol. If
+// the payload does not fit, even with all optional fields dropped, an error
+// wrapping [ErrContentTooLong] is returned as a [*CapacityError], together
+// with the reduced payload.
+func (p Payload) FitTo(maxVersion int, level RecoveryLevel) (Payload, []Dropped, error) {
+	if maxVersion < MinVersion || maxVersion > MaxVersion {
+		return p, nil, fmt.Errorf("%w: %d", ErrInvalidVersion, maxVersion)
+	}
+	opts := Options{Level: level, MaxVersion: maxVersion}
+	if err := opts.Validate(); err != nil {
+		return p, nil, err
+	}
+	fields := contactFields
+	if p.kind == payloadVEvent {
+		fields = eventFields
+	}
+
+	var dropped []Dropped
+	for _, of := range fields {
+		if fits, err := p.fits(opts); err != nil || fits {
+			return p, dropped, err
+		}
+		if p.kind == payloadMeCard && !of.meCard {
+			continue
+		}
+		value := of.value(&p)
+		if *value == "" {
+			continue
+		}
+		if of.truncate {
+			if truncated, ok := p.truncate(value, opts); ok {
+				*value = truncated
+				return p, append(dropped, Dropped{Field: of.name, Truncated: true}), nil
+			}
+		}
+		*value = ""
+		dropped = append(dropped, Dropped{Field: of.name})
+	}
+	_, err := NewWithOptions([]byte(p.String()), opts)
+	return p, dropped, err
+}
+
+// fits returns true, if the payload fits into a QR code with the options.
+func (p Payload) fits(opts Options) (bool, error) {
+	_, err := NewWithOptions([]byte(p.String()), opts)
+	if errors.Is(err, ErrContentTooLong) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// truncate returns the longest prefix of the value, with an ellipsis
+// appended, so that the payload fits. The value is a field of p, whose
+// current content does not fit. It is changed while searching.
+func (p *Payload) truncate(value *string, opts Options) (string, bool) {
+	runes := []rune(*value)
+	best := ""
+	lo, hi := 1, len(runes)-1
+	for lo <= hi {
+		mid := (lo + hi) / 2
+		candidate := string(runes[:mid]) + ellipsis
+		*value = candidate
+		if fits, _ := p.fits(opts); fits {
+			best, lo = candidate, mid+1
+		} else {
+			hi = mid - 1
+		}
+	}
+	return best, best != ""
+}
@@ -0,0 +1,174 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package qrcode
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func maximalContact() Contact {
+	return Contact{
+		Name:     "Dr. Alexandra Example-Mustermann",
+		Org:      "Example Research Laboratories, Inc.",
+		Title:    "Principal Investigator; Head of Department",
+		Phone:    "+49 170 1234567",
+		Phone2:   "+49 30 98765432",
+		Email:    "alexandra.example@research.example.com",
+		URL:      "https://research.example.com/people/alexandra-example",
+		Address:  "Musterstraße 123, 10115 Berlin, Germany",
+		PhotoURL: "https://research.example.com/photos/alexandra-example-portrait.jpg",
+		Note:     strings.Repeat("Available for consulting on quantum error correction. ", 4),
+	}
+}
+
+func TestPayloadString(t *testing.T) {
+	c := Contact{Name: "Doe, Jane", Phone: "+1 555 0100", Email: "jane@example.com", Note: "a;b\nc"}
+	testcases := []struct {
+		name string
+		p    Payload
+		exp  string
+	}{
+		{"vcard", VCard(c), "BEGIN:VCARD\r\nVERSION:3.0\r\nN:Doe\\, Jane;;;;\r\nFN:Doe\\, Jane\r\n" +
+			"TEL;TYPE=CELL:+1 555 0100\r\nEMAIL:jane@example.com\r\nNOTE:a\\;b\\nc\r\nEND:VCARD"},
+		{"mecard", MeCard(c), "MECARD:N:Doe\\, Jane;TEL:+1 555 0100;EMAIL:jane@example.com;NOTE:a\\;b\nc;;"},
+		{"vevent", VEvent(Event{
+			Summary: "Meeting",
+			Start:   time.Date(2025, time.March, 1, 13, 0, 0, 0, time.FixedZone("CET", 3600)),
+			End:     time.Date(2025, time.March, 1, 14, 30, 0, 0, time.UTC),
+		}), "BEGIN:VEVENT\r\nSUMMARY:Meeting\r\nDTSTART:20250301T120000Z\r\nDTEND:20250301T143000Z\r\nEND:VEVENT"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.p.String(); got != tc.exp {
+				t.Errorf("\nexp: %q\ngot: %q", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestPayloadFitTo(t *testing.T) {
+	contact := maximalContact()
+	p := VCard(contact)
+	testcases := []struct {
+		maxVersion int
+		exp        []Dropped
+	}{
+		{40, nil},
+		{20, []Dropped{{Field: "PhotoURL"}}},
+		{19, []Dropped{{Field: "PhotoURL"}, {Field: "Phone2"}}},
+		{16, []Dropped{{Field: "PhotoURL"}, {Field: "Phone2"}, {Field: "Note", Truncated: true}}},
+		{14, []Dropped{{Field: "PhotoURL"}, {Field: "Phone2"}, {Field: "Note"}, {Field: "URL"}}},
+		{10, []Dropped{{Field: "PhotoURL"}, {Field: "Phone2"}, {Field: "Note"}, {Field: "URL"},
+			{Field: "Address"}, {Field: "Title"}, {Field: "Org"}}},
+	}
+	for _, tc := range testcases {
+		fitted, dropped, err := p.FitTo(tc.maxVersion, Medium)
+		if err != nil {
+			t.Errorf("version %d: %v", tc.maxVersion, err)
+			continue
+		}
+		if !slices.Equal(dropped, tc.exp) {
+			t.Errorf("version %d: dropped %v expected, got %v", tc.maxVersion, tc.exp, dropped)
+		}
+		q, err := NewWithOptions([]byte(fitted.String()), Options{Level: Medium, MaxVersion: tc.maxVersion})
+		if err != nil {
+			t.Errorf("version %d: fitted payload must encode: %v", tc.maxVersion, err)
+		} else if q.VersionNumber > tc.maxVersion {
+			t.Errorf("version %d: got version %d", tc.maxVersion, q.VersionNumber)
+		}
+		if got := fitted.Contact(); got.Name != contact.Name || got.Phone != contact.Phone || got.Email != contact.Email {
+			t.Errorf("version %d: required fields must be kept, got %v", tc.maxVersion, got)
+		}
+	}
+	if p.Contact() != contact {
+		t.Error("original payload must not be changed")
+	}
+
+	// The note is truncated to the longest prefix that fits.
+	fitted, _, _ := p.FitTo(16, Medium)
+	note := fitted.Contact().Note
+	prefix, found := strings.CutSuffix(note, ellipsis)
+	if !found || !strings.HasPrefix(contact.Note, prefix) || prefix == "" {
+		t.Fatalf("truncated note expected, got %q", note)
+	}
+	longer := fitted.contact
+	longer.Note = contact.Note[:len(prefix)+1] + ellipsis
+	if fits, _ := VCard(longer).fits(Options{Level: Medium, MaxVersion: 16}); fits {
+		t.Errorf("note %q is not the longest prefix that fits", note)
+	}
+}
+
+func TestPayloadFitToMeCard(t *testing.T) {
+	contact := maximalContact()
+	p := MeCard(contact)
+	fitted, dropped, err := p.FitTo(10, Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []Dropped{{Field: "Phone2"}, {Field: "Note"}, {Field: "URL"}}
+	if !slices.Equal(dropped, exp) {
+		t.Errorf("dropped %v expected, got %v", exp, dropped)
+	}
+	if got := fitted.Contact(); got.PhotoURL != contact.PhotoURL || got.Title != contact.Title || got.Org != contact.Org {
+		t.Errorf("fields not encoded in a MeCard must be kept, got %v", got)
+	}
+	if _, err = NewWithOptions([]byte(fitted.String()), Options{Level: Medium, MaxVersion: 10}); err != nil {
+		t.Errorf("fitted payload must encode: %v", err)
+	}
+
+	// Without its encoded optional fields, nothing is dropped.
+	contact.Phone2, contact.Note, contact.URL, contact.Address = "", "", "", ""
+	if _, dropped, err = MeCard(contact).FitTo(1, Low); err == nil || len(dropped) != 0 {
+		t.Errorf("nothing dropped expected, got %v / %v", dropped, err)
+	}
+}
+
+func TestPayloadFitToEvent(t *testing.T) {
+	p := VEvent(Event{
+		Summary:     "Annual general meeting",
+		Start:       time.Date(2025, time.May, 1, 18, 0, 0, 0, time.UTC),
+		Location:    "Town hall, main floor",
+		URL:         "https://example.com/agm",
+		Description: strings.Repeat("Agenda item. ", 40),
+	})
+	fitted, dropped, err := p.FitTo(8, Low)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := []Dropped{{Field: "Description", Truncated: true}}; !slices.Equal(dropped, exp) {
+		t.Errorf("dropped %v expected, got %v", exp, dropped)
+	}
+	if !strings.HasSuffix(fitted.Event().Description, ellipsis) {
+		t.Errorf("truncated description expected, got %q", fitted.Event().Description)
+	}
+}
+
+func TestPayloadFitToErrors(t *testing.T) {
+	p := VCard(maximalContact())
+	if _, _, err := p.FitTo(41, Low); !errors.Is(err, ErrInvalidVersion) {
+		t.Errorf("invalid version expected, got %v", err)
+	}
+	fitted, dropped, err := p.FitTo(1, Highest)
+	var ce *CapacityError
+	if !errors.As(err, &ce) || ce.Version != 1 {
+		t.Errorf("capacity error expected, got %v", err)
+	}
+	if len(dropped) != len(contactFields) || fitted.Contact().Org != "" {
+		t.Errorf("all optional fields must be dropped, got %v", dropped)
+	}
+}
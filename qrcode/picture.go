@@ -0,0 +1,179 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package qrcode
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image/color"
+	"image/png"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"t73f.de/r/webs/htmls"
+)
+
+// PNGSet returns PNG images of the QR code for all given sizes. The symbol is
+// encoded only once, and all images share the palette and the buffers of
+// the PNG encoder.
+//
+// The map is indexed by the requested size. See [QRCode.PNG] for the meaning
+// of a size. In particular, a size smaller than the minimum size of the
+// symbol results in an image with the minimum size; use [QRCode.ImageSize]
+// to get the real size.
+func (q *QRCode) PNGSet(sizes []int) (map[int][]byte, error) {
+	if q.Strict {
+		if err := q.CheckColors(); err != nil {
+			return nil, err
+		}
+	}
+	q.encode()
+	p := color.Palette{q.BackgroundColor, q.ForegroundColor}
+	encoder := png.Encoder{
+		CompressionLevel: png.BestCompression,
+		BufferPool:       &pngBufferPool{},
+	}
+	result := make(map[int][]byte, len(sizes))
+	var b bytes.Buffer
+	for _, size := range sizes {
+		if _, found := result[size]; found {
+			continue
+		}
+		b.Reset()
+		if err := encoder.Encode(&b, q.image(size, p)); err != nil {
+			return nil, err
+		}
+		result[size] = bytes.Clone(b.Bytes())
+	}
+	return result, nil
+}
+
+// pngBufferPool reuses the buffers of one PNG encoder, which encodes images
+// sequentially.
+type pngBufferPool struct{ buf *png.EncoderBuffer }
+
+func (bp *pngBufferPool) Get() *png.EncoderBuffer {
+	buf := bp.buf
+	bp.buf = nil
+	return buf
+}
+func (bp *pngBufferPool) Put(buf *png.EncoderBuffer) { bp.buf = buf }
+
+// HTMLPictureNode returns a <picture> element with an <img> element that
+// references the PNG images of the QR code for all given sizes. The smallest
+// image is the default source, the others are listed in the srcset attribute
+// with a pixel density descriptor relative to the smallest one, rounded to
+// two decimals. The sizes are bumped to the real image sizes, see
+// [QRCode.ImageSize].
+//
+// The images are referenced by the URLs returned by [QRCode.PictureURL],
+// called with the requested sizes. If it is not set, the images are embedded
+// as data URIs. In this case, nil is returned if the images cannot be
+// encoded, e.g. because of a contrast error in strict mode.
+func (q *QRCode) HTMLPictureNode(sizes []int, alt string) *htmls.Node {
+	if len(sizes) == 0 {
+		return nil
+	}
+
+	type source struct{ requested, actual int }
+	sources := make([]source, 0, len(sizes))
+	for _, size := range sizes {
+		actual := q.ImageSize(size)
+		if !slices.ContainsFunc(sources, func(src source) bool { return src.actual == actual }) {
+			sources = append(sources, source{size, actual})
+		}
+	}
+	slices.SortFunc(sources, func(a, b source) int { return a.actual - b.actual })
+
+	urlFunc := q.PictureURL
+	if urlFunc == nil {
+		requested := make([]int, len(sources))
+		for i, src := range sources {
+			requested[i] = src.requested
+		}
+		images, err := q.PNGSet(requested)
+		if err != nil {
+			return nil
+		}
+		urlFunc = func(size int) string {
+			return "data:image/png;base64," + base64.StdEncoding.EncodeToString(images[size])
+		}
+	}
+
+	smallest := sources[0]
+	attrs := []htmls.Attribute{
+		{Key: "src", Value: urlFunc(smallest.requested)},
+		{Key: "alt", Value: alt},
+		{Key: "width", Value: strconv.Itoa(smallest.actual)},
+		{Key: "height", Value: strconv.Itoa(smallest.actual)},
+	}
+	if len(sources) > 1 {
+		var sb strings.Builder
+		for i, src := range sources {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(urlFunc(src.requested))
+			sb.WriteByte(' ')
+			density := float64(src.actual) / float64(smallest.actual)
+			sb.WriteString(strconv.FormatFloat(math.Round(density*100)/100, 'f', -1, 64))
+			sb.WriteByte('x')
+		}
+		attrs = append(attrs, htmls.Attribute{Key: "srcset", Value: sb.String()})
+	}
+	return htmls.Elem("picture", nil, htmls.Elem("img", attrs))
+}
+
+// pngDataURIPrefix is the prefix of a data URI with a base64 encoded PNG image.
+const pngDataURIPrefix = "data:image/png;base64,"
+
+func pngDataURI(data []byte) string {
+	return pngDataURIPrefix + base64.StdEncoding.EncodeToString(data)
+}
+
+// DataURI returns the QR code as a PNG image, embedded in a data URI, e.g. to
+// be used as the src attribute of an <img> element. See [QRCode.PNG] for the
+// meaning of size.
+func (q *QRCode) DataURI(size int) (string, error) {
+	data, err := q.PNG(size)
+	if err != nil {
+		return "", err
+	}
+	return pngDataURI(data), nil
+}
+
+// DataURI encodes the content as a QR code and returns it as a PNG image,
+// embedded in a data URI. See [QRCode.DataURI].
+func DataURI(content string, level RecoveryLevel, size int) (string, error) {
+	q, err := New(content, level)
+	if err != nil {
+		return "", err
+	}
+	return q.DataURI(size)
+}
+
+// HTMLImgNode returns an <img> element that embeds the PNG image of the QR
+// code as a data URI, together with its real width and height, see
+// [QRCode.ImageSize]. If the image cannot be encoded, e.g. because of a
+// contrast error in strict mode, nil is returned.
+func (q *QRCode) HTMLImgNode(size int, alt string) *htmls.Node {
+	uri, err := q.DataURI(size)
+	if err != nil {
+		return nil
+	}
+	actual := strconv.Itoa(q.ImageSize(size))
+	return htmls.Elem("img", htmls.Attrs("src", uri, "alt", alt, "width", actual, "height", actual))
+}
@@ -0,0 +1,185 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package qrcode
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"t73f.de/r/webs/htmls/render"
+)
+
+func TestPNGSet(t *testing.T) {
+	q, err := New("https://example.org/pay", Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bitmap := q.Bitmap()
+	realSize := len(bitmap)
+
+	sizes := []int{16, 128, 256, 512, -3, 128}
+	images, err := q.PNGSet(sizes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(images) != 5 {
+		t.Errorf("5 images expected, got %d", len(images))
+	}
+	for _, size := range sizes {
+		data := images[size]
+		if single, errPNG := q.PNG(size); errPNG != nil || !bytes.Equal(single, data) {
+			t.Errorf("size %d: PNGSet and PNG differ (%v)", size, errPNG)
+		}
+		img, errDecode := png.Decode(bytes.NewReader(data))
+		if errDecode != nil {
+			t.Fatal(errDecode)
+		}
+		actual := q.ImageSize(size)
+		if b := img.Bounds(); b.Dx() != actual || b.Dy() != actual {
+			t.Errorf("size %d: %dx%d image expected, got %v", size, actual, actual, b)
+		}
+		if size == 16 && actual != realSize {
+			t.Errorf("size 16 must be bumped to %d, got %d", realSize, actual)
+		}
+
+		// Scale back: sample the center of each module.
+		for y := range realSize {
+			py := (2*y + 1) * actual / (2 * realSize)
+			for x := range realSize {
+				px := (2*x + 1) * actual / (2 * realSize)
+				isFg := color.GrayModel.Convert(img.At(px, py)).(color.Gray).Y < 128
+				if isFg != bitmap[y][x] {
+					t.Fatalf("size %d: module (%d,%d) differs", size, x, y)
+				}
+			}
+		}
+	}
+}
+
+func TestHTMLPictureNode(t *testing.T) {
+	q, err := New("https://example.org/pay", Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.HTMLPictureNode(nil, "") != nil {
+		t.Error("no node expected for no sizes")
+	}
+
+	q.PictureURL = func(size int) string { return fmt.Sprintf("/qr.png?size=%d", size) }
+	var sb strings.Builder
+	if err = render.Render(&sb, q.HTMLPictureNode([]int{512, 1, 128, 256, 2}, "Scan to pay")); err != nil {
+		t.Fatal(err)
+	}
+	exp := `<picture><img src="/qr.png?size=1" alt="Scan to pay" width="33" height="33"` +
+		` srcset="/qr.png?size=1 1x, /qr.png?size=128 3.88x,` +
+		` /qr.png?size=256 7.76x, /qr.png?size=512 15.52x"></picture>`
+	if got := sb.String(); got != exp {
+		t.Errorf("expected\n%s\ngot\n%s", exp, got)
+	}
+
+	q.PictureURL = nil
+	node := q.HTMLPictureNode([]int{64}, "QR")
+	img := node.Children[0]
+	if len(img.Attributes) != 4 || img.Attributes[0].Key != "src" {
+		t.Fatalf("unexpected attributes %v", img.Attributes)
+	}
+	src, found := strings.CutPrefix(img.Attributes[0].Value, "data:image/png;base64,")
+	if !found {
+		t.Fatalf("data URI expected, got %q", img.Attributes[0].Value)
+	}
+	data, err := base64.StdEncoding.DecodeString(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if single, _ := q.PNG(64); !bytes.Equal(single, data) {
+		t.Error("data URI does not contain PNG image")
+	}
+}
+
+func TestDataURI(t *testing.T) {
+	q, err := New("https://example.org/pay", Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uri, err := q.DataURI(64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, found := strings.CutPrefix(uri, "data:image/png;base64,")
+	if !found {
+		t.Fatalf("data URI expected, got %q", uri)
+	}
+	data, err := base64.StdEncoding.DecodeString(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if single, _ := q.PNG(64); !bytes.Equal(single, data) {
+		t.Error("data URI does not contain PNG image")
+	}
+
+	if oneShot, errOne := DataURI("https://example.org/pay", Medium, 64); errOne != nil || oneShot != uri {
+		t.Errorf("one-shot DataURI differs (%v)", errOne)
+	}
+	if _, err = DataURI("", Medium, 64); !errors.Is(err, ErrEmptyContent) {
+		t.Errorf("ErrEmptyContent expected, got %v", err)
+	}
+
+	var sb strings.Builder
+	if err = render.Render(&sb, q.HTMLImgNode(16, "Scan to pay")); err != nil {
+		t.Fatal(err)
+	}
+	uri, _ = q.DataURI(16)
+	if got, exp := sb.String(), `<img src="`+uri+`" alt="Scan to pay" width="33" height="33">`; got != exp {
+		t.Errorf("expected\n%s\ngot\n%s", exp, got)
+	}
+
+	q.ForegroundColor = color.Gray{Y: 0xee}
+	q.Strict = true
+	if _, err = q.DataURI(64); !errors.Is(err, ErrLowContrast) {
+		t.Errorf("ErrLowContrast expected, got %v", err)
+	}
+	if q.HTMLImgNode(64, "") != nil {
+		t.Error("no node expected for low contrast")
+	}
+}
+
+var pictureSizes = []int{128, 256, 512, 64}
+
+func BenchmarkPNGSet(b *testing.B) {
+	q, err := New("https://example.org/pay", Medium)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for b.Loop() {
+		_, _ = q.PNGSet(pictureSizes)
+	}
+}
+
+func BenchmarkPNGSeparate(b *testing.B) {
+	q, err := New("https://example.org/pay", Medium)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for b.Loop() {
+		for _, size := range pictureSizes {
+			_, _ = q.PNG(size)
+		}
+	}
+}
@@ -25,11 +25,16 @@ package qrcode
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"image"
 	"image/color"
-	"image/png"
+	"io"
+	"os"
+	"slices"
 
 	"t73f.de/r/webs/qrcode/internal/bitset"
 	"t73f.de/r/webs/qrcode/internal/reedsolomon"
@@ -50,18 +55,136 @@ type QRCode struct {
 	// Disable the QR Code border.
 	DisableBorder bool
 
+	// Inverted draws set modules with the background color and all other
+	// modules, including the border, with the foreground color. This allows
+	// light QR codes on a dark background. The symbol itself is not changed,
+	// see [QRCode.Bitmap].
+	//
+	// Note: the QR code specification expects dark modules on a light
+	// background. Many scanners are able to read inverted symbols, but some
+	// are not.
+	Inverted bool
+
+	// Strict lets [QRCode.PNG] return an error, if the colors do not provide
+	// enough contrast, see [QRCode.CheckColors].
+	Strict bool
+
+	// PadFunc, if set, returns the i-th pad codeword that fills the unused
+	// data capacity of the symbol. If nil, the alternating codewords 0xEC
+	// and 0x11 of the specification are used. Decoders ignore the pad
+	// codewords, but they change the visual texture of sparse QR codes, see
+	// [PadPattern] and [SeededPad]. It must be set before the QR code is
+	// rendered for the first time.
+	PadFunc func(i int) byte
+
+	// PictureURL, if set, returns the URL of the PNG image with the given
+	// size, which is used by [QRCode.HTMLPictureNode]. Typically, the URL
+	// refers to a handler that calls [QRCode.Write]. If nil, the images are
+	// embedded as data URIs.
+	PictureURL func(size int) string
+
+	quietZone int // size of the border in modules
+	forceMask int // mask pattern, or -1 to choose the best one
+
 	encoder *dataEncoder
 	version qrCodeVersion
 
-	data   *bitset.Bitset
-	symbol *symbol
-	mask   int
+	data    *bitset.Bitset
+	symbol  *symbol
+	mask    int
+	penalty int
 }
 
-// New constructs a QRCode.
+// New constructs a QRCode, using the smallest version that is able to store
+// the content.
 //
-// An error occurs if the content is too long.
+// An error occurs if the content is empty ([ErrEmptyContent]), if the level is
+// unknown ([ErrInvalidLevel]), or if the content is too long
+// ([ErrContentTooLong], as a [*CapacityError]).
 func New(content string, level RecoveryLevel) (*QRCode, error) {
+	return NewWithOptions([]byte(content), Options{Level: level})
+}
+
+// NewShiftJIS constructs a QRCode for content that is encoded in Shift JIS.
+// Double-byte kanji characters are encoded in kanji mode, which needs less
+// space than byte mode. The errors are the same as for [New].
+//
+// Content encoded in UTF-8 must not be used, since some UTF-8 byte sequences
+// look like Shift JIS characters.
+func NewShiftJIS(content string, level RecoveryLevel) (*QRCode, error) {
+	return NewWithOptions([]byte(content), Options{Level: level, ShiftJIS: true})
+}
+
+// GroupSeparator is the ASCII group separator (GS), which terminates a
+// variable-length element string of GS1 data, if another one follows.
+const GroupSeparator = 0x1d
+
+// NewGS1 constructs a QRCode for GS1 data, e.g. for labels in the supply
+// chain. The FNC1 mode indicator (first position) is placed before the
+// data. The content is a sequence of GS1 element strings, i.e. application
+// identifiers and their data, without parentheses. Element strings of
+// variable length must be terminated by a [GroupSeparator], if another one
+// follows. The errors are the same as for [New].
+func NewGS1(content string, level RecoveryLevel) (*QRCode, error) {
+	return NewWithOptions([]byte(content), Options{Level: level, GS1: true})
+}
+
+// NewWithVersion constructs a QRCode with exactly the given version, e.g. to
+// produce a set of visually uniform QR codes.
+//
+// In addition to the errors of [New], an error occurs if the version is not
+// in the range 1-40 ([ErrInvalidVersion]). If the content does not fit into
+// the version, [ErrContentTooLong] is returned as a [*CapacityError].
+func NewWithVersion(content string, level RecoveryLevel, version int) (*QRCode, error) {
+	if version < MinVersion || version > MaxVersion {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidVersion, version)
+	}
+	return NewWithOptions([]byte(content), Options{Level: level, MinVersion: version, MaxVersion: version})
+}
+
+// newQRCode constructs a QRCode with valid options, where all defaults are
+// set.
+func newQRCode(content string, opts Options) (*QRCode, error) {
+	if content == "" {
+		return nil, ErrEmptyContent
+	}
+
+	encoder, encoded, chosenVersion, err := encodeContent(content, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	forceMask := -1
+	if opts.ForceMask != nil {
+		forceMask = *opts.ForceMask
+	}
+	q := &QRCode{
+		content: content,
+
+		recoveryLevel: opts.Level,
+		VersionNumber: chosenVersion.version,
+
+		ForegroundColor: opts.ForegroundColor,
+		BackgroundColor: opts.BackgroundColor,
+		DisableBorder:   opts.DisableBorder,
+		Inverted:        opts.Inverted,
+		Strict:          opts.Strict,
+		PadFunc:         opts.PadFunc,
+
+		quietZone: opts.QuietZone,
+		forceMask: forceMask,
+
+		encoder: encoder,
+		data:    encoded,
+		version: *chosenVersion,
+	}
+	return q, nil
+}
+
+// encodeContent encodes the content and chooses the smallest version of
+// the options that is able to store it. It does not build a symbol.
+func encodeContent(content string, opts Options) (*dataEncoder, *bitset.Bitset, *qrCodeVersion, error) {
+	encOpts := opts.encodeOptions()
 	var encoder *dataEncoder
 	var encoded *bitset.Bitset
 	var chosenVersion *qrCodeVersion
@@ -69,6 +192,10 @@ func New(content string, level RecoveryLevel) (*QRCode, error) {
 
 	for i := range allDataEncoder {
 		de := allDataEncoder[i] // we need a fresh copy
+		de.encodeOptions = encOpts
+		if opts.MaxVersion < de.minVersion || opts.MinVersion > de.maxVersion {
+			continue
+		}
 		encoder = &de
 
 		encoded, err = encoder.encode([]byte(content))
@@ -76,34 +203,22 @@ func New(content string, level RecoveryLevel) (*QRCode, error) {
 			continue
 		}
 
-		chosenVersion = chooseQRCodeVersion(level, encoder, encoded.Len())
+		chosenVersion = chooseQRCodeVersion(opts.Level, encoder, encoded.Len(), opts.MinVersion, opts.MaxVersion)
 		if chosenVersion != nil {
 			break
 		}
 	}
 
 	if err != nil {
-		return nil, err
+		if errors.Is(err, errLengthTooLong) {
+			return nil, nil, nil, newCapacityError(content, opts.Level, opts.MaxVersion, encOpts)
+		}
+		return nil, nil, nil, &EncodeError{Err: err}
 	}
 	if chosenVersion == nil {
-		return nil, errors.New("content too long to encode")
+		return nil, nil, nil, newCapacityError(content, opts.Level, opts.MaxVersion, encOpts)
 	}
-
-	q := &QRCode{
-		content: content,
-
-		recoveryLevel: level,
-		VersionNumber: chosenVersion.version,
-
-		ForegroundColor: color.Black,
-		BackgroundColor: color.White,
-		DisableBorder:   false,
-
-		encoder: encoder,
-		data:    encoded,
-		version: *chosenVersion,
-	}
-	return q, nil
+	return encoder, encoded, chosenVersion, nil
 }
 
 // Bitmap returns the QR Code as a 2D array of 1-bit pixels.
@@ -132,9 +247,24 @@ func (q *QRCode) Bitmap() [][]bool {
 // each module (QR Code "pixel") to be 5px in size.
 func (q *QRCode) Image(size int) image.Image {
 	q.encode()
+	return q.image(size, color.Palette{q.BackgroundColor, q.ForegroundColor})
+}
 
+// ImageSize returns the width and height in pixels of an image with the given
+// requested size, as returned by [QRCode.Image] and [QRCode.PNG]. Once the
+// symbol is encoded, its size is used, even if DisableBorder was changed
+// afterwards.
+func (q *QRCode) ImageSize(size int) int {
 	// Minimum pixels (both width and height) required.
-	realSize := q.symbol.fullSize
+	var realSize int
+	if q.symbol != nil {
+		realSize = q.symbol.fullSize
+	} else {
+		realSize = q.version.symbolSize()
+		if !q.DisableBorder {
+			realSize += 2 * q.quietZone
+		}
+	}
 
 	// Variable size support.
 	if size < 0 {
@@ -143,15 +273,17 @@ func (q *QRCode) Image(size int) image.Image {
 
 	// Actual pixels available to draw the symbol. Automatically increase the
 	// image size if it's not large enough.
-	if size < realSize {
-		size = realSize
-	}
+	return max(size, realSize)
+}
+
+// image draws the already encoded symbol with the given palette, which
+// contains the background and the foreground color, in this order.
+func (q *QRCode) image(size int, p color.Palette) *image.Paletted {
+	realSize := q.symbol.fullSize
+	size = q.ImageSize(size)
 
 	// Output image.
 	rect := image.Rectangle{Min: image.Point{0, 0}, Max: image.Point{size, size}}
-
-	// Saves a few bytes to have them in this order
-	p := color.Palette([]color.Color{q.BackgroundColor, q.ForegroundColor})
 	img := image.NewPaletted(rect, p)
 	fgClr := uint8(img.Palette.Index(q.ForegroundColor))
 
@@ -163,7 +295,7 @@ func (q *QRCode) Image(size int) image.Image {
 		y2 := int(float64(y) * modulesPerPixel)
 		for x := 0; x < size; x++ {
 			x2 := int(float64(x) * modulesPerPixel)
-			if bitmap[y2][x2] {
+			if bitmap[y2][x2] != q.Inverted {
 				pos := img.PixOffset(x, y)
 				img.Pix[pos] = fgClr
 			}
@@ -177,21 +309,58 @@ func (q *QRCode) Image(size int) image.Image {
 // size is both the image width and height in pixels. If size is too small then
 // a larger image is silently returned. Negative values for size cause a
 // variable sized image to be returned: See the documentation for Image().
+//
+// If Strict is set, an error is returned if the colors do not provide enough
+// contrast.
 func (q *QRCode) PNG(size int) ([]byte, error) {
-	img := q.Image(size)
-
 	var b bytes.Buffer
-	encoder := png.Encoder{CompressionLevel: png.BestCompression}
-	if err := encoder.Encode(&b, img); err != nil {
+	if err := q.Write(size, &b); err != nil {
 		return nil, err
 	}
 	return b.Bytes(), nil
 }
 
+// Write encodes the QR Code as a PNG image directly to the given writer,
+// e.g. an http.ResponseWriter. See [QRCode.PNG] for the meaning of size.
+//
+// If Strict is set, an error is returned if the colors do not provide enough
+// contrast. In this case, nothing is written.
+func (q *QRCode) Write(size int, w io.Writer) error {
+	return q.WriteContext(context.Background(), size, w)
+}
+
+// WriteFile writes the QR Code as a PNG image to the named file. The file is
+// created with permissions 0644, if it does not exist, otherwise it is
+// truncated. See [QRCode.PNG] for the meaning of size.
+func (q *QRCode) WriteFile(size int, filename string) error {
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	err = q.Write(size, f)
+	if errClose := f.Close(); err == nil {
+		err = errClose
+	}
+	return err
+}
+
 // encode completes the steps required to encode the QR Code. These include
 // adding the terminator bits and padding, splitting the data into blocks and
 // applying the error correction, and selecting the best data mask.
-func (q *QRCode) encode() {
+func (q *QRCode) encode() { _ = q.encodeContext(context.Background()) }
+
+// encodeContext encodes the QR code like encode, but checks the context
+// before each phase: the error correction, and the evaluation of each data
+// mask. If the context is done, its error is returned and the QR code is not
+// changed, except for the terminated and padded data. An encoded QR code is
+// not encoded again.
+func (q *QRCode) encodeContext(ctx context.Context) error {
+	if q.symbol != nil {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	numTerminatorBits := q.version.numTerminatorBitsRequired(q.data.Len())
 
 	q.addTerminatorBits(numTerminatorBits)
@@ -199,11 +368,23 @@ func (q *QRCode) encode() {
 
 	encoded := q.encodeBlocks()
 
+	quietZone := q.quietZone
+	if q.DisableBorder {
+		quietZone = 0
+	}
+
 	const numMasks int = 8
-	penalty := 0
+	var bestSymbol *symbol
+	bestMask, bestPenalty := 0, 0
 
 	for mask := range numMasks {
-		s := buildRegularSymbol(q.version, mask, encoded, !q.DisableBorder)
+		if q.forceMask >= 0 && mask != q.forceMask {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		s := buildRegularSymbol(q.version, mask, encoded, quietZone)
 
 		numEmptyModules := s.numEmptyModules()
 		if numEmptyModules != 0 {
@@ -212,12 +393,27 @@ func (q *QRCode) encode() {
 		}
 
 		p := s.penaltyScore()
-		if q.symbol == nil || p < penalty {
-			q.symbol = s
-			q.mask = mask
-			penalty = p
+		if bestSymbol == nil || p < bestPenalty {
+			bestSymbol, bestMask, bestPenalty = s, mask, p
 		}
 	}
+	q.symbol, q.mask, q.penalty = bestSymbol, bestMask, bestPenalty
+	return nil
+}
+
+// Mask returns the mask pattern (0-7) of the symbol: either the one with the
+// lowest penalty score, or the one forced by [Options.ForceMask].
+func (q *QRCode) Mask() int {
+	q.encode()
+	return q.mask
+}
+
+// PenaltyScore returns the penalty score of the symbol with the chosen mask
+// pattern, as defined by the specification. A lower score indicates fewer
+// patterns that may confuse a scanner.
+func (q *QRCode) PenaltyScore() int {
+	q.encode()
+	return q.penalty
 }
 
 // addTerminatorBits adds final terminator bits to the encoded data.
@@ -309,21 +505,49 @@ func (q *QRCode) addPadding() {
 	// Pad to the nearest codeword boundary.
 	q.data.AppendNumBools(q.version.numBitsToPadToCodeword(q.data.Len()), false)
 
-	// Pad codewords 0b11101100 and 0b00010001.
-	padding := [2]*bitset.Bitset{
-		bitset.New(b1, b1, b1, b0, b1, b1, b0, b0),
-		bitset.New(b0, b0, b0, b1, b0, b0, b0, b1),
+	padFunc := q.PadFunc
+	if padFunc == nil {
+		padFunc = defaultPad
 	}
 
-	// Insert pad codewords alternately.
-	i := 0
-	for numDataBits-q.data.Len() >= 8 {
-		q.data.Append(padding[i])
-
-		i = 1 - i // Alternate between 0 and 1.
+	// Insert pad codewords.
+	for i := 0; numDataBits-q.data.Len() >= 8; i++ {
+		q.data.AppendByte(padFunc(i), 8)
 	}
 
 	if q.data.Len() != numDataBits {
 		panic(fmt.Sprintf("BUG: got len %d, expected %d", q.data.Len(), numDataBits))
 	}
 }
+
+// defaultPad returns the pad codewords 0b11101100 and 0b00010001 alternately,
+// as required by the specification.
+func defaultPad(i int) byte {
+	if i%2 == 0 {
+		return 0xec
+	}
+	return 0x11
+}
+
+// PadPattern returns a function for [QRCode.PadFunc] that cycles through the
+// given pattern. An empty pattern results in the default pad codewords.
+func PadPattern(pattern []byte) func(int) byte {
+	if len(pattern) == 0 {
+		return defaultPad
+	}
+	pattern = slices.Clone(pattern)
+	return func(i int) byte { return pattern[i%len(pattern)] }
+}
+
+// SeededPad returns a function for [QRCode.PadFunc] that produces random
+// looking, but deterministic pad codewords, derived from the given seed.
+// Using the content as a seed yields visually varied QR codes that are
+// nevertheless reproducible.
+func SeededPad(seed string) func(int) byte {
+	return func(i int) byte {
+		var buf [8]byte
+		binary.BigEndian.PutUint64(buf[:], uint64(i/sha256.Size))
+		sum := sha256.Sum256(append([]byte(seed), buf[:]...))
+		return sum[i%sha256.Size]
+	}
+}
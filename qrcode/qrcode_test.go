@@ -23,6 +23,13 @@
 package qrcode
 
 import (
+	"bytes"
+	"errors"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"slices"
 	"strings"
 	"testing"
@@ -49,12 +56,151 @@ func TestQRCodeMaxCapacity(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		_, err := New(strings.Repeat(test.string, test.numRepetitions+1), Low)
+		content := strings.Repeat(test.string, test.numRepetitions+1)
+		_, err := New(content, Low)
 		if err == nil {
 			t.Errorf("%d x '%s' chars encodable, expected not encodable",
 				test.numRepetitions+1, test.string)
+			continue
+		}
+		if !errors.Is(err, ErrContentTooLong) {
+			t.Errorf("%d x '%s': expected ErrContentTooLong, but got %v", test.numRepetitions+1, test.string, err)
+		}
+		var ce *CapacityError
+		if !errors.As(err, &ce) {
+			t.Errorf("%d x '%s': expected CapacityError, but got %T", test.numRepetitions+1, test.string, err)
+			continue
+		}
+		if ce.Length != len(content) || ce.Level != Low {
+			t.Errorf("%d x '%s': unexpected error data %+v", test.numRepetitions+1, test.string, ce)
+		}
+		if exp := len(test.string) * test.numRepetitions; len(test.string) == 1 && ce.MaxCapacity != exp {
+			t.Errorf("%d x '%s': expected capacity %d, but got %d", test.numRepetitions+1, test.string, exp, ce.MaxCapacity)
 		}
 	}
+
+	// Longer than the character count indicator of byte mode.
+	if _, err := New(strings.Repeat("#", 70000), Highest); !errors.Is(err, ErrContentTooLong) {
+		t.Errorf("expected ErrContentTooLong, but got %v", err)
+	}
+}
+
+func TestQRCodeErrors(t *testing.T) {
+	if _, err := New("", Low); !errors.Is(err, ErrEmptyContent) {
+		t.Errorf("expected ErrEmptyContent, but got %v", err)
+	}
+	for _, level := range []RecoveryLevel{Low - 1, Highest + 1} {
+		if _, err := New("1", level); !errors.Is(err, ErrInvalidLevel) {
+			t.Errorf("level %d: expected ErrInvalidLevel, but got %v", level, err)
+		}
+	}
+	cause := errModeNotSupported
+	var err error = &EncodeError{Err: cause}
+	if !errors.Is(err, cause) || errors.Unwrap(err) != cause {
+		t.Errorf("EncodeError must unwrap to its cause: %v", err)
+	}
+}
+
+func TestNewWithVersion(t *testing.T) {
+	for _, version := range []int{1, 2, 9, 10, 26, 27, 40} {
+		q, err := NewWithVersion("HELLO WORLD", Medium, version)
+		if err != nil {
+			t.Errorf("version %d: %v", version, err)
+			continue
+		}
+		if q.VersionNumber != version {
+			t.Errorf("version %d expected, but got %d", version, q.VersionNumber)
+		}
+		if version < q.encoder.minVersion || version > q.encoder.maxVersion {
+			t.Errorf("version %d: wrong encoder %d-%d", version, q.encoder.minVersion, q.encoder.maxVersion)
+		}
+		if exp, got := 17+4*version+2*q.quietZone, len(q.Bitmap()); got != exp {
+			t.Errorf("version %d: bitmap size %d expected, but got %d", version, exp, got)
+		}
+	}
+
+	for _, version := range []int{0, -1, 41} {
+		if _, err := NewWithVersion("1", Low, version); !errors.Is(err, ErrInvalidVersion) {
+			t.Errorf("version %d: expected ErrInvalidVersion, but got %v", version, err)
+		}
+	}
+
+	if _, err := NewWithVersion(strings.Repeat("1", 41), Low, 1); err != nil {
+		t.Errorf("41 digits must fit into version 1: %v", err)
+	}
+	_, err := NewWithVersion(strings.Repeat("1", 42), Low, 1)
+	var ce *CapacityError
+	if !errors.As(err, &ce) {
+		t.Fatalf("expected CapacityError, but got %v", err)
+	}
+	if ce.Version != 1 || ce.MaxCapacity != 41 || ce.Length != 42 {
+		t.Errorf("unexpected error data %+v", ce)
+	}
+}
+
+func TestNewShiftJIS(t *testing.T) {
+	// 35 times "点" in Shift JIS.
+	content := strings.Repeat("\x93\x5f", 35)
+	qk, err := NewShiftJIS(content, Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(qk.encoder.optimised) != 1 || qk.encoder.optimised[0].dataMode != dataModeKanji {
+		t.Errorf("single kanji segment expected, got %s", segmentsString(qk.encoder.optimised))
+	}
+	qb, err := New(content, Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if qk.VersionNumber >= qb.VersionNumber {
+		t.Errorf("kanji mode should need a smaller version than %d, got %d", qb.VersionNumber, qk.VersionNumber)
+	}
+
+	// 1817 kanji characters is the maximum at version 40, level Low.
+	if _, err = NewShiftJIS(strings.Repeat("\x93\x5f", 1817), Low); err != nil {
+		t.Errorf("1817 kanji must fit: %v", err)
+	}
+	_, err = NewShiftJIS(strings.Repeat("\x93\x5f", 1818), Low)
+	var ce *CapacityError
+	if !errors.As(err, &ce) {
+		t.Fatalf("expected CapacityError, but got %v", err)
+	}
+	if ce.MaxCapacity != 1817 {
+		t.Errorf("capacity 1817 expected, got %d", ce.MaxCapacity)
+	}
+}
+
+func TestNewGS1(t *testing.T) {
+	// 41 digits is the maximum at version 1, level Low. The FNC1 mode
+	// indicator needs four more bits.
+	content := strings.Repeat("0", 41)
+	q, err := New(content, Low)
+	if err != nil {
+		t.Fatal(err)
+	}
+	qg, err := NewGS1(content, Low)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.VersionNumber != 1 || qg.VersionNumber != 2 {
+		t.Errorf("versions 1 and 2 expected, got %d and %d", q.VersionNumber, qg.VersionNumber)
+	}
+	if got := qg.data.Substr(0, 4); !got.Equals(fnc1FirstModeIndicator) {
+		t.Errorf("FNC1 mode indicator expected, got %v", got)
+	}
+
+	// 7089 digits is the maximum at version 40, level Low, but not with FNC1.
+	_, err = NewGS1(strings.Repeat("0", 7089), Low)
+	var ce *CapacityError
+	if !errors.As(err, &ce) {
+		t.Fatalf("expected CapacityError, but got %v", err)
+	}
+	if ce.MaxCapacity != 7087 {
+		t.Errorf("capacity 7087 expected, got %d", ce.MaxCapacity)
+	}
+	if _, err = NewGS1(strings.Repeat("0", 7087), Low); err != nil {
+		t.Errorf("7087 digits must fit: %v", err)
+	}
 }
 
 func TestQRCodeVersionCapacity(t *testing.T) {
@@ -115,15 +261,98 @@ func TestQRCodeISOAnnexIExample(t *testing.T) {
 		t.Fatalf("Error producing ISO Annex I Example: %s, expected success",
 			err.Error())
 	}
-	q.encode()
-
 	const expectedMask int = 2
-	if q.mask != expectedMask {
-		t.Errorf("ISO Annex I example mask got %d, expected %d\n", q.mask,
+	if got := q.Mask(); got != expectedMask {
+		t.Errorf("ISO Annex I example mask got %d, expected %d\n", got,
 			expectedMask)
 	}
 }
 
+func TestPenaltyScore(t *testing.T) {
+	q, err := New("01234567", Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	best := q.PenaltyScore()
+	if best <= 0 {
+		t.Errorf("positive penalty score expected, got %d", best)
+	}
+	for mask := range 8 {
+		qf, errF := NewWithOptions([]byte("01234567"), Options{Level: Medium, ForceMask: &mask})
+		if errF != nil {
+			t.Fatal(errF)
+		}
+		if got := qf.Mask(); got != mask {
+			t.Errorf("forced mask %d expected, got %d", mask, got)
+		}
+		if got := qf.PenaltyScore(); got < best || (mask == q.Mask() && got != best) {
+			t.Errorf("mask %d: penalty score %d is inconsistent with best score %d", mask, got, best)
+		}
+		if mask == q.Mask() && !slices.EqualFunc(q.Bitmap(), qf.Bitmap(), slices.Equal) {
+			t.Errorf("forcing the chosen mask %d results in a different symbol", mask)
+		}
+	}
+}
+
+func TestQRCodePadFunc(t *testing.T) {
+	const content = "https://example.org"
+	ref, err := New(content, Highest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payloadLen := ref.data.Len()
+	payloadLen += ref.version.numTerminatorBitsRequired(payloadLen)
+	payloadLen += ref.version.numBitsToPadToCodeword(payloadLen)
+	ref.encode()
+	numData := ref.version.numDataBits()
+	if payloadLen >= numData {
+		t.Fatalf("content too long to test padding: %d/%d bits", payloadLen, numData)
+	}
+	for i, pos := 0, payloadLen; pos < numData; i, pos = i+1, pos+8 {
+		if got, exp := ref.data.ByteAt(pos), defaultPad(i); got != exp {
+			t.Fatalf("default pad codeword %d: expected %x, but got %x", i, exp, got)
+		}
+	}
+
+	padFuncs := map[string]func(int) byte{
+		"zero":    PadPattern([]byte{0}),
+		"pattern": PadPattern([]byte{0x55, 0xaa, 0x0f}),
+		"empty":   PadPattern(nil),
+		"seeded":  SeededPad(content),
+	}
+	for name, padFunc := range padFuncs {
+		t.Run(name, func(t *testing.T) {
+			q, err := New(content, Highest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			q.PadFunc = padFunc
+			q.encode() // panics if the symbol is not completely filled
+
+			if q.data.Len() != numData {
+				t.Fatalf("expected %d data bits, but got %d", numData, q.data.Len())
+			}
+			// The content, which is interpreted by decoders, must not change.
+			if !q.data.Substr(0, payloadLen).Equals(ref.data.Substr(0, payloadLen)) {
+				t.Error("payload differs")
+			}
+			for i, pos := 0, payloadLen; pos < numData; i, pos = i+1, pos+8 {
+				if got, exp := q.data.ByteAt(pos), padFunc(i); got != exp {
+					t.Fatalf("pad codeword %d: expected %x, but got %x", i, exp, got)
+				}
+			}
+			if q.mask < 0 || q.mask >= 8 {
+				t.Errorf("invalid mask %d", q.mask)
+			}
+		})
+	}
+
+	seeded := SeededPad(content)
+	if seeded(0) != SeededPad(content)(0) || seeded(40) != SeededPad(content)(40) {
+		t.Error("seeded pad codewords must be deterministic")
+	}
+}
+
 func BenchmarkQRCodeURLSize(b *testing.B) {
 	for b.Loop() {
 		_, _ = New("http://www.example.org", Medium)
@@ -138,6 +367,47 @@ func BenchmarkQRCodeMaximumSize(b *testing.B) {
 	}
 }
 
+func TestWrite(t *testing.T) {
+	q, err := New("https://example.org", Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		if errWrite := q.Write(256, w); errWrite != nil {
+			t.Error(errWrite)
+		}
+	})
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/qr.png", nil))
+	body := rr.Body.Bytes()
+	if !bytes.HasPrefix(body, []byte("\x89PNG\r\n\x1a\n")) {
+		t.Fatalf("PNG magic bytes expected, got % x", body[:min(8, len(body))])
+	}
+	cfg, err := png.DecodeConfig(bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Width != 256 || cfg.Height != 256 {
+		t.Errorf("256x256 image expected, got %dx%d", cfg.Width, cfg.Height)
+	}
+	data, err := q.PNG(256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, body) {
+		t.Error("PNG and Write differ")
+	}
+
+	filename := filepath.Join(t.TempDir(), "qr.png")
+	if err = q.WriteFile(256, filename); err != nil {
+		t.Fatal(err)
+	}
+	if fileData, errRead := os.ReadFile(filename); errRead != nil || !bytes.Equal(fileData, body) {
+		t.Errorf("WriteFile and Write differ: %v", errRead)
+	}
+}
+
 func TestPNGBitmap(t *testing.T) {
 	qr, err := New("http://example.org", Low)
 	if err != nil {
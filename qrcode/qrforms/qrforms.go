@@ -0,0 +1,61 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+// Package qrforms allows to check form values that will be encoded as QR
+// codes.
+package qrforms
+
+import (
+	"errors"
+
+	"t73f.de/r/webs/forms"
+	"t73f.de/r/webs/qrcode"
+)
+
+// ContentValidator returns a form validator that checks the field value
+// with [qrcode.Inspect]. Content that does not fit into a QR code of the
+// given recovery level is invalid. All other findings of severity warning,
+// e.g. control characters or white space padding, are reported as warnings.
+// Each finding results in its own message, formatted with the message key
+// [forms.MsgQRCodeContent], and coded with [forms.CodeQRCodeContent]. An
+// empty value is not checked.
+func ContentValidator(level qrcode.RecoveryLevel) forms.Validator {
+	return forms.ValidatorFunc(func(f *forms.Form, field forms.Field) error {
+		value := field.Value()
+		if value == "" {
+			return nil
+		}
+		report := qrcode.Inspect(value)
+		var errs []error
+		tooLong := false
+		for _, finding := range report.Findings {
+			msg := f.Sprintf(field, forms.MsgQRCodeContent, finding.Key, finding.Message)
+			switch finding.Severity {
+			case qrcode.SeverityError:
+				tooLong = tooLong || finding.Key == qrcode.FindingTooLong
+				errs = append(errs, contentError(msg, finding.Key))
+			case qrcode.SeverityWarning:
+				errs = append(errs, forms.WarningError(msg))
+			}
+		}
+		if level >= qrcode.Low && level <= qrcode.Highest && !tooLong && !report.Levels[level].Fits() {
+			errs = append(errs, contentError(f.Sprintf(field, forms.MsgQRCodeContent,
+				qrcode.FindingTooLong, "content too long for the recovery level"), qrcode.FindingTooLong))
+		}
+		return errors.Join(errs...)
+	})
+}
+
+func contentError(msg, key string) error {
+	return forms.CodedValidationError(forms.CodeQRCodeContent, msg, map[string]any{"finding": key})
+}
@@ -0,0 +1,86 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package qrforms_test
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"t73f.de/r/webs/forms"
+	"t73f.de/r/webs/qrcode"
+	"t73f.de/r/webs/qrcode/qrforms"
+)
+
+func TestContentValidator(t *testing.T) {
+	t.Parallel()
+	field := forms.TextAreaField("content", "Content", qrforms.ContentValidator(qrcode.Highest))
+	form := forms.Define(field)
+
+	form.SetData(forms.Data{"content": "https://example.com/"})
+	if !form.IsValid() || len(form.Warnings()) != 0 {
+		t.Errorf("valid URL expected, got %v / %v", form.Messages(), form.Warnings())
+	}
+
+	_ = field.SetValue("https://ex\u0430mple.com/\u200B")
+	if !form.IsValid() {
+		t.Errorf("warnings must not invalidate the form: %v", form.Messages())
+	}
+	exp := []string{"invisible character U+200B", "host name label \"ex\u0430mple\" mixes scripts"}
+	if got := form.Warnings()["content"]; !slices.Equal(got, exp) {
+		t.Errorf("warnings %q expected, got %q", exp, got)
+	}
+
+	// Fits at level Low, but not at level Highest.
+	_ = field.SetValue(strings.Repeat("a", 1500))
+	if form.IsValid() {
+		t.Error("content too long for level Highest must be invalid")
+	}
+	if got := form.Messages()["content"]; len(got) != 1 {
+		t.Errorf("one message expected, got %q", got)
+	}
+
+	form.SetMessagePrinter(forms.MessagePrinterFunc(func(key string, args ...any) string {
+		if key == forms.MsgQRCodeContent {
+			return "QR:" + args[0].(string)
+		}
+		return forms.DefaultMessages.Sprintf(key, args...)
+	}))
+	_ = field.SetValue(strings.Repeat("a", 3000) + " ")
+	if form.IsValid() {
+		t.Error("content too long for any level must be invalid")
+	}
+	if got, exp := form.Messages()["content"], []string{"QR:" + qrcode.FindingTooLong}; !slices.Equal(got, exp) {
+		t.Errorf("messages %q expected, got %q", exp, got)
+	}
+	if got, exp := form.Warnings()["content"], []string{"QR:" + qrcode.FindingTrailingSpace}; !slices.Equal(got, exp) {
+		t.Errorf("warnings %q expected, got %q", exp, got)
+	}
+}
+
+func TestContentValidatorCode(t *testing.T) {
+	t.Parallel()
+	field := forms.TextField("f", "F", qrforms.ContentValidator(qrcode.Highest))
+	form := forms.Define(field)
+	_ = field.SetValue(strings.Repeat("a", 1500))
+	if form.IsValid() {
+		t.Fatal("form must be invalid")
+	}
+	errs := form.FieldErrors()["f"]
+	exp := map[string]any{"finding": qrcode.FindingTooLong}
+	if len(errs) != 1 || errs[0].Code != forms.CodeQRCodeContent || !reflect.DeepEqual(errs[0].Params, exp) {
+		t.Errorf("code %q with %v expected, got %+v", forms.CodeQRCodeContent, exp, errs)
+	}
+}
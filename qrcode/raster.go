@@ -0,0 +1,69 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package qrcode
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+)
+
+// JPEG returns the QR Code as a JPEG image with the given quality, which must
+// be in the range 1-100. See [QRCode.PNG] for the meaning of size.
+//
+// JPEG is a lossy format. Prefer a high quality, otherwise the edges of the
+// modules become blurry.
+//
+// If Strict is set, an error is returned if the colors do not provide enough
+// contrast.
+func (q *QRCode) JPEG(size int, quality int) ([]byte, error) {
+	if quality < 1 || quality > 100 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidQuality, quality)
+	}
+	if q.Strict {
+		if err := q.CheckColors(); err != nil {
+			return nil, err
+		}
+	}
+	img := q.Image(size)
+	rgba := image.NewRGBA(img.Bounds())
+	draw.Draw(rgba, rgba.Bounds(), img, image.Point{}, draw.Src)
+
+	var b bytes.Buffer
+	if err := jpeg.Encode(&b, rgba, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// GIF returns the QR Code as a GIF image. See [QRCode.PNG] for the meaning of
+// size.
+//
+// If Strict is set, an error is returned if the colors do not provide enough
+// contrast.
+func (q *QRCode) GIF(size int) ([]byte, error) {
+	if q.Strict {
+		if err := q.CheckColors(); err != nil {
+			return nil, err
+		}
+	}
+	var b bytes.Buffer
+	if err := gif.Encode(&b, q.Image(size), nil); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,136 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"testing"
+)
+
+// checkFinderPatterns verifies that the three finder patterns are drawn in
+// the corners of the image, which has the given number of pixels per module.
+func checkFinderPatterns(t *testing.T, q *QRCode, img image.Image, scale int) {
+	t.Helper()
+	quiet := q.quietZone
+	last := q.version.symbolSize() - 7
+
+	// Offsets of modules in a finder pattern and whether they are dark.
+	modules := []struct {
+		dx, dy int
+		dark   bool
+	}{
+		{-1, -1, false}, // quiet zone or separator
+		{0, 0, true},    // outer ring
+		{6, 6, true},
+		{1, 1, false}, // inner ring
+		{5, 3, false},
+		{3, 3, true}, // center
+	}
+	for _, corner := range []image.Point{{0, 0}, {last, 0}, {0, last}} {
+		for _, m := range modules {
+			dx, dy := m.dx, m.dy
+			if corner.X > 0 && dx < 0 {
+				dx = 7
+			}
+			if corner.Y > 0 && dy < 0 {
+				dy = 7
+			}
+			x := (quiet+corner.X+dx)*scale + scale/2
+			y := (quiet+corner.Y+dy)*scale + scale/2
+			gray := color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y
+			if isDark := gray < 128; isDark != m.dark {
+				t.Errorf("corner %v, module (%d,%d): dark=%v expected, got gray %d", corner, dx, dy, m.dark, gray)
+			}
+		}
+	}
+}
+
+func TestJPEG(t *testing.T) {
+	q, err := New("https://example.org", Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := q.JPEG(-8, 90)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := img.Bounds().Dx(), q.ImageSize(-8); got != exp {
+		t.Errorf("width %d expected, got %d", exp, got)
+	}
+	checkFinderPatterns(t, q, img, 8)
+
+	for _, quality := range []int{0, -1, 101} {
+		if _, err = q.JPEG(-8, quality); !errors.Is(err, ErrInvalidQuality) {
+			t.Errorf("quality %d: ErrInvalidQuality expected, got %v", quality, err)
+		}
+	}
+}
+
+func TestImageSizeEncoded(t *testing.T) {
+	q, err := New("https://example.org", Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bordered := q.ImageSize(-1)
+	if got := len(q.Bitmap()); got != bordered {
+		t.Fatalf("bitmap size %d expected, got %d", bordered, got)
+	}
+
+	// The encoded symbol keeps its border.
+	q.DisableBorder = true
+	if got := q.ImageSize(-1); got != bordered {
+		t.Errorf("image size %d of the encoded symbol expected, got %d", bordered, got)
+	}
+	if got := q.Image(-2).Bounds().Dx(); got != 2*bordered {
+		t.Errorf("image width %d expected, got %d", 2*bordered, got)
+	}
+}
+
+func TestGIF(t *testing.T) {
+	q, err := New("https://example.org", Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := q.GIF(-4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := gif.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := img.Bounds().Dx(), q.ImageSize(-4); got != exp {
+		t.Errorf("width %d expected, got %d", exp, got)
+	}
+	checkFinderPatterns(t, q, img, 4)
+	pos := 4 * q.quietZone
+	if got, exp := color.RGBAModel.Convert(img.At(pos, pos)), color.RGBAModel.Convert(q.ForegroundColor); got != exp {
+		t.Errorf("foreground color %v expected, got %v", exp, got)
+	}
+
+	q.ForegroundColor = color.Gray{Y: 0xee}
+	q.Strict = true
+	if _, err = q.GIF(-4); !errors.Is(err, ErrLowContrast) {
+		t.Errorf("ErrLowContrast expected, got %v", err)
+	}
+}
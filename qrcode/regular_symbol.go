@@ -22,7 +22,11 @@
 
 package qrcode
 
-import "t73f.de/r/webs/qrcode/internal/bitset"
+import (
+	"iter"
+
+	"t73f.de/r/webs/qrcode/internal/bitset"
+)
 
 type regularSymbol struct {
 	version    qrCodeVersion
@@ -120,12 +124,7 @@ var (
 )
 
 func buildRegularSymbol(
-	version qrCodeVersion, mask int, data *bitset.Bitset, includeQuietZone bool) *symbol {
-
-	quietZoneSize := 0
-	if includeQuietZone {
-		quietZoneSize = version.quietZoneSize()
-	}
+	version qrCodeVersion, mask int, data *bitset.Bitset, quietZoneSize int) *symbol {
 
 	symbolSize := version.symbolSize()
 	m := &regularSymbol{
@@ -152,19 +151,19 @@ func (m *regularSymbol) addFinderPatterns() {
 	fpVBorder := finderPatternVerticalBorder
 
 	// Top left Finder Pattern.
-	m.symbol.set2dPattern(0, 0, fp)
-	m.symbol.set2dPattern(0, fpSize, fpHBorder)
-	m.symbol.set2dPattern(fpSize, 0, fpVBorder)
+	m.set2dPattern(0, 0, fp, ModuleFinder)
+	m.set2dPattern(0, fpSize, fpHBorder, ModuleSeparator)
+	m.set2dPattern(fpSize, 0, fpVBorder, ModuleSeparator)
 
 	// Top right Finder Pattern.
-	m.symbol.set2dPattern(m.symbolSize-fpSize, 0, fp)
-	m.symbol.set2dPattern(m.symbolSize-fpSize-1, fpSize, fpHBorder)
-	m.symbol.set2dPattern(m.symbolSize-fpSize-1, 0, fpVBorder)
+	m.set2dPattern(m.symbolSize-fpSize, 0, fp, ModuleFinder)
+	m.set2dPattern(m.symbolSize-fpSize-1, fpSize, fpHBorder, ModuleSeparator)
+	m.set2dPattern(m.symbolSize-fpSize-1, 0, fpVBorder, ModuleSeparator)
 
 	// Bottom left Finder Pattern.
-	m.symbol.set2dPattern(0, m.symbolSize-fpSize, fp)
-	m.symbol.set2dPattern(0, m.symbolSize-fpSize-1, fpHBorder)
-	m.symbol.set2dPattern(fpSize, m.symbolSize-fpSize-1, fpVBorder)
+	m.set2dPattern(0, m.symbolSize-fpSize, fp, ModuleFinder)
+	m.set2dPattern(0, m.symbolSize-fpSize-1, fpHBorder, ModuleSeparator)
+	m.set2dPattern(fpSize, m.symbolSize-fpSize-1, fpVBorder, ModuleSeparator)
 }
 
 func (m *regularSymbol) addAlignmentPatterns() {
@@ -174,7 +173,7 @@ func (m *regularSymbol) addAlignmentPatterns() {
 				continue
 			}
 
-			m.symbol.set2dPattern(x-2, y-2, alignmentPattern)
+			m.set2dPattern(x-2, y-2, alignmentPattern, ModuleAlignment)
 		}
 	}
 }
@@ -183,8 +182,8 @@ func (m *regularSymbol) addTimingPatterns() {
 	value := true
 
 	for i := finderPatternSize + 1; i < m.symbolSize-finderPatternSize; i++ {
-		m.symbol.set(i, finderPatternSize-1, value)
-		m.symbol.set(finderPatternSize-1, i, value)
+		m.set(i, finderPatternSize-1, value, ModuleTiming)
+		m.set(finderPatternSize-1, i, value, ModuleTiming)
 
 		value = !value
 	}
@@ -198,31 +197,31 @@ func (m *regularSymbol) addFormatInfo() {
 
 	// Bits 0-7, under the top right finder pattern.
 	for i := 0; i <= 7; i++ {
-		m.symbol.set(m.symbolSize-i-1, fpSize+1, f.At(l-i))
+		m.set(m.symbolSize-i-1, fpSize+1, f.At(l-i), ModuleFormatInfo)
 	}
 
 	// Bits 0-5, right of the top left finder pattern.
 	for i := 0; i <= 5; i++ {
-		m.symbol.set(fpSize+1, i, f.At(l-i))
+		m.set(fpSize+1, i, f.At(l-i), ModuleFormatInfo)
 	}
 
 	// Bits 6-8 on the corner of the top left finder pattern.
-	m.symbol.set(fpSize+1, fpSize, f.At(l-6))
-	m.symbol.set(fpSize+1, fpSize+1, f.At(l-7))
-	m.symbol.set(fpSize, fpSize+1, f.At(l-8))
+	m.set(fpSize+1, fpSize, f.At(l-6), ModuleFormatInfo)
+	m.set(fpSize+1, fpSize+1, f.At(l-7), ModuleFormatInfo)
+	m.set(fpSize, fpSize+1, f.At(l-8), ModuleFormatInfo)
 
 	// Bits 9-14 on the underside of the top left finder pattern.
 	for i := 9; i <= 14; i++ {
-		m.symbol.set(14-i, fpSize+1, f.At(l-i))
+		m.set(14-i, fpSize+1, f.At(l-i), ModuleFormatInfo)
 	}
 
 	// Bits 8-14 on the right side of the bottom left finder pattern.
 	for i := 8; i <= 14; i++ {
-		m.symbol.set(fpSize+1, m.symbolSize-fpSize+i-8, f.At(l-i))
+		m.set(fpSize+1, m.symbolSize-fpSize+i-8, f.At(l-i), ModuleFormatInfo)
 	}
 
 	// Always dark symbol.
-	m.symbol.set(fpSize+1, m.symbolSize-fpSize-1, true)
+	m.set(fpSize+1, m.symbolSize-fpSize-1, true, ModuleFormatInfo)
 }
 
 func (m *regularSymbol) addVersionInfo() {
@@ -237,10 +236,30 @@ func (m *regularSymbol) addVersionInfo() {
 
 	for i := 0; i < v.Len(); i++ {
 		// Above the bottom left finder pattern.
-		m.symbol.set(i/3, m.symbolSize-fpSize-4+i%3, v.At(l-i))
+		m.set(i/3, m.symbolSize-fpSize-4+i%3, v.At(l-i), ModuleVersionInfo)
 
 		// Left of the top right finder pattern.
-		m.symbol.set(m.symbolSize-fpSize-4+i%3, i/3, v.At(l-i))
+		m.set(m.symbolSize-fpSize-4+i%3, i/3, v.At(l-i), ModuleVersionInfo)
+	}
+}
+
+// set sets the module at (x, y) to v. If the module is still empty, its type
+// is recorded. Therefore, a module shared by two patterns, e.g. an alignment
+// pattern and a timing pattern, keeps the type of the first one.
+func (m *regularSymbol) set(x, y int, v bool, mt ModuleType) {
+	if m.symbol.empty(x, y) {
+		m.symbol.setType(x, y, mt)
+	}
+	m.symbol.set(x, y, v)
+}
+
+// set2dPattern sets a 2D array of modules of the given type, starting at
+// (x, y).
+func (m *regularSymbol) set2dPattern(x, y int, v [][]bool, mt ModuleType) {
+	for j, row := range v {
+		for i, value := range row {
+			m.set(x+i, y+j, value, mt)
+		}
 	}
 }
 
@@ -252,42 +271,37 @@ const (
 )
 
 func (m *regularSymbol) addData() {
-	xOffset := 1
-	dir := up
-
-	x := m.symbolSize - 2
-	y := m.symbolSize - 1
-
-	for i := 0; i < m.data.Len(); i++ {
-		var mask bool
-		switch m.mask {
-		case 0:
-			mask = (y+x+xOffset)%2 == 0
-		case 1:
-			mask = y%2 == 0
-		case 2:
-			mask = (x+xOffset)%3 == 0
-		case 3:
-			mask = (y+x+xOffset)%3 == 0
-		case 4:
-			mask = (y/2+(x+xOffset)/3)%2 == 0
-		case 5:
-			mask = (y*(x+xOffset))%2+(y*(x+xOffset))%3 == 0
-		case 6:
-			mask = ((y*(x+xOffset))%2+((y*(x+xOffset))%3))%2 == 0
-		case 7:
-			mask = ((y+x+xOffset)%2+((y*(x+xOffset))%3))%2 == 0
-		}
-
+	i := 0
+	for x, y := range m.dataModules() {
 		// != is equivalent to XOR.
-		m.symbol.set(x+xOffset, y, mask != m.data.At(i))
-
-		if i == m.data.Len()-1 {
+		m.set(x, y, maskBit(m.mask, x, y) != m.data.At(i), ModuleData)
+		i++
+		if i == m.data.Len() {
 			break
 		}
+	}
+}
+
+// dataModules returns the positions (x, y) of all modules that are not
+// occupied by function patterns, in the order of data placement: in columns
+// of two modules, alternating upwards and downwards, starting at the bottom
+// right corner.
+func (m *regularSymbol) dataModules() iter.Seq2[int, int] {
+	return func(yield func(int, int) bool) {
+		xOffset := 1
+		dir := up
+
+		x := m.symbolSize - 2
+		y := m.symbolSize - 1
+
+		for x >= 0 {
+			if m.symbol.empty(x+xOffset, y) {
+				if !yield(x+xOffset, y) {
+					return
+				}
+			}
 
-		// Find next free bit in the symbol.
-		for {
+			// Find next position.
 			if xOffset == 1 {
 				xOffset = 0
 			} else {
@@ -314,10 +328,30 @@ func (m *regularSymbol) addData() {
 			if x == 5 {
 				x--
 			}
-
-			if m.symbol.empty(x+xOffset, y) {
-				break
-			}
 		}
 	}
 }
+
+// maskBit returns true, if the module at (x, y) is inverted by the mask
+// pattern.
+func maskBit(mask, x, y int) bool {
+	switch mask {
+	case 0:
+		return (y+x)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (y+x)%3 == 0
+	case 4:
+		return (y/2+x/3)%2 == 0
+	case 5:
+		return (y*x)%2+(y*x)%3 == 0
+	case 6:
+		return ((y*x)%2+((y*x)%3))%2 == 0
+	case 7:
+		return ((y+x)%2+((y*x)%3))%2 == 0
+	}
+	return false
+}
@@ -37,7 +37,7 @@ func TestBuildRegularSymbol(_ *testing.T) {
 			data.AppendNumBools(8, false)
 		}
 
-		s := buildRegularSymbol(*v, k, data, false)
+		s := buildRegularSymbol(*v, k, data, 0)
 		_ = s
 		//fmt.Print(m.string())
 	}
@@ -22,6 +22,8 @@
 
 package qrcode
 
+import "slices"
+
 // symbol is a 2D array of bits representing a QR Code symbol.
 //
 // A symbol consists of size*size modules, with each module normally drawn as a
@@ -47,6 +49,10 @@ type symbol struct {
 	// Used to identify unused modules.
 	isUsed [][]bool
 
+	// Type of the module at [y][x]. Modules of the quiet zone have the type
+	// ModuleQuietZone.
+	moduleType [][]ModuleType
+
 	// Combined width/height of the symbol and quiet zones.
 	//
 	// fullSize = symbolSize + 2*quietZoneSize.
@@ -66,6 +72,7 @@ func newSymbol(symbolSize int, quietZoneSize int) *symbol {
 	m := symbol{
 		module:        make([][]bool, fullSize),
 		isUsed:        make([][]bool, fullSize),
+		moduleType:    make([][]ModuleType, fullSize),
 		fullSize:      fullSize,
 		symbolSize:    symbolSize,
 		quietZoneSize: quietZoneSize,
@@ -74,6 +81,7 @@ func newSymbol(symbolSize int, quietZoneSize int) *symbol {
 	for i := range m.module {
 		m.module[i] = make([]bool, fullSize)
 		m.isUsed[i] = make([]bool, fullSize)
+		m.moduleType[i] = make([]ModuleType, fullSize)
 	}
 	return &m
 }
@@ -111,6 +119,11 @@ func (m *symbol) set(x, y int, v bool) {
 	m.isUsed[y+m.quietZoneSize][x+m.quietZoneSize] = true
 }
 
+// setType sets the type of the module at (x, y).
+func (m *symbol) setType(x, y int, mt ModuleType) {
+	m.moduleType[y+m.quietZoneSize][x+m.quietZoneSize] = mt
+}
+
 // set2dPattern sets a 2D array of modules, starting at (x, y).
 func (m *symbol) set2dPattern(x, y int, v [][]bool) {
 	for j, row := range v {
@@ -125,7 +138,7 @@ func (m *symbol) bitmap() [][]bool {
 	module := make([][]bool, len(m.module))
 
 	for i := range m.module {
-		module[i] = m.module[i][:]
+		module[i] = slices.Clone(m.module[i])
 	}
 	return module
 }
@@ -0,0 +1,75 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package qrcode
+
+import "strings"
+
+// ToString returns the QR code as text, e.g. to be printed on a terminal.
+//
+// Each module is rendered with two characters, so that it looks roughly
+// square. Set modules are drawn with full blocks, all other modules,
+// including the quiet zone, with spaces. This fits terminals with dark text
+// on a light background. If inverseColor is true, set modules are drawn with
+// spaces and all other modules with full blocks, which fits terminals with a
+// dark background.
+func (q *QRCode) ToString(inverseColor bool) string {
+	bitmap := q.Bitmap()
+	var sb strings.Builder
+	for _, row := range bitmap {
+		for _, set := range row {
+			if set != inverseColor {
+				sb.WriteString("██")
+			} else {
+				sb.WriteString("  ")
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// ToSmallString returns the QR code as text, like [QRCode.ToString], but
+// needs only half of the space.
+//
+// Each character renders one module horizontally and two modules vertically,
+// using the half block characters '▀' and '▄'. If the symbol has an odd
+// number of rows, the last row is padded with an unset module, i.e. with the
+// color of the quiet zone.
+func (q *QRCode) ToSmallString(inverseColor bool) string {
+	bitmap := q.Bitmap()
+	var sb strings.Builder
+	for y := 0; y < len(bitmap); y += 2 {
+		upper := bitmap[y]
+		var lower []bool
+		if y+1 < len(bitmap) {
+			lower = bitmap[y+1]
+		}
+		for x, set := range upper {
+			top := set != inverseColor
+			bottom := (x < len(lower) && lower[x]) != inverseColor
+			switch {
+			case top && bottom:
+				sb.WriteRune('█')
+			case top:
+				sb.WriteRune('▀')
+			case bottom:
+				sb.WriteRune('▄')
+			default:
+				sb.WriteByte(' ')
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
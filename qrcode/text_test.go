@@ -0,0 +1,104 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package qrcode
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToString(t *testing.T) {
+	for _, disableBorder := range []bool{false, true} {
+		q, err := New("https://example.org", Medium)
+		if err != nil {
+			t.Fatal(err)
+		}
+		q.DisableBorder = disableBorder
+		bitmap := q.Bitmap()
+
+		for _, inverse := range []bool{false, true} {
+			lines := strings.Split(strings.TrimSuffix(q.ToString(inverse), "\n"), "\n")
+			if len(lines) != len(bitmap) {
+				t.Fatalf("%d lines expected, got %d", len(bitmap), len(lines))
+			}
+			for y, line := range lines {
+				runes := []rune(line)
+				if len(runes) != 2*len(bitmap[y]) {
+					t.Fatalf("line %d: %d runes expected, got %d", y, 2*len(bitmap[y]), len(runes))
+				}
+				for x, set := range bitmap[y] {
+					exp := ' '
+					if set != inverse {
+						exp = '█'
+					}
+					if runes[2*x] != exp || runes[2*x+1] != exp {
+						t.Errorf("border=%v, inverse=%v, (%d,%d): %q expected, got %q",
+							!disableBorder, inverse, x, y, exp, string(runes[2*x:2*x+2]))
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestToSmallString(t *testing.T) {
+	for _, disableBorder := range []bool{false, true} {
+		q, err := New("https://example.org", Medium)
+		if err != nil {
+			t.Fatal(err)
+		}
+		q.DisableBorder = disableBorder
+		bitmap := q.Bitmap()
+		if len(bitmap)%2 == 0 {
+			t.Fatalf("symbol with odd height expected, got %d", len(bitmap))
+		}
+
+		for _, inverse := range []bool{false, true} {
+			lines := strings.Split(strings.TrimSuffix(q.ToSmallString(inverse), "\n"), "\n")
+			if exp := (len(bitmap) + 1) / 2; len(lines) != exp {
+				t.Fatalf("%d lines expected, got %d", exp, len(lines))
+			}
+			for i, line := range lines {
+				runes := []rune(line)
+				if len(runes) != len(bitmap[0]) {
+					t.Fatalf("line %d: %d runes expected, got %d", i, len(bitmap[0]), len(runes))
+				}
+				for x, r := range runes {
+					var top, bottom bool
+					switch r {
+					case '█':
+						top, bottom = true, true
+					case '▀':
+						top = true
+					case '▄':
+						bottom = true
+					case ' ':
+					default:
+						t.Fatalf("unexpected rune %q", r)
+					}
+					if exp := bitmap[2*i][x] != inverse; top != exp {
+						t.Errorf("border=%v, inverse=%v, (%d,%d): top=%v expected", !disableBorder, inverse, x, 2*i, exp)
+					}
+					expBottom := inverse // padding row has the color of the quiet zone
+					if 2*i+1 < len(bitmap) {
+						expBottom = bitmap[2*i+1][x] != inverse
+					}
+					if bottom != expBottom {
+						t.Errorf("border=%v, inverse=%v, (%d,%d): bottom=%v expected", !disableBorder, inverse, x, 2*i+1, expBottom)
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,86 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package qrcode
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"t73f.de/r/webs/qrcode/internal/vectors"
+)
+
+// TestVectors checks the encoder against the reference vectors in
+// testdata/vectors.json. To add a vector for a reported scanner
+// incompatibility, append it with its source and the expected results.
+// Generated vectors are updated with:
+//
+//	go run -tags tools ./qrcode/internal/tools/genvectors
+func TestVectors(t *testing.T) {
+	vecs, err := vectors.Load("testdata/vectors.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	levels := map[string]RecoveryLevel{"L": Low, "M": Medium, "Q": High, "H": Highest}
+	for _, vec := range vecs {
+		t.Run(vec.Name, func(t *testing.T) {
+			level, found := levels[vec.Level]
+			if !found {
+				t.Fatalf("unknown level %q", vec.Level)
+			}
+			q, err := New(vec.Content, level)
+			if err != nil {
+				t.Fatal(err)
+			}
+			q.DisableBorder = vec.DisableBorder
+			if vec.PadPattern != "" {
+				pattern, err := hex.DecodeString(vec.PadPattern)
+				if err != nil {
+					t.Fatal(err)
+				}
+				q.PadFunc = PadPattern(pattern)
+			}
+			if q.VersionNumber != vec.Version {
+				t.Errorf("expected version %d, but got %d", vec.Version, q.VersionNumber)
+			}
+			bitmap := q.Bitmap()
+
+			if vec.Mask != nil && q.mask != *vec.Mask {
+				t.Errorf("expected mask %d, but got %d", *vec.Mask, q.mask)
+			}
+			if vec.Codewords != "" {
+				final := q.encodeBlocks()
+				numBits := final.Len() - q.version.numRemainderBits
+				codewords := make([]byte, 0, numBits/8)
+				for pos := 0; pos < numBits; pos += 8 {
+					codewords = append(codewords, final.ByteAt(pos))
+				}
+				if got := hex.EncodeToString(codewords); got != strings.ToLower(vec.Codewords) {
+					t.Errorf("expected codewords\n%s, but got\n%s", vec.Codewords, got)
+				}
+			}
+			if len(vec.Matrix) > 0 {
+				got := vectors.MatrixRows(bitmap)
+				if len(got) != len(vec.Matrix) {
+					t.Fatalf("expected %d rows, but got %d", len(vec.Matrix), len(got))
+				}
+				for y, row := range vec.Matrix {
+					if got[y] != row {
+						t.Errorf("row %d differs:\nexpected %s\n but got %s", y, row, got[y])
+					}
+				}
+			}
+		})
+	}
+}
@@ -410,18 +410,19 @@ func (v qrCodeVersion) numDataBits() int {
 // used.
 //
 // The chosen QR Code version is the smallest version able to fit numDataBits
-// and the optional terminator bits required by the specified encoder.
+// and the optional terminator bits required by the specified encoder. The
+// choice is restricted to the versions from minVersion to maxVersion.
 //
 // On success the chosen QR Code version is returned.
-func chooseQRCodeVersion(level RecoveryLevel, encoder *dataEncoder, numDataBits int) *qrCodeVersion {
+func chooseQRCodeVersion(level RecoveryLevel, encoder *dataEncoder, numDataBits, minVersion, maxVersion int) *qrCodeVersion {
 	var chosenVersion *qrCodeVersion
 
 	for _, v := range versions {
 		if v.level != level {
 			continue
-		} else if v.version < encoder.minVersion {
+		} else if v.version < max(minVersion, encoder.minVersion) {
 			continue
-		} else if v.version > encoder.maxVersion {
+		} else if v.version > min(maxVersion, encoder.maxVersion) {
 			break
 		}
 
@@ -465,9 +466,3 @@ func (v qrCodeVersion) numBitsToPadToCodeword(numDataBits int) int {
 func (v qrCodeVersion) symbolSize() int {
 	return 21 + (v.version-1)*4
 }
-
-// quietZoneSize returns the number of pixels of border space on each side of
-// the QR Code. The quiet space assists with decoding.
-func (v qrCodeVersion) quietZoneSize() int {
-	return 4
-}
@@ -0,0 +1,82 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package site
+
+import (
+	"net/http"
+	"strings"
+
+	"t73f.de/r/webs/login"
+	"t73f.de/r/webs/middleware"
+)
+
+// CacheHeaderMiddleware returns a middleware functor that sets the headers
+// "Cache-Control" and "Surrogate-Key" of a response to a GET request,
+// according to [Node.CacheControl] and [Node.SurrogateKeys] of the node that
+// matches the request. Headers that are already set by the handler are not
+// changed.
+//
+// If there is a login session, "Cache-Control" is always set to "no-store".
+func (st *Site) CacheHeaderMiddleware() middleware.Functor {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				next.ServeHTTP(w, r)
+				return
+			}
+			crw := cacheRespWriter{w: w, noStore: login.Session(r.Context()) != nil}
+			if n := st.RequestNode(r); n != nil {
+				crw.cacheControl = n.CacheControl
+				crw.surrogateKeys = n.SurrogateKeys
+			}
+			next.ServeHTTP(&crw, r)
+			crw.setHeader()
+		})
+	}
+}
+
+type cacheRespWriter struct {
+	w             http.ResponseWriter
+	cacheControl  string
+	surrogateKeys []string
+	noStore       bool
+
+	done bool
+}
+
+func (crw *cacheRespWriter) Header() http.Header { return crw.w.Header() }
+func (crw *cacheRespWriter) WriteHeader(code int) {
+	crw.setHeader()
+	crw.w.WriteHeader(code)
+}
+func (crw *cacheRespWriter) Write(data []byte) (int, error) {
+	crw.setHeader()
+	return crw.w.Write(data)
+}
+
+func (crw *cacheRespWriter) setHeader() {
+	if crw.done {
+		return
+	}
+	crw.done = true
+	header := crw.w.Header()
+	if crw.noStore {
+		header.Set("Cache-Control", "no-store")
+	} else if cc := crw.cacheControl; cc != "" && header.Get("Cache-Control") == "" {
+		header.Set("Cache-Control", cc)
+	}
+	if keys := crw.surrogateKeys; len(keys) > 0 && header.Get("Surrogate-Key") == "" {
+		header.Set("Surrogate-Key", strings.Join(keys, " "))
+	}
+}
@@ -0,0 +1,129 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package site_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"t73f.de/r/webs/login"
+	"t73f.de/r/webs/site"
+)
+
+func makeCacheSite(t *testing.T) *site.Site {
+	t.Helper()
+	st := site.Site{
+		Basepath: "/app",
+		Root: site.Node{
+			ID:            "root",
+			CacheControl:  "max-age=3600",
+			SurrogateKeys: []string{"marketing"},
+			Children: []*site.Node{
+				{ID: "about", Nodepath: "about"},
+				{ID: "account", Nodepath: "account", CacheControl: "no-store", SurrogateKeys: []string{}},
+				{ID: "news", Nodepath: "news", SurrogateKeys: []string{"news", "marketing"}, Children: []*site.Node{
+					{ID: "item", Nodepath: "{id}"},
+				}},
+			},
+		},
+	}
+	if err := st.Bake(); err != nil {
+		t.Fatal(err)
+	}
+	return &st
+}
+
+func TestCacheInheritance(t *testing.T) {
+	st := makeCacheSite(t)
+	testcases := []struct {
+		id    string
+		cc    string
+		skeys []string
+	}{
+		{"root", "max-age=3600", []string{"marketing"}},
+		{"about", "max-age=3600", []string{"marketing"}},
+		{"account", "no-store", []string{}},
+		{"news", "max-age=3600", []string{"news", "marketing"}},
+		{"item", "max-age=3600", []string{"news", "marketing"}},
+	}
+	for _, tc := range testcases {
+		n := st.Node(tc.id)
+		if n.CacheControl != tc.cc {
+			t.Errorf("%s: expected cache control %q, but got %q", tc.id, tc.cc, n.CacheControl)
+		}
+		if !slices.Equal(n.SurrogateKeys, tc.skeys) {
+			t.Errorf("%s: expected surrogate keys %v, but got %v", tc.id, tc.skeys, n.SurrogateKeys)
+		}
+	}
+}
+
+func TestCacheHeaderMiddleware(t *testing.T) {
+	st := makeCacheSite(t)
+	lp := login.MakeProvider(slog.New(slog.DiscardHandler), &login.TestAuthenticator{}, &login.RAMSessions{}, &login.SimpleRedirector{})
+	h := lp.EnrichUserInfo(st.CacheHeaderMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Has("own") {
+			w.Header().Set("Cache-Control", "private")
+		}
+		_, _ = w.Write([]byte("ok"))
+	})))
+
+	form := url.Values{lp.UsernameKey: {"user"}, lp.PasswordKey: {"user"}}
+	r := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	lp.Login().ServeHTTP(w, r)
+	cookies := w.Result().Cookies()
+
+	testcases := []struct {
+		name     string
+		method   string
+		path     string
+		loggedIn bool
+		expCC    string
+		expSK    string
+	}{
+		{"root", http.MethodGet, "/app/", false, "max-age=3600", "marketing"},
+		{"about", http.MethodGet, "/app/about", false, "max-age=3600", "marketing"},
+		{"account", http.MethodGet, "/app/account", false, "no-store", ""},
+		{"item", http.MethodGet, "/app/news/17", false, "max-age=3600", "news marketing"},
+		{"post", http.MethodPost, "/app/about", false, "", ""},
+		{"outside", http.MethodGet, "/application", false, "", ""},
+		{"handler-set", http.MethodGet, "/app/about?own", false, "private", "marketing"},
+		{"logged-in", http.MethodGet, "/app/about", true, "no-store", "marketing"},
+		{"logged-in-handler-set", http.MethodGet, "/app/about?own", true, "no-store", "marketing"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(tc.method, tc.path, nil)
+			if tc.loggedIn {
+				for _, c := range cookies {
+					r.AddCookie(c)
+				}
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if got := w.Header().Get("Cache-Control"); got != tc.expCC {
+				t.Errorf("expected Cache-Control %q, but got %q", tc.expCC, got)
+			}
+			if got := w.Header().Get("Surrogate-Key"); got != tc.expSK {
+				t.Errorf("expected Surrogate-Key %q, but got %q", tc.expSK, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package site
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"t73f.de/r/webs/middleware"
+)
+
+// CanonicalSlashMiddleware returns a middleware functor that redirects GET
+// and HEAD requests to the canonical path of the node that matches the
+// request, if the request path differs from it only by a trailing slash, by
+// duplicate slashes, or by dot segments. The query string is preserved.
+// Placeholder segments keep their actual values.
+//
+// Requests with other methods, requests for paths below a node that matches
+// the full path (nodepath starting with '>'), and requests for paths that
+// match no node are passed through unchanged. If code is not a redirection
+// status code, "301 Moved Permanently" is used.
+func (st *Site) CanonicalSlashMiddleware(code int) middleware.Functor {
+	if code < 300 || code > 399 {
+		code = http.StatusMovedPermanently
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				if canonical, found := st.canonicalPath(r.URL.Path); found && canonical != r.URL.Path {
+					u := url.URL{Path: canonical, RawQuery: r.URL.RawQuery}
+					http.Redirect(w, r, u.String(), code)
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// canonicalPath returns the canonical form of the given request path, if it
+// matches a node exactly.
+func (st *Site) canonicalPath(reqpath string) (string, bool) {
+	cleaned := path.Clean("/" + reqpath)
+	relpath, found := strings.CutPrefix(cleaned, strings.TrimSuffix(st.Basepath, "/"))
+	if !found || (relpath != "" && relpath[0] != '/') {
+		return "", false
+	}
+	var segs []string
+	for seg := range strings.SplitSeq(relpath, "/") {
+		if seg != "" {
+			segs = append(segs, seg)
+		}
+	}
+	n := st.Root.matchSegments(segs)
+	if n == nil || n.pathSpec == pathSpecFull {
+		return "", false
+	}
+	result := path.Join(st.Basepath, path.Join(segs...))
+	if n.pathSpec == pathSpecDir && !strings.HasSuffix(result, "/") {
+		result += "/"
+	}
+	return result, true
+}
+
+// matchSegments returns the node that matches the given path segments
+// exactly, or the first node with a full path match on the way. It returns
+// nil, if there is no such node.
+func (n *Node) matchSegments(segs []string) *Node {
+	if len(segs) == 0 {
+		return n
+	}
+	for _, child := range n.Children {
+		childsegs := strings.Split(child.Nodepath, "/")
+		if len(childsegs) > len(segs) || !segmentsMatch(childsegs, segs) {
+			continue
+		}
+		if child.pathSpec == pathSpecFull {
+			return child
+		}
+		if result := child.matchSegments(segs[len(childsegs):]); result != nil {
+			return result
+		}
+	}
+	return nil
+}
+
+func segmentsMatch(nodesegs, segs []string) bool {
+	for i, ns := range nodesegs {
+		if !isPlaceholder(ns) && ns != segs[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,90 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package site_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"t73f.de/r/webs/site"
+)
+
+func TestCanonicalSlashMiddleware(t *testing.T) {
+	st := site.Site{
+		Basepath: "/app",
+		Root: site.Node{
+			ID: "root",
+			Children: []*site.Node{
+				{ID: "about", Nodepath: "about"},
+				{ID: "feed", Nodepath: "*feed.xml"},
+				{ID: "static", Nodepath: ">static"},
+				{ID: "news", Nodepath: "news", Children: []*site.Node{
+					{ID: "item", Nodepath: "*{id}"},
+					{ID: "edit", Nodepath: "{id}/edit"},
+				}},
+			},
+		},
+	}
+	if err := st.Bake(); err != nil {
+		t.Fatal(err)
+	}
+	h := st.CanonicalSlashMiddleware(http.StatusPermanentRedirect)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+
+	testcases := []struct {
+		name   string
+		method string
+		path   string
+		exp    string // empty: no redirect
+	}{
+		{"root", http.MethodGet, "/app/", ""},
+		{"root-no-slash", http.MethodGet, "/app", "/app/"},
+		{"dir", http.MethodGet, "/app/about/", ""},
+		{"dir-no-slash", http.MethodGet, "/app/about", "/app/about/"},
+		{"dir-query", http.MethodGet, "/app/about?lang=de&x=1", "/app/about/?lang=de&x=1"},
+		{"item", http.MethodGet, "/app/feed.xml", ""},
+		{"item-slash", http.MethodGet, "/app/feed.xml/?v=2", "/app/feed.xml?v=2"},
+		{"placeholder-item", http.MethodGet, "/app/news/17/", "/app/news/17"},
+		{"placeholder-dir", http.MethodGet, "/app/news/17/edit", "/app/news/17/edit/"},
+		{"duplicate-slashes", http.MethodGet, "/app//news///17", "/app/news/17"},
+		{"dot-segments", http.MethodGet, "/app/news/./x/../17", "/app/news/17"},
+		{"head", http.MethodHead, "/app/about", "/app/about/"},
+		{"post", http.MethodPost, "/app/about", ""},
+		{"full", http.MethodGet, "/app/static/css//site.css", ""},
+		{"full-dir", http.MethodGet, "/app/static", ""},
+		{"unknown", http.MethodGet, "/app/unknown", ""},
+		{"outside", http.MethodGet, "/application", ""},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(tc.method, tc.path, nil)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if tc.exp == "" {
+				if w.Code != http.StatusOK {
+					t.Errorf("no redirect expected, but got %d to %q", w.Code, w.Header().Get("Location"))
+				}
+				return
+			}
+			if w.Code != http.StatusPermanentRedirect {
+				t.Errorf("status %d expected, but got %d", http.StatusPermanentRedirect, w.Code)
+			}
+			if got := w.Header().Get("Location"); got != tc.exp {
+				t.Errorf("redirect to %q expected, but got %q", tc.exp, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package site
+
+import (
+	"net/http"
+
+	"t73f.de/r/webs/middleware"
+)
+
+// FlagResolver determines whether a feature flag is enabled for a request.
+type FlagResolver func(flag string, r *http.Request) bool
+
+// SetFlagResolver sets the function to resolve feature flags, see
+// [Node.RequiresFlags]. Without a resolver, all flags are disabled.
+func (st *Site) SetFlagResolver(resolver FlagResolver) { st.flagResolver = resolver }
+
+// FlagsEnabled returns true, if all feature flags required by the node are
+// enabled for the given request. It allows to hide navigation entries for
+// disabled nodes.
+func (n *Node) FlagsEnabled(r *http.Request) bool {
+	if len(n.RequiresFlags) == 0 {
+		return true
+	}
+	var resolver FlagResolver
+	if n.site != nil {
+		resolver = n.site.flagResolver
+	}
+	if resolver == nil {
+		return false
+	}
+	for _, flag := range n.RequiresFlags {
+		if !resolver(flag, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// VisibleChildren returns all children of the node, whose feature flags are
+// enabled for the given request.
+func (n *Node) VisibleChildren(r *http.Request) []*Node {
+	result := make([]*Node, 0, len(n.Children))
+	for _, child := range n.Children {
+		if child.FlagsEnabled(r) {
+			result = append(result, child)
+		}
+	}
+	return result
+}
+
+// FlagGateMiddleware returns a middleware functor that answers a request
+// with the given handler, if a feature flag required by the node that
+// matches the request is disabled. If the handler is nil, a plain "404 Not
+// Found" response is sent. Therefore, disabled nodes cannot be distinguished
+// from nonexistent ones.
+func (st *Site) FlagGateMiddleware(notFound http.Handler) middleware.Functor {
+	if notFound == nil {
+		notFound = http.NotFoundHandler()
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if n := st.RequestNode(r); n != nil && !n.FlagsEnabled(r) {
+				notFound.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
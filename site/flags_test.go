@@ -0,0 +1,133 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package site_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"t73f.de/r/webs/site"
+)
+
+func makeFlagSite(t *testing.T) *site.Site {
+	t.Helper()
+	st := site.Site{
+		Basepath: "/app",
+		Root: site.Node{
+			ID: "root",
+			Children: []*site.Node{
+				{ID: "about", Nodepath: "about"},
+				{ID: "beta", Nodepath: "beta", RequiresFlags: []string{" beta "}, Children: []*site.Node{
+					{ID: "new", Nodepath: "new", RequiresFlags: []string{"new", "beta"}},
+				}},
+			},
+		},
+	}
+	if err := st.Bake(); err != nil {
+		t.Fatal(err)
+	}
+	// Flags are enabled per user via a request header.
+	st.SetFlagResolver(func(flag string, r *http.Request) bool {
+		return slices.Contains(strings.Fields(r.Header.Get("X-Flags")), flag)
+	})
+	return &st
+}
+
+func flagRequest(path, flags string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, path, nil)
+	if flags != "" {
+		r.Header.Set("X-Flags", flags)
+	}
+	return r
+}
+
+func TestFlagInheritance(t *testing.T) {
+	st := makeFlagSite(t)
+	for id, exp := range map[string][]string{
+		"root":  {},
+		"about": {},
+		"beta":  {"beta"},
+		"new":   {"beta", "new"},
+	} {
+		if got := st.Node(id).RequiresFlags; !slices.Equal(got, exp) {
+			t.Errorf("%s: expected flags %v, but got %v", id, exp, got)
+		}
+	}
+
+	bad := site.Site{Root: site.Node{RequiresFlags: []string{" "}}}
+	if err := bad.Bake(); err == nil {
+		t.Error("empty flag must be rejected")
+	}
+}
+
+func TestFlagGateMiddleware(t *testing.T) {
+	st := makeFlagSite(t)
+	h := st.FlagGateMiddleware(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var titles []string
+		for _, child := range st.Node("root").VisibleChildren(r) {
+			titles = append(titles, child.ID)
+		}
+		_, _ = io.WriteString(w, strings.Join(titles, " "))
+	}))
+
+	testcases := []struct {
+		path  string
+		flags string
+		code  int
+		nav   string
+	}{
+		{"/app/about", "", http.StatusOK, "about"},
+		{"/app/beta", "", http.StatusNotFound, ""},
+		{"/app/beta", "beta", http.StatusOK, "about beta"},
+		{"/app/beta/new", "beta", http.StatusNotFound, ""},
+		{"/app/beta/new", "new", http.StatusNotFound, ""},
+		{"/app/beta/new", "new beta", http.StatusOK, "about beta"},
+		{"/other", "", http.StatusOK, "about"},
+	}
+	for _, tc := range testcases {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, flagRequest(tc.path, tc.flags))
+		if w.Code != tc.code {
+			t.Errorf("%s %q: expected status %d, but got %d", tc.path, tc.flags, tc.code, w.Code)
+			continue
+		}
+		if tc.code == http.StatusOK {
+			if got := w.Body.String(); got != tc.nav {
+				t.Errorf("%s %q: expected navigation %q, but got %q", tc.path, tc.flags, tc.nav, got)
+			}
+		}
+	}
+
+	notFound := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, "custom")
+	})
+	w := httptest.NewRecorder()
+	st.FlagGateMiddleware(notFound)(http.NotFoundHandler()).ServeHTTP(w, flagRequest("/app/beta", ""))
+	if w.Code != http.StatusNotFound || w.Body.String() != "custom" {
+		t.Errorf("custom handler expected, but got %d %q", w.Code, w.Body.String())
+	}
+
+	noResolver := site.Site{Root: site.Node{RequiresFlags: []string{"x"}}}
+	if err := noResolver.Bake(); err != nil {
+		t.Fatal(err)
+	}
+	if noResolver.Root.FlagsEnabled(flagRequest("/", "x")) {
+		t.Error("flags must be disabled without resolver")
+	}
+}
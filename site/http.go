@@ -16,6 +16,7 @@ package site
 import (
 	"net/http"
 	"path"
+	"strings"
 
 	"t73f.de/r/webs/middleware"
 )
@@ -79,3 +80,13 @@ func extendMiddleware(reg Registerer, baseMW middleware.Middleware, name string)
 	}
 	return baseMW
 }
+
+// RequestNode returns the node that matches the path of the given request at
+// best. It returns nil, if the path is not below the base path of the site.
+func (st *Site) RequestNode(r *http.Request) *Node {
+	relpath, found := strings.CutPrefix(r.URL.Path, strings.TrimSuffix(st.Basepath, "/"))
+	if !found || (relpath != "" && relpath[0] != '/') {
+		return nil
+	}
+	return st.Root.BestNode(strings.TrimPrefix(relpath, "/"))
+}
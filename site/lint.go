@@ -0,0 +1,115 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package site
+
+import (
+	"fmt"
+	"slices"
+)
+
+// ProblemKind classifies the problems found by [Site.Lint].
+type ProblemKind uint8
+
+// Values for ProblemKind.
+const (
+	_                          ProblemKind = iota
+	ProblemBake                            // Site cannot be baked
+	ProblemNoHandler                       // Node has no handler for any method
+	ProblemUnreachable                     // Node is a child of a node that matches the full path
+	ProblemDuplicatePath                   // Node has the same path element as a sibling
+	ProblemShadowedPlaceholder             // Placeholder node has a literal sibling
+	ProblemUnknownNodeRef                  // Extra value references an unknown node ID
+	ProblemEmptyTitle                      // Navigable node has no title
+)
+
+// Problem describes a mistake in the structure of a site.
+type Problem struct {
+	Kind    ProblemKind
+	NodeID  string // ID of the node, may be empty
+	Path    string // Full path of the node, see [Node.Path]
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.NodeID == "" {
+		return fmt.Sprintf("%s: %s", p.Path, p.Message)
+	}
+	return fmt.Sprintf("%s (%s): %s", p.Path, p.NodeID, p.Message)
+}
+
+// Lint checks the structure of the site for mistakes, e.g. unreachable
+// nodes. The site is baked, if this was not done before. Lint is not called
+// by [Site.Bake], because most problems do not prevent the site from
+// working.
+func (st *Site) Lint() []Problem {
+	if err := st.Bake(); err != nil {
+		return []Problem{{Kind: ProblemBake, Message: err.Error()}}
+	}
+	var problems []Problem
+	st.Root.lint(st, &problems)
+	return problems
+}
+
+func (n *Node) lint(st *Site, problems *[]Problem) {
+	add := func(node *Node, kind ProblemKind, format string, args ...any) {
+		*problems = append(*problems, Problem{
+			Kind:    kind,
+			NodeID:  node.ID,
+			Path:    node.Path(),
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	if !slices.ContainsFunc(n.Handler, func(h string) bool { return h != "" }) {
+		add(n, ProblemNoHandler, "no handler for any method")
+	}
+	if n.Navigable && n.Title == "" {
+		add(n, ProblemEmptyTitle, "navigable node without title")
+	}
+	for _, key := range st.NodeRefKeys {
+		if id, found := n.GetExtra(key); found && st.Node(id) == nil {
+			add(n, ProblemUnknownNodeRef, "extra %q references unknown node %q", key, id)
+		}
+	}
+
+	var literals []string
+	for i, child := range n.Children {
+		if n.pathSpec == pathSpecFull {
+			add(child, ProblemUnreachable, "parent %q matches the full path", n.Path())
+		}
+		if j := slices.IndexFunc(n.Children[:i], func(sibling *Node) bool {
+			return sibling.Nodepath == child.Nodepath
+		}); j >= 0 {
+			add(child, ProblemDuplicatePath, "same path element %q as sibling %d", child.Nodepath, j)
+		}
+		if !isPlaceholder(child.Nodepath) {
+			literals = append(literals, child.Nodepath)
+		}
+	}
+	for _, child := range n.Children {
+		if isPlaceholder(child.Nodepath) && len(literals) > 0 {
+			add(child, ProblemShadowedPlaceholder, "placeholder is shadowed by literal siblings %q", literals)
+		}
+	}
+
+	for _, child := range n.Children {
+		child.lint(st, problems)
+	}
+}
+
+// isPlaceholder returns true, if the path element is a placeholder, e.g.
+// "{id}".
+func isPlaceholder(nodepath string) bool {
+	return len(nodepath) > 1 && nodepath[0] == '{' && nodepath[len(nodepath)-1] == '}'
+}
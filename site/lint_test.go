@@ -0,0 +1,101 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package site_test
+
+import (
+	"slices"
+	"testing"
+
+	"t73f.de/r/webs/site"
+)
+
+func TestLint(t *testing.T) {
+	h := []string{"handler"}
+	testCases := []struct {
+		name     string
+		site     site.Site
+		expKind  site.ProblemKind
+		expPaths []string
+	}{
+		{"no-handler", site.Site{Root: site.Node{Handler: h, Children: []*site.Node{
+			{Nodepath: "a", Handler: []string{"", ""}},
+			{Nodepath: "b", Handler: []string{"", "post"}},
+		}}}, site.ProblemNoHandler, []string{"/a/"}},
+		{"unreachable", site.Site{Root: site.Node{Handler: h, Children: []*site.Node{
+			{Nodepath: ">files", Handler: h, Children: []*site.Node{
+				{Nodepath: "x", Handler: h},
+				{Nodepath: "y", Handler: h},
+			}},
+		}}}, site.ProblemUnreachable, []string{"/files/x/", "/files/y/"}},
+		{"duplicate", site.Site{Root: site.Node{Handler: h, Children: []*site.Node{
+			{Nodepath: "a", Handler: h},
+			{Nodepath: "b", Handler: h},
+			{Nodepath: "*a", Handler: h},
+		}}}, site.ProblemDuplicatePath, []string{"/a"}},
+		{"shadowed", site.Site{Root: site.Node{Handler: h, Children: []*site.Node{
+			{Nodepath: "{id}", Handler: h},
+			{Nodepath: "new", Handler: h},
+		}}}, site.ProblemShadowedPlaceholder, []string{"/{id}/"}},
+		{"node-ref", site.Site{NodeRefKeys: []string{"help"}, Root: site.Node{ID: "root", Handler: h, Children: []*site.Node{
+			{Nodepath: "a", Handler: h, Extra: map[string]string{"help": "root"}},
+			{Nodepath: "b", Handler: h, Extra: map[string]string{"help": "missing", "other": "missing"}},
+		}}}, site.ProblemUnknownNodeRef, []string{"/b/"}},
+		{"title", site.Site{Root: site.Node{Handler: h, Children: []*site.Node{
+			{ID: "a", Nodepath: "a", Handler: h, Navigable: true, Title: "A"},
+			{ID: "b", Nodepath: "b", Handler: h, Navigable: true, Title: "  "},
+			{ID: "c", Nodepath: "c", Handler: h},
+		}}}, site.ProblemEmptyTitle, []string{"/b/"}},
+		{"bake", site.Site{Root: site.Node{ID: "x", Handler: h, Children: []*site.Node{
+			{ID: "x", Nodepath: "a", Handler: h},
+		}}}, site.ProblemBake, []string{""}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			problems := tc.site.Lint()
+			var paths []string
+			for _, p := range problems {
+				if p.Kind != tc.expKind {
+					t.Errorf("unexpected problem %v", p)
+					continue
+				}
+				if p.Message == "" {
+					t.Errorf("problem without message: %v", p)
+				}
+				paths = append(paths, p.Path)
+			}
+			if !slices.Equal(paths, tc.expPaths) {
+				t.Errorf("problems expected for %q, got %q", tc.expPaths, paths)
+			}
+		})
+	}
+}
+
+func TestLintClean(t *testing.T) {
+	st := site.Site{
+		Basepath:    "/app",
+		NodeRefKeys: []string{"parent"},
+		Root: site.Node{ID: "root", Title: "Home", Navigable: true, Handler: []string{"home"}, Children: []*site.Node{
+			{ID: "items", Nodepath: "items", Handler: []string{"list", "create"}, Children: []*site.Node{
+				{ID: "item", Nodepath: "{id}", Handler: []string{"show"}, Extra: map[string]string{"parent": "items"}},
+			}},
+			{ID: "files", Nodepath: ">files", Handler: []string{"files"}},
+		}},
+	}
+	if problems := st.Lint(); len(problems) > 0 {
+		t.Errorf("no problems expected, got %v", problems)
+	}
+	if problems := st.Lint(); len(problems) > 0 {
+		t.Errorf("linting twice must not change the result, got %v", problems)
+	}
+}
@@ -32,9 +32,14 @@ type Site struct {
 	Methods  []string // HTTP methods to be used by node handler. Default: GET, POST.
 	Root     Node     // Root note of the site.
 
-	baked     bool
-	basepaths []string
-	nodes     map[string]*Node
+	// NodeRefKeys are the keys of Node.Extra, whose values are node IDs.
+	// They are checked by Site.Lint.
+	NodeRefKeys []string
+
+	baked        bool
+	basepaths    []string
+	nodes        map[string]*Node
+	flagResolver FlagResolver
 }
 
 // DefaultLanguage is the language value used as a default.
@@ -128,6 +133,11 @@ type Node struct {
 	HandlerMW  []string          // Specific middleware for Node.Handler[].
 	Children   []*Node           // Child nodes
 
+	CacheControl  string   // Value of "Cache-Control" header, is inherited to children
+	SurrogateKeys []string // Keys for "Surrogate-Key" header, are inherited to children
+	RequiresFlags []string // Feature flags that must be enabled, are added to those of children
+	Navigable     bool     // Node is shown in navigation menus, so it needs a title
+
 	site     *Site
 	parent   *Node
 	pathSpec pathSpec
@@ -234,8 +244,7 @@ func (n *Node) nodeByID(id string) *Node {
 func (n *Node) BestNode(relpath string) *Node {
 	for _, child := range n.Children {
 		childpath := child.Nodepath
-		if len(childpath) > 1 && childpath[0] == '{' && childpath[len(childpath)-1] == '}' {
-			// child path is a placeholder
+		if isPlaceholder(childpath) {
 			sepPos := strings.IndexByte(relpath, '/')
 			if sepPos < 0 || sepPos == len(relpath)-1 {
 				return child
@@ -302,6 +311,29 @@ func (n *Node) bake(st *Site, p *Node) error {
 
 	n.Middleware = strings.TrimSpace(n.Middleware)
 
+	n.CacheControl = strings.TrimSpace(n.CacheControl)
+	if n.CacheControl == "" && p != nil {
+		n.CacheControl = p.CacheControl
+	}
+	if n.SurrogateKeys == nil && p != nil {
+		n.SurrogateKeys = p.SurrogateKeys
+	}
+
+	flags := make([]string, 0, len(n.RequiresFlags))
+	if p != nil {
+		flags = append(flags, p.RequiresFlags...)
+	}
+	for _, flag := range n.RequiresFlags {
+		flag = strings.TrimSpace(flag)
+		if flag == "" {
+			return fmt.Errorf("empty feature flag for node %v", n.Nodepath)
+		}
+		if !slices.Contains(flags, flag) {
+			flags = append(flags, flag)
+		}
+	}
+	n.RequiresFlags = slices.Clip(flags)
+
 	for i, h := range n.Handler {
 		n.Handler[i] = strings.TrimSpace(h)
 	}
@@ -368,7 +400,7 @@ func (n *Node) BuilderFor(args ...any) *urlbuilder.URLBuilder {
 	ancestors := []string{}
 	for a := n; a != nil; a = a.parent {
 		if pe := a.Nodepath; pe != "" {
-			if pe[0] == '{' && pe[len(pe)-1] == '}' {
+			if isPlaceholder(pe) {
 				if pos < len(args) {
 					pe = anyToString(args[pos])
 				} else {
@@ -0,0 +1,32 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+// Package sitetest provides utilities to test the structure of a site.
+package sitetest
+
+import (
+	"testing"
+
+	"t73f.de/r/webs/site"
+)
+
+// AssertClean reports an error for each problem found by [site.Site.Lint].
+// It returns true, if no problem was found.
+func AssertClean(t testing.TB, st *site.Site) bool {
+	t.Helper()
+	problems := st.Lint()
+	for _, p := range problems {
+		t.Errorf("site %q: %v", st.Name, p)
+	}
+	return len(problems) == 0
+}
@@ -0,0 +1,49 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package sitetest_test
+
+import (
+	"fmt"
+	"testing"
+
+	"t73f.de/r/webs/site"
+	"t73f.de/r/webs/site/sitetest"
+)
+
+// recordingTB records the reported errors, instead of failing the test.
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (*recordingTB) Helper() {}
+func (r *recordingTB) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertClean(t *testing.T) {
+	st := site.Site{Name: "clean", Root: site.Node{ID: "root", Handler: []string{"home"}}}
+	if !sitetest.AssertClean(t, &st) {
+		t.Error("clean site expected")
+	}
+
+	st = site.Site{Name: "broken", Root: site.Node{ID: "root"}}
+	var tb recordingTB
+	if sitetest.AssertClean(&tb, &st) {
+		t.Error("problem expected")
+	}
+	if exp := `site "broken": / (root): no handler for any method`; len(tb.errors) != 1 || tb.errors[0] != exp {
+		t.Errorf("expected error %q, got %q", exp, tb.errors)
+	}
+}
@@ -0,0 +1,200 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+// Package paginate builds the links of a paginated listing.
+package paginate
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"t73f.de/r/webs/htmls"
+	"t73f.de/r/webs/urlbuilder"
+)
+
+// LinkSet contains the links to navigate between the pages of a listing.
+// A link is nil, if it is not applicable, e.g. there is no previous page
+// on the first page.
+type LinkSet struct {
+	Current    int // number of the current page, starting with 1
+	TotalPages int
+
+	First *urlbuilder.URLBuilder
+	Prev  *urlbuilder.URLBuilder
+	Next  *urlbuilder.URLBuilder
+	Last  *urlbuilder.URLBuilder
+
+	// Window contains the numbered pages around the current page. An
+	// ellipsis marks omitted pages before or after the window.
+	Window []PageLink
+}
+
+// PageLink is an element of the window of numbered pages.
+type PageLink struct {
+	Number   int                    // zero for an ellipsis
+	URL      *urlbuilder.URLBuilder // nil for an ellipsis
+	Current  bool
+	Ellipsis bool
+}
+
+// Links returns the links of a listing with the given number of pages. The
+// page number is stored in the query parameter pageParam. Every link is an
+// independent copy of base, where only this parameter is replaced; all
+// other parameters are preserved. The window contains at most window
+// numbered pages, centered on the current page.
+//
+// A current page outside the range of pages is moved to the nearest page.
+// If there are no pages, a zero LinkSet value is returned.
+func Links(base *urlbuilder.URLBuilder, current, totalPages, window int, pageParam string) LinkSet {
+	if totalPages <= 0 {
+		return LinkSet{}
+	}
+	current = max(1, min(current, totalPages))
+	window = max(1, min(window, totalPages))
+
+	pageURL := func(page int) *urlbuilder.URLBuilder {
+		var ub urlbuilder.URLBuilder
+		base.Copy(&ub)
+		return ub.SetQuery(pageParam, strconv.Itoa(page))
+	}
+
+	l := LinkSet{Current: current, TotalPages: totalPages}
+	if current > 1 {
+		l.First = pageURL(1)
+		l.Prev = pageURL(current - 1)
+	}
+	if current < totalPages {
+		l.Next = pageURL(current + 1)
+		l.Last = pageURL(totalPages)
+	}
+
+	start := max(1, min(current-window/2, totalPages-window+1))
+	end := start + window - 1
+	l.Window = make([]PageLink, 0, window+2)
+	if start > 1 {
+		l.Window = append(l.Window, PageLink{Ellipsis: true})
+	}
+	for page := start; page <= end; page++ {
+		l.Window = append(l.Window, PageLink{Number: page, URL: pageURL(page), Current: page == current})
+	}
+	if end < totalPages {
+		l.Window = append(l.Window, PageLink{Ellipsis: true})
+	}
+	return l
+}
+
+// LinkHeader returns the value of a "Link" header (RFC 8288) with the
+// relations "first", "prev", "next", and "last". It returns the empty
+// string, if there are no such links.
+func (l LinkSet) LinkHeader() string {
+	var sb strings.Builder
+	for _, rl := range l.relLinks() {
+		if sb.Len() > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteByte('<')
+		sb.WriteString(rl.url.String())
+		sb.WriteString(`>; rel="`)
+		sb.WriteString(rl.rel)
+		sb.WriteByte('"')
+	}
+	return sb.String()
+}
+
+// SetLinkHeader adds the "Link" header of the links to the given header,
+// e.g. of an API response. Nothing is added, if there are no links.
+func (l LinkSet) SetLinkHeader(h http.Header) {
+	if val := l.LinkHeader(); val != "" {
+		h.Add("Link", val)
+	}
+}
+
+type relLink struct {
+	rel string
+	url *urlbuilder.URLBuilder
+}
+
+func (l LinkSet) relLinks() []relLink {
+	result := make([]relLink, 0, 4)
+	for _, rl := range []relLink{{"first", l.First}, {"prev", l.Prev}, {"next", l.Next}, {"last", l.Last}} {
+		if rl.url != nil {
+			result = append(result, rl)
+		}
+	}
+	return result
+}
+
+// NavOptions contains the texts and the CSS class of [RenderNav]. An empty
+// text is replaced by its default value.
+type NavOptions struct {
+	Class      string // CSS class of the list
+	Label      string // accessible name of the navigation, default: "pagination"
+	FirstLabel string // default: "«"
+	PrevLabel  string // default: "‹"
+	NextLabel  string // default: "›"
+	LastLabel  string // default: "»"
+	Ellipsis   string // default: "…"
+}
+
+// RenderNav returns a <nav> element with a list of the links. The links to
+// the previous and the next page have the "rel" attributes "prev" and
+// "next", the current page is marked with aria-current="page". If there is
+// only one page or none, nil is returned.
+func RenderNav(l LinkSet, opts NavOptions) *htmls.Node {
+	if l.TotalPages <= 1 {
+		return nil
+	}
+	label := func(s, def string) string {
+		if s == "" {
+			return def
+		}
+		return s
+	}
+	link := func(ub *urlbuilder.URLBuilder, text, rel string) *htmls.Node {
+		if ub == nil {
+			return nil
+		}
+		attrs := htmls.Attrs("href", ub.String())
+		if rel != "" {
+			attrs = append(attrs, htmls.Attribute{Key: "rel", Value: rel})
+		}
+		return htmls.Elem("li", nil, htmls.Elem("a", attrs, htmls.Text(text)))
+	}
+
+	list := htmls.Elem("ul", listAttrs(opts.Class),
+		link(l.First, label(opts.FirstLabel, "«"), ""),
+		link(l.Prev, label(opts.PrevLabel, "‹"), "prev"))
+	for _, pl := range l.Window {
+		switch {
+		case pl.Ellipsis:
+			list.AddChildren(htmls.Elem("li", htmls.Attrs("aria-hidden", "true"), htmls.Text(label(opts.Ellipsis, "…"))))
+		case pl.Current:
+			list.AddChildren(htmls.Elem("li", nil,
+				htmls.Elem("a", htmls.Attrs("href", pl.URL.String(), "aria-current", "page"), htmls.Text(strconv.Itoa(pl.Number)))))
+		default:
+			list.AddChildren(link(pl.URL, strconv.Itoa(pl.Number), ""))
+		}
+	}
+	list.AddChildren(
+		link(l.Next, label(opts.NextLabel, "›"), "next"),
+		link(l.Last, label(opts.LastLabel, "»"), ""))
+	return htmls.Elem("nav", htmls.Attrs("aria-label", label(opts.Label, "pagination")), list)
+}
+
+func listAttrs(class string) []htmls.Attribute {
+	if class == "" {
+		return nil
+	}
+	return htmls.Attrs("class", class)
+}
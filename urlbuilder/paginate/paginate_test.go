@@ -0,0 +1,170 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package paginate_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"t73f.de/r/webs/htmls/render"
+	"t73f.de/r/webs/urlbuilder"
+	"t73f.de/r/webs/urlbuilder/paginate"
+)
+
+func newBase() *urlbuilder.URLBuilder {
+	var ub urlbuilder.URLBuilder
+	return ub.AddPath("items").AddQuery("q", "red shoes").AddQuery("page", "7").AddQuery("sort", "price")
+}
+
+func urlString(ub *urlbuilder.URLBuilder) string {
+	if ub == nil {
+		return "<nil>"
+	}
+	return ub.String()
+}
+
+// windowString returns a short representation of the window, e.g. "… 4 [5] 6 …".
+func windowString(l paginate.LinkSet) string {
+	var parts []string
+	for _, pl := range l.Window {
+		switch {
+		case pl.Ellipsis:
+			parts = append(parts, "…")
+		case pl.Current:
+			parts = append(parts, "["+pageOf(pl.URL)+"]")
+		default:
+			parts = append(parts, pageOf(pl.URL))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func pageOf(ub *urlbuilder.URLBuilder) string {
+	s := ub.String()
+	_, page, _ := strings.Cut(s, "page=")
+	page, _, _ = strings.Cut(page, "&")
+	return page
+}
+
+func TestLinks(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		name                    string
+		current, total, window  int
+		first, prev, next, last string
+		expWindow               string
+	}{
+		{"first page", 1, 10, 3, "<nil>", "<nil>", "2", "10", "[1] 2 3 …"},
+		{"last page", 10, 10, 3, "1", "9", "<nil>", "<nil>", "… 8 9 [10]"},
+		{"middle", 5, 10, 3, "1", "4", "6", "10", "… 4 [5] 6 …"},
+		{"even window", 5, 10, 4, "1", "4", "6", "10", "… 3 4 [5] 6 …"},
+		{"small total", 2, 3, 5, "1", "1", "3", "3", "1 [2] 3"},
+		{"single page", 1, 1, 5, "<nil>", "<nil>", "<nil>", "<nil>", "[1]"},
+		{"beyond last", 12, 10, 3, "1", "9", "<nil>", "<nil>", "… 8 9 [10]"},
+		{"no pages", 1, 0, 3, "<nil>", "<nil>", "<nil>", "<nil>", ""},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			l := paginate.Links(newBase(), tc.current, tc.total, tc.window, "page")
+			for _, link := range []struct {
+				name string
+				ub   *urlbuilder.URLBuilder
+				exp  string
+			}{{"first", l.First, tc.first}, {"prev", l.Prev, tc.prev}, {"next", l.Next, tc.next}, {"last", l.Last, tc.last}} {
+				got := "<nil>"
+				if link.ub != nil {
+					got = pageOf(link.ub)
+				}
+				if got != link.exp {
+					t.Errorf("%s: page %q expected, got %q", link.name, link.exp, got)
+				}
+			}
+			if got := windowString(l); got != tc.expWindow {
+				t.Errorf("window %q expected, got %q", tc.expWindow, got)
+			}
+		})
+	}
+}
+
+func TestLinksPreserveQuery(t *testing.T) {
+	t.Parallel()
+	base := newBase()
+	l := paginate.Links(base, 2, 5, 3, "page")
+	if exp, got := "/items?q=red+shoes&page=3&sort=price", urlString(l.Next); got != exp {
+		t.Errorf("next: %q expected, got %q", exp, got)
+	}
+	if exp, got := "/items?q=red+shoes&page=7&sort=price", base.String(); got != exp {
+		t.Errorf("base must not change: %q expected, got %q", exp, got)
+	}
+	l.Next.AddQuery("x", "y")
+	if exp, got := "/items?q=red+shoes&page=5&sort=price", urlString(l.Last); got != exp {
+		t.Errorf("links must be independent: %q expected, got %q", exp, got)
+	}
+
+	var ub urlbuilder.URLBuilder
+	ub.AddPath("items").AddQuery("q", "a")
+	if exp, got := "/items?q=a&p=2", urlString(paginate.Links(&ub, 1, 2, 2, "p").Next); got != exp {
+		t.Errorf("missing page parameter: %q expected, got %q", exp, got)
+	}
+}
+
+func TestLinkHeader(t *testing.T) {
+	t.Parallel()
+	var ub urlbuilder.URLBuilder
+	ub.AddPath("api").AddPath("items")
+	h := http.Header{}
+	paginate.Links(&ub, 2, 3, 3, "page").SetLinkHeader(h)
+	exp := `</api/items?page=1>; rel="first", </api/items?page=1>; rel="prev", </api/items?page=3>; rel="next", </api/items?page=3>; rel="last"`
+	if got := h.Get("Link"); got != exp {
+		t.Errorf("%q expected, got %q", exp, got)
+	}
+
+	h = http.Header{}
+	paginate.Links(&ub, 1, 1, 3, "page").SetLinkHeader(h)
+	if got, found := h["Link"]; found {
+		t.Errorf("no link header expected, got %q", got)
+	}
+}
+
+func TestRenderNav(t *testing.T) {
+	t.Parallel()
+	var ub urlbuilder.URLBuilder
+	ub.AddPath("items")
+	var sb strings.Builder
+	if err := render.Render(&sb, paginate.RenderNav(paginate.Links(&ub, 1, 5, 2, "page"), paginate.NavOptions{Class: "pages"})); err != nil {
+		t.Fatal(err)
+	}
+	exp := `<nav aria-label="pagination"><ul class="pages">` +
+		`<li><a href="/items?page=1" aria-current="page">1</a></li>` +
+		`<li><a href="/items?page=2">2</a></li>` +
+		`<li aria-hidden="true">…</li>` +
+		`<li><a href="/items?page=2" rel="next">›</a></li>` +
+		`<li><a href="/items?page=5">»</a></li></ul></nav>`
+	if got := sb.String(); got != exp {
+		t.Errorf("\nexp: %s\ngot: %s", exp, got)
+	}
+
+	sb.Reset()
+	if err := render.Render(&sb, paginate.RenderNav(paginate.Links(&ub, 5, 5, 1, "page"), paginate.NavOptions{PrevLabel: "back"})); err != nil {
+		t.Fatal(err)
+	}
+	if got := sb.String(); !strings.Contains(got, `<a href="/items?page=4" rel="prev">back</a>`) || strings.Contains(got, `rel="next"`) {
+		t.Errorf("unexpected navigation: %s", got)
+	}
+
+	if node := paginate.RenderNav(paginate.Links(&ub, 1, 1, 3, "page"), paginate.NavOptions{}); node != nil {
+		t.Errorf("no navigation expected for a single page, got %v", node)
+	}
+}
@@ -0,0 +1,332 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package urlbuilder
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AddQueryValues adds a query parameter for each of the given values, all
+// with the same key.
+func (ub *URLBuilder) AddQueryValues(key string, values []string) *URLBuilder {
+	for _, val := range values {
+		ub.query = append(ub.query, urlQuery{key, val})
+	}
+	return ub
+}
+
+// AddQueryStruct adds query parameters for the fields of the given struct,
+// or pointer to a struct, in the order of their declaration.
+//
+// Only exported fields with a tag `query:"name"` are considered, other fields
+// are ignored. The option "omitempty", as in `query:"name,omitempty"`, omits
+// a field with a zero value. Supported field types are strings, booleans,
+// numbers, and [time.Time]. A time is formatted according to RFC 3339, or
+// according to the layout given in an additional tag, like
+// `layout:"2006-01-02"`. Slices of these types result in a query parameter
+// for each element. A map[string]string results in a query parameter
+// "name.key" for each map entry, ordered by key.
+//
+// If the struct contains an unsupported field type, an error is returned and
+// no query parameter is added.
+func (ub *URLBuilder) AddQueryStruct(v any) error {
+	rv, err := structValue(v)
+	if err != nil {
+		return err
+	}
+	fields, err := queryFields(rv.Type())
+	if err != nil {
+		return err
+	}
+	var query []urlQuery
+	for _, qf := range fields {
+		fv := rv.Field(qf.index)
+		if qf.omitempty && isEmptyValue(fv) {
+			continue
+		}
+		key := url.QueryEscape(qf.name)
+		switch fv.Kind() {
+		case reflect.Slice:
+			for i := range fv.Len() {
+				s, errFormat := formatScalar(fv.Index(i), qf.layout)
+				if errFormat != nil {
+					return fmt.Errorf("query field %q: %w", qf.name, errFormat)
+				}
+				query = append(query, urlQuery{key, s})
+			}
+		case reflect.Map:
+			keys := make([]string, 0, fv.Len())
+			for _, k := range fv.MapKeys() {
+				keys = append(keys, k.String())
+			}
+			slices.Sort(keys)
+			for _, k := range keys {
+				query = append(query, urlQuery{
+					url.QueryEscape(qf.name + "." + k),
+					fv.MapIndex(reflect.ValueOf(k).Convert(fv.Type().Key())).String(),
+				})
+			}
+		default:
+			s, errFormat := formatScalar(fv, qf.layout)
+			if errFormat != nil {
+				return fmt.Errorf("query field %q: %w", qf.name, errFormat)
+			}
+			query = append(query, urlQuery{key, s})
+		}
+	}
+	ub.query = append(ub.query, query...)
+	return nil
+}
+
+// DecodeQueryStruct sets the fields of the struct dst points to from the
+// given query values. It is the inverse of [URLBuilder.AddQueryStruct] and
+// uses the same struct tags.
+//
+// Fields without a matching query parameter are not changed. For slices, all
+// values of a query parameter are used, for other field types only the first
+// one. Entries of a map are added to an existing map.
+func DecodeQueryStruct(values url.Values, dst any) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.New("query decoding needs a non-nil pointer to a struct")
+	}
+	rv, err := structValue(dst)
+	if err != nil {
+		return err
+	}
+	fields, err := queryFields(rv.Type())
+	if err != nil {
+		return err
+	}
+	for _, qf := range fields {
+		fv := rv.Field(qf.index)
+		switch fv.Kind() {
+		case reflect.Slice:
+			vals, found := values[qf.name]
+			if !found {
+				continue
+			}
+			slice := reflect.MakeSlice(fv.Type(), len(vals), len(vals))
+			for i, val := range vals {
+				if err = parseScalar(val, slice.Index(i), qf.layout); err != nil {
+					return fmt.Errorf("query field %q: %w", qf.name, err)
+				}
+			}
+			fv.Set(slice)
+		case reflect.Map:
+			prefix := qf.name + "."
+			for key, vals := range values {
+				k, found := strings.CutPrefix(key, prefix)
+				if !found || len(vals) == 0 {
+					continue
+				}
+				if fv.IsNil() {
+					fv.Set(reflect.MakeMap(fv.Type()))
+				}
+				fv.SetMapIndex(
+					reflect.ValueOf(k).Convert(fv.Type().Key()),
+					reflect.ValueOf(vals[0]).Convert(fv.Type().Elem()))
+			}
+		default:
+			vals, found := values[qf.name]
+			if !found || len(vals) == 0 {
+				continue
+			}
+			if err = parseScalar(vals[0], fv, qf.layout); err != nil {
+				return fmt.Errorf("query field %q: %w", qf.name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// queryField describes a struct field that is mapped to a query parameter.
+type queryField struct {
+	index     int
+	name      string
+	omitempty bool
+	layout    string
+}
+
+func structValue(v any) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return rv, errors.New("query struct must not be nil")
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return rv, fmt.Errorf("query struct expected, got %T", v)
+	}
+	return rv, nil
+}
+
+func queryFields(t reflect.Type) ([]queryField, error) {
+	var result []queryField
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		tag, found := sf.Tag.Lookup("query")
+		if !found || tag == "-" || !sf.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			return nil, fmt.Errorf("query field %s: missing name", sf.Name)
+		}
+		if err := checkFieldType(sf.Type); err != nil {
+			return nil, fmt.Errorf("query field %q: %w", name, err)
+		}
+		result = append(result, queryField{
+			index:     i,
+			name:      name,
+			omitempty: slices.Contains(strings.Split(opts, ","), "omitempty"),
+			layout:    sf.Tag.Get("layout"),
+		})
+	}
+	return result, nil
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+func checkFieldType(t reflect.Type) error {
+	switch t.Kind() {
+	case reflect.Slice:
+		if isScalarType(t.Elem()) {
+			return nil
+		}
+	case reflect.Map:
+		if t.Key().Kind() == reflect.String && t.Elem().Kind() == reflect.String {
+			return nil
+		}
+	default:
+		if isScalarType(t) {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported type %v", t)
+}
+
+func isScalarType(t reflect.Type) bool {
+	if t == timeType {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	if v.Type() == timeType {
+		return v.Interface().(time.Time).IsZero()
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+func formatScalar(v reflect.Value, layout string) (string, error) {
+	if v.Type() == timeType {
+		if layout == "" {
+			layout = time.RFC3339Nano
+		}
+		return v.Interface().(time.Time).Format(layout), nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	}
+	return "", fmt.Errorf("unsupported type %v", v.Type())
+}
+
+func parseScalar(s string, v reflect.Value, layout string) error {
+	if v.Type() == timeType {
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %v", v.Type())
+	}
+	return nil
+}
+
+// SetQuery sets the query parameter with the given key to the value. The
+// first parameter with this key is replaced, all other parameters with this
+// key are removed. If there is no such parameter, it is added.
+func (ub *URLBuilder) SetQuery(key, value string) *URLBuilder {
+	pos := slices.IndexFunc(ub.query, func(q urlQuery) bool { return q.key == key })
+	if pos < 0 {
+		ub.query = append(ub.query, urlQuery{key, value})
+		return ub
+	}
+	ub.query[pos].val = value
+	ub.query = append(ub.query[:pos+1], slices.DeleteFunc(ub.query[pos+1:], func(q urlQuery) bool { return q.key == key })...)
+	return ub
+}
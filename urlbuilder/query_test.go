@@ -0,0 +1,159 @@
+//-----------------------------------------------------------------------------
+// Copyright (c) 2025-present Detlef Stern
+//
+// This file is part of webs.
+//
+// webs is licensed under the latest version of the EUPL (European Union Public
+// License. Please see file LICENSE.txt for your rights and obligations under
+// this license.
+//
+// SPDX-License-Identifier: EUPL-1.2
+// SPDX-FileCopyrightText: 2025-present Detlef Stern
+//-----------------------------------------------------------------------------
+
+package urlbuilder_test
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"t73f.de/r/webs/urlbuilder"
+)
+
+func TestAddQueryValues(t *testing.T) {
+	t.Parallel()
+	var ub urlbuilder.URLBuilder
+	ub.AddPath("list").AddQueryValues("tag", []string{"a b", "c"}).AddQueryValues("none", nil)
+	if exp, got := "/list?tag=a+b&tag=c", ub.String(); got != exp {
+		t.Errorf("expected %q, but got %q", exp, got)
+	}
+}
+
+func TestSetQuery(t *testing.T) {
+	t.Parallel()
+	var ub urlbuilder.URLBuilder
+	ub.AddPath("list").AddQuery("page", "1").AddQuery("q", "x").AddQuery("page", "2")
+	var other urlbuilder.URLBuilder
+	ub.Copy(&other)
+	ub.SetQuery("page", "3")
+	if exp, got := "/list?page=3&q=x", ub.String(); got != exp {
+		t.Errorf("expected %q, but got %q", exp, got)
+	}
+	if exp, got := "/list?page=1&q=x&page=2", other.String(); got != exp {
+		t.Errorf("copy must not change, expected %q, but got %q", exp, got)
+	}
+	ub.SetQuery("size", "10")
+	if exp, got := "/list?page=3&q=x&size=10", ub.String(); got != exp {
+		t.Errorf("expected %q, but got %q", exp, got)
+	}
+}
+
+type filter struct {
+	Query   string            `query:"q,omitempty"`
+	Page    int               `query:"page"`
+	Size    uint16            `query:"size,omitempty"`
+	Ratio   float64           `query:"ratio,omitempty"`
+	Desc    bool              `query:"desc,omitempty"`
+	Since   time.Time         `query:"since,omitempty"`
+	Day     time.Time         `query:"day,omitempty" layout:"2006-01-02"`
+	Tags    []string          `query:"tag,omitempty"`
+	IDs     []int64           `query:"id,omitempty"`
+	Filters map[string]string `query:"f,omitempty"`
+	Ignored string
+	Skipped string `query:"-"`
+}
+
+func TestQueryStructRoundTrip(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		name string
+		in   filter
+		exp  string
+	}{
+		{"zero", filter{}, "/s?page=0"},
+		{"full", filter{
+			Query:   "hello world",
+			Page:    3,
+			Size:    50,
+			Ratio:   0.25,
+			Desc:    true,
+			Since:   time.Date(2025, 3, 4, 5, 6, 7, 8, time.UTC),
+			Day:     time.Date(2025, 12, 24, 0, 0, 0, 0, time.UTC),
+			Tags:    []string{"x", "y&z"},
+			IDs:     []int64{-1, 7},
+			Filters: map[string]string{"status": "open", "owner": "me"},
+			Ignored: "ignored",
+			Skipped: "skipped",
+		}, "/s?q=hello+world&page=3&size=50&ratio=0.25&desc=true" +
+			"&since=2025-03-04T05%3A06%3A07.000000008Z&day=2025-12-24" +
+			"&tag=x&tag=y%26z&id=-1&id=7&f.owner=me&f.status=open"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var ub urlbuilder.URLBuilder
+			ub.AddPath("s")
+			if err := ub.AddQueryStruct(&tc.in); err != nil {
+				t.Fatal(err)
+			}
+			s := ub.String()
+			if s != tc.exp {
+				t.Errorf("expected %q, but got %q", tc.exp, s)
+			}
+
+			u, err := url.Parse(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got filter
+			if err = urlbuilder.DecodeQueryStruct(u.Query(), &got); err != nil {
+				t.Fatal(err)
+			}
+			exp := tc.in
+			exp.Ignored, exp.Skipped = "", ""
+			if !reflect.DeepEqual(got, exp) {
+				t.Errorf("round trip failed:\nexp %+v\ngot %+v", exp, got)
+			}
+		})
+	}
+}
+
+func TestQueryStructErrors(t *testing.T) {
+	t.Parallel()
+	var ub urlbuilder.URLBuilder
+	ub.AddQuery("k", "v")
+	type unsupported struct {
+		Name string         `query:"name"`
+		Ch   chan int       `query:"ch"`
+		M    map[string]int `query:"m"`
+	}
+	if err := ub.AddQueryStruct(unsupported{Name: "n"}); err == nil {
+		t.Error("error expected for unsupported field type")
+	}
+	if err := ub.AddQueryStruct(17); err == nil {
+		t.Error("error expected for non-struct")
+	}
+	if err := ub.AddQueryStruct((*filter)(nil)); err == nil {
+		t.Error("error expected for nil pointer")
+	}
+	if exp, got := "/?k=v", ub.String(); got != exp {
+		t.Errorf("failed calls must not add queries, expected %q, but got %q", exp, got)
+	}
+
+	var f filter
+	if err := urlbuilder.DecodeQueryStruct(url.Values{}, f); err == nil {
+		t.Error("error expected for non-pointer")
+	}
+	for _, vals := range []url.Values{
+		{"page": {"x"}},
+		{"size": {"70000"}},
+		{"desc": {"maybe"}},
+		{"since": {"2025-13-01"}},
+		{"id": {"1", "two"}},
+	} {
+		if err := urlbuilder.DecodeQueryStruct(vals, &f); err == nil {
+			t.Errorf("error expected for %v", vals)
+		}
+	}
+}